@@ -0,0 +1,264 @@
+package golitekit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"runtime"
+	"runtime/pprof"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/hansir-hsj/GoLiteKit/env"
+	"github.com/hansir-hsj/GoLiteKit/logger"
+)
+
+const DefaultAdminPrefix = "/_admin"
+
+// AdminOptions configures the runtime introspection group.
+type AdminOptions struct {
+	Token  string // required bearer token
+	Prefix string // URL prefix, defaults to DefaultAdminPrefix
+
+	// RateLimiters are reported by GET {prefix}/ratelimit under their map keys.
+	RateLimiters map[string]*RateLimiter
+	// Config is an optional application config dumped next to the env
+	// settings by GET {prefix}/config; sensitive keys are redacted.
+	Config any
+	// Latency is reported by GET {prefix}/latency and cleared by
+	// POST {prefix}/latency/reset.
+	Latency *LatencyTracker
+	// Services is reported by GET {prefix}/services and cleared by
+	// POST {prefix}/services/reset.
+	Services *ServiceStats
+	// BodySampler is reported by GET {prefix}/shapes, the input of
+	// `glk infer types`.
+	BodySampler *BodySampler
+	// LoadShedder is reported by GET {prefix}/loadshed.
+	LoadShedder *LoadShedder
+	// ConcurrencyLimiters are reported by GET {prefix}/concurrency under
+	// their map keys.
+	ConcurrencyLimiters map[string]*ConcurrencyLimiter
+}
+
+// MountAdmin registers a token-protected group of introspection endpoints:
+//
+//	GET {prefix}/routes       registered routes
+//	GET {prefix}/middlewares  global middleware chain, outermost first
+//	GET {prefix}/config       env settings and AdminOptions.Config, secrets redacted
+//	GET {prefix}/goroutines   goroutine dump (text)
+//	GET {prefix}/heap         heap profile (pprof format, ?debug=1 for text)
+//	GET {prefix}/ratelimit    RateLimiter.Stats for each configured limiter
+//	GET {prefix}/loglevel     current logger level
+//	PUT {prefix}/loglevel     change the logger level: {"level": "DEBUG"}
+//	GET {prefix}/subsystems   subsystems that can be restarted
+//	POST {prefix}/subsystems/{name}/restart
+//	                          restart one of them (Services.Restart); "ratelimit"
+//	                          resets every RateLimiters entry
+//	GET {prefix}/allocs       framework allocation counters and tuning hints
+//	GET {prefix}/latency      per-route latency percentiles (AdminOptions.Latency)
+//	POST {prefix}/latency/reset
+//	                          clear the latency histograms
+//	GET {prefix}/services     per-service call counts and windowed percentiles
+//	                          (AdminOptions.Services)
+//	POST {prefix}/services/reset
+//	                          clear the service stats
+//	GET {prefix}/shapes       sampled request body shapes (AdminOptions.BodySampler)
+//	GET {prefix}/loadshed     shed ratio and the load it was derived from
+//	                          (AdminOptions.LoadShedder)
+//	GET {prefix}/concurrency  in-flight and queued requests of each
+//	                          AdminOptions.ConcurrencyLimiters entry
+//
+// Requests must carry "Authorization: Bearer <token>"; an empty token panics.
+func (r *Router) MountAdmin(opts AdminOptions) {
+	if opts.Token == "" {
+		panic("golitekit: MountAdmin requires a token")
+	}
+	prefix := opts.Prefix
+	if prefix == "" {
+		prefix = DefaultAdminPrefix
+	}
+	prefix = "/" + strings.Trim(prefix, "/")
+
+	g := r.Group(prefix).Use(adminAuthMiddleware(opts.Token))
+	g.GET("/routes", HandlerFunc(func(ctx *Context) error {
+		return ctx.JSON(http.StatusOK, r.Routes())
+	}))
+	g.GET("/middlewares", HandlerFunc(func(ctx *Context) error {
+		return ctx.JSON(http.StatusOK, r.Middlewares())
+	}))
+	g.GET("/config", HandlerFunc(func(ctx *Context) error {
+		return adminConfig(ctx, opts.Config)
+	}))
+	g.GET("/goroutines", HandlerFunc(func(ctx *Context) error {
+		return writeProfile(ctx, "goroutine", 2)
+	}))
+	g.GET("/heap", HandlerFunc(func(ctx *Context) error {
+		debug := 0
+		if ctx.Query("debug") == "1" {
+			debug = 1
+		}
+		runtime.GC()
+		return writeProfile(ctx, "heap", debug)
+	}))
+	g.GET("/ratelimit", HandlerFunc(func(ctx *Context) error {
+		stats := make(map[string]RateLimiterStats, len(opts.RateLimiters))
+		for name, limiter := range opts.RateLimiters {
+			stats[name] = limiter.Stats()
+		}
+		return ctx.JSON(http.StatusOK, stats)
+	}))
+	g.GET("/loglevel", HandlerFunc(func(ctx *Context) error {
+		lc, err := levelController(r.services)
+		if err != nil {
+			return err
+		}
+		return ctx.JSON(http.StatusOK, map[string]string{"level": logger.LevelName(lc.Level())})
+	}))
+	g.PUT("/loglevel", HandlerFunc(func(ctx *Context) error {
+		lc, err := levelController(r.services)
+		if err != nil {
+			return err
+		}
+		var body struct {
+			Level string `json:"level"`
+		}
+		if err := json.NewDecoder(http.MaxBytesReader(ctx.ResponseWriter(), ctx.Request().Body, 1<<10)).Decode(&body); err != nil {
+			return ErrBadRequest(`body must be {"level": "DEBUG|INFO|..."}`, err)
+		}
+		level, err := logger.ParseLevel(body.Level)
+		if err != nil {
+			return ErrBadRequest(err.Error(), err)
+		}
+		lc.SetLevel(level)
+		return ctx.JSON(http.StatusOK, map[string]string{"level": logger.LevelName(level)})
+	}))
+	g.GET("/subsystems", HandlerFunc(func(ctx *Context) error {
+		names := r.services.Subsystems()
+		if len(opts.RateLimiters) > 0 && !slices.Contains(names, SubsystemRateLimit) {
+			names = append(names, SubsystemRateLimit)
+			slices.Sort(names)
+		}
+		return ctx.JSON(http.StatusOK, names)
+	}))
+	g.POST("/subsystems/{name}/restart", HandlerFunc(func(ctx *Context) error {
+		name := ctx.Param("name")
+		start := time.Now()
+		if name == SubsystemRateLimit && len(opts.RateLimiters) > 0 {
+			for _, limiter := range opts.RateLimiters {
+				limiter.Reset()
+			}
+		} else if err := r.services.Restart(ctx.Request().Context(), name); err != nil {
+			if errors.Is(err, ErrUnknownSubsystem) {
+				return ErrNotFound("Unknown subsystem: "+name, err)
+			}
+			return NewAppError(http.StatusServiceUnavailable, "Restart of "+name+" failed: "+err.Error(), err)
+		}
+		return ctx.JSON(http.StatusOK, map[string]any{
+			"subsystem": name,
+			"restarted": true,
+			"elapsed":   time.Since(start).String(),
+		})
+	}))
+	g.GET("/allocs", HandlerFunc(func(ctx *Context) error {
+		return ctx.JSON(http.StatusOK, ReadAllocStats())
+	}))
+	if opts.Latency != nil {
+		g.GET("/latency", HandlerFunc(func(ctx *Context) error {
+			routes, since := opts.Latency.Snapshot()
+			return ctx.JSON(http.StatusOK, map[string]any{
+				"since":  since,
+				"routes": routes,
+			})
+		}))
+		g.POST("/latency/reset", HandlerFunc(func(ctx *Context) error {
+			opts.Latency.Reset()
+			return ctx.JSON(http.StatusOK, map[string]bool{"reset": true})
+		}))
+	}
+	if opts.Services != nil {
+		g.GET("/services", HandlerFunc(func(ctx *Context) error {
+			return ctx.JSON(http.StatusOK, map[string]any{
+				"window":   opts.Services.Window().String(),
+				"services": opts.Services.Snapshot(),
+			})
+		}))
+		g.POST("/services/reset", HandlerFunc(func(ctx *Context) error {
+			opts.Services.Reset()
+			return ctx.JSON(http.StatusOK, map[string]bool{"reset": true})
+		}))
+	}
+	if opts.BodySampler != nil {
+		g.GET("/shapes", HandlerFunc(func(ctx *Context) error {
+			return ctx.JSON(http.StatusOK, opts.BodySampler.Shapes())
+		}))
+	}
+	if opts.LoadShedder != nil {
+		g.GET("/loadshed", HandlerFunc(func(ctx *Context) error {
+			return ctx.JSON(http.StatusOK, opts.LoadShedder.Stats())
+		}))
+	}
+	if len(opts.ConcurrencyLimiters) > 0 {
+		g.GET("/concurrency", HandlerFunc(func(ctx *Context) error {
+			stats := make(map[string]ConcurrencyLimiterStats, len(opts.ConcurrencyLimiters))
+			for name, limiter := range opts.ConcurrencyLimiters {
+				stats[name] = limiter.Stats()
+			}
+			return ctx.JSON(http.StatusOK, stats)
+		}))
+	}
+}
+
+func adminAuthMiddleware(token string) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			if !validBearerToken(r, token) {
+				return ErrUnauthorized("Unauthorized", nil).WithHeader("WWW-Authenticate", "Bearer")
+			}
+			return next(ctx, w, r)
+		}
+	}
+}
+
+func levelController(services *Services) (logger.LevelController, error) {
+	lc, ok := services.Logger().(logger.LevelController)
+	if !ok {
+		return nil, NewAppError(http.StatusNotImplemented, "Logger does not support runtime level changes", nil)
+	}
+	return lc, nil
+}
+
+func adminConfig(ctx *Context, appConfig any) error {
+	snapshot := env.Snapshot()
+	// The key is not one redactJSONBody knows.
+	if snapshot != nil && snapshot.PprofToken != "" {
+		snapshot.PprofToken = "[REDACTED]"
+	}
+	dump := map[string]any{"env": snapshot}
+	if appConfig != nil {
+		dump["config"] = appConfig
+	}
+	raw, err := json.Marshal(dump)
+	if err != nil {
+		return ErrInternal("Failed to encode config", err)
+	}
+	redacted, ok := redactJSONBody(raw)
+	if !ok {
+		return ErrInternal("Failed to redact config", nil)
+	}
+	return ctx.JSON(http.StatusOK, json.RawMessage(redacted))
+}
+
+func writeProfile(ctx *Context, name string, debug int) error {
+	w := ctx.ResponseWriter()
+	if debug > 0 {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	} else {
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Header().Set("Content-Disposition", `attachment; filename="`+name+`.pprof"`)
+	}
+	w.WriteHeader(http.StatusOK)
+	return pprof.Lookup(name).WriteTo(w, debug)
+}
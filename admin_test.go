@@ -0,0 +1,135 @@
+package golitekit
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hansir-hsj/GoLiteKit/env"
+
+	"github.com/hansir-hsj/GoLiteKit/logger"
+)
+
+func newTestAdminApp(t *testing.T, opts AdminOptions) (*App, logger.Logger) {
+	t.Helper()
+	l, err := logger.NewLogger()
+	if err != nil {
+		t.Fatalf("NewLogger: %v", err)
+	}
+	app := NewApp(WithLogger(l))
+	app.GET("/users/{id}", HandlerFunc(func(ctx *Context) error { return nil }))
+	opts.Token = "secret"
+	app.MountAdmin(opts)
+	return app, l
+}
+
+func adminRequest(app *App, method, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer secret")
+	rec := httptest.NewRecorder()
+	app.Handler().ServeHTTP(rec, req)
+	return rec
+}
+
+func TestAdmin_RequiresToken(t *testing.T) {
+	app, _ := newTestAdminApp(t, AdminOptions{})
+	rec := httptest.NewRecorder()
+	app.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/_admin/routes", nil))
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("status = %d, want 401", rec.Code)
+	}
+}
+
+func TestAdmin_ListsRoutesAndMiddlewares(t *testing.T) {
+	app, _ := newTestAdminApp(t, AdminOptions{})
+
+	rec := adminRequest(app, http.MethodGet, "/_admin/routes", "")
+	var routes []RouteInfo
+	if err := json.Unmarshal(rec.Body.Bytes(), &routes); err != nil {
+		t.Fatalf("decode routes: %v (%s)", err, rec.Body)
+	}
+	found := false
+	for _, r := range routes {
+		if r.Method == http.MethodGet && r.Pattern == "/users/{id}" {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("routes = %+v, want GET /users/{id}", routes)
+	}
+
+	rec = adminRequest(app, http.MethodGet, "/_admin/middlewares", "")
+	if !strings.Contains(rec.Body.String(), ".TimeoutMiddleware") {
+		t.Fatalf("middlewares = %s, want TimeoutMiddleware", rec.Body)
+	}
+}
+
+func TestAdmin_ConfigIsRedacted(t *testing.T) {
+	app, _ := newTestAdminApp(t, AdminOptions{Config: map[string]string{"dsn": "db:3306", "password": "hunter2"}})
+	rec := adminRequest(app, http.MethodGet, "/_admin/config", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	if strings.Contains(rec.Body.String(), "hunter2") || !strings.Contains(rec.Body.String(), "db:3306") {
+		t.Fatalf("config = %s, want password redacted", rec.Body)
+	}
+}
+
+func TestAdmin_ConfigRedactsPprofToken(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.toml")
+	if err := os.WriteFile(path, []byte("[HttpServer]\naddr = \":0\"\npprofToken = \"pprof-s3cret\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := env.Init(path); err != nil {
+		t.Fatalf("env.Init: %v", err)
+	}
+	app, _ := newTestAdminApp(t, AdminOptions{})
+	rec := adminRequest(app, http.MethodGet, "/_admin/config", "")
+	if rec.Code != http.StatusOK || strings.Contains(rec.Body.String(), "pprof-s3cret") {
+		t.Fatalf("config = %d %s, want pprofToken redacted", rec.Code, rec.Body)
+	}
+}
+
+func TestAdmin_ChangesLogLevel(t *testing.T) {
+	app, l := newTestAdminApp(t, AdminOptions{})
+	rec := adminRequest(app, http.MethodPut, "/_admin/loglevel", `{"level":"warn"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200 (%s)", rec.Code, rec.Body)
+	}
+	if got := l.(logger.LevelController).Level(); got != slog.LevelWarn {
+		t.Fatalf("level = %v, want WARN", got)
+	}
+
+	rec = adminRequest(app, http.MethodPut, "/_admin/loglevel", `{"level":"loud"}`)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("invalid level status = %d, want 400", rec.Code)
+	}
+}
+
+func TestAdmin_RateLimiterStatsAndDumps(t *testing.T) {
+	limiter := NewRateLimiter(10, 5)
+	app, _ := newTestAdminApp(t, AdminOptions{RateLimiters: map[string]*RateLimiter{"api": limiter}})
+
+	rec := adminRequest(app, http.MethodGet, "/_admin/ratelimit", "")
+	var stats map[string]RateLimiterStats
+	if err := json.Unmarshal(rec.Body.Bytes(), &stats); err != nil {
+		t.Fatalf("decode stats: %v", err)
+	}
+	if stats["api"].Burst != 5 {
+		t.Fatalf("stats = %+v, want api burst 5", stats)
+	}
+
+	rec = adminRequest(app, http.MethodGet, "/_admin/goroutines", "")
+	if !strings.Contains(rec.Body.String(), "goroutine") {
+		t.Fatalf("goroutine dump = %q", rec.Body.String())
+	}
+	rec = adminRequest(app, http.MethodGet, "/_admin/heap", "")
+	if rec.Code != http.StatusOK || rec.Body.Len() == 0 {
+		t.Fatalf("heap status = %d len = %d", rec.Code, rec.Body.Len())
+	}
+}
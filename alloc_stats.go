@@ -0,0 +1,210 @@
+package golitekit
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// maxPooledJSONBuffer caps the buffers returned to the JSON buffer pool so one
+// large response does not pin its memory for the life of the process.
+const maxPooledJSONBuffer = 64 << 10
+
+// jsonSizeBounds are the upper bounds of the JSON response size histogram.
+var jsonSizeBounds = []uint64{256, 1 << 10, 4 << 10, 16 << 10, 64 << 10, 256 << 10, 1 << 20}
+
+// allocCounters are the cheap, always-on counters behind ReadAllocStats.
+type allocCounters struct {
+	mu          sync.Mutex
+	controllers map[reflect.Type]*cloneCounter
+
+	bufferGets   atomic.Uint64
+	bufferMisses atomic.Uint64
+
+	jsonEncodes atomic.Uint64
+	jsonBytes   atomic.Uint64
+	jsonMax     atomic.Uint64
+	jsonSizes   [8]atomic.Uint64 // len(jsonSizeBounds)+1, the last one unbounded
+}
+
+var allocStats allocCounters
+
+type cloneCounter struct {
+	typ    reflect.Type
+	clones atomic.Uint64
+}
+
+// controllerCounter returns the clone counter of a controller struct type.
+func (a *allocCounters) controllerCounter(t reflect.Type) *cloneCounter {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.controllers == nil {
+		a.controllers = make(map[reflect.Type]*cloneCounter)
+	}
+	c := a.controllers[t]
+	if c == nil {
+		c = &cloneCounter{typ: t}
+		a.controllers[t] = c
+	}
+	return c
+}
+
+var jsonBufferPool = sync.Pool{
+	New: func() any {
+		allocStats.bufferMisses.Add(1)
+		return new(bytes.Buffer)
+	},
+}
+
+func getJSONBuffer() *bytes.Buffer {
+	allocStats.bufferGets.Add(1)
+	buf := jsonBufferPool.Get().(*bytes.Buffer)
+	buf.Reset()
+	return buf
+}
+
+func putJSONBuffer(buf *bytes.Buffer) {
+	if buf == nil || buf.Cap() > maxPooledJSONBuffer {
+		return
+	}
+	jsonBufferPool.Put(buf)
+}
+
+// encodeJSON encodes v like json.Marshal into a pooled buffer. The returned
+// bytes alias buf, which the caller releases with putJSONBuffer.
+func encodeJSON(v any) (data []byte, buf *bytes.Buffer, err error) {
+	buf = getJSONBuffer()
+	if err := json.NewEncoder(buf).Encode(v); err != nil {
+		putJSONBuffer(buf)
+		return nil, nil, err
+	}
+	data = bytes.TrimSuffix(buf.Bytes(), []byte("\n"))
+	allocStats.recordJSON(uint64(len(data)))
+	return data, buf, nil
+}
+
+func (a *allocCounters) recordJSON(n uint64) {
+	a.jsonEncodes.Add(1)
+	a.jsonBytes.Add(n)
+	for {
+		cur := a.jsonMax.Load()
+		if n <= cur || a.jsonMax.CompareAndSwap(cur, n) {
+			break
+		}
+	}
+	i := sort.Search(len(jsonSizeBounds), func(i int) bool { return n <= jsonSizeBounds[i] })
+	a.jsonSizes[i].Add(1)
+}
+
+// AllocStats summarises the allocations the framework makes on behalf of
+// requests, to guide pool sizing and controller design.
+type AllocStats struct {
+	Controllers    []ControllerCloneStats `json:"controllers"`
+	JSONBufferPool BufferPoolStats        `json:"json_buffer_pool"`
+	JSONEncode     JSONEncodeStats        `json:"json_encode"`
+	Hints          []string               `json:"hints,omitempty"`
+}
+
+// ControllerCloneStats describes the per-request copy of a controller
+// prototype: every request allocates and copies SizeBytes.
+type ControllerCloneStats struct {
+	Type       string `json:"type"`
+	SizeBytes  uint64 `json:"size_bytes"`
+	Clones     uint64 `json:"clones"`
+	TotalBytes uint64 `json:"total_bytes"`
+}
+
+// BufferPoolStats reports how often the JSON buffer pool had a buffer ready.
+type BufferPoolStats struct {
+	Gets    uint64  `json:"gets"`
+	Misses  uint64  `json:"misses"`
+	HitRate float64 `json:"hit_rate"`
+}
+
+// JSONEncodeStats describes the sizes of encoded JSON responses.
+type JSONEncodeStats struct {
+	Count     uint64           `json:"count"`
+	MeanBytes float64          `json:"mean_bytes"`
+	MaxBytes  uint64           `json:"max_bytes"`
+	Sizes     []SizeBucketStat `json:"sizes"`
+}
+
+// SizeBucketStat counts responses of at most LE bytes and larger than the
+// previous bucket; LE is 0 for the last, unbounded bucket.
+type SizeBucketStat struct {
+	LE    uint64 `json:"le"`
+	Count uint64 `json:"count"`
+}
+
+// ReadAllocStats returns the allocation counters collected since start.
+func ReadAllocStats() AllocStats {
+	var s AllocStats
+
+	allocStats.mu.Lock()
+	for _, c := range allocStats.controllers {
+		size := uint64(c.typ.Size())
+		clones := c.clones.Load()
+		s.Controllers = append(s.Controllers, ControllerCloneStats{
+			Type:       c.typ.String(),
+			SizeBytes:  size,
+			Clones:     clones,
+			TotalBytes: size * clones,
+		})
+	}
+	allocStats.mu.Unlock()
+	sort.Slice(s.Controllers, func(i, j int) bool {
+		return s.Controllers[i].TotalBytes > s.Controllers[j].TotalBytes
+	})
+
+	s.JSONBufferPool.Gets = allocStats.bufferGets.Load()
+	s.JSONBufferPool.Misses = min(allocStats.bufferMisses.Load(), s.JSONBufferPool.Gets)
+	if s.JSONBufferPool.Gets > 0 {
+		s.JSONBufferPool.HitRate = 1 - float64(s.JSONBufferPool.Misses)/float64(s.JSONBufferPool.Gets)
+	}
+
+	s.JSONEncode.Count = allocStats.jsonEncodes.Load()
+	s.JSONEncode.MaxBytes = allocStats.jsonMax.Load()
+	if s.JSONEncode.Count > 0 {
+		s.JSONEncode.MeanBytes = float64(allocStats.jsonBytes.Load()) / float64(s.JSONEncode.Count)
+	}
+	for i := range allocStats.jsonSizes {
+		var le uint64
+		if i < len(jsonSizeBounds) {
+			le = jsonSizeBounds[i]
+		}
+		s.JSONEncode.Sizes = append(s.JSONEncode.Sizes, SizeBucketStat{LE: le, Count: allocStats.jsonSizes[i].Load()})
+	}
+
+	s.Hints = allocHints(s)
+	return s
+}
+
+// allocHints turns the counters into tuning suggestions.
+func allocHints(s AllocStats) []string {
+	var hints []string
+	for _, c := range s.Controllers {
+		if c.SizeBytes >= 1<<10 && c.Clones > 0 {
+			hints = append(hints, fmt.Sprintf(
+				"%s copies %d bytes per request; keep large read-only state behind a pointer or in a service", c.Type, c.SizeBytes))
+		}
+	}
+	if p := s.JSONBufferPool; p.Gets >= 1000 && p.HitRate < 0.5 {
+		hints = append(hints, fmt.Sprintf(
+			"JSON buffer pool hit rate is %.0f%%; GC is emptying the pool, consider raising GOGC or GOMEMLIMIT", p.HitRate*100))
+	}
+	if s.JSONEncode.MaxBytes > maxPooledJSONBuffer {
+		var large uint64
+		for _, b := range s.JSONEncode.Sizes {
+			if b.LE == 0 || b.LE > maxPooledJSONBuffer {
+				large += b.Count
+			}
+		}
+		hints = append(hints, fmt.Sprintf(
+			"%d JSON responses exceeded %d KiB and bypassed the buffer pool; paginate or stream them", large, maxPooledJSONBuffer>>10))
+	}
+	return hints
+}
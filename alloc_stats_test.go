@@ -0,0 +1,77 @@
+package golitekit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type allocStatsController struct {
+	BaseController
+	table [2048]byte
+}
+
+func (c *allocStatsController) Serve(ctx context.Context) error {
+	return c.JSON(http.StatusOK, map[string]string{"html": "<b>", "n": strings.Repeat("x", 300)})
+}
+
+func TestAllocStatsCountClonesAndJSON(t *testing.T) {
+	before := ReadAllocStats()
+
+	app := NewApp()
+	app.GET("/alloc", &allocStatsController{})
+	app.MountAdmin(AdminOptions{Token: "secret"})
+
+	var body string
+	for i := 0; i < 3; i++ {
+		rec := httptest.NewRecorder()
+		app.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/alloc", nil))
+		body = rec.Body.String()
+	}
+	want, _ := json.Marshal(map[string]string{"html": "<b>", "n": strings.Repeat("x", 300)})
+	if body != string(want) {
+		t.Fatalf("body = %q, want json.Marshal output %q", body, want)
+	}
+
+	rec := adminRequest(app, http.MethodGet, "/_admin/allocs", "")
+	var stats AllocStats
+	if err := json.Unmarshal(rec.Body.Bytes(), &stats); err != nil {
+		t.Fatalf("decode: %v (%s)", err, rec.Body)
+	}
+
+	var ctrl *ControllerCloneStats
+	for i := range stats.Controllers {
+		if stats.Controllers[i].Type == "golitekit.allocStatsController" {
+			ctrl = &stats.Controllers[i]
+		}
+	}
+	if ctrl == nil || ctrl.Clones != 3 || ctrl.SizeBytes < 2048 || ctrl.TotalBytes != 3*ctrl.SizeBytes {
+		t.Fatalf("controller stats = %+v", ctrl)
+	}
+	if stats.JSONEncode.Count < before.JSONEncode.Count+3 || stats.JSONBufferPool.Gets < before.JSONBufferPool.Gets+3 {
+		t.Fatalf("json stats = %+v, pool = %+v", stats.JSONEncode, stats.JSONBufferPool)
+	}
+	found := false
+	for _, h := range stats.Hints {
+		found = found || strings.Contains(h, "allocStatsController")
+	}
+	if !found {
+		t.Fatalf("hints = %v, want a hint for the large controller", stats.Hints)
+	}
+}
+
+func TestAllocStatsJSONSizeBuckets(t *testing.T) {
+	var a allocCounters
+	for _, n := range []uint64{10, 256, 257, 2 << 20} {
+		a.recordJSON(n)
+	}
+	if a.jsonSizes[0].Load() != 2 || a.jsonSizes[1].Load() != 1 || a.jsonSizes[len(jsonSizeBounds)].Load() != 1 {
+		t.Fatalf("unexpected buckets")
+	}
+	if a.jsonMax.Load() != 2<<20 {
+		t.Fatalf("max = %d", a.jsonMax.Load())
+	}
+}
@@ -6,6 +6,9 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
 	"sync"
 
 	"github.com/hansir-hsj/GoLiteKit/env"
@@ -19,22 +22,61 @@ type App struct {
 
 	serverMu sync.Mutex
 	server   *Server
+
+	maintenanceOnce sync.Once
+	maintenance     *Maintenance
 }
 
-// NewApp creates an App with dependency injection.
+// NewApp creates an App with dependency injection. It panics on options
+// NewAppWithOptions would reject.
 func NewApp(opts ...ServiceOption) *App {
+	app, err := NewAppWithOptions(opts...)
+	if err != nil {
+		panic("golitekit: " + err.Error())
+	}
+	return app
+}
+
+// NewAppWithOptions creates an App configured only by opts, without env, and
+// reports invalid options as an error: a server config that cannot be
+// served or a middleware preset breaking the default order.
+func NewAppWithOptions(opts ...ServiceOption) (*App, error) {
 	services := &Services{}
 	for _, opt := range opts {
 		opt(services)
 	}
+	if err := services.validateServerConfig(); err != nil {
+		return nil, err
+	}
 
-	router := NewRouter(services)
-	router.Use(defaultMiddlewares(services, defaultMiddlewareOptions{})...)
+	reportRecoveredRequests(services)
+	services.registerLoggers()
 
+	router, err := newAppRouter(services, DefaultMiddlewareOptions{})
+	if err != nil {
+		return nil, err
+	}
 	return &App{
 		services: services,
 		router:   router,
+	}, nil
+}
+
+// newAppRouter creates the router of an app on the configured mux, with the
+// default middlewares and the presets applied.
+func newAppRouter(services *Services, opts DefaultMiddlewareOptions) (*Router, error) {
+	router := NewRouter(services)
+	if services.mux != nil {
+		router.mux = services.mux
+	}
+	router.middlewares = defaultMiddlewares(services, opts)
+	for _, preset := range services.middlewarePresets {
+		preset(&router.middlewares)
 	}
+	if err := router.middlewares.Validate(); err != nil {
+		return nil, err
+	}
+	return router, nil
 }
 
 // NewAppFromConfig creates an App from an env config file.
@@ -56,6 +98,9 @@ func NewAppFromConfig(confPath string, opts ...ServiceOption) (*App, error) {
 	for _, opt := range opts {
 		opt(services)
 	}
+	if err := services.validateServerConfig(); err != nil {
+		return nil, err
+	}
 
 	if services.logger == nil {
 		loggerCfg := env.LoggerConfigFile()
@@ -71,6 +116,28 @@ func NewAppFromConfig(confPath string, opts ...ServiceOption) (*App, error) {
 		}
 		services.logger = l
 	}
+	if loggerCfg := env.LoggerConfigFile(); loggerCfg != "" {
+		levels, err := logger.LoadStatusLevels(loggerCfg)
+		if err != nil {
+			return nil, err
+		}
+		loggerOptions.WarnStatus = levels.Warn
+		loggerOptions.ErrorStatus = levels.Error
+		loggerOptions.LogErrorBodies = levels.Bodies
+
+		named, err := logger.NewNamedLoggers(loggerCfg)
+		if err != nil {
+			return nil, err
+		}
+		for name, l := range named {
+			if services.NamedLogger(name) != nil {
+				l.Close()
+				continue
+			}
+			WithNamedLogger(name, l)(services)
+		}
+	}
+	services.registerLoggers()
 	if services.panicLogger == nil {
 		pl, err := logger.NewPanicLogger(env.LoggerConfigFile())
 		if err != nil {
@@ -79,14 +146,36 @@ func NewAppFromConfig(confPath string, opts ...ServiceOption) (*App, error) {
 		services.panicLogger = pl
 	}
 
-	router := NewRouter(services)
-	router.Use(defaultMiddlewares(services, defaultMiddlewareOptions{
-		logger:  loggerOptions,
-		timeout: timeoutOptions,
-	})...)
+	if services.journal == nil {
+		if path := env.JournalFile(); path != "" {
+			journal, err := OpenRequestJournal(RequestJournalOptions{Path: path, Slots: env.JournalSlots()})
+			if err != nil {
+				return nil, err
+			}
+			services.journal = journal
+		}
+	}
+	if services.profiler == nil {
+		services.profiler = profilerFromEnv()
+	}
+	reportRecoveredRequests(services)
+
+	router, err := newAppRouter(services, DefaultMiddlewareOptions{
+		Logger:  loggerOptions,
+		Timeout: timeoutOptions,
+		LogID:   LogIDOptions{Header: env.LogIDHeader()},
+	})
+	if err != nil {
+		return nil, err
+	}
 
 	if env.EnablePprof() {
-		router.MountPprof(PprofOptions{LoopbackOnly: true})
+		services.pprofToken = env.PprofToken()
+		if addr := env.PprofAddr(); addr != "" {
+			services.pprofAddr = addr
+		} else {
+			router.MountPprof(configPprofOptions(services.pprofToken))
+		}
 	}
 
 	if staticDir := env.StaticDir(); staticDir != "" {
@@ -104,42 +193,142 @@ func NewAppFromConfig(confPath string, opts ...ServiceOption) (*App, error) {
 	}, nil
 }
 
-type defaultMiddlewareOptions struct {
-	logger  LoggerOptions
-	timeout TimeoutOptions
+// DefaultMiddlewareOptions configures the default middlewares, see
+// WithDefaultMiddlewareOptions. NewAppFromConfig fills it from the env
+// config first.
+type DefaultMiddlewareOptions struct {
+	Logger  LoggerOptions
+	Timeout TimeoutOptions
+	LogID   LogIDOptions
+	Context ContextMiddlewareOptions
 }
 
-func defaultMiddlewares(services *Services, opts defaultMiddlewareOptions) []Middleware {
-	middlewares := []Middleware{}
+// Names of the default middlewares installed by NewApp and NewAppFromConfig,
+// outermost first; use them with App.EditMiddlewares to reorder, replace or
+// remove a default. Observability, health and journal are only installed
+// when the matching service is configured. The order of some defaults is
+// enforced, see MiddlewareQueue.Validate.
+const (
+	MiddlewareObservability = "observability"
+	MiddlewareHealth        = "health"
+	MiddlewareErrorHandler  = "error_handler"
+	MiddlewareLogger        = "logger"
+	MiddlewareLogID         = "logid"
+	MiddlewareJournal       = "journal"
+	MiddlewareTimeout       = "timeout"
+	MiddlewareContext       = "context"
+)
+
+func defaultMiddlewares(services *Services, opts DefaultMiddlewareOptions) MiddlewareQueue {
+	for _, fn := range services.middlewareDefaults {
+		fn(&opts)
+	}
+	mq := NewMiddlewareQueue()
 	if observabilityMiddleware := services.ObservabilityMiddleware(); observabilityMiddleware != nil {
-		middlewares = append(middlewares, observabilityMiddleware)
-	}
-	middlewares = append(middlewares,
-		ErrorHandlerMiddleware(
-			WithErrorCallback(func(r *http.Request, err *AppError) {
-				if services.logger != nil {
-					services.logger.Warning(r.Context(), "request error: %d %s", err.Code, err.Message)
-				}
-			}),
-			WithPanicCallback(func(r *http.Request, recovered any) {
-				if services.panicLogger != nil {
-					services.panicLogger.Report(r.Context(), recovered)
-				}
-			}),
-		),
-		LoggerAsMiddleware(services.logger, services.panicLogger, opts.logger),
-		LogIDMiddleware(),
-		TimeoutMiddleware(opts.timeout),
-		ContextAsMiddleware(),
-	)
-	return middlewares
+		mq.UseNamed(MiddlewareObservability, observabilityMiddleware)
+	}
+	if monitor := services.HealthMonitor(); monitor != nil {
+		mq.UseNamed(MiddlewareHealth, monitor.Middleware())
+	}
+	mq.UseNamed(MiddlewareErrorHandler, ErrorHandlerMiddleware(
+		WithErrorCallback(func(r *http.Request, err *AppError) {
+			if services.logger == nil {
+				return
+			}
+			if err.Code >= http.StatusInternalServerError && err.Internal != nil {
+				services.logger.Error(r.Context(), "request error: %d %s: %s", err.Code, err.Message,
+					sanitizeErrorMessage(err.Internal.Error(), DefaultLogBodyLimit))
+				return
+			}
+			services.logger.Warning(r.Context(), "request error: %d %s", err.Code, err.Message)
+		}),
+		WithPanicCallback(func(r *http.Request, recovered any) {
+			services.HealthMonitor().RecordPanic()
+			if services.panicLogger != nil {
+				services.panicLogger.Report(r.Context(), recovered)
+			}
+		}),
+	))
+	loggerOptions := opts.Logger
+	if loggerOptions.AccessLogger == nil {
+		loggerOptions.AccessLogger = services.NamedLogger(logger.AccessLoggerName)
+	}
+	mq.UseNamed(MiddlewareLogger, LoggerAsMiddleware(services.logger, services.panicLogger, loggerOptions))
+	mq.UseNamed(MiddlewareLogID, LogIDMiddleware(opts.LogID))
+	if journal := services.RequestJournal(); journal != nil {
+		mq.UseNamed(MiddlewareJournal, journal.Middleware())
+	}
+	mq.UseNamed(MiddlewareTimeout, TimeoutMiddleware(opts.Timeout))
+	mq.UseNamed(MiddlewareContext, ContextAsMiddleware(opts.Context))
+	return mq
 }
 
-func appServerConfig(configs []ServerConfig) ServerConfig {
+func (a *App) serverConfig(configs []ServerConfig) ServerConfig {
+	config := DefaultServerConfig()
+	if a.services.serverConfig != nil {
+		config = *a.services.serverConfig
+	}
 	if len(configs) > 0 {
-		return configs[0]
+		config = configs[0]
+	}
+	if config.Maintenance == nil {
+		config.Maintenance = a.Maintenance()
+	}
+	if addr := a.services.pprofAddr; addr != "" && !slices.ContainsFunc(config.Listeners, func(l ListenerConfig) bool { return l.Name == "pprof" }) {
+		config.Listeners = append(slices.Clip(config.Listeners), ListenerConfig{Name: "pprof", Addr: addr, Handler: a.PprofHandler(configPprofOptions(a.services.pprofToken))})
+	}
+	return config
+}
+
+// Maintenance returns the app's maintenance switch. It is shared by every
+// server the app starts, so the state survives Shutdown and Start.
+func (a *App) Maintenance() *Maintenance {
+	a.maintenanceOnce.Do(func() {
+		if a.maintenance == nil {
+			a.maintenance = NewMaintenance()
+		}
+	})
+	return a.maintenance
+}
+
+// SetMaintenance turns maintenance mode on or off for the app.
+func (a *App) SetMaintenance(enabled bool) {
+	a.Maintenance().Set(enabled)
+}
+
+// SetLogLevel changes the app logger's minimum level at runtime.
+func (a *App) SetLogLevel(level string) error {
+	l, err := logger.ParseLevel(level)
+	if err != nil {
+		return err
+	}
+	return logger.SetLevel(a.services.Logger(), l)
+}
+
+// ReloadLogLevelOnSignal re-reads the level from the env logger config file
+// ([HttpServer.Logger] configFile) on SIGHUP, or the given signals, until ctx is done.
+func (a *App) ReloadLogLevelOnSignal(ctx context.Context, signals ...os.Signal) error {
+	return logger.ReloadLevelOnSignal(ctx, a.services.Logger(), env.LoggerConfigFile(), signals...)
+}
+
+// SetMockMode makes controllers that declare Examples answer with them
+// instead of running their lifecycle.
+func (a *App) SetMockMode(enabled bool) {
+	a.router.SetMockMode(enabled)
+}
+
+// MountMaintenance registers the token-protected maintenance admin endpoint.
+// The endpoint path is allowlisted so maintenance can be switched off again.
+func (a *App) MountMaintenance(opts MaintenanceAdminOptions) {
+	path := opts.Path
+	if path == "" {
+		path = DefaultMaintenancePath
 	}
-	return DefaultServerConfig()
+	m := a.Maintenance()
+	m.Allow(path)
+	a.router.routesRegistered = true
+	a.router.recordRoute("", path, "maintenance")
+	a.router.mux.Handle(path, a.router.wrapHTTPHandler(m.AdminHandler(opts.Token)))
 }
 
 func (a *App) currentServer() *Server {
@@ -162,40 +351,102 @@ func (a *App) OPTIONS(path string, c any)       { a.router.OPTIONS(path, c) }
 func (a *App) Any(path string, c any)           { a.router.Any(path, c) }
 func (a *App) Use(middlewares ...Middleware)    { a.router.Use(middlewares...) }
 func (a *App) Group(prefix string) *RouterGroup { return a.router.Group(prefix) }
+func (a *App) Host(host string) *RouterGroup    { return a.router.Host(host) }
 func (a *App) Static(urlPath, fsPath string)    { a.router.Static(urlPath, fsPath) }
 func (a *App) Handler() http.Handler            { return a.router.Handler() }
 
+// SetRouterOptions sets the RouterOptions of the app's routes; groups can
+// override them with RouterGroup.SetOptions.
+func (a *App) SetRouterOptions(opts RouterOptions) { a.router.SetOptions(opts) }
+
+// UseNamed adds a global middleware under name.
+func (a *App) UseNamed(name string, m Middleware) { a.router.UseNamed(name, m) }
+
+// EditMiddlewares reorders, replaces or removes global middlewares, including
+// the named defaults (MiddlewareErrorHandler, MiddlewareTimeout, ...). It
+// panics when the result breaks the order MiddlewareQueue.Validate enforces:
+//
+//	app.EditMiddlewares(func(mq *glk.MiddlewareQueue) {
+//		mq.InsertAfter(glk.MiddlewareLogID, "cors", cors)
+//		mq.Replace(glk.MiddlewareTimeout, myTimeout)
+//	})
+func (a *App) EditMiddlewares(fn func(mq *MiddlewareQueue)) { a.router.EditMiddlewares(fn) }
+
+// Middlewares describes the global middlewares, outermost first.
+func (a *App) Middlewares() []MiddlewareDescription { return a.router.Middlewares() }
+
 // MountPprof registers the standard net/http/pprof endpoints on the app router.
 // It only mounts handlers and does not start or block the server; pass PprofOptions
 // to restrict access or change the mount prefix.
 func (a *App) MountPprof(opts ...PprofOptions) { a.router.MountPprof(opts...) }
 
+// MountHealth registers /healthz and /readyz backed by the HealthMonitor passed
+// to WithHealthMonitor; it panics when no monitor was configured.
+// Health routes bypass the middleware chain so probes are not logged or counted,
+// and stay served in maintenance mode.
+func (a *App) MountHealth(opts ...HealthRouteOptions) {
+	if a.services.healthMonitor == nil {
+		panic("golitekit: MountHealth requires WithHealthMonitor")
+	}
+	a.router.MountHealth(a.services.healthMonitor, opts...)
+	if len(opts) > 0 && opts[0].Prefix != "" {
+		prefix := strings.TrimRight(opts[0].Prefix, "/")
+		a.Maintenance().Allow(prefix+"/healthz", prefix+"/readyz")
+	}
+}
+
+// MountAdmin registers the token-protected /_admin introspection group.
+func (a *App) MountAdmin(opts AdminOptions) { a.router.MountAdmin(opts) }
+
+// MountDocs serves the OpenAPI specification and Swagger UI at /docs in
+// development run modes; it reports whether the docs were mounted.
+func (a *App) MountDocs(opts ...DocsOptions) bool { return a.router.MountDocs(opts...) }
+
+// OpenAPI builds the OpenAPI specification of the registered routes.
+func (a *App) OpenAPI(info OpenAPIInfo) *OpenAPISpec { return a.router.OpenAPI(info) }
+
+// MountExports registers the export job routes of the ExportManager passed to
+// WithExportManager; it panics when no manager was configured.
+func (a *App) MountExports() {
+	if a.services.exports == nil {
+		panic("golitekit: MountExports requires WithExportManager")
+	}
+	a.router.MountExports(a.services.exports)
+}
+
 // Start starts the app's HTTP server in the background using the provided config,
-// or DefaultServerConfig when no config is supplied. It returns after the listener
-// is started and does not block while serving requests. If the app already has a
-// running server, Start returns an already-started error.
+// or the WithServerConfig/WithAddr config, or DefaultServerConfig when no config
+// is supplied. It returns after the listener is started and does not block while
+// serving requests. Once listening, Start logs a "startup" record summarising
+// the server, routes, middlewares, loggers and DB/Redis connectivity, see
+// WithStartupLog. If the app already has a running server, Start returns an
+// already-started error.
 func (a *App) Start(configs ...ServerConfig) error {
 	a.serverMu.Lock()
-	defer a.serverMu.Unlock()
-
 	if a.server != nil {
+		a.serverMu.Unlock()
 		return fmt.Errorf("app server already started")
 	}
 
-	srv := NewServer(appServerConfig(configs))
+	srv := NewServer(a.serverConfig(configs))
 	if err := srv.Start(a.router.Handler()); err != nil {
+		a.serverMu.Unlock()
 		return err
 	}
 	a.server = srv
+	a.serverMu.Unlock()
 	go a.clearServerWhenDone(srv)
+	a.startProfiler()
+	a.logStartup(srv)
 	return nil
 }
 
 // ListenAndServe starts the app's HTTP server and blocks until ctx is canceled.
-// It uses the provided config, or DefaultServerConfig when no config is supplied.
-// When ctx is canceled, ListenAndServe performs a graceful shutdown using the
-// configured shutdown timeout, clears the current server, and returns nil for
-// normal context-cancel shutdown. If the app already has a running server,
+// It uses the provided config, or the WithServerConfig/WithAddr config, or
+// DefaultServerConfig when no config is supplied.
+// When ctx is canceled, ListenAndServe stops accepting and drains requests
+// within the configured ShutdownBudgets, clears the current server, and
+// returns nil for a clean shutdown or the ShutdownReport error otherwise. If the app already has a running server,
 // ListenAndServe returns an already-started error.
 func (a *App) ListenAndServe(ctx context.Context, configs ...ServerConfig) error {
 	a.serverMu.Lock()
@@ -204,7 +455,7 @@ func (a *App) ListenAndServe(ctx context.Context, configs ...ServerConfig) error
 		return fmt.Errorf("app server already started")
 	}
 
-	config := appServerConfig(configs)
+	config := a.serverConfig(configs)
 	srv := NewServer(config)
 	if err := srv.Start(a.router.Handler()); err != nil {
 		a.serverMu.Unlock()
@@ -212,6 +463,8 @@ func (a *App) ListenAndServe(ctx context.Context, configs ...ServerConfig) error
 	}
 	a.server = srv
 	a.serverMu.Unlock()
+	a.startProfiler()
+	a.logStartup(srv)
 
 	select {
 	case serveErr := <-srv.Done():
@@ -222,19 +475,14 @@ func (a *App) ListenAndServe(ctx context.Context, configs ...ServerConfig) error
 		a.serverMu.Unlock()
 		return serveErr
 	case <-ctx.Done():
-		shutdownCtx, cancel := context.WithTimeout(context.Background(), srv.config.ShutdownTimeout)
-		defer cancel()
-		if err := srv.Shutdown(shutdownCtx); err != nil {
-			return err
+		err := srv.ShutdownInPhases(srv.config.ShutdownBudgets).Err()
+		a.serverMu.Lock()
+		if a.server == srv {
+			a.server = nil
 		}
+		a.serverMu.Unlock()
+		return err
 	}
-
-	a.serverMu.Lock()
-	if a.server == srv {
-		a.server = nil
-	}
-	a.serverMu.Unlock()
-	return nil
 }
 
 // Shutdown gracefully stops the app's current HTTP server using ctx and clears it
@@ -260,6 +508,95 @@ func (a *App) Shutdown(ctx context.Context) error {
 	return nil
 }
 
+// GracefulShutdown is the process-exit counterpart of Shutdown. It runs every
+// shutdown phase with its own budget: stop accepting and drain requests on
+// the current server, drain export jobs, close the DB, Redis and request
+// journal, and finally close the loggers. Components that miss their budget
+// are abandoned and listed in the report, so later phases still run. The app
+// cannot be restarted afterwards. Zero budgets come from the running server's
+// ShutdownBudgets and ShutdownTimeout, or DefaultServerConfig.
+func (a *App) GracefulShutdown(budgets ShutdownBudgets) *ShutdownReport {
+	total := DefaultServerConfig().ShutdownTimeout
+	a.serverMu.Lock()
+	srv := a.server
+	a.server = nil
+	a.serverMu.Unlock()
+	if srv != nil {
+		total = srv.config.ShutdownTimeout
+		budgets = budgets.or(srv.config.ShutdownBudgets)
+	}
+	budgets = budgets.withDefaults(total)
+
+	report := &ShutdownReport{}
+	if srv != nil {
+		srv.shutdownPhases(budgets, report)
+	}
+
+	s := a.services
+	var jobs []shutdownStep
+	if m := s.Exports(); m != nil {
+		jobs = append(jobs, shutdownStep{name: "exports", fn: func(ctx context.Context) error {
+			if err := m.Drain(ctx); err != nil {
+				return err
+			}
+			return m.Close()
+		}})
+	}
+	if p := s.Profiler(); p != nil {
+		jobs = append(jobs, shutdownStep{name: "profiler", fn: p.Stop})
+	}
+	poolNames := make([]string, 0, len(s.workerPools))
+	for name := range s.workerPools {
+		poolNames = append(poolNames, name)
+	}
+	sort.Strings(poolNames)
+	for _, name := range poolNames {
+		jobs = append(jobs, shutdownStep{name: "workerpool." + name, fn: s.workerPools[name].Drain})
+	}
+	phase := runShutdownPhase(PhaseDrainJobs, budgets.DrainJobs, jobs)
+	if i := slices.Index(phase.Unfinished, "exports"); i >= 0 {
+		phase.Unfinished = slices.Replace(phase.Unfinished, i, i+1, s.Exports().pending()...)
+	}
+	report.Phases = append(report.Phases, phase)
+
+	var pools []shutdownStep
+	if db := s.DB(); db != nil {
+		pools = append(pools, shutdownStep{name: "db", fn: func(context.Context) error {
+			sqlDB, err := db.DB()
+			if err != nil {
+				return err
+			}
+			return sqlDB.Close()
+		}})
+	}
+	if rdb := s.Redis(); rdb != nil {
+		pools = append(pools, shutdownStep{name: "redis", fn: func(context.Context) error { return rdb.Close() }})
+	}
+	if j := s.RequestJournal(); j != nil {
+		pools = append(pools, shutdownStep{name: "journal", fn: func(context.Context) error { return j.Close() }})
+	}
+	report.Phases = append(report.Phases, runShutdownPhase(PhaseClosePools, budgets.ClosePools, pools))
+
+	var logs []shutdownStep
+	if l := s.Logger(); l != nil {
+		logs = append(logs, shutdownStep{name: "logger", fn: func(context.Context) error { return l.Close() }})
+	}
+	names := make([]string, 0, len(s.namedLoggers))
+	for name := range s.namedLoggers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		l := s.namedLoggers[name]
+		logs = append(logs, shutdownStep{name: "logger." + name, fn: func(context.Context) error { return l.Close() }})
+	}
+	if pl := s.PanicLogger(); pl != nil {
+		logs = append(logs, shutdownStep{name: "panic_logger", fn: func(context.Context) error { return pl.Close() }})
+	}
+	report.Phases = append(report.Phases, runShutdownPhase(PhaseFlushLogs, budgets.FlushLogs, logs))
+	return report
+}
+
 func (a *App) clearServerWhenDone(srv *Server) {
 	<-srv.Done()
 
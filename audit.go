@@ -0,0 +1,33 @@
+package golitekit
+
+import (
+	"context"
+
+	"github.com/hansir-hsj/GoLiteKit/audit"
+)
+
+// RecordAudit writes e with audit.Record, filling in the actor, log ID and
+// client IP of the request in ctx where e leaves them empty. Use it for
+// failed or denied actions:
+//
+//	glk.RecordAudit(ctx, audit.Event{Action: "user.delete", Resource: id, Result: audit.Denied})
+func RecordAudit(ctx context.Context, e audit.Event) error {
+	if e.Actor == "" {
+		if p := PrincipalFrom(ctx); p != nil {
+			e.Actor = p.ID
+		}
+	}
+	if e.LogID == "" {
+		e.LogID = EnsureLogID(ctx)
+	}
+	if gcx := GetContext(ctx); e.IP == "" && gcx != nil && gcx.request != nil {
+		e.IP = ByIP(gcx.request)
+	}
+	return audit.Record(ctx, e)
+}
+
+// Audit records a successful action on resource by the caller of the
+// request, see RecordAudit.
+func (c *BaseControllerOf[T]) Audit(ctx context.Context, action, resource string, meta map[string]any) error {
+	return RecordAudit(ctx, audit.Event{Action: action, Resource: resource, Meta: meta})
+}
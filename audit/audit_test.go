@@ -0,0 +1,46 @@
+package audit
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hansir-hsj/GoLiteKit/logger"
+)
+
+type recordLogger struct {
+	logger.Logger
+	msg  string
+	args []any
+}
+
+func (l *recordLogger) Info(ctx context.Context, msg string, args ...any) {
+	l.msg, l.args = msg, args
+}
+
+func TestRecordDefaultsAndSinks(t *testing.T) {
+	defer SetSink(nil)
+	var got Event
+	SetSink(SinkFunc(func(ctx context.Context, e Event) error {
+		got = e
+		return nil
+	}))
+	if err := Record(context.Background(), Event{Actor: "ann", Action: "user.delete", Resource: "42"}); err != nil {
+		t.Fatal(err)
+	}
+	if got.Result != Success || got.Time.IsZero() || got.Action != "user.delete" {
+		t.Errorf("event = %+v, want defaults filled in", got)
+	}
+
+	l := &recordLogger{}
+	logger.Register(logger.AuditLoggerName, l)
+	defer logger.Register(logger.AuditLoggerName, nil)
+	SetSink(nil)
+	Record(context.Background(), Event{Actor: "ann", Action: "user.delete", Resource: "42", Result: Denied, Meta: map[string]any{"reason": "mfa"}})
+	fields := map[string]any{}
+	for i := 0; i+1 < len(l.args); i += 2 {
+		fields[l.args[i].(string)] = l.args[i+1]
+	}
+	if l.msg != "audit" || fields["actor"] != "ann" || fields["result"] != "denied" || fields["meta"] == nil {
+		t.Errorf("audit logger got %q %v", l.msg, fields)
+	}
+}
@@ -0,0 +1,34 @@
+package golitekit
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hansir-hsj/GoLiteKit/audit"
+)
+
+func TestControllerAuditFillsRequestFields(t *testing.T) {
+	defer audit.SetSink(nil)
+	var got audit.Event
+	audit.SetSink(audit.SinkFunc(func(ctx context.Context, e audit.Event) error {
+		got = e
+		return nil
+	}))
+
+	ctx := withContext(context.Background())
+	req := httptest.NewRequest("DELETE", "/users/42", nil)
+	req.RemoteAddr = "10.0.0.7:5123"
+	gcx := GetContext(ctx)
+	gcx.setContextOptions(withRequest(req.WithContext(ctx)))
+	gcx.SetPrincipal(&Principal{ID: "ann"})
+	SetLogID(ctx, "abc123")
+
+	var c BaseController
+	if err := c.Audit(ctx, "user.delete", "users/42", map[string]any{"hard": true}); err != nil {
+		t.Fatal(err)
+	}
+	if got.Actor != "ann" || got.LogID != "abc123" || got.IP != "10.0.0.7" || got.Result != audit.Success || got.Meta["hard"] != true {
+		t.Errorf("event = %+v", got)
+	}
+}
@@ -0,0 +1,193 @@
+package golitekit
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// DefaultAPIKeyHeader is the header APIKeyMiddleware reads by default.
+const DefaultAPIKeyHeader = "X-API-Key"
+
+// Principal is the authenticated caller placed in the Context by the auth
+// middlewares.
+type Principal struct {
+	ID     string            `json:"id"`
+	Scheme string            `json:"scheme"` // "basic", "apikey", ...
+	Roles  []string          `json:"roles,omitempty"`
+	Claims map[string]string `json:"claims,omitempty"`
+}
+
+// HasRole reports whether p has role.
+func (p *Principal) HasRole(role string) bool {
+	if p == nil {
+		return false
+	}
+	for _, r := range p.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// Principal returns the authenticated caller, or nil.
+func (ctx *Context) Principal() *Principal {
+	ctx.dataLock.RLock()
+	defer ctx.dataLock.RUnlock()
+	return ctx.principal
+}
+
+// SetPrincipal sets the authenticated caller, e.g. from a custom auth
+// middleware.
+func (ctx *Context) SetPrincipal(p *Principal) {
+	ctx.dataLock.Lock()
+	defer ctx.dataLock.Unlock()
+	ctx.principal = p
+}
+
+// PrincipalFrom returns the authenticated caller of the request, or nil.
+func PrincipalFrom(ctx context.Context) *Principal {
+	if gcx := GetContext(ctx); gcx != nil {
+		return gcx.Principal()
+	}
+	return nil
+}
+
+// Principal returns the authenticated caller, or nil.
+func (c *BaseControllerOf[T]) Principal() *Principal {
+	if c.gcx == nil {
+		return nil
+	}
+	return c.gcx.Principal()
+}
+
+// BasicAuthValidator checks a username and password. It returns a nil
+// Principal for invalid credentials; errors are reported as 500s unless they
+// are an *AppError.
+type BasicAuthValidator func(ctx context.Context, username, password string) (*Principal, error)
+
+// APIKeyValidator checks an API key, with the same contract as
+// BasicAuthValidator.
+type APIKeyValidator func(ctx context.Context, key string) (*Principal, error)
+
+// BasicAuthOptions configures BasicAuthMiddleware.
+type BasicAuthOptions struct {
+	Realm string // WWW-Authenticate realm, defaults to "Restricted"
+}
+
+// BasicAuthMiddleware authenticates requests with HTTP Basic credentials and
+// places the validated Principal in the Context. Missing or invalid
+// credentials are answered with 401 and a WWW-Authenticate challenge.
+func BasicAuthMiddleware(validate BasicAuthValidator, opts ...BasicAuthOptions) Middleware {
+	realm := "Restricted"
+	if len(opts) > 0 && opts[0].Realm != "" {
+		realm = opts[0].Realm
+	}
+	challenge := "Basic realm=" + strconv.Quote(realm) + `, charset="UTF-8"`
+	return func(next Handler) Handler {
+		return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			user, pass, ok := r.BasicAuth()
+			if !ok {
+				return ErrUnauthorized("Unauthorized", nil).WithHeader("WWW-Authenticate", challenge)
+			}
+			p, err := validate(ctx, user, pass)
+			if err := authenticate(ctx, p, err, "basic"); err != nil {
+				if err.Code == http.StatusUnauthorized {
+					err.WithHeader("WWW-Authenticate", challenge)
+				}
+				return err
+			}
+			return next(ctx, w, r)
+		}
+	}
+}
+
+// APIKeyMiddleware authenticates requests with an API key read from header
+// (DefaultAPIKeyHeader when empty; for "Authorization" a "Bearer " prefix is
+// stripped) and places the validated Principal in the Context.
+func APIKeyMiddleware(header string, validate APIKeyValidator) Middleware {
+	if header == "" {
+		header = DefaultAPIKeyHeader
+	}
+	return func(next Handler) Handler {
+		return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			key := r.Header.Get(header)
+			if strings.EqualFold(header, "Authorization") {
+				key = strings.TrimSpace(strings.TrimPrefix(key, "Bearer "))
+			}
+			if key == "" {
+				return ErrUnauthorized("Unauthorized", nil)
+			}
+			p, err := validate(ctx, key)
+			if err := authenticate(ctx, p, err, "apikey"); err != nil {
+				return err
+			}
+			return next(ctx, w, r)
+		}
+	}
+}
+
+func authenticate(ctx context.Context, p *Principal, err error, scheme string) *AppError {
+	if err != nil {
+		return WrapError(err, http.StatusInternalServerError)
+	}
+	if p == nil {
+		return ErrUnauthorized("Unauthorized", nil)
+	}
+	if p.Scheme == "" {
+		p.Scheme = scheme
+	}
+	if gcx := GetContext(ctx); gcx != nil {
+		gcx.SetPrincipal(p)
+	}
+	return nil
+}
+
+// StaticBasicAuth validates against a fixed username → password map using
+// constant-time comparisons. The username becomes the Principal ID.
+func StaticBasicAuth(users map[string]string) BasicAuthValidator {
+	hashed := make(map[string][32]byte, len(users))
+	for user, pass := range users {
+		hashed[user] = sha256.Sum256([]byte(pass))
+	}
+	return func(ctx context.Context, username, password string) (*Principal, error) {
+		want, ok := hashed[username]
+		got := sha256.Sum256([]byte(password))
+		if subtle.ConstantTimeCompare(got[:], want[:]) != 1 || !ok {
+			return nil, nil
+		}
+		return &Principal{ID: username}, nil
+	}
+}
+
+// StaticAPIKeys validates against a fixed key → principal map. Every key is
+// compared in constant time so timing does not reveal which keys exist.
+func StaticAPIKeys(keys map[string]*Principal) APIKeyValidator {
+	type entry struct {
+		sum       [32]byte
+		principal Principal
+	}
+	entries := make([]entry, 0, len(keys))
+	for key, p := range keys {
+		e := entry{sum: sha256.Sum256([]byte(key))}
+		if p != nil {
+			e.principal = *p
+		}
+		entries = append(entries, e)
+	}
+	return func(ctx context.Context, key string) (*Principal, error) {
+		sum := sha256.Sum256([]byte(key))
+		var found *Principal
+		for i := range entries {
+			if subtle.ConstantTimeCompare(sum[:], entries[i].sum[:]) == 1 {
+				p := entries[i].principal // copy, so requests cannot alter the store
+				found = &p
+			}
+		}
+		return found, nil
+	}
+}
@@ -0,0 +1,105 @@
+package golitekit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func authTestRouter(m Middleware) (*Router, *[]*Principal) {
+	var seen []*Principal
+	r := newTestRouter()
+	r.Use(m)
+	r.GET("/me", HandlerFunc(func(ctx *Context) error {
+		seen = append(seen, ctx.Principal())
+		return ctx.String(http.StatusOK, ctx.Principal().ID)
+	}))
+	return r, &seen
+}
+
+func TestBasicAuthMiddleware(t *testing.T) {
+	r, seen := authTestRouter(BasicAuthMiddleware(StaticBasicAuth(map[string]string{"ann": "s3cret"}), BasicAuthOptions{Realm: "admin"}))
+
+	for _, tc := range []struct {
+		user, pass string
+		set        bool
+		code       int
+	}{
+		{"ann", "s3cret", true, http.StatusOK},
+		{"ann", "wrong", true, http.StatusUnauthorized},
+		{"bob", "s3cret", true, http.StatusUnauthorized},
+		{"", "", false, http.StatusUnauthorized},
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/me", nil)
+		if tc.set {
+			req.SetBasicAuth(tc.user, tc.pass)
+		}
+		rec := httptest.NewRecorder()
+		r.Handler().ServeHTTP(rec, req)
+		if rec.Code != tc.code {
+			t.Errorf("%s/%s = %d, want %d", tc.user, tc.pass, rec.Code, tc.code)
+		}
+		if tc.code == http.StatusUnauthorized && rec.Header().Get("WWW-Authenticate") != `Basic realm="admin", charset="UTF-8"` {
+			t.Errorf("challenge = %q", rec.Header().Get("WWW-Authenticate"))
+		}
+	}
+	if len(*seen) != 1 || (*seen)[0].ID != "ann" || (*seen)[0].Scheme != "basic" {
+		t.Errorf("principals = %+v", *seen)
+	}
+}
+
+func TestAPIKeyMiddleware(t *testing.T) {
+	keys := StaticAPIKeys(map[string]*Principal{"k-1": {ID: "svc-a", Roles: []string{"reader"}}})
+	r, seen := authTestRouter(APIKeyMiddleware("", keys))
+
+	serve := func(header, value string) int {
+		req := httptest.NewRequest(http.MethodGet, "/me", nil)
+		if header != "" {
+			req.Header.Set(header, value)
+		}
+		rec := httptest.NewRecorder()
+		r.Handler().ServeHTTP(rec, req)
+		return rec.Code
+	}
+	if code := serve(DefaultAPIKeyHeader, "k-1"); code != http.StatusOK {
+		t.Errorf("valid key = %d", code)
+	}
+	if code := serve(DefaultAPIKeyHeader, "k-2"); code != http.StatusUnauthorized {
+		t.Errorf("unknown key = %d", code)
+	}
+	if code := serve("", ""); code != http.StatusUnauthorized {
+		t.Errorf("missing key = %d", code)
+	}
+	if p := (*seen)[0]; p.ID != "svc-a" || p.Scheme != "apikey" || !p.HasRole("reader") {
+		t.Errorf("principal = %+v", p)
+	}
+
+	bearer, _ := authTestRouter(APIKeyMiddleware("Authorization", keys))
+	req := httptest.NewRequest(http.MethodGet, "/me", nil)
+	req.Header.Set("Authorization", "Bearer k-1")
+	rec := httptest.NewRecorder()
+	bearer.Handler().ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK || rec.Body.String() != "svc-a" {
+		t.Errorf("bearer = %d %q", rec.Code, rec.Body)
+	}
+}
+
+func TestAPIKeyMiddleware_StoreErrors(t *testing.T) {
+	r, _ := authTestRouter(APIKeyMiddleware("", func(ctx context.Context, key string) (*Principal, error) {
+		if key == "locked" {
+			return nil, ErrForbidden("key disabled", nil)
+		}
+		return nil, errors.New("store unavailable")
+	}))
+	for key, want := range map[string]int{"locked": http.StatusForbidden, "any": http.StatusInternalServerError} {
+		req := httptest.NewRequest(http.MethodGet, "/me", nil)
+		req.Header.Set(DefaultAPIKeyHeader, key)
+		rec := httptest.NewRecorder()
+		r.Handler().ServeHTTP(rec, req)
+		if rec.Code != want {
+			t.Errorf("%s = %d, want %d", key, rec.Code, want)
+		}
+	}
+}
@@ -0,0 +1,276 @@
+// Package authz is a role-based authorization layer on top of the Principal
+// placed in the request Context by the golitekit auth middlewares.
+//
+// Permissions are "resource:action" strings; a role grants permissions
+// directly or by inheriting other roles, and "*" matches any resource or
+// action ("orders:*", "*:read", "*").
+//
+//	a, err := authz.LoadFile("conf/roles.toml")
+//	authz.SetDefault(a)
+//
+//	api.Use(glk.APIKeyMiddleware("", keys))
+//	api.POST("/orders", glk.WithMiddleware(&CreateOrderController{}, authz.RequirePermission("orders:write")))
+//
+//	if authz.Can(ctx, "refund", "orders") { ... }
+package authz
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	glk "github.com/hansir-hsj/GoLiteKit"
+	"github.com/hansir-hsj/GoLiteKit/config"
+)
+
+// Role grants permissions, directly or through inherited roles.
+type Role struct {
+	Name        string   `toml:"name" json:"name" yaml:"name"`
+	Permissions []string `toml:"permissions" json:"permissions" yaml:"permissions"`
+	Inherits    []string `toml:"inherits" json:"inherits,omitempty" yaml:"inherits"`
+}
+
+// Source loads role definitions, e.g. from a database.
+type Source interface {
+	Roles(ctx context.Context) ([]Role, error)
+}
+
+// SourceFunc adapts a function to Source.
+type SourceFunc func(ctx context.Context) ([]Role, error)
+
+func (f SourceFunc) Roles(ctx context.Context) ([]Role, error) { return f(ctx) }
+
+// Authorizer answers permission checks for principals. Its roles can be
+// replaced at runtime with SetRoles or Reload; checks always see a complete
+// set.
+type Authorizer struct {
+	grants atomic.Pointer[map[string][]permission] // role -> flattened permissions
+}
+
+type permission struct {
+	resource, action string
+}
+
+// New returns an Authorizer for roles; see SetRoles.
+func New(roles ...Role) (*Authorizer, error) {
+	a := &Authorizer{}
+	if err := a.SetRoles(roles); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// rolesFile is the layout read by LoadFile:
+//
+//	[[roles]]
+//	name = "editor"
+//	inherits = ["viewer"]
+//	permissions = ["orders:write"]
+type rolesFile struct {
+	Roles []Role `toml:"roles" json:"roles" yaml:"roles"`
+}
+
+// LoadFile returns an Authorizer for the roles of a TOML, YAML or JSON file.
+func LoadFile(path string) (*Authorizer, error) {
+	var file rolesFile
+	if err := config.Parse(path, &file); err != nil {
+		return nil, fmt.Errorf("authz: %w", err)
+	}
+	return New(file.Roles...)
+}
+
+// SetRoles replaces the role definitions. It rejects malformed permissions,
+// duplicate roles, unknown inherited roles and inheritance cycles, keeping
+// the previous roles in that case.
+func (a *Authorizer) SetRoles(roles []Role) error {
+	byName := make(map[string]Role, len(roles))
+	for _, r := range roles {
+		if r.Name == "" {
+			return fmt.Errorf("authz: role without a name")
+		}
+		if _, dup := byName[r.Name]; dup {
+			return fmt.Errorf("authz: duplicate role %q", r.Name)
+		}
+		byName[r.Name] = r
+	}
+
+	grants := make(map[string][]permission, len(roles))
+	var resolve func(name string, path []string) ([]permission, error)
+	resolve = func(name string, path []string) ([]permission, error) {
+		if perms, ok := grants[name]; ok {
+			return perms, nil
+		}
+		for _, p := range path {
+			if p == name {
+				return nil, fmt.Errorf("authz: role inheritance cycle %s", strings.Join(append(path, name), " -> "))
+			}
+		}
+		r, ok := byName[name]
+		if !ok {
+			return nil, fmt.Errorf("authz: role %q inherits unknown role %q", path[len(path)-1], name)
+		}
+		var perms []permission
+		for _, s := range r.Permissions {
+			p, err := parsePermission(s)
+			if err != nil {
+				return nil, fmt.Errorf("authz: role %q: %w", name, err)
+			}
+			perms = append(perms, p)
+		}
+		for _, parent := range r.Inherits {
+			inherited, err := resolve(parent, append(path, name))
+			if err != nil {
+				return nil, err
+			}
+			perms = append(perms, inherited...)
+		}
+		grants[name] = perms
+		return perms, nil
+	}
+	for name := range byName {
+		if _, err := resolve(name, nil); err != nil {
+			return err
+		}
+	}
+	a.grants.Store(&grants)
+	return nil
+}
+
+// Reload replaces the roles with those of src.
+func (a *Authorizer) Reload(ctx context.Context, src Source) error {
+	roles, err := src.Roles(ctx)
+	if err != nil {
+		return fmt.Errorf("authz: load roles: %w", err)
+	}
+	return a.SetRoles(roles)
+}
+
+func parsePermission(s string) (permission, error) {
+	if s == "*" {
+		return permission{"*", "*"}, nil
+	}
+	resource, action, ok := strings.Cut(s, ":")
+	if !ok || resource == "" || action == "" {
+		return permission{}, fmt.Errorf("invalid permission %q, want resource:action", s)
+	}
+	return permission{resource, action}, nil
+}
+
+// Allowed reports whether any of roles grants action on resource.
+func (a *Authorizer) Allowed(roles []string, action, resource string) bool {
+	grants := *a.grants.Load()
+	for _, role := range roles {
+		for _, p := range grants[role] {
+			if (p.resource == "*" || p.resource == resource) && (p.action == "*" || p.action == action) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Can reports whether the request's principal may perform action on
+// resource. It is false without an authenticated principal.
+func (a *Authorizer) Can(ctx context.Context, action, resource string) bool {
+	p := glk.PrincipalFrom(ctx)
+	return p != nil && a.Allowed(p.Roles, action, resource)
+}
+
+// RequirePermission returns a middleware answering 401 without a principal
+// and 403 when the principal lacks perm ("resource:action"). Attach it to a
+// group, or to one route with glk.WithMiddleware or a controller's
+// Middlewares method. It panics on a malformed permission.
+func (a *Authorizer) RequirePermission(perm string) glk.Middleware {
+	return requirePermission(perm, func() *Authorizer { return a })
+}
+
+func requirePermission(perm string, authorizer func() *Authorizer) glk.Middleware {
+	p, err := parsePermission(perm)
+	if err != nil {
+		panic("authz: " + err.Error())
+	}
+	return func(next glk.Handler) glk.Handler {
+		return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			principal := glk.PrincipalFrom(ctx)
+			if principal == nil {
+				return glk.ErrUnauthorized("Unauthorized", nil)
+			}
+			a := authorizer()
+			if a == nil || !a.Allowed(principal.Roles, p.action, p.resource) {
+				return glk.ErrForbidden("missing permission "+perm, nil)
+			}
+			return next(ctx, w, r)
+		}
+	}
+}
+
+var (
+	defaultMu         sync.RWMutex
+	defaultAuthorizer *Authorizer
+)
+
+// SetDefault sets the Authorizer used by the package-level Can and
+// RequirePermission.
+func SetDefault(a *Authorizer) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	defaultAuthorizer = a
+}
+
+// Default returns the Authorizer set with SetDefault, or nil.
+func Default() *Authorizer {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+	return defaultAuthorizer
+}
+
+// Can checks a permission with the default Authorizer; it is false when no
+// default is set.
+func Can(ctx context.Context, action, resource string) bool {
+	a := Default()
+	return a != nil && a.Can(ctx, action, resource)
+}
+
+// RequirePermission is Authorizer.RequirePermission on the default
+// Authorizer, resolved per request so it can be registered before
+// SetDefault. Requests are denied while no default is set.
+func RequirePermission(perm string) glk.Middleware {
+	return requirePermission(perm, Default)
+}
+
+// SQLSource loads roles from rows of (role, permission) returned by query,
+// e.g. "SELECT role, permission FROM role_permissions". A row with an empty
+// permission declares a role without permissions. With gorm, pass db.DB().
+func SQLSource(db *sql.DB, query string) Source {
+	return SourceFunc(func(ctx context.Context) ([]Role, error) {
+		rows, err := db.QueryContext(ctx, query)
+		if err != nil {
+			return nil, err
+		}
+		defer rows.Close()
+
+		var roles []Role
+		index := map[string]int{}
+		for rows.Next() {
+			var role string
+			var perm sql.NullString
+			if err := rows.Scan(&role, &perm); err != nil {
+				return nil, err
+			}
+			i, ok := index[role]
+			if !ok {
+				i = len(roles)
+				index[role] = i
+				roles = append(roles, Role{Name: role})
+			}
+			if perm.String != "" {
+				roles[i].Permissions = append(roles[i].Permissions, perm.String)
+			}
+		}
+		return roles, rows.Err()
+	})
+}
@@ -0,0 +1,183 @@
+package authz
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	glk "github.com/hansir-hsj/GoLiteKit"
+)
+
+var testRoles = []Role{
+	{Name: "viewer", Permissions: []string{"orders:read", "*:list"}},
+	{Name: "clerk", Permissions: []string{"orders:write"}, Inherits: []string{"viewer"}},
+	{Name: "admin", Permissions: []string{"*"}},
+}
+
+func TestAuthorizerAllowed(t *testing.T) {
+	a, err := New(testRoles...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		roles            []string
+		action, resource string
+		want             bool
+	}{
+		{[]string{"viewer"}, "read", "orders", true},
+		{[]string{"viewer"}, "write", "orders", false},
+		{[]string{"viewer"}, "list", "users", true},
+		{[]string{"clerk"}, "write", "orders", true},
+		{[]string{"clerk"}, "read", "orders", true},
+		{[]string{"clerk"}, "delete", "orders", false},
+		{[]string{"admin"}, "delete", "users", true},
+		{[]string{"ghost", "viewer"}, "read", "orders", true},
+		{nil, "read", "orders", false},
+	} {
+		if got := a.Allowed(tc.roles, tc.action, tc.resource); got != tc.want {
+			t.Errorf("Allowed(%v, %s, %s) = %v, want %v", tc.roles, tc.action, tc.resource, got, tc.want)
+		}
+	}
+}
+
+func TestSetRolesRejectsInvalidDefinitions(t *testing.T) {
+	a, err := New(testRoles...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, roles := range map[string][]Role{
+		"cycle":      {{Name: "a", Inherits: []string{"b"}}, {Name: "b", Inherits: []string{"a"}}},
+		"unknown":    {{Name: "a", Inherits: []string{"missing"}}},
+		"duplicate":  {{Name: "a"}, {Name: "a"}},
+		"permission": {{Name: "a", Permissions: []string{"orders"}}},
+		"unnamed":    {{Permissions: []string{"orders:read"}}},
+	} {
+		if err := a.SetRoles(roles); err == nil {
+			t.Errorf("%s: SetRoles accepted %+v", name, roles)
+		}
+	}
+	if !a.Allowed([]string{"clerk"}, "write", "orders") {
+		t.Error("previous roles lost after a rejected update")
+	}
+}
+
+func TestLoadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "roles.toml")
+	data := `
+[[roles]]
+name = "viewer"
+permissions = ["orders:read"]
+
+[[roles]]
+name = "clerk"
+inherits = ["viewer"]
+permissions = ["orders:write"]
+`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	a, err := LoadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !a.Allowed([]string{"clerk"}, "read", "orders") {
+		t.Error("inherited permission from file not granted")
+	}
+}
+
+func TestReload(t *testing.T) {
+	a, _ := New()
+	src := SourceFunc(func(ctx context.Context) ([]Role, error) {
+		return []Role{{Name: "viewer", Permissions: []string{"orders:read"}}}, nil
+	})
+	if err := a.Reload(context.Background(), src); err != nil {
+		t.Fatal(err)
+	}
+	if !a.Allowed([]string{"viewer"}, "read", "orders") {
+		t.Error("reloaded roles not applied")
+	}
+}
+
+func authzTestRouter(keys map[string]*glk.Principal, mws ...glk.Middleware) *glk.Router {
+	r := glk.NewRouter(nil)
+	r.Use(glk.ErrorHandlerMiddleware())
+	r.Use(glk.ContextAsMiddleware())
+	r.Use(glk.APIKeyMiddleware("", glk.StaticAPIKeys(keys)))
+	r.POST("/orders", glk.WithMiddleware(glk.HandlerFunc(func(ctx *glk.Context) error {
+		return ctx.String(http.StatusOK, "created")
+	}), mws...))
+	return r
+}
+
+func TestRequirePermission(t *testing.T) {
+	a, err := New(testRoles...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := authzTestRouter(map[string]*glk.Principal{
+		"clerk-key":  {ID: "c", Roles: []string{"clerk"}},
+		"viewer-key": {ID: "v", Roles: []string{"viewer"}},
+	}, a.RequirePermission("orders:write"))
+
+	for key, want := range map[string]int{"clerk-key": http.StatusOK, "viewer-key": http.StatusForbidden, "": http.StatusUnauthorized} {
+		req := httptest.NewRequest(http.MethodPost, "/orders", nil)
+		if key != "" {
+			req.Header.Set(glk.DefaultAPIKeyHeader, key)
+		}
+		rec := httptest.NewRecorder()
+		r.Handler().ServeHTTP(rec, req)
+		if rec.Code != want {
+			t.Errorf("key %q: code = %d, want %d (%s)", key, rec.Code, want, rec.Body)
+		}
+	}
+}
+
+func TestRequirePermissionPanicsOnMalformedPermission(t *testing.T) {
+	defer func() {
+		if p := recover(); p == nil || !strings.Contains(p.(string), "invalid permission") {
+			t.Errorf("recover() = %v", p)
+		}
+	}()
+	RequirePermission("orders")
+}
+
+func TestDefaultAuthorizer(t *testing.T) {
+	t.Cleanup(func() { SetDefault(nil) })
+	SetDefault(nil)
+
+	var allowed []bool
+	r := authzTestRouter(map[string]*glk.Principal{"clerk-key": {ID: "c", Roles: []string{"clerk"}}},
+		RequirePermission("orders:write"),
+		func(next glk.Handler) glk.Handler {
+			return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+				allowed = append(allowed, Can(ctx, "read", "orders"), Can(ctx, "delete", "orders"))
+				return next(ctx, w, r)
+			}
+		})
+	serve := func() int {
+		req := httptest.NewRequest(http.MethodPost, "/orders", nil)
+		req.Header.Set(glk.DefaultAPIKeyHeader, "clerk-key")
+		rec := httptest.NewRecorder()
+		r.Handler().ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	if code := serve(); code != http.StatusForbidden {
+		t.Errorf("without a default: code = %d, want 403", code)
+	}
+	a, _ := New(testRoles...)
+	SetDefault(a)
+	if code := serve(); code != http.StatusOK {
+		t.Errorf("with a default: code = %d, want 200", code)
+	}
+	if len(allowed) != 2 || !allowed[0] || allowed[1] {
+		t.Errorf("Can = %v, want [true false]", allowed)
+	}
+	if Can(context.Background(), "read", "orders") {
+		t.Error("Can allowed a request without a principal")
+	}
+}
@@ -0,0 +1,181 @@
+package golitekit
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/hansir-hsj/GoLiteKit/logger"
+)
+
+const (
+	// BaggageHeader is the W3C baggage header: "key1=value1,key2=value2".
+	BaggageHeader = "Baggage"
+	// BaggageHeaderPrefix carries one item per header, e.g.
+	// "X-Glk-Baggage-Tenant: acme" for the item "tenant".
+	BaggageHeaderPrefix = "X-Glk-Baggage-"
+
+	// MaxBaggageItems bounds the items of one request; further keys are
+	// dropped.
+	MaxBaggageItems = 32
+	// MaxBaggageValueLen bounds an item value; longer values are dropped.
+	MaxBaggageValueLen = 256
+)
+
+// SetBaggage sets key to value on the request's baggage, which
+// BaggageTransport forwards to downstream services and which is logged with
+// every record of the request as "baggage.<key>". Keys are limited to
+// [A-Za-z0-9._-]; invalid keys, oversized values and keys beyond
+// MaxBaggageItems are ignored. An empty value removes key.
+func SetBaggage(ctx context.Context, key, value string) {
+	gcx := GetContext(ctx)
+	if gcx == nil || !validBaggageKey(key) || len(value) > MaxBaggageValueLen {
+		return
+	}
+
+	gcx.dataLock.Lock()
+	if value == "" {
+		delete(gcx.baggage, key)
+		gcx.dataLock.Unlock()
+		return
+	}
+	if _, ok := gcx.baggage[key]; !ok && len(gcx.baggage) >= MaxBaggageItems {
+		gcx.dataLock.Unlock()
+		return
+	}
+	if gcx.baggage == nil {
+		gcx.baggage = make(map[string]string)
+	}
+	gcx.baggage[key] = value
+	gcx.dataLock.Unlock()
+
+	logger.AddInfo(ctx, "baggage."+key, value)
+}
+
+// GetBaggage returns the baggage item key of the request, or "".
+func GetBaggage(ctx context.Context, key string) string {
+	gcx := GetContext(ctx)
+	if gcx == nil {
+		return ""
+	}
+	gcx.dataLock.RLock()
+	defer gcx.dataLock.RUnlock()
+	return gcx.baggage[key]
+}
+
+// BaggageItems returns a copy of the request's baggage.
+func BaggageItems(ctx context.Context) map[string]string {
+	gcx := GetContext(ctx)
+	if gcx == nil {
+		return nil
+	}
+	gcx.dataLock.RLock()
+	defer gcx.dataLock.RUnlock()
+	if len(gcx.baggage) == 0 {
+		return nil
+	}
+	items := make(map[string]string, len(gcx.baggage))
+	for k, v := range gcx.baggage {
+		items[k] = v
+	}
+	return items
+}
+
+// BaggageMiddleware adopts the baggage of incoming requests, from the W3C
+// baggage header and from X-Glk-Baggage-* headers, which win on conflict.
+// Register it after LogIDMiddleware so the items are logged.
+func BaggageMiddleware() Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			ctx = withContext(ctx)
+			for _, v := range r.Header.Values(BaggageHeader) {
+				for key, value := range parseBaggage(v) {
+					SetBaggage(ctx, key, value)
+				}
+			}
+			for name, values := range r.Header {
+				if key, ok := strings.CutPrefix(name, BaggageHeaderPrefix); ok && len(values) > 0 {
+					SetBaggage(ctx, strings.ToLower(key), values[0])
+				}
+			}
+			return next(ctx, w, r.WithContext(ctx))
+		}
+	}
+}
+
+// InjectBaggage writes the baggage of ctx into h as a W3C baggage header.
+func InjectBaggage(ctx context.Context, h http.Header) {
+	if v := formatBaggage(BaggageItems(ctx)); v != "" {
+		h.Set(BaggageHeader, v)
+	}
+}
+
+// BaggageTransport returns a RoundTripper that forwards the baggage of each
+// request's context, for the http.Client used to call other services. A nil
+// base uses http.DefaultTransport.
+func BaggageTransport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return baggageTransport{base: base}
+}
+
+type baggageTransport struct {
+	base http.RoundTripper
+}
+
+func (t baggageTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	if v := formatBaggage(BaggageItems(r.Context())); v != "" {
+		r = r.Clone(r.Context())
+		r.Header.Set(BaggageHeader, v)
+	}
+	return t.base.RoundTrip(r)
+}
+
+// parseBaggage decodes a W3C baggage header; member properties after ";"
+// are ignored.
+func parseBaggage(header string) map[string]string {
+	items := make(map[string]string)
+	for _, member := range strings.Split(header, ",") {
+		member, _, _ = strings.Cut(member, ";")
+		key, value, ok := strings.Cut(member, "=")
+		if !ok {
+			continue
+		}
+		value, err := url.PathUnescape(strings.TrimSpace(value))
+		if err != nil {
+			continue
+		}
+		items[strings.TrimSpace(key)] = value
+	}
+	return items
+}
+
+func formatBaggage(items map[string]string) string {
+	if len(items) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(items))
+	for k := range items {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var b strings.Builder
+	for i, k := range keys {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(k)
+		b.WriteByte('=')
+		b.WriteString(url.PathEscape(items[k]))
+	}
+	return b.String()
+}
+
+// validBaggageKey accepts up to 64 characters of [A-Za-z0-9._-], like
+// validLogID, so keys are safe as header names and log keys.
+func validBaggageKey(key string) bool {
+	return validLogID(key)
+}
@@ -0,0 +1,90 @@
+package golitekit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/hansir-hsj/GoLiteKit/logger"
+)
+
+func TestBaggageMiddleware_ExtractsHeaders(t *testing.T) {
+	var got map[string]string
+	var logged any
+	handler := LogIDMiddleware()(BaggageMiddleware()(func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		got = BaggageItems(r.Context())
+		if lc, ok := r.Context().Value(logger.LoggerKey).(*logger.LoggerContext); ok {
+			for f := lc.Head; f != nil; f = f.Next {
+				if f.Key == "baggage.tenant" {
+					logged = f.Value
+				}
+			}
+		}
+		return nil
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Baggage", "tenant=globex, exp=a%2Fb;ttl=60,bad key=x,novalue")
+	req.Header.Set("X-Glk-Baggage-Tenant", "acme")
+	if err := handler(req.Context(), httptest.NewRecorder(), req); err != nil {
+		t.Fatal(err)
+	}
+
+	if got["tenant"] != "acme" || got["exp"] != "a/b" || len(got) != 2 {
+		t.Fatalf("baggage = %v", got)
+	}
+	if logged != "acme" {
+		t.Errorf("logged baggage.tenant = %v, want acme", logged)
+	}
+}
+
+func TestSetBaggage_Limits(t *testing.T) {
+	ctx := withContext(context.Background())
+	SetBaggage(ctx, "tenant", strings.Repeat("x", MaxBaggageValueLen+1))
+	SetBaggage(ctx, "bad:key", "x")
+	if items := BaggageItems(ctx); len(items) != 0 {
+		t.Fatalf("invalid items kept: %v", items)
+	}
+	for i := 0; i < MaxBaggageItems+5; i++ {
+		SetBaggage(ctx, "k"+strconv.Itoa(i), "v")
+	}
+	if n := len(BaggageItems(ctx)); n != MaxBaggageItems {
+		t.Fatalf("items = %d, want %d", n, MaxBaggageItems)
+	}
+	SetBaggage(ctx, "k0", "")
+	if GetBaggage(ctx, "k0") != "" {
+		t.Error("empty value did not remove the item")
+	}
+}
+
+func TestBaggageTransport_ForwardsItems(t *testing.T) {
+	var header string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Get(BaggageHeader)
+	}))
+	defer srv.Close()
+
+	ctx := withContext(context.Background())
+	SetBaggage(ctx, "tenant", "acme")
+	SetBaggage(ctx, "exp", "a/b c")
+	client := &http.Client{Transport: BaggageTransport(nil)}
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if header != "exp=a%2Fb%20c,tenant=acme" {
+		t.Fatalf("baggage header = %q", header)
+	}
+	if req.Header.Get(BaggageHeader) != "" {
+		t.Error("transport modified the caller's request")
+	}
+	if items := parseBaggage(header); items["exp"] != "a/b c" {
+		t.Errorf("round trip = %v", items)
+	}
+}
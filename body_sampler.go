@@ -0,0 +1,308 @@
+package golitekit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Kinds of a BodyShape.
+const (
+	ShapeObject  = "object"
+	ShapeMap     = "map" // object whose keys look like data, not field names
+	ShapeArray   = "array"
+	ShapeString  = "string"
+	ShapeInteger = "integer"
+	ShapeNumber  = "number"
+	ShapeBool    = "bool"
+	ShapeNull    = "null"
+	ShapeMixed   = "mixed" // incompatible kinds were seen
+)
+
+const (
+	DefaultSamplerMaxBodyBytes = 64 << 10
+	DefaultSamplerMaxSamples   = 1000
+	// DefaultSamplerMaxFields bounds the keys kept per object; objects with
+	// more keys are recorded as maps.
+	DefaultSamplerMaxFields = 64
+)
+
+// BodyShape is the anonymised structure of sampled JSON values: kinds and
+// field names only, never values.
+type BodyShape struct {
+	Kind     string                `json:"kind"`
+	Nullable bool                  `json:"nullable,omitempty"`
+	Samples  int                   `json:"samples"`
+	Fields   map[string]*BodyShape `json:"fields,omitempty"`
+	Elem     *BodyShape            `json:"elem,omitempty"`
+}
+
+// Optional reports whether field was missing from some samples of s.
+func (s *BodyShape) Optional(field string) bool {
+	f := s.Fields[field]
+	return f == nil || f.Samples < s.Samples
+}
+
+// BodySamplerOptions configures a BodySampler.
+type BodySamplerOptions struct {
+	SampleRate   float64 // fraction of requests sampled, defaults to 1
+	MaxBodyBytes int64   // larger bodies are skipped, defaults to DefaultSamplerMaxBodyBytes
+	MaxSamples   int     // per route; later requests are not sampled, defaults to DefaultSamplerMaxSamples
+	MaxFields    int     // per object, defaults to DefaultSamplerMaxFields
+}
+
+// BodySampler records the shapes of JSON request bodies per route so `glk
+// infer types` can turn untyped map[string]any handlers into typed
+// BaseControllerOf[T] bodies. It is a development tool: every sampled body is
+// read into memory and decoded a second time.
+type BodySampler struct {
+	opts BodySamplerOptions
+
+	mu     sync.Mutex
+	routes map[string]*BodyShape
+}
+
+// NewBodySampler creates a BodySampler, filling zero-valued options with defaults.
+func NewBodySampler(opts ...BodySamplerOptions) *BodySampler {
+	var opt BodySamplerOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if opt.SampleRate <= 0 {
+		opt.SampleRate = 1
+	}
+	if opt.MaxBodyBytes <= 0 {
+		opt.MaxBodyBytes = DefaultSamplerMaxBodyBytes
+	}
+	if opt.MaxSamples <= 0 {
+		opt.MaxSamples = DefaultSamplerMaxSamples
+	}
+	if opt.MaxFields <= 0 {
+		opt.MaxFields = DefaultSamplerMaxFields
+	}
+	return &BodySampler{opts: opt, routes: make(map[string]*BodyShape)}
+}
+
+// Middleware samples JSON bodies of routed requests and restores the body
+// for the handler.
+func (s *BodySampler) Middleware() Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			if s.wants(r) {
+				s.sample(r)
+			}
+			return next(ctx, w, r)
+		}
+	}
+}
+
+func (s *BodySampler) wants(r *http.Request) bool {
+	if r.Pattern == "" || r.Body == nil || r.Body == http.NoBody {
+		return false
+	}
+	if r.ContentLength > s.opts.MaxBodyBytes {
+		return false
+	}
+	if ct := r.Header.Get("Content-Type"); !strings.Contains(strings.ToLower(ct), "json") {
+		return false
+	}
+	if s.opts.SampleRate < 1 && rand.Float64() >= s.opts.SampleRate {
+		return false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	shape := s.routes[r.Pattern]
+	return shape == nil || shape.Samples < s.opts.MaxSamples
+}
+
+func (s *BodySampler) sample(r *http.Request) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, s.opts.MaxBodyBytes+1))
+	r.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(body), r.Body), r.Body}
+	if err != nil || int64(len(body)) > s.opts.MaxBodyBytes {
+		return
+	}
+	var v any
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	if dec.Decode(&v) != nil {
+		return
+	}
+	s.Record(r.Pattern, v)
+}
+
+// Record merges the shape of v, a value decoded by encoding/json with
+// UseNumber, into route's shape.
+func (s *BodySampler) Record(route string, v any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.routes[route] = mergeShape(s.routes[route], v, s.opts.MaxFields)
+}
+
+// Shapes returns a copy of the sampled shapes by route pattern.
+func (s *BodySampler) Shapes() map[string]*BodyShape {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make(map[string]*BodyShape, len(s.routes))
+	for route, shape := range s.routes {
+		out[route] = shape.clone()
+	}
+	return out
+}
+
+// Reset drops every sampled shape.
+func (s *BodySampler) Reset() {
+	s.mu.Lock()
+	s.routes = make(map[string]*BodyShape)
+	s.mu.Unlock()
+}
+
+func (s *BodyShape) clone() *BodyShape {
+	if s == nil {
+		return nil
+	}
+	c := *s
+	if s.Fields != nil {
+		c.Fields = make(map[string]*BodyShape, len(s.Fields))
+		for k, f := range s.Fields {
+			c.Fields[k] = f.clone()
+		}
+	}
+	c.Elem = s.Elem.clone()
+	return &c
+}
+
+// mergeShape folds one value into shape (nil for the first sample).
+func mergeShape(shape *BodyShape, v any, maxFields int) *BodyShape {
+	if shape == nil {
+		shape = &BodyShape{}
+	}
+	shape.Samples++
+	kind := valueKind(v)
+	if kind == ShapeNull {
+		shape.Nullable = true
+		if shape.Kind == "" {
+			shape.Kind = ShapeNull
+		}
+		return shape
+	}
+	if kind == ShapeObject && len(v.(map[string]any)) > maxFields {
+		kind = ShapeMap
+	}
+	shape.Kind = mergeKinds(shape.Kind, kind)
+
+	switch shape.Kind {
+	case ShapeObject:
+		if shape.Fields == nil {
+			shape.Fields = make(map[string]*BodyShape)
+		}
+		for k, fv := range v.(map[string]any) {
+			shape.Fields[k] = mergeShape(shape.Fields[k], fv, maxFields)
+		}
+		if len(shape.Fields) > maxFields {
+			shape.Kind = ShapeMap
+			shape.Elem = mapElem(shape.Fields)
+			shape.Fields = nil
+		}
+	case ShapeMap:
+		if kind == ShapeObject || kind == ShapeMap {
+			for _, fv := range v.(map[string]any) {
+				shape.Elem = mergeShape(shape.Elem, fv, maxFields)
+			}
+		}
+		if shape.Fields != nil {
+			shape.Elem = mergeShapes(shape.Elem, mapElem(shape.Fields))
+			shape.Fields = nil
+		}
+	case ShapeArray:
+		for _, ev := range v.([]any) {
+			shape.Elem = mergeShape(shape.Elem, ev, maxFields)
+		}
+	}
+	return shape
+}
+
+// mapElem merges the field shapes of an object that turned out to be a map.
+func mapElem(fields map[string]*BodyShape) *BodyShape {
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var elem *BodyShape
+	for _, k := range keys {
+		elem = mergeShapes(elem, fields[k])
+	}
+	return elem
+}
+
+// mergeShapes combines two shapes of the same position.
+func mergeShapes(a, b *BodyShape) *BodyShape {
+	if a == nil {
+		return b.clone()
+	}
+	if b == nil {
+		return a
+	}
+	a.Samples += b.Samples
+	a.Nullable = a.Nullable || b.Nullable
+	switch {
+	case b.Kind == ShapeNull:
+		return a
+	case a.Kind == ShapeNull:
+		a.Kind = b.Kind
+	default:
+		a.Kind = mergeKinds(a.Kind, b.Kind)
+	}
+	for k, f := range b.Fields {
+		if a.Fields == nil {
+			a.Fields = make(map[string]*BodyShape)
+		}
+		a.Fields[k] = mergeShapes(a.Fields[k], f)
+	}
+	a.Elem = mergeShapes(a.Elem, b.Elem)
+	return a
+}
+
+func mergeKinds(a, b string) string {
+	switch {
+	case a == "" || a == ShapeNull || a == b:
+		return b
+	case a == ShapeInteger && b == ShapeNumber, a == ShapeNumber && b == ShapeInteger:
+		return ShapeNumber
+	case a == ShapeMap && b == ShapeObject, a == ShapeObject && b == ShapeMap:
+		return ShapeMap
+	}
+	return ShapeMixed
+}
+
+func valueKind(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ShapeNull
+	case map[string]any:
+		return ShapeObject
+	case []any:
+		return ShapeArray
+	case string:
+		return ShapeString
+	case bool:
+		return ShapeBool
+	case json.Number:
+		if _, err := v.Int64(); err == nil {
+			return ShapeInteger
+		}
+		return ShapeNumber
+	case float64:
+		return ShapeNumber
+	}
+	return ShapeMixed
+}
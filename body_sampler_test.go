@@ -0,0 +1,98 @@
+package golitekit
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestBodySamplerMiddlewareRecordsShapes(t *testing.T) {
+	sampler := NewBodySampler()
+	app := NewApp()
+	app.Use(sampler.Middleware())
+	var seen []string
+	app.POST("/users", HandlerFunc(func(ctx *Context) error {
+		body, _ := io.ReadAll(ctx.Request().Body)
+		seen = append(seen, string(body))
+		return ctx.JSON(http.StatusOK, nil)
+	}))
+	app.MountAdmin(AdminOptions{Token: "secret", BodySampler: sampler})
+
+	bodies := []string{
+		`{"name":"ann","age":30,"tags":["a"],"address":{"city":"x"},"score":1}`,
+		`{"name":"bob","age":41,"tags":[],"address":null,"score":1.5,"extra":true}`,
+	}
+	for _, b := range bodies {
+		req := httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(b))
+		req.Header.Set("Content-Type", "application/json")
+		app.Handler().ServeHTTP(httptest.NewRecorder(), req)
+	}
+	plain := httptest.NewRequest(http.MethodPost, "/users", strings.NewReader("name=ann"))
+	plain.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	app.Handler().ServeHTTP(httptest.NewRecorder(), plain)
+
+	if len(seen) != 3 || seen[0] != bodies[0] || seen[1] != bodies[1] {
+		t.Fatalf("handler saw bodies %q", seen)
+	}
+
+	rec := adminRequest(app, http.MethodGet, "/_admin/shapes", "")
+	var shapes map[string]*BodyShape
+	if err := json.Unmarshal(rec.Body.Bytes(), &shapes); err != nil {
+		t.Fatalf("decode: %v (%s)", err, rec.Body)
+	}
+	shape := shapes["POST /users"]
+	if shape == nil || shape.Kind != ShapeObject || shape.Samples != 2 {
+		t.Fatalf("shape = %+v", shape)
+	}
+	want := map[string]string{
+		"name": ShapeString, "age": ShapeInteger, "tags": ShapeArray,
+		"address": ShapeObject, "score": ShapeNumber, "extra": ShapeBool,
+	}
+	for field, kind := range want {
+		if f := shape.Fields[field]; f == nil || f.Kind != kind {
+			t.Errorf("field %s = %+v, want kind %s", field, f, kind)
+		}
+	}
+	if !shape.Fields["address"].Nullable || shape.Optional("address") || !shape.Optional("extra") {
+		t.Errorf("address nullable/optional or extra optional not detected: %+v", shape.Fields)
+	}
+	if strings.Contains(rec.Body.String(), "ann") {
+		t.Errorf("shapes leak values: %s", rec.Body)
+	}
+}
+
+func TestBodySamplerMergeRules(t *testing.T) {
+	s := NewBodySampler(BodySamplerOptions{MaxFields: 2, MaxSamples: 3})
+	decode := func(src string) any {
+		var v any
+		dec := json.NewDecoder(strings.NewReader(src))
+		dec.UseNumber()
+		if err := dec.Decode(&v); err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+	s.Record("r", decode(`{"a":1,"b":{"x":1,"y":2,"z":3}}`))
+	s.Record("r", decode(`{"a":"s","b":{"w":4}}`))
+	s.Record("r", decode(`{"a":1,"b":{"x":1},"c":1}`))
+
+	shape := s.Shapes()["r"]
+	if shape.Kind != ShapeMap || shape.Elem == nil || shape.Elem.Kind != ShapeMixed {
+		t.Fatalf("object over MaxFields should become a map of mixed values: %+v", shape)
+	}
+
+	s.Reset()
+	s.Record("r", decode(`{"a":1,"b":{"x":1,"y":2,"z":3}}`))
+	s.Record("r", decode(`{"a":"s","b":{"w":4}}`))
+	shape = s.Shapes()["r"]
+	if shape.Fields["a"].Kind != ShapeMixed {
+		t.Errorf("a = %+v, want mixed", shape.Fields["a"])
+	}
+	b := shape.Fields["b"]
+	if b.Kind != ShapeMap || b.Elem == nil || b.Elem.Kind != ShapeInteger || b.Elem.Samples != 4 {
+		t.Errorf("b = %+v, want a map of integers", b)
+	}
+}
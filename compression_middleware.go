@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"compress/gzip"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net"
@@ -14,6 +15,10 @@ import (
 
 // CompressionMiddleware compresses responses with gzip when the client accepts it.
 // level is optional; defaults to gzip.DefaultCompression.
+//
+// Streaming responses (text/event-stream and NDJSON) are sent uncompressed so
+// every flushed event reaches the client immediately; the decision is made
+// from the Content-Type when the response headers are written.
 func CompressionMiddleware(level ...int) Middleware {
 	return func(next Handler) Handler {
 		return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
@@ -31,9 +36,7 @@ func CompressionMiddleware(level ...int) Middleware {
 				return ErrInternal("gzip init failed", err)
 			}
 
-			w.Header().Set("Content-Encoding", "gzip")
 			w.Header().Set("Vary", "Accept-Encoding")
-			w.Header().Del("Content-Length")
 
 			gzw := &gzipResponseWriter{
 				ResponseWriter: w,
@@ -53,32 +56,67 @@ func CompressionMiddleware(level ...int) Middleware {
 	}
 }
 
+// uncompressedContentTypes are streamed as is: gzip would hold events back
+// until its buffer fills.
+var uncompressedContentTypes = []string{"text/event-stream", NDJSONContentType}
+
+func isStreamingContentType(ct string) bool {
+	mediaType, _, _ := strings.Cut(ct, ";")
+	mediaType = strings.TrimSpace(mediaType)
+	for _, t := range uncompressedContentTypes {
+		if strings.EqualFold(mediaType, t) {
+			return true
+		}
+	}
+	return false
+}
+
 type gzipResponseWriter struct {
 	http.ResponseWriter
 	io.Writer
 	bodyAllowed bool
+	// wroteHeader is set once the encoding was chosen; compress tells which.
+	wroteHeader bool
+	compress    bool
 }
 
 func (w *gzipResponseWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		if w.Header().Get("Content-Type") == "" {
+			w.Header().Set("Content-Type", http.DetectContentType(b))
+		}
+		w.WriteHeader(http.StatusOK)
+	}
 	if !w.bodyAllowed {
 		return 0, http.ErrBodyNotAllowed
 	}
-	if w.Header().Get("Content-Type") == "" {
-		w.Header().Set("Content-Type", http.DetectContentType(b))
+	if !w.compress {
+		return w.ResponseWriter.Write(b)
 	}
 	return w.Writer.Write(b)
 }
 
 func (w *gzipResponseWriter) WriteHeader(statusCode int) {
-	if statusCode == http.StatusNoContent || statusCode == http.StatusNotModified {
+	// Informational responses leave the encoding to the final one.
+	if w.wroteHeader || statusCode < http.StatusOK {
+		w.ResponseWriter.WriteHeader(statusCode)
+		return
+	}
+	w.wroteHeader = true
+	switch {
+	case statusCode == http.StatusNoContent || statusCode == http.StatusNotModified:
 		w.bodyAllowed = false
-		w.Header().Del("Content-Encoding")
+	case isStreamingContentType(w.Header().Get("Content-Type")), w.Header().Get("Content-Encoding") != "":
+	default:
+		w.compress = true
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Header().Del("Content-Length")
 	}
 	w.ResponseWriter.WriteHeader(statusCode)
 }
 
 func (w *gzipResponseWriter) Close() error {
-	if !w.bodyAllowed {
+	if !w.compress {
 		return nil
 	}
 	if closer, ok := w.Writer.(io.Closer); ok {
@@ -89,26 +127,28 @@ func (w *gzipResponseWriter) Close() error {
 
 // Flush flushes the gzip buffer first, then the underlying connection.
 func (w *gzipResponseWriter) Flush() {
-	if gz, ok := w.Writer.(*gzip.Writer); ok {
-		if !w.bodyAllowed {
-			return
-		}
+	if err := w.FlushError(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		fmt.Fprintf(os.Stderr, "gzip flush error: %v\n", err)
+	}
+}
+
+// FlushError is Flush reporting the error; http.ResponseController.Flush
+// calls it.
+func (w *gzipResponseWriter) FlushError() error {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if gz, ok := w.Writer.(*gzip.Writer); ok && w.compress {
 		if err := gz.Flush(); err != nil {
-			fmt.Fprintf(os.Stderr, "gzip flush error: %v\n", err)
-			return
+			return err
 		}
 	}
-	if f, ok := w.ResponseWriter.(http.Flusher); ok {
-		f.Flush()
-	}
+	return http.NewResponseController(w.ResponseWriter).Flush()
 }
 
 // Hijack forwards WebSocket / HTTP upgrade requests to the underlying ResponseWriter.
 func (w *gzipResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
-	if hj, ok := w.ResponseWriter.(http.Hijacker); ok {
-		return hj.Hijack()
-	}
-	return nil, nil, fmt.Errorf("underlying ResponseWriter does not support Hijack")
+	return http.NewResponseController(w.ResponseWriter).Hijack()
 }
 
 func (w *gzipResponseWriter) Unwrap() http.ResponseWriter {
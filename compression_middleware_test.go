@@ -259,3 +259,124 @@ func TestGzipResponseWriter_Hijack_ErrorWhenNotSupported(t *testing.T) {
 
 	mw(inner).ServeHTTP(rec, req)
 }
+
+// compressedStackRouter puts CompressionMiddleware in front of the error
+// handler and Context, the way applications usually stack them.
+func compressedStackRouter() *Router {
+	r := NewRouter(nil)
+	r.Use(CompressionMiddleware())
+	r.Use(ErrorHandlerMiddleware())
+	r.Use(ContextAsMiddleware())
+	return r
+}
+
+func getGzip(t *testing.T, url string) *http.Response {
+	t.Helper()
+	req, _ := http.NewRequest(http.MethodGet, url, nil)
+	req.Header.Set("Accept-Encoding", "gzip") // set by hand, so the transport does not decompress
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+func TestCompressionMiddleware_SSEStreamsUncompressed(t *testing.T) {
+	next := make(chan struct{})
+	r := compressedStackRouter()
+	r.GET("/events", func(ctx *Context) error {
+		sse := ctx.SSEWriter()
+		if err := sse.Send(SSEvent{ID: "1", Data: "first"}); err != nil {
+			return err
+		}
+		<-next // the client must see the first event before the second is sent
+		return sse.Send(SSEvent{ID: "2", Data: "second"})
+	})
+	srv := httptest.NewServer(r.Handler())
+	defer srv.Close()
+
+	resp := getGzip(t, srv.URL+"/events")
+	if ce := resp.Header.Get("Content-Encoding"); ce != "" {
+		t.Fatalf("Content-Encoding = %q, want none for text/event-stream", ce)
+	}
+	if resp.Header.Get("Vary") != "Accept-Encoding" {
+		t.Errorf("Vary = %q, want Accept-Encoding", resp.Header.Get("Vary"))
+	}
+	events := bufio.NewReader(resp.Body)
+	for _, want := range []string{"id: 1\n", "data: first\n"} {
+		if line, err := events.ReadString('\n'); err != nil || line != want {
+			t.Fatalf("line = %q, %v; want %q", line, err, want)
+		}
+	}
+	close(next)
+	rest, _ := io.ReadAll(events)
+	if !strings.Contains(string(rest), "data: second\n") {
+		t.Errorf("rest of stream = %q", rest)
+	}
+}
+
+func TestCompressionMiddleware_NDJSONStreamsUncompressed(t *testing.T) {
+	next := make(chan struct{})
+	r := compressedStackRouter()
+	r.GET("/rows", func(ctx *Context) error {
+		rows := make(chan any)
+		go func() {
+			defer close(rows)
+			rows <- 1
+			<-next
+			rows <- 2
+		}()
+		return ctx.ServeNDJSON(rows)
+	})
+	srv := httptest.NewServer(r.Handler())
+	defer srv.Close()
+
+	resp := getGzip(t, srv.URL+"/rows")
+	if ce := resp.Header.Get("Content-Encoding"); ce != "" {
+		t.Fatalf("Content-Encoding = %q, want none for NDJSON", ce)
+	}
+	lines := bufio.NewReader(resp.Body)
+	if line, err := lines.ReadString('\n'); err != nil || line != "1\n" {
+		t.Fatalf("first line = %q, %v", line, err)
+	}
+	close(next)
+	if line, err := lines.ReadString('\n'); err != nil || line != "2\n" {
+		t.Fatalf("second line = %q, %v", line, err)
+	}
+}
+
+func TestCompressionMiddleware_StackStillCompressesJSON(t *testing.T) {
+	r := compressedStackRouter()
+	r.GET("/data", func(ctx *Context) error {
+		return ctx.JSON(http.StatusOK, map[string]string{"hello": "world"})
+	})
+	srv := httptest.NewServer(r.Handler())
+	defer srv.Close()
+
+	resp := getGzip(t, srv.URL+"/data")
+	if resp.Header.Get("Content-Encoding") != "gzip" {
+		t.Fatalf("Content-Encoding = %q, want gzip", resp.Header.Get("Content-Encoding"))
+	}
+	body, _ := io.ReadAll(resp.Body)
+	if got := gunzip(t, body); !strings.Contains(got, `"hello":"world"`) {
+		t.Errorf("body = %q", got)
+	}
+}
+
+func TestCompressionMiddleware_KeepsExistingContentEncoding(t *testing.T) {
+	inner := Handler(func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("Content-Encoding", "br")
+		w.Write([]byte("already encoded"))
+		return nil
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept-Encoding", "gzip, br")
+	rec := httptest.NewRecorder()
+	CompressionMiddleware()(inner).ServeHTTP(rec, req)
+
+	if rec.Header().Get("Content-Encoding") != "br" || rec.Body.String() != "already encoded" {
+		t.Errorf("got %q %q, want the body passed through", rec.Header().Get("Content-Encoding"), rec.Body)
+	}
+}
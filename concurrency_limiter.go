@@ -0,0 +1,173 @@
+package golitekit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/hansir-hsj/GoLiteKit/logger"
+)
+
+var (
+	errConcurrencyQueueFull    = errors.New("concurrency limit reached and queue full")
+	errConcurrencyQueueTimeout = errors.New("timed out waiting for a concurrency slot")
+)
+
+// ConcurrencyLimiterOptions configures the queue of a ConcurrencyLimiter.
+type ConcurrencyLimiterOptions struct {
+	// MaxQueue is the number of requests that may wait for a slot; further
+	// requests are rejected at once. 0 rejects every request beyond the
+	// limit.
+	MaxQueue int
+	// QueueTimeout bounds the wait for a slot; 0 waits until the request is
+	// cancelled.
+	QueueTimeout time.Duration
+	// Status is the status of rejected requests, defaults to 503; use 429
+	// when the limit is a quota the client should back off from.
+	Status int
+}
+
+// ConcurrencyLimiterStats is a point-in-time view of a ConcurrencyLimiter.
+type ConcurrencyLimiterStats struct {
+	Max      int    `json:"max"`
+	MaxQueue int    `json:"max_queue"`
+	InFlight int    `json:"in_flight"`
+	Queued   int    `json:"queued"`
+	Admitted uint64 `json:"admitted"`
+	// Waited counts the admitted requests that had to queue for a slot.
+	Waited   uint64 `json:"waited"`
+	Rejected uint64 `json:"rejected"`
+	TimedOut uint64 `json:"timed_out"`
+}
+
+// ConcurrencyLimiter bounds the number of requests served at once, for
+// quotas on a scarce resource rather than on request rate. Requests beyond
+// the limit wait in a bounded queue; those that find it full or wait longer
+// than QueueTimeout are answered 503 as shed load, or with Status.
+type ConcurrencyLimiter struct {
+	slots   chan struct{}
+	queue   chan struct{}
+	timeout time.Duration
+	status  int
+
+	admitted atomic.Uint64
+	waited   atomic.Uint64
+	rejected atomic.Uint64
+	timedOut atomic.Uint64
+}
+
+// NewConcurrencyLimiter creates a ConcurrencyLimiter admitting limit
+// requests at once; limit < 1 is treated as 1.
+func NewConcurrencyLimiter(limit int, opts ...ConcurrencyLimiterOptions) *ConcurrencyLimiter {
+	var opt ConcurrencyLimiterOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	return &ConcurrencyLimiter{
+		slots:   make(chan struct{}, max(limit, 1)),
+		queue:   make(chan struct{}, max(opt.MaxQueue, 0)),
+		timeout: opt.QueueTimeout,
+		status:  opt.Status,
+	}
+}
+
+// ConcurrencyLimitMiddleware runs at most limit requests at once, e.g. for
+// one expensive route via WithMiddleware. Up to queueLen further requests
+// wait at most wait for a slot; the others get 429. Use NewConcurrencyLimiter
+// to keep the limiter for Stats and metrics.
+func ConcurrencyLimitMiddleware(limit, queueLen int, wait time.Duration) Middleware {
+	return NewConcurrencyLimiter(limit, ConcurrencyLimiterOptions{
+		MaxQueue:     queueLen,
+		QueueTimeout: wait,
+		Status:       http.StatusTooManyRequests,
+	}).Middleware()
+}
+
+// Acquire takes a slot, waiting in the queue when none is free. It returns a
+// 503 (or Status) AppError when the queue is full, the wait exceeds
+// QueueTimeout or ctx ends; otherwise the caller must call Release.
+func (l *ConcurrencyLimiter) Acquire(ctx context.Context) error {
+	select {
+	case l.slots <- struct{}{}:
+		l.admitted.Add(1)
+		return nil
+	default:
+	}
+
+	select {
+	case l.queue <- struct{}{}:
+	default:
+		l.rejected.Add(1)
+		return l.reject(ctx, errConcurrencyQueueFull)
+	}
+	defer func() { <-l.queue }()
+
+	var expired <-chan time.Time
+	if l.timeout > 0 {
+		timer := time.NewTimer(l.timeout)
+		defer timer.Stop()
+		expired = timer.C
+	}
+	select {
+	case l.slots <- struct{}{}:
+		l.admitted.Add(1)
+		l.waited.Add(1)
+		return nil
+	case <-expired:
+		l.timedOut.Add(1)
+		return l.reject(ctx, errConcurrencyQueueTimeout)
+	case <-ctx.Done():
+		l.timedOut.Add(1)
+		return l.reject(ctx, ctx.Err())
+	}
+}
+
+func (l *ConcurrencyLimiter) reject(ctx context.Context, err error) *AppError {
+	appErr := shedError(ctx, err)
+	if l.status != 0 {
+		appErr.Code = l.status
+		appErr.Message = http.StatusText(l.status)
+	}
+	return appErr
+}
+
+// Release frees a slot taken by Acquire.
+func (l *ConcurrencyLimiter) Release() {
+	<-l.slots
+}
+
+// shedError returns the 503 of a request rejected to shed load and tags
+// the request log with timeout_source=shed.
+func shedError(ctx context.Context, err error) *AppError {
+	logger.AddInfo(ctx, "timeout_source", TimeoutSourceShed.String())
+	return NewTimeoutError(TimeoutSourceShed, err)
+}
+
+// Middleware serves each request within a slot.
+func (l *ConcurrencyLimiter) Middleware() Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			if err := l.Acquire(ctx); err != nil {
+				return err
+			}
+			defer l.Release()
+			return next(ctx, w, r)
+		}
+	}
+}
+
+// Stats returns the current occupancy and the rejection counters.
+func (l *ConcurrencyLimiter) Stats() ConcurrencyLimiterStats {
+	return ConcurrencyLimiterStats{
+		Max:      cap(l.slots),
+		MaxQueue: cap(l.queue),
+		InFlight: len(l.slots),
+		Queued:   len(l.queue),
+		Admitted: l.admitted.Load(),
+		Waited:   l.waited.Load(),
+		Rejected: l.rejected.Load(),
+		TimedOut: l.timedOut.Load(),
+	}
+}
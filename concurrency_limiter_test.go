@@ -0,0 +1,104 @@
+package golitekit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestConcurrencyLimiter_QueueAndReject(t *testing.T) {
+	l := NewConcurrencyLimiter(1, ConcurrencyLimiterOptions{MaxQueue: 1, QueueTimeout: time.Second})
+	release := make(chan struct{})
+	handler := l.Middleware()(func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		<-release
+		return nil
+	})
+	serve := func() error {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		return handler(req.Context(), httptest.NewRecorder(), req)
+	}
+
+	done := make(chan error, 2)
+	go func() { done <- serve() }()
+	waitFor(t, func() bool { return l.Stats().InFlight == 1 })
+	go func() { done <- serve() }()
+	waitFor(t, func() bool { return l.Stats().Queued == 1 })
+
+	var appErr *AppError
+	if err := serve(); !errors.As(err, &appErr) || appErr.Code != http.StatusServiceUnavailable {
+		t.Fatalf("third request = %v, want 503", err)
+	}
+
+	close(release)
+	for i := 0; i < 2; i++ {
+		if err := <-done; err != nil {
+			t.Fatalf("request %d: %v", i, err)
+		}
+	}
+	if s := l.Stats(); s.InFlight != 0 || s.Queued != 0 || s.Rejected != 1 {
+		t.Fatalf("stats = %+v", s)
+	}
+}
+
+func TestConcurrencyLimiter_QueueTimeout(t *testing.T) {
+	l := NewConcurrencyLimiter(1, ConcurrencyLimiterOptions{MaxQueue: 1, QueueTimeout: 20 * time.Millisecond})
+	if err := l.Acquire(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer l.Release()
+
+	start := time.Now()
+	err := l.Acquire(context.Background())
+	var appErr *AppError
+	if !errors.As(err, &appErr) || appErr.Code != http.StatusServiceUnavailable {
+		t.Fatalf("Acquire = %v, want 503", err)
+	}
+	if time.Since(start) < 20*time.Millisecond {
+		t.Error("gave up before QueueTimeout")
+	}
+	if s := l.Stats(); s.TimedOut != 1 || s.Queued != 0 {
+		t.Fatalf("stats = %+v", s)
+	}
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met in time")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestConcurrencyLimitMiddleware_RejectsWith429(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan struct{}, 1)
+	handler := ConcurrencyLimitMiddleware(1, 1, 20*time.Millisecond)(func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		started <- struct{}{}
+		<-release
+		return nil
+	})
+	serve := func() error {
+		req := httptest.NewRequest(http.MethodGet, "/export", nil)
+		return handler(req.Context(), httptest.NewRecorder(), req)
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- serve() }()
+	<-started
+
+	// the second request queues, then gives up after the wait
+	var appErr *AppError
+	if err := serve(); !errors.As(err, &appErr) || appErr.Code != http.StatusTooManyRequests {
+		t.Fatalf("queued request = %v, want 429", err)
+	}
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
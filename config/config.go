@@ -8,6 +8,7 @@ import (
 	"os"
 	"path/filepath"
 	"reflect"
+	"strings"
 
 	"github.com/BurntSushi/toml"
 	"gopkg.in/yaml.v2"
@@ -36,11 +37,20 @@ type Config interface {
 
 type AppConfig struct {
 	Decoders map[string]Decoder
+	// EnvPrefix names environment variable overrides (see ApplyEnvOverrides).
+	EnvPrefix string
+	// Profile forces the profile applied on top of the base values (see
+	// ActiveProfile).
+	Profile string
+	// ExpandEnv enables ${VAR} references in config files (see ExpandEnv).
+	ExpandEnv bool
 }
 
 func newAppConfig() *AppConfig {
 	cnf := &AppConfig{
-		Decoders: make(map[string]Decoder),
+		Decoders:  make(map[string]Decoder),
+		EnvPrefix: DefaultEnvPrefix,
+		ExpandEnv: true,
 	}
 	cnf.Decoders[ExtJSON] = JsonDecoder
 	cnf.Decoders[ExtTOML] = TomlDecoder
@@ -61,6 +71,17 @@ func Register(ext string, decoder Decoder) error {
 	return nil
 }
 
+// Parse reads the file at path and decodes it like ParseBytes. When a
+// profile is active, the profile file next to it (app.prod.toml for
+// app.toml, see ProfileFile) is layered over the [profiles.<name>] table and
+// below environment variable overrides, so the full precedence, lowest first,
+// is:
+//
+//	app.toml < [profiles.prod] in app.toml < app.prod.toml < GLK_* variables
+//
+// `default` tags only fill values that are still zero after every layer.
+// Overrides are namespaced by the file name unless obj is an EnvNamespacer,
+// so level in logger.toml is GLK_LOGGER_LEVEL (see ApplyEnvOverrides).
 func Parse(path string, obj any) error {
 	data, err := ReadFile(path)
 	if err != nil {
@@ -69,10 +90,38 @@ func Parse(path string, obj any) error {
 	}
 	ext := filepath.Ext(path)
 
-	return ParseBytes(ext, data, obj)
+	return parseLayers(ext, envNamespace(path, obj), data, obj, func(profile string) ([]byte, error) {
+		data, err := os.ReadFile(ProfileFile(path, profile))
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return data, err
+	})
+}
+
+// ProfileFile returns the overlay file of path for profile: the profile name
+// is inserted before the extension.
+func ProfileFile(path, profile string) string {
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + "." + profile + ext
 }
 
+// ParseBytes expands ${VAR} references in data (see ExpandEnv) and decodes it
+// with the decoder registered for ext. Values are then layered, later ones
+// winning: the base document, its [profiles.<name>] table for the active
+// profile (see ActiveProfile), and environment variable overrides (see
+// ApplyEnvOverrides, namespaced only when obj is an EnvNamespacer). Finally
+// `default` tags fill what is still zero (see ApplyDefaults) and `validate`
+// tags are checked (see Validate). When obj is a DocumentReceiver it also
+// receives the merged layers as a Document.
 func ParseBytes(ext string, data []byte, obj any) error {
+	return parseLayers(ext, envNamespace("", obj), data, obj, nil)
+}
+
+// parseLayers implements ParseBytes with the env override namespace; overlay,
+// if non-nil, returns the contents of the profile file for the active profile
+// or nil.
+func parseLayers(ext, namespace string, data []byte, obj any, overlay func(profile string) ([]byte, error)) error {
 	if data == nil {
 		return ErrFileEmpty
 	}
@@ -86,12 +135,60 @@ func ParseBytes(ext string, data []byte, obj any) error {
 	if t.Kind() != reflect.Ptr {
 		return fmt.Errorf("obj must be a pointer: %s", t)
 	}
-	err := decoder(data, obj)
+	data, err := expandEnv(ext, data)
+	if err != nil {
+		return err
+	}
+	err = decoder(data, obj)
 	if err != nil {
 		return err
 	}
 
-	return nil
+	layers := [][]byte{data}
+	if profile := ActiveProfile(obj); profile != "" {
+		section, err := profileSection(ext, data, profile)
+		if err != nil {
+			return fmt.Errorf("config: profile %s: %w", profile, err)
+		}
+		if section != nil {
+			if err := decoder(section, obj); err != nil {
+				return fmt.Errorf("config: profile %s: %w", profile, err)
+			}
+			layers = append(layers, section)
+		}
+		if overlay != nil {
+			file, err := overlay(profile)
+			if err != nil {
+				return fmt.Errorf("config: profile %s: %w", profile, err)
+			}
+			if file != nil {
+				if file, err = expandEnv(ext, file); err != nil {
+					return fmt.Errorf("config: profile file %s: %w", profile, err)
+				}
+				if err := decoder(file, obj); err != nil {
+					return fmt.Errorf("config: profile file %s: %w", profile, err)
+				}
+				layers = append(layers, file)
+			}
+		}
+	}
+	if recv, ok := obj.(DocumentReceiver); ok {
+		doc, err := NewDocument(ext, layers...)
+		if err != nil {
+			return err
+		}
+		doc.namespace = namespace
+		recv.SetConfigDocument(doc)
+	}
+
+	// Defaults go first so an override to a zero value is kept.
+	if err := ApplyDefaults(obj); err != nil {
+		return err
+	}
+	if err := ApplyEnvOverrides(defaultConfig.EnvPrefix, namespace, ext, obj); err != nil {
+		return err
+	}
+	return Validate(ext, obj)
 }
 
 func ReadFile(path string) ([]byte, error) {
@@ -1,7 +1,11 @@
 package config
 
 import (
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/hansir-hsj/GoLiteKit/config/test_data"
 )
@@ -34,3 +38,350 @@ func TestConfigParsing(t *testing.T) {
 		t.Log(p)
 	})
 }
+
+type envTestConfig struct {
+	HttpServer struct {
+		Addr    string        `toml:"addr"`
+		Debug   bool          `toml:"enableDebug"`
+		Timeout time.Duration `toml:"timeout"`
+		Hosts   []string      `toml:"hosts"`
+		Secret  string        `toml:"secret"`
+		Limits  `toml:"Limits"`
+	} `toml:"HttpServer"`
+}
+
+type Limits struct {
+	Rate int `toml:"rate"`
+}
+
+func TestParseBytes_EnvExpansionAndOverrides(t *testing.T) {
+	t.Setenv("APP_ADDR", ":9000")
+	t.Setenv("GLK_HTTPSERVER_ENABLEDEBUG", "true")
+	t.Setenv("GLK_HTTPSERVER_TIMEOUT", "3s")
+	t.Setenv("GLK_HTTPSERVER_HOSTS", "a.example, b.example")
+	t.Setenv("GLK_HTTPSERVER_LIMITS_RATE", "250")
+
+	data := []byte(`[HttpServer]
+addr = "${APP_ADDR}"
+secret = "${MISSING_SECRET:-fallback}-pa$$word-$${LITERAL}" # ${NOT_EXPANDED}
+
+[HttpServer.Limits]
+rate = ${RATE:-10}
+`)
+	var c envTestConfig
+	if err := ParseBytes(ExtTOML, data, &c); err != nil {
+		t.Fatalf("ParseBytes: %v", err)
+	}
+	s := c.HttpServer
+	if s.Addr != ":9000" || s.Secret != "fallback-pa$word-${LITERAL}" {
+		t.Fatalf("expansion: addr = %q secret = %q", s.Addr, s.Secret)
+	}
+	if !s.Debug || s.Timeout != 3*time.Second || s.Rate != 250 {
+		t.Fatalf("overrides: %+v", s)
+	}
+	if len(s.Hosts) != 2 || s.Hosts[1] != "b.example" {
+		t.Fatalf("hosts = %q", s.Hosts)
+	}
+
+	t.Setenv("GLK_HTTPSERVER_LIMITS_RATE", "fast")
+	if err := ParseBytes(ExtTOML, data, &c); err == nil || !strings.Contains(err.Error(), "GLK_HTTPSERVER_LIMITS_RATE") {
+		t.Fatalf("invalid override error = %v", err)
+	}
+}
+
+func TestExpandEnv_EscapesValues(t *testing.T) {
+	t.Setenv("DB_PASSWORD", `p"a\ss$1`+"\n[injected]")
+	t.Setenv("APP_NAME", "svc")
+
+	type creds struct {
+		Name     string `toml:"name" json:"name" yaml:"name"`
+		Password string `toml:"password" json:"password" yaml:"password"`
+		Port     int    `toml:"port" json:"port" yaml:"port"`
+	}
+	want := creds{Name: "svc", Password: `p"a\ss$1` + "\n[injected]", Port: 8080}
+	for _, tc := range []struct {
+		ext  string
+		data string
+	}{
+		{ExtTOML, "name = ${APP_NAME}\npassword = \"${DB_PASSWORD}\"\nport = ${PORT:-8080}\n"},
+		{ExtTOML, "name = ${APP_NAME}\npassword = \"\"\"${DB_PASSWORD}\"\"\"\nport = ${PORT:-8080}\n"},
+		{ExtJSON, `{"name": ${APP_NAME}, "password": "${DB_PASSWORD}", "port": ${PORT:-8080}}`},
+		{ExtYAML, "name: ${APP_NAME}\npassword: \"${DB_PASSWORD}\"\nport: ${PORT:-8080}\n"},
+	} {
+		var got creds
+		if err := ParseBytes(tc.ext, []byte(tc.data), &got); err != nil {
+			t.Errorf("%s %q: %v", tc.ext, tc.data, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("%s %q: got %+v, want %+v", tc.ext, tc.data, got, want)
+		}
+	}
+
+	t.Setenv("QUOTED", "it's")
+	var got creds
+	if err := ParseBytes(ExtYAML, []byte("password: '${QUOTED}'\n"), &got); err != nil || got.Password != "it's" {
+		t.Errorf("yaml single-quoted: password = %q, %v", got.Password, err)
+	}
+	if err := ParseBytes(ExtTOML, []byte("password = '${DB_PASSWORD}'\n"), &got); err == nil || !strings.Contains(err.Error(), "DB_PASSWORD") {
+		t.Errorf("literal string error = %v", err)
+	}
+
+	SetEnvExpansion(false)
+	defer SetEnvExpansion(true)
+	if err := ParseBytes(ExtTOML, []byte("password = \"${DB_PASSWORD}$$\"\n"), &got); err != nil || got.Password != "${DB_PASSWORD}$$" {
+		t.Errorf("disabled expansion: password = %q, %v", got.Password, err)
+	}
+}
+
+func TestParse_EnvNamespace(t *testing.T) {
+	type logConfig struct {
+		Logger struct {
+			Level string `toml:"level"`
+		} `toml:"logger"`
+		Dir string `toml:"dir"`
+	}
+	path := filepath.Join(t.TempDir(), "logger.toml")
+	if err := os.WriteFile(path, []byte("dir = \"logs\"\n[logger]\nlevel = \"INFO\"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("GLK_LEVEL", "ERROR")
+	t.Setenv("GLK_DIR", "/elsewhere")
+	t.Setenv("GLK_LOGGER_LEVEL", "DEBUG")
+	t.Setenv("GLK_LOGGER_DIR", "/var/log")
+
+	var cfg logConfig
+	if err := Parse(path, &cfg); err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if cfg.Logger.Level != "DEBUG" || cfg.Dir != "/var/log" {
+		t.Errorf("cfg = %+v, want the GLK_LOGGER_* overrides", cfg)
+	}
+
+	// Without a file name only an EnvNamespacer is namespaced.
+	var plain logConfig
+	if err := ParseBytes(ExtTOML, []byte("[logger]\nlevel = \"INFO\"\n"), &plain); err != nil {
+		t.Fatalf("ParseBytes: %v", err)
+	}
+	if plain.Dir != "/elsewhere" {
+		t.Errorf("dir = %q, want GLK_DIR", plain.Dir)
+	}
+	var named namespacedConfig
+	if err := ParseBytes(ExtTOML, []byte("[logger]\nlevel = \"INFO\"\n"), &named); err != nil {
+		t.Fatalf("ParseBytes: %v", err)
+	}
+	if named.Logger.Level != "DEBUG" {
+		t.Errorf("level = %q, want GLK_LOGGER_LEVEL", named.Logger.Level)
+	}
+}
+
+type namespacedConfig struct {
+	Logger struct {
+		Level string `toml:"level"`
+	} `toml:"logger"`
+}
+
+func (c *namespacedConfig) ConfigEnvNamespace() string { return "logger" }
+
+func TestParseBytes_DefaultsAndValidation(t *testing.T) {
+	type Pool struct {
+		Size    int           `toml:"size" default:"8" validate:"min=1,max=64"`
+		Mode    string        `toml:"mode" default:"fifo" validate:"oneof=fifo lifo"`
+		Timeout time.Duration `toml:"timeout" default:"2s"`
+	}
+	type Server struct {
+		Name string `toml:"name" validate:"required"`
+		Pool `toml:"pool"`
+	}
+
+	t.Run("defaults fill zero values", func(t *testing.T) {
+		var cfg Server
+		if err := ParseBytes(ExtTOML, []byte("name = \"api\"\n[pool]\nsize = 4\n"), &cfg); err != nil {
+			t.Fatalf("ParseBytes: %v", err)
+		}
+		if cfg.Size != 4 || cfg.Mode != "fifo" || cfg.Timeout != 2*time.Second {
+			t.Errorf("cfg = %+v, want size 4 with defaults", cfg)
+		}
+	})
+
+	t.Run("violations are aggregated", func(t *testing.T) {
+		var cfg Server
+		err := ParseBytes(ExtTOML, []byte("[pool]\nsize = -1\nmode = \"random\"\n"), &cfg)
+		if err == nil {
+			t.Fatal("expected validation error")
+		}
+		for _, want := range []string{
+			"name is required",
+			"pool.size must be >= 1",
+			`pool.mode must be one of [fifo lifo], got "random"`,
+		} {
+			if !strings.Contains(err.Error(), want) {
+				t.Errorf("error %q does not contain %q", err, want)
+			}
+		}
+	})
+
+	t.Run("env override to zero beats the default", func(t *testing.T) {
+		type Retry struct {
+			Attempts int `toml:"attempts" default:"3"`
+		}
+		t.Setenv("GLK_ATTEMPTS", "0")
+		var cfg Retry
+		if err := ParseBytes(ExtTOML, []byte("attempts = 5\n"), &cfg); err != nil {
+			t.Fatalf("ParseBytes: %v", err)
+		}
+		if cfg.Attempts != 0 {
+			t.Errorf("attempts = %d, want the override 0", cfg.Attempts)
+		}
+	})
+
+	t.Run("rules of other validators are ignored", func(t *testing.T) {
+		type Foreign struct {
+			Port  int      `toml:"port" validate:"required,gte=1,lte=65535"`
+			Hosts []string `toml:"hosts" validate:"dive,hostname"`
+			Name  string   `toml:"name" validate:"min=x"`
+		}
+		var cfg Foreign
+		if err := ParseBytes(ExtTOML, []byte("port = 80\nhosts = [\"a\"]\nname = \"n\"\n"), &cfg); err != nil {
+			t.Fatalf("ParseBytes: %v", err)
+		}
+		var empty Foreign
+		if err := ParseBytes(ExtTOML, []byte("hosts = []\n"), &empty); err == nil || !strings.Contains(err.Error(), "port is required") {
+			t.Fatalf("err = %v, want port is required", err)
+		}
+	})
+}
+
+type profileConfig struct {
+	Mode string `toml:"mode" json:"mode" yaml:"mode"`
+	Addr string `toml:"addr" json:"addr" yaml:"addr"`
+	Pool struct {
+		Size int `toml:"size" json:"size" yaml:"size"`
+		Idle int `toml:"idle" json:"idle" yaml:"idle"`
+	} `toml:"pool" json:"pool" yaml:"pool"`
+}
+
+func (c *profileConfig) ConfigProfile() string { return c.Mode }
+
+func TestParseBytes_Profiles(t *testing.T) {
+	docs := map[string]string{
+		ExtTOML: `
+mode = "prod"
+addr = ":8080"
+[pool]
+size = 4
+idle = 2
+[profiles.prod]
+addr = ":80"
+[profiles.prod.pool]
+size = 32
+[profiles.test]
+addr = ":0"
+`,
+		ExtJSON: `{"mode":"prod","addr":":8080","pool":{"size":4,"idle":2},
+			"profiles":{"prod":{"addr":":80","pool":{"size":32}},"test":{"addr":":0"}}}`,
+		ExtYAML: `
+mode: prod
+addr: ":8080"
+pool:
+  size: 4
+  idle: 2
+profiles:
+  prod:
+    addr: ":80"
+    pool:
+      size: 32
+  test:
+    addr: ":0"
+`,
+	}
+	for ext, doc := range docs {
+		t.Run(ext, func(t *testing.T) {
+			var cfg profileConfig
+			if err := ParseBytes(ext, []byte(doc), &cfg); err != nil {
+				t.Fatalf("ParseBytes: %v", err)
+			}
+			if cfg.Addr != ":80" || cfg.Pool.Size != 32 || cfg.Pool.Idle != 2 {
+				t.Errorf("cfg = %+v, want prod profile merged over base", cfg)
+			}
+		})
+	}
+
+	t.Run("env var selects the profile", func(t *testing.T) {
+		t.Setenv("GLK_PROFILE", "test")
+		var cfg profileConfig
+		if err := ParseBytes(ExtTOML, []byte(docs[ExtTOML]), &cfg); err != nil {
+			t.Fatalf("ParseBytes: %v", err)
+		}
+		if cfg.Addr != ":0" || cfg.Pool.Size != 4 {
+			t.Errorf("cfg = %+v, want test profile", cfg)
+		}
+	})
+
+	t.Run("unknown profile keeps base values", func(t *testing.T) {
+		SetProfile("staging")
+		defer SetProfile("")
+		var cfg profileConfig
+		if err := ParseBytes(ExtTOML, []byte(docs[ExtTOML]), &cfg); err != nil {
+			t.Fatalf("ParseBytes: %v", err)
+		}
+		if cfg.Addr != ":8080" {
+			t.Errorf("addr = %q, want base value", cfg.Addr)
+		}
+	})
+}
+
+func TestDocumentMergesLayers(t *testing.T) {
+	base := []byte("feature:\n  limit: 10\n  name: base\n")
+	override := []byte("feature:\n  limit: 20\n")
+	doc, err := NewDocument(ExtYAML, base, nil, override)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var limit int
+	if err := doc.Get("feature.limit", &limit); err != nil || limit != 20 {
+		t.Errorf("limit = %d, %v; want 20", limit, err)
+	}
+	var feature struct {
+		Limit int    `yaml:"limit"`
+		Name  string `yaml:"name"`
+	}
+	if err := doc.Decode("feature", &feature); err != nil {
+		t.Fatal(err)
+	}
+	if feature.Limit != 20 || feature.Name != "base" {
+		t.Errorf("feature = %+v, want tables merged key by key", feature)
+	}
+}
+
+func TestDiffStructs(t *testing.T) {
+	type DB struct {
+		Host     string `toml:"host"`
+		Password string `toml:"password"`
+		Token    string `toml:"t" secret:"true"`
+	}
+	type conf struct {
+		Addr string   `toml:"addr" reload:"immutable"`
+		Tags []string `toml:"tags"`
+		DB   DB       `toml:"DB"`
+	}
+	old := conf{Addr: ":80", Tags: []string{"a"}, DB: DB{Host: "h1", Password: "p1", Token: "t1"}}
+	next := conf{Addr: ":81", Tags: []string{"a"}, DB: DB{Host: "h2", Password: "p2", Token: "t2"}}
+
+	got := Diff(ExtTOML, &old, &next)
+	want := []Change{
+		{Key: "addr", Old: ":80", New: ":81", Immutable: true},
+		{Key: "DB.host", Old: "h1", New: "h2"},
+		{Key: "DB.password", Old: RedactedValue, New: RedactedValue},
+		{Key: "DB.t", Old: RedactedValue, New: RedactedValue},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Diff = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("change %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// RedactedValue replaces the values of secret keys in a Change.
+const RedactedValue = "[REDACTED]"
+
+// secretKeyParts mark a key as secret when its lower-cased name contains one
+// of them.
+var secretKeyParts = []string{"password", "passwd", "secret", "token", "credential", "apikey", "api_key", "private_key", "dsn"}
+
+// Change is a key whose value differs between two configurations.
+type Change struct {
+	Key string `json:"key"`
+	Old string `json:"old"`
+	New string `json:"new"`
+	// Immutable is set for fields tagged `reload:"immutable"`, which only take
+	// effect on restart.
+	Immutable bool `json:"immutable,omitempty"`
+}
+
+func (c Change) String() string {
+	return fmt.Sprintf("%s: %s -> %s", c.Key, c.Old, c.New)
+}
+
+// Diff compares two values of the same struct type field by field and
+// returns the changed keys, named by their tag path for ext (e.g.
+// "HttpServer.Timeout.readTimeout"). Values of secret keys (see IsSecretKey)
+// and of fields tagged `secret:"true"` are reported as RedactedValue.
+func Diff(ext string, old, next any) []Change {
+	ov, nv := reflect.ValueOf(old), reflect.ValueOf(next)
+	for ov.Kind() == reflect.Pointer && nv.Kind() == reflect.Pointer {
+		if ov.IsNil() || nv.IsNil() {
+			return nil
+		}
+		ov, nv = ov.Elem(), nv.Elem()
+	}
+	if ov.Type() != nv.Type() || ov.Kind() != reflect.Struct {
+		return nil
+	}
+	var changes []Change
+	diffStruct(ov, nv, "", tagForExt(ext), &changes)
+	return changes
+}
+
+func diffStruct(ov, nv reflect.Value, prefix, tag string, changes *[]Change) {
+	t := ov.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		key, skip := fieldKey(sf, tag)
+		if skip {
+			continue
+		}
+		path := prefix
+		if key != "" {
+			path = joinPath(prefix, key)
+		}
+		of, nf := ov.Field(i), nv.Field(i)
+		if of.Kind() == reflect.Struct && !isTextValue(of) {
+			diffStruct(of, nf, path, tag, changes)
+			continue
+		}
+		if reflect.DeepEqual(of.Interface(), nf.Interface()) {
+			continue
+		}
+		c := Change{
+			Key:       path,
+			Old:       fmt.Sprint(of.Interface()),
+			New:       fmt.Sprint(nf.Interface()),
+			Immutable: sf.Tag.Get("reload") == "immutable",
+		}
+		if sf.Tag.Get("secret") == "true" || IsSecretKey(key) {
+			c.Old, c.New = RedactedValue, RedactedValue
+		}
+		*changes = append(*changes, c)
+	}
+}
+
+// IsSecretKey reports whether a config key holds a secret, e.g. "password",
+// "dbPassword" or "api_key".
+func IsSecretKey(key string) bool {
+	lower := strings.ToLower(key)
+	for _, part := range secretKeyParts {
+		if strings.Contains(lower, part) {
+			return true
+		}
+	}
+	return false
+}
+
+// Diff returns the keys whose values differ between d and next, sorted by
+// key. Tables are compared key by key; skip lists top-level tables to
+// ignore. Secret keys are redacted as in Diff.
+func (d *Document) Diff(next *Document, skip ...string) []Change {
+	oldKeys, newKeys := map[string]any{}, map[string]any{}
+	if d != nil {
+		flatten(d.root, "", oldKeys)
+	}
+	if next != nil {
+		flatten(next.root, "", newKeys)
+	}
+	skipped := func(key string) bool {
+		top, _, _ := strings.Cut(key, ".")
+		for _, s := range skip {
+			if top == s {
+				return true
+			}
+		}
+		return false
+	}
+
+	var changes []Change
+	add := func(key string, o, n any, hasOld, hasNew bool) {
+		if skipped(key) || (hasOld && hasNew && reflect.DeepEqual(o, n)) {
+			return
+		}
+		c := Change{Key: key}
+		if hasOld {
+			c.Old = fmt.Sprint(o)
+		}
+		if hasNew {
+			c.New = fmt.Sprint(n)
+		}
+		if IsSecretKey(key[strings.LastIndex(key, ".")+1:]) {
+			c.Old, c.New = RedactedValue, RedactedValue
+		}
+		changes = append(changes, c)
+	}
+	for key, o := range oldKeys {
+		n, ok := newKeys[key]
+		add(key, o, n, true, ok)
+	}
+	for key, n := range newKeys {
+		if _, ok := oldKeys[key]; !ok {
+			add(key, nil, n, false, true)
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Key < changes[j].Key })
+	return changes
+}
+
+// flatten stores the non-table values of m under their dotted paths.
+func flatten(m map[string]any, prefix string, out map[string]any) {
+	for k, v := range m {
+		path := joinPath(prefix, k)
+		if sub, ok := v.(map[string]any); ok {
+			flatten(sub, path, out)
+			continue
+		}
+		out[path] = v
+	}
+}
@@ -0,0 +1,194 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+)
+
+// ErrKeyNotFound is returned by Document.Get for paths that are not set.
+var ErrKeyNotFound = errors.New("config: key not found")
+
+// DocumentReceiver is implemented by config structs that keep the whole
+// decoded document, so sections without a Go field can be read later (see
+// Document). env.Env uses it for env.Get and env.Section.
+type DocumentReceiver interface {
+	SetConfigDocument(doc *Document)
+}
+
+// Document is a configuration decoded without a schema. It holds every layer
+// applied by Parse merged in order: tables are merged key by key, other values
+// are replaced.
+type Document struct {
+	ext       string
+	namespace string // env override namespace, see ApplyEnvOverrides
+	root      map[string]any
+}
+
+// NewDocument decodes layers with the decoder registered for ext and merges
+// them, later layers winning. nil layers are skipped.
+func NewDocument(ext string, layers ...[]byte) (*Document, error) {
+	decoder, ok := defaultConfig.Decoders[ext]
+	if !ok {
+		return nil, fmt.Errorf("decoder not found for extension: %s", ext)
+	}
+	doc := &Document{ext: ext, root: make(map[string]any)}
+	for _, layer := range layers {
+		if layer == nil {
+			continue
+		}
+		var m map[string]any
+		if err := decoder(layer, &m); err != nil {
+			return nil, err
+		}
+		mergeMaps(doc.root, normalize(m).(map[string]any))
+	}
+	return doc, nil
+}
+
+// Lookup returns the raw value at path, whose elements are separated by "."
+// (e.g. "MyFeature.limit").
+func (d *Document) Lookup(path string) (any, bool) {
+	if d == nil {
+		return nil, false
+	}
+	var cur any = d.root
+	for _, key := range strings.Split(path, ".") {
+		m, ok := cur.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		if cur, ok = m[key]; !ok {
+			return nil, false
+		}
+	}
+	return cur, true
+}
+
+// Decode decodes the table at path into obj like ParseBytes decodes a whole
+// file: `default` tags fill the zero values, environment variables named
+// PREFIX_PATH_KEY override them (e.g. GLK_MYFEATURE_LIMIT; the namespace of
+// the parsed file, if any, follows the prefix), then `validate` tags apply. A
+// missing table leaves obj to its defaults.
+func (d *Document) Decode(path string, obj any) error {
+	ext := ExtTOML
+	if d != nil {
+		ext = d.ext
+	}
+	if v, ok := d.Lookup(path); ok {
+		if _, isTable := v.(map[string]any); !isTable {
+			return fmt.Errorf("config: %s is not a table", path)
+		}
+		data, err := encode(ext, v)
+		if err != nil {
+			return fmt.Errorf("config: %s: %w", path, err)
+		}
+		if err := defaultConfig.Decoders[ext](data, obj); err != nil {
+			return fmt.Errorf("config: %s: %w", path, err)
+		}
+	}
+	if err := ApplyDefaults(obj); err != nil {
+		return err
+	}
+	if prefix := defaultConfig.EnvPrefix; prefix != "" {
+		if err := ApplyEnvOverrides(d.envName(prefix, path), "", ext, obj); err != nil {
+			return err
+		}
+	}
+	if err := Validate(ext, obj); err != nil {
+		return fmt.Errorf("config: %s: %w", path, err)
+	}
+	return nil
+}
+
+// envName returns the variable overriding path in d's namespace.
+func (d *Document) envName(prefix, path string) string {
+	if d == nil {
+		return envName(prefix, "", path)
+	}
+	return envName(prefix, d.namespace, path)
+}
+
+// Get stores the value at path in out, which must be a non-nil pointer. The
+// PREFIX_PATH environment variable (GLK_MYFEATURE_LIMIT for
+// "MyFeature.limit") wins over the document. Strings are parsed into
+// non-string targets, so "5s" fills a time.Duration. ErrKeyNotFound is
+// returned when path is not set.
+func (d *Document) Get(path string, out any) error {
+	v := reflect.ValueOf(out)
+	if v.Kind() != reflect.Pointer || v.IsNil() {
+		return fmt.Errorf("out must be a non-nil pointer: %T", out)
+	}
+	if prefix := defaultConfig.EnvPrefix; prefix != "" {
+		name := d.envName(prefix, path)
+		if raw, ok := os.LookupEnv(name); ok {
+			if err := setFromString(v.Elem(), raw); err != nil {
+				return fmt.Errorf("config: env %s: %w", name, err)
+			}
+			return nil
+		}
+	}
+
+	raw, ok := d.Lookup(path)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrKeyNotFound, path)
+	}
+	if s, isString := raw.(string); isString && v.Elem().Kind() != reflect.String {
+		if err := setFromString(v.Elem(), s); err != nil {
+			return fmt.Errorf("config: %s: %w", path, err)
+		}
+		return nil
+	}
+	data, err := json.Marshal(raw)
+	if err == nil {
+		err = json.Unmarshal(data, out)
+	}
+	if err != nil {
+		return fmt.Errorf("config: %s: %w", path, err)
+	}
+	return nil
+}
+
+// normalize converts the map[any]any tables produced by YAML to
+// map[string]any, recursively.
+func normalize(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, e := range v {
+			v[k] = normalize(e)
+		}
+		return v
+	case map[any]any:
+		m := make(map[string]any, len(v))
+		for k, e := range v {
+			m[fmt.Sprint(k)] = normalize(e)
+		}
+		return m
+	case []any:
+		for i, e := range v {
+			v[i] = normalize(e)
+		}
+		return v
+	case []map[string]any:
+		for _, e := range v {
+			normalize(e)
+		}
+		return v
+	}
+	return v
+}
+
+func mergeMaps(dst, src map[string]any) {
+	for k, v := range src {
+		if sm, ok := v.(map[string]any); ok {
+			if dm, ok := dst[k].(map[string]any); ok {
+				mergeMaps(dm, sm)
+				continue
+			}
+		}
+		dst[k] = v
+	}
+}
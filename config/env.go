@@ -0,0 +1,427 @@
+package config
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultEnvPrefix is the prefix of environment variable overrides:
+// GLK_HTTPSERVER_ADDR overrides addr in the [HttpServer] section of app.toml
+// and GLK_LOGGER_LEVEL overrides level in logger.toml (see EnvNamespacer).
+const DefaultEnvPrefix = "GLK"
+
+var envRefPattern = regexp.MustCompile(`^\$\{([A-Za-z_][A-Za-z0-9_]*)(?::-([^}]*))?\}`)
+
+// lexState is where ExpandEnv stands in the document.
+type lexState int
+
+const (
+	lexBare lexState = iota
+	lexComment
+	lexBasic     // "..."
+	lexBasicML   // """..."""
+	lexLiteral   // '...'
+	lexLiteralML // '''...'''
+)
+
+// ExpandEnv replaces ${VAR} in data, written in the format of ext, with the
+// value of the environment variable VAR and ${VAR:-default} with default when
+// VAR is unset or empty. "$$" produces a literal "$"; references in comments
+// are left alone. Expansion happens before decoding, so it also works for
+// numbers and booleans (port = ${PORT:-8080}).
+//
+// Values are escaped for where the reference stands, so quotes, backslashes
+// and newlines cannot change the document: inside double-quoted strings they
+// are escaped like JSON strings and inside single-quoted YAML strings quotes
+// are doubled. A reference standing alone as a value is inserted as is when
+// the value is a number or a boolean and as a quoted string otherwise. Values
+// that cannot be written where they stand, such as a line break inside single
+// quotes, are an error.
+func ExpandEnv(ext string, data []byte) ([]byte, error) {
+	var out bytes.Buffer
+	state := lexBare
+	for i := 0; i < len(data); {
+		c := data[i]
+		if c == '$' && state != lexComment {
+			if bytes.HasPrefix(data[i:], []byte("$$")) {
+				out.WriteByte('$')
+				i += 2
+				continue
+			}
+			if m := envRefPattern.FindSubmatch(data[i:]); m != nil {
+				value := os.Getenv(string(m[1]))
+				if value == "" {
+					value = string(m[2])
+				}
+				end := i + len(m[0])
+				quoted, err := quoteEnvValue(ext, state, value, standsAlone(data, i, end))
+				if err != nil {
+					return nil, fmt.Errorf("config: ${%s}: %w", m[1], err)
+				}
+				out.WriteString(quoted)
+				i = end
+				continue
+			}
+		}
+
+		n := 1
+		switch state {
+		case lexBare:
+			switch {
+			case c == '#' && ext != ExtJSON && (ext != ExtYAML || i == 0 || data[i-1] == ' ' || data[i-1] == '\t' || data[i-1] == '\n'):
+				state = lexComment
+			case c == '"' && opensString(ext, data, i):
+				state = lexBasic
+				if ext == ExtTOML && bytes.HasPrefix(data[i:], []byte(`"""`)) {
+					state, n = lexBasicML, 3
+				}
+			case c == '\'' && ext != ExtJSON && opensString(ext, data, i):
+				state = lexLiteral
+				if ext == ExtTOML && bytes.HasPrefix(data[i:], []byte(`'''`)) {
+					state, n = lexLiteralML, 3
+				}
+			}
+		case lexComment:
+			if c == '\n' {
+				state = lexBare
+			}
+		case lexBasic, lexBasicML:
+			switch {
+			case c == '\\':
+				n = 2
+			case state == lexBasicML:
+				if bytes.HasPrefix(data[i:], []byte(`"""`)) {
+					state, n = lexBare, 3
+				}
+			case c == '"', c == '\n' && ext != ExtYAML:
+				state = lexBare
+			}
+		case lexLiteral:
+			switch {
+			case c == '\'' && ext == ExtYAML && i+1 < len(data) && data[i+1] == '\'':
+				n = 2
+			case c == '\'', c == '\n' && ext == ExtTOML:
+				state = lexBare
+			}
+		case lexLiteralML:
+			if bytes.HasPrefix(data[i:], []byte(`'''`)) {
+				state, n = lexBare, 3
+			}
+		}
+		n = min(n, len(data)-i)
+		out.Write(data[i : i+n])
+		i += n
+	}
+	return out.Bytes(), nil
+}
+
+// opensString reports whether the quote at data[i] starts a string. YAML
+// quotes only do at the start of a value; elsewhere they are plain text.
+func opensString(ext string, data []byte, i int) bool {
+	if ext != ExtYAML {
+		return true
+	}
+	j := i - 1
+	for j >= 0 && (data[j] == ' ' || data[j] == '\t') {
+		j--
+	}
+	return j < 0 || strings.IndexByte("\n:-[{,?", data[j]) >= 0
+}
+
+// standsAlone reports whether the reference data[start:end] is a whole value:
+// it follows "=", ":", "[", "{", ",", "-" or the start of a line and ends the
+// line or precedes ",", "]", "}" or a comment.
+func standsAlone(data []byte, start, end int) bool {
+	j := start - 1
+	for j >= 0 && (data[j] == ' ' || data[j] == '\t') {
+		j--
+	}
+	if j >= 0 && strings.IndexByte("\n=:[{,-", data[j]) < 0 {
+		return false
+	}
+	k := end
+	for k < len(data) && (data[k] == ' ' || data[k] == '\t') {
+		k++
+	}
+	return k == len(data) || strings.IndexByte("\r\n,]}#", data[k]) >= 0
+}
+
+// quoteEnvValue returns v escaped for a reference in state.
+func quoteEnvValue(ext string, state lexState, v string, alone bool) (string, error) {
+	switch state {
+	case lexBasic, lexBasicML:
+		q := jsonString(v)
+		return q[1 : len(q)-1], nil
+	case lexLiteral:
+		if strings.ContainsAny(v, "\r\n") || (ext != ExtYAML && strings.Contains(v, "'")) {
+			return "", errors.New("value cannot be written in a single-quoted string, use double quotes")
+		}
+		return strings.ReplaceAll(v, "'", "''"), nil
+	case lexLiteralML:
+		if strings.Contains(v, "'''") {
+			return "", errors.New("value cannot be written in a literal string, use double quotes")
+		}
+		return v, nil
+	}
+	if alone && !isScalarLiteral(v) {
+		return jsonString(v), nil
+	}
+	if strings.ContainsAny(v, "\r\n") {
+		return "", errors.New("value contains a line break, quote the reference")
+	}
+	return v, nil
+}
+
+func jsonString(s string) string {
+	var b strings.Builder
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(s)
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+func isScalarLiteral(v string) bool {
+	if v == "true" || v == "false" {
+		return true
+	}
+	_, err := strconv.ParseFloat(v, 64)
+	return err == nil
+}
+
+// SetEnvExpansion turns ExpandEnv on or off for Parse and ParseBytes. It is on
+// by default; turn it off for files whose values contain "${" and "$$" that
+// must be kept verbatim.
+func SetEnvExpansion(enabled bool) {
+	defaultConfig.ExpandEnv = enabled
+}
+
+func expandEnv(ext string, data []byte) ([]byte, error) {
+	if !defaultConfig.ExpandEnv {
+		return data, nil
+	}
+	return ExpandEnv(ext, data)
+}
+
+// SetEnvPrefix changes the prefix of environment variable overrides applied
+// by Parse and ParseBytes. An empty prefix disables overrides.
+func SetEnvPrefix(prefix string) {
+	defaultConfig.EnvPrefix = prefix
+}
+
+// EnvNamespacer is implemented by config structs that choose the namespace of
+// their environment variable overrides. Otherwise Parse uses the file name
+// (logger for conf/logger.toml) and ParseBytes uses none. env.Env returns ""
+// because the sections of app.toml are namespaces already.
+type EnvNamespacer interface {
+	ConfigEnvNamespace() string
+}
+
+// envNamespace returns the namespace of obj's overrides when decoded from
+// path, which is "" for data without a file name.
+func envNamespace(path string, obj any) string {
+	if n, ok := obj.(EnvNamespacer); ok {
+		return n.ConfigEnvNamespace()
+	}
+	if path == "" {
+		return ""
+	}
+	base := filepath.Base(path)
+	return strings.TrimSuffix(base, filepath.Ext(base))
+}
+
+// envName returns the variable overriding the "."-separated path (the whole
+// document when "") in namespace: PREFIX_NAMESPACE_PATH, where a path that
+// starts with the namespace does not repeat it.
+func envName(prefix, namespace, path string) string {
+	name := envKey(prefix)
+	if namespace != "" {
+		name += "_" + envKey(namespace)
+		if first, rest, _ := strings.Cut(path, "."); envKey(first) == envKey(namespace) {
+			path = rest
+		}
+	}
+	if path != "" {
+		name += "_" + envKey(path)
+	}
+	return name
+}
+
+// ApplyEnvOverrides sets fields of obj from environment variables named
+// PREFIX_NAMESPACE_SECTION_KEY: each path element is the field's tag name for
+// ext (toml, json or yaml; the Go field name when untagged), upper-cased with
+// non-alphanumerics replaced by "_". A top-level section named like the
+// namespace is not repeated, so [logger] level in logger.toml is
+// GLK_LOGGER_LEVEL, and an empty namespace leaves PREFIX_SECTION_KEY.
+// Anonymous fields without a tag are flattened. Strings, bools, numbers,
+// time.Duration, encoding.TextUnmarshaler and comma-separated []string/[]int
+// values are supported.
+func ApplyEnvOverrides(prefix, namespace, ext string, obj any) error {
+	if prefix == "" {
+		return nil
+	}
+	v := reflect.ValueOf(obj)
+	if v.Kind() != reflect.Pointer || v.IsNil() {
+		return fmt.Errorf("obj must be a non-nil pointer: %T", obj)
+	}
+	return applyEnv(v.Elem(), envName(prefix, namespace, ""), envKey(namespace), tagForExt(ext))
+}
+
+// applyEnv sets v from the variable name or, for structs, its fields from
+// name_KEY; a field whose key is skip adds no element.
+func applyEnv(v reflect.Value, name, skip, tag string) error {
+	if v.Kind() == reflect.Struct && !isTextValue(v) {
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			sf := t.Field(i)
+			if !sf.IsExported() {
+				continue
+			}
+			key, ignore := fieldKey(sf, tag)
+			if ignore {
+				continue
+			}
+			child, childSkip := name, ""
+			switch {
+			case key == "":
+				childSkip = skip
+			case envKey(key) != skip:
+				child = name + "_" + envKey(key)
+			}
+			if err := applyEnv(v.Field(i), child, childSkip, tag); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	raw, ok := os.LookupEnv(name)
+	if !ok {
+		return nil
+	}
+	if err := setFromString(v, raw); err != nil {
+		return fmt.Errorf("config: env %s: %w", name, err)
+	}
+	return nil
+}
+
+// fieldKey returns the key of sf under tag; "" flattens an untagged anonymous
+// struct, and skip is true for "-".
+func fieldKey(sf reflect.StructField, tag string) (key string, skip bool) {
+	name, _, _ := strings.Cut(sf.Tag.Get(tag), ",")
+	switch {
+	case name == "-":
+		return "", true
+	case name != "":
+		return name, false
+	case sf.Anonymous:
+		return "", false
+	}
+	return sf.Name, false
+}
+
+func tagForExt(ext string) string {
+	switch ext {
+	case ExtJSON:
+		return "json"
+	case ExtYAML:
+		return "yaml"
+	}
+	return "toml"
+}
+
+func envKey(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		}
+		return '_'
+	}, s)
+}
+
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+func isTextValue(v reflect.Value) bool {
+	return v.CanAddr() && v.Addr().Type().Implements(textUnmarshalerType)
+}
+
+func setFromString(v reflect.Value, raw string) error {
+	if isTextValue(v) {
+		return v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(raw))
+	}
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return setFromString(v.Elem(), raw)
+	}
+	if v.Type() == reflect.TypeOf(time.Duration(0)) {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(raw, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(raw, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	case reflect.Slice:
+		var parts []string
+		if raw != "" {
+			parts = strings.Split(raw, ",")
+		}
+		s := reflect.MakeSlice(v.Type(), len(parts), len(parts))
+		for i, p := range parts {
+			if err := setFromString(s.Index(i), strings.TrimSpace(p)); err != nil {
+				return err
+			}
+		}
+		v.Set(s)
+	case reflect.Interface:
+		if v.NumMethod() != 0 {
+			return fmt.Errorf("unsupported type %s", v.Type())
+		}
+		v.Set(reflect.ValueOf(raw))
+	default:
+		return fmt.Errorf("unsupported kind %s", v.Kind())
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v2"
+)
+
+// ProfilesKey is the top-level table holding per-environment overrides:
+//
+//	[HttpServer]
+//	addr = ":8080"
+//
+//	[profiles.prod.HttpServer]
+//	addr = ":80"
+const ProfilesKey = "profiles"
+
+// ProfileSelector is implemented by config structs that choose their profile
+// from a decoded value; env.Env returns its runMode.
+type ProfileSelector interface {
+	ConfigProfile() string
+}
+
+// SetProfile forces the profile applied by Parse and ParseBytes. An empty
+// name restores automatic selection.
+func SetProfile(name string) {
+	defaultConfig.Profile = name
+}
+
+// ProfileEnvVar returns the environment variable that selects the profile,
+// PREFIX_PROFILE (GLK_PROFILE by default), or "" when env overrides are
+// disabled.
+func ProfileEnvVar() string {
+	if defaultConfig.EnvPrefix == "" {
+		return ""
+	}
+	return envKey(defaultConfig.EnvPrefix) + "_PROFILE"
+}
+
+// ActiveProfile returns the profile applied to obj, which must already hold
+// the base values: the ProfileEnvVar variable wins, then SetProfile, then
+// obj's ProfileSelector. "" means no profile.
+func ActiveProfile(obj any) string {
+	if name := ProfileEnvVar(); name != "" {
+		if v := os.Getenv(name); v != "" {
+			return v
+		}
+	}
+	if defaultConfig.Profile != "" {
+		return defaultConfig.Profile
+	}
+	if s, ok := obj.(ProfileSelector); ok {
+		return s.ConfigProfile()
+	}
+	return ""
+}
+
+// profileSection returns the [profiles.<name>] table of data re-encoded for
+// ext, or nil when data has no such profile.
+func profileSection(ext string, data []byte, name string) ([]byte, error) {
+	decoder, ok := defaultConfig.Decoders[ext]
+	if !ok || name == "" {
+		return nil, nil
+	}
+	var doc map[string]any
+	if err := decoder(data, &doc); err != nil {
+		return nil, err
+	}
+	section := lookupKey(lookupKey(doc, ProfilesKey), name)
+	if section == nil {
+		return nil, nil
+	}
+	return encode(ext, section)
+}
+
+// encode re-encodes a decoded value for ext.
+func encode(ext string, section any) ([]byte, error) {
+	switch ext {
+	case ExtJSON:
+		return json.Marshal(section)
+	case ExtTOML:
+		var buf bytes.Buffer
+		if err := toml.NewEncoder(&buf).Encode(section); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	case ExtYAML:
+		return yaml.Marshal(section)
+	}
+	return nil, fmt.Errorf("config: profiles are not supported for extension %s", ext)
+}
+
+// lookupKey indexes the map types produced by the JSON, TOML and YAML decoders.
+func lookupKey(m any, key string) any {
+	switch m := m.(type) {
+	case map[string]any:
+		return m[key]
+	case map[any]any:
+		return m[key]
+	}
+	return nil
+}
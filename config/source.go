@@ -0,0 +1,307 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultPollInterval = 30 * time.Second
+	defaultFetchTimeout = 10 * time.Second
+)
+
+// Source loads raw configuration data. ext selects the decoder (".toml",
+// ".json", ".yaml").
+type Source interface {
+	Load(ctx context.Context) (data []byte, ext string, err error)
+}
+
+// Watcher is implemented by sources that can wait for changes natively (e.g.
+// Consul blocking queries). Watch calls fn with every new version until ctx is
+// done. Sources without it are polled by Watch.
+type Watcher interface {
+	Watch(ctx context.Context, fn func(data []byte, ext string)) error
+}
+
+// ParseSource loads src and decodes it into obj like ParseBytes. A
+// FileSource is parsed with Parse, so its profile file is layered too.
+func ParseSource(ctx context.Context, src Source, obj any) error {
+	if fs, ok := src.(FileSource); ok {
+		return Parse(fs.Path, obj)
+	}
+	data, ext, err := src.Load(ctx)
+	if err != nil {
+		return err
+	}
+	if len(data) == 0 {
+		return ErrFileEmpty
+	}
+	return ParseBytes(ext, data, obj)
+}
+
+// Watch calls fn whenever the content of src changes, until ctx is done.
+// Sources implementing Watcher are used directly; others are polled every
+// interval (DefaultPollInterval when <= 0). Load errors are retried at the
+// next poll.
+func Watch(ctx context.Context, src Source, interval time.Duration, fn func(data []byte, ext string)) error {
+	if w, ok := src.(Watcher); ok {
+		return w.Watch(ctx, fn)
+	}
+	if interval <= 0 {
+		interval = DefaultPollInterval
+	}
+	last, _, _ := src.Load(ctx)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			data, ext, err := src.Load(ctx)
+			if err != nil || bytes.Equal(data, last) {
+				continue
+			}
+			last = data
+			fn(data, ext)
+		}
+	}
+}
+
+// OpenSource resolves a config location:
+//
+//	app.toml, /etc/app/app.toml          FileSource
+//	http://host/app.toml, https://...    HTTPSource
+//	consul://host:8500/service/app.toml  ConsulSource (consul+https:// for TLS)
+//	etcd://host:2379/service/app.toml    EtcdSource (etcd+https:// for TLS)
+func OpenSource(location string) (Source, error) {
+	scheme, rest, ok := strings.Cut(location, "://")
+	if !ok {
+		return FileSource{Path: location}, nil
+	}
+	switch scheme {
+	case "http", "https":
+		return &HTTPSource{URL: location}, nil
+	case "consul", "consul+https", "etcd", "etcd+https":
+		host, key, _ := strings.Cut(rest, "/")
+		if host == "" || key == "" {
+			return nil, fmt.Errorf("config: %s source needs host and key: %s", scheme, location)
+		}
+		httpScheme := "http"
+		if strings.HasSuffix(scheme, "+https") {
+			httpScheme = "https"
+		}
+		addr := httpScheme + "://" + host
+		if strings.HasPrefix(scheme, "consul") {
+			return &ConsulSource{Addr: addr, Key: key}, nil
+		}
+		return &EtcdSource{Endpoint: addr, Key: "/" + key}, nil
+	}
+	return nil, fmt.Errorf("config: unsupported source scheme %q", scheme)
+}
+
+// IsRemote reports whether location names a non-file source.
+func IsRemote(location string) bool {
+	return strings.Contains(location, "://")
+}
+
+// FileSource reads a local file.
+type FileSource struct {
+	Path string
+}
+
+func (s FileSource) Load(ctx context.Context) ([]byte, string, error) {
+	data, err := ReadFile(s.Path)
+	return data, filepath.Ext(s.Path), err
+}
+
+// HTTPSource fetches configuration with GET. The decoder is chosen by Ext,
+// then the URL path extension, then the response Content-Type.
+type HTTPSource struct {
+	URL    string
+	Ext    string
+	Header http.Header
+	Client *http.Client // defaults to a client with a 10s timeout
+}
+
+func (s *HTTPSource) Load(ctx context.Context) ([]byte, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.URL, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	for k, v := range s.Header {
+		req.Header[k] = v
+	}
+	data, resp, err := fetch(s.Client, req)
+	if err != nil {
+		return nil, "", err
+	}
+	ext := s.Ext
+	if ext == "" {
+		if u, err := url.Parse(s.URL); err == nil {
+			ext = path.Ext(u.Path)
+		}
+	}
+	if ext == "" {
+		ext = extFromContentType(resp.Header.Get("Content-Type"))
+	}
+	return data, ext, nil
+}
+
+// ConsulSource reads a Consul KV key. Watch uses blocking queries, so changes
+// are delivered as soon as Consul sees them.
+type ConsulSource struct {
+	Addr   string // e.g. http://127.0.0.1:8500
+	Key    string // e.g. service/app.toml
+	Ext    string // defaults to the key extension
+	Token  string // ACL token, sent as X-Consul-Token
+	Client *http.Client
+}
+
+func (s *ConsulSource) Load(ctx context.Context) ([]byte, string, error) {
+	data, _, err := s.get(ctx, 0, 0)
+	return data, keyExt(s.Ext, s.Key), err
+}
+
+func (s *ConsulSource) Watch(ctx context.Context, fn func(data []byte, ext string)) error {
+	_, index, err := s.get(ctx, 0, 0)
+	if err != nil {
+		index = 0
+	}
+	for {
+		data, next, err := s.get(ctx, index, 5*time.Minute)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(time.Second):
+			}
+			continue
+		}
+		// Consul may return the same index on timeout, and indexes can go
+		// backwards after a snapshot restore.
+		if next != index {
+			if next > index && data != nil {
+				fn(data, keyExt(s.Ext, s.Key))
+			}
+			index = next
+		}
+	}
+}
+
+func (s *ConsulSource) get(ctx context.Context, index uint64, wait time.Duration) ([]byte, uint64, error) {
+	u := strings.TrimRight(s.Addr, "/") + "/v1/kv/" + strings.TrimLeft(s.Key, "/") + "?raw"
+	if index > 0 {
+		u += "&index=" + strconv.FormatUint(index, 10) + "&wait=" + wait.String()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	if s.Token != "" {
+		req.Header.Set("X-Consul-Token", s.Token)
+	}
+	client := s.Client
+	if client == nil && index > 0 {
+		client = &http.Client{Timeout: wait + wait/16 + defaultFetchTimeout}
+	}
+	data, resp, err := fetch(client, req)
+	if err != nil {
+		return nil, 0, err
+	}
+	next, _ := strconv.ParseUint(resp.Header.Get("X-Consul-Index"), 10, 64)
+	return data, next, nil
+}
+
+// EtcdSource reads a key through the etcd v3 JSON gateway (/v3/kv/range).
+// It is polled by Watch.
+type EtcdSource struct {
+	Endpoint string // e.g. http://127.0.0.1:2379
+	Key      string // e.g. /service/app.toml
+	Ext      string // defaults to the key extension
+	Client   *http.Client
+}
+
+func (s *EtcdSource) Load(ctx context.Context) ([]byte, string, error) {
+	body, _ := json.Marshal(map[string]string{"key": base64.StdEncoding.EncodeToString([]byte(s.Key))})
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimRight(s.Endpoint, "/")+"/v3/kv/range", bytes.NewReader(body))
+	if err != nil {
+		return nil, "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	data, _, err := fetch(s.Client, req)
+	if err != nil {
+		return nil, "", err
+	}
+	var out struct {
+		Kvs []struct {
+			Value string `json:"value"`
+		} `json:"kvs"`
+	}
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, "", fmt.Errorf("config: decode etcd response: %w", err)
+	}
+	if len(out.Kvs) == 0 {
+		return nil, "", fmt.Errorf("config: etcd key not found: %s", s.Key)
+	}
+	value, err := base64.StdEncoding.DecodeString(out.Kvs[0].Value)
+	if err != nil {
+		return nil, "", fmt.Errorf("config: decode etcd value: %w", err)
+	}
+	return value, keyExt(s.Ext, s.Key), nil
+}
+
+func fetch(client *http.Client, req *http.Request) ([]byte, *http.Response, error) {
+	if client == nil {
+		client = &http.Client{Timeout: defaultFetchTimeout}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 16<<20))
+	if err != nil {
+		return nil, nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, nil, fmt.Errorf("config: %s: unexpected status %s", req.URL.Redacted(), resp.Status)
+	}
+	return data, resp, nil
+}
+
+func keyExt(ext, key string) string {
+	if ext != "" {
+		return ext
+	}
+	if ext = path.Ext(key); ext != "" {
+		return ext
+	}
+	return ExtTOML
+}
+
+func extFromContentType(ct string) string {
+	mediaType, _, _ := mime.ParseMediaType(ct)
+	switch {
+	case strings.HasSuffix(mediaType, "json"):
+		return ExtJSON
+	case strings.HasSuffix(mediaType, "yaml"):
+		return ExtYAML
+	}
+	return ExtTOML
+}
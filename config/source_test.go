@@ -0,0 +1,210 @@
+package config
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+type sourceConfig struct {
+	Server struct {
+		Addr string `toml:"addr" json:"addr"`
+	} `toml:"Server" json:"Server"`
+}
+
+func TestOpenSource(t *testing.T) {
+	tests := []struct {
+		location string
+		want     Source
+	}{
+		{"conf/app.toml", FileSource{Path: "conf/app.toml"}},
+		{"https://cfg.example.com/app.toml", &HTTPSource{URL: "https://cfg.example.com/app.toml"}},
+		{"consul://127.0.0.1:8500/svc/app.toml", &ConsulSource{Addr: "http://127.0.0.1:8500", Key: "svc/app.toml"}},
+		{"etcd+https://etcd:2379/svc/app.json", &EtcdSource{Endpoint: "https://etcd:2379", Key: "/svc/app.json"}},
+	}
+	for _, tt := range tests {
+		got, err := OpenSource(tt.location)
+		if err != nil {
+			t.Fatalf("OpenSource(%q): %v", tt.location, err)
+		}
+		gotJSON, _ := json.Marshal(got)
+		wantJSON, _ := json.Marshal(tt.want)
+		if string(gotJSON) != string(wantJSON) {
+			t.Errorf("OpenSource(%q) = %s, want %s", tt.location, gotJSON, wantJSON)
+		}
+	}
+	if _, err := OpenSource("ftp://host/app.toml"); err == nil {
+		t.Error("expected error for unsupported scheme")
+	}
+	if _, err := OpenSource("consul://127.0.0.1:8500"); err == nil {
+		t.Error("expected error for consul source without key")
+	}
+}
+
+func TestHTTPSource(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer t" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.Write([]byte(`{"Server":{"addr":":9000"}}`))
+	}))
+	defer srv.Close()
+
+	src := &HTTPSource{URL: srv.URL + "/config", Header: http.Header{"Authorization": {"Bearer t"}}}
+	var cfg sourceConfig
+	if err := ParseSource(context.Background(), src, &cfg); err != nil {
+		t.Fatalf("ParseSource: %v", err)
+	}
+	if cfg.Server.Addr != ":9000" {
+		t.Errorf("addr = %q, want :9000", cfg.Server.Addr)
+	}
+
+	src.Header = nil
+	if err := ParseSource(context.Background(), src, &cfg); err == nil {
+		t.Error("expected error for non-2xx response")
+	}
+}
+
+func TestEtcdSource(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Key string `json:"key"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		key, _ := base64.StdEncoding.DecodeString(req.Key)
+		if r.URL.Path != "/v3/kv/range" || string(key) != "/svc/app.toml" {
+			w.Write([]byte(`{"header":{}}`))
+			return
+		}
+		value := base64.StdEncoding.EncodeToString([]byte("[Server]\naddr = \":7000\"\n"))
+		w.Write([]byte(`{"kvs":[{"key":"` + req.Key + `","value":"` + value + `"}]}`))
+	}))
+	defer srv.Close()
+
+	var cfg sourceConfig
+	if err := ParseSource(context.Background(), &EtcdSource{Endpoint: srv.URL, Key: "/svc/app.toml"}, &cfg); err != nil {
+		t.Fatalf("ParseSource: %v", err)
+	}
+	if cfg.Server.Addr != ":7000" {
+		t.Errorf("addr = %q, want :7000", cfg.Server.Addr)
+	}
+	if err := ParseSource(context.Background(), &EtcdSource{Endpoint: srv.URL, Key: "/missing"}, &cfg); err == nil {
+		t.Error("expected error for missing key")
+	}
+}
+
+// fakeConsul serves one KV key and implements blocking queries.
+type fakeConsul struct {
+	mu      sync.Mutex
+	index   uint64
+	value   string
+	changed chan struct{}
+}
+
+func (c *fakeConsul) set(value string) {
+	c.mu.Lock()
+	c.index++
+	c.value = value
+	close(c.changed)
+	c.changed = make(chan struct{})
+	c.mu.Unlock()
+}
+
+func (c *fakeConsul) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	c.mu.Lock()
+	index, value, changed := c.index, c.value, c.changed
+	c.mu.Unlock()
+	if want, _ := strconv.ParseUint(r.URL.Query().Get("index"), 10, 64); want >= index {
+		select {
+		case <-changed:
+		case <-r.Context().Done():
+			return
+		}
+		c.mu.Lock()
+		index, value = c.index, c.value
+		c.mu.Unlock()
+	}
+	w.Header().Set("X-Consul-Index", strconv.FormatUint(index, 10))
+	w.Write([]byte(value))
+}
+
+func TestConsulSourceWatch(t *testing.T) {
+	consul := &fakeConsul{index: 1, value: "[Server]\naddr = \":1\"\n", changed: make(chan struct{})}
+	srv := httptest.NewServer(consul)
+	defer srv.Close()
+
+	src := &ConsulSource{Addr: srv.URL, Key: "svc/app.toml"}
+	var cfg sourceConfig
+	if err := ParseSource(context.Background(), src, &cfg); err != nil {
+		t.Fatalf("ParseSource: %v", err)
+	}
+	if cfg.Server.Addr != ":1" {
+		t.Fatalf("addr = %q, want :1", cfg.Server.Addr)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	updates := make(chan string, 1)
+	go Watch(ctx, src, 0, func(data []byte, ext string) {
+		var next sourceConfig
+		if err := ParseBytes(ext, data, &next); err == nil {
+			updates <- next.Server.Addr
+		}
+	})
+
+	time.Sleep(50 * time.Millisecond)
+	consul.set("[Server]\naddr = \":2\"\n")
+	select {
+	case addr := <-updates:
+		if addr != ":2" {
+			t.Errorf("updated addr = %q, want :2", addr)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("no update delivered")
+	}
+}
+
+func TestWatchPolls(t *testing.T) {
+	var mu sync.Mutex
+	body := `{"Server":{"addr":":1"}}`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		w.Write([]byte(body))
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	updates := make(chan string, 4)
+	go Watch(ctx, &HTTPSource{URL: srv.URL + "/app.json"}, 10*time.Millisecond, func(data []byte, ext string) {
+		updates <- string(data)
+	})
+
+	time.Sleep(30 * time.Millisecond)
+	select {
+	case got := <-updates:
+		t.Fatalf("unexpected update for unchanged content: %s", got)
+	default:
+	}
+
+	mu.Lock()
+	body = `{"Server":{"addr":":2"}}`
+	mu.Unlock()
+	select {
+	case got := <-updates:
+		if got != `{"Server":{"addr":":2"}}` {
+			t.Errorf("update = %s", got)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("no update delivered")
+	}
+}
@@ -0,0 +1,131 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/hansir-hsj/GoLiteKit/internal/rules"
+)
+
+// ApplyDefaults sets every zero-valued field of obj that has a
+// `default:"..."` tag to the tag value, parsed like an environment override
+// (see ApplyEnvOverrides). Nested structs are walked.
+func ApplyDefaults(obj any) error {
+	v := reflect.ValueOf(obj)
+	if v.Kind() != reflect.Pointer || v.IsNil() {
+		return fmt.Errorf("obj must be a non-nil pointer: %T", obj)
+	}
+	if v.Elem().Kind() != reflect.Struct {
+		return nil
+	}
+	return applyDefaults(v.Elem())
+}
+
+func applyDefaults(v reflect.Value) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		fv := v.Field(i)
+		if def, ok := sf.Tag.Lookup("default"); ok && fv.IsZero() {
+			if err := setFromString(fv, def); err != nil {
+				return fmt.Errorf("config: default of %s: %w", sf.Name, err)
+			}
+			continue
+		}
+		if fv.Kind() == reflect.Struct && !isTextValue(fv) {
+			if err := applyDefaults(fv); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Validate checks the `validate:"..."` tags of obj and returns every
+// violation at once, joined with errors.Join. The rules are those of request
+// validation: required, min=N, max=N, len=N, email and oneof=a b c, with min
+// and max bounding numbers or the length of strings, slices and maps. Rules
+// it does not know, e.g. go-playground's gte, are ignored.
+//
+// Fields are named by their tag path for ext, e.g. "logger.maxFileNum".
+// Rules other than required accept unset strings and nil pointers, so
+// combine them with required (or a default tag) when a value is mandatory.
+func Validate(ext string, obj any) error {
+	v := reflect.ValueOf(obj)
+	if v.Kind() != reflect.Pointer || v.IsNil() {
+		return fmt.Errorf("obj must be a non-nil pointer: %T", obj)
+	}
+	if v.Elem().Kind() != reflect.Struct {
+		return nil
+	}
+	var errs []error
+	validateStruct(v.Elem(), "", tagForExt(ext), &errs)
+	return errors.Join(errs...)
+}
+
+func validateStruct(v reflect.Value, prefix, tag string, errs *[]error) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		key, skip := fieldKey(sf, tag)
+		if skip {
+			continue
+		}
+		path := prefix
+		if key != "" {
+			path = joinPath(prefix, key)
+		}
+		fv := v.Field(i)
+		if rules := sf.Tag.Get("validate"); rules != "" {
+			for _, rule := range strings.Split(rules, ",") {
+				if err := checkRule(fv, path, strings.TrimSpace(rule)); err != nil {
+					*errs = append(*errs, err)
+				}
+			}
+		}
+		if fv.Kind() == reflect.Struct && !isTextValue(fv) {
+			validateStruct(fv, path, tag, errs)
+		}
+	}
+}
+
+func joinPath(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
+
+func checkRule(v reflect.Value, path, rule string) error {
+	name, param, _ := strings.Cut(rule, "=")
+	if ok, known := rules.Check(v, name, param); ok || !known {
+		return nil
+	}
+	subject := path
+	switch reflect.Indirect(v).Kind() {
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		subject += " length"
+	}
+	switch name {
+	case "required":
+		return fmt.Errorf("%s is required", path)
+	case "min":
+		return fmt.Errorf("%s must be >= %s", subject, param)
+	case "max":
+		return fmt.Errorf("%s must be <= %s", subject, param)
+	case "len":
+		return fmt.Errorf("%s must be %s", subject, param)
+	case "email":
+		return fmt.Errorf("%s must be an email address", path)
+	default:
+		return fmt.Errorf("%s must be one of [%s], got %q", path, strings.Join(strings.Fields(param), " "), fmt.Sprint(reflect.Indirect(v).Interface()))
+	}
+}
@@ -1,15 +1,21 @@
 package golitekit
 
 import (
+	"bytes"
 	"context"
+	"crypto/x509"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"reflect"
 	"strings"
 	"sync"
 	"time"
 
+	glkdb "github.com/hansir-hsj/GoLiteKit/db"
 	"github.com/hansir-hsj/GoLiteKit/logger"
+	glkredis "github.com/hansir-hsj/GoLiteKit/redis"
 	"github.com/redis/go-redis/v9"
 	"gorm.io/gorm"
 )
@@ -61,12 +67,23 @@ type Context struct {
 
 	rawResponse  any
 	jsonResponse any
+	jsonBuf      *bytes.Buffer             // pooled backing of jsonResponse, see JSON
+	streamJSON   int                       // StreamJSONThreshold of ContextAsMiddleware
+	responseOpts *ContextMiddlewareOptions // set by ContextAsMiddleware
 	rawHtml      string
 	statusCode   int
 
 	sseWriter *SSEWriter
 
-	logID string
+	aborted     bool
+	abortErr    *AppError
+	redirectURL string
+
+	principal *Principal
+	tenant    *Tenant
+
+	logID   string
+	baggage map[string]string
 
 	data     map[string]any
 	dataLock sync.RWMutex
@@ -201,20 +218,127 @@ func (ctx *Context) PanicLogger() *logger.PanicLogger {
 	return ctx.panicLogger
 }
 
+// DB returns the database bound to the request context, so queries are
+// canceled when the client goes away or the TimeoutMiddleware deadline
+// expires. It is the tenant's database when the request's Tenant has one.
 func (ctx *Context) DB() *gorm.DB {
+	db := ctx.db()
+	if db == nil {
+		return nil
+	}
+	return db.WithContext(ctx.Context())
+}
+
+func (ctx *Context) db() *gorm.DB {
+	if t := ctx.Tenant(); t != nil && t.DB != nil {
+		return t.DB
+	}
 	if ctx.services == nil {
 		return nil
 	}
 	return ctx.services.DB()
 }
 
+// Redis returns the Redis client, the tenant's when the request's Tenant has
+// one. Pass ctx.Context() (or a controller's ctx) to its commands to bound
+// them by the request deadline.
 func (ctx *Context) Redis() *redis.Client {
+	if t := ctx.Tenant(); t != nil && t.Redis != nil {
+		return t.Redis
+	}
 	if ctx.services == nil {
 		return nil
 	}
 	return ctx.services.Redis()
 }
 
+// Querier returns the database set on the request context by db.WithDB,
+// or else the tenant's or app's database, or nil. Controllers depending on
+// it instead of DB can be tested with a fake.
+func (ctx *Context) Querier() glkdb.Querier {
+	if q := glkdb.FromContext(ctx.Context()); q != nil {
+		return q
+	}
+	db := ctx.db()
+	if db == nil {
+		return nil
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil
+	}
+	return sqlDB
+}
+
+// Cmdable returns the Redis client set on the request context by
+// redis.WithRedis, or else the app's Redis client, or nil.
+func (ctx *Context) Cmdable() glkredis.Cmdable {
+	if c := glkredis.FromContext(ctx.Context()); c != nil {
+		return c
+	}
+	if client := ctx.Redis(); client != nil {
+		return client
+	}
+	return nil
+}
+
+// Context returns the request's context.Context, which carries the
+// TimeoutMiddleware deadline and is canceled when the client disconnects.
+func (ctx *Context) Context() context.Context {
+	if ctx.request == nil {
+		return context.Background()
+	}
+	return ctx.request.Context()
+}
+
+// Deadline returns the request deadline, if any.
+func (ctx *Context) Deadline() (time.Time, bool) {
+	return ctx.Context().Deadline()
+}
+
+// Remaining returns the time left until the request deadline; ok is false
+// when the request has no deadline. Use it to size budgets of backend calls
+// that do not take a context.
+func (ctx *Context) Remaining() (d time.Duration, ok bool) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return 0, false
+	}
+	return time.Until(deadline), true
+}
+
+// ClientCertificate returns the leaf certificate of the verified mutual TLS
+// chain, or nil when the client presented no certificate that verified
+// against the configured client CA.
+func (ctx *Context) ClientCertificate() *x509.Certificate {
+	if ctx.request == nil || ctx.request.TLS == nil {
+		return nil
+	}
+	chains := ctx.request.TLS.VerifiedChains
+	if len(chains) == 0 || len(chains[0]) == 0 {
+		return nil
+	}
+	return chains[0][0]
+}
+
+// ClientSubject returns the distinguished name of the verified client
+// certificate (e.g. "CN=alice,OU=ops,O=Example"), or "" without one.
+func (ctx *Context) ClientSubject() string {
+	cert := ctx.ClientCertificate()
+	if cert == nil {
+		return ""
+	}
+	return cert.Subject.String()
+}
+
+// Exports returns the ExportManager installed with WithExportManager.
+func (ctx *Context) Exports() *ExportManager {
+	if ctx.services == nil {
+		return nil
+	}
+	return ctx.services.Exports()
+}
+
 // Service retrieves a startup-registered custom service.
 func (ctx *Context) Service(key string) any {
 	if ctx.services == nil {
@@ -235,12 +359,54 @@ func (ctx *Context) setHTMLResponse(html string) {
 	ctx.rawHtml = html
 }
 
+// ContextMiddlewareOptions configures ContextAsMiddleware.
+type ContextMiddlewareOptions struct {
+	// MaxResponseBytes caps the body a handler may buffer on the Context,
+	// so an accidental huge payload, e.g. a query returning millions of
+	// rows, is not written out. 0 means no limit.
+	MaxResponseBytes int
+	// TruncateOversized writes the first MaxResponseBytes of an oversized
+	// String or Bytes body instead of rejecting it. JSON and HTML bodies are
+	// always rejected, a truncated document being invalid.
+	TruncateOversized bool
+	// StreamJSONThreshold, when positive, makes JSON stream a list of at
+	// least this many elements, top level or in the `any` field of an
+	// envelope such as Response.Data, one element at a time into the
+	// response writer instead of encoding it in full first. Peak memory no
+	// longer grows with the list, but an encoding error past the first
+	// bytes can only cut the response short.
+	StreamJSONThreshold int
+}
+
+// oversized handles a body of n bytes over the limit: it records the size
+// on the request log and returns the error to answer with, or nil when the
+// body is to be truncated.
+func (opt ContextMiddlewareOptions) oversized(ctx context.Context, n int, truncatable bool) error {
+	logger.AddWarning(ctx, "response_too_large", n)
+	if truncatable && opt.TruncateOversized {
+		return nil
+	}
+	return ErrInternal("Response too large",
+		fmt.Errorf("response of %d bytes exceeds the limit of %d", n, opt.MaxResponseBytes))
+}
+
 // ContextAsMiddleware writes the buffered response stored in Context (via
 // JSON / String / HTML) after the inner handler returns.
 // Errors returned by the inner handler are propagated without writing a response.
-func ContextAsMiddleware() Middleware {
+// A body over opts.MaxResponseBytes is answered with a 500, see
+// ContextMiddlewareOptions.
+func ContextAsMiddleware(opts ...ContextMiddlewareOptions) Middleware {
+	var opt ContextMiddlewareOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
 	return func(next Handler) Handler {
 		return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			if gcx := GetContext(ctx); gcx != nil {
+				gcx.streamJSON = opt.StreamJSONThreshold
+				gcx.responseOpts = &opt
+			}
 			err := next(ctx, w, r)
 			if err != nil {
 				return err
@@ -250,56 +416,112 @@ func ContextAsMiddleware() Middleware {
 			if gcx == nil {
 				return nil
 			}
+			return opt.writeResponse(ctx, gcx, w, r)
+		}
+	}
+}
 
-			statusCode := http.StatusOK
-			if gcx.statusCode != 0 {
-				statusCode = gcx.statusCode
-			}
+// writeResponse writes the response buffered in gcx.
+func (opt ContextMiddlewareOptions) writeResponse(ctx context.Context, gcx *Context, w http.ResponseWriter, r *http.Request) error {
+	limit := opt.MaxResponseBytes
 
-			if gcx.jsonResponse != nil {
-				w.Header().Set("Content-Type", "application/json")
-				w.WriteHeader(statusCode)
-				if bytes, ok := gcx.jsonResponse.([]byte); ok {
-					if _, err := w.Write(bytes); err != nil {
-						return ErrInternal("failed to write response", err)
-					}
-				} else {
-					jsonData, err := json.Marshal(gcx.jsonResponse)
-					if err != nil {
-						return ErrInternal("Failed to marshal JSON response", err)
-					}
-					if _, err := w.Write(jsonData); err != nil {
-						return ErrInternal("failed to write response", err)
-					}
-				}
-			} else if gcx.rawResponse != nil {
-				switch body := gcx.rawResponse.(type) {
-				case []byte:
-					w.Header().Set("Content-Type", "application/octet-stream")
-					w.WriteHeader(statusCode)
-					if _, err := w.Write(body); err != nil {
-						return ErrInternal("failed to write response", err)
-					}
-				case string:
-					w.Header().Set("Content-Type", "text/plain; charset=UTF-8")
-					w.WriteHeader(statusCode)
-					if _, err := w.Write([]byte(body)); err != nil {
-						return ErrInternal("failed to write response", err)
-					}
-				default:
-					return ErrInternal("Unsupported response type", nil)
-				}
-			} else if gcx.rawHtml != "" {
-				w.Header().Set("Content-Type", "text/html; charset=UTF-8")
-				w.WriteHeader(statusCode)
-				if _, err := w.Write([]byte(gcx.rawHtml)); err != nil {
-					return ErrInternal("failed to write response", err)
-				}
-			}
+	statusCode := http.StatusOK
+	if gcx.statusCode != 0 {
+		statusCode = gcx.statusCode
+	}
 
-			return nil
+	if stream, ok := gcx.jsonResponse.(jsonStream); ok {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(statusCode)
+		cw := &countingWriter{w: w, limit: limit}
+		err := streamJSON(cw, stream.value, stream.threshold)
+		allocStats.recordJSON(uint64(cw.n))
+		if errors.Is(err, errStreamLimit) {
+			return opt.oversized(ctx, cw.n, false)
+		}
+		if err != nil {
+			return ErrInternal("Failed to stream JSON response", err)
+		}
+	} else if gcx.jsonResponse != nil {
+		data, ok := gcx.jsonResponse.([]byte)
+		if !ok {
+			jsonData, err := json.Marshal(gcx.jsonResponse)
+			if err != nil {
+				return ErrInternal("Failed to marshal JSON response", err)
+			}
+			data = jsonData
+		}
+		if limit > 0 && len(data) > limit {
+			gcx.releaseJSONBuffer()
+			return opt.oversized(ctx, len(data), false)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(statusCode)
+		_, err := w.Write(data)
+		gcx.releaseJSONBuffer()
+		if err != nil {
+			return ErrInternal("failed to write response", err)
+		}
+	} else if gcx.rawResponse != nil {
+		var body []byte
+		switch b := gcx.rawResponse.(type) {
+		case []byte:
+			body = b
+			w.Header().Set("Content-Type", "application/octet-stream")
+		case string:
+			body = []byte(b)
+			w.Header().Set("Content-Type", "text/plain; charset=UTF-8")
+		default:
+			return ErrInternal("Unsupported response type", nil)
+		}
+		if limit > 0 && len(body) > limit {
+			if err := opt.oversized(ctx, len(body), true); err != nil {
+				w.Header().Del("Content-Type")
+				return err
+			}
+			body = body[:limit]
+		}
+		w.WriteHeader(statusCode)
+		if _, err := w.Write(body); err != nil {
+			return ErrInternal("failed to write response", err)
+		}
+	} else if gcx.rawHtml != "" {
+		if limit > 0 && len(gcx.rawHtml) > limit {
+			return opt.oversized(ctx, len(gcx.rawHtml), false)
+		}
+		w.Header().Set("Content-Type", "text/html; charset=UTF-8")
+		w.WriteHeader(statusCode)
+		if _, err := w.Write([]byte(gcx.rawHtml)); err != nil {
+			return ErrInternal("failed to write response", err)
 		}
+	} else if gcx.redirectURL != "" {
+		http.Redirect(w, r, gcx.redirectURL, statusCode)
 	}
+
+	return nil
+}
+
+// clearResponse drops the buffered response.
+func (ctx *Context) clearResponse() {
+	ctx.releaseJSONBuffer()
+	ctx.jsonResponse = nil
+	ctx.rawResponse = nil
+	ctx.rawHtml = ""
+	ctx.redirectURL = ""
+}
+
+// flushResponse writes the response buffered in the Context of ctx to w
+// ahead of ContextAsMiddleware. Middleware registered with Use runs inside
+// ContextAsMiddleware, so one that captures the response, such as
+// IdempotencyMiddleware, calls it to see JSON, String and HTML responses.
+// The response is cleared so ContextAsMiddleware does not write it again.
+func flushResponse(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	gcx := GetContext(ctx)
+	if gcx == nil || gcx.responseOpts == nil {
+		return nil
+	}
+	defer gcx.clearResponse()
+	return gcx.responseOpts.writeResponse(ctx, gcx, w, r)
 }
 
 func (sse *SSEWriter) Send(event SSEvent) error {
@@ -337,13 +559,21 @@ func (sse *SSEWriter) Send(event SSEvent) error {
 		return err
 	}
 
-	if f, ok := sse.w.(http.Flusher); ok {
-		f.Flush()
-	}
+	_ = http.NewResponseController(sse.w).Flush()
 
 	return nil
 }
 
+// Comment sends an SSE comment line, which clients ignore; use it as a
+// heartbeat or to commit the response before the first event.
+func (sse *SSEWriter) Comment(text string) error {
+	if _, err := fmt.Fprintf(sse.w, ": %s\n\n", sse.sanitize(text)); err != nil {
+		return err
+	}
+	_ = http.NewResponseController(sse.w).Flush()
+	return nil
+}
+
 func (sse *SSEWriter) sanitize(data string) string {
 	data = strings.ReplaceAll(data, "\r", "")
 	data = strings.ReplaceAll(data, "\n", "")
@@ -351,6 +581,10 @@ func (sse *SSEWriter) sanitize(data string) string {
 }
 
 func (sse *SSEWriter) serializeData(data any) (string, error) {
+	return serializeSSEData(data)
+}
+
+func serializeSSEData(data any) (string, error) {
 	switch v := data.(type) {
 	case string:
 		return v, nil
@@ -372,6 +606,34 @@ func (ctx *Context) SSEWriter() *SSEWriter {
 	return ctx.sseWriter
 }
 
+// Locales returns the request's preferred locales: the "lang" query parameter
+// first, then Accept-Language.
+func (ctx *Context) Locales() []string {
+	return RequestLocales(ctx.request)
+}
+
+// Catalog returns the message catalog configured with WithCatalog.
+func (ctx *Context) Catalog() *Catalog {
+	return ctx.services.Catalog()
+}
+
+// T translates key for the request locales, replacing {name} placeholders
+// with args.
+func (ctx *Context) T(key string, args map[string]string) string {
+	return ctx.Catalog().Translate(ctx.Locales(), key, args)
+}
+
+// Validate checks the `validate` struct tags of v with messages translated
+// for the request locales. Failures are returned as a 400 AppError wrapping
+// ValidationErrors.
+func (ctx *Context) Validate(v any) error {
+	err := ValidateStruct(v, ctx.T)
+	if err == nil {
+		return nil
+	}
+	return ErrBadRequest(ctx.T("validation.failed", nil), err)
+}
+
 // Query returns query parameter value.
 func (ctx *Context) Query(key string) string {
 	if ctx.request == nil {
@@ -396,17 +658,37 @@ func (ctx *Context) Param(key string) string {
 	return ctx.request.PathValue(key)
 }
 
-// JSON writes JSON response with status code.
+// JSON writes JSON response with status code. A list at least as long as
+// ContextMiddlewareOptions.StreamJSONThreshold is streamed instead.
 func (ctx *Context) JSON(code int, data any) error {
-	jsonData, err := json.Marshal(data)
+	if ctx.streamJSON > 0 && wantsStream(reflect.ValueOf(data), ctx.streamJSON, 0) {
+		ctx.releaseJSONBuffer()
+		ctx.statusCode = code
+		ctx.setJSONResponse(jsonStream{value: data, threshold: ctx.streamJSON})
+		return nil
+	}
+	jsonData, buf, err := encodeJSON(data)
 	if err != nil {
 		return err
 	}
+	ctx.releaseJSONBuffer()
+	ctx.jsonBuf = buf
 	ctx.statusCode = code
 	ctx.setJSONResponse(jsonData)
 	return nil
 }
 
+// releaseJSONBuffer returns the buffer behind the JSON response to the pool
+// once it has been written; the response must not be read afterwards.
+func (ctx *Context) releaseJSONBuffer() {
+	if ctx.jsonBuf == nil {
+		return
+	}
+	putJSONBuffer(ctx.jsonBuf)
+	ctx.jsonBuf = nil
+	ctx.jsonResponse = nil
+}
+
 // String writes plain text response with status code.
 func (ctx *Context) String(code int, s string) error {
 	ctx.statusCode = code
@@ -427,3 +709,62 @@ func (ctx *Context) HTML(code int, html string) error {
 	ctx.setHTMLResponse(html)
 	return nil
 }
+
+// WriteEarlyHints sends a 103 Early Hints response carrying links as Link
+// headers, so the client starts fetching assets while the page renders:
+//
+//	ctx.WriteEarlyHints("</app.css>; rel=preload; as=style", "</app.js>; rel=preload; as=script")
+//
+// Call it before the response is written. The links are not repeated on the
+// final response; set the Link header for that.
+func (ctx *Context) WriteEarlyHints(links ...string) error {
+	if ctx.responseWriter == nil {
+		return ErrInternal("no response writer", nil)
+	}
+	if len(links) == 0 {
+		return nil
+	}
+	header := ctx.responseWriter.Header()
+	saved := header.Values("Link")
+	header["Link"] = links
+	ctx.responseWriter.WriteHeader(http.StatusEarlyHints)
+	if len(saved) > 0 {
+		header["Link"] = saved
+	} else {
+		header.Del("Link")
+	}
+	return nil
+}
+
+// Abort stops the controller lifecycle: the router skips the remaining
+// Init/ParseRequest/Validate/Serve/Finalize steps and hands err to the error
+// pipeline. With a nil err the response set so far (JSON, String, ...) is
+// written as usual. It returns err, so `return ctx.Abort(err)` also works.
+func (ctx *Context) Abort(err *AppError) error {
+	ctx.aborted = true
+	ctx.abortErr = err
+	return ctx.abortError()
+}
+
+// Aborted reports whether Abort or Redirect was called.
+func (ctx *Context) Aborted() bool {
+	return ctx != nil && ctx.aborted
+}
+
+// abortError returns the Abort error without the typed-nil pitfall.
+func (ctx *Context) abortError() error {
+	if ctx.abortErr == nil {
+		return nil
+	}
+	return ctx.abortErr
+}
+
+// Redirect answers with a redirect to url (relative URLs are resolved
+// against the request path) and aborts the controller lifecycle. A response
+// set before, e.g. by JSON, is discarded.
+func (ctx *Context) Redirect(code int, url string) error {
+	ctx.clearResponse()
+	ctx.statusCode = code
+	ctx.redirectURL = url
+	return ctx.Abort(nil)
+}
@@ -2,11 +2,24 @@ package golitekit
 
 import (
 	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/http/httptrace"
+	"net/textproto"
 	"strings"
 	"sync"
 	"testing"
+	"time"
+
+	glkdb "github.com/hansir-hsj/GoLiteKit/db"
+	glkredis "github.com/hansir-hsj/GoLiteKit/redis"
+	"github.com/redis/go-redis/v9"
+	mysqlDriver "gorm.io/driver/mysql"
+	"gorm.io/gorm"
 )
 
 func TestWithFrameworkContext(t *testing.T) {
@@ -232,6 +245,57 @@ func TestContextAsMiddleware(t *testing.T) {
 	})
 }
 
+func TestContextAsMiddlewareMaxResponseBytes(t *testing.T) {
+	serve := func(opt ContextMiddlewareOptions, write func(*Context) error) (*httptest.ResponseRecorder, error) {
+		ctx := withContext(context.Background())
+		gcx := GetContext(ctx)
+		req := httptest.NewRequest("GET", "/test", nil).WithContext(ctx)
+		rec := httptest.NewRecorder()
+		gcx.setContextOptions(withRequest(req), withResponseWriter(rec))
+		if err := write(gcx); err != nil {
+			t.Fatalf("write failed: %v", err)
+		}
+		inner := Handler(func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			return nil
+		})
+		err := ContextAsMiddleware(opt)(inner)(req.Context(), rec, req)
+		return rec, err
+	}
+	rows := make([]int, 100)
+
+	rec, err := serve(ContextMiddlewareOptions{MaxResponseBytes: 64}, func(c *Context) error {
+		return c.JSON(http.StatusOK, rows)
+	})
+	var appErr *AppError
+	if !errors.As(err, &appErr) || appErr.Code != http.StatusInternalServerError {
+		t.Fatalf("oversized JSON: err = %v, want a 500", err)
+	}
+	if rec.Body.Len() > 0 {
+		t.Errorf("oversized JSON wrote %q", rec.Body.String())
+	}
+
+	rec, err = serve(ContextMiddlewareOptions{MaxResponseBytes: 64, TruncateOversized: true}, func(c *Context) error {
+		return c.JSON(http.StatusOK, rows)
+	})
+	if err == nil || rec.Body.Len() > 0 {
+		t.Errorf("JSON must be rejected even with TruncateOversized: err = %v, body %q", err, rec.Body.String())
+	}
+
+	rec, err = serve(ContextMiddlewareOptions{MaxResponseBytes: 5, TruncateOversized: true}, func(c *Context) error {
+		return c.String(http.StatusOK, "hello world")
+	})
+	if err != nil || rec.Body.String() != "hello" {
+		t.Errorf("truncated string: err = %v, body %q, want hello", err, rec.Body.String())
+	}
+
+	rec, err = serve(ContextMiddlewareOptions{MaxResponseBytes: 64}, func(c *Context) error {
+		return c.JSON(http.StatusOK, rows[:3])
+	})
+	if err != nil || rec.Body.String() != "[0,0,0]" {
+		t.Errorf("small JSON: err = %v, body %q", err, rec.Body.String())
+	}
+}
+
 func TestSSEWriter(t *testing.T) {
 	t.Run("sends basic event", func(t *testing.T) {
 		rec := httptest.NewRecorder()
@@ -335,3 +399,133 @@ func TestContextServiceReadsStartupRegisteredService(t *testing.T) {
 		t.Fatalf("status = %d, want %d; body = %s", rec.Code, http.StatusOK, rec.Body.String())
 	}
 }
+
+func TestContextDeadlineReachesDB(t *testing.T) {
+	gdb, err := gorm.Open(mysqlDriver.New(mysqlDriver.Config{DSN: "u:p@tcp(127.0.0.1:1)/x", SkipInitializeWithVersion: true}), &gorm.Config{DisableAutomaticPing: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	svc := &Services{}
+	WithDB(gdb)(svc)
+	r := NewRouter(svc)
+	r.Use(ErrorHandlerMiddleware())
+	r.Use(TimeoutMiddleware(TimeoutOptions{Duration: time.Minute}))
+	r.Use(ContextAsMiddleware())
+
+	r.GET("/deadline", func(ctx *Context) error {
+		deadline, ok := ctx.Deadline()
+		if !ok {
+			return ErrInternal("no request deadline", nil)
+		}
+		if left, ok := ctx.Remaining(); !ok || left <= 0 || left > time.Minute {
+			return ErrInternal(fmt.Sprintf("remaining = %v", left), nil)
+		}
+		if dbDeadline, ok := ctx.DB().Statement.Context.Deadline(); !ok || !dbDeadline.Equal(deadline) {
+			return ErrInternal("db context lacks the request deadline", nil)
+		}
+		return ctx.String(http.StatusOK, "ok")
+	})
+
+	rec := httptest.NewRecorder()
+	r.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/deadline", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("code = %d: %s", rec.Code, rec.Body)
+	}
+
+	var gcx Context
+	if _, ok := gcx.Deadline(); ok || gcx.Context() == nil {
+		t.Error("a Context without a request must have no deadline")
+	}
+}
+
+type fakeQuerier struct{ glkdb.Querier }
+
+func TestContextPrefersInjectedQuerierAndCmdable(t *testing.T) {
+	gdb, err := gorm.Open(mysqlDriver.New(mysqlDriver.Config{DSN: "u:p@tcp(127.0.0.1:1)/x", SkipInitializeWithVersion: true}), &gorm.Config{DisableAutomaticPing: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	appRedis := redis.NewClient(&redis.Options{Addr: "127.0.0.1:1"})
+	defer appRedis.Close()
+	svc := &Services{}
+	WithDB(gdb)(svc)
+	WithRedis(appRedis)(svc)
+	r := newTestRouter()
+	r.services = svc
+
+	fakeDB := &fakeQuerier{}
+	fakeRedis := redis.NewClient(&redis.Options{Addr: "127.0.0.1:2"})
+	defer fakeRedis.Close()
+	var injected bool
+	r.GET("/q", func(ctx *Context) error {
+		q, c := ctx.Querier(), ctx.Cmdable()
+		if injected && (q != glkdb.Querier(fakeDB) || c != glkredis.Cmdable(fakeRedis)) {
+			return ErrInternal("injected fakes not used", nil)
+		}
+		if !injected {
+			if _, ok := q.(*sql.DB); !ok || c != glkredis.Cmdable(appRedis) {
+				return ErrInternal("app services not used", nil)
+			}
+		}
+		return ctx.String(http.StatusOK, "ok")
+	})
+
+	for _, injected = range []bool{false, true} {
+		req := httptest.NewRequest(http.MethodGet, "/q", nil)
+		if injected {
+			reqCtx := glkdb.WithDB(req.Context(), fakeDB)
+			req = req.WithContext(glkredis.WithRedis(reqCtx, fakeRedis))
+		}
+		rec := httptest.NewRecorder()
+		r.Handler().ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("injected=%v: code = %d: %s", injected, rec.Code, rec.Body)
+		}
+	}
+}
+
+func TestContextWriteEarlyHints(t *testing.T) {
+	app := NewApp(WithDefaultMiddlewareOptions(func(o *DefaultMiddlewareOptions) {
+		o.Timeout.Duration = time.Second
+	}))
+	app.Use(CompressionMiddleware())
+	app.GET("/page", HandlerFunc(func(ctx *Context) error {
+		ctx.ResponseWriter().Header().Set("X-Page", "1")
+		if err := ctx.WriteEarlyHints("</app.css>; rel=preload; as=style"); err != nil {
+			return err
+		}
+		return ctx.HTML(http.StatusOK, "<h1>page</h1>")
+	}))
+	srv := httptest.NewServer(app.Handler())
+	defer srv.Close()
+
+	var hints []string
+	var hintCodes []int
+	trace := &httptrace.ClientTrace{
+		Got1xxResponse: func(code int, header textproto.MIMEHeader) error {
+			hintCodes = append(hintCodes, code)
+			hints = append(hints, header.Values("Link")...)
+			return nil
+		},
+	}
+	req, _ := http.NewRequestWithContext(httptrace.WithClientTrace(context.Background(), trace), http.MethodGet, srv.URL+"/page", nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	if len(hintCodes) != 1 || hintCodes[0] != http.StatusEarlyHints {
+		t.Fatalf("1xx responses = %v, want one 103", hintCodes)
+	}
+	if len(hints) != 1 || hints[0] != "</app.css>; rel=preload; as=style" {
+		t.Errorf("early hint links = %q", hints)
+	}
+	if resp.StatusCode != http.StatusOK || string(body) != "<h1>page</h1>" {
+		t.Errorf("final response = %d %q", resp.StatusCode, body)
+	}
+	if resp.Header.Get("Link") != "" || resp.Header.Get("X-Page") != "1" {
+		t.Errorf("final headers = %v", resp.Header)
+	}
+}
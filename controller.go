@@ -3,7 +3,9 @@ package golitekit
 import (
 	"bytes"
 	"context"
+	"crypto/x509"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"mime"
@@ -12,8 +14,11 @@ import (
 	"reflect"
 	"strconv"
 	"strings"
+	"time"
 
+	glkdb "github.com/hansir-hsj/GoLiteKit/db"
 	"github.com/hansir-hsj/GoLiteKit/logger"
+	glkredis "github.com/hansir-hsj/GoLiteKit/redis"
 	"github.com/redis/go-redis/v9"
 	"gorm.io/gorm"
 )
@@ -39,12 +44,24 @@ type Controller interface {
 }
 
 // Optional lifecycle hooks. Implement these interfaces to customize behavior.
+// The router calls the implemented ones in this order:
+//
+//	Init → SanityCheck → ParseRequest → Validate → BeforeServe → Serve → AfterServe → Finalize
+//
+// An error, Abort or Redirect skips the remaining steps. Errors default to
+// 400 for SanityCheck, ParseRequest and Validate and to 500 for the others.
 
 // Initializer is called first to set up per-request state.
 type Initializer interface {
 	Init(ctx context.Context) error
 }
 
+// SanityChecker is called after Init, before the body is read, for cheap
+// checks of the raw request such as required headers or content types.
+type SanityChecker interface {
+	SanityCheck(ctx context.Context) error
+}
+
 // Validator is called after ParseRequest to validate parsed request data and controller state.
 type Validator interface {
 	Validate(ctx context.Context) error
@@ -57,11 +74,46 @@ type RequestParser interface {
 	ParseRequest(ctx context.Context) error
 }
 
+// BeforeServeHook is called after Validate, right before Serve, e.g. to load
+// resources the validated request refers to.
+type BeforeServeHook interface {
+	BeforeServe(ctx context.Context) error
+}
+
+// AfterServeHook is called after a successful Serve, before Finalize, e.g. to
+// decorate the response.
+type AfterServeHook interface {
+	AfterServe(ctx context.Context) error
+}
+
 // Finalizer is called after Serve for cleanup, metrics, or audit logging.
 type Finalizer interface {
 	Finalize(ctx context.Context) error
 }
 
+// LifecycleController implements every lifecycle hook. BaseControllerOf
+// provides defaults for all of them, so controllers override only the steps
+// they need.
+type LifecycleController interface {
+	Controller
+	Initializer
+	SanityChecker
+	RequestParser
+	Validator
+	BeforeServeHook
+	AfterServeHook
+	Finalizer
+}
+
+var _ LifecycleController = (*BaseController)(nil)
+
+// MiddlewareProvider declares middlewares (auth, caching, ...) that wrap only
+// this controller's routes. Middlewares is called once at registration, after
+// the global and group middlewares, so it must not depend on request state.
+type MiddlewareProvider interface {
+	Middlewares() []Middleware
+}
+
 // BaseControllerOf is a generic controller base. T is the request struct type.
 // Use BaseController directly when no request body is needed.
 type BaseControllerOf[T any] struct {
@@ -107,6 +159,45 @@ func (c *BaseControllerOf[T]) Redis() *redis.Client {
 	return c.gcx.Redis()
 }
 
+// Querier returns the request's database, see Context.Querier.
+func (c *BaseControllerOf[T]) Querier() glkdb.Querier {
+	if c.gcx == nil {
+		return nil
+	}
+	return c.gcx.Querier()
+}
+
+// Cmdable returns the request's Redis client, see Context.Cmdable.
+func (c *BaseControllerOf[T]) Cmdable() glkredis.Cmdable {
+	if c.gcx == nil {
+		return nil
+	}
+	return c.gcx.Cmdable()
+}
+
+// ClientCertificate returns the verified mutual TLS client certificate, if any.
+func (c *BaseControllerOf[T]) ClientCertificate() *x509.Certificate {
+	if c.gcx == nil {
+		return nil
+	}
+	return c.gcx.ClientCertificate()
+}
+
+// ClientSubject returns the distinguished name of the verified client certificate.
+func (c *BaseControllerOf[T]) ClientSubject() string {
+	if c.gcx == nil {
+		return ""
+	}
+	return c.gcx.ClientSubject()
+}
+
+func (c *BaseControllerOf[T]) Exports() *ExportManager {
+	if c.gcx == nil {
+		return nil
+	}
+	return c.gcx.Exports()
+}
+
 func (c *BaseControllerOf[T]) Service(key string) any {
 	if c.gcx == nil {
 		return nil
@@ -118,6 +209,27 @@ func (c *BaseControllerOf[T]) Validate(ctx context.Context) error {
 	return nil
 }
 
+// ValidateRequest checks the `validate` struct tags of Request, translating
+// messages for the request locale. Controllers opt in by calling it from
+// Validate.
+func (c *BaseControllerOf[T]) ValidateRequest() error {
+	if c.gcx == nil {
+		return nil
+	}
+	if _, isNoBody := any(c.Request).(NoBody); isNoBody {
+		return nil
+	}
+	return c.gcx.Validate(&c.Request)
+}
+
+// T translates key for the request locale.
+func (c *BaseControllerOf[T]) T(key string, args map[string]string) string {
+	if c.gcx == nil {
+		return DefaultCatalog().Translate(nil, key, args)
+	}
+	return c.gcx.T(key, args)
+}
+
 func (c *BaseControllerOf[T]) ParseRequest(ctx context.Context) error {
 	var zeroValue T
 	if _, isNoBody := any(zeroValue).(NoBody); isNoBody {
@@ -141,7 +253,26 @@ func (c *BaseControllerOf[T]) ParseRequest(ctx context.Context) error {
 	if len(c.gcx.rawBody) == 0 {
 		return nil
 	}
-	return json.Unmarshal(c.gcx.rawBody, &c.Request)
+	if err := json.Unmarshal(c.gcx.rawBody, &c.Request); err != nil {
+		return jsonBodyError(err)
+	}
+	return nil
+}
+
+// jsonBodyError turns a JSON decode error into a 400 naming the offending
+// offset or field.
+func jsonBodyError(err error) *AppError {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntaxErr):
+		return ErrBadRequest(fmt.Sprintf("Invalid JSON body at offset %d", syntaxErr.Offset), err)
+	case errors.As(err, &typeErr) && typeErr.Field != "":
+		return ErrBadRequest(fmt.Sprintf("Invalid JSON body: field %q must be %s", typeErr.Field, typeErr.Type), err)
+	case errors.As(err, &typeErr):
+		return ErrBadRequest(fmt.Sprintf("Invalid JSON body: must be %s", typeErr.Type), err)
+	}
+	return ErrBadRequest("Invalid JSON body", err)
 }
 
 // bindFormData binds form data to a struct.
@@ -196,52 +327,7 @@ func (c *BaseControllerOf[T]) bindFormData(dst *T) error {
 
 // setFieldValue sets a struct field from string value.
 func (c *BaseControllerOf[T]) setFieldValue(field reflect.Value, value string) error {
-	switch field.Kind() {
-	case reflect.String:
-		field.SetString(value)
-
-	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		intVal, err := strconv.ParseInt(value, 10, 64)
-		if err != nil {
-			return err
-		}
-		field.SetInt(intVal)
-
-	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-		uintVal, err := strconv.ParseUint(value, 10, 64)
-		if err != nil {
-			return err
-		}
-		field.SetUint(uintVal)
-
-	case reflect.Float32, reflect.Float64:
-		floatVal, err := strconv.ParseFloat(value, 64)
-		if err != nil {
-			return err
-		}
-		field.SetFloat(floatVal)
-
-	case reflect.Bool:
-		boolVal := value == "1" || strings.ToLower(value) == "true"
-		field.SetBool(boolVal)
-
-	case reflect.Ptr:
-		// Allocate a new value of the pointed-to type, parse the string into it,
-		// then point the field at the new value.  This supports optional fields
-		// declared as *string, *int64, *bool, etc.
-		elem := reflect.New(field.Type().Elem()).Elem()
-		if err := c.setFieldValue(elem, value); err != nil {
-			return err
-		}
-		ptr := reflect.New(field.Type().Elem())
-		ptr.Elem().Set(elem)
-		field.Set(ptr)
-
-	default:
-		return fmt.Errorf("unsupported field type: %v", field.Kind())
-	}
-
-	return nil
+	return setStringValue(field, value, "")
 }
 
 // BadRequest returns a 400 AppError. Use as: return c.BadRequest(...)
@@ -279,10 +365,62 @@ func (c *BaseControllerOf[T]) InternalError(msg string, internal error) error {
 	return ErrInternal(msg, internal)
 }
 
+// Abort skips the remaining lifecycle steps (Init, ParseRequest, Validate,
+// Serve, Finalize) and sends err through the error pipeline; see Context.Abort.
+//
+//	func (c *AdminController) Init(ctx context.Context) error {
+//		if !isAdmin(ctx) {
+//			return c.Abort(ctx, glk.ErrForbidden("admins only", nil))
+//		}
+//		return c.BaseController.Init(ctx)
+//	}
+func (c *BaseControllerOf[T]) Abort(ctx context.Context, err *AppError) error {
+	gcx := c.context(ctx)
+	if gcx == nil {
+		if err == nil {
+			return nil
+		}
+		return err
+	}
+	return gcx.Abort(err)
+}
+
+// Redirect answers with a redirect and skips the remaining lifecycle steps.
+func (c *BaseControllerOf[T]) Redirect(ctx context.Context, code int, url string) error {
+	gcx := c.context(ctx)
+	if gcx == nil {
+		return ErrInternal("golitekit: context not initialized", nil)
+	}
+	return gcx.Redirect(code, url)
+}
+
+// context returns the request Context, also before Init has stored it.
+func (c *BaseControllerOf[T]) context(ctx context.Context) *Context {
+	if c.gcx != nil {
+		return c.gcx
+	}
+	return GetContext(ctx)
+}
+
+// SanityCheck does nothing by default.
+func (c *BaseControllerOf[T]) SanityCheck(ctx context.Context) error {
+	return nil
+}
+
+// BeforeServe does nothing by default.
+func (c *BaseControllerOf[T]) BeforeServe(ctx context.Context) error {
+	return nil
+}
+
 func (c *BaseControllerOf[T]) Serve(ctx context.Context) error {
 	return nil
 }
 
+// AfterServe does nothing by default.
+func (c *BaseControllerOf[T]) AfterServe(ctx context.Context) error {
+	return nil
+}
+
 func (c *BaseControllerOf[T]) Finalize(ctx context.Context) error {
 	return nil
 }
@@ -291,6 +429,12 @@ func (c *BaseControllerOf[T]) GetRequest() T {
 	return c.Request
 }
 
+// Body returns the request body bound by ParseRequest and checked by
+// Validate, so Serve needs no parsing of its own.
+func (c *BaseControllerOf[T]) Body() *T {
+	return &c.Request
+}
+
 func (c *BaseControllerOf[T]) parseBody() error {
 	maxMemorySize := c.MaxMemorySize()
 	if maxMemorySize <= 0 {
@@ -319,13 +463,22 @@ func (c *BaseControllerOf[T]) parseBody() error {
 			var rawBody []byte
 			rawBody, err = io.ReadAll(originBody)
 			if err != nil {
-				return err
+				return bodyReadError(err)
 			}
 			c.gcx.rawBody = rawBody
 			httpReq.Body = io.NopCloser(bytes.NewBuffer(rawBody))
 		}
 	}
 
+	return bodyReadError(err)
+}
+
+// bodyReadError reports read deadlines hit while receiving the request body as
+// client timeouts; other errors are returned unchanged.
+func bodyReadError(err error) error {
+	if isTimeoutError(err) {
+		return NewTimeoutError(TimeoutSourceClient, err)
+	}
 	return err
 }
 
@@ -382,6 +535,24 @@ func (c *BaseControllerOf[T]) QueryBool(key string, def bool) bool {
 	return parseValue(c.queryValue(key), def, parseBool)
 }
 
+func (c *BaseControllerOf[T]) QueryTime(key, layout string, def time.Time) time.Time {
+	return parseValue(c.queryValue(key), def, func(s string) (time.Time, error) {
+		return time.Parse(layout, s)
+	})
+}
+
+func (c *BaseControllerOf[T]) QueryDuration(key string, def time.Duration) time.Duration {
+	return parseValue(c.queryValue(key), def, time.ParseDuration)
+}
+
+func (c *BaseControllerOf[T]) QueryUUID(key string, def string) string {
+	return parseValue(c.queryValue(key), def, parseUUID)
+}
+
+func (c *BaseControllerOf[T]) QueryStringSlice(key, sep string) []string {
+	return splitValues(c.request.URL.Query()[key], sep)
+}
+
 func (c *BaseControllerOf[T]) forms() (map[string][]string, error) {
 	ct := c.request.Header.Get("Content-Type")
 	ct, _, err := mime.ParseMediaType(ct)
@@ -416,6 +587,46 @@ func parseBool(value string) (bool, error) {
 	return value == "1" || strings.ToLower(value) == "true", nil
 }
 
+// parseUUID accepts a UUID in the canonical 8-4-4-4-12 hex form and
+// returns it lower-cased.
+func parseUUID(value string) (string, error) {
+	if len(value) != 36 {
+		return "", fmt.Errorf("invalid UUID %q", value)
+	}
+	for i := 0; i < len(value); i++ {
+		ch := value[i]
+		switch i {
+		case 8, 13, 18, 23:
+			if ch != '-' {
+				return "", fmt.Errorf("invalid UUID %q", value)
+			}
+		default:
+			if !('0' <= ch && ch <= '9' || 'a' <= ch && ch <= 'f' || 'A' <= ch && ch <= 'F') {
+				return "", fmt.Errorf("invalid UUID %q", value)
+			}
+		}
+	}
+	return strings.ToLower(value), nil
+}
+
+// splitValues splits every value on sep, or keeps them whole when sep is
+// empty, dropping empty elements. It returns nil when nothing is left.
+func splitValues(values []string, sep string) []string {
+	var out []string
+	for _, v := range values {
+		parts := []string{v}
+		if sep != "" {
+			parts = strings.Split(v, sep)
+		}
+		for _, p := range parts {
+			if p = strings.TrimSpace(p); p != "" {
+				out = append(out, p)
+			}
+		}
+	}
+	return out
+}
+
 func (c *BaseControllerOf[T]) queryValue(key string) string {
 	if vals, ok := c.request.URL.Query()[key]; ok && len(vals) > 0 {
 		return vals[0]
@@ -424,16 +635,20 @@ func (c *BaseControllerOf[T]) queryValue(key string) string {
 }
 
 func (c *BaseControllerOf[T]) formValue(key string) string {
-	params, err := c.forms()
-	if err != nil {
-		return ""
-	}
-	if vals, ok := params[key]; ok && len(vals) > 0 {
+	if vals := c.formValues(key); len(vals) > 0 {
 		return vals[0]
 	}
 	return ""
 }
 
+func (c *BaseControllerOf[T]) formValues(key string) []string {
+	params, err := c.forms()
+	if err != nil {
+		return nil
+	}
+	return params[key]
+}
+
 func (c *BaseControllerOf[T]) pathValue(key string) string {
 	return c.request.PathValue(key)
 }
@@ -471,6 +686,24 @@ func (c *BaseControllerOf[T]) FormBool(key string, def bool) bool {
 	return parseValue(c.formValue(key), def, parseBool)
 }
 
+func (c *BaseControllerOf[T]) FormTime(key, layout string, def time.Time) time.Time {
+	return parseValue(c.formValue(key), def, func(s string) (time.Time, error) {
+		return time.Parse(layout, s)
+	})
+}
+
+func (c *BaseControllerOf[T]) FormDuration(key string, def time.Duration) time.Duration {
+	return parseValue(c.formValue(key), def, time.ParseDuration)
+}
+
+func (c *BaseControllerOf[T]) FormUUID(key string, def string) string {
+	return parseValue(c.formValue(key), def, parseUUID)
+}
+
+func (c *BaseControllerOf[T]) FormStringSlice(key, sep string) []string {
+	return splitValues(c.formValues(key), sep)
+}
+
 func (c *BaseControllerOf[T]) FormFile(key string) (multipart.File, *multipart.FileHeader, error) {
 	return c.request.FormFile(key)
 }
@@ -508,6 +741,24 @@ func (c *BaseControllerOf[T]) PathValueBool(key string, def bool) bool {
 	return parseValue(c.pathValue(key), def, parseBool)
 }
 
+func (c *BaseControllerOf[T]) PathValueTime(key, layout string, def time.Time) time.Time {
+	return parseValue(c.pathValue(key), def, func(s string) (time.Time, error) {
+		return time.Parse(layout, s)
+	})
+}
+
+func (c *BaseControllerOf[T]) PathValueDuration(key string, def time.Duration) time.Duration {
+	return parseValue(c.pathValue(key), def, time.ParseDuration)
+}
+
+func (c *BaseControllerOf[T]) PathValueUUID(key string, def string) string {
+	return parseValue(c.pathValue(key), def, parseUUID)
+}
+
+func (c *BaseControllerOf[T]) PathValueStringSlice(key, sep string) []string {
+	return splitValues([]string{c.pathValue(key)}, sep)
+}
+
 func (c *BaseControllerOf[T]) AddDebug(ctx context.Context, key string, value any) {
 	logger.AddDebug(ctx, key, value)
 }
@@ -0,0 +1,293 @@
+package golitekit
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// Clone tag values. Each request gets a copy of the registered controller;
+// the clone tag decides how a field reaches the copy:
+//
+//	Cache  *Cache         `clone:"shared"` // the prototype's value, the default
+//	APIKey string         `clone:"skip"`   // zeroed, e.g. a secret only the prototype needs
+//	Seen   map[string]int `clone:"deep"`   // deep-copied, so requests do not share mutations
+//
+// Tagged fields must be exported and reachable through exported fields.
+const (
+	cloneTag    = "clone"
+	cloneShared = "shared"
+	cloneSkip   = "skip"
+	cloneDeep   = "deep"
+)
+
+// copier sets dst to a copy of src.
+type copier func(dst, src reflect.Value, s *copyState)
+
+// maxDeepCopyDepth bounds how many pointers, maps, slices and interfaces a
+// deep copy follows from its root.
+const maxDeepCopyDepth = 1000
+
+// copyState is the state of one deep copy: the pointers and maps copied so
+// far, so shared and cyclic references are copied once and keep their
+// shape, and the current depth.
+type copyState struct {
+	visited map[copyKey]reflect.Value
+	depth   int
+}
+
+type copyKey struct {
+	typ reflect.Type
+	ptr uintptr
+}
+
+// enter descends one level, panicking past maxDeepCopyDepth.
+func (s *copyState) enter(t reflect.Type) {
+	s.depth++
+	if s.depth > maxDeepCopyDepth {
+		panic(fmt.Sprintf("golitekit: deep copy of %s exceeds depth %d", t, maxDeepCopyDepth))
+	}
+}
+
+func (s *copyState) leave() { s.depth-- }
+
+// seen returns the copy of the pointer or map src made earlier in this
+// copy, and otherwise records that dup is its copy.
+func (s *copyState) seen(src, dup reflect.Value) (reflect.Value, bool) {
+	if s.visited == nil {
+		s.visited = make(map[copyKey]reflect.Value)
+	}
+	key := copyKey{src.Type(), src.Pointer()}
+	if v, ok := s.visited[key]; ok {
+		return v, true
+	}
+	s.visited[key] = dup
+	return reflect.Value{}, false
+}
+
+// clonePlan is the compiled clone tags of a controller type: a plain copy
+// followed by ops on the tagged fields.
+type clonePlan struct {
+	ops []cloneOp
+}
+
+type cloneOp struct {
+	index []int
+	copy  copier // nil zeroes the field
+}
+
+var (
+	clonePlans   sync.Map // reflect.Type -> *clonePlan
+	deepCopiers  sync.Map // reflect.Type -> copier
+	copierBuilds sync.Mutex
+)
+
+// clonePlanFor returns the plan of the struct type t, compiling it on first
+// use. It panics when a clone tag has an unknown value or sits on a field
+// the copy cannot set.
+func clonePlanFor(t reflect.Type) *clonePlan {
+	if p, ok := clonePlans.Load(t); ok {
+		return p.(*clonePlan)
+	}
+	p := &clonePlan{}
+	var walk func(st reflect.Type, index []int, path string, settable bool)
+	walk = func(st reflect.Type, index []int, path string, settable bool) {
+		for i := 0; i < st.NumField(); i++ {
+			f := st.Field(i)
+			name := path + f.Name
+			fieldIndex := append(index[:len(index):len(index)], i)
+			ok := settable && f.IsExported()
+			tag := f.Tag.Get(cloneTag)
+			switch tag {
+			case "", cloneShared:
+				if f.Type.Kind() == reflect.Struct {
+					walk(f.Type, fieldIndex, name+".", ok)
+				}
+				continue
+			case cloneSkip, cloneDeep:
+			default:
+				panic(fmt.Sprintf("golitekit: %s.%s: unknown clone tag %q", t.Name(), name, tag))
+			}
+			if !ok {
+				panic(fmt.Sprintf("golitekit: %s.%s: clone tag on an unexported field", t.Name(), name))
+			}
+			op := cloneOp{index: fieldIndex}
+			if tag == cloneDeep {
+				op.copy = deepCopier(f.Type)
+			}
+			p.ops = append(p.ops, op)
+		}
+	}
+	walk(t, nil, "", true)
+	actual, _ := clonePlans.LoadOrStore(t, p)
+	return actual.(*clonePlan)
+}
+
+// copyFields sets dst to src, then zeroes the fields tagged clone:"skip"
+// and deep-copies the ones tagged clone:"deep".
+func (p *clonePlan) copyFields(dst, src reflect.Value) {
+	dst.Set(src)
+	for _, op := range p.ops {
+		f := dst.FieldByIndex(op.index)
+		if op.copy == nil {
+			f.SetZero()
+			continue
+		}
+		op.copy(f, src.FieldByIndex(op.index), &copyState{})
+	}
+}
+
+// deepCopy returns a copy of v sharing no pointers, maps or slices with it.
+func deepCopy(v reflect.Value) reflect.Value {
+	out := reflect.New(v.Type()).Elem()
+	deepCopier(v.Type())(out, v, &copyState{})
+	return out
+}
+
+// deepCopier returns the cached copier of t. The copy shares no pointers,
+// maps or slices with the source, and pointers or maps reached twice, as in
+// parent/child links, are copied once. Unexported struct fields cannot be
+// set through reflection, so they keep the source's value, pointers
+// included; channels and funcs are shared too, and sync types are left
+// zero.
+func deepCopier(t reflect.Type) copier {
+	if c, ok := deepCopiers.Load(t); ok {
+		return c.(copier)
+	}
+	copierBuilds.Lock()
+	defer copierBuilds.Unlock()
+	return buildCopier(t, make(map[reflect.Type]*copier))
+}
+
+func buildCopier(t reflect.Type, building map[reflect.Type]*copier) copier {
+	if c, ok := deepCopiers.Load(t); ok {
+		return c.(copier)
+	}
+	if c, ok := building[t]; ok {
+		// a recursive type: resolve the copier once it is built
+		return func(dst, src reflect.Value, s *copyState) { (*c)(dst, src, s) }
+	}
+	c := new(copier)
+	building[t] = c
+	*c = compileCopier(t, building)
+	deepCopiers.Store(t, *c)
+	return *c
+}
+
+func setCopier(dst, src reflect.Value, _ *copyState) { dst.Set(src) }
+
+func compileCopier(t reflect.Type, building map[reflect.Type]*copier) copier {
+	if t.PkgPath() == "sync" {
+		return func(dst, src reflect.Value, _ *copyState) { dst.SetZero() }
+	}
+	switch t.Kind() {
+	case reflect.Pointer:
+		elem := buildCopier(t.Elem(), building)
+		return func(dst, src reflect.Value, s *copyState) {
+			if src.IsNil() {
+				dst.SetZero()
+				return
+			}
+			p := reflect.New(t.Elem())
+			if dup, ok := s.seen(src, p); ok {
+				dst.Set(dup)
+				return
+			}
+			s.enter(t)
+			elem(p.Elem(), src.Elem(), s)
+			s.leave()
+			dst.Set(p)
+		}
+	case reflect.Interface:
+		return func(dst, src reflect.Value, s *copyState) {
+			if src.IsNil() {
+				dst.SetZero()
+				return
+			}
+			e := src.Elem()
+			v := reflect.New(e.Type()).Elem()
+			s.enter(t)
+			deepCopier(e.Type())(v, e, s)
+			s.leave()
+			dst.Set(v)
+		}
+	case reflect.Map:
+		key, elem := buildCopier(t.Key(), building), buildCopier(t.Elem(), building)
+		return func(dst, src reflect.Value, s *copyState) {
+			if src.IsNil() {
+				dst.SetZero()
+				return
+			}
+			m := reflect.MakeMapWithSize(t, src.Len())
+			if dup, ok := s.seen(src, m); ok {
+				dst.Set(dup)
+				return
+			}
+			s.enter(t)
+			k, v := reflect.New(t.Key()).Elem(), reflect.New(t.Elem()).Elem()
+			iter := src.MapRange()
+			for iter.Next() {
+				key(k, iter.Key(), s)
+				elem(v, iter.Value(), s)
+				m.SetMapIndex(k, v)
+			}
+			s.leave()
+			dst.Set(m)
+		}
+	case reflect.Slice:
+		elem := buildCopier(t.Elem(), building)
+		return func(dst, src reflect.Value, s *copyState) {
+			if src.IsNil() {
+				dst.SetZero()
+				return
+			}
+			out := reflect.MakeSlice(t, src.Len(), src.Len())
+			s.enter(t)
+			for i := 0; i < src.Len(); i++ {
+				elem(out.Index(i), src.Index(i), s)
+			}
+			s.leave()
+			dst.Set(out)
+		}
+	case reflect.Array:
+		elem := buildCopier(t.Elem(), building)
+		return func(dst, src reflect.Value, s *copyState) {
+			for i := 0; i < src.Len(); i++ {
+				elem(dst.Index(i), src.Index(i), s)
+			}
+		}
+	case reflect.Struct:
+		type field struct {
+			index int
+			copy  copier
+		}
+		var fields []field
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if f.IsExported() && !shallowKind(f.Type.Kind()) {
+				fields = append(fields, field{i, buildCopier(f.Type, building)})
+			}
+		}
+		if len(fields) == 0 {
+			return setCopier
+		}
+		return func(dst, src reflect.Value, s *copyState) {
+			dst.Set(src)
+			for _, f := range fields {
+				f.copy(dst.Field(f.index), src.Field(f.index), s)
+			}
+		}
+	default:
+		return setCopier
+	}
+}
+
+// shallowKind reports whether values of kind k hold no memory a copy could
+// share.
+func shallowKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Pointer, reflect.Interface, reflect.Map, reflect.Slice, reflect.Array, reflect.Struct:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,238 @@
+package golitekit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+type cloneCache struct{ hits int }
+
+type cloneTagsController struct {
+	BaseController
+	Cache  *cloneCache    `clone:"shared"`
+	APIKey string         `clone:"skip"`
+	Seen   map[string]int `clone:"deep"`
+	Nested struct {
+		Tags []string `clone:"deep"`
+	}
+	observed *cloneTagsController
+}
+
+func (c *cloneTagsController) Serve(ctx context.Context) error {
+	*c.observed = *c
+	c.Cache.hits++
+	c.Seen["served"]++
+	c.Nested.Tags[0] = "changed"
+	return c.String(http.StatusOK, "ok")
+}
+
+func TestCopyFields_HonorsCloneTags(t *testing.T) {
+	var observed cloneTagsController
+	proto := &cloneTagsController{
+		Cache:    &cloneCache{},
+		APIKey:   "secret",
+		Seen:     map[string]int{"served": 0},
+		observed: &observed,
+	}
+	proto.Nested.Tags = []string{"orig"}
+
+	r := newTestRouter()
+	r.GET("/c", proto)
+	for i := 0; i < 2; i++ {
+		rec := httptest.NewRecorder()
+		r.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/c", nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("code = %d: %s", rec.Code, rec.Body)
+		}
+	}
+
+	if observed.APIKey != "" {
+		t.Error("clone:\"skip\" field reached the request copy")
+	}
+	if observed.Cache != proto.Cache || proto.Cache.hits != 2 {
+		t.Errorf("clone:\"shared\" field not shared, hits = %d", proto.Cache.hits)
+	}
+	if proto.Seen["served"] != 0 || observed.Seen["served"] != 1 {
+		t.Errorf("clone:\"deep\" map shared: prototype %v, copy %v", proto.Seen, observed.Seen)
+	}
+	if proto.Nested.Tags[0] != "orig" {
+		t.Errorf("nested clone:\"deep\" slice shared: %v", proto.Nested.Tags)
+	}
+}
+
+func TestCheckCloneTags_RejectsInvalidTags(t *testing.T) {
+	type unknownTag struct {
+		BaseController
+		Field int `clone:"copy"`
+	}
+	type unexportedTag struct {
+		BaseController
+		secret string `clone:"skip"`
+	}
+	for name, c := range map[string]Controller{"unknown": &unknownTag{}, "unexported": &unexportedTag{}} {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Fatal("expected panic")
+				}
+			}()
+			newTestRouter().GET("/x", c)
+		})
+	}
+}
+
+type cloneNode struct {
+	Name string
+	Next *cloneNode
+}
+
+type cloneState struct {
+	Mu     sync.Mutex
+	Events chan string
+	Items  []any
+	List   *cloneNode
+}
+
+func TestDeepCopy_SkipsSyncAndSharesChans(t *testing.T) {
+	src := cloneState{
+		Events: make(chan string),
+		Items:  []any{map[string]int{"a": 1}, "x"},
+		List:   &cloneNode{Name: "a", Next: &cloneNode{Name: "b"}},
+	}
+	src.Mu.Lock()
+	defer src.Mu.Unlock()
+
+	out := deepCopy(reflect.ValueOf(&src)).Interface().(*cloneState)
+	if !out.Mu.TryLock() {
+		t.Error("sync.Mutex copied in its locked state")
+	}
+	if out.Events != src.Events {
+		t.Error("channel not shared")
+	}
+	out.Items[0].(map[string]int)["a"] = 2
+	out.List.Next.Name = "changed"
+	if src.Items[0].(map[string]int)["a"] != 1 || src.List.Next.Name != "b" {
+		t.Errorf("copy shares memory with the source: %v %v", src.Items, src.List.Next)
+	}
+}
+
+func TestClonePlanFor_CachedPerType(t *testing.T) {
+	typ := reflect.TypeOf(cloneTagsController{})
+	p := clonePlanFor(typ)
+	if clonePlanFor(typ) != p {
+		t.Fatal("plan compiled twice")
+	}
+	if len(p.ops) != 3 {
+		t.Fatalf("ops = %d, want skip, deep and nested deep", len(p.ops))
+	}
+}
+
+type complexController struct {
+	BaseController
+	Cache   map[string][]byte
+	Clients []*cloneNode
+	Token   string            `clone:"skip"`
+	Filters map[string]string `clone:"deep"`
+	Page    struct {
+		Sort  []string `clone:"deep"`
+		Limit int
+	}
+	Meta [8]string
+}
+
+func newComplexController() *complexController {
+	c := &complexController{
+		Cache:   map[string][]byte{"k": []byte("v")},
+		Token:   "secret",
+		Filters: map[string]string{"status": "open", "owner": "me"},
+	}
+	c.Page.Sort = []string{"-created", "name"}
+	return c
+}
+
+// walkCloneTags is the uncompiled clone: it reads the tags of every field
+// on every copy.
+func walkCloneTags(dst, src reflect.Value) {
+	t := dst.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		switch f.Tag.Get(cloneTag) {
+		case cloneSkip:
+			dst.Field(i).SetZero()
+		case cloneDeep:
+			dst.Field(i).Set(deepCopy(src.Field(i)))
+		case "", cloneShared:
+			if f.Type.Kind() == reflect.Struct && f.IsExported() {
+				walkCloneTags(dst.Field(i), src.Field(i))
+			}
+		}
+	}
+}
+
+func BenchmarkControllerClone_Compiled(b *testing.B) {
+	proto := reflect.ValueOf(newComplexController()).Elem()
+	plan := clonePlanFor(proto.Type())
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		v := reflect.New(proto.Type())
+		plan.copyFields(v.Elem(), proto)
+	}
+}
+
+func BenchmarkControllerClone_Uncompiled(b *testing.B) {
+	proto := reflect.ValueOf(newComplexController()).Elem()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		v := reflect.New(proto.Type())
+		v.Elem().Set(proto)
+		walkCloneTags(v.Elem(), proto)
+	}
+}
+
+type cloneTree struct {
+	Name     string
+	Parent   *cloneTree
+	Children []*cloneTree
+	secret   *cloneNode
+}
+
+func TestDeepCopy_CopiesCyclesOnce(t *testing.T) {
+	root := &cloneTree{Name: "root", secret: &cloneNode{Name: "s"}}
+	child := &cloneTree{Name: "child", Parent: root}
+	root.Children = []*cloneTree{child, child}
+
+	out := deepCopy(reflect.ValueOf(root)).Interface().(*cloneTree)
+	if out == root || out.Children[0] == child {
+		t.Fatal("copy shares nodes with the source")
+	}
+	if out.Children[0].Parent != out || out.Children[0] != out.Children[1] {
+		t.Error("parent/child links or shared children lost their shape")
+	}
+	if out.secret != root.secret {
+		t.Error("unexported pointer field should keep the source's value")
+	}
+
+	selfRef := map[string]any{}
+	selfRef["self"] = selfRef
+	m := deepCopy(reflect.ValueOf(selfRef)).Interface().(map[string]any)
+	if reflect.ValueOf(m["self"]).Pointer() != reflect.ValueOf(m).Pointer() {
+		t.Error("self-referencing map not copied onto itself")
+	}
+}
+
+func TestDeepCopy_PanicsPastDepthLimit(t *testing.T) {
+	var head *cloneNode
+	for i := 0; i <= maxDeepCopyDepth; i++ {
+		head = &cloneNode{Next: head}
+	}
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected a depth panic")
+		}
+	}()
+	deepCopy(reflect.ValueOf(head))
+}
@@ -2,7 +2,10 @@ package golitekit
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
@@ -10,6 +13,8 @@ import (
 	"strings"
 	"sync"
 	"testing"
+
+	"github.com/hansir-hsj/GoLiteKit/logger"
 )
 
 type lifecycleRequest struct {
@@ -326,3 +331,247 @@ func TestControllerLifecycle_CustomRequestParserOwnsBodyParsing(t *testing.T) {
 		t.Fatalf("response ok = %q, want true", body["ok"])
 	}
 }
+
+type abortingController struct {
+	BaseController
+	stage string
+	steps *[]string
+}
+
+func (c *abortingController) Init(ctx context.Context) error {
+	if err := c.BaseController.Init(ctx); err != nil {
+		return err
+	}
+	*c.steps = append(*c.steps, "init")
+	switch c.stage {
+	case "init":
+		c.Abort(ctx, ErrForbidden("admins only", nil))
+		return nil // Abort alone is enough
+	case "redirect":
+		return c.Redirect(ctx, http.StatusFound, "/login")
+	case "json-then-redirect":
+		c.JSON(http.StatusOK, map[string]string{"state": "draft"})
+	}
+	return nil
+}
+
+func (c *abortingController) Serve(ctx context.Context) error {
+	*c.steps = append(*c.steps, "serve")
+	if c.stage == "serve" {
+		c.JSON(http.StatusAccepted, map[string]string{"state": "queued"})
+		return c.Abort(ctx, nil)
+	}
+	if c.stage == "json-then-redirect" {
+		return c.Redirect(ctx, http.StatusFound, "/login")
+	}
+	return nil
+}
+
+func (c *abortingController) Finalize(ctx context.Context) error {
+	*c.steps = append(*c.steps, "finalize")
+	return nil
+}
+
+func TestController_AbortAndRedirect(t *testing.T) {
+	for _, tc := range []struct {
+		stage, steps string
+		code         int
+		body         string
+	}{
+		{"init", "init", http.StatusForbidden, "admins only"},
+		{"redirect", "init", http.StatusFound, `href="/login"`},
+		{"json-then-redirect", "init,serve", http.StatusFound, `href="/login"`},
+		{"serve", "init,serve", http.StatusAccepted, "queued"},
+		{"none", "init,serve,finalize", http.StatusOK, ""},
+	} {
+		var steps []string
+		r := newTestRouter()
+		r.GET("/", &abortingController{stage: tc.stage, steps: &steps})
+		rec := httptest.NewRecorder()
+		r.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		if got := strings.Join(steps, ","); got != tc.steps {
+			t.Errorf("%s: steps = %s, want %s", tc.stage, got, tc.steps)
+		}
+		if rec.Code != tc.code || !strings.Contains(rec.Body.String(), tc.body) {
+			t.Errorf("%s: response = %d %q", tc.stage, rec.Code, rec.Body)
+		}
+		if tc.code == http.StatusFound && (rec.Header().Get("Location") != "/login" || strings.Contains(rec.Body.String(), "draft")) {
+			t.Errorf("%s: Location = %q, body %q", tc.stage, rec.Header().Get("Location"), rec.Body)
+		}
+	}
+}
+
+func TestHandlerFunc_Abort(t *testing.T) {
+	r := newTestRouter()
+	r.GET("/", HandlerFunc(func(ctx *Context) error {
+		ctx.Abort(ErrUnauthorized("login first", nil))
+		return nil
+	}))
+	rec := httptest.NewRecorder()
+	r.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("status = %d, want 401", rec.Code)
+	}
+}
+
+type failingStageController struct {
+	BaseControllerOf[lifecycleRequest]
+	fail  string
+	steps *[]string
+}
+
+func (c *failingStageController) step(stage string) error {
+	*c.steps = append(*c.steps, stage)
+	if stage == c.fail {
+		return errors.New(stage + " failed")
+	}
+	return nil
+}
+
+func (c *failingStageController) Init(ctx context.Context) error {
+	if err := c.BaseControllerOf.Init(ctx); err != nil {
+		return err
+	}
+	return c.step("init")
+}
+
+func (c *failingStageController) SanityCheck(ctx context.Context) error {
+	return c.step("sanity_check")
+}
+
+func (c *failingStageController) ParseRequest(ctx context.Context) error {
+	return c.step("parse_request")
+}
+
+func (c *failingStageController) Validate(ctx context.Context) error {
+	return c.step("validate")
+}
+
+func (c *failingStageController) BeforeServe(ctx context.Context) error {
+	return c.step("before_serve")
+}
+
+func (c *failingStageController) AfterServe(ctx context.Context) error {
+	return c.step("after_serve")
+}
+
+func (c *failingStageController) Serve(ctx context.Context) error {
+	if err := c.step("serve"); err != nil {
+		return err
+	}
+	return c.String(http.StatusOK, "ok")
+}
+
+func (c *failingStageController) Finalize(ctx context.Context) error {
+	return c.step("finalize")
+}
+
+func TestControllerLifecycle_StageErrorsStopThePipeline(t *testing.T) {
+	for _, tc := range []struct {
+		fail, steps string
+		code        int
+	}{
+		{"init", "init", http.StatusInternalServerError},
+		{"sanity_check", "init,sanity_check", http.StatusBadRequest},
+		{"parse_request", "init,sanity_check,parse_request", http.StatusBadRequest},
+		{"validate", "init,sanity_check,parse_request,validate", http.StatusBadRequest},
+		{"before_serve", "init,sanity_check,parse_request,validate,before_serve", http.StatusInternalServerError},
+		{"serve", "init,sanity_check,parse_request,validate,before_serve,serve", http.StatusInternalServerError},
+		{"after_serve", "init,sanity_check,parse_request,validate,before_serve,serve,after_serve", http.StatusInternalServerError},
+		{"finalize", "init,sanity_check,parse_request,validate,before_serve,serve,after_serve,finalize", http.StatusInternalServerError},
+	} {
+		var steps []string
+		var stage any
+		r := newTestRouter()
+		r.Use(func(next Handler) Handler {
+			return func(ctx context.Context, w http.ResponseWriter, req *http.Request) error {
+				err := next(ctx, w, req)
+				for node := logger.GetLoggerContext(ctx).Head; node != nil; node = node.Next {
+					if node.Key == "controller_stage" {
+						stage = node.Value
+					}
+				}
+				return err
+			}
+		})
+		r.GET("/", &failingStageController{fail: tc.fail, steps: &steps})
+		rec := httptest.NewRecorder()
+		r.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		if got := strings.Join(steps, ","); got != tc.steps {
+			t.Errorf("%s: steps = %s, want %s", tc.fail, got, tc.steps)
+		}
+		if rec.Code != tc.code {
+			t.Errorf("%s: status = %d, want %d", tc.fail, rec.Code, tc.code)
+		}
+		if stage != tc.fail {
+			t.Errorf("%s: controller_stage = %v", tc.fail, stage)
+		}
+	}
+}
+
+// stackError formats like github.com/pkg/errors: %+v adds the stack.
+type stackError struct{ msg string }
+
+func (e stackError) Error() string { return e.msg }
+
+func (e stackError) Format(f fmt.State, verb rune) {
+	io.WriteString(f, e.msg)
+	if verb == 'v' && f.Flag('+') {
+		io.WriteString(f, "\nmain.loadOrder\n\torders.go:42")
+	}
+}
+
+type failingServeController struct {
+	BaseController
+	err error
+}
+
+func (c *failingServeController) Serve(ctx context.Context) error {
+	return c.err
+}
+
+func TestControllerServeErrorReachesErrorPipeline(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		err   error
+		code  int
+		level string
+	}{
+		{"plain", stackError{"db exploded"}, http.StatusInternalServerError, "ERROR"},
+		{"no rows", fmt.Errorf("load: %w", sql.ErrNoRows), http.StatusNotFound, "WARN"},
+		{"wrapped AppError", fmt.Errorf("load: %w", ErrConflict("taken", nil)), http.StatusConflict, "WARN"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			appLog := &levelLogger{captureLogger: captureLogger{fields: map[string]any{}}}
+			accessLog := &levelLogger{captureLogger: captureLogger{fields: map[string]any{}}}
+			app := NewApp(WithLogger(appLog), WithNamedLogger(logger.AccessLoggerName, accessLog))
+			app.GET("/orders", &failingServeController{err: tc.err})
+			rec := httptest.NewRecorder()
+			app.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/orders", nil))
+
+			if rec.Code != tc.code {
+				t.Fatalf("status = %d, want %d; body %s", rec.Code, tc.code, rec.Body.String())
+			}
+			var resp Response
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil || resp.LogID == "" {
+				t.Errorf("error response = %s, want the rendered envelope with a log ID", rec.Body.String())
+			}
+			if len(appLog.levels) != 1 || appLog.levels[0] != tc.level {
+				t.Errorf("application log levels = %v, want [%s]", appLog.levels, tc.level)
+			}
+			if accessLog.fields["logid"] != resp.LogID || accessLog.fields["controller_stage"] != "serve" {
+				t.Errorf("access fields = %v", accessLog.fields)
+			}
+			if tc.level == "ERROR" {
+				if !strings.Contains(appLog.msgs[0], "db exploded") {
+					t.Errorf("error line %q lacks the original error", appLog.msgs[0])
+				}
+				if stack, _ := accessLog.fields["err_stack"].(string); !strings.Contains(stack, "orders.go:42") {
+					t.Errorf("err_stack = %q", stack)
+				}
+			}
+		})
+	}
+}
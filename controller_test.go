@@ -3,12 +3,16 @@ package golitekit
 import (
 	"bytes"
 	"context"
+	"encoding/json"
+	"io"
 	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"reflect"
 	"strings"
 	"testing"
+	"time"
 )
 
 // ============================================================================
@@ -108,6 +112,50 @@ func TestBaseController_ParseRequest_JSON(t *testing.T) {
 	}
 }
 
+type jsonBodyController struct {
+	BaseControllerOf[jsonRequest]
+}
+
+func (c *jsonBodyController) Serve(ctx context.Context) error {
+	return c.JSON(http.StatusOK, c.Body())
+}
+
+func TestBaseController_BindsJSONBodyOrAnswers400(t *testing.T) {
+	r := newTestRouter()
+	r.POST("/items", &jsonBodyController{})
+
+	tests := []struct {
+		body     string
+		wantCode int
+		wantMsg  string
+	}{
+		{`{"name":"alice","value":42}`, http.StatusOK, ""},
+		{`{"name":"alice","value":"x"}`, http.StatusBadRequest, `Invalid JSON body: field "value" must be int`},
+		{`{"name":`, http.StatusBadRequest, "Invalid JSON body at offset 8"},
+		{`{"name" 1}`, http.StatusBadRequest, "Invalid JSON body at offset 9"},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodPost, "/items", strings.NewReader(tt.body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		r.Handler().ServeHTTP(rec, req)
+		if rec.Code != tt.wantCode {
+			t.Fatalf("%s: code = %d, want %d: %s", tt.body, rec.Code, tt.wantCode, rec.Body)
+		}
+		if tt.wantCode == http.StatusOK {
+			if rec.Body.String() != `{"name":"alice","value":42}` {
+				t.Errorf("body = %s", rec.Body)
+			}
+			continue
+		}
+		var resp Response
+		_ = json.Unmarshal(rec.Body.Bytes(), &resp)
+		if resp.Msg != tt.wantMsg {
+			t.Errorf("%s: msg = %q, want %q", tt.body, resp.Msg, tt.wantMsg)
+		}
+	}
+}
+
 func TestBaseController_ParseRequest_PopulatesRawBodyAndRequest(t *testing.T) {
 	body := []byte(`{"name":"alice","value":42}`)
 	req, _, _ := makeRequest(http.MethodPost, "/", body, "application/json")
@@ -390,6 +438,42 @@ func TestBaseController_QueryFloat64(t *testing.T) {
 	}
 }
 
+func TestBaseController_QueryTypedHelpers(t *testing.T) {
+	const id = "3F2504E0-4F89-11D3-9A0C-0305E82C3301"
+	req, _, _ := makeRequest(http.MethodGet, "/?day=2024-05-01&ttl=90s&id="+id+"&bad=x&tags=a,+b,,c&tag=x&tag=y", nil, "")
+
+	c := &BaseController{}
+	if err := c.Init(req.Context()); err != nil {
+		t.Fatalf("Init: %v", err)
+	}
+
+	def := time.Unix(0, 0)
+	if v := c.QueryTime("day", "2006-01-02", def); !v.Equal(time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("QueryTime = %v", v)
+	}
+	if v := c.QueryTime("bad", "2006-01-02", def); !v.Equal(def) {
+		t.Errorf("QueryTime bad = %v, want default", v)
+	}
+	if v := c.QueryDuration("ttl", time.Second); v != 90*time.Second {
+		t.Errorf("QueryDuration = %v", v)
+	}
+	if v := c.QueryUUID("id", ""); v != strings.ToLower(id) {
+		t.Errorf("QueryUUID = %q", v)
+	}
+	if v := c.QueryUUID("bad", "none"); v != "none" {
+		t.Errorf("QueryUUID bad = %q, want default", v)
+	}
+	if v := c.QueryStringSlice("tags", ","); !reflect.DeepEqual(v, []string{"a", "b", "c"}) {
+		t.Errorf("QueryStringSlice = %q", v)
+	}
+	if v := c.QueryStringSlice("tag", ""); !reflect.DeepEqual(v, []string{"x", "y"}) {
+		t.Errorf("QueryStringSlice repeated = %q", v)
+	}
+	if v := c.QueryStringSlice("missing", ","); v != nil {
+		t.Errorf("QueryStringSlice missing = %q, want nil", v)
+	}
+}
+
 // ============================================================================
 // Error helpers
 // ============================================================================
@@ -494,3 +578,14 @@ func (c *serviceController) Serve(ctx context.Context) error {
 	}
 	return c.JSON(http.StatusOK, map[string]string{"ok": "true"})
 }
+
+func TestBodyReadError_ClassifiesClientTimeout(t *testing.T) {
+	err := bodyReadError(fakeNetTimeout{})
+	appErr, ok := err.(*AppError)
+	if !ok || appErr.Code != http.StatusRequestTimeout {
+		t.Fatalf("bodyReadError = %v, want 408 AppError", err)
+	}
+	if other := bodyReadError(io.ErrUnexpectedEOF); other != io.ErrUnexpectedEOF {
+		t.Fatalf("bodyReadError(non-timeout) = %v, want unchanged", other)
+	}
+}
@@ -43,6 +43,12 @@ type Config struct {
 	DbConn    `toml:"Conn"`
 }
 
+// ConfigEnvNamespace names the environment variable overrides of the file
+// like GLK_DB_HOST, whatever the file is called.
+func (c *Config) ConfigEnvNamespace() string {
+	return "db"
+}
+
 func parse(conf string) (*Config, error) {
 	var dbConfig Config
 	if err := config.Parse(conf, &dbConfig); err != nil {
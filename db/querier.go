@@ -0,0 +1,29 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+)
+
+// Querier is the part of *sql.DB and *sql.Tx that queries use. Code
+// depending on it instead of *gorm.DB can be unit tested with a fake.
+type Querier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+type querierKey struct{}
+
+// WithDB returns a copy of ctx carrying q, which Context.Querier and
+// controllers prefer over the app's database, e.g. to inject a fake or a
+// transaction.
+func WithDB(ctx context.Context, q Querier) context.Context {
+	return context.WithValue(ctx, querierKey{}, q)
+}
+
+// FromContext returns the Querier set by WithDB, or nil.
+func FromContext(ctx context.Context) Querier {
+	q, _ := ctx.Value(querierKey{}).(Querier)
+	return q
+}
@@ -1,8 +1,13 @@
 package env
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 	"time"
 
@@ -15,6 +20,7 @@ const (
 	DefaultReadHeaderTimeout = 200 * time.Millisecond
 	DefaultIdleTimeout       = 2 * time.Second
 	DefaultShutdownTimeout   = 2 * time.Second
+	DefaultSourceTimeout     = 10 * time.Second
 )
 
 var (
@@ -25,11 +31,16 @@ var (
 type EnvHttpServer struct {
 	AppName string `toml:"appName"`
 	RunMode string `toml:"runMode"`
-	Network string `toml:"network"`
-	Addr    string `toml:"addr"`
+	Network string `toml:"network" reload:"immutable"`
+	Addr    string `toml:"addr" reload:"immutable"`
 
 	MaxHeaderBytes int  `toml:"maxHeaderBytes"`
 	EnablePprof    bool `toml:"enablePprof"`
+	// PprofAddr serves pprof on a listener of its own instead of addr.
+	PprofAddr string `toml:"pprofAddr" reload:"immutable"`
+	// PprofToken admits non-loopback pprof clients that send it as a bearer
+	// token; without it pprof only answers loopback clients.
+	PprofToken string `toml:"pprofToken" reload:"immutable"`
 
 	EnvTimeout   `toml:"Timeout"`
 	EnvRateLimit `toml:"RateLimit"`
@@ -39,6 +50,10 @@ type EnvHttpServer struct {
 	EnvTLSConfig `toml:"TLSConfig"`
 	EnvSSE       `toml:"SSE"`
 	EnvStatic    `toml:"Static"`
+	EnvJournal   `toml:"Journal"`
+	EnvProfiling `toml:"Profiling"`
+
+	Listeners []EnvListener `toml:"Listeners"`
 }
 
 type EnvTimeout struct {
@@ -47,17 +62,35 @@ type EnvTimeout struct {
 	WriteTimeout      int `toml:"writeTimeout"`
 	IdleTimeout       int `toml:"idleTimeout"`
 	ShutdownTimeout   int `toml:"shutdownTimeout"`
+
+	// Per-phase shutdown budgets in milliseconds; 0 takes a share of
+	// shutdownTimeout.
+	StopAcceptingTimeout int `toml:"stopAcceptingTimeout"`
+	DrainRequestsTimeout int `toml:"drainRequestsTimeout"`
+	DrainJobsTimeout     int `toml:"drainJobsTimeout"`
+	ClosePoolsTimeout    int `toml:"closePoolsTimeout"`
+	FlushLogsTimeout     int `toml:"flushLogsTimeout"`
 }
 
 type EnvRateLimit struct {
 	RateLimit int `toml:"rateLimit"`
 	RateBurst int `toml:"rateBurst"`
+	// Tiers holds per-tier limits, e.g. [HttpServer.RateLimit.tiers.pro].
+	Tiers map[string]EnvRateTier `toml:"tiers"`
+}
+
+type EnvRateTier struct {
+	RateLimit int `toml:"rateLimit"`
+	RateBurst int `toml:"rateBurst"`
 }
 
 type EnvLogger struct {
 	Logger          string `toml:"configFile"`
 	LogRequestBody  bool   `toml:"logRequestBody"`
 	LogResponseBody bool   `toml:"logResponseBody"`
+	// LogIDHeader is an extra request header the log ID is adopted from,
+	// e.g. "X-Correlation-ID".
+	LogIDHeader string `toml:"logIdHeader"`
 }
 
 type EnvDB struct {
@@ -68,14 +101,63 @@ type EnvRedis struct {
 	Redis string `toml:"configFile"`
 }
 
+type EnvJournal struct {
+	JournalFile  string `toml:"file"`
+	JournalSlots int    `toml:"slots"`
+}
+
+// EnvProfiling configures continuous profiling; it is enabled by dir or
+// pyroscopeURL. Durations are in milliseconds.
+type EnvProfiling struct {
+	ProfileDir         string   `toml:"dir"`
+	ProfileInterval    int      `toml:"interval"`
+	ProfileCPUDuration int      `toml:"cpuDuration"`
+	ProfileMaxFiles    int      `toml:"maxFiles"`
+	Profiles           []string `toml:"profiles"`
+	PyroscopeURL       string   `toml:"pyroscopeURL"`
+}
+
 type EnvStatic struct {
 	StaticDir string `toml:"staticDir"`
 }
 
 type EnvTLSConfig struct {
-	TLS      bool   `toml:"tls"`
+	TLS          bool   `toml:"tls"`
+	CertFile     string `toml:"certFile"`
+	KeyFile      string `toml:"keyFile"`
+	ClientCAFile string `toml:"clientCAFile"`
+	ClientAuth   string `toml:"clientAuth"`
+
+	// Hosts are certificates selected by SNI, declared as
+	// [[HttpServer.TLSConfig.Hosts]] tables.
+	Hosts        []EnvTLSHost `toml:"Hosts"`
+	ACMECacheDir string       `toml:"acmeCacheDir"`
+	ACMEEmail    string       `toml:"acmeEmail"`
+}
+
+// EnvTLSHost is the certificate of one hostname: certFile and keyFile, or
+// acme = true to obtain it from Let's Encrypt.
+type EnvTLSHost struct {
+	Host     string `toml:"host"`
 	CertFile string `toml:"certFile"`
 	KeyFile  string `toml:"keyFile"`
+	ACME     bool   `toml:"acme"`
+}
+
+// EnvListener describes an additional listener declared as a
+// [[HttpServer.Listeners]] table.
+type EnvListener struct {
+	Name          string `toml:"name"`
+	Network       string `toml:"network"`
+	Addr          string `toml:"addr"`
+	TLS           bool   `toml:"tls"`
+	CertFile      string `toml:"certFile"`
+	KeyFile       string `toml:"keyFile"`
+	ClientCAFile  string `toml:"clientCAFile"`
+	ClientAuth    string `toml:"clientAuth"`
+	PathPrefix    string `toml:"pathPrefix"`
+	RedirectHTTPS bool   `toml:"redirectHTTPS"`
+	HTTPSAddr     string `toml:"httpsAddr"`
 }
 
 type EnvSSE struct {
@@ -85,11 +167,118 @@ type EnvSSE struct {
 type Env struct {
 	rootDir string
 	confDir string
+	doc     *config.Document
 
 	EnvHttpServer `toml:"HttpServer"`
 }
 
+// SetConfigDocument keeps the whole app.toml so Get and Section can read
+// application-defined sections.
+func (e *Env) SetConfigDocument(doc *config.Document) {
+	e.doc = doc
+}
+
+// ConfigProfile selects the [profiles.<runMode>] table and the
+// app.<runMode>.toml file, unless GLK_PROFILE or config.SetProfile chooses
+// another profile.
+func (e *Env) ConfigProfile() string {
+	return e.RunMode
+}
+
+// ConfigEnvNamespace keeps app.toml overrides named after their section
+// (GLK_HTTPSERVER_ADDR) whatever the file is called.
+func (e *Env) ConfigEnvNamespace() string {
+	return ""
+}
+
+// Init loads the configuration at path, layered as described by
+// config.Parse: app.toml, its [profiles.<runMode>] table, app.<runMode>.toml
+// and GLK_* environment variables. path is a file or, for remote
+// configuration, a URL understood by config.OpenSource (http(s)://,
+// consul://host:port/key, etcd://host:port/key). Relative file paths
+// (StaticDir, DB config files, ...) stay relative to the working directory.
 func Init(path string) error {
+	src, err := config.OpenSource(path)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultSourceTimeout)
+	defer cancel()
+	return InitFromSource(ctx, src)
+}
+
+// InitFromSource loads the configuration from src.
+func InitFromSource(ctx context.Context, src config.Source) error {
+	return load(func(e *Env) error { return config.ParseSource(ctx, src, e) }, nil)
+}
+
+// ErrImmutableChange is returned by a reload that changes a key tagged
+// `reload:"immutable"` (addr, network); those only take effect on restart.
+var ErrImmutableChange = errors.New("env: immutable keys changed")
+
+// Watch re-initialises the environment whenever the configuration at path
+// changes, until ctx is done. Sources without native change notification are
+// polled every interval (config.DefaultPollInterval when <= 0). onReload, if
+// non-nil, is called after each reload attempt; a failed reload keeps the
+// previous configuration.
+//
+// Each reload logs the changed keys, old -> new with secrets redacted, to
+// slog.Default. A reload that changes an immutable key is rejected with
+// ErrImmutableChange so the running configuration always matches what is
+// reported.
+func Watch(ctx context.Context, path string, interval time.Duration, onReload func(error)) error {
+	src, err := config.OpenSource(path)
+	if err != nil {
+		return err
+	}
+	return config.Watch(ctx, src, interval, func(data []byte, ext string) {
+		err := load(func(e *Env) error {
+			if _, ok := src.(config.FileSource); ok {
+				return config.ParseSource(ctx, src, e) // re-read with profile file
+			}
+			return config.ParseBytes(ext, data, e)
+		}, checkReload)
+		if onReload != nil {
+			onReload(err)
+		}
+	})
+}
+
+// Diff returns the keys that differ between two loaded environments,
+// including application-defined sections (see Section).
+func Diff(old, next *Env) []config.Change {
+	if old == nil || next == nil {
+		return nil
+	}
+	changes := config.Diff(config.ExtTOML, old, next)
+	return append(changes, old.doc.Diff(next.doc, "HttpServer", config.ProfilesKey)...)
+}
+
+// checkReload logs the changes a reload applies and rejects immutable ones.
+func checkReload(prev, next *Env) error {
+	if prev == nil {
+		return nil
+	}
+	changes := Diff(prev, next)
+	var immutable []string
+	for _, c := range changes {
+		if c.Immutable {
+			immutable = append(immutable, c.String())
+		}
+	}
+	if len(immutable) > 0 {
+		slog.Error("env: configuration reload rejected", "immutable", immutable)
+		return fmt.Errorf("%w, restart to apply: %s", ErrImmutableChange, strings.Join(immutable, "; "))
+	}
+	if len(changes) > 0 {
+		slog.Info("env: configuration reloaded", "changes", changes)
+	}
+	return nil
+}
+
+// load parses a new Env and installs it; accept, if non-nil, can veto it
+// after comparing with the current one (nil before the first load).
+func load(parse func(*Env) error, accept func(prev, next *Env) error) error {
 	curPath, err := os.Getwd()
 	if err != nil {
 		return err
@@ -98,16 +287,67 @@ func Init(path string) error {
 		rootDir: curPath,
 		confDir: filepath.Join(curPath, "conf"),
 	}
-	if err := config.Parse(path, nextEnv); err != nil {
+	if err := parse(nextEnv); err != nil {
 		return err
 	}
 
 	envMu.Lock()
+	defer envMu.Unlock()
+	if accept != nil {
+		if err := accept(defaultEnv, nextEnv); err != nil {
+			return err
+		}
+	}
 	defaultEnv = nextEnv
-	envMu.Unlock()
 	return nil
 }
 
+// Snapshot returns a copy of the loaded [HttpServer] settings, or nil before Init.
+func Snapshot() *EnvHttpServer {
+	e := currentEnv()
+	if e == nil {
+		return nil
+	}
+	snapshot := e.EnvHttpServer
+	snapshot.Listeners = append([]EnvListener(nil), e.Listeners...)
+	snapshot.Hosts = append([]EnvTLSHost(nil), e.Hosts...)
+	return &snapshot
+}
+
+// ErrNotInitialized is returned by Get and Section before Init.
+var ErrNotInitialized = errors.New("env: not initialized")
+
+// Get returns the value at path in the loaded configuration converted to T,
+// e.g. env.Get[int]("MyFeature.limit") for
+//
+//	[MyFeature]
+//	limit = 10
+//
+// GLK_MYFEATURE_LIMIT overrides the file, and string values are parsed into
+// other types, so env.Get[time.Duration] accepts "5s". A missing key returns
+// an error wrapping config.ErrKeyNotFound.
+func Get[T any](path string) (T, error) {
+	var v T
+	e := currentEnv()
+	if e == nil {
+		return v, ErrNotInitialized
+	}
+	err := e.doc.Get(path, &v)
+	return v, err
+}
+
+// Section decodes the [name] table of the loaded configuration into target,
+// a pointer to a struct tagged like the file format. GLK_NAME_KEY variables,
+// `default` and `validate` tags apply as for the built-in sections; a missing
+// table leaves target to its defaults. name may be a dotted path.
+func Section(name string, target any) error {
+	e := currentEnv()
+	if e == nil {
+		return ErrNotInitialized
+	}
+	return e.doc.Decode(name, target)
+}
+
 func currentEnv() *Env {
 	envMu.RLock()
 	defer envMu.RUnlock()
@@ -228,6 +468,36 @@ func ShutdownTimeout() time.Duration {
 	return time.Duration(e.ShutdownTimeout) * time.Millisecond
 }
 
+// StopAcceptingTimeout returns the budget of the stop_accepting shutdown
+// phase, or 0 when unset.
+func StopAcceptingTimeout() time.Duration {
+	return phaseTimeout(func(e *Env) int { return e.StopAcceptingTimeout })
+}
+
+func DrainRequestsTimeout() time.Duration {
+	return phaseTimeout(func(e *Env) int { return e.DrainRequestsTimeout })
+}
+
+func DrainJobsTimeout() time.Duration {
+	return phaseTimeout(func(e *Env) int { return e.DrainJobsTimeout })
+}
+
+func ClosePoolsTimeout() time.Duration {
+	return phaseTimeout(func(e *Env) int { return e.ClosePoolsTimeout })
+}
+
+func FlushLogsTimeout() time.Duration {
+	return phaseTimeout(func(e *Env) int { return e.FlushLogsTimeout })
+}
+
+func phaseTimeout(field func(*Env) int) time.Duration {
+	e := currentEnv()
+	if e == nil {
+		return 0
+	}
+	return time.Duration(field(e)) * time.Millisecond
+}
+
 func MaxHeaderBytes() int {
 	e := currentEnv()
 	if e == nil {
@@ -258,6 +528,14 @@ func RateBurst() int {
 	return e.RateBurst
 }
 
+func RateTiers() map[string]EnvRateTier {
+	e := currentEnv()
+	if e == nil {
+		return nil
+	}
+	return e.Tiers
+}
+
 func DBConfigFile() string {
 	e := currentEnv()
 	if e == nil {
@@ -321,6 +599,28 @@ func TLSKeyFile() string {
 	return filepath.Join(e.confDir, e.KeyFile)
 }
 
+// TLSClientCAFile returns the CA bundle used to verify client certificates,
+// resolved against the conf directory. Empty disables mutual TLS.
+func TLSClientCAFile() string {
+	e := currentEnv()
+	if e == nil {
+		return ""
+	}
+	if e.ClientCAFile == "" {
+		return ""
+	}
+	return filepath.Join(e.confDir, e.ClientCAFile)
+}
+
+// TLSClientAuth returns the configured client certificate policy.
+func TLSClientAuth() string {
+	e := currentEnv()
+	if e == nil {
+		return ""
+	}
+	return e.ClientAuth
+}
+
 func EnablePprof() bool {
 	e := currentEnv()
 	if e == nil {
@@ -329,6 +629,22 @@ func EnablePprof() bool {
 	return e.EnablePprof
 }
 
+func PprofAddr() string {
+	e := currentEnv()
+	if e == nil {
+		return ""
+	}
+	return e.PprofAddr
+}
+
+func PprofToken() string {
+	e := currentEnv()
+	if e == nil {
+		return ""
+	}
+	return e.PprofToken
+}
+
 func SSETimeout() time.Duration {
 	e := currentEnv()
 	if e == nil {
@@ -355,3 +671,157 @@ func LogResponseBody() bool {
 	}
 	return e.LogResponseBody
 }
+
+// LogIDHeader returns the extra request header the log ID is adopted from,
+// or "".
+func LogIDHeader() string {
+	e := currentEnv()
+	if e == nil {
+		return ""
+	}
+	return e.LogIDHeader
+}
+
+// TLSHosts returns the SNI certificates with their paths resolved against
+// the conf directory, or nil when tls is false.
+func TLSHosts() []EnvTLSHost {
+	e := currentEnv()
+	if e == nil || !e.TLS || len(e.Hosts) == 0 {
+		return nil
+	}
+	hosts := make([]EnvTLSHost, 0, len(e.Hosts))
+	for _, h := range e.Hosts {
+		if h.CertFile != "" {
+			h.CertFile = filepath.Join(e.confDir, h.CertFile)
+		}
+		if h.KeyFile != "" {
+			h.KeyFile = filepath.Join(e.confDir, h.KeyFile)
+		}
+		hosts = append(hosts, h)
+	}
+	return hosts
+}
+
+// ACMECacheDir returns the directory ACME certificates are kept in, resolved
+// against the root directory.
+func ACMECacheDir() string {
+	e := currentEnv()
+	if e == nil || e.ACMECacheDir == "" {
+		return ""
+	}
+	if filepath.IsAbs(e.ACMECacheDir) {
+		return e.ACMECacheDir
+	}
+	return filepath.Join(e.rootDir, e.ACMECacheDir)
+}
+
+// ACMEEmail returns the contact address of the ACME account.
+func ACMEEmail() string {
+	e := currentEnv()
+	if e == nil {
+		return ""
+	}
+	return e.ACMEEmail
+}
+
+// Listeners returns the additional listeners with certificate paths resolved
+// against the conf directory. TLS files are cleared when tls is false.
+func Listeners() []EnvListener {
+	e := currentEnv()
+	if e == nil || len(e.Listeners) == 0 {
+		return nil
+	}
+	listeners := make([]EnvListener, 0, len(e.Listeners))
+	for _, l := range e.Listeners {
+		if !l.TLS {
+			l.CertFile = ""
+			l.KeyFile = ""
+			l.ClientCAFile = ""
+		}
+		if l.CertFile != "" {
+			l.CertFile = filepath.Join(e.confDir, l.CertFile)
+		}
+		if l.KeyFile != "" {
+			l.KeyFile = filepath.Join(e.confDir, l.KeyFile)
+		}
+		if l.ClientCAFile != "" {
+			l.ClientCAFile = filepath.Join(e.confDir, l.ClientCAFile)
+		}
+		listeners = append(listeners, l)
+	}
+	return listeners
+}
+
+// JournalFile returns the request journal path, resolved against the root
+// directory, or "" when the journal is disabled.
+func JournalFile() string {
+	e := currentEnv()
+	if e == nil || e.JournalFile == "" {
+		return ""
+	}
+	if filepath.IsAbs(e.JournalFile) {
+		return e.JournalFile
+	}
+	return filepath.Join(e.rootDir, e.JournalFile)
+}
+
+// ProfileDir returns the directory profiles are written to, resolved
+// against the root directory, or "".
+func ProfileDir() string {
+	e := currentEnv()
+	if e == nil || e.ProfileDir == "" {
+		return ""
+	}
+	if filepath.IsAbs(e.ProfileDir) {
+		return e.ProfileDir
+	}
+	return filepath.Join(e.rootDir, e.ProfileDir)
+}
+
+func ProfileInterval() time.Duration {
+	e := currentEnv()
+	if e == nil {
+		return 0
+	}
+	return time.Duration(e.ProfileInterval) * time.Millisecond
+}
+
+func ProfileCPUDuration() time.Duration {
+	e := currentEnv()
+	if e == nil {
+		return 0
+	}
+	return time.Duration(e.ProfileCPUDuration) * time.Millisecond
+}
+
+func ProfileMaxFiles() int {
+	e := currentEnv()
+	if e == nil {
+		return 0
+	}
+	return e.ProfileMaxFiles
+}
+
+func Profiles() []string {
+	e := currentEnv()
+	if e == nil {
+		return nil
+	}
+	return e.Profiles
+}
+
+func PyroscopeURL() string {
+	e := currentEnv()
+	if e == nil {
+		return ""
+	}
+	return e.PyroscopeURL
+}
+
+func JournalSlots() int {
+	e := currentEnv()
+	if e == nil {
+		return 0
+	}
+	return e.JournalSlots
+}
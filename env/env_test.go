@@ -1,10 +1,21 @@
 package env
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 	"testing"
+	"time"
+
+	"github.com/hansir-hsj/GoLiteKit/config"
 )
 
 func TestInit(t *testing.T) {
@@ -75,3 +86,340 @@ writeTimeout = 1000
 	}
 	return path
 }
+
+func TestListenersResolveTLSFiles(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.toml")
+	content := `[HttpServer]
+addr = ":0"
+
+[[HttpServer.Listeners]]
+name = "redirect"
+addr = ":80"
+redirectHTTPS = true
+httpsAddr = ":443"
+
+[[HttpServer.Listeners]]
+name = "admin"
+addr = "127.0.0.1:9090"
+pathPrefix = "/_admin"
+tls = true
+certFile = "tls/admin.crt"
+keyFile = "tls/admin.key"
+clientCAFile = "tls/ops-ca.crt"
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("write env config: %v", err)
+	}
+	if err := Init(path); err != nil {
+		t.Fatalf("Init: %v", err)
+	}
+
+	listeners := Listeners()
+	if len(listeners) != 2 {
+		t.Fatalf("listeners = %d, want 2", len(listeners))
+	}
+	if !listeners[0].RedirectHTTPS || listeners[0].HTTPSAddr != ":443" {
+		t.Fatalf("redirect listener = %+v", listeners[0])
+	}
+	if listeners[1].PathPrefix != "/_admin" {
+		t.Fatalf("admin pathPrefix = %q, want /_admin", listeners[1].PathPrefix)
+	}
+	if listeners[1].CertFile != filepath.Join(ConfDir(), "tls/admin.crt") {
+		t.Fatalf("admin certFile = %q, want resolved under conf dir", listeners[1].CertFile)
+	}
+	if listeners[1].ClientCAFile != filepath.Join(ConfDir(), "tls/ops-ca.crt") {
+		t.Fatalf("admin clientCAFile = %q, want resolved under conf dir", listeners[1].ClientCAFile)
+	}
+}
+
+func TestTLSHostsResolveFiles(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.toml")
+	content := `[HttpServer]
+addr = ":443"
+
+[HttpServer.TLSConfig]
+tls = true
+acmeCacheDir = "certs"
+acmeEmail = "ops@example.com"
+
+[[HttpServer.TLSConfig.Hosts]]
+host = "api.example.com"
+certFile = "tls/api.crt"
+keyFile = "tls/api.key"
+
+[[HttpServer.TLSConfig.Hosts]]
+host = "shop.example.com"
+acme = true
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("write env config: %v", err)
+	}
+	if err := Init(path); err != nil {
+		t.Fatalf("Init: %v", err)
+	}
+
+	hosts := TLSHosts()
+	if len(hosts) != 2 || !hosts[1].ACME {
+		t.Fatalf("hosts = %+v, want api and an ACME shop", hosts)
+	}
+	if hosts[0].CertFile != filepath.Join(ConfDir(), "tls/api.crt") || hosts[0].KeyFile != filepath.Join(ConfDir(), "tls/api.key") {
+		t.Fatalf("api files = %q, %q, want resolved under conf dir", hosts[0].CertFile, hosts[0].KeyFile)
+	}
+	if ACMECacheDir() != filepath.Join(RootDir(), "certs") || ACMEEmail() != "ops@example.com" {
+		t.Fatalf("acme = %q, %q", ACMECacheDir(), ACMEEmail())
+	}
+}
+func TestInitFromHTTPAndWatch(t *testing.T) {
+	var mu sync.Mutex
+	appName := "remote-a"
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		fmt.Fprintf(w, "[HttpServer]\nappName = %q\n", appName)
+	}))
+	defer srv.Close()
+
+	location := srv.URL + "/app.toml"
+	if err := Init(location); err != nil {
+		t.Fatalf("Init(%q): %v", location, err)
+	}
+	if AppName() != "remote-a" {
+		t.Fatalf("AppName = %q, want remote-a", AppName())
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	reloaded := make(chan error, 1)
+	go Watch(ctx, location, 10*time.Millisecond, func(err error) { reloaded <- err })
+
+	time.Sleep(30 * time.Millisecond)
+	mu.Lock()
+	appName = "remote-b"
+	mu.Unlock()
+	select {
+	case err := <-reloaded:
+		if err != nil {
+			t.Fatalf("reload: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("config was not reloaded")
+	}
+	if AppName() != "remote-b" {
+		t.Errorf("AppName = %q, want remote-b", AppName())
+	}
+}
+
+func TestInitAppliesRunModeProfile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.toml")
+	content := `
+[HttpServer]
+appName = "svc"
+runMode = "prod"
+addr = ":8080"
+
+[HttpServer.Timeout]
+writeTimeout = 1000
+
+[profiles.prod.HttpServer]
+addr = ":80"
+
+[profiles.prod.HttpServer.Timeout]
+writeTimeout = 30000
+`
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := Init(path); err != nil {
+		t.Fatalf("Init: %v", err)
+	}
+	if Addr() != ":80" || WriteTimeout() != 30*time.Second || AppName() != "svc" {
+		t.Errorf("addr=%q writeTimeout=%v appName=%q, want prod profile over base", Addr(), WriteTimeout(), AppName())
+	}
+}
+
+func TestInitLayersProfileFile(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	path := write("app.toml", `
+[HttpServer]
+appName = "svc"
+runMode = "test"
+addr = ":8080"
+network = "tcp"
+
+[profiles.test.HttpServer]
+addr = ":7000"
+network = "tcp4"
+`)
+	write("app.test.toml", `
+[HttpServer]
+addr = ":9000"
+`)
+
+	if err := Init(path); err != nil {
+		t.Fatalf("Init: %v", err)
+	}
+	// app.test.toml beats [profiles.test], which beats the base values.
+	if Addr() != ":9000" || Network() != "tcp4" || AppName() != "svc" {
+		t.Errorf("addr=%q network=%q appName=%q", Addr(), Network(), AppName())
+	}
+
+	// Environment variables beat every file layer.
+	t.Setenv("GLK_HTTPSERVER_ADDR", ":1234")
+	if err := Init(path); err != nil {
+		t.Fatalf("Init: %v", err)
+	}
+	if Addr() != ":1234" {
+		t.Errorf("addr = %q, want env override", Addr())
+	}
+
+	// GLK_PROFILE picks a profile without its own file.
+	t.Setenv("GLK_PROFILE", "prod")
+	if err := Init(path); err != nil {
+		t.Fatalf("Init: %v", err)
+	}
+	if Network() != "tcp" {
+		t.Errorf("network = %q, want base value for profile without overrides", Network())
+	}
+}
+
+func TestGetAndSection(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.toml")
+	content := `
+[HttpServer]
+appName = "svc"
+runMode = "prod"
+
+[MyFeature]
+enabled = true
+limit = 10
+ttl = "5s"
+tags = ["a", "b"]
+
+[profiles.prod.MyFeature]
+limit = 50
+`
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("GLK_MYFEATURE_ENABLED", "false")
+	if err := Init(path); err != nil {
+		t.Fatalf("Init: %v", err)
+	}
+
+	if limit, err := Get[int]("MyFeature.limit"); err != nil || limit != 50 {
+		t.Errorf("limit = %d, %v; want 50 from the prod profile", limit, err)
+	}
+	if ttl, err := Get[time.Duration]("MyFeature.ttl"); err != nil || ttl != 5*time.Second {
+		t.Errorf("ttl = %v, %v; want 5s", ttl, err)
+	}
+	if tags, err := Get[[]string]("MyFeature.tags"); err != nil || len(tags) != 2 || tags[1] != "b" {
+		t.Errorf("tags = %v, %v", tags, err)
+	}
+	if enabled, err := Get[bool]("MyFeature.enabled"); err != nil || enabled {
+		t.Errorf("enabled = %v, %v; want env override false", enabled, err)
+	}
+	if _, err := Get[string]("MyFeature.missing"); !errors.Is(err, config.ErrKeyNotFound) {
+		t.Errorf("missing key err = %v, want ErrKeyNotFound", err)
+	}
+	if _, err := Get[int]("MyFeature.tags"); err == nil {
+		t.Error("expected an error converting an array to int")
+	}
+
+	var feature struct {
+		Enabled bool          `toml:"enabled"`
+		Limit   int           `toml:"limit" validate:"max=100"`
+		TTL     time.Duration `toml:"ttl"`
+		Mode    string        `toml:"mode" default:"fast"`
+	}
+	if err := Section("MyFeature", &feature); err != nil {
+		t.Fatalf("Section: %v", err)
+	}
+	if feature.Enabled || feature.Limit != 50 || feature.TTL != 5*time.Second || feature.Mode != "fast" {
+		t.Errorf("feature = %+v", feature)
+	}
+
+	var missing struct {
+		Size int `toml:"size" default:"3"`
+	}
+	if err := Section("Other", &missing); err != nil || missing.Size != 3 {
+		t.Errorf("missing section: size=%d err=%v, want defaults", missing.Size, err)
+	}
+	if err := Section("HttpServer.appName", &missing); err == nil {
+		t.Error("expected an error decoding a scalar as a section")
+	}
+}
+
+func TestWatchLogsDiffAndRejectsImmutableChanges(t *testing.T) {
+	var mu sync.Mutex
+	content := "[HttpServer]\naddr = \":8080\"\n\n[Payments]\napiKey = \"k1\"\nretries = 1\n"
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		fmt.Fprint(w, content)
+	}))
+	defer srv.Close()
+	set := func(s string) {
+		mu.Lock()
+		content = s
+		mu.Unlock()
+	}
+
+	var logs bytes.Buffer
+	prevLogger := slog.Default()
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&logs, nil)))
+	defer slog.SetDefault(prevLogger)
+
+	location := srv.URL + "/app.toml"
+	if err := Init(location); err != nil {
+		t.Fatalf("Init: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	reloaded := make(chan error, 1)
+	go Watch(ctx, location, 10*time.Millisecond, func(err error) { reloaded <- err })
+	wait := func() error {
+		select {
+		case err := <-reloaded:
+			return err
+		case <-time.After(2 * time.Second):
+			t.Fatal("config was not reloaded")
+			return nil
+		}
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	set("[HttpServer]\naddr = \":8080\"\nrunMode = \"prod\"\n\n[Payments]\napiKey = \"k2\"\nretries = 3\n")
+	if err := wait(); err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	if RunMode() != "prod" {
+		t.Fatalf("RunMode = %q, want prod", RunMode())
+	}
+	out := logs.String()
+	for _, want := range []string{`"key":"HttpServer.runMode","old":"","new":"prod"`, `"key":"Payments.retries","old":"1","new":"3"`, `"key":"Payments.apiKey","old":"[REDACTED]"`} {
+		if !strings.Contains(out, want) {
+			t.Errorf("log missing %s:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "k2") {
+		t.Errorf("secret leaked into the log:\n%s", out)
+	}
+
+	set("[HttpServer]\naddr = \":9090\"\nrunMode = \"test\"\n")
+	err := wait()
+	if !errors.Is(err, ErrImmutableChange) || !strings.Contains(err.Error(), "HttpServer.addr: :8080 -> :9090") {
+		t.Fatalf("reload err = %v, want ErrImmutableChange for addr", err)
+	}
+	if Addr() != ":8080" || RunMode() != "prod" {
+		t.Errorf("addr=%q runMode=%q, want the previous configuration kept", Addr(), RunMode())
+	}
+}
@@ -3,13 +3,26 @@ package golitekit
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"runtime/debug"
+
+	"github.com/hansir-hsj/GoLiteKit/env"
 )
 
 type errorHandlerConfig struct {
 	formatter func(w http.ResponseWriter, err *AppError, logID string)
 	onError   func(r *http.Request, err *AppError)
 	onPanic   func(r *http.Request, recovered any)
+	detail    bool
+}
+
+// ErrorDetail is the Response.Data of error responses in development: the
+// internal error or recovered panic value, and its stack when there is one.
+type ErrorDetail struct {
+	Error string `json:"error"`
+	Stack string `json:"stack,omitempty"`
 }
 
 type ErrorHandlerOption func(*errorHandlerConfig)
@@ -28,6 +41,15 @@ func WithErrorCallback(f func(r *http.Request, err *AppError)) ErrorHandlerOptio
 	}
 }
 
+// WithErrorDetail overrides whether error responses carry an ErrorDetail,
+// which by default they do only when env.RunMode is a development mode
+// ("debug", "dev", "development" or "test").
+func WithErrorDetail(detail bool) ErrorHandlerOption {
+	return func(c *errorHandlerConfig) {
+		c.detail = detail
+	}
+}
+
 // WithPanicCallback sets a hook called when a panic is recovered.
 func WithPanicCallback(f func(r *http.Request, recovered any)) ErrorHandlerOption {
 	return func(c *errorHandlerConfig) {
@@ -36,18 +58,29 @@ func WithPanicCallback(f func(r *http.Request, recovered any)) ErrorHandlerOptio
 }
 
 // ErrorHandlerMiddleware is the outermost middleware. It catches errors returned
-// by inner handlers and panics, writing appropriate JSON responses.
+// by inner handlers and panics, writing appropriate JSON responses. In
+// production they only carry the status, the public message and the log ID;
+// in development, see WithErrorDetail, the internal error or panic value and
+// its stack are added as an ErrorDetail.
 func ErrorHandlerMiddleware(opts ...ErrorHandlerOption) Middleware {
+	mode := env.RunMode()
 	cfg := &errorHandlerConfig{
-		formatter: defaultErrorFormatter,
+		detail: devRunMode(mode),
 	}
 	for _, opt := range opts {
 		opt(cfg)
 	}
+	if cfg.formatter == nil {
+		cfg.formatter = defaultErrorFormatter
+		if cfg.detail {
+			cfg.formatter = detailedErrorFormatter
+		}
+	}
 
 	return func(next Handler) Handler {
 		return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
 			dw := newDeferredResponseWriter(w)
+			defer dw.ReleaseTo(&deferredWriterPool)
 
 			defer func() {
 				if p := recover(); p != nil {
@@ -60,7 +93,7 @@ func ErrorHandlerMiddleware(opts ...ErrorHandlerOption) Middleware {
 
 			if err != nil && !dw.IsFlushed() {
 				dw.Reset()
-				handleAppError(w, r, WrapError(err, http.StatusInternalServerError), cfg)
+				handleAppError(w, r, ClassifyError(err), cfg)
 				return nil
 			}
 
@@ -91,6 +124,9 @@ func handlePanic(w http.ResponseWriter, r *http.Request, recovered any, cfg *err
 		Msg:    "Internal Server Error",
 		LogID:  logID,
 	}
+	if cfg.detail {
+		resp.Data = ErrorDetail{Error: fmt.Sprint(recovered), Stack: string(debug.Stack())}
+	}
 	json.NewEncoder(w).Encode(resp)
 }
 
@@ -103,11 +139,27 @@ func handleAppError(w http.ResponseWriter, r *http.Request, err *AppError, cfg *
 		cfg.onError(r, err)
 	}
 
+	for key, values := range err.Header {
+		for _, v := range values {
+			w.Header().Add(key, v)
+		}
+	}
+
 	cfg.formatter(w, err, logID)
 }
 
 // defaultErrorFormatter formats error as JSON response.
 func defaultErrorFormatter(w http.ResponseWriter, err *AppError, logID string) {
+	writeErrorResponse(w, err, logID, false)
+}
+
+// detailedErrorFormatter is defaultErrorFormatter adding the internal error
+// as an ErrorDetail, for development.
+func detailedErrorFormatter(w http.ResponseWriter, err *AppError, logID string) {
+	writeErrorResponse(w, err, logID, true)
+}
+
+func writeErrorResponse(w http.ResponseWriter, err *AppError, logID string, detail bool) {
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
 	w.WriteHeader(err.Code)
 
@@ -116,6 +168,12 @@ func defaultErrorFormatter(w http.ResponseWriter, err *AppError, logID string) {
 		Msg:    err.Message,
 		LogID:  logID,
 	}
+	var fields ValidationErrors
+	if errors.As(err.Internal, &fields) {
+		resp.Data = fields
+	} else if detail && err.Internal != nil {
+		resp.Data = ErrorDetail{Error: err.Internal.Error(), Stack: errorStack(err.Internal)}
+	}
 
 	json.NewEncoder(w).Encode(resp)
 }
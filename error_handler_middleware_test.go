@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 )
 
@@ -246,3 +247,46 @@ func TestErrorHandlerMiddleware_ErrorCallback(t *testing.T) {
 		}
 	})
 }
+
+func TestErrorHandlerMiddleware_ErrorDetail(t *testing.T) {
+	serve := func(detail bool, h Handler) map[string]any {
+		req := httptest.NewRequest("GET", "/test", nil)
+		req = req.WithContext(withContext(req.Context()))
+		rec := httptest.NewRecorder()
+		ErrorHandlerMiddleware(WithErrorDetail(detail))(h).ServeHTTP(rec, req)
+
+		var resp struct {
+			Msg  string         `json:"msg"`
+			Data map[string]any `json:"data"`
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("failed to parse response: %v", err)
+		}
+		if resp.Msg != "Internal Server Error" && resp.Msg != "load failed" {
+			t.Errorf("msg = %q, want the public message", resp.Msg)
+		}
+		return resp.Data
+	}
+	panicking := Handler(func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		panic("nil order")
+	})
+	failing := Handler(func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		return ErrInternal("load failed", stackError{msg: "db down"})
+	})
+
+	if data := serve(false, panicking); data != nil {
+		t.Errorf("production panic data = %v, want none", data)
+	}
+	if data := serve(false, failing); data != nil {
+		t.Errorf("production error data = %v, want none", data)
+	}
+
+	data := serve(true, panicking)
+	if data["error"] != "nil order" || !strings.Contains(data["stack"].(string), "goroutine") {
+		t.Errorf("development panic data = %v, want the value and stack", data)
+	}
+	data = serve(true, failing)
+	if data["error"] != "db down" || data["stack"] != "main.loadOrder\n\torders.go:42" {
+		t.Errorf("development error data = %v, want the internal error and stack", data)
+	}
+}
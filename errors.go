@@ -1,8 +1,14 @@
 package golitekit
 
 import (
+	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"net/http"
+	"sync"
+
+	"gorm.io/gorm"
 )
 
 // AppError is an HTTP error with a status code, message, and optional internal cause.
@@ -10,6 +16,10 @@ type AppError struct {
 	Code     int    `json:"code"`
 	Message  string `json:"message"`
 	Internal error  `json:"-"`
+
+	// Header is added to the error response, e.g. a WWW-Authenticate
+	// challenge; headers set on the writer before the error are discarded.
+	Header http.Header `json:"-"`
 }
 
 // Error implements the error interface.
@@ -20,6 +30,15 @@ func (e *AppError) Error() string {
 	return e.Message
 }
 
+// WithHeader adds a response header to the error and returns it.
+func (e *AppError) WithHeader(key, value string) *AppError {
+	if e.Header == nil {
+		e.Header = make(http.Header)
+	}
+	e.Header.Add(key, value)
+	return e
+}
+
 // Unwrap returns the internal error for errors.Is/errors.As support.
 func (e *AppError) Unwrap() error {
 	return e.Internal
@@ -65,6 +84,11 @@ func ErrTimeout(msg string, internal error) *AppError {
 	return &AppError{Code: http.StatusRequestTimeout, Message: msg, Internal: internal}
 }
 
+// ErrGatewayTimeout returns a 504 AppError.
+func ErrGatewayTimeout(msg string, internal error) *AppError {
+	return &AppError{Code: http.StatusGatewayTimeout, Message: msg, Internal: internal}
+}
+
 // ErrInternal returns a 500 AppError.
 func ErrInternal(msg string, internal error) *AppError {
 	return &AppError{Code: http.StatusInternalServerError, Message: msg, Internal: internal}
@@ -80,19 +104,90 @@ func NewAppError(code int, msg string, internal error) *AppError {
 	return &AppError{Code: code, Message: msg, Internal: internal}
 }
 
-// WrapError returns err as *AppError with the given status code.
-// If err is already *AppError it is returned unchanged.
-// For 5xx status codes, the error message is not exposed to the client.
-func WrapError(err error, code int) *AppError {
+// WrapError returns err as *AppError with the given status code and, when
+// given, msg as the client message. If err is already *AppError it is
+// returned unchanged. Without msg, the message of a 5xx is the status text,
+// so the error itself is not exposed to the client.
+func WrapError(err error, code int, msg ...string) *AppError {
 	if err == nil {
 		return nil
 	}
 	if appErr, ok := err.(*AppError); ok {
 		return appErr
 	}
-	msg := err.Error()
-	if code >= 500 {
-		msg = http.StatusText(code)
+	var message string
+	switch {
+	case len(msg) > 0:
+		message = msg[0]
+	case code >= 500:
+		message = http.StatusText(code)
+	default:
+		message = err.Error()
+	}
+	return &AppError{Code: code, Message: message, Internal: err}
+}
+
+// AsAppError finds the first *AppError in the chain of err, so an AppError
+// wrapped with fmt.Errorf("...: %w", appErr) keeps its status.
+func AsAppError(err error) (*AppError, bool) {
+	var appErr *AppError
+	if errors.As(err, &appErr) {
+		return appErr, true
+	}
+	return nil, false
+}
+
+// ErrorRule classifies the errors matching Target, by errors.Is, as Code
+// with Message, which defaults to the status text.
+type ErrorRule struct {
+	Target  error
+	Code    int
+	Message string
+}
+
+var (
+	errorRulesMu sync.RWMutex
+	errorRules   = []ErrorRule{
+		{Target: context.DeadlineExceeded, Code: http.StatusGatewayTimeout},
+		{Target: sql.ErrNoRows, Code: http.StatusNotFound},
+		{Target: gorm.ErrRecordNotFound, Code: http.StatusNotFound},
+	}
+)
+
+// RegisterErrorRule adds a rule to ClassifyError, checked before the rules
+// registered earlier and the defaults: context.DeadlineExceeded is a 504,
+// sql.ErrNoRows and gorm.ErrRecordNotFound are 404s. Register rules at
+// startup.
+func RegisterErrorRule(rule ErrorRule) {
+	errorRulesMu.Lock()
+	defer errorRulesMu.Unlock()
+	errorRules = append([]ErrorRule{rule}, errorRules...)
+}
+
+// ClassifyError turns err into the *AppError the error pipeline renders:
+// the AppError in its chain, else the first matching ErrorRule, else a 500.
+func ClassifyError(err error) *AppError {
+	return classifyError(err, http.StatusInternalServerError)
+}
+
+// classifyError is ClassifyError with code for errors no rule matches.
+func classifyError(err error, code int) *AppError {
+	if err == nil {
+		return nil
+	}
+	if appErr, ok := AsAppError(err); ok {
+		return appErr
+	}
+	errorRulesMu.RLock()
+	defer errorRulesMu.RUnlock()
+	for _, rule := range errorRules {
+		if errors.Is(err, rule.Target) {
+			msg := rule.Message
+			if msg == "" {
+				msg = http.StatusText(rule.Code)
+			}
+			return &AppError{Code: rule.Code, Message: msg, Internal: err}
+		}
 	}
-	return &AppError{Code: code, Message: msg, Internal: err}
+	return WrapError(err, code)
 }
@@ -1,9 +1,15 @@
 package golitekit
 
 import (
+	"context"
+	"database/sql"
 	"errors"
+	"fmt"
 	"net/http"
+	"net/http/httptest"
 	"testing"
+
+	"gorm.io/gorm"
 )
 
 func TestAppError_Error(t *testing.T) {
@@ -200,3 +206,63 @@ func TestWrapError_4xxExposesMessage(t *testing.T) {
 		t.Fatalf("4xx message = %q, want %q", appErr.Message, rawErr.Error())
 	}
 }
+
+func TestErrGatewayTimeout(t *testing.T) {
+	err := ErrGatewayTimeout("upstream", nil)
+	if err.Code != http.StatusGatewayTimeout {
+		t.Errorf("Code = %d, want %d", err.Code, http.StatusGatewayTimeout)
+	}
+}
+
+func TestWrapError_Message(t *testing.T) {
+	appErr := WrapError(errors.New("dial tcp: refused"), http.StatusBadGateway, "Payment provider unavailable")
+	if appErr.Code != http.StatusBadGateway || appErr.Message != "Payment provider unavailable" {
+		t.Errorf("WrapError = %d %q", appErr.Code, appErr.Message)
+	}
+}
+
+func TestAsAppError(t *testing.T) {
+	notFound := ErrNotFound("no such order", nil)
+	if got, ok := AsAppError(fmt.Errorf("load order: %w", notFound)); !ok || got != notFound {
+		t.Errorf("AsAppError(wrapped) = %v, %v", got, ok)
+	}
+	if _, ok := AsAppError(errors.New("plain")); ok {
+		t.Error("AsAppError(plain) = true")
+	}
+}
+
+func TestClassifyError(t *testing.T) {
+	errPaymentDeclined := errors.New("payment declined")
+	RegisterErrorRule(ErrorRule{Target: errPaymentDeclined, Code: http.StatusPaymentRequired, Message: "Payment declined"})
+
+	tests := []struct {
+		err  error
+		code int
+		msg  string
+	}{
+		{fmt.Errorf("query: %w", sql.ErrNoRows), http.StatusNotFound, "Not Found"},
+		{gorm.ErrRecordNotFound, http.StatusNotFound, "Not Found"},
+		{fmt.Errorf("call: %w", context.DeadlineExceeded), http.StatusGatewayTimeout, "Gateway Timeout"},
+		{fmt.Errorf("charge: %w", errPaymentDeclined), http.StatusPaymentRequired, "Payment declined"},
+		{fmt.Errorf("wrapped: %w", ErrConflict("taken", nil)), http.StatusConflict, "taken"},
+		{errors.New("boom"), http.StatusInternalServerError, "Internal Server Error"},
+	}
+	for _, tt := range tests {
+		got := ClassifyError(tt.err)
+		if got.Code != tt.code || got.Message != tt.msg {
+			t.Errorf("ClassifyError(%v) = %d %q, want %d %q", tt.err, got.Code, got.Message, tt.code, tt.msg)
+		}
+	}
+}
+
+func TestErrorHandlerClassifiesPlainErrors(t *testing.T) {
+	r := newTestRouter()
+	r.GET("/orders/{id}", HandlerFunc(func(ctx *Context) error {
+		return fmt.Errorf("load order %s: %w", ctx.Param("id"), sql.ErrNoRows)
+	}))
+	rec := httptest.NewRecorder()
+	r.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/orders/7", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404; body %s", rec.Code, rec.Body.String())
+	}
+}
@@ -0,0 +1,85 @@
+package golitekit
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// MockExampleHeader selects which example a mocked route answers with; the
+// "mock" query parameter does the same.
+const MockExampleHeader = "X-Mock-Example"
+
+// ExampleProvider is implemented by controllers that declare example
+// responses. Keys are HTTP status codes, optionally followed by ":" and a
+// name to register several examples per status ("200", "200:empty", "404").
+// Values are the response bodies, encoded as JSON.
+//
+// Examples are listed in Router.Routes (and the admin /routes endpoint) and
+// served verbatim when mock mode is enabled.
+type ExampleProvider interface {
+	Examples() map[string]any
+}
+
+func (t routeTarget) examples() map[string]any {
+	if provider, ok := t.controller.(ExampleProvider); ok {
+		return provider.Examples()
+	}
+	return nil
+}
+
+// SetMockMode makes controllers that implement ExampleProvider answer with
+// one of their examples instead of running Init/ParseRequest/Validate/Serve.
+// Controllers without examples are unaffected. Safe to toggle at runtime.
+func (r *Router) SetMockMode(enabled bool) {
+	r.mock.Store(enabled)
+}
+
+// MockMode reports whether mock mode is enabled.
+func (r *Router) MockMode() bool {
+	return r.mock.Load()
+}
+
+// serveMockExample writes the example selected by MockExampleHeader or the
+// "mock" query parameter, defaulting to the lowest 2xx key (or the lowest key
+// when there is no success example).
+func serveMockExample(gcx *Context, examples map[string]any) error {
+	if gcx == nil {
+		return ErrInternal("golitekit: context not initialized", nil)
+	}
+	key := gcx.Request().Header.Get(MockExampleHeader)
+	if key == "" {
+		key = gcx.Query("mock")
+	}
+	if key == "" {
+		key = defaultExampleKey(examples)
+	}
+	body, ok := examples[key]
+	if !ok {
+		return ErrNotFound("mock example "+strconv.Quote(key)+" not declared", nil)
+	}
+	code, err := strconv.Atoi(strings.SplitN(key, ":", 2)[0])
+	if err != nil || code < 100 || code > 599 {
+		code = http.StatusOK
+	}
+	gcx.ResponseWriter().Header().Set("X-Mock", "true")
+	return gcx.JSON(code, body)
+}
+
+func defaultExampleKey(examples map[string]any) string {
+	keys := make([]string, 0, len(examples))
+	for k := range examples {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if strings.HasPrefix(k, "2") {
+			return k
+		}
+	}
+	if len(keys) > 0 {
+		return keys[0]
+	}
+	return ""
+}
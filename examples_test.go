@@ -0,0 +1,82 @@
+package golitekit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type exampleUserController struct {
+	BaseController
+	served *bool
+}
+
+func (c *exampleUserController) Examples() map[string]any {
+	return map[string]any{
+		"200":       map[string]any{"id": 1, "name": "alice"},
+		"404":       Response{Status: http.StatusNotFound, Msg: "user not found"},
+		"200:empty": map[string]any{},
+	}
+}
+
+func (c *exampleUserController) Serve(ctx context.Context) error {
+	*c.served = true
+	return c.JSON(http.StatusOK, map[string]any{"id": 42})
+}
+
+func TestRouter_RoutesListExamples(t *testing.T) {
+	served := false
+	r := newTestRouter()
+	r.GET("/users/{id}", &exampleUserController{served: &served})
+
+	routes := r.Routes()
+	if len(routes) != 1 || len(routes[0].Examples) != 3 {
+		t.Fatalf("routes = %+v, want one route with 3 examples", routes)
+	}
+}
+
+func TestRouter_MockModeServesExamples(t *testing.T) {
+	served := false
+	r := newTestRouter()
+	r.GET("/users/{id}", &exampleUserController{served: &served})
+	r.GET("/plain", &serveController{})
+	r.SetMockMode(true)
+
+	do := func(path, example string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if example != "" {
+			req.Header.Set(MockExampleHeader, example)
+		}
+		rec := httptest.NewRecorder()
+		r.Handler().ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := do("/users/7", "")
+	var body map[string]any
+	_ = json.Unmarshal(rec.Body.Bytes(), &body)
+	if rec.Code != http.StatusOK || body["name"] != "alice" || rec.Header().Get("X-Mock") != "true" {
+		t.Fatalf("default example = %d %s", rec.Code, rec.Body)
+	}
+	if served {
+		t.Fatal("Serve ran in mock mode")
+	}
+
+	if rec := do("/users/7", "404"); rec.Code != http.StatusNotFound {
+		t.Fatalf("404 example status = %d", rec.Code)
+	}
+	if rec := do("/users/7", "500"); rec.Code != http.StatusNotFound {
+		t.Fatalf("undeclared example status = %d, want 404", rec.Code)
+	}
+	if rec := do("/plain", ""); rec.Code != http.StatusOK || rec.Header().Get("X-Mock") != "" {
+		t.Fatalf("controller without examples was mocked: %d %v", rec.Code, rec.Header())
+	}
+
+	r.SetMockMode(false)
+	do("/users/7", "")
+	if !served {
+		t.Fatal("Serve did not run after disabling mock mode")
+	}
+}
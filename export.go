@@ -0,0 +1,526 @@
+package golitekit
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultExportPrefix  = "/exports"
+	DefaultExportTTL     = time.Hour
+	DefaultExportWorkers = 4
+
+	// exportSweepInterval bounds how long expired artifacts outlive their TTL.
+	exportSweepInterval = time.Minute
+)
+
+// ErrExportNotFound is returned by ExportStorage.Open when the artifact is missing.
+var ErrExportNotFound = errors.New("golitekit: export artifact not found")
+
+// ExportStorage persists finished export artifacts until they expire.
+type ExportStorage interface {
+	Save(ctx context.Context, key string, r io.Reader) error
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// DirExportStorage stores artifacts as files in a single directory.
+type DirExportStorage struct {
+	dir string
+}
+
+// NewDirExportStorage creates the directory if needed and returns a storage backed by it.
+func NewDirExportStorage(dir string) (*DirExportStorage, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &DirExportStorage{dir: dir}, nil
+}
+
+func (s *DirExportStorage) Save(ctx context.Context, key string, r io.Reader) error {
+	path := s.path(key)
+	tmp, err := os.CreateTemp(s.dir, ".export-*")
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func (s *DirExportStorage) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	f, err := os.Open(s.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrExportNotFound
+	}
+	return f, err
+}
+
+func (s *DirExportStorage) Delete(ctx context.Context, key string) error {
+	err := os.Remove(s.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+func (s *DirExportStorage) path(key string) string {
+	return filepath.Join(s.dir, filepath.Base(key))
+}
+
+// ExportState is the lifecycle state of an export job.
+type ExportState string
+
+const (
+	ExportQueued    ExportState = "queued"
+	ExportRunning   ExportState = "running"
+	ExportSucceeded ExportState = "succeeded"
+	ExportFailed    ExportState = "failed"
+	ExportExpired   ExportState = "expired"
+)
+
+func (s ExportState) terminal() bool {
+	return s == ExportSucceeded || s == ExportFailed || s == ExportExpired
+}
+
+// ExportStatus is the JSON view of a job returned by the status and SSE endpoints.
+type ExportStatus struct {
+	ID          string      `json:"id"`
+	Name        string      `json:"name,omitempty"`
+	State       ExportState `json:"state"`
+	Progress    int         `json:"progress"`
+	Message     string      `json:"message,omitempty"`
+	Error       string      `json:"error,omitempty"`
+	CreatedAt   time.Time   `json:"created_at"`
+	UpdatedAt   time.Time   `json:"updated_at"`
+	ExpiresAt   time.Time   `json:"expires_at,omitempty"`
+	StatusURL   string      `json:"status_url"`
+	EventsURL   string      `json:"events_url"`
+	DownloadURL string      `json:"download_url,omitempty"`
+}
+
+// ExportSpec describes the artifact an export job produces.
+type ExportSpec struct {
+	Name        string // free-form label shown in the status
+	Filename    string // download filename, defaults to the job ID
+	ContentType string // defaults to application/octet-stream
+}
+
+// ExportFunc writes the artifact to w, reporting progress through job.
+// Returning an error marks the job failed and discards the partial artifact.
+type ExportFunc func(ctx context.Context, job *ExportJob, w io.Writer) error
+
+// ExportJob is a single asynchronous export.
+type ExportJob struct {
+	manager *ExportManager
+	spec    ExportSpec
+	status  ExportStatus
+	changed chan struct{}
+}
+
+// ID returns the job identifier used in its URLs.
+func (j *ExportJob) ID() string { return j.status.ID }
+
+// SetProgress records progress as a percentage (clamped to 0-100) with an
+// optional message and wakes SSE subscribers.
+func (j *ExportJob) SetProgress(percent int, message string) {
+	j.manager.update(j, func(s *ExportStatus) {
+		s.Progress = min(max(percent, 0), 100)
+		if message != "" {
+			s.Message = message
+		}
+	})
+}
+
+// Status returns a snapshot of the job.
+func (j *ExportJob) Status() ExportStatus {
+	status, _ := j.manager.snapshot(j)
+	return status
+}
+
+// ExportOptions configures an ExportManager.
+type ExportOptions struct {
+	Storage ExportStorage // required; where finished artifacts are kept
+	Prefix  string        // URL prefix of the job routes, defaults to DefaultExportPrefix
+	TTL     time.Duration // how long finished jobs and artifacts are kept
+	Workers int           // maximum concurrently running jobs
+}
+
+// ExportManager runs export jobs in the background and serves their status,
+// progress stream and artifacts.
+type ExportManager struct {
+	opts ExportOptions
+	sem  chan struct{}
+
+	ctx       context.Context
+	cancel    context.CancelFunc
+	wg        sync.WaitGroup
+	sweepDone chan struct{}
+
+	mu   sync.Mutex
+	jobs map[string]*ExportJob
+	now  func() time.Time
+}
+
+// NewExportManager creates an ExportManager, filling zero-valued options with
+// defaults. It panics when no Storage is configured.
+func NewExportManager(opts ExportOptions) *ExportManager {
+	if opts.Storage == nil {
+		panic("golitekit: ExportOptions.Storage is required")
+	}
+	if opts.Prefix == "" {
+		opts.Prefix = DefaultExportPrefix
+	}
+	opts.Prefix = "/" + strings.Trim(opts.Prefix, "/")
+	if opts.TTL <= 0 {
+		opts.TTL = DefaultExportTTL
+	}
+	if opts.Workers <= 0 {
+		opts.Workers = DefaultExportWorkers
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	m := &ExportManager{
+		opts:      opts,
+		sem:       make(chan struct{}, opts.Workers),
+		ctx:       ctx,
+		cancel:    cancel,
+		sweepDone: make(chan struct{}),
+		jobs:      make(map[string]*ExportJob),
+		now:       time.Now,
+	}
+	go m.sweepLoop(min(opts.TTL, exportSweepInterval))
+	return m
+}
+
+// Enqueue registers a job and runs fn in the background. The job outlives the
+// request that created it; it is cancelled only by Close.
+func (m *ExportManager) Enqueue(spec ExportSpec, fn ExportFunc) *ExportJob {
+	m.sweep()
+
+	id := newExportID()
+	now := m.now()
+	job := &ExportJob{
+		manager: m,
+		spec:    spec,
+		changed: make(chan struct{}),
+		status: ExportStatus{
+			ID:        id,
+			Name:      spec.Name,
+			State:     ExportQueued,
+			CreatedAt: now,
+			UpdatedAt: now,
+			StatusURL: m.opts.Prefix + "/" + id,
+			EventsURL: m.opts.Prefix + "/" + id + "/events",
+		},
+	}
+
+	m.mu.Lock()
+	m.jobs[id] = job
+	m.mu.Unlock()
+
+	m.wg.Add(1)
+	go m.run(job, fn)
+	return job
+}
+
+// Job looks up a job by ID.
+func (m *ExportManager) Job(id string) (*ExportJob, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	job, ok := m.jobs[id]
+	return job, ok
+}
+
+// Close cancels running jobs, waits for them to finish and stops deleting
+// expired artifacts.
+func (m *ExportManager) Close() error {
+	m.cancel()
+	m.wg.Wait()
+	<-m.sweepDone
+	return nil
+}
+
+// Drain waits for queued and running jobs to finish without cancelling them.
+// When ctx is done first the remaining jobs are cancelled as by Close and
+// ctx.Err() is returned.
+func (m *ExportManager) Drain(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		m.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		m.cancel()
+		return ctx.Err()
+	}
+}
+
+// pending returns the IDs of jobs that have not reached a terminal state.
+func (m *ExportManager) pending() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var ids []string
+	for id, job := range m.jobs {
+		if !job.status.State.terminal() {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func (m *ExportManager) run(job *ExportJob, fn ExportFunc) {
+	defer m.wg.Done()
+
+	select {
+	case m.sem <- struct{}{}:
+		defer func() { <-m.sem }()
+	case <-m.ctx.Done():
+		m.finish(job, m.ctx.Err())
+		return
+	}
+	m.update(job, func(s *ExportStatus) { s.State = ExportRunning })
+
+	pr, pw := io.Pipe()
+	saved := make(chan error, 1)
+	go func() {
+		err := m.opts.Storage.Save(m.ctx, job.ID(), pr)
+		pr.CloseWithError(err)
+		saved <- err
+	}()
+
+	err := runExport(m.ctx, job, pw, fn)
+	pw.CloseWithError(err)
+	if saveErr := <-saved; err == nil {
+		err = saveErr
+	}
+	if err != nil {
+		_ = m.opts.Storage.Delete(context.Background(), job.ID())
+	}
+	m.finish(job, err)
+}
+
+func runExport(ctx context.Context, job *ExportJob, w io.Writer, fn ExportFunc) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("export panic: %v", r)
+		}
+	}()
+	return fn(ctx, job, w)
+}
+
+func (m *ExportManager) finish(job *ExportJob, err error) {
+	m.update(job, func(s *ExportStatus) {
+		if err != nil {
+			s.State = ExportFailed
+			s.Error = err.Error()
+		} else {
+			s.State = ExportSucceeded
+			s.Progress = 100
+			s.DownloadURL = m.opts.Prefix + "/" + s.ID + "/download"
+		}
+		s.ExpiresAt = m.now().Add(m.opts.TTL)
+	})
+}
+
+func (m *ExportManager) update(job *ExportJob, fn func(*ExportStatus)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	fn(&job.status)
+	job.status.UpdatedAt = m.now()
+	close(job.changed)
+	job.changed = make(chan struct{})
+}
+
+// snapshot returns the job status and a channel closed on its next change.
+func (m *ExportManager) snapshot(job *ExportJob) (ExportStatus, <-chan struct{}) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	status := job.status
+	if status.State.terminal() && !status.ExpiresAt.IsZero() && !m.now().Before(status.ExpiresAt) {
+		status.State = ExportExpired
+		status.DownloadURL = ""
+	}
+	return status, job.changed
+}
+
+// sweepLoop sweeps every interval until the manager is closed, so artifacts
+// expire even when no new jobs are enqueued.
+func (m *ExportManager) sweepLoop(interval time.Duration) {
+	defer close(m.sweepDone)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			m.sweep()
+		case <-m.ctx.Done():
+			return
+		}
+	}
+}
+
+// sweep drops expired jobs and deletes their artifacts.
+func (m *ExportManager) sweep() {
+	var expired []string
+
+	m.mu.Lock()
+	now := m.now()
+	for id, job := range m.jobs {
+		if job.status.State.terminal() && !now.Before(job.status.ExpiresAt) {
+			delete(m.jobs, id)
+			expired = append(expired, id)
+		}
+	}
+	m.mu.Unlock()
+
+	for _, id := range expired {
+		_ = m.opts.Storage.Delete(context.Background(), id)
+	}
+}
+
+func newExportID() string {
+	var b [12]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b[:])
+}
+
+// AcceptExport answers the current request with 202 Accepted, a Location
+// header pointing at the job status and the status as the JSON body.
+func AcceptExport(ctx context.Context, job *ExportJob) error {
+	gcx := GetContext(ctx)
+	if gcx == nil {
+		return fmt.Errorf("golitekit: context not initialized")
+	}
+	status := job.Status()
+	if w := gcx.ResponseWriter(); w != nil {
+		w.Header().Set("Location", status.StatusURL)
+	}
+	return gcx.JSON(http.StatusAccepted, status)
+}
+
+// MountExports registers the job routes under the manager prefix:
+//
+//	GET {prefix}/{id}           job status
+//	GET {prefix}/{id}/events    progress stream (SSE) until the job finishes
+//	GET {prefix}/{id}/download  the finished artifact
+//
+// Unlike health probes these routes run through the middleware chain so
+// logging and any authentication middleware apply.
+func (r *Router) MountExports(m *ExportManager) {
+	prefix := m.opts.Prefix
+	r.GET(prefix+"/{id}", HandlerFunc(func(ctx *Context) error {
+		job, err := m.lookup(ctx.Param("id"))
+		if err != nil {
+			return err
+		}
+		status, _ := m.snapshot(job)
+		return ctx.JSON(http.StatusOK, status)
+	}))
+	r.GET(prefix+"/{id}/events", HandlerFunc(func(ctx *Context) error {
+		job, err := m.lookup(ctx.Param("id"))
+		if err != nil {
+			return err
+		}
+		return m.stream(ctx, job)
+	}))
+	r.GET(prefix+"/{id}/download", HandlerFunc(func(ctx *Context) error {
+		job, err := m.lookup(ctx.Param("id"))
+		if err != nil {
+			return err
+		}
+		return m.download(ctx, job)
+	}))
+}
+
+func (m *ExportManager) lookup(id string) (*ExportJob, error) {
+	job, ok := m.Job(id)
+	if !ok {
+		return nil, ErrNotFound("Export not found", nil)
+	}
+	return job, nil
+}
+
+func (m *ExportManager) stream(ctx *Context, job *ExportJob) error {
+	sse := ctx.SSEWriter()
+	reqCtx := ctx.Request().Context()
+	for {
+		status, changed := m.snapshot(job)
+		event := "progress"
+		if status.State.terminal() {
+			event = string(status.State)
+		}
+		if err := sse.Send(SSEvent{Event: event, Data: status}); err != nil {
+			return nil
+		}
+		if status.State.terminal() {
+			return nil
+		}
+		select {
+		case <-changed:
+		case <-reqCtx.Done():
+			return nil
+		}
+	}
+}
+
+func (m *ExportManager) download(ctx *Context, job *ExportJob) error {
+	status, _ := m.snapshot(job)
+	switch status.State {
+	case ExportSucceeded:
+	case ExportExpired:
+		return NewAppError(http.StatusGone, "Export expired", nil)
+	case ExportFailed:
+		return ErrConflict("Export failed", nil)
+	default:
+		return ErrConflict("Export not finished", nil)
+	}
+
+	rc, err := m.opts.Storage.Open(ctx.Request().Context(), job.ID())
+	if errors.Is(err, ErrExportNotFound) {
+		return NewAppError(http.StatusGone, "Export expired", err)
+	}
+	if err != nil {
+		return ErrInternal("Failed to open export", err)
+	}
+	defer rc.Close()
+
+	contentType := job.spec.ContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	filename := job.spec.Filename
+	if filename == "" {
+		filename = job.ID()
+	}
+	w := ctx.ResponseWriter()
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	w.WriteHeader(http.StatusOK)
+	_, err = io.Copy(w, rc)
+	return err
+}
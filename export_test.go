@@ -0,0 +1,204 @@
+package golitekit
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestExportApp(t *testing.T, opts ExportOptions) (*App, *ExportManager) {
+	t.Helper()
+	storage, err := NewDirExportStorage(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewDirExportStorage: %v", err)
+	}
+	opts.Storage = storage
+	m := NewExportManager(opts)
+	t.Cleanup(func() { m.Close() })
+
+	app := NewApp(WithExportManager(m))
+	app.MountExports()
+	return app, m
+}
+
+func waitExport(t *testing.T, job *ExportJob) ExportStatus {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if status := job.Status(); status.State.terminal() {
+			return status
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("export %s did not finish", job.ID())
+	return ExportStatus{}
+}
+
+func TestExport_AcceptStatusAndDownload(t *testing.T) {
+	app, _ := newTestExportApp(t, ExportOptions{})
+	var job *ExportJob
+	app.POST("/reports", HandlerFunc(func(ctx *Context) error {
+		job = ctx.Exports().Enqueue(ExportSpec{Name: "report", Filename: "report.csv", ContentType: "text/csv"},
+			func(ctx context.Context, job *ExportJob, w io.Writer) error {
+				job.SetProgress(50, "halfway")
+				_, err := io.WriteString(w, "id,name\n1,alice\n")
+				return err
+			})
+		return AcceptExport(ctx.Request().Context(), job)
+	}))
+
+	rec := httptest.NewRecorder()
+	app.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/reports", nil))
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("status = %d, want 202", rec.Code)
+	}
+	if loc := rec.Header().Get("Location"); loc != "/exports/"+job.ID() {
+		t.Fatalf("Location = %q", loc)
+	}
+
+	waitExport(t, job)
+
+	rec = httptest.NewRecorder()
+	app.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/exports/"+job.ID(), nil))
+	var status ExportStatus
+	if err := json.Unmarshal(rec.Body.Bytes(), &status); err != nil {
+		t.Fatalf("decode status: %v", err)
+	}
+	if status.State != ExportSucceeded || status.Progress != 100 || status.DownloadURL == "" {
+		t.Fatalf("status = %+v, want succeeded with download URL", status)
+	}
+
+	rec = httptest.NewRecorder()
+	app.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, status.DownloadURL, nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("download status = %d, want 200", rec.Code)
+	}
+	if got := rec.Body.String(); got != "id,name\n1,alice\n" {
+		t.Fatalf("download body = %q", got)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "text/csv" {
+		t.Fatalf("Content-Type = %q, want text/csv", ct)
+	}
+}
+
+func TestExport_FailedJobHasNoArtifact(t *testing.T) {
+	app, m := newTestExportApp(t, ExportOptions{})
+	job := m.Enqueue(ExportSpec{}, func(ctx context.Context, job *ExportJob, w io.Writer) error {
+		_, _ = io.WriteString(w, "partial")
+		return errors.New("query failed")
+	})
+	status := waitExport(t, job)
+	if status.State != ExportFailed || status.Error != "query failed" {
+		t.Fatalf("status = %+v, want failed", status)
+	}
+
+	rec := httptest.NewRecorder()
+	app.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/exports/"+job.ID()+"/download", nil))
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("download status = %d, want 409", rec.Code)
+	}
+}
+
+func TestExport_ExpiredArtifactIsGone(t *testing.T) {
+	app, m := newTestExportApp(t, ExportOptions{TTL: time.Minute})
+	now := time.Now()
+	m.mu.Lock()
+	m.now = func() time.Time { return now }
+	m.mu.Unlock()
+
+	job := m.Enqueue(ExportSpec{}, func(ctx context.Context, job *ExportJob, w io.Writer) error {
+		_, err := io.WriteString(w, "data")
+		return err
+	})
+	waitExport(t, job)
+
+	m.mu.Lock()
+	m.now = func() time.Time { return now.Add(2 * time.Minute) }
+	m.mu.Unlock()
+
+	rec := httptest.NewRecorder()
+	app.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/exports/"+job.ID()+"/download", nil))
+	if rec.Code != http.StatusGone {
+		t.Fatalf("download status = %d, want 410", rec.Code)
+	}
+
+	m.sweep()
+	if _, ok := m.Job(job.ID()); ok {
+		t.Fatal("expected expired job to be swept")
+	}
+	if _, err := m.opts.Storage.Open(context.Background(), job.ID()); !errors.Is(err, ErrExportNotFound) {
+		t.Fatalf("Open after sweep err = %v, want ErrExportNotFound", err)
+	}
+}
+
+func TestExport_EventsStreamUntilDone(t *testing.T) {
+	app, m := newTestExportApp(t, ExportOptions{})
+	release := make(chan struct{})
+	job := m.Enqueue(ExportSpec{}, func(ctx context.Context, job *ExportJob, w io.Writer) error {
+		job.SetProgress(10, "started")
+		<-release
+		return nil
+	})
+
+	srv := httptest.NewServer(app.Handler())
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/exports/" + job.ID() + "/events")
+	if err != nil {
+		t.Fatalf("GET events: %v", err)
+	}
+	defer resp.Body.Close()
+	close(release)
+
+	var events []string
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		if name, ok := strings.CutPrefix(scanner.Text(), "event: "); ok {
+			events = append(events, name)
+		}
+	}
+	if len(events) == 0 || events[len(events)-1] != string(ExportSucceeded) {
+		t.Fatalf("events = %v, want stream ending with %q", events, ExportSucceeded)
+	}
+}
+
+func TestExport_UnknownJobIsNotFound(t *testing.T) {
+	app, _ := newTestExportApp(t, ExportOptions{})
+	rec := httptest.NewRecorder()
+	app.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/exports/missing", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("status = %d, want 404", rec.Code)
+	}
+}
+
+func TestExport_ExpiredArtifactsSweptWithoutNewJobs(t *testing.T) {
+	_, m := newTestExportApp(t, ExportOptions{TTL: 20 * time.Millisecond})
+	job := m.Enqueue(ExportSpec{}, func(ctx context.Context, job *ExportJob, w io.Writer) error {
+		_, err := io.WriteString(w, "data")
+		return err
+	})
+	waitExport(t, job)
+
+	waitFor(t, func() bool {
+		_, ok := m.Job(job.ID())
+		return !ok
+	})
+	if _, err := m.opts.Storage.Open(context.Background(), job.ID()); !errors.Is(err, ErrExportNotFound) {
+		t.Fatalf("Open after expiry err = %v, want ErrExportNotFound", err)
+	}
+	if err := m.Close(); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-m.sweepDone:
+	default:
+		t.Fatal("sweeper still running after Close")
+	}
+}
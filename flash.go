@@ -0,0 +1,203 @@
+package golitekit
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+const (
+	DefaultFlashCookie = "glk_flash"
+	// maxFlashCookieBytes keeps the cookie under the 4KB browsers guarantee.
+	maxFlashCookieBytes = 4000
+	flashDataKey        = "golitekit.flash"
+)
+
+// Flash is a one-shot message shown on the next rendered page.
+type Flash struct {
+	Kind    string `json:"kind"` // e.g. "success", "error"
+	Message string `json:"message"`
+}
+
+// FlashOptions configures FlashMiddleware.
+type FlashOptions struct {
+	// Secret signs the flash cookie so clients cannot forge messages. Required.
+	Secret []byte
+	// Cookie is the cookie name, defaults to DefaultFlashCookie.
+	Cookie string
+	// Path is the cookie path, defaults to "/".
+	Path string
+}
+
+// FlashMiddleware enables Flash and Flashes. GoLiteKit has no server-side
+// session, so flashes travel in a signed, HttpOnly cookie: messages added
+// with Flash are sent with the response, and the next request that reads them
+// with Flashes clears the cookie.
+//
+//	app.Use(glk.FlashMiddleware(glk.FlashOptions{Secret: key}))
+//
+//	c.Flash(ctx, "success", "saved")
+//	return c.Redirect(ctx, http.StatusSeeOther, "/orders")
+//
+//	// GET /orders
+//	data := map[string]any{"Flashes": c.Flashes(ctx)}
+func FlashMiddleware(opts FlashOptions) Middleware {
+	if len(opts.Secret) == 0 {
+		panic("golitekit: FlashMiddleware requires a secret")
+	}
+	if opts.Cookie == "" {
+		opts.Cookie = DefaultFlashCookie
+	}
+	if opts.Path == "" {
+		opts.Path = "/"
+	}
+	return func(next Handler) Handler {
+		return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			state := &flashState{opts: opts, w: w, secure: r.TLS != nil}
+			if c, err := r.Cookie(opts.Cookie); err == nil {
+				state.incoming, _ = decodeFlashes(c.Value, opts.Secret)
+			}
+			SetContextData(ctx, flashDataKey, state)
+			return next(ctx, w, r)
+		}
+	}
+}
+
+type flashState struct {
+	mu       sync.Mutex
+	opts     FlashOptions
+	w        http.ResponseWriter
+	secure   bool
+	incoming []Flash
+	consumed bool
+	outgoing []Flash
+}
+
+// writeCookie replaces the flash Set-Cookie header with the current state:
+// the outgoing messages, or a deletion once the incoming ones were read.
+func (s *flashState) writeCookie() error {
+	cookie := &http.Cookie{
+		Name:     s.opts.Cookie,
+		Path:     s.opts.Path,
+		HttpOnly: true,
+		Secure:   s.secure,
+		SameSite: http.SameSiteLaxMode,
+	}
+	switch {
+	case len(s.outgoing) > 0:
+		cookie.Value = encodeFlashes(s.outgoing, s.opts.Secret)
+		if len(cookie.Value) > maxFlashCookieBytes {
+			return ErrInternal("flash messages exceed the cookie size limit", nil)
+		}
+	case s.consumed:
+		cookie.MaxAge = -1
+	default:
+		return nil
+	}
+
+	header := s.w.Header()
+	var kept []string
+	for _, v := range header.Values("Set-Cookie") {
+		if !strings.HasPrefix(v, s.opts.Cookie+"=") {
+			kept = append(kept, v)
+		}
+	}
+	header.Del("Set-Cookie")
+	for _, v := range kept {
+		header.Add("Set-Cookie", v)
+	}
+	http.SetCookie(s.w, cookie)
+	return nil
+}
+
+func (ctx *Context) flashState() *flashState {
+	ctx.dataLock.RLock()
+	defer ctx.dataLock.RUnlock()
+	state, _ := ctx.data[flashDataKey].(*flashState)
+	return state
+}
+
+// AddFlash queues a flash message for the next request. It must be called
+// before the response is written and requires FlashMiddleware.
+func (ctx *Context) AddFlash(kind, message string) error {
+	state := ctx.flashState()
+	if state == nil {
+		return ErrInternal("golitekit: flash messages require FlashMiddleware", nil)
+	}
+	state.mu.Lock()
+	defer state.mu.Unlock()
+	state.outgoing = append(state.outgoing, Flash{Kind: kind, Message: message})
+	return state.writeCookie()
+}
+
+// Flashes returns the flash messages set by the previous request and
+// consumes them. It returns nil without FlashMiddleware.
+func (ctx *Context) Flashes() []Flash {
+	state := ctx.flashState()
+	if state == nil {
+		return nil
+	}
+	state.mu.Lock()
+	defer state.mu.Unlock()
+	if state.consumed || len(state.incoming) == 0 {
+		return nil
+	}
+	state.consumed = true
+	_ = state.writeCookie()
+	return state.incoming
+}
+
+// Flash queues a flash message for the next request; see FlashMiddleware.
+func (c *BaseControllerOf[T]) Flash(ctx context.Context, kind, message string) error {
+	gcx := c.context(ctx)
+	if gcx == nil {
+		return ErrInternal("golitekit: context not initialized", nil)
+	}
+	return gcx.AddFlash(kind, message)
+}
+
+// Flashes returns and consumes the flash messages of the previous request.
+func (c *BaseControllerOf[T]) Flashes(ctx context.Context) []Flash {
+	gcx := c.context(ctx)
+	if gcx == nil {
+		return nil
+	}
+	return gcx.Flashes()
+}
+
+func encodeFlashes(flashes []Flash, secret []byte) string {
+	payload, _ := json.Marshal(flashes)
+	enc := base64.RawURLEncoding.EncodeToString(payload)
+	return enc + "." + base64.RawURLEncoding.EncodeToString(flashMAC(enc, secret))
+}
+
+func decodeFlashes(value string, secret []byte) ([]Flash, bool) {
+	enc, sig, ok := strings.Cut(value, ".")
+	if !ok {
+		return nil, false
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil || !hmac.Equal(mac, flashMAC(enc, secret)) {
+		return nil, false
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(enc)
+	if err != nil {
+		return nil, false
+	}
+	var flashes []Flash
+	if json.Unmarshal(payload, &flashes) != nil {
+		return nil, false
+	}
+	return flashes, true
+}
+
+func flashMAC(payload string, secret []byte) []byte {
+	h := hmac.New(sha256.New, secret)
+	h.Write([]byte("glk-flash:" + payload))
+	return h.Sum(nil)
+}
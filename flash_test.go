@@ -0,0 +1,85 @@
+package golitekit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type saveController struct {
+	BaseController
+}
+
+func (c *saveController) Serve(ctx context.Context) error {
+	if err := c.Flash(ctx, "success", "saved"); err != nil {
+		return err
+	}
+	if err := c.Flash(ctx, "info", "email sent"); err != nil {
+		return err
+	}
+	return c.Redirect(ctx, http.StatusSeeOther, "/orders")
+}
+
+type ordersController struct {
+	BaseController
+	seen *[][]Flash
+}
+
+func (c *ordersController) Serve(ctx context.Context) error {
+	*c.seen = append(*c.seen, c.Flashes(ctx))
+	return c.HTML(http.StatusOK, "<p>orders</p>")
+}
+
+func TestFlashMessagesRoundTrip(t *testing.T) {
+	var seen [][]Flash
+	r := newTestRouter()
+	r.Use(FlashMiddleware(FlashOptions{Secret: []byte("k")}))
+	r.POST("/orders", &saveController{})
+	r.GET("/orders", &ordersController{seen: &seen})
+
+	rec := httptest.NewRecorder()
+	r.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/orders", nil))
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("status = %d", rec.Code)
+	}
+	cookies := rec.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Name != DefaultFlashCookie || !cookies[0].HttpOnly {
+		t.Fatalf("cookies = %+v, want one flash cookie", cookies)
+	}
+
+	get := func(c *http.Cookie) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/orders", nil)
+		if c != nil {
+			req.AddCookie(c)
+		}
+		rec := httptest.NewRecorder()
+		r.Handler().ServeHTTP(rec, req)
+		return rec
+	}
+	rec = get(cookies[0])
+	if len(seen) != 1 || len(seen[0]) != 2 || seen[0][0] != (Flash{"success", "saved"}) || seen[0][1].Message != "email sent" {
+		t.Fatalf("flashes = %+v", seen)
+	}
+	if cleared := rec.Result().Cookies(); len(cleared) != 1 || cleared[0].MaxAge >= 0 {
+		t.Errorf("flash cookie not cleared after reading: %+v", cleared)
+	}
+
+	forged := *cookies[0]
+	forged.Value = strings.Replace(forged.Value, forged.Value[:4], "AAAA", 1)
+	get(&forged)
+	if len(seen) != 2 || seen[1] != nil {
+		t.Errorf("forged cookie accepted: %+v", seen[1])
+	}
+}
+
+func TestFlashWithoutMiddleware(t *testing.T) {
+	r := newTestRouter()
+	r.POST("/orders", &saveController{})
+	rec := httptest.NewRecorder()
+	r.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/orders", nil))
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want 500 when FlashMiddleware is missing", rec.Code)
+	}
+}
@@ -0,0 +1,474 @@
+package cmd
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/hansir-hsj/GoLiteKit/config"
+	"github.com/hansir-hsj/GoLiteKit/db"
+	"github.com/hansir-hsj/GoLiteKit/env"
+	"github.com/hansir-hsj/GoLiteKit/logger"
+	"github.com/hansir-hsj/GoLiteKit/redis"
+	"github.com/spf13/cobra"
+)
+
+// certExpiryWarning is how close to expiry a certificate starts to warn.
+const certExpiryWarning = 30 * 24 * time.Hour
+
+var (
+	doctorDir     string
+	doctorConfig  string
+	doctorConnect bool
+	doctorTimeout time.Duration
+)
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Validate the project configuration before deploying",
+	Long: `Parse conf/app.toml and the logger, DB and Redis files it references,
+validate their values, and check that TLS certificates and keys load and
+that log, static and journal directories exist and are writable.
+
+With --connect, also open a connection to the configured DB and Redis.
+Exits with a non-zero status when any check fails.
+
+Example:
+  glk doctor --connect
+  ✗ HttpServer.TLSConfig: load conf/tls/server.crt: open ...: no such file or directory
+      fix certFile/keyFile, or set tls = false`,
+	Args:          cobra.NoArgs,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := context.WithCancel(cmd.Context())
+		defer cancel()
+		report := runDoctor(ctx, doctorOptions{
+			Dir:     doctorDir,
+			Config:  doctorConfig,
+			Connect: doctorConnect,
+			Timeout: doctorTimeout,
+		})
+		report.print(os.Stdout)
+		if n := report.failures(); n > 0 {
+			return fmt.Errorf("glk doctor: %d check(s) failed", n)
+		}
+		return nil
+	},
+}
+
+func init() {
+	doctorCmd.Flags().StringVar(&doctorDir, "dir", ".", "Project directory (the server's working directory)")
+	doctorCmd.Flags().StringVar(&doctorConfig, "config", filepath.Join("conf", "app.toml"), "App config, relative to --dir")
+	doctorCmd.Flags().BoolVar(&doctorConnect, "connect", false, "Also check DB and Redis connectivity")
+	doctorCmd.Flags().DurationVar(&doctorTimeout, "timeout", 5*time.Second, "Timeout of each connectivity check")
+}
+
+type doctorOptions struct {
+	Dir     string
+	Config  string
+	Connect bool
+	Timeout time.Duration
+}
+
+type doctorStatus int
+
+const (
+	doctorOK doctorStatus = iota
+	doctorWarn
+	doctorFail
+)
+
+// doctorCheck is one line of the report; Hint says how to fix a problem.
+type doctorCheck struct {
+	Status  doctorStatus
+	Subject string
+	Message string
+	Hint    string
+}
+
+type doctorReport struct {
+	checks []doctorCheck
+}
+
+func (r *doctorReport) ok(subject, format string, args ...any) {
+	r.checks = append(r.checks, doctorCheck{doctorOK, subject, fmt.Sprintf(format, args...), ""})
+}
+
+func (r *doctorReport) warn(subject, hint, format string, args ...any) {
+	r.checks = append(r.checks, doctorCheck{doctorWarn, subject, fmt.Sprintf(format, args...), hint})
+}
+
+func (r *doctorReport) fail(subject, hint, format string, args ...any) {
+	r.checks = append(r.checks, doctorCheck{doctorFail, subject, fmt.Sprintf(format, args...), hint})
+}
+
+func (r *doctorReport) failures() int {
+	n := 0
+	for _, c := range r.checks {
+		if c.Status == doctorFail {
+			n++
+		}
+	}
+	return n
+}
+
+func (r *doctorReport) print(w io.Writer) {
+	marks := map[doctorStatus]string{
+		doctorOK:   "\x1b[32m✓\x1b[0m",
+		doctorWarn: "\x1b[33m!\x1b[0m",
+		doctorFail: "\x1b[31m✗\x1b[0m",
+	}
+	warnings := 0
+	for _, c := range r.checks {
+		fmt.Fprintf(w, "%s %s: %s\n", marks[c.Status], c.Subject, c.Message)
+		if c.Hint != "" {
+			fmt.Fprintf(w, "    %s\n", c.Hint)
+		}
+		if c.Status == doctorWarn {
+			warnings++
+		}
+	}
+	fmt.Fprintf(w, "\n%d check(s), %d warning(s), %d failure(s)\n", len(r.checks), warnings, r.failures())
+}
+
+// runDoctor resolves paths like env does when the server runs from opts.Dir:
+// config files, certificates and keys against <dir>/conf, the static
+// directory, the journal and the log directory against <dir>.
+func runDoctor(ctx context.Context, opts doctorOptions) *doctorReport {
+	r := &doctorReport{}
+	root, err := filepath.Abs(opts.Dir)
+	if err != nil {
+		r.fail("project", "", "%v", err)
+		return r
+	}
+	confDir := filepath.Join(root, "conf")
+	appPath := opts.Config
+	if !filepath.IsAbs(appPath) {
+		appPath = filepath.Join(root, appPath)
+	}
+
+	var e env.Env
+	if err := config.Parse(appPath, &e); err != nil {
+		r.fail(rel(root, appPath), "fix the file, then run glk doctor again", "%v", err)
+		return r
+	}
+	r.ok(rel(root, appPath), "parsed (runMode %q)", e.RunMode)
+
+	checkHTTPServer(r, &e.EnvHttpServer)
+	checkTLS(r, root, confDir, &e.EnvHttpServer)
+	checkDirs(r, root, &e.EnvHttpServer)
+
+	if e.Logger != "" {
+		checkLogger(r, root, filepath.Join(confDir, e.Logger))
+	}
+	var dbPath, redisPath string
+	if e.DB != "" {
+		dbPath = filepath.Join(confDir, e.DB)
+		checkDB(r, root, dbPath)
+	}
+	if e.Redis != "" {
+		redisPath = filepath.Join(confDir, e.Redis)
+		checkRedis(r, root, redisPath)
+	}
+
+	if opts.Connect {
+		if dbPath != "" && r.passed(rel(root, dbPath)) {
+			checkConnect(ctx, r, "DB", opts.Timeout, func() error {
+				conn, err := db.NewFromConfig(dbPath)
+				if err == nil {
+					db.Close(conn)
+				}
+				return err
+			})
+		}
+		if redisPath != "" && r.passed(rel(root, redisPath)) {
+			checkConnect(ctx, r, "Redis", opts.Timeout, func() error {
+				client, err := redis.NewFromConfig(redisPath)
+				if err == nil {
+					redis.Close(client)
+				}
+				return err
+			})
+		}
+	}
+	return r
+}
+
+// passed reports whether no check of subject failed.
+func (r *doctorReport) passed(subject string) bool {
+	for _, c := range r.checks {
+		if c.Subject == subject && c.Status == doctorFail {
+			return false
+		}
+	}
+	return true
+}
+
+func checkHTTPServer(r *doctorReport, s *env.EnvHttpServer) {
+	const subject = "HttpServer"
+	problems := 0
+	switch s.Network {
+	case "", "tcp", "tcp4", "tcp6", "unix":
+	default:
+		r.fail(subject, "use tcp, tcp4, tcp6 or unix", "unknown network %q", s.Network)
+		problems++
+	}
+	if s.Addr == "" {
+		r.warn(subject, `set addr, e.g. addr = ":8080"`, "addr is empty, the server listens on :http (port 80)")
+		problems++
+	}
+	timeouts := []struct {
+		key   string
+		value int
+	}{
+		{"Timeout.readTimeout", s.ReadTimeout},
+		{"Timeout.readHeaderTimeout", s.ReadHeaderTimeout},
+		{"Timeout.writeTimeout", s.WriteTimeout},
+		{"Timeout.idleTimeout", s.IdleTimeout},
+		{"Timeout.shutdownTimeout", s.ShutdownTimeout},
+	}
+	for _, t := range timeouts {
+		if t.value < 0 {
+			r.fail(subject, "timeouts are milliseconds; use 0 for the default", "%s is negative (%d)", t.key, t.value)
+			problems++
+		}
+	}
+	if s.RateLimit < 0 || s.RateBurst < 0 {
+		r.fail(subject, "use 0 to disable rate limiting", "RateLimit values must not be negative")
+		problems++
+	} else if s.RateBurst > 0 && s.RateBurst < s.RateLimit {
+		r.warn(subject, "set rateBurst >= rateLimit", "rateBurst %d is below rateLimit %d", s.RateBurst, s.RateLimit)
+		problems++
+	}
+	for name, t := range s.Tiers {
+		if t.RateLimit < 0 || t.RateBurst < 0 {
+			r.fail(subject, "use 0 to block the tier", "RateLimit.tiers.%s values must not be negative", name)
+			problems++
+		}
+	}
+	if s.MaxHeaderBytes < 0 {
+		r.fail(subject, "use 0 for the default (1 MiB)", "maxHeaderBytes is negative")
+		problems++
+	}
+	if problems == 0 {
+		r.ok(subject, "listening on %s", s.Addr)
+	}
+}
+
+func checkTLS(r *doctorReport, root, confDir string, s *env.EnvHttpServer) {
+	if s.TLS {
+		checkCertificate(r, root, confDir, "HttpServer.TLSConfig", s.CertFile, s.KeyFile, s.ClientCAFile, s.ClientAuth)
+	}
+	for i, l := range s.Listeners {
+		subject := fmt.Sprintf("HttpServer.Listeners[%d]", i)
+		if l.Name != "" {
+			subject = fmt.Sprintf("HttpServer.Listeners %q", l.Name)
+		}
+		if l.Addr == "" {
+			r.fail(subject, "set addr", "addr is empty")
+		}
+		if l.RedirectHTTPS && l.HTTPSAddr == "" {
+			r.warn(subject, "set httpsAddr to the public HTTPS address", "redirectHTTPS without httpsAddr redirects to the request host")
+		}
+		if l.TLS {
+			checkCertificate(r, root, confDir, subject, l.CertFile, l.KeyFile, l.ClientCAFile, l.ClientAuth)
+		}
+	}
+}
+
+// checkCertificate loads a certificate pair and the client CA bundle the way
+// the server does and reports load errors and (near) expiry.
+func checkCertificate(r *doctorReport, root, confDir, subject, certFile, keyFile, caFile, clientAuth string) {
+	if certFile == "" || keyFile == "" {
+		r.fail(subject, "set certFile and keyFile, or tls = false", "tls is enabled without certFile/keyFile")
+		return
+	}
+	certPath, keyPath := filepath.Join(confDir, certFile), filepath.Join(confDir, keyFile)
+	pair, err := tls.LoadX509KeyPair(certPath, keyPath)
+	if err != nil {
+		r.fail(subject, "fix certFile/keyFile (relative to conf/), or set tls = false", "load %s: %v", rel(root, certPath), err)
+		return
+	}
+	leaf, err := x509.ParseCertificate(pair.Certificate[0])
+	if err != nil {
+		r.fail(subject, "", "parse %s: %v", rel(root, certPath), err)
+		return
+	}
+	switch left := time.Until(leaf.NotAfter); {
+	case left <= 0:
+		r.fail(subject, "renew the certificate", "%s expired on %s", rel(root, certPath), leaf.NotAfter.Format(time.DateOnly))
+	case left < certExpiryWarning:
+		r.warn(subject, "renew the certificate", "%s expires on %s", rel(root, certPath), leaf.NotAfter.Format(time.DateOnly))
+	default:
+		r.ok(subject, "%s valid until %s", rel(root, certPath), leaf.NotAfter.Format(time.DateOnly))
+	}
+
+	switch strings.ToLower(strings.TrimSpace(clientAuth)) {
+	case "", "none", "request", "require-any", "verify-if-given", "require":
+	default:
+		r.fail(subject, "use none, request, require-any, verify-if-given or require", "unknown clientAuth %q", clientAuth)
+	}
+	if caFile == "" {
+		return
+	}
+	caPath := filepath.Join(confDir, caFile)
+	pem, err := os.ReadFile(caPath)
+	if err != nil {
+		r.fail(subject, "fix clientCAFile (relative to conf/)", "%v", err)
+		return
+	}
+	if !x509.NewCertPool().AppendCertsFromPEM(pem) {
+		r.fail(subject, "clientCAFile must be a PEM bundle", "no certificates found in %s", rel(root, caPath))
+		return
+	}
+	r.ok(subject, "client CA bundle %s loaded", rel(root, caPath))
+}
+
+func checkDirs(r *doctorReport, root string, s *env.EnvHttpServer) {
+	if s.StaticDir != "" {
+		dir := filepath.Join(root, s.StaticDir)
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			r.fail("HttpServer.Static", "create the directory or remove staticDir", "%s is not a directory", rel(root, dir))
+		} else {
+			r.ok("HttpServer.Static", "serving %s", rel(root, dir))
+		}
+	}
+	if s.JournalFile != "" {
+		file := s.JournalFile
+		if !filepath.IsAbs(file) {
+			file = filepath.Join(root, file)
+		}
+		checkWritableDir(r, root, "HttpServer.Journal", filepath.Dir(file))
+	}
+}
+
+func checkLogger(r *doctorReport, root, path string) {
+	subject := rel(root, path)
+	var cfg logger.Config
+	if err := config.Parse(path, &cfg); err != nil {
+		r.fail(subject, "fix [HttpServer.Logger] configFile or the file itself", "%v", err)
+		return
+	}
+	if _, err := logger.ParseLevel(cfg.MinLevel); err != nil {
+		r.fail(subject, "use TRACE, DEBUG, INFO, WARN, ERROR or FATAL", "%v", err)
+	}
+	switch strings.ToLower(cfg.Format) {
+	case "", logger.LoggerTextFormat, logger.LoggerJSONFormat:
+	default:
+		r.warn(subject, "use text or json", "unknown format %q is written as text", cfg.Format)
+	}
+	dir := cfg.Dir
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(root, dir)
+	}
+	checkWritableDir(r, root, subject, dir)
+}
+
+func checkDB(r *doctorReport, root, path string) {
+	subject := rel(root, path)
+	var cfg db.Config
+	if err := config.Parse(path, &cfg); err != nil {
+		r.fail(subject, "fix [HttpServer.DB] configFile or the file itself", "%v", err)
+		return
+	}
+	switch {
+	case cfg.DSN != "":
+		r.ok(subject, "dsn set")
+	case cfg.Host == "" || cfg.Database == "":
+		r.fail(subject, "set dsn, or host, port, database, username and password", "no dsn and no host/database")
+	case cfg.Port <= 0 || cfg.Port > 65535:
+		r.fail(subject, "set port, e.g. 3306", "invalid port %d", cfg.Port)
+	default:
+		r.ok(subject, "%s:%d/%s", cfg.Host, cfg.Port, cfg.Database)
+	}
+	if cfg.MaxIdleConns > cfg.MaxOpenConns && cfg.MaxOpenConns > 0 {
+		r.warn(subject, "set maxIdleConns <= maxOpenConns", "maxIdleConns %d exceeds maxOpenConns %d", cfg.MaxIdleConns, cfg.MaxOpenConns)
+	}
+}
+
+func checkRedis(r *doctorReport, root, path string) {
+	subject := rel(root, path)
+	var cfg redis.Config
+	if err := config.Parse(path, &cfg); err != nil {
+		r.fail(subject, "fix [HttpServer.Redis] configFile or the file itself", "%v", err)
+		return
+	}
+	switch {
+	case cfg.Host == "":
+		r.fail(subject, "set host and port", "host is empty")
+	case cfg.Port <= 0 || cfg.Port > 65535:
+		r.fail(subject, "set port, e.g. 6379", "invalid port %d", cfg.Port)
+	default:
+		r.ok(subject, "%s:%d db %d", cfg.Host, cfg.Port, cfg.DB)
+	}
+}
+
+// checkConnect runs connect with a timeout; the DB and Redis constructors
+// dial and ping with their own timeouts, which may be longer.
+func checkConnect(ctx context.Context, r *doctorReport, subject string, timeout time.Duration, connect func() error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	done := make(chan error, 1)
+	start := time.Now()
+	go func() { done <- connect() }()
+	select {
+	case err := <-done:
+		if err != nil {
+			r.fail(subject, "check the address, credentials and firewall rules", "%v", err)
+			return
+		}
+		r.ok(subject, "connected in %s", time.Since(start).Round(time.Millisecond))
+	case <-ctx.Done():
+		r.fail(subject, "check the address and firewall rules, or raise --timeout", "no connection after %s", timeout)
+	}
+}
+
+// checkWritableDir creates and removes a file in dir, or checks that the
+// nearest existing parent is writable when dir does not exist yet.
+func checkWritableDir(r *doctorReport, root, subject, dir string) {
+	target := dir
+	for {
+		info, err := os.Stat(target)
+		if err == nil {
+			if !info.IsDir() {
+				r.fail(subject, "point it at a directory", "%s is not a directory", rel(root, target))
+				return
+			}
+			break
+		}
+		if !errors.Is(err, fs.ErrNotExist) || filepath.Dir(target) == target {
+			r.fail(subject, "", "%v", err)
+			return
+		}
+		target = filepath.Dir(target)
+	}
+	f, err := os.CreateTemp(target, ".glk-doctor-*")
+	if err != nil {
+		r.fail(subject, "fix the directory permissions for the server user", "%s is not writable: %v", rel(root, target), err)
+		return
+	}
+	f.Close()
+	os.Remove(f.Name())
+	if target != dir {
+		r.ok(subject, "%s will be created", rel(root, dir))
+		return
+	}
+	r.ok(subject, "%s is writable", rel(root, dir))
+}
+
+// rel shortens path for the report.
+func rel(root, path string) string {
+	if p, err := filepath.Rel(root, path); err == nil && !strings.HasPrefix(p, "..") {
+		return p
+	}
+	return path
+}
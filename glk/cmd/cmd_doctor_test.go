@@ -0,0 +1,185 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeCert writes a self-signed certificate and its key valid until notAfter.
+func writeCert(t *testing.T, dir, name string, notAfter time.Time) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		NotBefore:    notAfter.Add(-365 * 24 * time.Hour),
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tpl, tpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(dir, name+".crt"), string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})))
+	writeFile(t, filepath.Join(dir, name+".key"), string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})))
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// closedPort returns a local port nothing listens on.
+func closedPort(t *testing.T) int {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := l.Addr().(*net.TCPAddr).Port
+	l.Close()
+	return port
+}
+
+func TestDoctor_ReportsActionableProblems(t *testing.T) {
+	dir := t.TempDir()
+	conf := filepath.Join(dir, "conf")
+	writeCert(t, filepath.Join(conf, "tls"), "server", time.Now().Add(-time.Hour))
+	writeCert(t, filepath.Join(conf, "tls"), "admin", time.Now().Add(10*24*time.Hour))
+
+	writeFile(t, filepath.Join(conf, "app.toml"), `
+[HttpServer]
+runMode = "prod"
+addr = ":8443"
+
+[HttpServer.Timeout]
+writeTimeout = -1
+
+[HttpServer.Logger]
+configFile = "logger.toml"
+
+[HttpServer.DB]
+configFile = "db.toml"
+
+[HttpServer.Redis]
+configFile = "redis.toml"
+
+[HttpServer.Static]
+staticDir = "static"
+
+[HttpServer.TLSConfig]
+tls = true
+certFile = "tls/server.crt"
+keyFile = "tls/server.key"
+
+[[HttpServer.Listeners]]
+name = "admin"
+addr = "127.0.0.1:9443"
+tls = true
+certFile = "tls/admin.crt"
+keyFile = "tls/admin.key"
+clientAuth = "sometimes"
+`)
+	writeFile(t, filepath.Join(conf, "logger.toml"), "[logger]\ndir = \"var/logs\"\nlevel = \"verbose\"\n")
+	writeFile(t, filepath.Join(conf, "db.toml"), "[db]\nhost = \"\"\n")
+	writeFile(t, filepath.Join(conf, "redis.toml"), fmt.Sprintf("[redis]\nhost = \"127.0.0.1\"\nport = %d\n", closedPort(t)))
+
+	report := runDoctor(context.Background(), doctorOptions{Dir: dir, Config: "conf/app.toml", Connect: true, Timeout: 5 * time.Second})
+	var out bytes.Buffer
+	report.print(&out)
+
+	for _, want := range []string{
+		"✓\x1b[0m conf/app.toml: parsed",
+		"HttpServer: Timeout.writeTimeout is negative (-1)",
+		"HttpServer.TLSConfig: conf/tls/server.crt expired on",
+		`HttpServer.Listeners "admin": conf/tls/admin.crt expires on`,
+		`unknown clientAuth "sometimes"`,
+		"HttpServer.Static: static is not a directory",
+		"conf/logger.toml: invalid log level: verbose",
+		"conf/logger.toml: var/logs will be created",
+		"conf/db.toml: no dsn and no host/database",
+		"Redis: ",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("report missing %q:\n%s", want, out.String())
+		}
+	}
+	if strings.Contains(out.String(), "\nDB:") || strings.Contains(out.String(), "m DB:") {
+		t.Errorf("DB connectivity should be skipped when its config is invalid:\n%s", out.String())
+	}
+	for _, c := range report.checks {
+		if c.Subject == "Redis" && c.Status != doctorFail {
+			t.Errorf("redis check on a closed port = %+v", c)
+		}
+	}
+	if report.failures() != 7 {
+		t.Errorf("failures = %d, want 7:\n%s", report.failures(), out.String())
+	}
+}
+
+func TestDoctor_HealthyProject(t *testing.T) {
+	dir := t.TempDir()
+	conf := filepath.Join(dir, "conf")
+	writeCert(t, filepath.Join(conf, "tls"), "server", time.Now().Add(365*24*time.Hour))
+	writeFile(t, filepath.Join(conf, "app.toml"), `
+[HttpServer]
+addr = ":8080"
+
+[HttpServer.Logger]
+configFile = "logger.toml"
+
+[HttpServer.Journal]
+file = "run/requests.journal"
+
+[HttpServer.TLSConfig]
+tls = true
+certFile = "tls/server.crt"
+keyFile = "tls/server.key"
+`)
+	writeFile(t, filepath.Join(conf, "logger.toml"), "[logger]\ndir = \"logs\"\n")
+	if err := os.MkdirAll(filepath.Join(dir, "logs"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	report := runDoctor(context.Background(), doctorOptions{Dir: dir, Config: "conf/app.toml"})
+	var out bytes.Buffer
+	report.print(&out)
+	if report.failures() != 0 || strings.Contains(out.String(), "!") {
+		t.Fatalf("unexpected problems:\n%s", out.String())
+	}
+	if !strings.Contains(out.String(), "conf/logger.toml: logs is writable") {
+		t.Fatalf("log directory not checked:\n%s", out.String())
+	}
+}
+
+func TestDoctor_MissingConfig(t *testing.T) {
+	report := runDoctor(context.Background(), doctorOptions{Dir: t.TempDir(), Config: "conf/app.toml"})
+	if report.failures() != 1 || len(report.checks) != 1 {
+		t.Fatalf("checks = %+v", report.checks)
+	}
+}
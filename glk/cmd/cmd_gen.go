@@ -0,0 +1,450 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/spf13/cobra"
+)
+
+var genCmd = &cobra.Command{
+	Use:   "gen",
+	Short: "Generate code from the current project",
+	Long:  "Generate code derived from the routes and types of the current GoLiteKit project.",
+}
+
+var (
+	genParamsDir    string
+	genParamsOutput string
+	genParamsTypes  []string
+)
+
+var genParamsCmd = &cobra.Command{
+	Use:   "params",
+	Short: "Generate typed path parameter structs",
+	Long: `Scan route registrations (app.GET("/users/{id}", &UserShowController{}) and
+friends, including Group prefixes) and generate a typed struct plus parser
+for every route that has path parameters.
+
+Parameters named id, *_id or *ID are parsed as int64, everything else as
+string; override with --type name=int|int64|uint64|float64|bool|string.
+
+Example:
+  glk gen params
+  → creates params/params_gen.go with UserShowParams{ID int64} and
+    ParseUserShowParams(r *http.Request) (UserShowParams, error)`,
+	Run: runGenParams,
+}
+
+func init() {
+	genParamsCmd.Flags().StringVar(&genParamsDir, "dir", ".", "Project directory to scan for routes")
+	genParamsCmd.Flags().StringVarP(&genParamsOutput, "output", "o", filepath.Join("params", "params_gen.go"), "Output file")
+	genParamsCmd.Flags().StringSliceVar(&genParamsTypes, "type", nil, "Parameter type override, e.g. --type slug=string")
+	genCmd.AddCommand(genParamsCmd)
+}
+
+func runGenParams(cmd *cobra.Command, args []string) {
+	overrides, err := parseParamTypes(genParamsTypes)
+	if err != nil {
+		fmt.Printf("%s%s%s\n", "\x1b[31m", err, "\x1b[0m")
+		return
+	}
+	routes, err := scanRoutes(genParamsDir)
+	if err != nil {
+		fmt.Printf("scan routes failed: %s\n", err)
+		return
+	}
+
+	pkg := filepath.Base(filepath.Dir(genParamsOutput))
+	if pkg == "." || pkg == string(filepath.Separator) {
+		pkg = "params"
+	}
+	src, err := generateParams(pkg, routes, overrides)
+	if err != nil {
+		fmt.Printf("generate params failed: %s\n", err)
+		return
+	}
+
+	if err := os.MkdirAll(filepath.Dir(genParamsOutput), 0755); err != nil {
+		fmt.Printf("create directory %s failed: %s\n", filepath.Dir(genParamsOutput), err)
+		return
+	}
+	if err := os.WriteFile(genParamsOutput, src, 0644); err != nil {
+		fmt.Printf("write %s failed: %s\n", genParamsOutput, err)
+		return
+	}
+	fmt.Printf("created: %s\n", genParamsOutput)
+}
+
+// route is a route registration found by scanRoutes.
+type route struct {
+	Method  string
+	Pattern string
+	Handler string // controller type name, empty for function handlers
+}
+
+var routeMethods = map[string]string{
+	"GET": "GET", "POST": "POST", "PUT": "PUT", "DELETE": "DELETE",
+	"PATCH": "PATCH", "HEAD": "HEAD", "OPTIONS": "OPTIONS", "Any": "ANY",
+}
+
+// scanRoutes parses the non-test Go files under dir and returns every
+// X.GET("literal", handler)-style registration, resolving Group prefixes
+// assigned to local variables.
+func scanRoutes(dir string) ([]route, error) {
+	var routes []route
+	fset := token.NewFileSet()
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			name := d.Name()
+			if path != dir && (strings.HasPrefix(name, ".") || name == "vendor" || name == "testdata") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+			return nil
+		}
+		file, err := parser.ParseFile(fset, path, nil, parser.SkipObjectResolution)
+		if err != nil {
+			return err
+		}
+		routes = append(routes, routesInFile(file)...)
+		return nil
+	})
+	return routes, err
+}
+
+func routesInFile(file *ast.File) []route {
+	prefixes := map[string]string{}
+	var routes []route
+
+	var prefixOf func(expr ast.Expr) string
+	prefixOf = func(expr ast.Expr) string {
+		switch e := expr.(type) {
+		case *ast.Ident:
+			return prefixes[e.Name]
+		case *ast.CallExpr:
+			sel, ok := e.Fun.(*ast.SelectorExpr)
+			if ok && sel.Sel.Name == "Group" && len(e.Args) > 0 {
+				if lit, ok := stringLiteral(e.Args[0]); ok {
+					return prefixOf(sel.X) + lit
+				}
+			}
+		}
+		return ""
+	}
+
+	ast.Inspect(file, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.AssignStmt:
+			for i, rhs := range n.Rhs {
+				if i >= len(n.Lhs) {
+					break
+				}
+				ident, ok := n.Lhs[i].(*ast.Ident)
+				if !ok {
+					continue
+				}
+				if call, ok := rhs.(*ast.CallExpr); ok {
+					if sel, ok := call.Fun.(*ast.SelectorExpr); ok && sel.Sel.Name == "Group" {
+						prefixes[ident.Name] = prefixOf(call)
+					}
+				}
+			}
+		case *ast.CallExpr:
+			sel, ok := n.Fun.(*ast.SelectorExpr)
+			if !ok || len(n.Args) < 2 {
+				return true
+			}
+			method, ok := routeMethods[sel.Sel.Name]
+			if !ok {
+				return true
+			}
+			pattern, ok := stringLiteral(n.Args[0])
+			if !ok {
+				return true
+			}
+			routes = append(routes, route{
+				Method:  method,
+				Pattern: prefixOf(sel.X) + pattern,
+				Handler: controllerTypeName(n.Args[1]),
+			})
+		}
+		return true
+	})
+	return routes
+}
+
+func stringLiteral(expr ast.Expr) (string, bool) {
+	lit, ok := expr.(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return "", false
+	}
+	s, err := strconv.Unquote(lit.Value)
+	return s, err == nil
+}
+
+// controllerTypeName returns "UserShowController" for &UserShowController{} or
+// &controller.UserShowController{}, and "" for anything else.
+func controllerTypeName(expr ast.Expr) string {
+	if u, ok := expr.(*ast.UnaryExpr); ok && u.Op == token.AND {
+		expr = u.X
+	}
+	lit, ok := expr.(*ast.CompositeLit)
+	if !ok {
+		return ""
+	}
+	switch t := lit.Type.(type) {
+	case *ast.Ident:
+		return t.Name
+	case *ast.SelectorExpr:
+		return t.Sel.Name
+	}
+	return ""
+}
+
+var pathParamPattern = regexp.MustCompile(`\{([A-Za-z_][A-Za-z0-9_]*)(\.\.\.)?\}`)
+
+type pathParam struct {
+	Name     string
+	Field    string
+	Type     string
+	Wildcard bool
+}
+
+type paramsStruct struct {
+	Name   string
+	Route  string
+	Params []pathParam
+}
+
+var paramTypes = map[string]bool{
+	"string": true, "int": true, "int64": true, "uint64": true, "float64": true, "bool": true,
+}
+
+func parseParamTypes(specs []string) (map[string]string, error) {
+	overrides := make(map[string]string, len(specs))
+	for _, spec := range specs {
+		name, typ, ok := strings.Cut(spec, "=")
+		if !ok || name == "" || !paramTypes[typ] {
+			return nil, fmt.Errorf("invalid --type %q, want name=int|int64|uint64|float64|bool|string", spec)
+		}
+		overrides[name] = typ
+	}
+	return overrides, nil
+}
+
+func inferParamType(name string, overrides map[string]string) string {
+	if typ, ok := overrides[name]; ok {
+		return typ
+	}
+	if strings.EqualFold(name, "id") || strings.HasSuffix(name, "_id") ||
+		strings.HasSuffix(name, "ID") || strings.HasSuffix(name, "Id") {
+		return "int64"
+	}
+	return "string"
+}
+
+// goFieldName converts a path parameter name to an exported Go identifier,
+// upper-casing common initialisms: user_id → UserID.
+func goFieldName(name string) string {
+	parts := strings.FieldsFunc(name, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) })
+	var b strings.Builder
+	for _, p := range parts {
+		switch strings.ToLower(p) {
+		case "id", "url", "uri", "uuid", "api", "http", "ip":
+			b.WriteString(strings.ToUpper(p))
+		default:
+			b.WriteString(strings.ToUpper(p[:1]) + p[1:])
+		}
+	}
+	return b.String()
+}
+
+// structName names the params struct after the controller (UserShowController
+// → UserShowParams) or, for function handlers, after method and static path
+// segments (GET /users/{id}/posts → GetUsersPostsParams).
+func structName(r route) string {
+	if r.Handler != "" {
+		return strings.TrimSuffix(r.Handler, "Controller") + "Params"
+	}
+	var b strings.Builder
+	b.WriteString(goFieldName(strings.ToLower(r.Method)))
+	for _, seg := range strings.Split(r.Pattern, "/") {
+		if seg == "" || strings.HasPrefix(seg, "{") {
+			continue
+		}
+		b.WriteString(goFieldName(seg))
+	}
+	return b.String() + "Params"
+}
+
+func buildParamsStructs(routes []route, overrides map[string]string) []paramsStruct {
+	seen := map[string]paramsStruct{}
+	var structs []paramsStruct
+	for _, r := range routes {
+		matches := pathParamPattern.FindAllStringSubmatch(r.Pattern, -1)
+		if len(matches) == 0 {
+			continue
+		}
+		s := paramsStruct{Name: structName(r), Route: r.Method + " " + r.Pattern}
+		for _, m := range matches {
+			s.Params = append(s.Params, pathParam{
+				Name:     m[1],
+				Field:    goFieldName(m[1]),
+				Type:     inferParamType(m[1], overrides),
+				Wildcard: m[2] != "",
+			})
+		}
+		if prev, ok := seen[s.Name]; ok {
+			if sameParams(prev.Params, s.Params) {
+				continue
+			}
+			s.Name = strings.TrimSuffix(s.Name, "Params") + goFieldName(strings.ToLower(r.Method)) + "Params"
+			if _, ok := seen[s.Name]; ok {
+				continue
+			}
+		}
+		seen[s.Name] = s
+		structs = append(structs, s)
+	}
+	sort.Slice(structs, func(i, j int) bool { return structs[i].Name < structs[j].Name })
+	return structs
+}
+
+func sameParams(a, b []pathParam) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// paramHelpers are the per-type parse helpers emitted into the generated file.
+var paramHelpers = map[string]string{
+	"string": `func pathString(r *http.Request, name string) (string, error) {
+	v := r.PathValue(name)
+	if v == "" {
+		return "", kit.ErrBadRequest("missing path parameter "+name, nil)
+	}
+	return v, nil
+}
+`,
+	"int":     parseHelper("pathInt", "int", "0", "strconv.Atoi(v)"),
+	"int64":   parseHelper("pathInt64", "int64", "0", "strconv.ParseInt(v, 10, 64)"),
+	"uint64":  parseHelper("pathUint64", "uint64", "0", "strconv.ParseUint(v, 10, 64)"),
+	"float64": parseHelper("pathFloat64", "float64", "0", "strconv.ParseFloat(v, 64)"),
+	"bool":    parseHelper("pathBool", "bool", "false", "strconv.ParseBool(v)"),
+}
+
+var paramHelperNames = map[string]string{
+	"string": "pathString", "int": "pathInt", "int64": "pathInt64",
+	"uint64": "pathUint64", "float64": "pathFloat64", "bool": "pathBool",
+}
+
+// parseHelper renders a helper that parses a path parameter of typ; zero is
+// the Go literal it returns on errors.
+func parseHelper(name, typ, zero, parse string) string {
+	return fmt.Sprintf(`func %s(r *http.Request, name string) (%s, error) {
+	v, err := pathString(r, name)
+	if err != nil {
+		return %s, err
+	}
+	n, err := %s
+	if err != nil {
+		return n, kit.ErrBadRequest("invalid path parameter "+name, err)
+	}
+	return n, nil
+}
+`, name, typ, zero, parse)
+}
+
+// generateParams renders the gofmt-ed source of the params package.
+func generateParams(pkg string, routes []route, overrides map[string]string) ([]byte, error) {
+	structs := buildParamsStructs(routes, overrides)
+
+	used := map[string]bool{}
+	for _, s := range structs {
+		for _, p := range s.Params {
+			if p.Wildcard && p.Type == "string" {
+				continue
+			}
+			used[p.Type] = true
+		}
+	}
+	if len(used) > 0 {
+		used["string"] = true
+	}
+
+	var b bytes.Buffer
+	b.WriteString("// Code generated by glk gen params. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&b, "package %s\n\n", pkg)
+	if len(structs) > 0 {
+		b.WriteString("import (\n\t\"net/http\"\n")
+		if len(used) > 1 {
+			b.WriteString("\t\"strconv\"\n")
+		}
+		if len(used) > 0 {
+			b.WriteString("\n\tkit \"github.com/hansir-hsj/GoLiteKit\"\n")
+		}
+		b.WriteString(")\n\n")
+	}
+
+	for _, s := range structs {
+		fmt.Fprintf(&b, "// %s holds the path parameters of %s.\n", s.Name, s.Route)
+		fmt.Fprintf(&b, "type %s struct {\n", s.Name)
+		for _, p := range s.Params {
+			fmt.Fprintf(&b, "\t%s %s\n", p.Field, p.Type)
+		}
+		b.WriteString("}\n\n")
+
+		fmt.Fprintf(&b, "// Parse%s parses and validates the path parameters of %s.\n", s.Name, s.Route)
+		fmt.Fprintf(&b, "func Parse%s(r *http.Request) (%s, error) {\n", s.Name, s.Name)
+		fmt.Fprintf(&b, "\tvar p %s\n", s.Name)
+		declared := false
+		for _, p := range s.Params {
+			if p.Wildcard && p.Type == "string" {
+				fmt.Fprintf(&b, "\tp.%s = r.PathValue(%q)\n", p.Field, p.Name)
+				continue
+			}
+			if !declared {
+				b.WriteString("\tvar err error\n")
+				declared = true
+			}
+			fmt.Fprintf(&b, "\tif p.%s, err = %s(r, %q); err != nil {\n\t\treturn p, err\n\t}\n",
+				p.Field, paramHelperNames[p.Type], p.Name)
+		}
+		b.WriteString("\treturn p, nil\n}\n\n")
+	}
+
+	types := make([]string, 0, len(used))
+	for typ := range used {
+		types = append(types, typ)
+	}
+	sort.Strings(types)
+	for _, typ := range types {
+		b.WriteString(paramHelpers[typ])
+		b.WriteString("\n")
+	}
+	return format.Source(b.Bytes())
+}
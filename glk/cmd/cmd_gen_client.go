@@ -0,0 +1,621 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/spf13/cobra"
+)
+
+const (
+	defaultClientTimeout = 10 * time.Second
+	// clientTimeoutMargin lets the server's own write timeout fire first so
+	// callers receive its error response instead of a client-side abort.
+	clientTimeoutMargin = time.Second
+)
+
+var (
+	genClientDir     string
+	genClientLang    string
+	genClientOutput  string
+	genClientConfig  string
+	genClientTimeout time.Duration
+	genClientRetries int
+	genClientTypes   []string
+)
+
+var genClientCmd = &cobra.Command{
+	Use:   "client",
+	Short: "Generate a typed HTTP client for the registered routes",
+	Long: `Scan route registrations the same way as "glk gen params" and generate a
+client with one method per route. Path parameters become typed arguments;
+request and response bodies are passed as JSON-encodable values.
+
+The client forwards the X-Log-Id header, retries idempotent requests on
+transport errors, 429, 502, 503 and 504 (honouring Retry-After), and uses
+the server's writeTimeout from --config plus one second as its default
+timeout.
+
+Example:
+  glk gen client --lang go
+  → creates client/client_gen.go with
+    func (c *Client) UserShow(ctx context.Context, id int64, out any) error
+  glk gen client --lang ts -o web/api.gen.ts`,
+	Run: runGenClient,
+}
+
+func init() {
+	genClientCmd.Flags().StringVar(&genClientDir, "dir", ".", "Project directory to scan for routes")
+	genClientCmd.Flags().StringVar(&genClientLang, "lang", "go", "Client language: go or ts")
+	genClientCmd.Flags().StringVarP(&genClientOutput, "output", "o", "", "Output file (default client/client_gen.go or client/client.gen.ts)")
+	genClientCmd.Flags().StringVar(&genClientConfig, "config", filepath.Join("conf", "app.toml"), "Server config used to derive the default timeout, relative to --dir")
+	genClientCmd.Flags().DurationVar(&genClientTimeout, "timeout", 0, "Request timeout (default: server writeTimeout + 1s, or 10s)")
+	genClientCmd.Flags().IntVar(&genClientRetries, "retries", 2, "Retries for idempotent requests")
+	genClientCmd.Flags().StringSliceVar(&genClientTypes, "type", nil, "Parameter type override, e.g. --type slug=string")
+	genCmd.AddCommand(genClientCmd)
+}
+
+func runGenClient(cmd *cobra.Command, args []string) {
+	if genClientLang != "go" && genClientLang != "ts" {
+		fmt.Printf("%sunsupported --lang %q, want go or ts%s\n", "\x1b[31m", genClientLang, "\x1b[0m")
+		return
+	}
+	overrides, err := parseParamTypes(genClientTypes)
+	if err != nil {
+		fmt.Printf("%s%s%s\n", "\x1b[31m", err, "\x1b[0m")
+		return
+	}
+	routes, err := scanRoutes(genClientDir)
+	if err != nil {
+		fmt.Printf("scan routes failed: %s\n", err)
+		return
+	}
+
+	opts := clientOptions{Timeout: genClientTimeout, Retries: genClientRetries}
+	if opts.Timeout <= 0 {
+		opts.Timeout = serverClientTimeout(filepath.Join(genClientDir, genClientConfig))
+	}
+
+	output := genClientOutput
+	var src []byte
+	switch genClientLang {
+	case "go":
+		if output == "" {
+			output = filepath.Join("client", "client_gen.go")
+		}
+		pkg := filepath.Base(filepath.Dir(output))
+		if pkg == "." || pkg == string(filepath.Separator) {
+			pkg = "client"
+		}
+		src, err = generateGoClient(pkg, routes, overrides, opts)
+	case "ts":
+		if output == "" {
+			output = filepath.Join("client", "client.gen.ts")
+		}
+		src, err = generateTSClient(routes, overrides, opts)
+	}
+	if err != nil {
+		fmt.Printf("generate client failed: %s\n", err)
+		return
+	}
+
+	if err := os.MkdirAll(filepath.Dir(output), 0755); err != nil {
+		fmt.Printf("create directory %s failed: %s\n", filepath.Dir(output), err)
+		return
+	}
+	if err := os.WriteFile(output, src, 0644); err != nil {
+		fmt.Printf("write %s failed: %s\n", output, err)
+		return
+	}
+	fmt.Printf("created: %s\n", output)
+}
+
+type clientOptions struct {
+	Timeout time.Duration
+	Retries int
+}
+
+// serverClientTimeout derives the client timeout from the server's
+// [HttpServer.Timeout] writeTimeout (milliseconds), falling back to
+// defaultClientTimeout when the config is missing or has no write timeout.
+func serverClientTimeout(path string) time.Duration {
+	var conf struct {
+		HttpServer struct {
+			Timeout struct {
+				WriteTimeout int `toml:"writeTimeout"`
+			} `toml:"Timeout"`
+		} `toml:"HttpServer"`
+	}
+	if _, err := toml.DecodeFile(path, &conf); err != nil || conf.HttpServer.Timeout.WriteTimeout <= 0 {
+		return defaultClientTimeout
+	}
+	return time.Duration(conf.HttpServer.Timeout.WriteTimeout)*time.Millisecond + clientTimeoutMargin
+}
+
+// clientMethod is one generated client call.
+type clientMethod struct {
+	Name    string
+	Method  string // HTTP method, "" for Any routes (the caller passes it)
+	Pattern string
+	Params  []pathParam
+	HasBody bool
+}
+
+// buildClientMethods maps routes to uniquely named client methods sorted by name.
+func buildClientMethods(routes []route, overrides map[string]string) []clientMethod {
+	used := map[string]bool{}
+	seenRoute := map[string]bool{}
+	var methods []clientMethod
+	for _, r := range routes {
+		key := r.Method + " " + r.Pattern
+		if seenRoute[key] {
+			continue
+		}
+		seenRoute[key] = true
+
+		m := clientMethod{Method: r.Method, Pattern: r.Pattern}
+		if r.Method == "ANY" {
+			m.Method = ""
+		}
+		switch r.Method {
+		case "POST", "PUT", "PATCH", "ANY":
+			m.HasBody = true
+		}
+		for _, match := range pathParamPattern.FindAllStringSubmatch(r.Pattern, -1) {
+			m.Params = append(m.Params, pathParam{
+				Name:     match[1],
+				Field:    goFieldName(match[1]),
+				Type:     inferParamType(match[1], overrides),
+				Wildcard: match[2] != "",
+			})
+		}
+
+		name := strings.TrimSuffix(structName(r), "Params")
+		if r.Handler == "" && name == goFieldName(strings.ToLower(r.Method)) {
+			name += "Root"
+		}
+		if used[name] {
+			name += goFieldName(strings.ToLower(r.Method))
+		}
+		for i := 2; used[name]; i++ {
+			name = fmt.Sprintf("%s%d", strings.TrimRight(name, "0123456789"), i)
+		}
+		used[name] = true
+		m.Name = name
+		methods = append(methods, m)
+	}
+	sort.Slice(methods, func(i, j int) bool { return methods[i].Name < methods[j].Name })
+	return methods
+}
+
+// paramArg is the lower-camel argument name of a path parameter.
+func paramArg(p pathParam) string {
+	arg := strings.ToLower(p.Field[:1]) + p.Field[1:]
+	if strings.ToUpper(p.Field) == p.Field {
+		arg = strings.ToLower(p.Field)
+	}
+	switch arg {
+	case "ctx", "method", "body", "out", "c", "type", "func", "default", "var", "range", "map", "string":
+		arg += "Param"
+	}
+	return arg
+}
+
+// generateGoClient renders the gofmt-ed source of the Go client package.
+func generateGoClient(pkg string, routes []route, overrides map[string]string, opts clientOptions) ([]byte, error) {
+	methods := buildClientMethods(routes, overrides)
+
+	var b bytes.Buffer
+	b.WriteString("// Code generated by glk gen client. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&b, "package %s\n\n", pkg)
+	fmt.Fprintf(&b, goClientRuntime, int64(opts.Timeout/time.Millisecond), opts.Retries)
+
+	for _, m := range methods {
+		var args []string
+		args = append(args, "ctx context.Context")
+		if m.Method == "" {
+			args = append(args, "method string")
+		}
+		for _, p := range m.Params {
+			args = append(args, paramArg(p)+" "+p.Type)
+		}
+		if m.HasBody {
+			args = append(args, "body any")
+		}
+		args = append(args, "out any")
+
+		verb := m.Method
+		if verb == "" {
+			verb = "ANY"
+		}
+		fmt.Fprintf(&b, "// %s calls %s %s.\n", m.Name, verb, m.Pattern)
+		fmt.Fprintf(&b, "func (c *Client) %s(%s) error {\n", m.Name, strings.Join(args, ", "))
+
+		path := m.Pattern
+		var pathArgs []string
+		for _, p := range m.Params {
+			placeholder := "{" + p.Name + "}"
+			if p.Wildcard {
+				placeholder = "{" + p.Name + "...}"
+			}
+			path = strings.Replace(path, placeholder, "%s", 1)
+			escape := "url.PathEscape"
+			if p.Wildcard {
+				escape = "escapeWildcard"
+			}
+			pathArgs = append(pathArgs, fmt.Sprintf("%s(fmt.Sprint(%s))", escape, paramArg(p)))
+		}
+		pathExpr := fmt.Sprintf("%q", path)
+		if len(pathArgs) > 0 {
+			pathExpr = fmt.Sprintf("fmt.Sprintf(%q, %s)", path, strings.Join(pathArgs, ", "))
+		}
+		methodExpr := fmt.Sprintf("%q", m.Method)
+		if m.Method == "" {
+			methodExpr = "method"
+		}
+		bodyExpr := "nil"
+		if m.HasBody {
+			bodyExpr = "body"
+		}
+		fmt.Fprintf(&b, "\treturn c.Do(ctx, %s, %s, %s, out)\n}\n\n", methodExpr, pathExpr, bodyExpr)
+	}
+	return format.Source(b.Bytes())
+}
+
+const goClientRuntime = `import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LogIDHeader carries the log ID to the server, which adopts it for its logs.
+const LogIDHeader = "X-Log-Id"
+
+const (
+	DefaultTimeout = %d * time.Millisecond
+	DefaultRetries = %d
+)
+
+// Client calls the service's routes.
+type Client struct {
+	BaseURL    string
+	HTTPClient *http.Client
+	// Retries is the number of retries for idempotent requests (GET, HEAD,
+	// PUT, DELETE, OPTIONS) on transport errors, 429, 502, 503 and 504.
+	Retries int
+	// Backoff is the base delay between retries, doubled on each attempt.
+	Backoff time.Duration
+	// LogID returns the log ID to forward when ctx carries none set by
+	// WithLogID, e.g. golitekit.EnsureLogID inside a GoLiteKit handler.
+	LogID func(ctx context.Context) string
+}
+
+// New creates a Client with the generated default timeout and retries.
+func New(baseURL string) *Client {
+	return &Client{
+		BaseURL:    strings.TrimRight(baseURL, "/"),
+		HTTPClient: &http.Client{Timeout: DefaultTimeout},
+		Retries:    DefaultRetries,
+		Backoff:    100 * time.Millisecond,
+	}
+}
+
+type logIDKey struct{}
+
+// WithLogID returns a context whose requests carry logID in LogIDHeader.
+func WithLogID(ctx context.Context, logID string) context.Context {
+	return context.WithValue(ctx, logIDKey{}, logID)
+}
+
+// Response is the service's JSON envelope.
+type Response struct {
+	Status int             ` + "`json:\"status\"`" + `
+	Msg    string          ` + "`json:\"msg\"`" + `
+	Data   json.RawMessage ` + "`json:\"data,omitempty\"`" + `
+	LogID  string          ` + "`json:\"logid,omitempty\"`" + `
+}
+
+// Error is returned for non-2xx responses.
+type Error struct {
+	StatusCode int
+	Msg        string
+	LogID      string
+	Body       []byte
+}
+
+func (e *Error) Error() string {
+	if e.LogID != "" {
+		return fmt.Sprintf("%%d %%s (logid %%s)", e.StatusCode, e.Msg, e.LogID)
+	}
+	return fmt.Sprintf("%%d %%s", e.StatusCode, e.Msg)
+}
+
+// Do sends a request with a JSON body (when body is non-nil) and decodes a
+// 2xx JSON response into out (when out is non-nil).
+func (c *Client) Do(ctx context.Context, method, path string, body, out any) error {
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return err
+		}
+	}
+	logID, _ := ctx.Value(logIDKey{}).(string)
+	if logID == "" && c.LogID != nil {
+		logID = c.LogID(ctx)
+	}
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	attempts := 1
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete, http.MethodOptions:
+		attempts += c.Retries
+	}
+	backoff := c.Backoff
+	for attempt := 1; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, method, c.BaseURL+path, bytes.NewReader(payload))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Accept", "application/json")
+		if payload != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		if logID != "" {
+			req.Header.Set(LogIDHeader, logID)
+		}
+
+		resp, err := httpClient.Do(req)
+		var wait time.Duration
+		if err == nil {
+			if attempt < attempts && retryableStatus(resp.StatusCode) {
+				wait = retryAfter(resp.Header.Get("Retry-After"))
+				_, _ = io.Copy(io.Discard, resp.Body)
+				resp.Body.Close()
+			} else {
+				return decodeResponse(resp, out)
+			}
+		} else if attempt >= attempts || ctx.Err() != nil {
+			return err
+		}
+
+		if wait <= 0 {
+			wait = backoff
+			backoff *= 2
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+func retryableStatus(code int) bool {
+	switch code {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+func retryAfter(v string) time.Duration {
+	if secs, err := strconv.Atoi(v); err == nil && secs > 0 {
+		return time.Duration(secs) * time.Second
+	}
+	return 0
+}
+
+func decodeResponse(resp *http.Response, out any) error {
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		e := &Error{StatusCode: resp.StatusCode, Msg: http.StatusText(resp.StatusCode), LogID: resp.Header.Get(LogIDHeader), Body: data}
+		var envelope Response
+		if json.Unmarshal(data, &envelope) == nil && envelope.Msg != "" {
+			e.Msg = envelope.Msg
+			if envelope.LogID != "" {
+				e.LogID = envelope.LogID
+			}
+		}
+		return e
+	}
+	if out == nil || len(data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(data, out)
+}
+
+func escapeWildcard(p string) string {
+	segments := strings.Split(p, "/")
+	for i, s := range segments {
+		segments[i] = url.PathEscape(s)
+	}
+	return strings.Join(segments, "/")
+}
+
+`
+
+// tsParamTypes maps generated Go parameter types to TypeScript.
+var tsParamTypes = map[string]string{
+	"string": "string", "int": "number", "int64": "number", "uint64": "number",
+	"float64": "number", "bool": "boolean",
+}
+
+// generateTSClient renders a fetch-based TypeScript client.
+func generateTSClient(routes []route, overrides map[string]string, opts clientOptions) ([]byte, error) {
+	methods := buildClientMethods(routes, overrides)
+
+	var b bytes.Buffer
+	b.WriteString("// Code generated by glk gen client. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&b, tsClientRuntime, int64(opts.Timeout/time.Millisecond), opts.Retries)
+
+	for _, m := range methods {
+		var args []string
+		if m.Method == "" {
+			args = append(args, "method: string")
+		}
+		for _, p := range m.Params {
+			args = append(args, paramArg(p)+": "+tsParamTypes[p.Type])
+		}
+		if m.HasBody {
+			args = append(args, "body?: unknown")
+		}
+		args = append(args, "opts: CallOptions = {}")
+
+		path := m.Pattern
+		for _, p := range m.Params {
+			placeholder := "{" + p.Name + "}"
+			expr := "${encodeURIComponent(String(" + paramArg(p) + "))}"
+			if p.Wildcard {
+				placeholder = "{" + p.Name + "...}"
+				expr = "${String(" + paramArg(p) + ").split(\"/\").map(encodeURIComponent).join(\"/\")}"
+			}
+			path = strings.Replace(path, placeholder, expr, 1)
+		}
+		methodExpr := fmt.Sprintf("%q", m.Method)
+		verb := m.Method
+		if m.Method == "" {
+			methodExpr = "method"
+			verb = "ANY"
+		}
+		bodyExpr := "undefined"
+		if m.HasBody {
+			bodyExpr = "body"
+		}
+		name := strings.ToLower(m.Name[:1]) + m.Name[1:]
+		fmt.Fprintf(&b, "  /** %s %s */\n", verb, m.Pattern)
+		fmt.Fprintf(&b, "  %s<T = unknown>(%s): Promise<T> {\n", name, strings.Join(args, ", "))
+		fmt.Fprintf(&b, "    return this.request<T>(%s, `%s`, %s, opts);\n  }\n\n", methodExpr, path, bodyExpr)
+	}
+	b.WriteString("}\n")
+	return b.Bytes(), nil
+}
+
+const tsClientRuntime = `export const LOG_ID_HEADER = "X-Log-Id";
+export const DEFAULT_TIMEOUT_MS = %d;
+export const DEFAULT_RETRIES = %d;
+
+export interface ClientOptions {
+  timeoutMs?: number;
+  /** Retries for idempotent requests on network errors, 429, 502, 503 and 504. */
+  retries?: number;
+  backoffMs?: number;
+  fetch?: typeof fetch;
+}
+
+export interface CallOptions {
+  /** Forwarded in X-Log-Id so server logs correlate with the caller. */
+  logId?: string;
+  signal?: AbortSignal;
+}
+
+export class ApiError extends Error {
+  constructor(
+    public readonly status: number,
+    message: string,
+    public readonly logId?: string,
+    public readonly body?: unknown,
+  ) {
+    super(logId ? ` + "`${status} ${message} (logid ${logId})`" + ` : ` + "`${status} ${message}`" + `);
+  }
+}
+
+async function decode<T>(resp: Response): Promise<T> {
+  const text = await resp.text();
+  let data: unknown = undefined;
+  if (text) {
+    try {
+      data = JSON.parse(text);
+    } catch {
+      data = text;
+    }
+  }
+  if (!resp.ok) {
+    const envelope = (data ?? {}) as { msg?: string; logid?: string };
+    throw new ApiError(
+      resp.status,
+      envelope.msg || resp.statusText,
+      envelope.logid || resp.headers.get(LOG_ID_HEADER) || undefined,
+      data,
+    );
+  }
+  return data as T;
+}
+
+const IDEMPOTENT = new Set(["GET", "HEAD", "PUT", "DELETE", "OPTIONS"]);
+const RETRYABLE = new Set([429, 502, 503, 504]);
+
+export class Client {
+  private readonly baseUrl: string;
+  private readonly timeoutMs: number;
+  private readonly retries: number;
+  private readonly backoffMs: number;
+  private readonly fetchImpl: typeof fetch;
+
+  constructor(baseUrl: string, options: ClientOptions = {}) {
+    this.baseUrl = baseUrl.replace(/\/+$/, "");
+    this.timeoutMs = options.timeoutMs ?? DEFAULT_TIMEOUT_MS;
+    this.retries = options.retries ?? DEFAULT_RETRIES;
+    this.backoffMs = options.backoffMs ?? 100;
+    this.fetchImpl = options.fetch ?? fetch;
+  }
+
+  async request<T>(method: string, path: string, body: unknown, opts: CallOptions = {}): Promise<T> {
+    const attempts = 1 + (IDEMPOTENT.has(method) ? this.retries : 0);
+    let backoff = this.backoffMs;
+    for (let attempt = 1; ; attempt++) {
+      const controller = new AbortController();
+      const timer = setTimeout(() => controller.abort(), this.timeoutMs);
+      opts.signal?.addEventListener("abort", () => controller.abort(), { once: true });
+      const headers: Record<string, string> = { Accept: "application/json" };
+      if (body !== undefined) headers["Content-Type"] = "application/json";
+      if (opts.logId) headers[LOG_ID_HEADER] = opts.logId;
+
+      let wait = 0;
+      try {
+        const resp = await this.fetchImpl(this.baseUrl + path, {
+          method,
+          headers,
+          body: body === undefined ? undefined : JSON.stringify(body),
+          signal: controller.signal,
+        });
+        if (attempt < attempts && RETRYABLE.has(resp.status)) {
+          wait = Number(resp.headers.get("Retry-After") ?? 0) * 1000;
+        } else {
+          return await decode<T>(resp);
+        }
+      } catch (err) {
+        if (err instanceof ApiError || attempt >= attempts || opts.signal?.aborted) throw err;
+      } finally {
+        clearTimeout(timer);
+      }
+      if (!(wait > 0)) {
+        wait = backoff;
+        backoff *= 2;
+      }
+      await new Promise((resolve) => setTimeout(resolve, wait));
+    }
+  }
+
+`
@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const clientRoutesSrc = `package generated
+
+import kit "github.com/hansir-hsj/GoLiteKit"
+
+type UserShowController struct{ kit.BaseController }
+
+func routes(app *kit.App) {
+	app.GET("/users/{id}", &UserShowController{})
+	api := app.Group("/api")
+	api.POST("/orgs/{org}/members", func(ctx *kit.Context) error { return nil })
+	app.GET("/files/{path...}", func(ctx *kit.Context) error { return nil })
+}
+`
+
+func TestGenClient_GoClientCompilesAndCallsServer(t *testing.T) {
+	dir := t.TempDir()
+	writeTempModule(t, dir)
+	if err := os.WriteFile(filepath.Join(dir, "routes.go"), []byte(clientRoutesSrc), 0644); err != nil {
+		t.Fatalf("write routes: %v", err)
+	}
+
+	routes, err := scanRoutes(dir)
+	if err != nil {
+		t.Fatalf("scanRoutes: %v", err)
+	}
+	src, err := generateGoClient("client", routes, nil, clientOptions{Timeout: 3 * time.Second, Retries: 2})
+	if err != nil {
+		t.Fatalf("generateGoClient: %v", err)
+	}
+	for _, want := range []string{
+		"DefaultTimeout = 3000 * time.Millisecond",
+		"func (c *Client) UserShow(ctx context.Context, id int64, out any) error",
+		"func (c *Client) PostAPIOrgsMembers(ctx context.Context, org string, body any, out any) error",
+		"func (c *Client) GetFiles(ctx context.Context, path string, out any) error",
+	} {
+		if !strings.Contains(string(src), want) {
+			t.Fatalf("generated client missing %q:\n%s", want, src)
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Join(dir, "client"), 0755); err != nil {
+		t.Fatalf("mkdir client: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "client", "client_gen.go"), src, 0644); err != nil {
+		t.Fatalf("write client: %v", err)
+	}
+
+	usage := `package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGeneratedClient(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if r.URL.EscapedPath() != "/users/42" || r.Header.Get(LogIDHeader) != "trace-1" {
+			t.Errorf("path = %s logid = %q", r.URL.EscapedPath(), r.Header.Get(LogIDHeader))
+		}
+		json.NewEncoder(w).Encode(map[string]any{"status": 200, "msg": "ok", "data": map[string]any{"name": "alice"}})
+	}))
+	defer srv.Close()
+
+	c := New(srv.URL)
+	c.Backoff = 0
+	var out Response
+	if err := c.UserShow(WithLogID(context.Background(), "trace-1"), 42, &out); err != nil {
+		t.Fatalf("UserShow: %v", err)
+	}
+	if calls != 2 || string(out.Data) != ` + "`" + `{"name":"alice"}` + "`" + ` {
+		t.Fatalf("calls = %d data = %s", calls, out.Data)
+	}
+
+	calls = 0
+	err := c.PostAPIOrgsMembers(context.Background(), "acme", map[string]string{"user": "bob"}, nil)
+	if apiErr, ok := err.(*Error); !ok || apiErr.StatusCode != http.StatusServiceUnavailable || calls != 1 {
+		t.Fatalf("POST must not be retried: err = %v calls = %d", err, calls)
+	}
+}
+`
+	if err := os.WriteFile(filepath.Join(dir, "client", "client_usage_test.go"), []byte(usage), 0644); err != nil {
+		t.Fatalf("write usage test: %v", err)
+	}
+	runGoTest(t, dir)
+}
+
+func TestGenClient_TypeScript(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "routes.go"), []byte(clientRoutesSrc), 0644); err != nil {
+		t.Fatalf("write routes: %v", err)
+	}
+	routes, err := scanRoutes(dir)
+	if err != nil {
+		t.Fatalf("scanRoutes: %v", err)
+	}
+	src, err := generateTSClient(routes, nil, clientOptions{Timeout: 16 * time.Second, Retries: 1})
+	if err != nil {
+		t.Fatalf("generateTSClient: %v", err)
+	}
+	for _, want := range []string{
+		"export const DEFAULT_TIMEOUT_MS = 16000;",
+		"userShow<T = unknown>(id: number, opts: CallOptions = {}): Promise<T>",
+		"return this.request<T>(\"GET\", `/users/${encodeURIComponent(String(id))}`, undefined, opts);",
+		"postAPIOrgsMembers<T = unknown>(org: string, body?: unknown, opts: CallOptions = {})",
+	} {
+		if !strings.Contains(string(src), want) {
+			t.Fatalf("generated client missing %q:\n%s", want, src)
+		}
+	}
+}
+
+func TestServerClientTimeout(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.toml")
+	if err := os.WriteFile(path, []byte("[HttpServer.Timeout]\nwriteTimeout = 15000\n"), 0644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	if got := serverClientTimeout(path); got != 16*time.Second {
+		t.Fatalf("timeout = %v, want 16s", got)
+	}
+	if got := serverClientTimeout(filepath.Join(t.TempDir(), "missing.toml")); got != defaultClientTimeout {
+		t.Fatalf("missing config timeout = %v, want default", got)
+	}
+}
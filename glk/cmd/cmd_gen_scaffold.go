@@ -0,0 +1,247 @@
+package cmd
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"go/format"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/spf13/cobra"
+)
+
+//go:embed tpl_gen
+var tplGen embed.FS
+
+// DefaultTemplateDir is where a project overrides the scaffolding templates:
+// a file named like one in tpl_gen (controller.go.tpl, model_test.go.tpl, ...)
+// replaces the built-in one.
+const DefaultTemplateDir = ".glk/templates"
+
+var (
+	genScaffoldTemplates string
+	genControllerRest    bool
+	genControllerRoute   string
+	genModelTable        string
+)
+
+var genControllerCmd = &cobra.Command{
+	Use:   "controller <Name>",
+	Short: "Generate a controller, its test and a route snippet",
+	Long: `Generate controller/<name>_controller.go and a table-driven test.
+With --rest the controller embeds RestControllerOf[<Name>Request], so the
+JSON body is parsed and validated before Serve runs.
+
+Example:
+  glk gen controller User --rest
+  → creates controller/user_controller.go with UserController and
+    UserRequest, controller/user_controller_test.go, and prints
+    app.POST("/user", &controller.UserController{})`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		reportScaffold(genController(".", args[0], genControllerRest, genControllerRoute, genScaffoldTemplates))
+	},
+}
+
+var genMiddlewareCmd = &cobra.Command{
+	Use:   "middleware <Name>",
+	Short: "Generate a middleware and its test",
+	Long: `Generate middleware/<name>_middleware.go and a table-driven test.
+
+Example:
+  glk gen middleware Auth
+  → creates middleware/auth_middleware.go with AuthMiddleware`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		reportScaffold(genMiddleware(".", args[0], genScaffoldTemplates))
+	},
+}
+
+var genModelCmd = &cobra.Command{
+	Use:   "model <Name>",
+	Short: "Generate a GORM model and its test",
+	Long: `Generate model/<name>.go with a GORM model mapped to --table
+(default: the plural snake_case name).
+
+Example:
+  glk gen model Order --table orders
+  → creates model/order.go with Order and TableName() "orders"`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		reportScaffold(genModel(".", args[0], genModelTable, genScaffoldTemplates))
+	},
+}
+
+func init() {
+	for _, c := range []*cobra.Command{genControllerCmd, genMiddlewareCmd, genModelCmd} {
+		c.Flags().StringVar(&genScaffoldTemplates, "templates", DefaultTemplateDir, "Directory of project template overrides")
+		genCmd.AddCommand(c)
+	}
+	genControllerCmd.Flags().BoolVar(&genControllerRest, "rest", false, "Generate a RestControllerOf controller with a typed JSON body")
+	genControllerCmd.Flags().StringVar(&genControllerRoute, "route", "", "Route pattern (default /<name>)")
+	genModelCmd.Flags().StringVar(&genModelTable, "table", "", "Table name (default: plural snake_case name)")
+}
+
+// scaffoldResult lists the files written and what to print afterwards.
+type scaffoldResult struct {
+	files   []string
+	snippet string
+}
+
+func reportScaffold(res scaffoldResult, err error) {
+	for _, f := range res.files {
+		fmt.Printf("created: %s\n", f)
+	}
+	if err != nil {
+		fmt.Printf("%s%s%s\n", "\x1b[31m", err, "\x1b[0m")
+		return
+	}
+	if res.snippet != "" {
+		fmt.Printf("register the route:\n\t%s\n", res.snippet)
+	}
+}
+
+func genController(root, name string, rest bool, route, tplDir string) (scaffoldResult, error) {
+	camel, snake := scaffoldNames(name)
+	if route == "" {
+		route = "/" + snake
+	}
+	method, methodTitle, tpl := "GET", "Get", "controller.go.tpl"
+	if rest {
+		method, methodTitle, tpl = "POST", "Post", "controller_rest.go.tpl"
+	}
+	data := map[string]any{
+		"Name":        camel,
+		"Route":       route,
+		"Method":      method,
+		"MethodTitle": methodTitle,
+		"Rest":        rest,
+	}
+	dir := filepath.Join(root, "controller")
+	res, err := writeScaffold(tplDir, data, []scaffoldFile{
+		{tpl, filepath.Join(dir, snake+"_controller.go")},
+		{"controller_test.go.tpl", filepath.Join(dir, snake+"_controller_test.go")},
+	})
+	if err == nil {
+		res.snippet = fmt.Sprintf("app.%s(%q, &controller.%sController{})", method, route, camel)
+	}
+	return res, err
+}
+
+func genMiddleware(root, name, tplDir string) (scaffoldResult, error) {
+	camel, snake := scaffoldNames(name)
+	dir := filepath.Join(root, "middleware")
+	res, err := writeScaffold(tplDir, map[string]any{"Name": camel}, []scaffoldFile{
+		{"middleware.go.tpl", filepath.Join(dir, snake+"_middleware.go")},
+		{"middleware_test.go.tpl", filepath.Join(dir, snake+"_middleware_test.go")},
+	})
+	if err == nil {
+		res.snippet = fmt.Sprintf("app.Use(middleware.%sMiddleware)", camel)
+	}
+	return res, err
+}
+
+func genModel(root, name, table, tplDir string) (scaffoldResult, error) {
+	camel, snake := scaffoldNames(name)
+	if table == "" {
+		table = pluralize(snake)
+	}
+	dir := filepath.Join(root, "model")
+	return writeScaffold(tplDir, map[string]any{"Name": camel, "Table": table}, []scaffoldFile{
+		{"model.go.tpl", filepath.Join(dir, snake+".go")},
+		{"model_test.go.tpl", filepath.Join(dir, snake+"_test.go")},
+	})
+}
+
+type scaffoldFile struct {
+	tpl, out string
+}
+
+// writeScaffold renders and gofmts every file, then writes them. Nothing is
+// written when a target already exists or a template fails to render.
+func writeScaffold(tplDir string, data map[string]any, files []scaffoldFile) (scaffoldResult, error) {
+	var res scaffoldResult
+	rendered := make([][]byte, len(files))
+	for i, f := range files {
+		if _, err := os.Stat(f.out); err == nil {
+			return res, fmt.Errorf("%s already exists", f.out)
+		}
+		src, err := renderGenTemplate(tplDir, f.tpl, data)
+		if err != nil {
+			return res, err
+		}
+		rendered[i] = src
+	}
+	for i, f := range files {
+		if err := os.MkdirAll(filepath.Dir(f.out), 0755); err != nil {
+			return res, fmt.Errorf("create directory %s failed: %w", filepath.Dir(f.out), err)
+		}
+		if err := os.WriteFile(f.out, rendered[i], 0644); err != nil {
+			return res, fmt.Errorf("write %s failed: %w", f.out, err)
+		}
+		res.files = append(res.files, f.out)
+	}
+	return res, nil
+}
+
+// renderGenTemplate renders tplDir/name when the project provides it, the
+// built-in tpl_gen/name otherwise, and gofmts the result.
+func renderGenTemplate(tplDir, name string, data map[string]any) ([]byte, error) {
+	content, err := os.ReadFile(filepath.Join(tplDir, name))
+	if errors.Is(err, fs.ErrNotExist) || tplDir == "" {
+		content, err = tplGen.ReadFile("tpl_gen/" + name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read template %s failed: %w", name, err)
+	}
+	t, err := template.New(name).Parse(string(content))
+	if err != nil {
+		return nil, fmt.Errorf("parse template %s failed: %w", name, err)
+	}
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("render template %s failed: %w", name, err)
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("format template %s output failed: %w", name, err)
+	}
+	return src, nil
+}
+
+// scaffoldNames returns the Go identifier and file name of a component name
+// given as CamelCase, snake_case or kebab-case: "UserProfile",
+// "user_profile" and "user-profile" all give UserProfile and user_profile.
+func scaffoldNames(name string) (camel, snake string) {
+	camel = toCamelCase(name)
+	var b strings.Builder
+	runes := []rune(camel)
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			if i > 0 && (unicode.IsLower(runes[i-1]) || i+1 < len(runes) && unicode.IsLower(runes[i+1])) {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return camel, b.String()
+}
+
+// pluralize applies the English rules that cover most table names.
+func pluralize(s string) string {
+	switch {
+	case strings.HasSuffix(s, "s"), strings.HasSuffix(s, "x"), strings.HasSuffix(s, "z"),
+		strings.HasSuffix(s, "ch"), strings.HasSuffix(s, "sh"):
+		return s + "es"
+	case strings.HasSuffix(s, "y") && len(s) > 1 && !strings.ContainsRune("aeiou", rune(s[len(s)-2])):
+		return s[:len(s)-1] + "ies"
+	}
+	return s + "s"
+}
@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenScaffold_GeneratedCodeCompilesAndPasses(t *testing.T) {
+	dir := t.TempDir()
+	writeTempModule(t, dir)
+	tplDir := filepath.Join(dir, DefaultTemplateDir)
+
+	res, err := genController(dir, "User", true, "", tplDir)
+	if err != nil {
+		t.Fatalf("genController --rest: %v", err)
+	}
+	if res.snippet != `app.POST("/user", &controller.UserController{})` {
+		t.Fatalf("snippet = %q", res.snippet)
+	}
+	if _, err := genController(dir, "health_check", false, "/healthz", tplDir); err != nil {
+		t.Fatalf("genController: %v", err)
+	}
+	if _, err := genMiddleware(dir, "Auth", tplDir); err != nil {
+		t.Fatalf("genMiddleware: %v", err)
+	}
+	if _, err := genModel(dir, "OrderItem", "", tplDir); err != nil {
+		t.Fatalf("genModel: %v", err)
+	}
+
+	src, err := os.ReadFile(filepath.Join(dir, "controller", "user_controller.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(src), "kit.RestControllerOf[UserRequest]") {
+		t.Fatalf("rest controller source:\n%s", src)
+	}
+	model, err := os.ReadFile(filepath.Join(dir, "model", "order_item.go"))
+	if err != nil || !strings.Contains(string(model), `return "order_items"`) {
+		t.Fatalf("model source (%v):\n%s", err, model)
+	}
+
+	if _, err := genMiddleware(dir, "auth", tplDir); err == nil {
+		t.Fatal("expected an error for an existing file")
+	}
+
+	runGoTest(t, dir)
+}
+
+func TestGenScaffold_ProjectTemplateOverride(t *testing.T) {
+	dir := t.TempDir()
+	tplDir := filepath.Join(dir, "tpl")
+	if err := os.MkdirAll(tplDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	custom := "package model\n\n// {{.Name}} is custom.\ntype {{.Name}} struct{}\n"
+	if err := os.WriteFile(filepath.Join(tplDir, "model.go.tpl"), []byte(custom), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := genModel(dir, "Order", "orders", tplDir); err != nil {
+		t.Fatalf("genModel: %v", err)
+	}
+	got, _ := os.ReadFile(filepath.Join(dir, "model", "order.go"))
+	if !strings.Contains(string(got), "// Order is custom.") {
+		t.Fatalf("override not used:\n%s", got)
+	}
+	test, _ := os.ReadFile(filepath.Join(dir, "model", "order_test.go"))
+	if !strings.Contains(string(test), "TestOrderTableName") {
+		t.Fatalf("built-in test template not used:\n%s", test)
+	}
+}
+
+func TestScaffoldNames(t *testing.T) {
+	cases := map[string][2]string{
+		"User":         {"User", "user"},
+		"user_profile": {"UserProfile", "user_profile"},
+		"order-item":   {"OrderItem", "order_item"},
+		"HTTPClient":   {"HTTPClient", "http_client"},
+		"UserID":       {"UserID", "user_id"},
+	}
+	for in, want := range cases {
+		camel, snake := scaffoldNames(in)
+		if camel != want[0] || snake != want[1] {
+			t.Errorf("scaffoldNames(%q) = %q, %q; want %q, %q", in, camel, snake, want[0], want[1])
+		}
+	}
+	for in, want := range map[string]string{"order": "orders", "box": "boxes", "category": "categories", "day": "days"} {
+		if got := pluralize(in); got != want {
+			t.Errorf("pluralize(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
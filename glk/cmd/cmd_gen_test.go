@@ -0,0 +1,160 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenParams_GeneratedCodeCompilesAndParses(t *testing.T) {
+	dir := t.TempDir()
+	writeTempModule(t, dir)
+
+	routesSrc := `package generated
+
+import kit "github.com/hansir-hsj/GoLiteKit"
+
+type UserShowController struct{ kit.BaseController }
+
+func routes(app *kit.App) {
+	app.GET("/users/{id}", &UserShowController{})
+	api := app.Group("/api")
+	v1 := api.Group("/v1")
+	v1.GET("/orgs/{org}/members/{member_id}", func(ctx *kit.Context) error { return nil })
+	app.GET("/files/{path...}", func(ctx *kit.Context) error { return nil })
+	app.GET("/health", func(ctx *kit.Context) error { return nil })
+}
+`
+	if err := os.WriteFile(filepath.Join(dir, "routes.go"), []byte(routesSrc), 0644); err != nil {
+		t.Fatalf("write routes: %v", err)
+	}
+
+	routes, err := scanRoutes(dir)
+	if err != nil {
+		t.Fatalf("scanRoutes: %v", err)
+	}
+	src, err := generateParams("params", routes, map[string]string{"org": "string"})
+	if err != nil {
+		t.Fatalf("generateParams: %v", err)
+	}
+	for _, want := range []string{
+		"type UserShowParams struct",
+		"ID int64",
+		"// GetAPIV1OrgsMembersParams holds the path parameters of GET /api/v1/orgs/{org}/members/{member_id}.",
+		"MemberID int64",
+		"type GetFilesParams struct",
+	} {
+		if !strings.Contains(string(src), want) {
+			t.Fatalf("generated source missing %q:\n%s", want, src)
+		}
+	}
+	if strings.Contains(string(src), "GetHealthParams") {
+		t.Fatalf("routes without parameters should be skipped:\n%s", src)
+	}
+
+	if err := os.MkdirAll(filepath.Join(dir, "params"), 0755); err != nil {
+		t.Fatalf("mkdir params: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "params", "params_gen.go"), src, 0644); err != nil {
+		t.Fatalf("write params: %v", err)
+	}
+
+	usage := `package generated
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	kit "github.com/hansir-hsj/GoLiteKit"
+	"example.com/generated/params"
+)
+
+func TestGeneratedParams(t *testing.T) {
+	r := httptest.NewRequest("GET", "/users/42", nil)
+	r.SetPathValue("id", "42")
+	p, err := params.ParseUserShowParams(r)
+	if err != nil || p.ID != 42 {
+		t.Fatalf("ParseUserShowParams = %+v, %v", p, err)
+	}
+
+	r.SetPathValue("id", "abc")
+	_, err = params.ParseUserShowParams(r)
+	if appErr, ok := err.(*kit.AppError); !ok || appErr.Code != 400 {
+		t.Fatalf("invalid id error = %v, want 400 AppError", err)
+	}
+}
+`
+	if err := os.WriteFile(filepath.Join(dir, "params_usage_test.go"), []byte(usage), 0644); err != nil {
+		t.Fatalf("write usage test: %v", err)
+	}
+
+	runGoTest(t, dir)
+}
+
+func TestGoFieldName(t *testing.T) {
+	cases := map[string]string{
+		"id":         "ID",
+		"user_id":    "UserID",
+		"org-slug":   "OrgSlug",
+		"users.json": "UsersJson",
+	}
+	for in, want := range cases {
+		if got := goFieldName(in); got != want {
+			t.Errorf("goFieldName(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestGenParams_EveryTypeCompiles(t *testing.T) {
+	dir := t.TempDir()
+	writeTempModule(t, dir)
+
+	pattern := "/typed"
+	overrides := map[string]string{}
+	for typ := range paramHelpers {
+		name := "p_" + typ
+		pattern += "/{" + name + "}"
+		overrides[name] = typ
+	}
+	src, err := generateParams("params", []route{{Method: "GET", Pattern: pattern}}, overrides)
+	if err != nil {
+		t.Fatalf("generateParams: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "params"), 0755); err != nil {
+		t.Fatalf("mkdir params: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "params", "params_gen.go"), src, 0644); err != nil {
+		t.Fatalf("write params: %v", err)
+	}
+
+	usage := `package generated
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"example.com/generated/params"
+)
+
+func TestEveryType(t *testing.T) {
+	r := httptest.NewRequest("GET", "/", nil)
+	for name, v := range map[string]string{"p_string": "a", "p_int": "-1", "p_int64": "2", "p_uint64": "3", "p_float64": "1.5", "p_bool": "true"} {
+		r.SetPathValue(name, v)
+	}
+	p, err := params.ParseGetTypedParams(r)
+	if err != nil || !p.PBool || p.PFloat64 != 1.5 {
+		t.Fatalf("ParseGetTypedParams = %+v, %v", p, err)
+	}
+	r.SetPathValue("p_bool", "maybe")
+	if _, err := params.ParseGetTypedParams(r); err == nil {
+		t.Fatal("invalid bool parsed")
+	}
+}
+`
+	if err := os.WriteFile(filepath.Join(dir, "params_usage_test.go"), []byte(usage), 0644); err != nil {
+		t.Fatalf("write usage test: %v", err)
+	}
+
+	runGoTest(t, dir)
+}
@@ -0,0 +1,271 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	glk "github.com/hansir-hsj/GoLiteKit"
+	"github.com/spf13/cobra"
+)
+
+var inferCmd = &cobra.Command{
+	Use:   "infer",
+	Short: "Infer code from runtime samples",
+	Long:  "Infer code from data sampled by a running GoLiteKit application.",
+}
+
+var (
+	inferTypesOutput  string
+	inferTypesPackage string
+	inferTypesToken   string
+)
+
+var inferTypesCmd = &cobra.Command{
+	Use:   "types <shapes.json|URL>",
+	Short: "Generate request structs from sampled JSON bodies",
+	Long: `Generate Go structs from the request body shapes recorded by a
+BodySampler, to replace map[string]any handlers with BaseControllerOf[T].
+
+The input is the output of the admin endpoint GET /_admin/shapes, either
+saved to a file or fetched directly (--token sets the bearer token).
+
+Fields missing from some samples get ",omitempty", fields that were null
+become pointers, and fields seen with incompatible types become any.
+
+Example:
+  glk infer types http://localhost:8080/_admin/shapes --token $ADMIN_TOKEN -o controller/requests.go
+  → creates controller/requests.go with PostUsersRequest for POST /users`,
+	Args: cobra.ExactArgs(1),
+	Run:  runInferTypes,
+}
+
+func init() {
+	inferTypesCmd.Flags().StringVarP(&inferTypesOutput, "output", "o", "", "Output file (default: stdout)")
+	inferTypesCmd.Flags().StringVar(&inferTypesPackage, "package", "", "Package name (default: output directory name, or controller)")
+	inferTypesCmd.Flags().StringVar(&inferTypesToken, "token", "", "Admin bearer token when reading from a URL")
+	inferCmd.AddCommand(inferTypesCmd)
+}
+
+func runInferTypes(cmd *cobra.Command, args []string) {
+	shapes, err := readShapes(args[0], inferTypesToken)
+	if err != nil {
+		fmt.Printf("%s%s%s\n", "\x1b[31m", err, "\x1b[0m")
+		return
+	}
+
+	pkg := inferTypesPackage
+	if pkg == "" {
+		pkg = "controller"
+		if inferTypesOutput != "" {
+			if dir := filepath.Base(filepath.Dir(inferTypesOutput)); dir != "." && dir != string(filepath.Separator) {
+				pkg = dir
+			}
+		}
+	}
+	src, err := generateTypes(pkg, shapes)
+	if err != nil {
+		fmt.Printf("generate types failed: %s\n", err)
+		return
+	}
+
+	if inferTypesOutput == "" {
+		os.Stdout.Write(src)
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(inferTypesOutput), 0755); err != nil {
+		fmt.Printf("create directory %s failed: %s\n", filepath.Dir(inferTypesOutput), err)
+		return
+	}
+	if err := os.WriteFile(inferTypesOutput, src, 0644); err != nil {
+		fmt.Printf("write %s failed: %s\n", inferTypesOutput, err)
+		return
+	}
+	fmt.Printf("created: %s\n", inferTypesOutput)
+}
+
+// readShapes loads BodySampler.Shapes output from a file or an http(s) URL.
+func readShapes(src, token string) (map[string]*glk.BodyShape, error) {
+	var data []byte
+	if strings.HasPrefix(src, "http://") || strings.HasPrefix(src, "https://") {
+		req, err := http.NewRequest(http.MethodGet, src, nil)
+		if err != nil {
+			return nil, err
+		}
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("fetch %s failed: %w", src, err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("fetch %s failed: %s", src, resp.Status)
+		}
+		if data, err = io.ReadAll(resp.Body); err != nil {
+			return nil, fmt.Errorf("fetch %s failed: %w", src, err)
+		}
+	} else {
+		var err error
+		if data, err = os.ReadFile(src); err != nil {
+			return nil, fmt.Errorf("read %s failed: %w", src, err)
+		}
+	}
+	var shapes map[string]*glk.BodyShape
+	if err := json.Unmarshal(data, &shapes); err != nil {
+		return nil, fmt.Errorf("decode shapes failed: %w", err)
+	}
+	return shapes, nil
+}
+
+// typeWriter accumulates the struct declarations of generateTypes.
+type typeWriter struct {
+	decls bytes.Buffer
+	used  map[string]bool
+}
+
+// generateTypes renders one <Method><Path>Request type per sampled route, plus
+// a named struct for every nested object.
+func generateTypes(pkg string, shapes map[string]*glk.BodyShape) ([]byte, error) {
+	patterns := make([]string, 0, len(shapes))
+	for p := range shapes {
+		patterns = append(patterns, p)
+	}
+	sort.Strings(patterns)
+
+	w := &typeWriter{used: map[string]bool{}}
+	for _, p := range patterns {
+		shape := shapes[p]
+		if shape == nil {
+			continue
+		}
+		name := w.name(requestTypeName(p))
+		doc := fmt.Sprintf("// %s is the request body of %s, inferred from sampled requests (n=%d).\n", name, p, shape.Samples)
+		if shape.Kind == glk.ShapeObject {
+			w.writeStruct(name, doc, shape)
+			continue
+		}
+		typ := w.goType(name, shape, false)
+		fmt.Fprintf(&w.decls, "%stype %s %s\n\n", doc, name, typ)
+	}
+
+	var b bytes.Buffer
+	b.WriteString("// Code generated by glk infer types from sampled request bodies.\n")
+	b.WriteString("// Review field types and optionality before relying on them.\n\n")
+	fmt.Fprintf(&b, "package %s\n\n", pkg)
+	b.Write(w.decls.Bytes())
+	return format.Source(b.Bytes())
+}
+
+// requestTypeName names a route's body type after its method and static path
+// segments: "POST /users/{id}/posts" → PostUsersPostsRequest.
+func requestTypeName(pattern string) string {
+	method, path, ok := strings.Cut(pattern, " ")
+	if !ok {
+		method, path = "", pattern
+	}
+	if i := strings.Index(path, "/"); i > 0 {
+		path = path[i:] // drop the host
+	}
+	name := strings.TrimSuffix(structName(route{Method: method, Pattern: path}), "Params")
+	if name == "" || name == goFieldName(strings.ToLower(method)) {
+		name += "Root"
+	}
+	return name + "Request"
+}
+
+// name reserves a unique type name.
+func (w *typeWriter) name(base string) string {
+	name := base
+	for i := 2; w.used[name]; i++ {
+		name = base + strconv.Itoa(i)
+	}
+	w.used[name] = true
+	return name
+}
+
+// writeStruct declares name, preceded by doc, after the nested types its
+// fields need.
+func (w *typeWriter) writeStruct(name, doc string, shape *glk.BodyShape) {
+	keys := make([]string, 0, len(shape.Fields))
+	for k := range shape.Fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var body bytes.Buffer
+	fieldNames := map[string]bool{}
+	for i, k := range keys {
+		if strings.ContainsRune(k, '`') {
+			continue // cannot appear in a struct tag
+		}
+		field := exportedName(k, i)
+		for base, n := field, 2; fieldNames[field]; n++ {
+			field = base + strconv.Itoa(n)
+		}
+		fieldNames[field] = true
+
+		tag := k
+		if shape.Optional(k) {
+			tag += ",omitempty"
+		}
+		typ := w.goType(name+field, shape.Fields[k], true)
+		fmt.Fprintf(&body, "\t%s %s `json:%q`\n", field, typ, tag)
+	}
+	fmt.Fprintf(&w.decls, "%stype %s struct {\n%s}\n\n", doc, name, body.String())
+}
+
+// goType returns the Go type of shape, declaring a struct named hint when the
+// shape is an object. Nullable scalars and objects become pointers.
+func (w *typeWriter) goType(hint string, shape *glk.BodyShape, pointer bool) string {
+	if shape == nil {
+		return "any"
+	}
+	var typ string
+	switch shape.Kind {
+	case glk.ShapeObject:
+		name := w.name(hint)
+		w.writeStruct(name, "", shape)
+		typ = name
+	case glk.ShapeMap:
+		return "map[string]" + w.goType(hint+"Value", shape.Elem, false)
+	case glk.ShapeArray:
+		return "[]" + w.goType(hint+"Item", shape.Elem, false)
+	case glk.ShapeString:
+		typ = "string"
+	case glk.ShapeInteger:
+		typ = "int64"
+	case glk.ShapeNumber:
+		typ = "float64"
+	case glk.ShapeBool:
+		typ = "bool"
+	default:
+		return "any"
+	}
+	if pointer && shape.Nullable {
+		return "*" + typ
+	}
+	return typ
+}
+
+// exportedName turns a JSON key into an exported Go identifier, falling back
+// to FieldN for keys without letters.
+func exportedName(key string, i int) string {
+	name := goFieldName(key)
+	if name == "" {
+		return "Field" + strconv.Itoa(i)
+	}
+	if name[0] >= '0' && name[0] <= '9' {
+		return "F" + name
+	}
+	return name
+}
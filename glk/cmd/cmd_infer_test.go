@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	glk "github.com/hansir-hsj/GoLiteKit"
+)
+
+func TestInferTypes_GeneratedCodeCompilesAndDecodes(t *testing.T) {
+	sampler := glk.NewBodySampler()
+	record := func(route, body string) {
+		var v any
+		dec := json.NewDecoder(strings.NewReader(body))
+		dec.UseNumber()
+		if err := dec.Decode(&v); err != nil {
+			t.Fatal(err)
+		}
+		sampler.Record(route, v)
+	}
+	record("POST /users/{id}/orders", `{"user_id":1,"note":null,"items":[{"sku":"a","qty":2,"price":1.5}],"meta":{"k":"v"}}`)
+	record("POST /users/{id}/orders", `{"user_id":2,"note":"x","items":[],"coupon":"c","any":1}`)
+	record("POST /users/{id}/orders", `{"user_id":3,"note":"y","items":[{"sku":"b","qty":1,"price":2}],"any":"s"}`)
+	record("PUT /tags", `["a","b"]`)
+
+	app := glk.NewApp()
+	app.MountAdmin(glk.AdminOptions{Token: "secret", BodySampler: sampler})
+	srv := httptest.NewServer(app.Handler())
+	defer srv.Close()
+
+	if _, err := readShapes(srv.URL+"/_admin/shapes", "wrong"); err == nil {
+		t.Fatal("expected an error for a rejected token")
+	}
+	shapes, err := readShapes(srv.URL+"/_admin/shapes", "secret")
+	if err != nil {
+		t.Fatalf("readShapes: %v", err)
+	}
+	src, err := generateTypes("requests", shapes)
+	if err != nil {
+		t.Fatalf("generateTypes: %v", err)
+	}
+	flat := strings.Join(strings.Fields(string(src)), " ")
+	for _, want := range []string{
+		"// PostUsersOrdersRequest is the request body of POST /users/{id}/orders, inferred from sampled requests (n=3).",
+		"UserID int64 `json:\"user_id\"`",
+		"Note *string `json:\"note\"`",
+		"Coupon string `json:\"coupon,omitempty\"`",
+		"Any any `json:\"any,omitempty\"`",
+		"Items []PostUsersOrdersRequestItemsItem `json:\"items\"`",
+		"Price float64 `json:\"price\"`",
+		"Meta PostUsersOrdersRequestMeta `json:\"meta,omitempty\"`",
+		"type PutTagsRequest []string",
+	} {
+		if !strings.Contains(flat, want) {
+			t.Fatalf("generated source missing %q:\n%s", want, src)
+		}
+	}
+
+	dir := t.TempDir()
+	writeTempModule(t, dir)
+	if err := os.MkdirAll(filepath.Join(dir, "requests"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "requests", "requests.go"), src, 0644); err != nil {
+		t.Fatal(err)
+	}
+	testSrc := `package requests
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestDecode(t *testing.T) {
+	var req PostUsersOrdersRequest
+	if err := json.Unmarshal([]byte(` + "`" + `{"user_id":7,"note":null,"items":[{"sku":"a","qty":2,"price":1.5}]}` + "`" + `), &req); err != nil {
+		t.Fatal(err)
+	}
+	if req.UserID != 7 || req.Note != nil || len(req.Items) != 1 || req.Items[0].Qty != 2 {
+		t.Fatalf("decoded %+v", req)
+	}
+}
+`
+	if err := os.WriteFile(filepath.Join(dir, "requests", "requests_test.go"), []byte(testSrc), 0644); err != nil {
+		t.Fatal(err)
+	}
+	runGoTest(t, dir)
+}
+
+func TestRequestTypeName(t *testing.T) {
+	cases := map[string]string{
+		"POST /users":              "PostUsersRequest",
+		"PATCH /api/v1/users/{id}": "PatchAPIV1UsersRequest",
+		"/webhooks":                "WebhooksRequest",
+		"POST /":                   "PostRootRequest",
+		"POST example.com/hooks":   "PostHooksRequest",
+	}
+	for in, want := range cases {
+		if got := requestTypeName(in); got != want {
+			t.Errorf("requestTypeName(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
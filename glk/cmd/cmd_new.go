@@ -4,7 +4,6 @@ import (
 	"bytes"
 	"embed"
 	"fmt"
-	"io"
 	"io/fs"
 	"os"
 	"os/exec"
@@ -19,18 +18,53 @@ import (
 var tpl embed.FS
 
 var (
-	moduleFlag   string
-	forceFlag    bool
-	dryRunFlag   bool
-	skipTidyFlag bool
+	moduleFlag    string
+	forceFlag     bool
+	dryRunFlag    bool
+	skipTidyFlag  bool
+	templateFlag  string
+	goVersionFlag string
+	dbFlag        bool
+	redisFlag     bool
+	varFlags      []string
 )
 
+// DefaultTemplate is the built-in template used by glk new.
+const DefaultTemplate = "rest-api"
+
+// DefaultGoVersion is the go directive of generated go.mod files.
+const DefaultGoVersion = "1.23"
+
+// builtinTemplates lists the tpl directories each built-in template renders,
+// later ones overriding files of earlier ones.
+var builtinTemplates = map[string][]string{
+	"minimal":   {"tpl/base", "tpl/minimal"},
+	"rest-api":  {"tpl/base", "tpl/rest-api"},
+	"fullstack": {"tpl/base", "tpl/rest-api", "tpl/fullstack"},
+}
+
 var newCmd = &cobra.Command{
 	Use:   "new <appName>",
 	Short: "Create a new GoLiteKit application",
 	Long: `Create a new GoLiteKit application in the current directory.
 The appName may include subdirectories, e.g. "glk new myorg/myapp".
-Use --module to set a custom Go module path.`,
+Use --module to set a custom Go module path.
+
+--template selects a built-in starter:
+  minimal    one HandlerFunc route, no controllers
+  rest-api   controllers, including a validated JSON endpoint (default)
+  fullstack  rest-api plus static/ assets, with DB and Redis enabled
+
+or a team starter: a git URL (optionally suffixed with #branch or #tag)
+or a local directory. Files ending in .tpl are rendered with text/template
+and written without the suffix, other files are copied; a template that
+renders to blank output is skipped. Templates can use {{.App}}, {{.Module}},
+{{.GoVersion}}, {{.DB}}, {{.Redis}}, {{.Static}} and any --var key=value as
+{{.Vars.key}}.
+
+Example:
+  glk new shop --module github.com/acme/shop --template fullstack
+  glk new billing --template https://github.com/acme/glk-starter.git#v2 --var team=payments`,
 	Run: CreateApp,
 }
 
@@ -39,6 +73,11 @@ func init() {
 	newCmd.Flags().BoolVar(&forceFlag, "force", false, "Overwrite existing directory without asking")
 	newCmd.Flags().BoolVar(&dryRunFlag, "dry-run", false, "Print intended operations without writing files")
 	newCmd.Flags().BoolVar(&skipTidyFlag, "skip-tidy", false, "Skip running go mod tidy")
+	newCmd.Flags().StringVar(&templateFlag, "template", DefaultTemplate, "Template: minimal, rest-api, fullstack, a git URL or a directory")
+	newCmd.Flags().StringVar(&goVersionFlag, "go-version", DefaultGoVersion, "Go version of the generated go.mod")
+	newCmd.Flags().BoolVar(&dbFlag, "db", false, "Configure a DB connection (default: true for fullstack)")
+	newCmd.Flags().BoolVar(&redisFlag, "redis", false, "Configure a Redis client (default: true for fullstack)")
+	newCmd.Flags().StringArrayVar(&varFlags, "var", nil, "Extra template variable key=value, available as {{.Vars.key}}")
 }
 
 // dangerousTargets that should be rejected.
@@ -91,9 +130,39 @@ func CreateApp(cmd *cobra.Command, args []string) {
 		return
 	}
 
+	vars, err := parseTemplateVars(varFlags)
+	if err != nil {
+		fmt.Printf("%s%s%s\n", "\x1b[31m", err.Error(), "\x1b[0m")
+		return
+	}
+	fullstack := templateFlag == "fullstack"
+	data := templateData{
+		App:       name,
+		Module:    module,
+		GoVersion: goVersionFlag,
+		DB:        dbFlag || fullstack && !cmd.Flags().Changed("db"),
+		Redis:     redisFlag || fullstack && !cmd.Flags().Changed("redis"),
+		Static:    fullstack,
+		Vars:      vars,
+	}
+
+	src, err := openTemplate(templateFlag)
+	if err != nil {
+		fmt.Printf("%s%s%s\n", "\x1b[31m", err.Error(), "\x1b[0m")
+		return
+	}
+	defer src.Close()
+	files, err := renderProject(src, data)
+	if err != nil {
+		fmt.Printf("render templates failed: %s\n", err)
+		return
+	}
+
 	if dryRunFlag {
-		fmt.Printf("[dry-run] Would create project %q in %s (module: %s)\n", name, dstDir, module)
-		printTemplateFiles(dstDir)
+		fmt.Printf("[dry-run] Would create project %q in %s (module: %s, template: %s)\n", name, dstDir, module, templateFlag)
+		for _, f := range files {
+			fmt.Println("  [dry-run] +", filepath.Join(dstDir, f.path))
+		}
 		return
 	}
 
@@ -113,15 +182,10 @@ func CreateApp(cmd *cobra.Command, args []string) {
 		}
 	}
 
-	if err = os.MkdirAll(dstDir, 0755); err != nil {
-		fmt.Printf("create app directory failed: %s\n", err)
-		return
-	}
-
 	fmt.Printf("Creating application %s%s%s...\n", "\x1b[32m", name, "\x1b[0m")
 
-	if err := renderTemplates(dstDir, name, module); err != nil {
-		fmt.Printf("render templates failed: %s\n", err)
+	if err := writeProject(dstDir, files); err != nil {
+		fmt.Printf("write project failed: %s\n", err)
 		return
 	}
 
@@ -145,64 +209,175 @@ func CreateApp(cmd *cobra.Command, args []string) {
 	fmt.Printf("Run: cd %s && go run .\n", args[0])
 }
 
-func renderTemplates(dstDir, name, module string) error {
-	glkTpl := template.New("glk")
+// templateData are the variables available to project templates. Name is
+// kept for templates written before App existed.
+type templateData struct {
+	App       string
+	Name      string
+	Module    string
+	GoVersion string
+	DB        bool
+	Redis     bool
+	Static    bool
+	Vars      map[string]string
+}
 
-	return fs.WalkDir(tpl, "tpl", func(path string, dy fs.DirEntry, err error) error {
-		if err != nil {
-			return err
+func parseTemplateVars(pairs []string) (map[string]string, error) {
+	vars := make(map[string]string, len(pairs))
+	for _, p := range pairs {
+		k, v, ok := strings.Cut(p, "=")
+		if !ok || k == "" {
+			return nil, fmt.Errorf("invalid --var %q, want key=value", p)
 		}
+		vars[k] = v
+	}
+	return vars, nil
+}
 
-		rel, err := filepath.Rel("tpl", path)
-		if err != nil {
-			return err
-		}
+// templateSource is a set of template directories in an FS; files of later
+// roots override files of earlier ones with the same relative path.
+type templateSource struct {
+	fsys    fs.FS
+	roots   []string
+	cleanup func()
+}
 
-		if dy.IsDir() {
-			dir := filepath.Join(dstDir, rel)
-			return os.MkdirAll(dir, 0755)
-		}
+func (s *templateSource) Close() {
+	if s.cleanup != nil {
+		s.cleanup()
+	}
+}
 
-		src, err := tpl.Open(path)
-		if err != nil {
-			return err
-		}
-		defer src.Close()
+// openTemplate resolves a built-in template name, a git URL (cloned into a
+// temporary directory, "#ref" selecting a branch or tag) or a local directory.
+func openTemplate(name string) (*templateSource, error) {
+	if roots, ok := builtinTemplates[name]; ok {
+		return &templateSource{fsys: tpl, roots: roots}, nil
+	}
+	if isGitURL(name) {
+		return cloneTemplate(name)
+	}
+	if info, err := os.Stat(name); err == nil && info.IsDir() {
+		return &templateSource{fsys: os.DirFS(name), roots: []string{"."}}, nil
+	}
+	return nil, fmt.Errorf("unknown template %q: use minimal, rest-api, fullstack, a git URL or a directory", name)
+}
 
-		dst := strings.TrimSuffix(filepath.Join(dstDir, rel), ".tpl")
-		dstFile, err := os.Create(dst)
-		if err != nil {
-			return err
+func isGitURL(s string) bool {
+	for _, prefix := range []string{"https://", "http://", "ssh://", "git://", "file://", "git@"} {
+		if strings.HasPrefix(s, prefix) {
+			return true
 		}
-		defer dstFile.Close()
+	}
+	url, _, _ := strings.Cut(s, "#")
+	return strings.HasSuffix(url, ".git")
+}
 
-		fmt.Println("  +", dst)
+func cloneTemplate(src string) (*templateSource, error) {
+	url, ref, _ := strings.Cut(src, "#")
+	// git would parse them as options, e.g. --upload-pack=<command>.
+	if strings.HasPrefix(url, "-") || strings.HasPrefix(ref, "-") {
+		return nil, fmt.Errorf("invalid template %q: must not start with '-'", src)
+	}
+	dir, err := os.MkdirTemp("", "glk-template-*")
+	if err != nil {
+		return nil, err
+	}
+	args := []string{"clone", "--quiet", "--depth", "1"}
+	if ref != "" {
+		args = append(args, "--branch", ref)
+	}
+	clone := exec.Command("git", append(args, "--", url, dir)...)
+	if out, err := clone.CombinedOutput(); err != nil {
+		os.RemoveAll(dir)
+		return nil, fmt.Errorf("git clone %s failed: %w\n%s", url, err, bytes.TrimSpace(out))
+	}
+	return &templateSource{
+		fsys:    os.DirFS(dir),
+		roots:   []string{"."},
+		cleanup: func() { os.RemoveAll(dir) },
+	}, nil
+}
 
-		txt, err := io.ReadAll(src)
-		if err != nil {
-			return err
-		}
+// projectFile is a rendered file, path relative to the project directory.
+type projectFile struct {
+	path    string
+	content []byte
+}
 
-		parser, err := glkTpl.Parse(string(bytes.TrimSpace(txt)))
+// renderProject renders every file of src in memory, so nothing is written
+// when a template fails.
+func renderProject(src *templateSource, data templateData) ([]projectFile, error) {
+	data.Name = data.App
+	if data.GoVersion == "" {
+		data.GoVersion = DefaultGoVersion
+	}
+	index := map[string]int{}
+	var files []projectFile
+	for _, root := range src.roots {
+		err := fs.WalkDir(src.fsys, root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				if d.Name() == ".git" {
+					return fs.SkipDir
+				}
+				return nil
+			}
+			rel := strings.TrimPrefix(strings.TrimPrefix(path, root), "/")
+			content, err := fs.ReadFile(src.fsys, path)
+			if err != nil {
+				return err
+			}
+			if strings.HasSuffix(rel, ".tpl") {
+				rel = strings.TrimSuffix(rel, ".tpl")
+				if content, err = renderProjectTemplate(path, content, data); err != nil {
+					return err
+				}
+				if len(bytes.TrimSpace(content)) == 0 {
+					return nil
+				}
+			}
+			f := projectFile{path: filepath.FromSlash(rel), content: content}
+			if i, ok := index[rel]; ok {
+				files[i] = f
+				return nil
+			}
+			index[rel] = len(files)
+			files = append(files, f)
+			return nil
+		})
 		if err != nil {
-			return err
+			return nil, err
 		}
+	}
+	return files, nil
+}
 
-		return parser.Execute(dstFile, map[string]any{
-			"Module": module,
-			"Name":   name,
-		})
-	})
+func renderProjectTemplate(name string, content []byte, data templateData) ([]byte, error) {
+	t, err := template.New(name).Option("missingkey=error").Parse(string(bytes.TrimSpace(content)))
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes(), nil
 }
 
-func printTemplateFiles(dstDir string) {
-	fs.WalkDir(tpl, "tpl", func(path string, dy fs.DirEntry, err error) error {
-		if err != nil || dy.IsDir() {
+func writeProject(dstDir string, files []projectFile) error {
+	for _, f := range files {
+		dst := filepath.Join(dstDir, f.path)
+		if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
 			return err
 		}
-		rel, _ := filepath.Rel("tpl", path)
-		dst := strings.TrimSuffix(filepath.Join(dstDir, rel), ".tpl")
-		fmt.Println("  [dry-run] +", dst)
-		return nil
-	})
+		fmt.Println("  +", dst)
+		if err := os.WriteFile(dst, f.content, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
 }
@@ -155,12 +155,22 @@ func TestGeneratedMiddlewareHasFrameworkType(t *testing.T) {
 	runGoTest(t, dir)
 }
 
-func TestRenderTemplates_NewProjectCompiles(t *testing.T) {
-	dir := t.TempDir()
-	module := "example.com/generated-app"
+// renderNewProject renders a template into dir and points the generated
+// go.mod at this checkout.
+func renderNewProject(t *testing.T, dir, template string, data templateData) []projectFile {
+	t.Helper()
 
-	if err := renderTemplates(dir, "generated-app", module); err != nil {
-		t.Fatalf("renderTemplates: %v", err)
+	src, err := openTemplate(template)
+	if err != nil {
+		t.Fatalf("openTemplate(%q): %v", template, err)
+	}
+	defer src.Close()
+	files, err := renderProject(src, data)
+	if err != nil {
+		t.Fatalf("renderProject: %v", err)
+	}
+	if err := writeProject(dir, files); err != nil {
+		t.Fatalf("writeProject: %v", err)
 	}
 
 	goModPath := filepath.Join(dir, "go.mod")
@@ -175,6 +185,12 @@ func TestRenderTemplates_NewProjectCompiles(t *testing.T) {
 	if err := os.WriteFile(goModPath, []byte(goModText), 0644); err != nil {
 		t.Fatalf("rewrite generated go.mod: %v", err)
 	}
+	return files
+}
+
+func TestRenderTemplates_NewProjectCompiles(t *testing.T) {
+	dir := t.TempDir()
+	renderNewProject(t, dir, DefaultTemplate, templateData{App: "generated-app", Module: "example.com/generated-app"})
 
 	controllerPath := filepath.Join(dir, "controller", "hello_controller.go")
 	controller, err := os.ReadFile(controllerPath)
@@ -184,6 +200,125 @@ func TestRenderTemplates_NewProjectCompiles(t *testing.T) {
 	if strings.Contains(string(controller), "BaseController[") {
 		t.Fatalf("generated controller uses obsolete generic BaseController syntax:\n%s", controller)
 	}
+	for _, skipped := range []string{"db.toml", "redis.toml"} {
+		if _, err := os.Stat(filepath.Join(dir, "conf", skipped)); err == nil {
+			t.Errorf("conf/%s generated without its toggle", skipped)
+		}
+	}
 
 	runGoTest(t, dir)
 }
+
+func TestRenderTemplates_Variants(t *testing.T) {
+	for _, tc := range []struct {
+		template string
+		data     templateData
+		want     map[string]string // file → content it must contain
+		missing  []string
+	}{
+		{
+			template: "minimal",
+			data:     templateData{App: "tiny", Module: "example.com/tiny", GoVersion: "1.24"},
+			want:     map[string]string{"go.mod": "go 1.24", "main.go": "kit.HandlerFunc", "conf/app.toml": `runMode  = "prod"`},
+			missing:  []string{"controller"},
+		},
+		{
+			template: "fullstack",
+			data:     templateData{App: "shop", Module: "example.com/shop", DB: true, Redis: true, Static: true},
+			want: map[string]string{
+				"main.go":           "kit.WithDB(dbConn)",
+				"conf/app.toml":     `staticDir = "static"`,
+				"conf/db.toml":      `database = "shop"`,
+				"conf/redis.toml":   "port = 6379",
+				"static/index.html": "<title>shop</title>",
+			},
+		},
+	} {
+		t.Run(tc.template, func(t *testing.T) {
+			dir := t.TempDir()
+			renderNewProject(t, dir, tc.template, tc.data)
+			for file, want := range tc.want {
+				got, err := os.ReadFile(filepath.Join(dir, file))
+				if err != nil || !strings.Contains(string(got), want) {
+					t.Errorf("%s (%v) missing %q:\n%s", file, err, want, got)
+				}
+			}
+			for _, file := range tc.missing {
+				if _, err := os.Stat(filepath.Join(dir, file)); err == nil {
+					t.Errorf("%s should not be generated", file)
+				}
+			}
+			runGoTest(t, dir)
+		})
+	}
+}
+
+func TestRenderTemplates_GitTemplate(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	repo := t.TempDir()
+	files := map[string]string{
+		"go.mod.tpl":  "module {{.Module}}\n\ngo {{.GoVersion}}\n",
+		"main.go.tpl": "package main\n\n// owned by {{.Vars.team}}\nfunc main() {}\n",
+		"README.md":   "# {{.App}} is copied verbatim\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(repo, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, args := range [][]string{
+		{"init", "--quiet"},
+		{"add", "."},
+		{"-c", "user.name=t", "-c", "user.email=t@example.com", "commit", "--quiet", "-m", "starter"},
+		{"tag", "v1"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repo
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+
+	src, err := openTemplate("file://" + repo + "#v1")
+	if err != nil {
+		t.Fatalf("openTemplate: %v", err)
+	}
+	rendered, err := renderProject(src, templateData{App: "svc", Module: "example.com/svc", Vars: map[string]string{"team": "payments"}})
+	src.Close()
+	if err != nil {
+		t.Fatalf("renderProject: %v", err)
+	}
+	got := map[string]string{}
+	for _, f := range rendered {
+		got[f.path] = string(f.content)
+	}
+	if len(got) != 3 || !strings.Contains(got["main.go"], "// owned by payments") || got["README.md"] != files["README.md"] {
+		t.Fatalf("rendered files = %v", got)
+	}
+
+	src, _ = openTemplate(repo)
+	defer src.Close()
+	if _, err := renderProject(src, templateData{App: "svc", Module: "example.com/svc"}); err == nil {
+		t.Fatal("expected an error for a missing --var")
+	}
+	if _, err := openTemplate("no-such-template"); err == nil {
+		t.Fatal("expected an error for an unknown template")
+	}
+}
+
+func TestOpenTemplate_RejectsOptionLikeGitURL(t *testing.T) {
+	marker := filepath.Join(t.TempDir(), "pwned")
+	for _, name := range []string{
+		"--upload-pack=touch " + marker + ";.git",
+		"https://example.com/starter.git#--upload-pack=touch " + marker,
+	} {
+		if _, err := openTemplate(name); err == nil || !strings.Contains(err.Error(), "must not start with '-'") {
+			t.Errorf("openTemplate(%q) err = %v, want a rejection", name, err)
+		}
+	}
+	if _, err := os.Stat(marker); err == nil {
+		t.Fatal("git ran the injected command")
+	}
+}
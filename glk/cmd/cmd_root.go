@@ -26,5 +26,8 @@ func Execute() {
 func init() {
 	rootCmd.AddCommand(newCmd)
 	rootCmd.AddCommand(addCmd)
+	rootCmd.AddCommand(genCmd)
+	rootCmd.AddCommand(inferCmd)
+	rootCmd.AddCommand(doctorCmd)
 	rootCmd.AddCommand(versionCmd)
 }
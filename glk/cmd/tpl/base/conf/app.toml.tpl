@@ -1,6 +1,7 @@
 [HttpServer]
-appName  = "{{.Name}}"
-runMode  = "debug"
+appName  = "{{.App}}"
+# "debug", "dev", "development" or "test" add error detail to responses
+runMode  = "prod"
 addr     = ":8080"
 # set to true to enable pprof endpoints
 enablePprof = false
@@ -17,14 +18,24 @@ shutdownTimeout = 5000
 rateLimit = 100
 rateBurst = 150
 
-# logger config file path (relative to working directory)
+# logger config file path (relative to conf/)
 [HttpServer.Logger]
 configFile = "logger.toml"
+{{- if .DB}}
 
 # database config file path
 [HttpServer.DB]
 configFile = "db.toml"
+{{- end}}
+{{- if .Redis}}
 
 # redis config file path
 [HttpServer.Redis]
 configFile = "redis.toml"
+{{- end}}
+{{- if .Static}}
+
+# files under static/ are served at /static/
+[HttpServer.Static]
+staticDir = "static"
+{{- end}}
@@ -0,0 +1,21 @@
+{{- if .DB -}}
+[db]
+username = "root"
+password = ""
+protocol = "tcp"
+host     = "127.0.0.1"
+port     = 3306
+database = "{{.App}}"
+charset  = "utf8mb4"
+
+# milliseconds
+[db.Timeout]
+timeout      = 1500
+readTimeout  = 1500
+writeTimeout = 1500
+
+[db.Conn]
+maxOpenConns    = 10
+maxIdleConns    = 5
+connMaxLifeTime = 600000
+{{- end}}
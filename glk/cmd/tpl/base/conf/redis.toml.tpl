@@ -0,0 +1,17 @@
+{{- if .Redis -}}
+[redis]
+host = "127.0.0.1"
+port = 6379
+db   = 0
+
+# milliseconds
+[redis.Timeout]
+dialTimeout  = 3000
+readTimeout  = 3000
+writeTimeout = 3000
+
+[redis.Conn]
+poolSize     = 10
+minIdleConns = 1
+maxIdleConns = 5
+{{- end}}
@@ -1,3 +1,3 @@
 module {{.Module}}
 
-go 1.23
+go {{.GoVersion}}
@@ -0,0 +1,16 @@
+<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <title>{{.App}}</title>
+</head>
+<body>
+  <h1>{{.App}}</h1>
+  <p id="message">Loading...</p>
+  <script>
+    fetch("/hello")
+      .then((resp) => resp.json())
+      .then((body) => { document.getElementById("message").textContent = body.message; });
+  </script>
+</body>
+</html>
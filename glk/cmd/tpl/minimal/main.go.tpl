@@ -0,0 +1,34 @@
+package main
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+
+	kit "github.com/hansir-hsj/GoLiteKit"
+)
+
+func main() {
+	app, err := kit.NewAppFromConfig("conf/app.toml")
+	if err != nil {
+		log.Fatalf("failed to create app: %v", err)
+	}
+
+	app.GET("/hello", kit.HandlerFunc(func(ctx *kit.Context) error {
+		return ctx.JSON(http.StatusOK, map[string]string{"message": "Hello, {{.App}}!"})
+	}))
+
+	config, err := kit.ServerConfigFromEnv()
+	if err != nil {
+		log.Fatalf("invalid server config: %v", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if err := app.ListenAndServe(ctx, config); err != nil {
+		log.Fatalf("server error: %v", err)
+	}
+}
@@ -0,0 +1,26 @@
+package controller
+
+import (
+	"context"
+
+	kit "github.com/hansir-hsj/GoLiteKit"
+)
+
+// EchoRequest is the JSON body of POST /echo.
+type EchoRequest struct {
+	Message string `json:"message" validate:"required,max=256"`
+}
+
+// EchoController returns the validated request body in the standard
+// {status, msg, data} envelope.
+type EchoController struct {
+	kit.RestControllerOf[EchoRequest]
+}
+
+func (c *EchoController) Validate(ctx context.Context) error {
+	return c.ValidateRequest()
+}
+
+func (c *EchoController) Serve(ctx context.Context) error {
+	return c.ServeData(ctx, c.Request)
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+
+	kit "github.com/hansir-hsj/GoLiteKit"
+{{- if .DB}}
+	"github.com/hansir-hsj/GoLiteKit/db"
+{{- end}}
+{{- if .Redis}}
+	"github.com/hansir-hsj/GoLiteKit/redis"
+{{- end}}
+
+	"{{.Module}}/controller"
+)
+
+func main() {
+	var opts []kit.ServiceOption
+{{- if .DB}}
+	dbConn, err := db.NewFromConfig("conf/db.toml")
+	if err != nil {
+		log.Fatalf("failed to connect to the database: %v", err)
+	}
+	opts = append(opts, kit.WithDB(dbConn))
+{{- end}}
+{{- if .Redis}}
+	rdb, err := redis.NewFromConfig("conf/redis.toml")
+	if err != nil {
+		log.Fatalf("failed to connect to redis: %v", err)
+	}
+	opts = append(opts, kit.WithRedis(rdb))
+{{- end}}
+
+	app, err := kit.NewAppFromConfig("conf/app.toml", opts...)
+	if err != nil {
+		log.Fatalf("failed to create app: %v", err)
+	}
+
+	app.GET("/hello", &controller.HelloController{})
+	app.POST("/echo", &controller.EchoController{})
+
+	config, err := kit.ServerConfigFromEnv()
+	if err != nil {
+		log.Fatalf("invalid server config: %v", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if err := app.ListenAndServe(ctx, config); err != nil {
+		log.Fatalf("server error: %v", err)
+	}
+}
@@ -0,0 +1,26 @@
+package controller
+
+import (
+	"context"
+
+	kit "github.com/hansir-hsj/GoLiteKit"
+)
+
+// {{.Name}}Request is the JSON body of {{.Method}} {{.Route}}.
+type {{.Name}}Request struct {
+	Name string `json:"name" validate:"required,max=64"`
+}
+
+// {{.Name}}Controller handles {{.Method}} {{.Route}}. The body is parsed into
+// c.Request and its validate tags are checked before Serve runs.
+type {{.Name}}Controller struct {
+	kit.RestControllerOf[{{.Name}}Request]
+}
+
+func (c *{{.Name}}Controller) Validate(ctx context.Context) error {
+	return c.ValidateRequest()
+}
+
+func (c *{{.Name}}Controller) Serve(ctx context.Context) error {
+	return c.ServeData(ctx, c.Request)
+}
@@ -0,0 +1,40 @@
+package controller
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	kit "github.com/hansir-hsj/GoLiteKit"
+)
+
+func Test{{.Name}}Controller(t *testing.T) {
+	app := kit.NewApp()
+	app.{{.Method}}("{{.Route}}", &{{.Name}}Controller{})
+
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+	}{
+{{- if .Rest}}
+		{name: "valid body", body: `{"name": "demo"}`, wantStatus: http.StatusOK},
+		{name: "missing name", body: `{}`, wantStatus: http.StatusBadRequest},
+		{name: "malformed json", body: `{`, wantStatus: http.StatusBadRequest},
+{{- else}}
+		{name: "ok", wantStatus: http.StatusOK},
+{{- end}}
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.Method{{.MethodTitle}}, "{{.Route}}", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			rec := httptest.NewRecorder()
+			app.Handler().ServeHTTP(rec, req)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+		})
+	}
+}
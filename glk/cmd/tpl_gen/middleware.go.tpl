@@ -0,0 +1,16 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	kit "github.com/hansir-hsj/GoLiteKit"
+)
+
+// {{.Name}}Middleware is a GoLiteKit middleware; register it with
+// app.Use({{.Name}}Middleware) or on a route group.
+func {{.Name}}Middleware(next kit.Handler) kit.Handler {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		return next(ctx, w, r)
+	}
+}
@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func Test{{.Name}}Middleware(t *testing.T) {
+	errNext := errors.New("next failed")
+	tests := []struct {
+		name    string
+		nextErr error
+		wantErr error
+	}{
+		{name: "passes through", nextErr: nil, wantErr: nil},
+		{name: "propagates errors", nextErr: errNext, wantErr: errNext},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			called := false
+			handler := {{.Name}}Middleware(func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+				called = true
+				return tt.nextErr
+			})
+			err := handler(context.Background(), httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+			if !called {
+				t.Fatal("next handler was not called")
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,15 @@
+package model
+
+import "time"
+
+// {{.Name}} is a row of the {{.Table}} table.
+type {{.Name}} struct {
+	ID        uint64    `gorm:"primaryKey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName maps {{.Name}} to the {{.Table}} table.
+func ({{.Name}}) TableName() string {
+	return "{{.Table}}"
+}
@@ -0,0 +1,21 @@
+package model
+
+import "testing"
+
+func Test{{.Name}}TableName(t *testing.T) {
+	tests := []struct {
+		name  string
+		model interface{ TableName() string }
+		want  string
+	}{
+		{name: "value", model: {{.Name}}{}, want: "{{.Table}}"},
+		{name: "pointer", model: &{{.Name}}{}, want: "{{.Table}}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.model.TableName(); got != tt.want {
+				t.Fatalf("TableName() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
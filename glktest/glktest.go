@@ -0,0 +1,106 @@
+// Package glktest runs golitekit controllers and handlers end to end
+// without binding a port: requests go through the default middlewares, so
+// the request Context, the log ID and error rendering are in place.
+//
+//	res := glktest.Serve(t, "/users/{id}", &UserController{}, glktest.NewRequest(http.MethodGet, "/users/7", nil))
+//	res.AssertStatus(t, http.StatusOK)
+//	if got := res.Response(t); got.Status != glk.OK { ... }
+package glktest
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	glk "github.com/hansir-hsj/GoLiteKit"
+)
+
+// NewApp creates an app from opts for a test and stops the test when the
+// options are invalid.
+func NewApp(t testing.TB, opts ...glk.ServiceOption) *glk.App {
+	t.Helper()
+	app, err := glk.NewAppWithOptions(opts...)
+	if err != nil {
+		t.Fatalf("glktest: %v", err)
+	}
+	return app
+}
+
+// NewRequest returns a request for target. body may be nil, a string,
+// []byte, an io.Reader, or any other value, which is sent as JSON with a
+// JSON Content-Type.
+func NewRequest(method, target string, body any) *http.Request {
+	var r io.Reader
+	isJSON := false
+	switch b := body.(type) {
+	case nil:
+	case string:
+		r = strings.NewReader(b)
+	case []byte:
+		r = bytes.NewReader(b)
+	case io.Reader:
+		r = b
+	default:
+		data, err := json.Marshal(b)
+		if err != nil {
+			panic("glktest: marshal body: " + err.Error())
+		}
+		r, isJSON = bytes.NewReader(data), true
+	}
+	req := httptest.NewRequest(method, target, r)
+	if isJSON {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return req
+}
+
+// Do serves req with h and records the response.
+func Do(h http.Handler, req *http.Request) *Result {
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return &Result{ResponseRecorder: rec}
+}
+
+// Serve registers c, a controller or a glk.HandlerFunc, under pattern on a
+// new app built from opts and serves req with it.
+func Serve(t testing.TB, pattern string, c any, req *http.Request, opts ...glk.ServiceOption) *Result {
+	t.Helper()
+	app := NewApp(t, opts...)
+	app.Any(pattern, c)
+	return Do(app.Handler(), req)
+}
+
+// Result is a recorded response.
+type Result struct {
+	*httptest.ResponseRecorder
+}
+
+// AssertStatus fails the test unless the response has status code.
+func (r *Result) AssertStatus(t testing.TB, code int) {
+	t.Helper()
+	if r.Code != code {
+		t.Fatalf("status = %d, want %d; body: %s", r.Code, code, r.Body.String())
+	}
+}
+
+// DecodeJSON decodes the response body into v, failing the test when it is
+// not valid JSON.
+func (r *Result) DecodeJSON(t testing.TB, v any) {
+	t.Helper()
+	if err := json.Unmarshal(r.Body.Bytes(), v); err != nil {
+		t.Fatalf("decode response %q: %v", r.Body.String(), err)
+	}
+}
+
+// Response decodes the glk.Response rendered by a REST controller or the
+// error handler.
+func (r *Result) Response(t testing.TB) glk.Response {
+	t.Helper()
+	var resp glk.Response
+	r.DecodeJSON(t, &resp)
+	return resp
+}
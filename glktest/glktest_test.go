@@ -0,0 +1,64 @@
+package glktest
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"testing"
+
+	glk "github.com/hansir-hsj/GoLiteKit"
+)
+
+type userController struct {
+	glk.RestController
+}
+
+func (c *userController) Serve(ctx context.Context) error {
+	id := glk.GetContext(ctx).Param("id")
+	if id == "0" {
+		return glk.ErrNotFound("user not found", nil)
+	}
+	return c.ServeData(ctx, map[string]string{"id": id})
+}
+
+func TestServeController(t *testing.T) {
+	res := Serve(t, "/users/{id}", &userController{}, NewRequest(http.MethodGet, "/users/7", nil))
+	res.AssertStatus(t, http.StatusOK)
+	resp := res.Response(t)
+	if resp.Status != glk.OK || resp.LogID == "" {
+		t.Fatalf("response = %+v", resp)
+	}
+	if data, _ := resp.Data.(map[string]any); data["id"] != "7" {
+		t.Fatalf("data = %v", resp.Data)
+	}
+
+	res = Serve(t, "/users/{id}", &userController{}, NewRequest(http.MethodGet, "/users/0", nil))
+	res.AssertStatus(t, http.StatusNotFound)
+	if resp := res.Response(t); resp.Msg != "user not found" {
+		t.Fatalf("error response = %+v", resp)
+	}
+}
+
+func TestServeHandlerFuncWithJSONBody(t *testing.T) {
+	handler := glk.HandlerFunc(func(ctx *glk.Context) error {
+		if glk.GetContext(ctx.Context()) != ctx {
+			t.Error("context not installed")
+		}
+		body, err := io.ReadAll(ctx.Request().Body)
+		if err != nil {
+			return err
+		}
+		return ctx.Bytes(http.StatusCreated, body)
+	})
+	req := NewRequest(http.MethodPost, "/echo", map[string]int{"n": 1})
+	if req.Header.Get("Content-Type") != "application/json" {
+		t.Fatalf("Content-Type = %q", req.Header.Get("Content-Type"))
+	}
+	res := Serve(t, "/echo", handler, req)
+	res.AssertStatus(t, http.StatusCreated)
+	var got map[string]int
+	res.DecodeJSON(t, &got)
+	if got["n"] != 1 {
+		t.Fatalf("body = %v", got)
+	}
+}
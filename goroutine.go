@@ -0,0 +1,40 @@
+package golitekit
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+
+	"github.com/hansir-hsj/GoLiteKit/logger"
+)
+
+// Go runs fn in a new goroutine that may outlive the request. fn gets ctx
+// without its cancellation and deadline but with its values, so the logID,
+// the log fields and the Context stay available. When an Observer is set,
+// fn runs in a "goroutine" child span of the request.
+//
+// A panic in fn is recovered and reported to the PanicLogger of the
+// request with its logID, or logged at ERROR without one, instead of
+// crashing the process.
+func Go(ctx context.Context, fn func(ctx context.Context)) {
+	ctx = context.WithoutCancel(ctx)
+	go func() {
+		ctx, span := StartSpan(ctx, "goroutine")
+		defer span.End()
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				span.SetError(fmt.Errorf("panic: %v", recovered))
+				reportGoroutinePanic(ctx, recovered)
+			}
+		}()
+		fn(ctx)
+	}()
+}
+
+func reportGoroutinePanic(ctx context.Context, recovered any) {
+	if gcx := GetContext(ctx); gcx != nil && gcx.PanicLogger() != nil {
+		gcx.PanicLogger().Report(ctx, recovered)
+		return
+	}
+	logger.FromContext(ctx).Error(ctx, "goroutine panic", "panic", fmt.Sprint(recovered), "stack", string(debug.Stack()))
+}
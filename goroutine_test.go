@@ -0,0 +1,63 @@
+package golitekit
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hansir-hsj/GoLiteKit/logger"
+)
+
+func TestGoRecoversPanicWithLogID(t *testing.T) {
+	dir := t.TempDir()
+	conf := filepath.Join(dir, "logger.toml")
+	content := "[logger]\ndir = \"" + filepath.ToSlash(dir) + "\"\nrotateRule = \"no\"\n"
+	if err := os.WriteFile(conf, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	panicLog, err := logger.NewPanicLogger(conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer panicLog.Close()
+
+	ctx, cancel := context.WithCancel(logger.WithLoggerContext(withContext(context.Background())))
+	GetContext(ctx).setContextOptions(withPanicLogger(panicLog))
+	SetLogID(ctx, "abc123")
+	logger.AddInfo(ctx, "logid", "abc123")
+	cancel()
+
+	done := make(chan error, 1)
+	Go(ctx, func(ctx context.Context) {
+		defer close(done)
+		done <- ctx.Err()
+		if EnsureLogID(ctx) != "abc123" {
+			t.Errorf("logID = %q, want abc123", EnsureLogID(ctx))
+		}
+		panic("boom")
+	})
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("goroutine context err = %v, want it detached from cancellation", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("goroutine did not run")
+	}
+
+	path := filepath.Join(dir, "panic.log")
+	deadline := time.Now().Add(time.Second)
+	for {
+		data, _ := os.ReadFile(path)
+		if strings.Contains(string(data), "Recover from panic: boom logid=abc123") {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("panic log = %q, want the panic with its logid", data)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
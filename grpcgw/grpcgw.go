@@ -0,0 +1,180 @@
+// Package grpcgw mounts grpc-gateway handlers into the golitekit middleware
+// pipeline and translates errors between AppError and gRPC statuses, so REST
+// clients (through the gateway) and gRPC clients share one implementation.
+//
+//	mux := grpcgw.NewServeMux()
+//	pb.RegisterItemsHandlerServer(ctx, mux, itemsServer) // in-process, no network hop
+//	grpcgw.Mount(app, "/v1/", mux)
+//
+//	srv := grpc.NewServer(grpc.UnaryInterceptor(grpcgw.UnaryServerInterceptor()))
+//	pb.RegisterItemsServer(srv, itemsServer)
+//
+// Service methods can return *golitekit.AppError (ErrNotFound, ErrConflict,
+// ...) or gRPC status errors; both reach gRPC clients as statuses and REST
+// clients as the usual Response envelope rendered by ErrorHandlerMiddleware.
+package grpcgw
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	glk "github.com/hansir-hsj/GoLiteKit"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// LogIDMetadataKey carries the golitekit log ID in gRPC metadata.
+const LogIDMetadataKey = "x-log-id"
+
+// Registrar is a router that registers a handler for every method: *App,
+// *Router or *RouterGroup.
+type Registrar interface {
+	Any(path string, c any)
+}
+
+// NewServeMux returns a gateway mux whose errors are returned to the
+// golitekit pipeline when mounted with Mount and whose calls carry the log ID
+// as LogIDMetadataKey metadata. opts are applied after these defaults.
+func NewServeMux(opts ...runtime.ServeMuxOption) *runtime.ServeMux {
+	defaults := []runtime.ServeMuxOption{
+		runtime.WithErrorHandler(errorHandler),
+		runtime.WithMetadata(func(ctx context.Context, r *http.Request) metadata.MD {
+			if logID := glk.EnsureLogID(r.Context()); logID != "" {
+				return metadata.Pairs(LogIDMetadataKey, logID)
+			}
+			return nil
+		}),
+	}
+	return runtime.NewServeMux(append(defaults, opts...)...)
+}
+
+// Mount registers mux under the subtree pattern prefix (e.g. "/v1/") for all
+// methods, behind the router's middlewares. The gateway matches the full
+// request path, so prefix must be a prefix of the paths in its proto
+// annotations. Errors of a mux built with NewServeMux are returned as
+// *AppError for ErrorHandlerMiddleware to render.
+func Mount(r Registrar, prefix string, mux http.Handler) {
+	r.Any(prefix, glk.HandlerFunc(func(ctx *glk.Context) error {
+		var failure error
+		req := ctx.Request()
+		req = req.WithContext(context.WithValue(req.Context(), errorSlotKey{}, &failure))
+		mux.ServeHTTP(ctx.ResponseWriter(), req)
+		return failure
+	}))
+}
+
+type errorSlotKey struct{}
+
+// errorHandler hands the error to Mount when the mux is mounted, and otherwise
+// writes it with the gateway's default handler.
+func errorHandler(ctx context.Context, mux *runtime.ServeMux, m runtime.Marshaler, w http.ResponseWriter, r *http.Request, err error) {
+	if slot, ok := r.Context().Value(errorSlotKey{}).(*error); ok {
+		*slot = FromError(err)
+		return
+	}
+	runtime.DefaultHTTPErrorHandler(ctx, mux, m, w, r, ToStatus(err).Err())
+}
+
+// FromError converts a gRPC status error to an AppError with the matching
+// HTTP status. An *AppError in err's chain is returned as is; other errors
+// become a 500 whose message is not exposed, as with glk.WrapError.
+func FromError(err error) *glk.AppError {
+	if err == nil {
+		return nil
+	}
+	var appErr *glk.AppError
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+	st, ok := status.FromError(err)
+	if !ok {
+		return glk.WrapError(err, http.StatusInternalServerError)
+	}
+	code := runtime.HTTPStatusFromCode(st.Code())
+	msg := st.Message()
+	if code >= 500 {
+		msg = http.StatusText(code)
+	}
+	return glk.NewAppError(code, msg, err)
+}
+
+// ToStatus converts err to a gRPC status: an *AppError maps its HTTP status
+// with CodeFromHTTPStatus, a status error is returned unchanged and any other
+// error becomes codes.Internal without its message.
+func ToStatus(err error) *status.Status {
+	if err == nil {
+		return nil
+	}
+	var appErr *glk.AppError
+	if errors.As(err, &appErr) {
+		return status.New(CodeFromHTTPStatus(appErr.Code), appErr.Message)
+	}
+	if st, ok := status.FromError(err); ok {
+		return st
+	}
+	return status.New(codes.Internal, http.StatusText(http.StatusInternalServerError))
+}
+
+// CodeFromHTTPStatus is the inverse of runtime.HTTPStatusFromCode; statuses
+// without a gRPC counterpart map to codes.Unknown (4xx) or codes.Internal.
+func CodeFromHTTPStatus(code int) codes.Code {
+	switch code {
+	case http.StatusOK:
+		return codes.OK
+	case http.StatusBadRequest:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusMethodNotAllowed, http.StatusNotImplemented:
+		return codes.Unimplemented
+	case http.StatusRequestTimeout, http.StatusGatewayTimeout:
+		return codes.DeadlineExceeded
+	case http.StatusConflict:
+		return codes.AlreadyExists
+	case http.StatusPreconditionFailed:
+		return codes.FailedPrecondition
+	case http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case 499: // client closed request
+		return codes.Canceled
+	case http.StatusServiceUnavailable:
+		return codes.Unavailable
+	}
+	if code >= 200 && code < 300 {
+		return codes.OK
+	}
+	if code >= 400 && code < 500 {
+		return codes.Unknown
+	}
+	return codes.Internal
+}
+
+// UnaryServerInterceptor converts errors returned by unary handlers with
+// ToStatus, so AppErrors reach gRPC clients with a meaningful code.
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		resp, err := handler(ctx, req)
+		if err != nil {
+			return resp, ToStatus(err).Err()
+		}
+		return resp, nil
+	}
+}
+
+// StreamServerInterceptor is the streaming counterpart of UnaryServerInterceptor.
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := handler(srv, ss); err != nil {
+			return ToStatus(err).Err()
+		}
+		return nil
+	}
+}
@@ -0,0 +1,133 @@
+package grpcgw
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	glk "github.com/hansir-hsj/GoLiteKit"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestMount_ErrorsFlowThroughPipeline(t *testing.T) {
+	mux := NewServeMux()
+	var md metadata.MD
+	err := mux.HandlePath(http.MethodGet, "/v1/items/{id}", func(w http.ResponseWriter, r *http.Request, params map[string]string) {
+		var fail error
+		switch params["id"] {
+		case "missing":
+			fail = status.Error(codes.NotFound, "item missing")
+		case "taken":
+			fail = glk.ErrConflict("item exists", nil)
+		case "boom":
+			fail = status.Error(codes.Internal, "db password leaked")
+		}
+		if fail != nil {
+			runtime.HTTPError(r.Context(), mux, &runtime.JSONPb{}, w, r, fail)
+			return
+		}
+		ctx, _ := runtime.AnnotateIncomingContext(r.Context(), mux, r, "/items.Items/Get", runtime.WithHTTPPathPattern("/v1/items/{id}"))
+		md, _ = metadata.FromIncomingContext(ctx)
+		w.Write([]byte(`{"id":"` + params["id"] + `"}`))
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	app := glk.NewApp()
+	app.Use(glk.ErrorHandlerMiddleware(), glk.LogIDMiddleware(), func(next glk.Handler) glk.Handler {
+		return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			w.Header().Set("X-Pipeline", "yes")
+			return next(ctx, w, r)
+		}
+	})
+	Mount(app, "/v1/", mux)
+
+	serve := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		app.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+
+	rec := serve("/v1/items/7")
+	if rec.Code != http.StatusOK || rec.Body.String() != `{"id":"7"}` || rec.Header().Get("X-Pipeline") != "yes" {
+		t.Fatalf("ok = %d %q %v", rec.Code, rec.Body, rec.Header())
+	}
+	if logID := rec.Header().Get(glk.LogIDHeader); logID == "" || len(md.Get(LogIDMetadataKey)) != 1 || md.Get(LogIDMetadataKey)[0] != logID {
+		t.Errorf("log id %q not forwarded as metadata: %v", logID, md)
+	}
+
+	for path, want := range map[string]struct {
+		code int
+		msg  string
+	}{
+		"/v1/items/missing": {http.StatusNotFound, "item missing"},
+		"/v1/items/taken":   {http.StatusConflict, "item exists"},
+		"/v1/items/boom":    {http.StatusInternalServerError, "Internal Server Error"},
+		"/v1/unknown":       {http.StatusNotFound, "Not Found"},
+	} {
+		rec := serve(path)
+		var resp glk.Response
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("%s: decode %q: %v", path, rec.Body, err)
+		}
+		if rec.Code != want.code || resp.Status != want.code || resp.Msg != want.msg {
+			t.Errorf("%s = %d %+v, want %d %q", path, rec.Code, resp, want.code, want.msg)
+		}
+	}
+}
+
+func TestErrorTranslation(t *testing.T) {
+	for _, tc := range []struct {
+		http int
+		code codes.Code
+	}{
+		{http.StatusBadRequest, codes.InvalidArgument},
+		{http.StatusUnauthorized, codes.Unauthenticated},
+		{http.StatusForbidden, codes.PermissionDenied},
+		{http.StatusNotFound, codes.NotFound},
+		{http.StatusConflict, codes.AlreadyExists},
+		{http.StatusTooManyRequests, codes.ResourceExhausted},
+		{http.StatusServiceUnavailable, codes.Unavailable},
+		{http.StatusGatewayTimeout, codes.DeadlineExceeded},
+		{http.StatusNotImplemented, codes.Unimplemented},
+		{http.StatusInternalServerError, codes.Internal},
+	} {
+		st := ToStatus(glk.NewAppError(tc.http, "m", nil))
+		if st.Code() != tc.code || st.Message() != "m" {
+			t.Errorf("ToStatus(%d) = %v, want %v", tc.http, st, tc.code)
+		}
+		if back := FromError(st.Err()); back.Code != tc.http {
+			t.Errorf("FromError(%v) = %d, want %d", tc.code, back.Code, tc.http)
+		}
+	}
+	if st := ToStatus(errors.New("secret")); st.Code() != codes.Internal || st.Message() == "secret" {
+		t.Errorf("plain error = %v", st)
+	}
+	if e := FromError(errors.New("secret")); e.Code != http.StatusInternalServerError || e.Message == "secret" {
+		t.Errorf("plain error = %+v", e)
+	}
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	intercept := UnaryServerInterceptor()
+	_, err := intercept(context.Background(), nil, &grpc.UnaryServerInfo{}, func(ctx context.Context, req any) (any, error) {
+		return nil, glk.ErrNotFound("no such item", nil)
+	})
+	if st, _ := status.FromError(err); st.Code() != codes.NotFound || st.Message() != "no such item" {
+		t.Errorf("status = %v", st)
+	}
+	resp, err := intercept(context.Background(), nil, &grpc.UnaryServerInfo{}, func(ctx context.Context, req any) (any, error) {
+		return "ok", nil
+	})
+	if resp != "ok" || err != nil {
+		t.Errorf("resp, err = %v, %v", resp, err)
+	}
+}
@@ -0,0 +1,293 @@
+package golitekit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultHealthWindow   = time.Minute
+	DefaultHealthBuckets  = 12
+	DefaultHealthCooldown = 30 * time.Second
+)
+
+// HealthOptions configures the rolling error/panic counters and the crash-loop
+// circuit that marks the instance unready.
+type HealthOptions struct {
+	Window   time.Duration // rolling window length, defaults to DefaultHealthWindow
+	Buckets  int           // number of buckets the window is split into
+	Cooldown time.Duration // how long the instance stays unready after the circuit trips
+
+	MaxPanics    int     // trip when panics within Window reach this value; 0 disables
+	MaxErrorRate float64 // trip when 5xx/total within Window reaches this ratio; 0 disables
+	MinRequests  int     // minimum requests within Window before MaxErrorRate applies
+}
+
+// HealthSnapshot is a point-in-time view of the rolling counters.
+type HealthSnapshot struct {
+	Ready        bool      `json:"ready"`
+	Requests     int64     `json:"requests"`
+	Errors       int64     `json:"errors"`
+	Panics       int64     `json:"panics"`
+	ErrorRate    float64   `json:"error_rate"`
+	Window       string    `json:"window"`
+	TrippedUntil time.Time `json:"tripped_until,omitempty"`
+	Reason       string    `json:"reason,omitempty"`
+}
+
+type healthBucket struct {
+	start    int64
+	requests int64
+	errors   int64
+	panics   int64
+}
+
+// HealthMonitor tracks rolling request, 5xx and panic counts in-process and
+// decides readiness from them.
+type HealthMonitor struct {
+	opts HealthOptions
+
+	mu           sync.Mutex
+	buckets      []healthBucket
+	bucketSize   int64
+	trippedUntil time.Time
+	reason       string
+	now          func() time.Time
+}
+
+// NewHealthMonitor creates a HealthMonitor, filling zero-valued options with defaults.
+func NewHealthMonitor(opts ...HealthOptions) *HealthMonitor {
+	var opt HealthOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if opt.Window <= 0 {
+		opt.Window = DefaultHealthWindow
+	}
+	if opt.Buckets <= 0 {
+		opt.Buckets = DefaultHealthBuckets
+	}
+	if opt.Cooldown <= 0 {
+		opt.Cooldown = DefaultHealthCooldown
+	}
+
+	bucketSize := int64(opt.Window) / int64(opt.Buckets)
+	if bucketSize <= 0 {
+		bucketSize = 1
+	}
+	return &HealthMonitor{
+		opts:       opt,
+		buckets:    make([]healthBucket, opt.Buckets),
+		bucketSize: bucketSize,
+		now:        time.Now,
+	}
+}
+
+// RecordRequest counts a completed request; status codes >= 500 count as errors.
+func (m *HealthMonitor) RecordRequest(status int) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	b := m.bucketLocked(now)
+	b.requests++
+	if status >= http.StatusInternalServerError {
+		b.errors++
+	}
+	m.evaluateLocked(now)
+}
+
+// RecordPanic counts a recovered panic.
+func (m *HealthMonitor) RecordPanic() {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	m.bucketLocked(now).panics++
+	m.evaluateLocked(now)
+}
+
+// Ready reports whether the crash-loop circuit is closed.
+func (m *HealthMonitor) Ready() bool {
+	return m.Snapshot().Ready
+}
+
+// Snapshot returns the current rolling counters and readiness.
+func (m *HealthMonitor) Snapshot() HealthSnapshot {
+	if m == nil {
+		return HealthSnapshot{Ready: true}
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	m.evaluateLocked(now)
+	requests, errors, panics := m.totalsLocked(now)
+
+	snap := HealthSnapshot{
+		Ready:    !now.Before(m.trippedUntil),
+		Requests: requests,
+		Errors:   errors,
+		Panics:   panics,
+		Window:   m.opts.Window.String(),
+	}
+	if requests > 0 {
+		snap.ErrorRate = float64(errors) / float64(requests)
+	}
+	if !snap.Ready {
+		snap.TrippedUntil = m.trippedUntil
+		snap.Reason = m.reason
+	}
+	return snap
+}
+
+// Reset clears all counters and closes the circuit.
+func (m *HealthMonitor) Reset() {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i := range m.buckets {
+		m.buckets[i] = healthBucket{}
+	}
+	m.trippedUntil = time.Time{}
+	m.reason = ""
+}
+
+func (m *HealthMonitor) bucketLocked(now time.Time) *healthBucket {
+	start := now.UnixNano() / m.bucketSize
+	b := &m.buckets[start%int64(len(m.buckets))]
+	if b.start != start {
+		*b = healthBucket{start: start}
+	}
+	return b
+}
+
+func (m *HealthMonitor) totalsLocked(now time.Time) (requests, errors, panics int64) {
+	current := now.UnixNano() / m.bucketSize
+	oldest := current - int64(len(m.buckets)) + 1
+	for _, b := range m.buckets {
+		if b.start < oldest || b.start > current {
+			continue
+		}
+		requests += b.requests
+		errors += b.errors
+		panics += b.panics
+	}
+	return
+}
+
+func (m *HealthMonitor) evaluateLocked(now time.Time) {
+	requests, errors, panics := m.totalsLocked(now)
+
+	reason := ""
+	if m.opts.MaxPanics > 0 && panics >= int64(m.opts.MaxPanics) {
+		reason = "panic threshold exceeded"
+	} else if m.opts.MaxErrorRate > 0 && requests > 0 && requests >= int64(m.opts.MinRequests) &&
+		float64(errors)/float64(requests) >= m.opts.MaxErrorRate {
+		reason = "error rate threshold exceeded"
+	}
+	if reason == "" {
+		return
+	}
+	m.trippedUntil = now.Add(m.opts.Cooldown)
+	m.reason = reason
+}
+
+// Middleware records the final status of every request. Install it outside
+// ErrorHandlerMiddleware so handled errors and recovered panics are observed.
+func (m *HealthMonitor) Middleware() Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			sw := &healthStatusWriter{ResponseWriter: w, statusCode: http.StatusOK}
+			err := next(ctx, sw, r)
+			status := sw.statusCode
+			if err != nil {
+				status = ClassifyError(err).Code
+			}
+			m.RecordRequest(status)
+			return err
+		}
+	}
+}
+
+type healthStatusWriter struct {
+	http.ResponseWriter
+	statusCode    int
+	headerWritten bool
+}
+
+func (w *healthStatusWriter) WriteHeader(code int) {
+	if !w.headerWritten && !isInformational(code) {
+		w.headerWritten = true
+		w.statusCode = code
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *healthStatusWriter) Write(b []byte) (int, error) {
+	w.headerWritten = true
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *healthStatusWriter) Flush() {
+	_ = w.FlushError()
+}
+
+// FlushError is Flush reporting the error of the underlying writer.
+func (w *healthStatusWriter) FlushError() error {
+	return http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+func (w *healthStatusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// HealthRouteOptions configures health route mounting.
+type HealthRouteOptions struct {
+	Prefix string // URL prefix, defaults to "" (routes are /healthz and /readyz)
+}
+
+// MountHealth registers /healthz (liveness) and /readyz (readiness with counter
+// detail) on the router. /readyz returns 503 while the crash-loop circuit is open.
+func (r *Router) MountHealth(m *HealthMonitor, opts ...HealthRouteOptions) {
+	var opt HealthRouteOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	prefix := strings.TrimRight(opt.Prefix, "/")
+
+	r.routesRegistered = true
+	r.recordRoute(http.MethodGet, prefix+"/healthz", "health")
+	r.recordRoute(http.MethodGet, prefix+"/readyz", "health")
+	r.mux.Handle(http.MethodGet+" "+prefix+"/healthz", http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		writeHealthJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+	}))
+	r.mux.Handle(http.MethodGet+" "+prefix+"/readyz", http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		snap := m.Snapshot()
+		status := http.StatusOK
+		if !snap.Ready {
+			status = http.StatusServiceUnavailable
+		}
+		writeHealthJSON(w, status, snap)
+	}))
+}
+
+func writeHealthJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,134 @@
+package golitekit
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func newTestHealthMonitor(opts HealthOptions) (*HealthMonitor, *time.Time) {
+	m := NewHealthMonitor(opts)
+	now := time.Unix(1_700_000_000, 0)
+	m.now = func() time.Time { return now }
+	return m, &now
+}
+
+func TestHealthMonitor_CountsRequestsErrorsAndPanics(t *testing.T) {
+	m, _ := newTestHealthMonitor(HealthOptions{})
+	m.RecordRequest(http.StatusOK)
+	m.RecordRequest(http.StatusBadRequest)
+	m.RecordRequest(http.StatusInternalServerError)
+	m.RecordPanic()
+
+	snap := m.Snapshot()
+	if snap.Requests != 3 || snap.Errors != 1 || snap.Panics != 1 {
+		t.Fatalf("snapshot = %+v, want requests=3 errors=1 panics=1", snap)
+	}
+	if !snap.Ready {
+		t.Fatal("expected ready without thresholds configured")
+	}
+}
+
+func TestHealthMonitor_WindowExpiresOldBuckets(t *testing.T) {
+	m, now := newTestHealthMonitor(HealthOptions{Window: 10 * time.Second, Buckets: 10})
+	m.RecordRequest(http.StatusInternalServerError)
+
+	*now = now.Add(11 * time.Second)
+	if snap := m.Snapshot(); snap.Requests != 0 || snap.Errors != 0 {
+		t.Fatalf("snapshot = %+v, want counters expired", snap)
+	}
+}
+
+func TestHealthMonitor_PanicThresholdTripsCircuit(t *testing.T) {
+	m, now := newTestHealthMonitor(HealthOptions{
+		Window:    10 * time.Second,
+		MaxPanics: 2,
+		Cooldown:  30 * time.Second,
+	})
+	m.RecordPanic()
+	if !m.Ready() {
+		t.Fatal("expected ready below panic threshold")
+	}
+	m.RecordPanic()
+	snap := m.Snapshot()
+	if snap.Ready {
+		t.Fatal("expected unready at panic threshold")
+	}
+	if snap.Reason == "" {
+		t.Fatal("expected trip reason")
+	}
+
+	*now = now.Add(31 * time.Second)
+	if !m.Ready() {
+		t.Fatal("expected ready after cooldown and window expiry")
+	}
+}
+
+func TestHealthMonitor_ErrorRateRequiresMinRequests(t *testing.T) {
+	m, _ := newTestHealthMonitor(HealthOptions{MaxErrorRate: 0.5, MinRequests: 4})
+	m.RecordRequest(http.StatusInternalServerError)
+	m.RecordRequest(http.StatusInternalServerError)
+	if !m.Ready() {
+		t.Fatal("expected ready below MinRequests")
+	}
+	m.RecordRequest(http.StatusOK)
+	m.RecordRequest(http.StatusOK)
+	if m.Ready() {
+		t.Fatal("expected unready at 50% error rate")
+	}
+
+	m.Reset()
+	if !m.Ready() {
+		t.Fatal("expected ready after Reset")
+	}
+}
+
+func TestApp_HealthMonitorRecordsHandledErrorsAndPanics(t *testing.T) {
+	monitor := NewHealthMonitor(HealthOptions{MaxPanics: 1})
+	app := NewApp(WithHealthMonitor(monitor))
+	app.GET("/fail", HandlerFunc(func(ctx *Context) error {
+		return ErrInternal("boom", nil)
+	}))
+	app.GET("/panic", HandlerFunc(func(ctx *Context) error {
+		panic("boom")
+	}))
+	app.MountHealth()
+
+	for _, path := range []string{"/fail", "/panic"} {
+		rec := httptest.NewRecorder()
+		app.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != http.StatusInternalServerError {
+			t.Fatalf("%s status = %d, want 500", path, rec.Code)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	app.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("readyz status = %d, want 503", rec.Code)
+	}
+	var snap HealthSnapshot
+	if err := json.Unmarshal(rec.Body.Bytes(), &snap); err != nil {
+		t.Fatalf("decode readyz: %v", err)
+	}
+	if snap.Requests != 2 || snap.Errors != 2 || snap.Panics != 1 {
+		t.Fatalf("snapshot = %+v, want requests=2 errors=2 panics=1", snap)
+	}
+
+	rec = httptest.NewRecorder()
+	app.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("healthz status = %d, want 200", rec.Code)
+	}
+}
+
+func TestApp_MountHealthRequiresMonitor(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected MountHealth to panic without a monitor")
+		}
+	}()
+	NewApp().MountHealth()
+}
@@ -0,0 +1,191 @@
+package golitekit
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const DefaultLocale = "en"
+
+// Catalog holds translated messages keyed by locale and message key.
+// Lookups walk a fallback chain: the requested locale ("pt-br"), its base
+// language ("pt"), then the catalog's fallback locale. It is safe for
+// concurrent use.
+type Catalog struct {
+	mu       sync.RWMutex
+	fallback string
+	messages map[string]map[string]string
+}
+
+// NewCatalog creates a catalog seeded with the built-in validation messages.
+// fallback is the locale used when none of the requested locales has a key;
+// it defaults to DefaultLocale.
+func NewCatalog(fallback ...string) *Catalog {
+	c := &Catalog{fallback: DefaultLocale, messages: make(map[string]map[string]string)}
+	if len(fallback) > 0 && fallback[0] != "" {
+		c.fallback = normalizeLocale(fallback[0])
+	}
+	for locale, messages := range defaultValidationMessages {
+		c.Add(locale, messages)
+	}
+	return c
+}
+
+// Add merges messages into locale, overriding existing keys.
+func (c *Catalog) Add(locale string, messages map[string]string) *Catalog {
+	locale = normalizeLocale(locale)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	m := c.messages[locale]
+	if m == nil {
+		m = make(map[string]string, len(messages))
+		c.messages[locale] = m
+	}
+	for k, v := range messages {
+		m[k] = v
+	}
+	return c
+}
+
+// Lookup returns the message for key, trying each locale's fallback chain in
+// order.
+func (c *Catalog) Lookup(locales []string, key string) (string, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for _, locale := range c.chain(locales) {
+		if msg, ok := c.messages[locale][key]; ok {
+			return msg, true
+		}
+	}
+	return "", false
+}
+
+// Translate looks up key and replaces {name} placeholders with args. Unknown
+// keys are returned unchanged so a missing translation is visible but harmless.
+func (c *Catalog) Translate(locales []string, key string, args map[string]string) string {
+	msg, ok := c.Lookup(locales, key)
+	if !ok {
+		msg = key
+	}
+	if len(args) == 0 {
+		return msg
+	}
+	pairs := make([]string, 0, len(args)*2)
+	for k, v := range args {
+		pairs = append(pairs, "{"+k+"}", v)
+	}
+	return strings.NewReplacer(pairs...).Replace(msg)
+}
+
+func (c *Catalog) chain(locales []string) []string {
+	chain := make([]string, 0, len(locales)*2+1)
+	seen := make(map[string]bool, cap(chain))
+	add := func(locale string) {
+		if locale != "" && !seen[locale] {
+			seen[locale] = true
+			chain = append(chain, locale)
+		}
+	}
+	for _, locale := range locales {
+		locale = normalizeLocale(locale)
+		add(locale)
+		if base, _, ok := strings.Cut(locale, "-"); ok {
+			add(base)
+		}
+	}
+	add(c.fallback)
+	return chain
+}
+
+func normalizeLocale(locale string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(locale), "_", "-"))
+}
+
+var (
+	defaultCatalogOnce sync.Once
+	defaultCatalog     *Catalog
+)
+
+// DefaultCatalog returns the shared catalog used when no WithCatalog service
+// is installed. Messages added to it apply process-wide.
+func DefaultCatalog() *Catalog {
+	defaultCatalogOnce.Do(func() { defaultCatalog = NewCatalog() })
+	return defaultCatalog
+}
+
+// WithCatalog installs the message catalog used for request-locale translation.
+func WithCatalog(c *Catalog) ServiceOption {
+	return func(s *Services) { s.catalog = c }
+}
+
+// ParseAcceptLanguage returns the locales of an Accept-Language header ordered
+// by descending quality. Wildcards and q=0 entries are dropped.
+func ParseAcceptLanguage(header string) []string {
+	type weighted struct {
+		locale string
+		q      float64
+	}
+	var tags []weighted
+	for _, part := range strings.Split(header, ",") {
+		locale, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		locale = normalizeLocale(locale)
+		if locale == "" || locale == "*" {
+			continue
+		}
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if q <= 0 {
+			continue
+		}
+		tags = append(tags, weighted{locale, q})
+	}
+	sort.SliceStable(tags, func(i, j int) bool { return tags[i].q > tags[j].q })
+	locales := make([]string, len(tags))
+	for i, t := range tags {
+		locales[i] = t.locale
+	}
+	return locales
+}
+
+// RequestLocales returns the preferred locales of r: the "lang" query
+// parameter first, then Accept-Language.
+func RequestLocales(r *http.Request) []string {
+	if r == nil {
+		return nil
+	}
+	locales := ParseAcceptLanguage(r.Header.Get("Accept-Language"))
+	if lang := r.URL.Query().Get("lang"); lang != "" {
+		locales = append([]string{normalizeLocale(lang)}, locales...)
+	}
+	return locales
+}
+
+var defaultValidationMessages = map[string]map[string]string{
+	"en": {
+		"validation.failed":   "Validation failed",
+		"validation.required": "{field} is required",
+		"validation.min":      "{field} must be at least {param}",
+		"validation.max":      "{field} must be at most {param}",
+		"validation.len":      "{field} must have length {param}",
+		"validation.email":    "{field} must be a valid email address",
+		"validation.oneof":    "{field} must be one of [{param}]",
+	},
+	"zh": {
+		"validation.failed":   "参数校验失败",
+		"validation.required": "{field}不能为空",
+		"validation.min":      "{field}不能小于{param}",
+		"validation.max":      "{field}不能大于{param}",
+		"validation.len":      "{field}长度必须为{param}",
+		"validation.email":    "{field}必须是有效的邮箱地址",
+		"validation.oneof":    "{field}必须是[{param}]之一",
+	},
+}
@@ -0,0 +1,41 @@
+package golitekit
+
+import (
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestParseAcceptLanguage(t *testing.T) {
+	got := ParseAcceptLanguage("fr;q=0.5, en_US, *, de;q=0, zh-CN;q=0.8")
+	want := []string{"en-us", "zh-cn", "fr"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("ParseAcceptLanguage = %v, want %v", got, want)
+	}
+
+	r := httptest.NewRequest("GET", "/?lang=ja", nil)
+	r.Header.Set("Accept-Language", "en")
+	if got := RequestLocales(r); !reflect.DeepEqual(got, []string{"ja", "en"}) {
+		t.Fatalf("RequestLocales = %v", got)
+	}
+}
+
+func TestCatalog_FallbackChain(t *testing.T) {
+	c := NewCatalog("en").
+		Add("en", map[string]string{"greet": "Hello {name}", "bye": "Bye"}).
+		Add("pt", map[string]string{"greet": "Olá {name}"}).
+		Add("pt-BR", map[string]string{"bye": "Tchau"})
+
+	if got := c.Translate([]string{"pt-BR"}, "greet", map[string]string{"name": "Ana"}); got != "Olá Ana" {
+		t.Fatalf("base language fallback = %q", got)
+	}
+	if got := c.Translate([]string{"pt-br"}, "bye", nil); got != "Tchau" {
+		t.Fatalf("exact locale = %q", got)
+	}
+	if got := c.Translate([]string{"de"}, "bye", nil); got != "Bye" {
+		t.Fatalf("catalog fallback = %q", got)
+	}
+	if got := c.Translate([]string{"de"}, "missing.key", nil); got != "missing.key" {
+		t.Fatalf("missing key = %q", got)
+	}
+}
@@ -0,0 +1,416 @@
+package golitekit
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayedHeader is set to "true" on replayed responses.
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+
+	DefaultIdempotencyTTL      = 24 * time.Hour
+	DefaultIdempotencyLockTTL  = time.Minute
+	DefaultIdempotencyWait     = 10 * time.Second
+	DefaultIdempotencyMaxBody  = DefaultMaxBodySize
+	MaxIdempotencyKeyLen       = 255
+	idempotencyPollInterval    = 50 * time.Millisecond
+	idempotencyMemorySweepRate = time.Minute
+)
+
+// IdempotentResponse is a stored response replayed for retries.
+type IdempotentResponse struct {
+	Status      int         `json:"status"`
+	Header      http.Header `json:"header,omitempty"`
+	Body        []byte      `json:"body,omitempty"`
+	Fingerprint string      `json:"fingerprint"`
+}
+
+// IdempotencyStore keeps the responses of idempotent requests and the
+// claims of requests still running.
+type IdempotencyStore interface {
+	// Begin claims key for lockTTL. It returns the stored response when the
+	// key completed before, or acquired=false when another request holds
+	// the claim.
+	Begin(ctx context.Context, key string, lockTTL time.Duration) (resp *IdempotentResponse, acquired bool, err error)
+	// Complete stores resp under key for ttl, replacing the claim.
+	Complete(ctx context.Context, key string, resp *IdempotentResponse, ttl time.Duration) error
+	// Renew extends the claim of key to lockTTL from now; it does nothing
+	// when key is no longer claimed.
+	Renew(ctx context.Context, key string, lockTTL time.Duration) error
+	// Release drops the claim of key so a retry runs the request again.
+	Release(ctx context.Context, key string) error
+}
+
+// IdempotencyOptions configures IdempotencyMiddleware.
+type IdempotencyOptions struct {
+	Store IdempotencyStore // defaults to a MemoryIdempotencyStore
+	TTL   time.Duration    // how long responses are replayed, defaults to DefaultIdempotencyTTL
+	// LockTTL is how long a claim outlives a crashed request, defaults to
+	// DefaultIdempotencyLockTTL. The claim of a running request is renewed
+	// every LockTTL/3, so requests may run longer than LockTTL.
+	LockTTL time.Duration
+	// MaxBody caps the request body buffered for the fingerprint, defaults
+	// to DefaultIdempotencyMaxBody; larger requests get 413.
+	MaxBody int64
+	// Wait bounds how long a retry waits for the request holding its key;
+	// it then gets 409. Defaults to DefaultIdempotencyWait.
+	Wait time.Duration
+	// Methods are the methods keys apply to, defaults to POST and PATCH.
+	Methods []string
+	// Scope namespaces keys per client, e.g. by user ID, so clients cannot
+	// replay each other's responses.
+	Scope func(r *http.Request) string
+}
+
+// IdempotencyMiddleware makes requests carrying Idempotency-Key safe to
+// retry: the first request runs and its response is stored; retries with
+// the same key within TTL get that response again, and retries arriving
+// while it runs wait for it. Reusing a key for another method, path, query or
+// body is rejected with 422. A request that returns an error, writes a 5xx or
+// writes no response is not stored, so its retry runs again.
+func IdempotencyMiddleware(opts ...IdempotencyOptions) Middleware {
+	var opt IdempotencyOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if opt.Store == nil {
+		opt.Store = NewMemoryIdempotencyStore()
+	}
+	if opt.TTL <= 0 {
+		opt.TTL = DefaultIdempotencyTTL
+	}
+	if opt.LockTTL <= 0 {
+		opt.LockTTL = DefaultIdempotencyLockTTL
+	}
+	if opt.Wait <= 0 {
+		opt.Wait = DefaultIdempotencyWait
+	}
+	if opt.MaxBody <= 0 {
+		opt.MaxBody = DefaultIdempotencyMaxBody
+	}
+	if len(opt.Methods) == 0 {
+		opt.Methods = []string{http.MethodPost, http.MethodPatch}
+	}
+
+	return func(next Handler) Handler {
+		return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			key := r.Header.Get(IdempotencyKeyHeader)
+			if key == "" || !slices.Contains(opt.Methods, r.Method) {
+				return next(ctx, w, r)
+			}
+			if len(key) > MaxIdempotencyKeyLen {
+				return ErrBadRequest("Idempotency-Key too long", nil)
+			}
+			if opt.Scope != nil {
+				key = opt.Scope(r) + ":" + key
+			}
+
+			body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, opt.MaxBody))
+			if err != nil {
+				var tooLarge *http.MaxBytesError
+				if errors.As(err, &tooLarge) {
+					return NewAppError(http.StatusRequestEntityTooLarge, "Request body too large", err)
+				}
+				return ErrBadRequest("Failed to read request body", err)
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+			fingerprint := idempotencyFingerprint(r, body)
+
+			resp, err := awaitIdempotencyKey(ctx, opt, key)
+			if err != nil {
+				return err
+			}
+			if resp != nil {
+				if resp.Fingerprint != fingerprint {
+					return NewAppError(http.StatusUnprocessableEntity, "Idempotency-Key reused with a different request", nil)
+				}
+				replayIdempotentResponse(w, resp)
+				return nil
+			}
+
+			rec := &capturingWriter{ResponseWriter: w, status: http.StatusOK}
+			stopRenew := renewIdempotencyClaim(ctx, opt, key)
+			err = next(ctx, rec, r)
+			if err == nil {
+				err = flushResponse(ctx, rec, r)
+			}
+			stopRenew()
+			// the claim must be settled even when the client went away
+			storeCtx := context.WithoutCancel(ctx)
+			if err != nil || !rec.wroteHeader || rec.status >= http.StatusInternalServerError {
+				_ = opt.Store.Release(storeCtx, key)
+				return err
+			}
+			resp = &IdempotentResponse{
+				Status:      rec.status,
+				Header:      rec.header,
+				Body:        rec.body.Bytes(),
+				Fingerprint: fingerprint,
+			}
+			if resp.Header == nil {
+				resp.Header = w.Header().Clone()
+			}
+			if err := opt.Store.Complete(storeCtx, key, resp, opt.TTL); err != nil {
+				_ = opt.Store.Release(storeCtx, key)
+			}
+			return nil
+		}
+	}
+}
+
+// awaitIdempotencyKey claims key, or waits for the request holding it and
+// returns its response.
+func awaitIdempotencyKey(ctx context.Context, opt IdempotencyOptions, key string) (*IdempotentResponse, error) {
+	deadline := time.Now().Add(opt.Wait)
+	for {
+		resp, acquired, err := opt.Store.Begin(ctx, key, opt.LockTTL)
+		if err != nil {
+			return nil, ErrServiceUnavailable("Idempotency store unavailable", err)
+		}
+		if acquired || resp != nil {
+			return resp, nil
+		}
+		if time.Now().After(deadline) {
+			return nil, ErrConflict("A request with this Idempotency-Key is in progress", nil)
+		}
+		timer := time.NewTimer(idempotencyPollInterval)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ErrConflict("A request with this Idempotency-Key is in progress", ctx.Err())
+		}
+	}
+}
+
+// renewIdempotencyClaim renews the claim of key every LockTTL/3 until the
+// returned stop is called, so a request running longer than LockTTL is not
+// run again by a retry.
+func renewIdempotencyClaim(ctx context.Context, opt IdempotencyOptions, key string) (stop func()) {
+	ctx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(opt.LockTTL / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				_ = opt.Store.Renew(ctx, key, opt.LockTTL)
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+func idempotencyFingerprint(r *http.Request, body []byte) string {
+	h := sha256.New()
+	io.WriteString(h, r.Method+" "+r.URL.Path+"?"+r.URL.RawQuery+"\n")
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func replayIdempotentResponse(w http.ResponseWriter, resp *IdempotentResponse) {
+	w.Header().Set(IdempotentReplayedHeader, "true")
+	replayResponse(w, resp.Status, resp.Header, resp.Body)
+}
+
+// replayResponse writes a response captured by a capturingWriter.
+func replayResponse(w http.ResponseWriter, status int, header http.Header, body []byte) {
+	for k, v := range header {
+		w.Header()[k] = v
+	}
+	w.WriteHeader(status)
+	_, _ = w.Write(body)
+}
+
+// capturingWriter copies the response it passes through. With limit > 0 it
+// stops copying a body larger than limit and sets overflow.
+type capturingWriter struct {
+	http.ResponseWriter
+	status      int
+	header      http.Header
+	wroteHeader bool
+	body        bytes.Buffer
+	limit       int
+	overflow    bool
+}
+
+func (w *capturingWriter) WriteHeader(code int) {
+	if !w.wroteHeader && !isInformational(code) {
+		w.wroteHeader = true
+		w.status = code
+		w.header = w.ResponseWriter.Header().Clone()
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *capturingWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.limit > 0 && w.body.Len()+len(b) > w.limit {
+		w.overflow = true
+		w.body.Reset()
+	}
+	if !w.overflow {
+		w.body.Write(b)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *capturingWriter) Flush() {
+	_ = w.FlushError()
+}
+
+// FlushError is Flush reporting the error of the underlying writer.
+func (w *capturingWriter) FlushError() error {
+	return http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+func (w *capturingWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+type idempotencyEntry struct {
+	resp    *IdempotentResponse // nil while claimed
+	expires time.Time
+}
+
+// MemoryIdempotencyStore is an in-process IdempotencyStore, for a single
+// instance or tests.
+type MemoryIdempotencyStore struct {
+	mu        sync.Mutex
+	entries   map[string]idempotencyEntry
+	lastSweep time.Time
+}
+
+// NewMemoryIdempotencyStore creates an empty MemoryIdempotencyStore.
+func NewMemoryIdempotencyStore() *MemoryIdempotencyStore {
+	return &MemoryIdempotencyStore{entries: make(map[string]idempotencyEntry)}
+}
+
+func (s *MemoryIdempotencyStore) Begin(ctx context.Context, key string, lockTTL time.Duration) (*IdempotentResponse, bool, error) {
+	now := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if now.Sub(s.lastSweep) >= idempotencyMemorySweepRate {
+		s.lastSweep = now
+		for k, e := range s.entries {
+			if now.After(e.expires) {
+				delete(s.entries, k)
+			}
+		}
+	}
+	if e, ok := s.entries[key]; ok && now.Before(e.expires) {
+		return e.resp, false, nil
+	}
+	s.entries[key] = idempotencyEntry{expires: now.Add(lockTTL)}
+	return nil, true, nil
+}
+
+func (s *MemoryIdempotencyStore) Complete(ctx context.Context, key string, resp *IdempotentResponse, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries[key] = idempotencyEntry{resp: resp, expires: time.Now().Add(ttl)}
+	return nil
+}
+
+func (s *MemoryIdempotencyStore) Renew(ctx context.Context, key string, lockTTL time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if e, ok := s.entries[key]; ok && e.resp == nil {
+		s.entries[key] = idempotencyEntry{expires: time.Now().Add(lockTTL)}
+	}
+	return nil
+}
+
+func (s *MemoryIdempotencyStore) Release(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if e, ok := s.entries[key]; ok && e.resp == nil {
+		delete(s.entries, key)
+	}
+	return nil
+}
+
+// RedisIdempotencyStore is an IdempotencyStore shared by all replicas. A
+// claim is a key holding "pending"; a completed request holds its response
+// as JSON.
+type RedisIdempotencyStore struct {
+	client redis.UniversalClient
+	prefix string
+}
+
+const idempotencyPending = "pending"
+
+// NewRedisIdempotencyStore returns an IdempotencyStore keeping responses in
+// client under prefix, which defaults to "glk:idem:".
+func NewRedisIdempotencyStore(client redis.UniversalClient, prefix ...string) *RedisIdempotencyStore {
+	p := "glk:idem:"
+	if len(prefix) > 0 && prefix[0] != "" {
+		p = prefix[0]
+	}
+	return &RedisIdempotencyStore{client: client, prefix: p}
+}
+
+func (s *RedisIdempotencyStore) Begin(ctx context.Context, key string, lockTTL time.Duration) (*IdempotentResponse, bool, error) {
+	ok, err := s.client.SetNX(ctx, s.prefix+key, idempotencyPending, lockTTL).Result()
+	if err != nil {
+		return nil, false, err
+	}
+	if ok {
+		return nil, true, nil
+	}
+	data, err := s.client.Get(ctx, s.prefix+key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		// expired between SETNX and GET; the caller polls again
+		return nil, false, nil
+	}
+	if err != nil || string(data) == idempotencyPending {
+		return nil, false, err
+	}
+	var resp IdempotentResponse
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, false, err
+	}
+	return &resp, false, nil
+}
+
+func (s *RedisIdempotencyStore) Complete(ctx context.Context, key string, resp *IdempotentResponse, ttl time.Duration) error {
+	data, err := json.Marshal(resp)
+	if err != nil {
+		return err
+	}
+	return s.client.Set(ctx, s.prefix+key, data, ttl).Err()
+}
+
+// Renew extends the claim of key, leaving a completed response alone.
+func (s *RedisIdempotencyStore) Renew(ctx context.Context, key string, lockTTL time.Duration) error {
+	const script = `if redis.call("GET", KEYS[1]) == ARGV[1] then return redis.call("PEXPIRE", KEYS[1], ARGV[2]) end return 0`
+	return s.client.Eval(ctx, script, []string{s.prefix + key}, idempotencyPending, lockTTL.Milliseconds()).Err()
+}
+
+// Release deletes the claim of key, leaving a completed response alone.
+func (s *RedisIdempotencyStore) Release(ctx context.Context, key string) error {
+	const script = `if redis.call("GET", KEYS[1]) == ARGV[1] then return redis.call("DEL", KEYS[1]) end return 0`
+	return s.client.Eval(ctx, script, []string{s.prefix + key}, idempotencyPending).Err()
+}
@@ -0,0 +1,251 @@
+package golitekit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func newIdempotentHandler(opt IdempotencyOptions, calls *atomic.Int32, release <-chan struct{}) Handler {
+	return IdempotencyMiddleware(opt)(func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		n := calls.Add(1)
+		if release != nil {
+			<-release
+		}
+		if r.URL.Path == "/fail" {
+			return ErrInternal("boom", nil)
+		}
+		w.Header().Set("X-Order", "1")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"call":` + strconv.Itoa(int(n)) + `}`))
+		return nil
+	})
+}
+
+func serveIdempotent(h Handler, path, key, body string) (*httptest.ResponseRecorder, error) {
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	if key != "" {
+		req.Header.Set(IdempotencyKeyHeader, key)
+	}
+	rec := httptest.NewRecorder()
+	return rec, h(req.Context(), rec, req)
+}
+
+func testIdempotencyReplay(t *testing.T, store IdempotencyStore) {
+	var calls atomic.Int32
+	h := newIdempotentHandler(IdempotencyOptions{Store: store}, &calls, nil)
+
+	first, err := serveIdempotent(h, "/orders", "k1", `{"qty":1}`)
+	if err != nil || first.Code != http.StatusCreated {
+		t.Fatalf("first = %d, %v", first.Code, err)
+	}
+	retry, err := serveIdempotent(h, "/orders", "k1", `{"qty":1}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calls.Load() != 1 {
+		t.Fatalf("handler ran %d times, want 1", calls.Load())
+	}
+	if retry.Code != http.StatusCreated || retry.Body.String() != first.Body.String() ||
+		retry.Header().Get("X-Order") != "1" || retry.Header().Get(IdempotentReplayedHeader) != "true" {
+		t.Fatalf("replay = %d %q %v", retry.Code, retry.Body.String(), retry.Header())
+	}
+
+	_, err = serveIdempotent(h, "/orders", "k1", `{"qty":2}`)
+	var appErr *AppError
+	if !errors.As(err, &appErr) || appErr.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("reused key = %v, want 422", err)
+	}
+
+	_, err = serveIdempotent(h, "/orders?dry_run=1", "k1", `{"qty":1}`)
+	if !errors.As(err, &appErr) || appErr.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("reused key with another query = %v, want 422", err)
+	}
+
+	if _, err := serveIdempotent(h, "/orders", "", `{"qty":1}`); err != nil || calls.Load() != 2 {
+		t.Fatalf("request without key: %v, calls = %d", err, calls.Load())
+	}
+}
+
+func TestIdempotencyMiddleware_ReplaysMemory(t *testing.T) {
+	testIdempotencyReplay(t, NewMemoryIdempotencyStore())
+}
+
+func TestIdempotencyMiddleware_ReplaysRedis(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	testIdempotencyReplay(t, NewRedisIdempotencyStore(client))
+}
+
+func TestIdempotencyMiddleware_CoalescesInFlight(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	h := newIdempotentHandler(IdempotencyOptions{}, &calls, release)
+
+	var wg sync.WaitGroup
+	codes := make([]int, 3)
+	for i := range codes {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			rec, err := serveIdempotent(h, "/orders", "k", "{}")
+			if err != nil {
+				t.Error(err)
+			}
+			codes[i] = rec.Code
+		}()
+	}
+	waitFor(t, func() bool { return calls.Load() == 1 })
+	time.Sleep(2 * idempotencyPollInterval)
+	close(release)
+	wg.Wait()
+
+	if calls.Load() != 1 {
+		t.Fatalf("handler ran %d times, want 1", calls.Load())
+	}
+	for i, code := range codes {
+		if code != http.StatusCreated {
+			t.Errorf("request %d = %d, want 201", i, code)
+		}
+	}
+}
+
+func TestIdempotencyMiddleware_ErrorReleasesKey(t *testing.T) {
+	var calls atomic.Int32
+	h := newIdempotentHandler(IdempotencyOptions{}, &calls, nil)
+
+	for i := 0; i < 2; i++ {
+		if _, err := serveIdempotent(h, "/fail", "k", "{}"); err == nil {
+			t.Fatal("want the handler error")
+		}
+	}
+	if calls.Load() != 2 {
+		t.Fatalf("handler ran %d times, want a retry after the error", calls.Load())
+	}
+}
+
+func TestIdempotencyMiddleware_WaitTimesOut(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	defer close(release)
+	h := newIdempotentHandler(IdempotencyOptions{Wait: 60 * time.Millisecond}, &calls, release)
+
+	go serveIdempotent(h, "/orders", "k", "{}")
+	waitFor(t, func() bool { return calls.Load() == 1 })
+	_, err := serveIdempotent(h, "/orders", "k", "{}")
+	var appErr *AppError
+	if !errors.As(err, &appErr) || appErr.Code != http.StatusConflict {
+		t.Fatalf("retry while in flight = %v, want 409", err)
+	}
+}
+
+type idempotentOrderController struct {
+	BaseController
+	calls *atomic.Int32
+}
+
+func (c *idempotentOrderController) Serve(ctx context.Context) error {
+	return c.JSON(http.StatusCreated, map[string]int32{"n": c.calls.Add(1)})
+}
+
+func TestIdempotencyMiddleware_ReplaysControllerJSON(t *testing.T) {
+	var calls atomic.Int32
+	app := NewApp()
+	app.Use(IdempotencyMiddleware())
+	app.POST("/payments", &idempotentOrderController{calls: &calls})
+
+	post := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/payments", strings.NewReader(`{"amount":5}`))
+		req.Header.Set(IdempotencyKeyHeader, "pay-1")
+		rec := httptest.NewRecorder()
+		app.Handler().ServeHTTP(rec, req)
+		return rec
+	}
+	first, retry := post(), post()
+
+	if calls.Load() != 1 {
+		t.Fatalf("controller ran %d times, want 1", calls.Load())
+	}
+	if first.Code != http.StatusCreated || first.Body.String() != `{"n":1}` {
+		t.Fatalf("first response = %d %q", first.Code, first.Body.String())
+	}
+	if retry.Code != http.StatusCreated || retry.Body.String() != `{"n":1}` ||
+		retry.Header().Get(IdempotentReplayedHeader) != "true" || retry.Header().Get("Content-Type") != "application/json" {
+		t.Fatalf("replayed response = %d %q %v", retry.Code, retry.Body.String(), retry.Header())
+	}
+}
+
+func TestIdempotencyMiddleware_EmptyResponseNotStored(t *testing.T) {
+	var calls atomic.Int32
+	h := IdempotencyMiddleware()(func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		calls.Add(1)
+		return nil
+	})
+	for range 2 {
+		req := httptest.NewRequest(http.MethodPost, "/payments", nil)
+		req.Header.Set(IdempotencyKeyHeader, "pay-2")
+		if err := h(req.Context(), httptest.NewRecorder(), req); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if calls.Load() != 2 {
+		t.Fatalf("handler ran %d times, want 2", calls.Load())
+	}
+}
+
+func TestIdempotencyMiddleware_RenewsClaimOfLongRequest(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	opt := IdempotencyOptions{LockTTL: 60 * time.Millisecond, Wait: 30 * time.Millisecond}
+	h := IdempotencyMiddleware(opt)(func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		if calls.Add(1) == 1 {
+			<-release
+		}
+		w.WriteHeader(http.StatusCreated)
+		return nil
+	})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		serveIdempotent(h, "/orders", "k", "{}")
+	}()
+	waitFor(t, func() bool { return calls.Load() == 1 })
+	time.Sleep(4 * opt.LockTTL)
+
+	_, err := serveIdempotent(h, "/orders", "k", "{}")
+	close(release)
+	<-done
+	var appErr *AppError
+	if !errors.As(err, &appErr) || appErr.Code != http.StatusConflict {
+		t.Fatalf("retry after LockTTL = %v, want 409 while the first request runs", err)
+	}
+	if calls.Load() != 1 {
+		t.Fatalf("handler ran %d times, want 1", calls.Load())
+	}
+}
+
+func TestIdempotencyMiddleware_BodyTooLarge(t *testing.T) {
+	var calls atomic.Int32
+	h := newIdempotentHandler(IdempotencyOptions{MaxBody: 8}, &calls, nil)
+
+	_, err := serveIdempotent(h, "/orders", "k", `{"qty":12345}`)
+	var appErr *AppError
+	if !errors.As(err, &appErr) || appErr.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("large body = %v, want 413", err)
+	}
+	if calls.Load() != 0 {
+		t.Fatalf("handler ran %d times, want 0", calls.Load())
+	}
+}
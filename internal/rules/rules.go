@@ -0,0 +1,113 @@
+// Package rules implements the `validate` struct tag rules shared by request
+// validation (golitekit.ValidateStruct) and config validation
+// (config.Validate), so both accept the same tags with the same meaning.
+package rules
+
+import (
+	"fmt"
+	"net/mail"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Check reports whether v satisfies rule with param. Supported rules are
+// required, min=N, max=N, len=N, email and oneof=a b c; known is false for
+// other rules, malformed parameters and kinds a rule does not apply to, which
+// callers ignore so that tags written for another validator do no harm.
+//
+// Every rule but required accepts unset values, nil pointers and empty
+// strings, so combine them with required when a value is mandatory.
+func Check(v reflect.Value, rule, param string) (ok, known bool) {
+	switch rule {
+	case "required":
+		return !v.IsZero(), true
+	case "min", "max", "len":
+		if isEmptyOptional(v) {
+			return true, true
+		}
+		n, err := strconv.ParseFloat(param, 64)
+		if err != nil {
+			return true, false
+		}
+		size, measurable := Measure(v)
+		if !measurable {
+			return true, false
+		}
+		switch rule {
+		case "min":
+			return size >= n, true
+		case "max":
+			return size <= n, true
+		default:
+			return size == n, true
+		}
+	case "email":
+		s, isString := stringValue(v)
+		if !isString {
+			return true, false
+		}
+		if s == "" {
+			return true, true
+		}
+		addr, err := mail.ParseAddress(s)
+		return err == nil && addr.Address == s, true
+	case "oneof":
+		if isEmptyOptional(v) {
+			return true, true
+		}
+		got := fmt.Sprint(reflect.Indirect(v).Interface())
+		for _, option := range strings.Fields(param) {
+			if got == option {
+				return true, true
+			}
+		}
+		return false, true
+	}
+	return true, false
+}
+
+// Measure returns the number min, max and len compare: the value of numbers,
+// the rune count of strings and the length of slices, arrays and maps.
+func Measure(v reflect.Value) (float64, bool) {
+	v = reflect.Indirect(v)
+	switch v.Kind() {
+	case reflect.String:
+		return float64(utf8.RuneCountInString(v.String())), true
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return float64(v.Len()), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	}
+	return 0, false
+}
+
+// isEmptyOptional reports whether v is an unset value that only "required"
+// should reject.
+func isEmptyOptional(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		return v.IsNil()
+	case reflect.String:
+		return v.Len() == 0
+	}
+	return false
+}
+
+func stringValue(v reflect.Value) (string, bool) {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return "", true
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.String {
+		return "", false
+	}
+	return v.String(), true
+}
@@ -0,0 +1,157 @@
+package golitekit
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
+)
+
+// maxStreamEnvelopeDepth bounds how many `any` fields streamJSON follows to
+// find the list to stream.
+const maxStreamEnvelopeDepth = 4
+
+// errStreamLimit aborts a streamed body over MaxResponseBytes.
+var errStreamLimit = errors.New("streamed response exceeds the size limit")
+
+// jsonStream is a JSON response left unencoded by Context.JSON, to be
+// streamed by ContextAsMiddleware.
+type jsonStream struct {
+	value     any
+	threshold int
+}
+
+// streamMarker stands in for the streamed list while its envelope is
+// encoded; the envelope is split around it.
+type streamMarker struct{}
+
+var marshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+
+var streamMarkerJSON = []byte(`"\u0000glk-stream"`)
+
+func (streamMarker) MarshalJSON() ([]byte, error) { return streamMarkerJSON, nil }
+
+// wantsStream reports whether v holds a list of at least threshold
+// elements, directly or through the `any` fields of envelopes such as
+// Response.Data and PageResult.Items.
+func wantsStream(v reflect.Value, threshold, depth int) bool {
+	v = indirectJSON(v)
+	if !v.IsValid() || v.Type().Implements(marshalerType) {
+		return false
+	}
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		return v.Type().Elem().Kind() != reflect.Uint8 && v.Len() >= threshold
+	case reflect.Struct:
+		if depth >= maxStreamEnvelopeDepth {
+			return false
+		}
+		return streamField(v, threshold, depth) >= 0
+	}
+	return false
+}
+
+// streamField returns the index of the exported `any` field of the struct v
+// that holds the list to stream, or -1.
+func streamField(v reflect.Value, threshold, depth int) int {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.IsExported() && f.Type.Kind() == reflect.Interface && wantsStream(v.Field(i), threshold, depth+1) {
+			return i
+		}
+	}
+	return -1
+}
+
+func indirectJSON(v reflect.Value) reflect.Value {
+	for (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) && !v.IsNil() {
+		if v.Type().Implements(marshalerType) {
+			break
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
+// streamJSON writes v to w as json.Marshal would, except that the list
+// wantsStream finds is encoded one element at a time, so it is never held
+// encoded in full.
+func streamJSON(w io.Writer, v any, threshold int) error {
+	return streamJSONValue(w, reflect.ValueOf(v), threshold, 0)
+}
+
+func streamJSONValue(w io.Writer, v reflect.Value, threshold, depth int) error {
+	if !v.IsValid() {
+		return writeJSONValue(w, nil)
+	}
+	if !wantsStream(v, threshold, depth) {
+		return writeJSONValue(w, v.Interface())
+	}
+	v = indirectJSON(v)
+	if v.Kind() == reflect.Struct {
+		i := streamField(v, threshold, depth)
+		envelope := reflect.New(v.Type()).Elem()
+		envelope.Set(v)
+		envelope.Field(i).Set(reflect.ValueOf(streamMarker{}))
+		data, err := json.Marshal(envelope.Interface())
+		if err != nil {
+			return err
+		}
+		before, after, _ := bytes.Cut(data, streamMarkerJSON)
+		if _, err := w.Write(before); err != nil {
+			return err
+		}
+		if err := streamJSONValue(w, v.Field(i), threshold, depth+1); err != nil {
+			return err
+		}
+		_, err = w.Write(after)
+		return err
+	}
+
+	if _, err := io.WriteString(w, "["); err != nil {
+		return err
+	}
+	for i := 0; i < v.Len(); i++ {
+		if i > 0 {
+			if _, err := io.WriteString(w, ","); err != nil {
+				return err
+			}
+		}
+		if err := writeJSONValue(w, v.Index(i).Interface()); err != nil {
+			return err
+		}
+	}
+	_, err := io.WriteString(w, "]")
+	return err
+}
+
+// writeJSONValue encodes v into a pooled buffer and writes it to w.
+func writeJSONValue(w io.Writer, v any) error {
+	buf := getJSONBuffer()
+	defer putJSONBuffer(buf)
+	if err := json.NewEncoder(buf).Encode(v); err != nil {
+		return err
+	}
+	_, err := w.Write(bytes.TrimSuffix(buf.Bytes(), []byte("\n")))
+	return err
+}
+
+// countingWriter counts the bytes written through it and fails writes past
+// limit, when set; n then includes the failed write.
+type countingWriter struct {
+	w     io.Writer
+	n     int
+	limit int
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	if c.limit > 0 && c.n+len(p) > c.limit {
+		c.n += len(p)
+		return 0, errStreamLimit
+	}
+	n, err := c.w.Write(p)
+	c.n += n
+	return n, err
+}
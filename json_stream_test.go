@@ -0,0 +1,92 @@
+package golitekit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type streamRow struct {
+	ID   int       `json:"id"`
+	Name string    `json:"name,omitempty"`
+	At   time.Time `json:"at"`
+	Tags []string  `json:"tags"`
+}
+
+func TestStreamJSONMatchesMarshal(t *testing.T) {
+	rows := []streamRow{
+		{ID: 1, Name: "<a>", Tags: []string{"x"}},
+		{ID: 2, At: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)},
+		{ID: 3},
+	}
+	page := Page{Number: 1, Size: 3}
+	values := map[string]any{
+		"slice":    rows,
+		"pointer":  &rows,
+		"array":    [2]int{1, 2},
+		"response": Response{Status: OK, Msg: "ok", Data: rows, LogID: "abc"},
+		"page":     &Response{Data: page.Result(rows, 3)},
+		"short":    Response{Data: rows[:1]},
+		"bytes":    []byte("abc"),
+		"nil":      nil,
+	}
+	for name, v := range values {
+		want, err := json.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var buf bytes.Buffer
+		if err := streamJSON(&buf, v, 2); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if buf.String() != string(want) {
+			t.Errorf("%s: streamed %s, want %s", name, buf.String(), want)
+		}
+	}
+}
+
+func TestContextAsMiddlewareStreamsJSON(t *testing.T) {
+	serve := func(opt ContextMiddlewareOptions, data any) (*httptest.ResponseRecorder, error) {
+		ctx := withContext(context.Background())
+		gcx := GetContext(ctx)
+		req := httptest.NewRequest("GET", "/test", nil).WithContext(ctx)
+		rec := httptest.NewRecorder()
+		gcx.setContextOptions(withRequest(req), withResponseWriter(rec))
+		inner := Handler(func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			return GetContext(ctx).JSON(http.StatusCreated, data)
+		})
+		err := ContextAsMiddleware(opt)(inner)(req.Context(), rec, req)
+		if _, ok := gcx.jsonResponse.(jsonStream); !ok {
+			t.Errorf("response was not streamed")
+		}
+		return rec, err
+	}
+	rows := make([]streamRow, 50)
+	for i := range rows {
+		rows[i].ID = i
+	}
+	data := Response{Status: OK, Data: rows}
+	want, _ := json.Marshal(data)
+
+	rec, err := serve(ContextMiddlewareOptions{StreamJSONThreshold: 10}, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec.Code != http.StatusCreated || rec.Header().Get("Content-Type") != "application/json" {
+		t.Errorf("status = %d, Content-Type = %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	if rec.Body.String() != string(want) {
+		t.Errorf("body = %s, want %s", rec.Body.String(), want)
+	}
+
+	_, err = serve(ContextMiddlewareOptions{StreamJSONThreshold: 10, MaxResponseBytes: 100}, data)
+	var appErr *AppError
+	if !errors.As(err, &appErr) || appErr.Code != http.StatusInternalServerError {
+		t.Errorf("oversized stream: err = %v, want a 500", err)
+	}
+}
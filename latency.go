@@ -0,0 +1,265 @@
+package golitekit
+
+import (
+	"context"
+	"math"
+	"math/bits"
+	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// latencySubBucketBits sets the precision: each power of two is split into
+	// 64 linear buckets, a relative error below 1.6%.
+	latencySubBucketBits = 6
+	latencySubBuckets    = 1 << latencySubBucketBits
+	// latencyChunks covers values up to 2^(latencyChunks+5) microseconds
+	// (about 12 days); larger values land in the last bucket.
+	latencyChunks = 36
+)
+
+// UnmatchedRoute is the LatencyTracker key of requests without a route
+// pattern (handlers mounted outside the Router), so they cannot create a
+// histogram per URL.
+const UnmatchedRoute = "unmatched"
+
+type latencyChunk [latencySubBuckets]atomic.Uint64
+
+// LatencyHistogram is a sparse HDR-style histogram of durations with
+// microsecond resolution. Bucket chunks are allocated on first use, so
+// memory grows with the range of observed values (at most 18 KiB), not with
+// the number of observations. Record is lock-free.
+type LatencyHistogram struct {
+	chunks [latencyChunks]atomic.Pointer[latencyChunk]
+	count  atomic.Uint64
+	sum    atomic.Uint64 // microseconds
+	max    atomic.Uint64 // microseconds
+}
+
+// Record adds one observation.
+func (h *LatencyHistogram) Record(d time.Duration) {
+	us := uint64(0)
+	if d > 0 {
+		us = uint64(d / time.Microsecond)
+	}
+	chunk, sub := latencyIndex(us)
+	c := h.chunks[chunk].Load()
+	if c == nil {
+		c = new(latencyChunk)
+		if !h.chunks[chunk].CompareAndSwap(nil, c) {
+			c = h.chunks[chunk].Load()
+		}
+	}
+	c[sub].Add(1)
+	h.count.Add(1)
+	h.sum.Add(us)
+	for {
+		cur := h.max.Load()
+		if us <= cur || h.max.CompareAndSwap(cur, us) {
+			break
+		}
+	}
+}
+
+// Quantile returns the value at quantile q (0 < q <= 1), reported as the
+// upper bound of its bucket, or 0 when the histogram is empty.
+func (h *LatencyHistogram) Quantile(q float64) time.Duration {
+	count := h.count.Load()
+	if count == 0 {
+		return 0
+	}
+	rank := uint64(math.Ceil(q * float64(count)))
+	if rank < 1 {
+		rank = 1
+	}
+	var seen uint64
+	for chunk := range h.chunks {
+		c := h.chunks[chunk].Load()
+		if c == nil {
+			continue
+		}
+		for sub := range c {
+			seen += c[sub].Load()
+			if seen >= rank {
+				return min(latencyUpperBound(chunk, sub), h.maxDuration())
+			}
+		}
+	}
+	return h.maxDuration()
+}
+
+// merge adds the observations of other to h.
+func (h *LatencyHistogram) merge(other *LatencyHistogram) {
+	for chunk := range other.chunks {
+		oc := other.chunks[chunk].Load()
+		if oc == nil {
+			continue
+		}
+		c := h.chunks[chunk].Load()
+		if c == nil {
+			c = new(latencyChunk)
+			if !h.chunks[chunk].CompareAndSwap(nil, c) {
+				c = h.chunks[chunk].Load()
+			}
+		}
+		for sub := range oc {
+			if n := oc[sub].Load(); n > 0 {
+				c[sub].Add(n)
+			}
+		}
+	}
+	h.count.Add(other.count.Load())
+	h.sum.Add(other.sum.Load())
+	if us := other.max.Load(); us > h.max.Load() {
+		h.max.Store(us)
+	}
+}
+
+func (h *LatencyHistogram) maxDuration() time.Duration {
+	return time.Duration(h.max.Load()) * time.Microsecond
+}
+
+// Snapshot summarises the histogram.
+func (h *LatencyHistogram) Snapshot() LatencySnapshot {
+	count := h.count.Load()
+	snap := LatencySnapshot{Count: count}
+	if count == 0 {
+		return snap
+	}
+	snap.Mean = durationMillis(time.Duration(h.sum.Load()/count) * time.Microsecond)
+	snap.Max = durationMillis(h.maxDuration())
+	snap.P50 = durationMillis(h.Quantile(0.50))
+	snap.P90 = durationMillis(h.Quantile(0.90))
+	snap.P99 = durationMillis(h.Quantile(0.99))
+	snap.P999 = durationMillis(h.Quantile(0.999))
+	return snap
+}
+
+// latencyIndex maps microseconds to a bucket: chunk 0 holds 0-63 exactly,
+// chunk k >= 1 splits [2^(k+5), 2^(k+6)) into 64 buckets of width 2^(k-1).
+func latencyIndex(us uint64) (chunk, sub int) {
+	if us < latencySubBuckets {
+		return 0, int(us)
+	}
+	chunk = bits.Len64(us) - latencySubBucketBits
+	if chunk >= latencyChunks {
+		return latencyChunks - 1, latencySubBuckets - 1
+	}
+	return chunk, int(us>>(chunk-1)) - latencySubBuckets
+}
+
+func latencyUpperBound(chunk, sub int) time.Duration {
+	if chunk == 0 {
+		return time.Duration(sub) * time.Microsecond
+	}
+	lower := uint64(sub+latencySubBuckets) << (chunk - 1)
+	return time.Duration(lower+(1<<(chunk-1))-1) * time.Microsecond
+}
+
+func durationMillis(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// LatencySnapshot summarises one route's latency; durations are in
+// milliseconds.
+type LatencySnapshot struct {
+	Count uint64  `json:"count"`
+	Mean  float64 `json:"mean_ms"`
+	Max   float64 `json:"max_ms"`
+	P50   float64 `json:"p50_ms"`
+	P90   float64 `json:"p90_ms"`
+	P99   float64 `json:"p99_ms"`
+	P999  float64 `json:"p999_ms"`
+}
+
+// RouteLatency is the snapshot of one route.
+type RouteLatency struct {
+	Route string `json:"route"`
+	LatencySnapshot
+}
+
+// LatencyTracker keeps a LatencyHistogram per route pattern ("GET
+// /users/{id}"), so percentiles up to p999 stay exact to a bucket under any
+// load without storing samples.
+type LatencyTracker struct {
+	mu     sync.RWMutex
+	routes map[string]*LatencyHistogram
+	since  time.Time
+	now    func() time.Time
+}
+
+// NewLatencyTracker creates an empty LatencyTracker.
+func NewLatencyTracker() *LatencyTracker {
+	return &LatencyTracker{
+		routes: make(map[string]*LatencyHistogram),
+		since:  time.Now(),
+		now:    time.Now,
+	}
+}
+
+// Record adds an observation to route's histogram.
+func (t *LatencyTracker) Record(route string, d time.Duration) {
+	if t == nil {
+		return
+	}
+	t.mu.RLock()
+	h := t.routes[route]
+	t.mu.RUnlock()
+	if h == nil {
+		t.mu.Lock()
+		if h = t.routes[route]; h == nil {
+			h = &LatencyHistogram{}
+			t.routes[route] = h
+		}
+		t.mu.Unlock()
+	}
+	h.Record(d)
+}
+
+// Snapshot returns every route's summary, sorted by route, and the time the
+// histograms were started or last reset.
+func (t *LatencyTracker) Snapshot() (routes []RouteLatency, since time.Time) {
+	if t == nil {
+		return nil, time.Time{}
+	}
+	t.mu.RLock()
+	routes = make([]RouteLatency, 0, len(t.routes))
+	for route, h := range t.routes {
+		routes = append(routes, RouteLatency{Route: route, LatencySnapshot: h.Snapshot()})
+	}
+	since = t.since
+	t.mu.RUnlock()
+	sort.Slice(routes, func(i, j int) bool { return routes[i].Route < routes[j].Route })
+	return routes, since
+}
+
+// Reset drops every histogram. Requests in flight may still land in the old
+// ones; they are not reported.
+func (t *LatencyTracker) Reset() {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	t.routes = make(map[string]*LatencyHistogram)
+	t.since = t.now()
+	t.mu.Unlock()
+}
+
+// Middleware records the duration of every request under its route pattern.
+func (t *LatencyTracker) Middleware() Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			start := time.Now()
+			err := next(ctx, w, r)
+			route := r.Pattern
+			if route == "" {
+				route = UnmatchedRoute
+			}
+			t.Record(route, time.Since(start))
+			return err
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package golitekit
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLatencyIndexBounds(t *testing.T) {
+	for _, us := range []uint64{0, 1, 63, 64, 65, 127, 128, 1000, 123456, 1 << 30} {
+		chunk, sub := latencyIndex(us)
+		upper := uint64(latencyUpperBound(chunk, sub) / time.Microsecond)
+		if upper < us {
+			t.Errorf("%dus: upper bound %d below value", us, upper)
+		}
+		if us >= latencySubBuckets && float64(upper-us)/float64(us) > 1.0/latencySubBuckets {
+			t.Errorf("%dus: upper bound %d exceeds the relative error", us, upper)
+		}
+	}
+	if chunk, sub := latencyIndex(math.MaxUint64); chunk != latencyChunks-1 || sub != latencySubBuckets-1 {
+		t.Errorf("huge value indexed to (%d, %d), want the last bucket", chunk, sub)
+	}
+}
+
+func TestLatencyHistogramQuantiles(t *testing.T) {
+	var h LatencyHistogram
+	var wg sync.WaitGroup
+	for w := 0; w < 4; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 1 + w; i <= 10000; i += 4 {
+				h.Record(time.Duration(i) * time.Millisecond)
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	snap := h.Snapshot()
+	if snap.Count != 10000 || snap.Max != 10000 {
+		t.Fatalf("count=%d max=%v", snap.Count, snap.Max)
+	}
+	for _, tc := range []struct {
+		got, want float64
+	}{{snap.P50, 5000}, {snap.P99, 9900}, {snap.P999, 9990}, {snap.Mean, 5000.5}} {
+		if math.Abs(tc.got-tc.want)/tc.want > 0.02 {
+			t.Errorf("got %.1fms, want about %.1fms", tc.got, tc.want)
+		}
+	}
+}
+
+func TestLatencyTrackerAdminSnapshotAndReset(t *testing.T) {
+	tracker := NewLatencyTracker()
+	app := NewApp()
+	app.Use(tracker.Middleware())
+	app.GET("/users/{id}", HandlerFunc(func(ctx *Context) error { return nil }))
+	app.MountAdmin(AdminOptions{Token: "secret", Latency: tracker})
+
+	for i := 0; i < 3; i++ {
+		adminRequest(app, http.MethodGet, "/users/1", "")
+	}
+	// Requests served outside the router have no pattern.
+	handler := tracker.Middleware()(func(ctx context.Context, w http.ResponseWriter, r *http.Request) error { return nil })
+	_ = handler(context.Background(), httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/nope", nil))
+
+	rec := adminRequest(app, http.MethodGet, "/_admin/latency", "")
+	var body struct {
+		Routes []RouteLatency `json:"routes"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode: %v (%s)", err, rec.Body)
+	}
+	counts := map[string]uint64{}
+	for _, r := range body.Routes {
+		counts[r.Route] = r.Count
+	}
+	if counts["GET /users/{id}"] != 3 || counts[UnmatchedRoute] != 1 {
+		t.Fatalf("counts = %v", counts)
+	}
+
+	if rec := adminRequest(app, http.MethodPost, "/_admin/latency/reset", ""); rec.Code != http.StatusOK {
+		t.Fatalf("reset status = %d", rec.Code)
+	}
+	routes, _ := tracker.Snapshot()
+	for _, r := range routes {
+		if r.Route != "POST /_admin/latency/reset" {
+			t.Fatalf("routes after reset = %+v", routes)
+		}
+	}
+}
@@ -0,0 +1,220 @@
+package golitekit
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand/v2"
+	"net/http"
+	"runtime"
+	"runtime/metrics"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	DefaultLoadShedInterval    = time.Second
+	DefaultLoadShedStep        = 0.1
+	DefaultLoadShedMaxRatio    = 0.9
+	DefaultLoadShedMinRequests = 20
+	DefaultLoadShedRetryAfter  = time.Second
+)
+
+var errLoadShed = errors.New("request shed under overload")
+
+// LoadShedderOptions sets the overload thresholds of a LoadShedder; a zero
+// threshold disables its signal.
+type LoadShedderOptions struct {
+	MaxCPU        float64       // busy share of the GOMAXPROCS CPUs, e.g. 0.85
+	MaxGoroutines int           // number of goroutines
+	MaxP99        time.Duration // p99 latency of the requests served within an Interval
+
+	MinRequests int           // requests within an Interval before MaxP99 applies, defaults to DefaultLoadShedMinRequests
+	Interval    time.Duration // how often load is sampled, defaults to DefaultLoadShedInterval
+	Step        float64       // change of the shed ratio per Interval, defaults to DefaultLoadShedStep
+	MaxRatio    float64       // highest shed ratio, defaults to DefaultLoadShedMaxRatio
+	RetryAfter  time.Duration // Retry-After of shed requests, defaults to DefaultLoadShedRetryAfter
+}
+
+// LoadShedderStats is the load seen at the last sample and the shed ratio
+// derived from it.
+type LoadShedderStats struct {
+	Ratio      float64   `json:"ratio"`
+	CPU        float64   `json:"cpu"`
+	Goroutines int       `json:"goroutines"`
+	P99        float64   `json:"p99_ms"`
+	Requests   uint64    `json:"requests"`
+	Overloaded []string  `json:"overloaded,omitempty"`
+	Shed       uint64    `json:"shed"`
+	SampledAt  time.Time `json:"sampled_at"`
+}
+
+// LoadShedder rejects a growing fraction of requests with 503 while the
+// process is overloaded, protecting the tail latency of the rest. Every
+// Interval it samples CPU, goroutines and the p99 of the requests it let
+// through; while any exceeds its threshold the shed ratio rises by Step, up
+// to MaxRatio, and it falls by Step once all are back below.
+type LoadShedder struct {
+	opts LoadShedderOptions
+
+	ratio      atomic.Uint64 // math.Float64bits of the shed ratio
+	shed       atomic.Uint64
+	hist       atomic.Pointer[LatencyHistogram]
+	nextSample atomic.Int64
+
+	mu        sync.Mutex
+	lastBusy  float64
+	lastTotal float64
+	last      LoadShedderStats
+
+	now        func() time.Time
+	cpuTime    func() (busy, total float64)
+	goroutines func() int
+	random     func() float64
+}
+
+// NewLoadShedder creates a LoadShedder, filling zero-valued options with
+// defaults.
+func NewLoadShedder(opts ...LoadShedderOptions) *LoadShedder {
+	var opt LoadShedderOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if opt.MinRequests <= 0 {
+		opt.MinRequests = DefaultLoadShedMinRequests
+	}
+	if opt.Interval <= 0 {
+		opt.Interval = DefaultLoadShedInterval
+	}
+	if opt.Step <= 0 {
+		opt.Step = DefaultLoadShedStep
+	}
+	if opt.MaxRatio <= 0 || opt.MaxRatio > 1 {
+		opt.MaxRatio = DefaultLoadShedMaxRatio
+	}
+	if opt.RetryAfter <= 0 {
+		opt.RetryAfter = DefaultLoadShedRetryAfter
+	}
+	l := &LoadShedder{
+		opts:       opt,
+		now:        time.Now,
+		cpuTime:    runtimeCPUTime,
+		goroutines: runtime.NumGoroutine,
+		random:     rand.Float64,
+	}
+	l.hist.Store(&LatencyHistogram{})
+	return l
+}
+
+// Middleware sheds requests at the current ratio and times the others.
+func (l *LoadShedder) Middleware() Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			l.maybeSample()
+			if ratio := l.Ratio(); ratio > 0 && l.random() < ratio {
+				l.shed.Add(1)
+				return l.reject(ctx)
+			}
+			start := l.now()
+			err := next(ctx, w, r)
+			l.hist.Load().Record(l.now().Sub(start))
+			return err
+		}
+	}
+}
+
+func (l *LoadShedder) reject(ctx context.Context) error {
+	return shedError(ctx, errLoadShed).WithHeader("Retry-After", strconv.Itoa(retryAfterSeconds(l.opts.RetryAfter)))
+}
+
+// Ratio returns the fraction of requests currently shed.
+func (l *LoadShedder) Ratio() float64 {
+	return math.Float64frombits(l.ratio.Load())
+}
+
+// Stats returns the last sample and the number of shed requests.
+func (l *LoadShedder) Stats() LoadShedderStats {
+	l.mu.Lock()
+	stats := l.last
+	l.mu.Unlock()
+	stats.Overloaded = append([]string(nil), stats.Overloaded...)
+	stats.Ratio = l.Ratio()
+	stats.Shed = l.shed.Load()
+	return stats
+}
+
+// maybeSample samples the load once per Interval, on the first request
+// after it elapsed.
+func (l *LoadShedder) maybeSample() {
+	now := l.now()
+	if now.UnixNano() < l.nextSample.Load() {
+		return
+	}
+	if !l.mu.TryLock() {
+		return
+	}
+	defer l.mu.Unlock()
+	if now.UnixNano() < l.nextSample.Load() {
+		return
+	}
+	l.nextSample.Store(now.Add(l.opts.Interval).UnixNano())
+	l.sampleLocked(now)
+}
+
+func (l *LoadShedder) sampleLocked(now time.Time) {
+	hist := l.hist.Swap(&LatencyHistogram{})
+	busy, total := l.cpuTime()
+	first := l.last.SampledAt.IsZero()
+
+	stats := LoadShedderStats{
+		Goroutines: l.goroutines(),
+		Requests:   hist.count.Load(),
+		SampledAt:  now,
+	}
+	if total > l.lastTotal {
+		stats.CPU = (busy - l.lastBusy) / (total - l.lastTotal)
+	}
+	l.lastBusy, l.lastTotal = busy, total
+	p99 := hist.Quantile(0.99)
+	stats.P99 = durationMillis(p99)
+
+	if l.opts.MaxCPU > 0 && !first && stats.CPU >= l.opts.MaxCPU {
+		stats.Overloaded = append(stats.Overloaded, "cpu")
+	}
+	if l.opts.MaxGoroutines > 0 && stats.Goroutines >= l.opts.MaxGoroutines {
+		stats.Overloaded = append(stats.Overloaded, "goroutines")
+	}
+	if l.opts.MaxP99 > 0 && stats.Requests >= uint64(l.opts.MinRequests) && p99 >= l.opts.MaxP99 {
+		stats.Overloaded = append(stats.Overloaded, "p99")
+	}
+
+	ratio := l.Ratio()
+	if len(stats.Overloaded) > 0 {
+		ratio = min(ratio+l.opts.Step, l.opts.MaxRatio)
+	} else {
+		ratio = max(ratio-l.opts.Step, 0)
+	}
+	// snap float residue to zero so a recovered shedder sheds nothing
+	if ratio < l.opts.Step/2 {
+		ratio = 0
+	}
+	l.ratio.Store(math.Float64bits(ratio))
+	l.last = stats
+}
+
+// runtimeCPUTime returns the CPU seconds the process spent busy and the CPU
+// seconds available to it under GOMAXPROCS, both since start.
+func runtimeCPUTime() (busy, total float64) {
+	samples := []metrics.Sample{
+		{Name: "/cpu/classes/total:cpu-seconds"},
+		{Name: "/cpu/classes/idle:cpu-seconds"},
+	}
+	metrics.Read(samples)
+	if samples[0].Value.Kind() != metrics.KindFloat64 || samples[1].Value.Kind() != metrics.KindFloat64 {
+		return 0, 0
+	}
+	total = samples[0].Value.Float64()
+	return total - samples[1].Value.Float64(), total
+}
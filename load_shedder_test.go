@@ -0,0 +1,109 @@
+package golitekit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func newTestLoadShedder(opts LoadShedderOptions) (*LoadShedder, *time.Time, *int) {
+	now := time.Unix(1700000000, 0)
+	goroutines := 10
+	l := NewLoadShedder(opts)
+	l.now = func() time.Time { return now }
+	l.cpuTime = func() (float64, float64) { return 0, 0 }
+	l.goroutines = func() int { return goroutines }
+	l.random = func() float64 { return 0.5 }
+	return l, &now, &goroutines
+}
+
+func TestLoadShedder_RatioFollowsOverload(t *testing.T) {
+	l, now, goroutines := newTestLoadShedder(LoadShedderOptions{MaxGoroutines: 100, Step: 0.3, MaxRatio: 0.8})
+	handler := l.Middleware()(func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		return nil
+	})
+	serve := func() error {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		return handler(req.Context(), httptest.NewRecorder(), req)
+	}
+
+	if err := serve(); err != nil {
+		t.Fatalf("healthy request: %v", err)
+	}
+	*goroutines = 500
+	for i, want := range []float64{0.3, 0.6, 0.8, 0.8} {
+		*now = now.Add(time.Second)
+		_ = serve()
+		if got := l.Ratio(); got < want-1e-9 || got > want+1e-9 {
+			t.Fatalf("interval %d: ratio = %v, want %v", i, got, want)
+		}
+	}
+
+	err := serve()
+	var appErr *AppError
+	if !errors.As(err, &appErr) || appErr.Code != http.StatusServiceUnavailable {
+		t.Fatalf("shed error = %v, want 503", err)
+	}
+	if got := appErr.Header.Get("Retry-After"); got != "1" {
+		t.Errorf("Retry-After = %q, want 1", got)
+	}
+	if stats := l.Stats(); stats.Shed == 0 || len(stats.Overloaded) != 1 || stats.Overloaded[0] != "goroutines" {
+		t.Errorf("stats = %+v", stats)
+	}
+
+	*goroutines = 10
+	for i := 0; i < 3; i++ {
+		*now = now.Add(time.Second)
+		_ = serve()
+	}
+	if got := l.Ratio(); got != 0 {
+		t.Fatalf("ratio after recovery = %v, want 0", got)
+	}
+}
+
+func TestLoadShedder_P99NeedsMinRequests(t *testing.T) {
+	l, now, _ := newTestLoadShedder(LoadShedderOptions{MaxP99: 100 * time.Millisecond, MinRequests: 5, Interval: 10 * time.Second})
+	handler := l.Middleware()(func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		*now = now.Add(200 * time.Millisecond)
+		return nil
+	})
+	serve := func(n int) {
+		for i := 0; i < n; i++ {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			_ = handler(req.Context(), httptest.NewRecorder(), req)
+		}
+	}
+
+	// the first request samples the empty start-up interval
+	serve(4)
+	*now = now.Add(10 * time.Second)
+	serve(1)
+	if got := l.Ratio(); got != 0 {
+		t.Fatalf("ratio with %d slow requests = %v, want 0", l.Stats().Requests, got)
+	}
+
+	serve(5)
+	*now = now.Add(10 * time.Second)
+	serve(1)
+	stats := l.Stats()
+	if stats.Ratio == 0 || len(stats.Overloaded) != 1 || stats.Overloaded[0] != "p99" {
+		t.Fatalf("stats = %+v, want p99 overload", stats)
+	}
+}
+
+func TestLoadShedder_CPU(t *testing.T) {
+	l, now, _ := newTestLoadShedder(LoadShedderOptions{MaxCPU: 0.8})
+	busy, total := 0.0, 0.0
+	l.cpuTime = func() (float64, float64) { return busy, total }
+
+	l.maybeSample()
+	busy, total = 0.9, 1
+	*now = now.Add(time.Second)
+	l.maybeSample()
+	if stats := l.Stats(); stats.CPU < 0.89 || stats.Ratio == 0 {
+		t.Fatalf("stats = %+v, want cpu overload", stats)
+	}
+}
@@ -7,19 +7,30 @@ import (
 	"time"
 )
 
-// logRecord creates and handles a slog.Record with the given parameters.
-func logRecord(ctx context.Context, handler slog.Handler, level slog.Level, msg string, callerSkip int, args ...any) error {
+// logRecord creates and handles a slog.Record with the given parameters,
+// counting it as dropped when the handler fails. The caller's pc, which costs
+// a stack walk, is only looked up when addSource is set, since handlers read
+// it for the source attribute alone.
+func logRecord(ctx context.Context, handler slog.Handler, level slog.Level, msg string, addSource bool, callerSkip int, args ...any) error {
 	var pc uintptr
-	var pcs [1]uintptr
-	runtime.Callers(callerSkip, pcs[:])
-	pc = pcs[0]
+	if addSource {
+		var pcs [1]uintptr
+		runtime.Callers(callerSkip, pcs[:])
+		pc = pcs[0]
+	}
 
 	r := slog.NewRecord(time.Now(), level, msg, pc)
-	r.Add(args...)
+	if len(args) > 0 {
+		r.Add(args...)
+	}
 
 	if ctx == nil {
 		ctx = context.Background()
 	}
 
-	return handler.Handle(ctx, r)
+	err := handler.Handle(ctx, r)
+	if err != nil {
+		stats.dropped.Add(1)
+	}
+	return err
 }
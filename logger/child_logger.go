@@ -0,0 +1,76 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+)
+
+// childLogger is a logger returned by With or WithGroup. It rewrites the
+// arguments of each call and logs through base, so the fields of the request
+// context are still added by base's handler.
+type childLogger struct {
+	base Logger
+	// attrs turns the arguments of a call into those passed to base.
+	attrs func(args []any) []any
+}
+
+func withArgs(base Logger, attrs func([]any) []any, args []any) StructuredLogger {
+	if attrs == nil {
+		attrs = func(a []any) []any { return a }
+	}
+	return &childLogger{base: base, attrs: func(a []any) []any {
+		return attrs(append(append([]any(nil), args...), a...))
+	}}
+}
+
+func withGroup(base Logger, attrs func([]any) []any, name string) StructuredLogger {
+	if attrs == nil {
+		attrs = func(a []any) []any { return a }
+	}
+	if name == "" {
+		return &childLogger{base: base, attrs: attrs}
+	}
+	return &childLogger{base: base, attrs: func(a []any) []any {
+		if len(a) == 0 {
+			return attrs(nil)
+		}
+		return attrs([]any{slog.Group(name, a...)})
+	}}
+}
+
+func (l *childLogger) Debug(ctx context.Context, msg string, args ...any) {
+	l.base.Debug(ctx, msg, l.attrs(args)...)
+}
+
+func (l *childLogger) Trace(ctx context.Context, msg string, args ...any) {
+	l.base.Trace(ctx, msg, l.attrs(args)...)
+}
+
+func (l *childLogger) Info(ctx context.Context, msg string, args ...any) {
+	l.base.Info(ctx, msg, l.attrs(args)...)
+}
+
+func (l *childLogger) Warning(ctx context.Context, msg string, args ...any) {
+	l.base.Warning(ctx, msg, l.attrs(args)...)
+}
+
+func (l *childLogger) Error(ctx context.Context, msg string, args ...any) {
+	l.base.Error(ctx, msg, l.attrs(args)...)
+}
+
+func (l *childLogger) Fatal(ctx context.Context, msg string, args ...any) {
+	l.base.Fatal(ctx, msg, l.attrs(args)...)
+}
+
+// Close does nothing: the parent logger owns the output.
+func (l *childLogger) Close() error {
+	return nil
+}
+
+func (l *childLogger) With(args ...any) StructuredLogger {
+	return withArgs(l.base, l.attrs, args)
+}
+
+func (l *childLogger) WithGroup(name string) StructuredLogger {
+	return withGroup(l.base, l.attrs, name)
+}
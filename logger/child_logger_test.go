@@ -0,0 +1,59 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+func TestWithAndWithGroup(t *testing.T) {
+	var buf bytes.Buffer
+	ctx := WithLoggerContext(context.Background())
+	AddInfo(ctx, "logid", "abc123")
+
+	var log StructuredLogger = newBufferLogger(&buf)
+	log.With("component", "cache").WithGroup("req").With("id", 7).Info(ctx, "lookup failed", "key", "k1")
+
+	var rec map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &rec); err != nil {
+		t.Fatalf("record %q: %v", buf.String(), err)
+	}
+	if rec["level"] != "INFO" || rec["component"] != "cache" || rec["logid"] != "abc123" {
+		t.Errorf("record = %v", rec)
+	}
+	req, _ := rec["req"].(map[string]any)
+	if req["id"] != float64(7) || req["key"] != "k1" {
+		t.Errorf("group req = %v, want id and key", rec["req"])
+	}
+}
+
+func TestWithGroupWithoutAttrs(t *testing.T) {
+	var buf bytes.Buffer
+	newBufferLogger(&buf).WithGroup("empty").Info(context.Background(), "plain")
+
+	var rec map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &rec); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := rec["empty"]; ok {
+		t.Errorf("empty group logged: %v", rec)
+	}
+}
+
+func TestFromContextWith(t *testing.T) {
+	var buf bytes.Buffer
+	ctx := WithLoggerContext(context.Background())
+	SetLogger(ctx, newBufferLogger(&buf))
+	AddInfo(ctx, "logid", "abc123")
+
+	FromContext(ctx).With("component", "cache").Info(context.Background(), "hit")
+
+	var rec map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &rec); err != nil {
+		t.Fatal(err)
+	}
+	if rec["component"] != "cache" || rec["logid"] != "abc123" {
+		t.Errorf("record = %v", rec)
+	}
+}
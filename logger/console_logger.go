@@ -7,7 +7,10 @@ import (
 )
 
 type ConsoleLogger struct {
-	logger *slog.Logger
+	logger    *slog.Logger
+	level     *slog.LevelVar
+	redactor  *redactorVar
+	addSource bool
 }
 
 func (l *ConsoleLogger) Debug(ctx context.Context, msg string, args ...any) {
@@ -44,17 +47,53 @@ func (l *ConsoleLogger) logit(ctx context.Context, level slog.Level, format stri
 }
 
 func NewConsoleLogger(opts *slog.HandlerOptions) (*ConsoleLogger, error) {
-	handler := newContextHandler(os.Stdout, LoggerTextFormat, opts)
+	return newConsoleLogger(opts, LoggerTextFormat)
+}
+
+func newConsoleLogger(opts *slog.HandlerOptions, format string) (*ConsoleLogger, error) {
+	opts, level := withLevelVar(opts)
+	opts, redactor := withRedactorVar(opts)
+	handler := newContextHandler(os.Stdout, format, opts)
 
 	return &ConsoleLogger{
-		logger: slog.New(handler),
+		logger:    slog.New(handler),
+		level:     level,
+		redactor:  redactor,
+		addSource: opts.AddSource,
 	}, nil
 }
 
+// With returns a logger that adds args to every record.
+func (l *ConsoleLogger) With(args ...any) StructuredLogger {
+	return withArgs(l, nil, args)
+}
+
+// WithGroup returns a logger that nests the attributes of every record under
+// name.
+func (l *ConsoleLogger) WithGroup(name string) StructuredLogger {
+	return withGroup(l, nil, name)
+}
+
+// Level returns the current minimum level.
+func (l *ConsoleLogger) Level() slog.Level {
+	return l.level.Level()
+}
+
+// SetLevel changes the minimum level; it takes effect for the next record.
+func (l *ConsoleLogger) SetLevel(level slog.Level) {
+	l.level.Set(level)
+}
+
+// SetRedactor replaces the Redactor applied to records; nil turns redaction
+// off.
+func (l *ConsoleLogger) SetRedactor(r Redactor) {
+	l.redactor.Store(r)
+}
+
 func (l *ConsoleLogger) log(ctx context.Context, level slog.Level, msg string, args ...any) {
 	if !l.logger.Enabled(ctx, level) {
 		return
 	}
 	// callerSkip=5: logRecord -> log -> logit -> Debug/Info/... -> user code
-	_ = logRecord(ctx, l.logger.Handler(), level, msg, 5, args...)
+	_ = logRecord(ctx, l.logger.Handler(), level, msg, l.addSource, 5, args...)
 }
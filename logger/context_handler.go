@@ -28,14 +28,16 @@ type Field struct {
 // concurrent use: multiple goroutines (e.g. the handler goroutine and deferred
 // middleware cleanup after a timeout) may call add() and Handle() in parallel.
 type LoggerContext struct {
-	mu   sync.RWMutex
-	Head *Field
+	mu     sync.RWMutex
+	Head   *Field
+	logger Logger // returned by FromContext, see SetLogger
 }
 
 // Reset clears all accumulated log fields so the instance can be reused.
 func (lc *LoggerContext) Reset() {
 	lc.mu.Lock()
 	lc.Head = nil
+	lc.logger = nil
 	lc.mu.Unlock()
 }
 
@@ -65,30 +67,45 @@ func GetLoggerContext(ctx context.Context) *LoggerContext {
 // handler.
 func (h ContextHandler) Handle(ctx context.Context, r slog.Record) error {
 	if logCtx, ok := ctx.Value(LoggerKey).(*LoggerContext); ok {
+		attrs := attrSlicePool.Get().(*[]slog.Attr)
 		logCtx.mu.RLock()
 		for node := logCtx.Head; node != nil; node = node.Next {
 			// skip lower level field
 			if node.Level < r.Level {
 				continue
 			}
-			attr := slog.Attr{
+			*attrs = append(*attrs, slog.Attr{
 				Key:   node.Key,
 				Value: slog.AnyValue(node.Value),
-			}
-			r.AddAttrs(attr)
+			})
 		}
 		logCtx.mu.RUnlock()
+		// one call sizes the record's attribute storage once
+		r.AddAttrs(*attrs...)
+		clear(*attrs)
+		*attrs = (*attrs)[:0]
+		attrSlicePool.Put(attrs)
 	}
 	return h.Handler.Handle(ctx, r)
 }
 
+// attrSlicePool holds the slices Handle gathers context fields in. AddAttrs
+// copies them into the record, so they are reusable once it returns.
+var attrSlicePool = sync.Pool{
+	New: func() any {
+		attrs := make([]slog.Attr, 0, 16)
+		return &attrs
+	},
+}
+
 func newContextHandler(target io.Writer, format string, opts *slog.HandlerOptions) *ContextHandler {
 	switch strings.ToLower(format) {
-	case "json":
+	case LoggerJSONFormat:
 		return &ContextHandler{slog.NewJSONHandler(target, opts)}
-	case "text":
-		fallthrough
+	case LoggerECSFormat:
+		return &ContextHandler{slog.NewJSONHandler(target, opts).WithAttrs([]slog.Attr{slog.String("ecs.version", ECSVersion)})}
 	default:
+		// text and logfmt; logfmt differs only in its keys, see schemaOptions.
 		return &ContextHandler{slog.NewTextHandler(target, opts)}
 	}
 }
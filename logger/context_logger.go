@@ -0,0 +1,99 @@
+package logger
+
+import (
+	"context"
+	"sync"
+)
+
+var (
+	fallbackOnce   sync.Once
+	fallbackLogger Logger
+)
+
+// SetLogger makes l the logger FromContext returns for ctx. Like AddInfo it
+// does nothing when ctx was not initialized with WithLoggerContext.
+func SetLogger(ctx context.Context, l Logger) {
+	logCtx := GetLoggerContext(ctx)
+	if logCtx == nil {
+		return
+	}
+	logCtx.mu.Lock()
+	logCtx.logger = l
+	logCtx.mu.Unlock()
+}
+
+// FromContext returns the request's logger bound to ctx, so library code can
+// log without having the framework logger passed in. Its records carry the
+// fields accumulated in ctx, the logid included, even when a method is given
+// a context without them such as context.Background(); fields added after
+// the call are picked up as well. Without a logger set by SetLogger the
+// records go to the console. Close on the returned logger is a no-op.
+func FromContext(ctx context.Context) StructuredLogger {
+	var base Logger
+	logCtx := GetLoggerContext(ctx)
+	if logCtx != nil {
+		logCtx.mu.RLock()
+		base = logCtx.logger
+		logCtx.mu.RUnlock()
+	}
+	if base == nil {
+		fallbackOnce.Do(func() {
+			fallbackLogger, _ = NewLogger()
+		})
+		base = fallbackLogger
+	}
+	return &boundLogger{base: base, logCtx: logCtx}
+}
+
+// boundLogger logs through base with the fields of logCtx.
+type boundLogger struct {
+	base   Logger
+	logCtx *LoggerContext
+}
+
+func (l *boundLogger) bind(ctx context.Context) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if l.logCtx == nil || GetLoggerContext(ctx) == l.logCtx {
+		return ctx
+	}
+	return context.WithValue(ctx, LoggerKey, l.logCtx)
+}
+
+func (l *boundLogger) Debug(ctx context.Context, msg string, args ...any) {
+	l.base.Debug(l.bind(ctx), msg, args...)
+}
+
+func (l *boundLogger) Trace(ctx context.Context, msg string, args ...any) {
+	l.base.Trace(l.bind(ctx), msg, args...)
+}
+
+func (l *boundLogger) Info(ctx context.Context, msg string, args ...any) {
+	l.base.Info(l.bind(ctx), msg, args...)
+}
+
+func (l *boundLogger) Warning(ctx context.Context, msg string, args ...any) {
+	l.base.Warning(l.bind(ctx), msg, args...)
+}
+
+func (l *boundLogger) Error(ctx context.Context, msg string, args ...any) {
+	l.base.Error(l.bind(ctx), msg, args...)
+}
+
+func (l *boundLogger) Fatal(ctx context.Context, msg string, args ...any) {
+	l.base.Fatal(l.bind(ctx), msg, args...)
+}
+
+// Close does nothing: the request's logger outlives the request.
+func (l *boundLogger) Close() error {
+	return nil
+}
+
+func (l *boundLogger) With(args ...any) StructuredLogger {
+	return withArgs(l, nil, args)
+}
+
+func (l *boundLogger) WithGroup(name string) StructuredLogger {
+	return withGroup(l, nil, name)
+}
@@ -0,0 +1,43 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func newBufferLogger(buf *bytes.Buffer) *ConsoleLogger {
+	opts, level := withLevelVar(&slog.HandlerOptions{Level: LevelDebug})
+	return &ConsoleLogger{logger: slog.New(newContextHandler(buf, LoggerJSONFormat, opts)), level: level}
+}
+
+func TestFromContextCarriesRequestFields(t *testing.T) {
+	var buf bytes.Buffer
+	ctx := WithLoggerContext(context.Background())
+	SetLogger(ctx, newBufferLogger(&buf))
+	AddInfo(ctx, "logid", "abc123")
+
+	log := FromContext(ctx)
+	AddInfo(ctx, "user", "ann") // added after FromContext, still attached
+	log.Info(context.Background(), "cache miss", "key", "k1")
+
+	line := buf.String()
+	for _, want := range []string{`"msg":"cache miss"`, `"key":"k1"`, `"logid":"abc123"`, `"user":"ann"`} {
+		if !strings.Contains(line, want) {
+			t.Errorf("record %s lacks %s", line, want)
+		}
+	}
+	if err := log.Close(); err != nil {
+		t.Errorf("Close = %v", err)
+	}
+}
+
+func TestFromContextWithoutLogger(t *testing.T) {
+	if FromContext(context.Background()) == nil {
+		t.Fatal("FromContext returned nil without a request logger")
+	}
+	ctx := WithLoggerContext(context.Background())
+	FromContext(ctx).Debug(ctx, "falls back to the console")
+}
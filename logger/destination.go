@@ -0,0 +1,27 @@
+package logger
+
+import "fmt"
+
+// Destination describes where l writes, for startup diagnostics: the file
+// of a FileLogger followed by its wf file, "stdout" for a ConsoleLogger and
+// the type of any other logger.
+func Destination(l Logger) string {
+	switch l := l.(type) {
+	case nil:
+		return ""
+	case *FileLogger:
+		if l.wf != nil {
+			return l.filePath + ", " + l.wf.filePath
+		}
+		return l.filePath
+	case *ConsoleLogger:
+		return "stdout"
+	default:
+		return fmt.Sprintf("%T", l)
+	}
+}
+
+// FilePath returns the file panics are written to.
+func (l *PanicLogger) FilePath() string {
+	return l.filePath
+}
@@ -0,0 +1,31 @@
+package logger
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestDestination(t *testing.T) {
+	console, _ := NewLogger()
+	if got := Destination(console); got != "stdout" {
+		t.Errorf("Destination(console) = %q, want stdout", got)
+	}
+
+	dir := t.TempDir()
+	conf := &Config{LoggerConfig: LoggerConfig{
+		Dir: dir, FileName: "app.log", MinLevel: "INFO", RotateRule: "no",
+		WF: WFConfig{Enable: true, MinLevel: "WARN", RotateRule: "no"},
+	}}
+	l, err := newLoggerFromConfig(conf, defaultHandlerOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	want := filepath.Join(dir, "app.log") + ", " + filepath.Join(dir, "app.log.wf")
+	if got := Destination(l); got != want {
+		t.Errorf("Destination(file) = %q, want %q", got, want)
+	}
+	if Destination(nil) != "" {
+		t.Error("Destination(nil) is not empty")
+	}
+}
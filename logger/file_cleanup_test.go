@@ -397,3 +397,74 @@ func TestCleanOldLogFiles_PanicLog(t *testing.T) {
 		t.Error("panic.log.20260118 should still exist")
 	}
 }
+
+func TestRemoveExpiredLogFiles(t *testing.T) {
+	tempDir := t.TempDir()
+	baseLogFile := filepath.Join(tempDir, "app.log")
+
+	// 1, 2 and 3 days old, plus a file of another logger and the current file
+	names := []string{"app.log.20260115", "app.log.20260116", "app.log.20260117", "other.log.20260115", "app.log"}
+	for i, name := range names {
+		filePath := filepath.Join(tempDir, name)
+		if err := os.WriteFile(filePath, nil, 0644); err != nil {
+			t.Fatalf("Failed to create file: %v", err)
+		}
+		modTime := time.Now().Add(-time.Duration(3-i%3) * 24 * time.Hour)
+		os.Chtimes(filePath, modTime, modTime)
+	}
+
+	removeExpiredLogFiles(tempDir, baseLogFile, 36*time.Hour)
+
+	for _, name := range []string{"app.log.20260115", "app.log.20260116"} {
+		if _, err := os.Stat(filepath.Join(tempDir, name)); !os.IsNotExist(err) {
+			t.Errorf("%s should be deleted", name)
+		}
+	}
+	for _, name := range []string{"app.log.20260117", "other.log.20260115", "app.log"} {
+		if _, err := os.Stat(filepath.Join(tempDir, name)); err != nil {
+			t.Errorf("%s should be kept: %v", name, err)
+		}
+	}
+
+	// zero maxAge keeps everything
+	removeExpiredLogFiles(tempDir, baseLogFile, 0)
+	if _, err := os.Stat(filepath.Join(tempDir, "app.log.20260117")); err != nil {
+		t.Errorf("maxAge 0 deleted a file: %v", err)
+	}
+}
+
+func TestFileLoggerMaxAge(t *testing.T) {
+	tempDir := t.TempDir()
+	old := filepath.Join(tempDir, "app.log.2026011512")
+	if err := os.WriteFile(old, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	modTime := time.Now().Add(-48 * time.Hour)
+	os.Chtimes(old, modTime, modTime)
+
+	conf := filepath.Join(tempDir, "logger.toml")
+	content := "[logger]\ndir = \"" + filepath.ToSlash(tempDir) + "\"\nfilename = \"app.log\"\nrotateRule = \"no\"\nmaxAge = \"24h\"\n"
+	if err := os.WriteFile(conf, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	l, err := NewLogger(conf)
+	if err != nil {
+		t.Fatalf("NewLogger: %v", err)
+	}
+	defer l.Close()
+	if got := l.(*FileLogger).logConf.MaxAge; got != 24*time.Hour {
+		t.Fatalf("MaxAge = %v, want 24h", got)
+	}
+
+	// cleanup runs at startup without waiting for a rotation
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if _, err := os.Stat(old); os.IsNotExist(err) {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("expired file was not deleted")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -9,23 +9,35 @@ import (
 	"sort"
 	"sync"
 	"time"
+
+	"github.com/hansir-hsj/GoLiteKit/workerpool"
 )
 
 var _ Rotator = (*FileLogger)(nil)
 
 type FileLogger struct {
-	logConf *Config
-	opts    *slog.HandlerOptions
+	logConf   *Config
+	level     *slog.LevelVar
+	redactor  *redactorVar
+	addSource bool
 
 	filePath string
 
 	lastRotate time.Time
 
+	// logger writes through writer for the logger's lifetime; rotation
+	// swaps the file behind writer.
 	logger *slog.Logger
+	writer *reopenWriter
 
-	file *os.File
+	// wf receives the records at or above its own level as well, see
+	// WFConfig.
+	wf *FileLogger
 
 	mu sync.Mutex
+
+	stopCleanup chan struct{}
+	closeOnce   sync.Once
 }
 
 func NewTextLogger(logConf *Config, opts *slog.HandlerOptions) (*FileLogger, error) {
@@ -40,21 +52,62 @@ func NewTextLogger(logConf *Config, opts *slog.HandlerOptions) (*FileLogger, err
 		fmt.Fprintf(os.Stderr, "warning: failed to rotate existing log file: %v\n", err)
 	}
 
-	target, err := os.OpenFile(filePath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0666)
+	writer, err := openReopenWriter(filePath, logConf.RotateMode)
 	if err != nil {
 		return nil, err
 	}
 
-	handler := newContextHandler(target, logConf.Format, opts)
+	var wf *FileLogger
+	if logConf.WF.Enable {
+		wf, err = newWFLogger(logConf, opts)
+		if err != nil {
+			writer.Close()
+			return nil, err
+		}
+	}
 
-	return &FileLogger{
-		logConf:    logConf,
-		opts:       opts,
-		filePath:   filePath,
-		logger:     slog.New(handler),
-		file:       target,
-		lastRotate: time.Now(),
-	}, nil
+	opts, level := withLevelVar(opts)
+	opts, redactor := withRedactorVar(opts)
+	handler := newContextHandler(writer, logConf.Format, opts)
+
+	l := &FileLogger{
+		logConf:     logConf,
+		level:       level,
+		redactor:    redactor,
+		addSource:   opts.AddSource,
+		filePath:    filePath,
+		logger:      slog.New(handler),
+		writer:      writer,
+		wf:          wf,
+		lastRotate:  time.Now(),
+		stopCleanup: make(chan struct{}),
+	}
+	startCleanup(logConf.MaxAge, l.stopCleanup, l.cleanOldFiles)
+	return l, nil
+}
+
+// newWFLogger opens the wf file of logConf with its own level, rotation and
+// retention; format and schema are shared with the main file.
+func newWFLogger(logConf *Config, opts *slog.HandlerOptions) (*FileLogger, error) {
+	level, err := ParseLevel(logConf.WF.MinLevel)
+	if err != nil {
+		return nil, fmt.Errorf("wf: %w", err)
+	}
+
+	wfConf := *logConf
+	wfConf.FileName = logConf.WFFileName()
+	wfConf.MinLevel = logConf.WF.MinLevel
+	wfConf.RotateRule = logConf.WF.RotateRule
+	wfConf.MaxFileNum = logConf.WF.MaxFileNum
+	wfConf.MaxAge = logConf.WF.MaxAge
+	wfConf.WF = WFConfig{}
+
+	wfOpts := slog.HandlerOptions{}
+	if opts != nil {
+		wfOpts = *opts
+	}
+	wfOpts.Level = level
+	return NewTextLogger(&wfConf, &wfOpts)
 }
 
 func rotateExistingFileIfNeeded(filePath string, logConf *Config) error {
@@ -113,7 +166,7 @@ func rotateExistingFileIfNeeded(filePath string, logConf *Config) error {
 		return nil
 	}
 
-	return os.Rename(filePath, newFilePath)
+	return rotateLogFile(filePath, newFilePath, logConf.RotateMode)
 }
 
 func (l *FileLogger) needRotate() bool {
@@ -146,45 +199,23 @@ func (l *FileLogger) NeedRotate() bool {
 	return l.needRotate()
 }
 
-// rotate opens a new file first, then renames the old one, then swaps handles.
-// This ensures l.file is always valid even if rename fails.
+// rotate moves the current file aside and continues in a new one. Writes
+// from other goroutines are not held up by l.mu; the writer makes them wait
+// for the swap, see reopenWriter.
 func (l *FileLogger) rotate() error {
-	newFilePath := l.newFilePath(l.lastRotate)
-
-	// Step 1: Open new file first
-	newTarget, err := os.OpenFile(l.filePath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
-	if err != nil {
-		return fmt.Errorf("rotate: open new file failed: %w", err)
-	}
-
-	// Step 2: Close and rename old file
-	oldTarget := l.file
-	if err := oldTarget.Close(); err != nil {
-		newTarget.Close()
-		return fmt.Errorf("rotate: close failed: %w", err)
-	}
-	if err := os.Rename(l.filePath, newFilePath); err != nil {
-		newTarget.Close()
-		l.file, _ = os.OpenFile(l.filePath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0666)
-		return fmt.Errorf("rotate: rename failed: %w", err)
+	if err := countRotation(l.writer.rotate(l.newFilePath(l.lastRotate))); err != nil {
+		return err
 	}
-
-	// Step 3: Swap handle
-	l.file = newTarget
-	handler := newContextHandler(newTarget, l.logConf.Format, l.opts)
-	l.logger = slog.New(handler)
 	l.lastRotate = time.Now()
 
-	go l.cleanOldFiles()
+	scheduleCleanup(l.cleanOldFiles)
 
 	return nil
 }
 
 func (l *FileLogger) cleanOldFiles() {
-	if l.logConf.MaxFileNum <= 0 {
-		return
-	}
 	cleanOldLogFiles(l.logConf.Dir, l.filePath, l.logConf.MaxFileNum)
+	removeExpiredLogFiles(l.logConf.Dir, l.filePath, l.logConf.MaxAge)
 }
 
 func cleanOldLogFiles(dir string, filePath string, maxFileNum int) {
@@ -192,14 +223,57 @@ func cleanOldLogFiles(dir string, filePath string, maxFileNum int) {
 		return
 	}
 
-	baseFileName := filepath.Base(filePath)
+	rotatedFiles, err := rotatedLogFiles(dir, filePath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to read log directory for cleanup: %v\n", err)
+		return
+	}
 
-	entries, err := os.ReadDir(dir)
+	if len(rotatedFiles) <= maxFileNum {
+		return
+	}
+
+	sortFilesByModTime(dir, rotatedFiles)
+
+	deleteCount := len(rotatedFiles) - maxFileNum
+	for i := 0; i < deleteCount; i++ {
+		removeLogFile(filepath.Join(dir, rotatedFiles[i].Name()))
+	}
+}
+
+// removeExpiredLogFiles deletes the rotated files of filePath last modified
+// more than maxAge ago. A maxAge of zero keeps them.
+func removeExpiredLogFiles(dir string, filePath string, maxAge time.Duration) {
+	if maxAge <= 0 {
+		return
+	}
+
+	rotatedFiles, err := rotatedLogFiles(dir, filePath)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "failed to read log directory for cleanup: %v\n", err)
 		return
 	}
 
+	cutoff := time.Now().Add(-maxAge)
+	for _, entry := range rotatedFiles {
+		info, err := entry.Info()
+		if err != nil || !info.ModTime().Before(cutoff) {
+			continue
+		}
+		removeLogFile(filepath.Join(dir, entry.Name()))
+	}
+}
+
+// rotatedLogFiles lists the files in dir named filePath's base name followed
+// by a rotation timestamp.
+func rotatedLogFiles(dir string, filePath string) ([]os.DirEntry, error) {
+	baseFileName := filepath.Base(filePath)
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
 	var rotatedFiles []os.DirEntry
 	for _, entry := range entries {
 		if entry.IsDir() {
@@ -213,20 +287,53 @@ func cleanOldLogFiles(dir string, filePath string, maxFileNum int) {
 			}
 		}
 	}
+	return rotatedFiles, nil
+}
 
-	if len(rotatedFiles) <= maxFileNum {
-		return
+// removeLogFile deletes path; a file already removed by a concurrent
+// cleanup is not an error.
+func removeLogFile(path string) {
+	err := os.Remove(path)
+	if err == nil {
+		stats.cleanupDeletions.Add(1)
+	} else if !os.IsNotExist(err) {
+		fmt.Fprintf(os.Stderr, "failed to remove old log file %s: %v\n", path, err)
 	}
+}
 
-	sortFilesByModTime(dir, rotatedFiles)
+// cleanupPool runs the cleanups rotation triggers. A cleanup that does not
+// fit the queue is skipped; the next one removes the same files.
+var cleanupPool = workerpool.New(workerpool.Options{Workers: 2, QueueSize: 8})
 
-	deleteCount := len(rotatedFiles) - maxFileNum
-	for i := 0; i < deleteCount; i++ {
-		targetPath := filepath.Join(dir, rotatedFiles[i].Name())
-		if err := os.Remove(targetPath); err != nil {
-			fmt.Fprintf(os.Stderr, "failed to remove old log file %s: %v\n", targetPath, err)
-		}
+func scheduleCleanup(clean func()) {
+	cleanupPool.TrySubmit(context.Background(), func(context.Context) { clean() })
+}
+
+// maxCleanupInterval bounds how long an expired file survives when rotation
+// is infrequent.
+const maxCleanupInterval = time.Hour
+
+// startCleanup runs clean now and then periodically until stop is closed,
+// when maxAge is set. Count-based cleanup alone only needs to run on
+// rotation, the only time files are added.
+func startCleanup(maxAge time.Duration, stop <-chan struct{}, clean func()) {
+	if maxAge <= 0 {
+		return
 	}
+	interval := min(maxAge, maxCleanupInterval)
+	go func() {
+		clean()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				clean()
+			}
+		}
+	}()
 }
 
 func isDigits(s string) bool {
@@ -337,13 +444,46 @@ func (l *FileLogger) Fatal(ctx context.Context, msg string, args ...any) {
 	l.logit(ctx, LevelFatal, msg, args...)
 }
 
+// With returns a logger that adds args to every record.
+func (l *FileLogger) With(args ...any) StructuredLogger {
+	return withArgs(l, nil, args)
+}
+
+// WithGroup returns a logger that nests the attributes of every record under
+// name.
+func (l *FileLogger) WithGroup(name string) StructuredLogger {
+	return withGroup(l, nil, name)
+}
+
+// Level returns the current minimum level.
+func (l *FileLogger) Level() slog.Level {
+	return l.level.Level()
+}
+
+// SetLevel changes the minimum level; it takes effect for the next record.
+func (l *FileLogger) SetLevel(level slog.Level) {
+	l.level.Set(level)
+}
+
+// SetRedactor replaces the Redactor applied to records, in the current and
+// rotated files; nil turns redaction off.
+func (l *FileLogger) SetRedactor(r Redactor) {
+	l.redactor.Store(r)
+	if l.wf != nil {
+		l.wf.SetRedactor(r)
+	}
+}
+
 func (l *FileLogger) Close() error {
-	l.mu.Lock()
-	defer l.mu.Unlock()
-	if l.file != nil {
-		return l.file.Close()
+	l.closeOnce.Do(func() { close(l.stopCleanup) })
+	var wfErr error
+	if l.wf != nil {
+		wfErr = l.wf.Close()
 	}
-	return nil
+	if err := l.writer.Close(); err != nil {
+		return err
+	}
+	return wfErr
 }
 
 func (l *FileLogger) logit(ctx context.Context, level slog.Level, format string, args ...any) {
@@ -351,20 +491,23 @@ func (l *FileLogger) logit(ctx context.Context, level slog.Level, format string,
 }
 
 func (l *FileLogger) log(ctx context.Context, level slog.Level, msg string, args ...any) {
-	l.mu.Lock()
-	defer l.mu.Unlock()
+	// callerSkip=6: logRecord -> write -> log -> logit -> Debug/Info/... -> user code
+	l.write(ctx, level, msg, 6, args...)
+	if l.wf != nil {
+		l.wf.write(ctx, level, msg, 6, args...)
+	}
+}
 
+func (l *FileLogger) write(ctx context.Context, level slog.Level, msg string, callerSkip int, args ...any) {
 	if !l.logger.Enabled(ctx, level) {
 		return
 	}
 
-	if l.needRotate() {
-		if err := l.rotate(); err != nil {
-			fmt.Fprintf(os.Stderr, "failed to rotate log file: %v\n", err)
-		}
+	if err := l.rotateIfNeeded(); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to rotate log file: %v\n", err)
 	}
 
-	if err := logRecord(ctx, l.logger.Handler(), level, msg, 5, args...); err != nil {
+	if err := logRecord(ctx, l.logger.Handler(), level, msg, l.addSource, callerSkip, args...); err != nil {
 		fmt.Fprintf(os.Stderr, "failed to log message: %v\n", err)
 	}
 }
@@ -0,0 +1,164 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+var errWriterClosed = errors.New("log file already closed")
+
+const (
+	// RotateModeRename renames the current file and opens a new one.
+	RotateModeRename = "rename"
+	// RotateModeCopyTruncate copies the current file and truncates it in
+	// place, for files other processes keep open, which Windows refuses to
+	// rename.
+	RotateModeCopyTruncate = "copytruncate"
+)
+
+// reopenWriter is the io.Writer behind a file logger. Rotation swaps the file
+// it writes to instead of the handler, so a record being written while the
+// file rotates lands whole in either the old or the new file, never in a
+// closed one.
+type reopenWriter struct {
+	path string
+	mode string
+
+	// mu is held for reading by writes and for writing while the file is
+	// swapped or closed, so the old file is closed only once no write is
+	// using it.
+	mu     sync.RWMutex
+	file   *os.File
+	closed bool
+}
+
+func openReopenWriter(path string, mode string) (*reopenWriter, error) {
+	file, err := openLogFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return &reopenWriter{path: path, mode: mode, file: file}, nil
+}
+
+func openLogFile(path string) (*os.File, error) {
+	return os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0666)
+}
+
+func (w *reopenWriter) Write(p []byte) (int, error) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if w.closed {
+		return 0, errWriterClosed
+	}
+	return w.file.Write(p)
+}
+
+// rotate moves the content written so far to rotatedPath, by renaming or by
+// copy-truncate depending on the mode, and continues at the original path.
+// Writes wait for it, so none are lost or go to the rotated file once it
+// returns. On failure writing continues in the current file.
+func (w *reopenWriter) rotate(rotatedPath string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return errWriterClosed
+	}
+	if rotatedPath == w.path {
+		return nil
+	}
+
+	if w.mode == RotateModeCopyTruncate {
+		if err := copyLogFile(w.path, rotatedPath); err != nil {
+			return fmt.Errorf("rotate: copy failed: %w", err)
+		}
+		if err := w.file.Truncate(0); err != nil {
+			return fmt.Errorf("rotate: truncate failed: %w", err)
+		}
+		return nil
+	}
+
+	if !canRenameOpenFile {
+		if err := w.file.Close(); err != nil {
+			return fmt.Errorf("rotate: close failed: %w", err)
+		}
+	}
+	if err := os.Rename(w.path, rotatedPath); err != nil {
+		return w.keepFile(fmt.Errorf("rotate: rename failed: %w", err))
+	}
+	file, err := openLogFile(w.path)
+	if err != nil {
+		if undoErr := os.Rename(rotatedPath, w.path); undoErr != nil {
+			err = fmt.Errorf("%w (undo rename: %v)", err, undoErr)
+		}
+		return w.keepFile(fmt.Errorf("rotate: open new file failed: %w", err))
+	}
+
+	if canRenameOpenFile {
+		if err := w.file.Close(); err != nil {
+			w.file = file
+			return fmt.Errorf("rotate: close failed: %w", err)
+		}
+	}
+	w.file = file
+	return nil
+}
+
+// keepFile returns err after making sure writes can continue in the current
+// file, which was closed before the rename where open files cannot be
+// renamed.
+func (w *reopenWriter) keepFile(err error) error {
+	if canRenameOpenFile {
+		return err
+	}
+	file, openErr := openLogFile(w.path)
+	if openErr != nil {
+		return fmt.Errorf("%w; reopen failed: %v", err, openErr)
+	}
+	w.file = file
+	return err
+}
+
+// Close closes the current file; later writes fail with errWriterClosed.
+func (w *reopenWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	return w.file.Close()
+}
+
+// rotateLogFile moves path, which the logger does not hold open, to
+// rotatedPath.
+func rotateLogFile(path string, rotatedPath string, mode string) error {
+	if mode != RotateModeCopyTruncate {
+		return os.Rename(path, rotatedPath)
+	}
+	if err := copyLogFile(path, rotatedPath); err != nil {
+		return err
+	}
+	return os.Truncate(path, 0)
+}
+
+// copyLogFile copies src to dst, replacing dst.
+func copyLogFile(src string, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0666)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,8 @@
+//go:build !windows
+
+package logger
+
+// canRenameOpenFile reports whether a file can be renamed while the logger
+// holds it open, which lets rename rotation open the new file before closing
+// the old one.
+const canRenameOpenFile = true
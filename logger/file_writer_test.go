@@ -0,0 +1,164 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestReopenWriter_ConcurrentRotate(t *testing.T) {
+	for _, mode := range []string{RotateModeRename, RotateModeCopyTruncate} {
+		t.Run(mode, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, "app.log")
+			w, err := openReopenWriter(path, mode)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			const writers, lines, rotations = 8, 200, 20
+			var wg sync.WaitGroup
+			for i := 0; i < writers; i++ {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					for j := 0; j < lines; j++ {
+						if _, err := fmt.Fprintf(w, "writer=%d line=%d\n", i, j); err != nil {
+							t.Errorf("write: %v", err)
+							return
+						}
+					}
+				}(i)
+			}
+			for r := 0; r < rotations; r++ {
+				if err := w.rotate(fmt.Sprintf("%s.%08d", path, r)); err != nil {
+					t.Fatalf("rotate: %v", err)
+				}
+			}
+			wg.Wait()
+			if err := w.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			got := readLogLines(t, path)
+			if len(got) != writers*lines {
+				t.Fatalf("got %d lines, want %d", len(got), writers*lines)
+			}
+			for _, line := range got {
+				if !strings.HasPrefix(line, "writer=") {
+					t.Fatalf("torn line %q", line)
+				}
+			}
+
+			if _, err := w.Write([]byte("late\n")); err != errWriterClosed {
+				t.Errorf("write after Close = %v, want errWriterClosed", err)
+			}
+		})
+	}
+}
+
+// readLogLines returns the lines of path and of its rotated files.
+func readLogLines(t *testing.T, path string) []string {
+	t.Helper()
+	files, _ := filepath.Glob(path + "*")
+	var all []byte
+	for _, f := range files {
+		data, err := os.ReadFile(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		all = append(all, data...)
+	}
+	return strings.Split(strings.TrimSuffix(string(all), "\n"), "\n")
+}
+
+func TestReopenWriter_RotateFailureKeepsFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	w, err := openReopenWriter(path, RotateModeRename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	if err := w.rotate(filepath.Join(dir, "missing", "app.log.1")); err == nil {
+		t.Fatal("rotate into a missing directory succeeded")
+	}
+	if _, err := w.Write([]byte("still here\n")); err != nil {
+		t.Fatalf("write after failed rotate: %v", err)
+	}
+	if data, _ := os.ReadFile(path); !bytes.Equal(data, []byte("still here\n")) {
+		t.Errorf("app.log = %q", data)
+	}
+}
+
+func TestFileLogger_ConcurrentLogAndRotate(t *testing.T) {
+	for _, mode := range []string{RotateModeRename, RotateModeCopyTruncate} {
+		t.Run(mode, func(t *testing.T) {
+			dir := t.TempDir()
+			conf := &Config{LoggerConfig: LoggerConfig{Dir: dir, FileName: "app.log", RotateRule: "no", RotateMode: mode}}
+			l, err := NewTextLogger(conf, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			const writers, lines = 8, 200
+			var wg sync.WaitGroup
+			for i := 0; i < writers; i++ {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					ctx := context.Background()
+					for j := 0; j < lines; j++ {
+						l.Info(ctx, "line", "writer", i, "n", j)
+					}
+				}(i)
+			}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for r := 0; r < 50; r++ {
+					if err := l.Rotate(); err != nil {
+						t.Errorf("Rotate: %v", err)
+						return
+					}
+					if err := l.writer.rotate(fmt.Sprintf("%s.%08d", l.filePath, r)); err != nil {
+						t.Errorf("rotate: %v", err)
+						return
+					}
+				}
+			}()
+			wg.Wait()
+			if err := l.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			got := readLogLines(t, l.filePath)
+			if n := strings.Count(strings.Join(got, "\n"), "msg=line"); n != writers*lines {
+				t.Errorf("got %d records, want %d", n, writers*lines)
+			}
+		})
+	}
+}
+
+func TestRotateLogFile_CopyTruncate(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	if err := os.WriteFile(path, []byte("old\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := rotateLogFile(path, path+".2026011512", RotateModeCopyTruncate); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(path + ".2026011512"); string(data) != "old\n" {
+		t.Errorf("rotated file = %q", data)
+	}
+	if info, err := os.Stat(path); err != nil || info.Size() != 0 {
+		t.Errorf("app.log not truncated in place: %v, %v", info, err)
+	}
+}
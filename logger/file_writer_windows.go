@@ -0,0 +1,7 @@
+//go:build windows
+
+package logger
+
+// canRenameOpenFile is false because Windows refuses to rename a file while
+// it is open, so rename rotation closes the file first.
+const canRenameOpenFile = false
@@ -0,0 +1,110 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+)
+
+// LevelController is implemented by loggers whose minimum level can be
+// changed while the process is running.
+type LevelController interface {
+	Level() slog.Level
+	SetLevel(level slog.Level)
+}
+
+var (
+	_ LevelController = (*ConsoleLogger)(nil)
+	_ LevelController = (*FileLogger)(nil)
+)
+
+// ParseLevel maps a level name from LevelMap (case-insensitive) to its slog level.
+func ParseLevel(name string) (slog.Level, error) {
+	level, ok := LevelMap[strings.ToUpper(strings.TrimSpace(name))]
+	if !ok {
+		return 0, fmt.Errorf("invalid log level: %s", name)
+	}
+	return level, nil
+}
+
+// LevelName returns the label used in log output for level.
+func LevelName(level slog.Level) string {
+	if name, ok := LevelNames[level]; ok {
+		return name
+	}
+	return level.String()
+}
+
+// SetLevel atomically changes the minimum level of l. It fails when l does
+// not implement LevelController.
+func SetLevel(l Logger, level slog.Level) error {
+	lc, ok := l.(LevelController)
+	if !ok {
+		return fmt.Errorf("logger %T does not support runtime level changes", l)
+	}
+	lc.SetLevel(level)
+	return nil
+}
+
+// ConfigLevel reads the minimum level from a logger config file.
+func ConfigLevel(configPath string) (slog.Level, error) {
+	conf, err := parse(configPath)
+	if err != nil {
+		return 0, err
+	}
+	return ParseLevel(conf.MinLevel)
+}
+
+// ReloadLevelOnSignal re-reads the level from configPath and applies it to l
+// whenever one of signals (SIGHUP by default) arrives, until ctx is done.
+// Reload failures are reported on stderr and keep the current level.
+func ReloadLevelOnSignal(ctx context.Context, l Logger, configPath string, signals ...os.Signal) error {
+	lc, ok := l.(LevelController)
+	if !ok {
+		return fmt.Errorf("logger %T does not support runtime level changes", l)
+	}
+	if configPath == "" {
+		return fmt.Errorf("logger config path is required to reload the level")
+	}
+	if len(signals) == 0 {
+		signals = []os.Signal{syscall.SIGHUP}
+	}
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, signals...)
+	go func() {
+		defer signal.Stop(ch)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ch:
+				level, err := ConfigLevel(configPath)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "golitekit/logger: reload level from %s: %v\n", configPath, err)
+					continue
+				}
+				lc.SetLevel(level)
+			}
+		}
+	}()
+	return nil
+}
+
+// withLevelVar returns a copy of opts whose Level is a LevelVar seeded from
+// the configured level, so handlers observe later SetLevel calls.
+func withLevelVar(opts *slog.HandlerOptions) (*slog.HandlerOptions, *slog.LevelVar) {
+	levelVar := new(slog.LevelVar)
+	if opts == nil {
+		return &slog.HandlerOptions{Level: levelVar}, levelVar
+	}
+	if opts.Level != nil {
+		levelVar.Set(opts.Level.Level())
+	}
+	copied := *opts
+	copied.Level = levelVar
+	return &copied, levelVar
+}
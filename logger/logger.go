@@ -6,7 +6,6 @@ import (
 	"log/slog"
 	"os"
 	"path/filepath"
-	"strings"
 	"time"
 
 	"github.com/hansir-hsj/GoLiteKit/config"
@@ -16,6 +15,10 @@ const (
 	LoggerConfigFile = "logger.toml"
 	LoggerTextFormat = "text"
 	LoggerJSONFormat = "json"
+	// LoggerECSFormat writes JSON with Elastic Common Schema field names.
+	LoggerECSFormat = "ecs"
+	// LoggerLogfmtFormat writes logfmt with "ts" and lowercase levels.
+	LoggerLogfmtFormat = "logfmt"
 )
 
 const (
@@ -37,6 +40,25 @@ type Logger interface {
 	Close() error
 }
 
+// StructuredLogger is a Logger that derives child loggers, like slog's
+// Logger.With and Logger.WithGroup. ConsoleLogger, FileLogger and the logger
+// returned by FromContext implement it.
+type StructuredLogger interface {
+	Logger
+	// With returns a logger that adds args, as key-value pairs or slog.Attr
+	// values, to every record.
+	With(args ...any) StructuredLogger
+	// WithGroup returns a logger that nests the attributes of every record,
+	// including those added later by With, under name. The fields attached
+	// to the request context stay at the top level.
+	WithGroup(name string) StructuredLogger
+}
+
+var (
+	_ StructuredLogger = (*ConsoleLogger)(nil)
+	_ StructuredLogger = (*FileLogger)(nil)
+)
+
 var LevelNames = map[slog.Leveler]string{
 	LevelTrace: "TRACE",
 	LevelFatal: "FATAL",
@@ -52,17 +74,74 @@ var LevelMap = map[string]slog.Level{
 }
 
 type LoggerConfig struct {
-	Dir      string `toml:"dir"`
+	Dir      string `toml:"dir" default:"logs"`
 	FileName string `toml:"filename"`
-	MinLevel string `toml:"level"`
+	MinLevel string `toml:"level" default:"INFO"`
 	Format   string `toml:"format"`
+	// Rename renames top-level keys as written by Format, e.g.
+	// {time = "ts", msg = "message"}.
+	Rename map[string]string `toml:"rename"`
+	// Redact masks sensitive keys and patterns, see RedactConfig.
+	Redact RedactConfig `toml:"redact"`
+
+	RotateRule string `toml:"rotateRule" default:"1hour" validate:"oneof=1hour 1day 1min 5min 10min 30min no"`
+	MaxFileNum int    `toml:"maxFileNum" default:"48" validate:"min=0"`
+	// RotateMode is RotateModeRename or RotateModeCopyTruncate; use the
+	// latter when other processes, such as log shippers on Windows, keep the
+	// file open.
+	RotateMode string `toml:"rotateMode" default:"rename" validate:"oneof=rename copytruncate"`
+	// MaxAge deletes rotated files older than it, e.g. "168h"; zero keeps
+	// them. Files are checked at least hourly, not only on rotation.
+	MaxAge time.Duration `toml:"maxAge" validate:"min=0"`
+
+	// WF copies records at or above its level to a second file.
+	WF WFConfig `toml:"wf"`
+	// Status sets the level of the access line by response status.
+	Status StatusLevelConfig `toml:"status"`
+}
+
+// StatusLevelConfig is the [logger.status] section of logger.toml: the
+// access line of a request answered with Warn or above is logged at WARN,
+// with Error or above at ERROR, and at INFO otherwise. Bodies adds the
+// request body to those lines even when request bodies are not logged.
+type StatusLevelConfig struct {
+	Warn   int  `toml:"warn" default:"400" validate:"min=100"`
+	Error  int  `toml:"error" default:"500" validate:"min=100"`
+	Bodies bool `toml:"bodies"`
+}
+
+// LoadStatusLevels returns the [logger.status] section of the config file.
+func LoadStatusLevels(configPath string) (StatusLevelConfig, error) {
+	conf, err := parse(configPath)
+	if err != nil {
+		return StatusLevelConfig{}, err
+	}
+	return conf.Status, nil
+}
 
-	RotateRule string `toml:"rotateRule"`
-	MaxFileNum int    `toml:"maxFileNum"`
+// WFConfig is the [logger.wf] section of logger.toml: the "wf" file that
+// receives WARN+ records in addition to the main file, rotated and cleaned
+// up on its own schedule.
+type WFConfig struct {
+	Enable bool `toml:"enable"`
+	// FileName defaults to the main file name with a ".wf" suffix.
+	FileName   string        `toml:"filename"`
+	MinLevel   string        `toml:"level" default:"WARN"`
+	RotateRule string        `toml:"rotateRule" default:"1hour" validate:"oneof=1hour 1day 1min 5min 10min 30min no"`
+	MaxFileNum int           `toml:"maxFileNum" default:"48" validate:"min=0"`
+	MaxAge     time.Duration `toml:"maxAge" validate:"min=0"`
 }
 
 type Config struct {
 	LoggerConfig `toml:"logger"`
+	// Loggers are the [loggers.<name>] sections, see NewNamedLoggers.
+	Loggers map[string]LoggerConfig `toml:"loggers"`
+}
+
+// ConfigEnvNamespace names the environment variable overrides of the file
+// like GLK_LOGGER_LEVEL, whatever the file is called.
+func (c *Config) ConfigEnvNamespace() string {
+	return "logger"
 }
 
 func parse(conf string) (*Config, error) {
@@ -70,31 +149,12 @@ func parse(conf string) (*Config, error) {
 	if err := config.Parse(conf, &lConfig); err != nil {
 		return nil, err
 	}
-	if lConfig.Dir == "" {
-		lConfig.Dir = "logs"
-	}
 	absDir, err := filepath.Abs(lConfig.Dir)
 	if err != nil {
 		return nil, err
 	}
 	lConfig.Dir = absDir
 
-	if lConfig.RotateRule == "" {
-		lConfig.RotateRule = "1hour"
-	}
-	switch lConfig.RotateRule {
-	case "1hour", "1day", "1min", "5min", "10min", "30min", "no":
-		break
-	default:
-		return nil, fmt.Errorf("invalid rotate rule: %s", lConfig.RotateRule)
-	}
-	if lConfig.MaxFileNum == 0 {
-		lConfig.MaxFileNum = 48
-	}
-	if lConfig.MinLevel == "" {
-		lConfig.MinLevel = "INFO"
-	}
-
 	return &lConfig, nil
 }
 
@@ -106,19 +166,33 @@ func (c *Config) LogFileName() string {
 	return filepath.Join(c.Dir, name)
 }
 
+// WFFileName returns the name of the wf file within Dir.
+func (c *Config) WFFileName() string {
+	if c.WF.FileName != "" {
+		return c.WF.FileName
+	}
+	name := c.FileName
+	if name == "" {
+		name = "app.log"
+	}
+	return name + ".wf"
+}
+
 func (c *Config) PanicFileName() string {
 	return filepath.Join(c.Dir, "panic.log")
 }
 
-func NewLogger(loggerConfig ...string) (Logger, error) {
-	opts := &slog.HandlerOptions{
+// defaultHandlerOptions returns the options NewLogger starts from: DEBUG and
+// above, no source, and the time and level written as in logTimeLayout and
+// LevelName.
+func defaultHandlerOptions() *slog.HandlerOptions {
+	return &slog.HandlerOptions{
 		Level:     LevelDebug,
 		AddSource: false,
 		ReplaceAttr: func(groups []string, attr slog.Attr) slog.Attr {
-			if attr.Key == slog.TimeKey {
-				if t, ok := attr.Value.Any().(time.Time); ok {
-					attr.Value = slog.StringValue(t.Format("2006-01-02 15:04:05.000"))
-				}
+			// Kind and Time, unlike Any, do not box the time.
+			if attr.Key == slog.TimeKey && attr.Value.Kind() == slog.KindTime {
+				attr.Value = slog.StringValue(formatLogTime(attr.Value.Time()))
 			}
 			if attr.Key == slog.LevelKey {
 				level := attr.Value.Any().(slog.Level)
@@ -131,6 +205,10 @@ func NewLogger(loggerConfig ...string) (Logger, error) {
 			return attr
 		},
 	}
+}
+
+func NewLogger(loggerConfig ...string) (Logger, error) {
+	opts := defaultHandlerOptions()
 
 	if len(loggerConfig) == 0 {
 		return NewConsoleLogger(opts)
@@ -140,15 +218,33 @@ func NewLogger(loggerConfig ...string) (Logger, error) {
 	if err != nil {
 		return nil, err
 	}
+	return newLoggerFromConfig(logConf, opts)
+}
 
-	logLevel, ok := LevelMap[strings.ToUpper(logConf.MinLevel)]
-	if !ok {
-		return nil, fmt.Errorf("invalid log level: %s", logConf.MinLevel)
+// newLoggerFromConfig builds the logger described by logConf's [logger]
+// section, starting from opts.
+func newLoggerFromConfig(logConf *Config, opts *slog.HandlerOptions) (Logger, error) {
+	logLevel, err := ParseLevel(logConf.MinLevel)
+	if err != nil {
+		return nil, err
 	}
 	opts.Level = logLevel
+	opts = schemaOptions(logConf.Format, logConf.Rename, opts)
+
+	redactor, err := NewKeyRedactor(logConf.Redact)
+	if err != nil {
+		return nil, err
+	}
 
 	if logConf.Dir != "" && logConf.FileName != "" {
-		return NewTextLogger(logConf, opts)
+		l, err := NewTextLogger(logConf, opts)
+		if err != nil {
+			return nil, err
+		}
+		if redactor != nil {
+			l.SetRedactor(redactor)
+		}
+		return l, nil
 	}
 
 	// Warn when the config is partially set: the user likely intended file
@@ -160,5 +256,12 @@ func NewLogger(loggerConfig ...string) (Logger, error) {
 			logConf.Dir, logConf.FileName)
 	}
 
-	return NewConsoleLogger(opts)
+	l, err := newConsoleLogger(opts, logConf.Format)
+	if err != nil {
+		return nil, err
+	}
+	if redactor != nil {
+		l.SetRedactor(redactor)
+	}
+	return l, nil
 }
@@ -0,0 +1,93 @@
+package logger
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+// newDiscardLogger returns a logger configured like NewLogger that writes to
+// io.Discard.
+func newDiscardLogger(format string, addSource bool) *ConsoleLogger {
+	opts := defaultHandlerOptions()
+	opts.Level = LevelInfo
+	opts.AddSource = addSource
+	opts, level := withLevelVar(opts)
+	opts, redactor := withRedactorVar(opts)
+	return &ConsoleLogger{
+		logger:    slog.New(newContextHandler(io.Discard, format, opts)),
+		level:     level,
+		redactor:  redactor,
+		addSource: addSource,
+	}
+}
+
+func BenchmarkLogger_NoArgs(b *testing.B) {
+	l := newDiscardLogger(LoggerJSONFormat, false)
+	ctx := context.Background()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		l.Info(ctx, "request served")
+	}
+}
+
+func BenchmarkLogger_Args(b *testing.B) {
+	l := newDiscardLogger(LoggerJSONFormat, false)
+	ctx := context.Background()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		l.Info(ctx, "request served", slog.Int("status", 200), slog.String("path", "/users"))
+	}
+}
+
+func BenchmarkLogger_AddSource(b *testing.B) {
+	l := newDiscardLogger(LoggerJSONFormat, true)
+	ctx := context.Background()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		l.Info(ctx, "request served")
+	}
+}
+
+func BenchmarkLogger_ContextFields(b *testing.B) {
+	l := newDiscardLogger(LoggerJSONFormat, false)
+	ctx := WithLoggerContext(context.Background())
+	AddInfo(ctx, "logid", "abc123")
+	AddInfo(ctx, "method", "GET")
+	AddInfo(ctx, "path", "/users")
+	AddInfo(ctx, "status", 200)
+	AddInfo(ctx, "latency_ms", 12)
+	AddInfo(ctx, "bytes", 512)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		l.Info(ctx, "request served")
+	}
+}
+
+func BenchmarkLogger_Disabled(b *testing.B) {
+	l := newDiscardLogger(LoggerJSONFormat, false)
+	ctx := context.Background()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		l.Debug(ctx, "not written", "n", i)
+	}
+}
+
+func TestFormatLogTime(t *testing.T) {
+	base := time.Date(2026, 1, 15, 12, 30, 45, 123_000_000, time.UTC)
+	if got := formatLogTime(base); got != "2026-01-15 12:30:45.123" {
+		t.Fatalf("formatLogTime = %q", got)
+	}
+	// same millisecond is served from the cache, others are formatted anew
+	if got := formatLogTime(base.Add(500 * time.Microsecond)); got != "2026-01-15 12:30:45.123" {
+		t.Errorf("same millisecond = %q", got)
+	}
+	if got := formatLogTime(base.Add(time.Millisecond)); got != "2026-01-15 12:30:45.124" {
+		t.Errorf("next millisecond = %q", got)
+	}
+	if got := formatLogTime(base.In(time.FixedZone("UTC+8", 8*3600))); got != "2026-01-15 20:30:45.123" {
+		t.Errorf("other location = %q", got)
+	}
+}
@@ -2,12 +2,19 @@ package logger
 
 import (
 	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
 	"testing"
+	"time"
 )
 
 func TestLogger(t *testing.T) {
 	ctx := WithLoggerContext(context.Background())
 	log, _ := NewLogger("logs/logger.toml")
+	defer log.Close()
 	log.Debug(ctx, "debug")
 	log.Trace(ctx, "trace")
 	log.Info(ctx, "info")
@@ -30,6 +37,7 @@ func TestStruct(t *testing.T) {
 	}
 	ctx := WithLoggerContext(context.Background())
 	log, _ := NewLogger("logs/logger.toml")
+	defer log.Close()
 	u := &User{
 		ID:        "user-12234",
 		FirstName: "Jan",
@@ -49,9 +57,206 @@ func TestConsole(t *testing.T) {
 func TestRotate(t *testing.T) {
 	ctx := WithLoggerContext(context.Background())
 	log, _ := NewLogger("logs/logger.toml")
+	defer log.Close()
 	for i := 0; i < 10000; i++ {
 		log.Info(ctx, "info", "times", i)
 	}
 	log.Trace(ctx, "new file")
 	log.Info(ctx, "new file")
 }
+
+func TestConsoleLogger_SetLevel(t *testing.T) {
+	l, err := NewConsoleLogger(&slog.HandlerOptions{Level: LevelInfo})
+	if err != nil {
+		t.Fatalf("NewConsoleLogger: %v", err)
+	}
+	if l.logger.Enabled(context.Background(), LevelDebug) {
+		t.Fatal("debug should be disabled at INFO")
+	}
+	l.SetLevel(LevelDebug)
+	if !l.logger.Enabled(context.Background(), LevelDebug) || l.Level() != LevelDebug {
+		t.Fatal("debug should be enabled after SetLevel(DEBUG)")
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	if level, err := ParseLevel("warn"); err != nil || level != LevelWarning {
+		t.Fatalf("ParseLevel(warn) = %v, %v", level, err)
+	}
+	if _, err := ParseLevel("loud"); err == nil {
+		t.Fatal("expected error for unknown level")
+	}
+	if got := LevelName(LevelTrace); got != "TRACE" {
+		t.Fatalf("LevelName(TRACE) = %q", got)
+	}
+}
+
+func TestReloadLevelOnSignal(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logger.toml")
+	if err := os.WriteFile(path, []byte("[logger]\nlevel = \"warn\"\n"), 0644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	l, err := NewConsoleLogger(&slog.HandlerOptions{Level: LevelInfo})
+	if err != nil {
+		t.Fatalf("NewConsoleLogger: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := ReloadLevelOnSignal(ctx, l, path); err != nil {
+		t.Fatalf("ReloadLevelOnSignal: %v", err)
+	}
+
+	p, _ := os.FindProcess(os.Getpid())
+	if err := p.Signal(syscall.SIGHUP); err != nil {
+		t.Skipf("cannot signal self: %v", err)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for l.Level() != LevelWarning {
+		if time.Now().After(deadline) {
+			t.Fatalf("level = %v after SIGHUP, want WARN", l.Level())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if err := SetLevel(l, LevelDebug); err != nil || l.Level() != LevelDebug {
+		t.Fatalf("SetLevel = %v, level = %v", err, l.Level())
+	}
+}
+
+func TestParse_DefaultsAndValidation(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	conf, err := parse(write("ok.toml", "[logger]\nfilename = \"app.log\"\n"))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if conf.RotateRule != "1hour" || conf.MaxFileNum != 48 || conf.MinLevel != "INFO" || !filepath.IsAbs(conf.Dir) {
+		t.Errorf("defaults not applied: %+v", conf.LoggerConfig)
+	}
+	if conf.Status.Warn != 400 || conf.Status.Error != 500 || conf.Status.Bodies {
+		t.Errorf("status level defaults not applied: %+v", conf.Status)
+	}
+
+	levels, err := LoadStatusLevels(write("status.toml", "[logger.status]\nerror = 503\nbodies = true\n"))
+	if err != nil {
+		t.Fatalf("LoadStatusLevels: %v", err)
+	}
+	if levels != (StatusLevelConfig{Warn: 400, Error: 503, Bodies: true}) {
+		t.Errorf("status levels = %+v", levels)
+	}
+
+	_, err = parse(write("bad.toml", "[logger]\nmaxFileNum = -1\nrotateRule = \"2hour\"\n"))
+	if err == nil {
+		t.Fatal("expected validation error")
+	}
+	for _, want := range []string{"logger.maxFileNum must be >= 0", "logger.rotateRule must be one of"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not contain %q", err, want)
+		}
+	}
+}
+
+func TestWFFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "logger.toml")
+	conf := "[logger]\ndir = \"" + filepath.ToSlash(dir) + "\"\nfilename = \"app.log\"\nlevel = \"debug\"\nrotateRule = \"no\"\n" +
+		"[logger.wf]\nenable = true\nrotateRule = \"1day\"\nmaxFileNum = 7\n"
+	if err := os.WriteFile(path, []byte(conf), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	l, err := NewLogger(path)
+	if err != nil {
+		t.Fatalf("NewLogger: %v", err)
+	}
+	fl := l.(*FileLogger)
+	if fl.wf == nil || fl.wf.logConf.RotateRule != "1day" || fl.wf.logConf.MaxFileNum != 7 {
+		t.Fatalf("wf logger not configured: %+v", fl.wf)
+	}
+
+	ctx := context.Background()
+	l.Info(ctx, "started")
+	l.Warning(ctx, "disk low")
+	l.Error(ctx, "write failed")
+	if err := l.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	main, _ := os.ReadFile(filepath.Join(dir, "app.log"))
+	wf, _ := os.ReadFile(filepath.Join(dir, "app.log.wf"))
+	for _, msg := range []string{"started", "disk low", "write failed"} {
+		if !strings.Contains(string(main), msg) {
+			t.Errorf("app.log missing %q:\n%s", msg, main)
+		}
+	}
+	if strings.Contains(string(wf), "started") || !strings.Contains(string(wf), "disk low") || !strings.Contains(string(wf), "write failed") {
+		t.Errorf("app.log.wf =\n%s", wf)
+	}
+}
+
+func TestNewNamedLoggers(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "logger.toml")
+	conf := "[logger]\ndir = \"" + filepath.ToSlash(dir) + "\"\nfilename = \"app.log\"\nrotateRule = \"no\"\n" +
+		"[loggers.access]\nfilename = \"access.log\"\nformat = \"json\"\nlevel = \"info\"\nrotateRule = \"1day\"\n" +
+		"[loggers.audit]\nfilename = \"audit.log\"\nlevel = \"warn\"\nrotateRule = \"no\"\n"
+	if err := os.WriteFile(path, []byte(conf), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	loggers, err := LoadNamed(path)
+	if err != nil {
+		t.Fatalf("LoadNamed: %v", err)
+	}
+	defer func() {
+		for name := range loggers {
+			Register(name, nil)
+		}
+	}()
+
+	access, audit := Named(AccessLoggerName), Named(AuditLoggerName)
+	if fl, ok := access.(*FileLogger); !ok || fl.logConf.RotateRule != "1day" || fl.filePath != filepath.Join(dir, "access.log") {
+		t.Fatalf("access logger not configured from its section: %+v", access)
+	}
+	ctx := context.Background()
+	access.Info(ctx, "GET /users")
+	audit.Info(ctx, "below audit level")
+	audit.Warning(ctx, "role changed")
+	closeLoggers(loggers)
+
+	accessData, _ := os.ReadFile(filepath.Join(dir, "access.log"))
+	if !strings.Contains(string(accessData), `"msg":"GET /users"`) {
+		t.Errorf("access.log =\n%s", accessData)
+	}
+	auditData, _ := os.ReadFile(filepath.Join(dir, "audit.log"))
+	if strings.Contains(string(auditData), "below audit level") || !strings.Contains(string(auditData), "role changed") {
+		t.Errorf("audit.log =\n%s", auditData)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "app.log")); err == nil {
+		t.Error("NewNamedLoggers opened the [logger] file")
+	}
+
+	// unknown names fall back to the app logger
+	Register(AppLoggerName, audit)
+	defer Register(AppLoggerName, nil)
+	if Named("missing") != audit {
+		t.Error("Named did not fall back to the app logger")
+	}
+}
+
+func TestNewNamedLoggers_Invalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logger.toml")
+	conf := "[logger]\n[loggers.access]\nrotateRule = \"2day\"\n"
+	if err := os.WriteFile(path, []byte(conf), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	_, err := NewNamedLoggers(path)
+	if err == nil || !strings.Contains(err.Error(), "loggers.access") {
+		t.Fatalf("err = %v, want an error naming loggers.access", err)
+	}
+}
@@ -0,0 +1,137 @@
+package logger
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/hansir-hsj/GoLiteKit/config"
+)
+
+// Names of the loggers the framework looks up with Named.
+const (
+	// AppLoggerName is the application logger, the [logger] section.
+	AppLoggerName = "app"
+	// AccessLoggerName receives one line per request from the logger
+	// middleware when configured.
+	AccessLoggerName = "access"
+	// AuditLoggerName is reserved for audit records.
+	AuditLoggerName = "audit"
+)
+
+var registry = struct {
+	mu      sync.RWMutex
+	loggers map[string]Logger
+}{loggers: make(map[string]Logger)}
+
+// Register makes l the logger Named returns for name, replacing any logger
+// registered before; nil removes it. The caller keeps ownership: Register
+// never closes a logger.
+func Register(name string, l Logger) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	if l == nil {
+		delete(registry.loggers, name)
+		return
+	}
+	registry.loggers[name] = l
+}
+
+// Lookup returns the logger registered for name.
+func Lookup(name string) (Logger, bool) {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+	l, ok := registry.loggers[name]
+	return l, ok
+}
+
+// Named returns the logger registered for name. Without one it returns the
+// app logger, and without that a console logger, so callers can log to a
+// name that is not configured.
+func Named(name string) Logger {
+	if l, ok := Lookup(name); ok {
+		return l
+	}
+	if l, ok := Lookup(AppLoggerName); ok {
+		return l
+	}
+	fallbackOnce.Do(func() {
+		fallbackLogger, _ = NewLogger()
+	})
+	return fallbackLogger
+}
+
+// NewNamedLoggers builds a logger for each [loggers.<name>] section of the
+// config file, e.g.
+//
+//	[loggers.access]
+//	filename = "access.log"
+//	format = "json"
+//	rotateRule = "1day"
+//
+// Each section takes the keys of [logger] with their defaults, except that
+// dir defaults to the [logger] dir. A section without filename logs to the
+// console. On error the loggers built so far are closed.
+func NewNamedLoggers(configPath string) (map[string]Logger, error) {
+	conf, err := parse(configPath)
+	if err != nil {
+		return nil, err
+	}
+	ext := filepath.Ext(configPath)
+
+	names := make([]string, 0, len(conf.Loggers))
+	for name := range conf.Loggers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	loggers := make(map[string]Logger, len(names))
+	for _, name := range names {
+		l, err := newNamedLogger(ext, conf, conf.Loggers[name])
+		if err != nil {
+			closeLoggers(loggers)
+			return nil, fmt.Errorf("loggers.%s: %w", name, err)
+		}
+		loggers[name] = l
+	}
+	return loggers, nil
+}
+
+func newNamedLogger(ext string, parent *Config, section LoggerConfig) (Logger, error) {
+	if section.Dir == "" {
+		section.Dir = parent.Dir
+	}
+	if err := config.ApplyDefaults(&section); err != nil {
+		return nil, err
+	}
+	if err := config.Validate(ext, &section); err != nil {
+		return nil, err
+	}
+	absDir, err := filepath.Abs(section.Dir)
+	if err != nil {
+		return nil, err
+	}
+	section.Dir = absDir
+
+	return newLoggerFromConfig(&Config{LoggerConfig: section}, defaultHandlerOptions())
+}
+
+// LoadNamed builds the loggers of NewNamedLoggers and registers each under
+// its name. It returns them so the caller can close them on shutdown.
+func LoadNamed(configPath string) (map[string]Logger, error) {
+	loggers, err := NewNamedLoggers(configPath)
+	if err != nil {
+		return nil, err
+	}
+	for name, l := range loggers {
+		Register(name, l)
+	}
+	return loggers, nil
+}
+
+func closeLoggers(loggers map[string]Logger) {
+	for _, l := range loggers {
+		l.Close()
+	}
+}
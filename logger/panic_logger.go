@@ -15,9 +15,12 @@ import (
 type PanicLogger struct {
 	logConf    *Config
 	filePath   string
-	file       *os.File
+	writer     *reopenWriter
 	lastRotate time.Time
 	mu         sync.Mutex
+
+	stopCleanup chan struct{}
+	closeOnce   sync.Once
 }
 
 func NewPanicLogger(loggerConfig ...string) (*PanicLogger, error) {
@@ -53,17 +56,20 @@ func NewPanicLogger(loggerConfig ...string) (*PanicLogger, error) {
 		fmt.Fprintf(os.Stderr, "warning: failed to rotate existing panic log file: %v\n", err)
 	}
 
-	target, err := os.OpenFile(filePath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
+	writer, err := openReopenWriter(filePath, logConf.RotateMode)
 	if err != nil {
 		return nil, err
 	}
 
-	return &PanicLogger{
-		logConf:    logConf,
-		filePath:   filePath,
-		file:       target,
-		lastRotate: time.Now(),
-	}, nil
+	l := &PanicLogger{
+		logConf:     logConf,
+		filePath:    filePath,
+		writer:      writer,
+		lastRotate:  time.Now(),
+		stopCleanup: make(chan struct{}),
+	}
+	startCleanup(logConf.MaxAge, l.stopCleanup, l.cleanOldFiles)
+	return l, nil
 }
 
 func (l *PanicLogger) caller() string {
@@ -102,43 +108,25 @@ func (l *PanicLogger) needRotate() bool {
 	return false
 }
 
-// rotate opens a new file first, then renames the old one, then swaps handles.
+// rotate moves the current file aside and continues in a new one.
 func (l *PanicLogger) rotate() error {
-	newFilePath := l.newFilePath(l.lastRotate)
-
-	// Step 1: Open new file first
-	newTarget, err := os.OpenFile(l.filePath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
-	if err != nil {
-		return fmt.Errorf("rotate: open new file failed: %w", err)
-	}
-
-	// Step 2: Close and rename old file
-	oldTarget := l.file
-	if err := oldTarget.Close(); err != nil {
-		newTarget.Close()
-		return fmt.Errorf("rotate: close failed: %w", err)
-	}
-	if err := os.Rename(l.filePath, newFilePath); err != nil {
-		newTarget.Close()
-		l.file, _ = os.OpenFile(l.filePath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0666)
-		return fmt.Errorf("rotate: rename failed: %w", err)
+	if err := countRotation(l.writer.rotate(l.newFilePath(l.lastRotate))); err != nil {
+		return err
 	}
-
-	// Step 3: Swap handle
-	l.file = newTarget
 	l.lastRotate = time.Now()
 
-	go l.cleanOldFiles()
+	scheduleCleanup(l.cleanOldFiles)
 
 	return nil
 }
 
 func (l *PanicLogger) cleanOldFiles() {
-	if l.logConf == nil || l.logConf.MaxFileNum <= 0 {
+	if l.logConf == nil {
 		return
 	}
 	dir := filepath.Dir(l.filePath)
 	cleanOldLogFiles(dir, l.filePath, l.logConf.MaxFileNum)
+	removeExpiredLogFiles(dir, l.filePath, l.logConf.MaxAge)
 }
 
 func (l *PanicLogger) newFilePath(t time.Time) string {
@@ -166,8 +154,9 @@ func (l *PanicLogger) newFilePath(t time.Time) string {
 	return l.filePath
 }
 
-// Report writes a panic record. Holds a single lock for both rotate check and write
-// to avoid a race window between rotateIfNeeded() and the write.
+// Report writes a panic record, tagged with the logid field of ctx when it
+// has one. Holds a single lock for both rotate check and write to avoid a
+// race window between rotateIfNeeded() and the write.
 func (l *PanicLogger) Report(ctx context.Context, p any) {
 	l.mu.Lock()
 	defer l.mu.Unlock()
@@ -179,20 +168,36 @@ func (l *PanicLogger) Report(ctx context.Context, p any) {
 	}
 
 	msg := fmt.Sprintf("[%s] Recover from panic: %v", time.Now().Format("2006-01-02 15:04:05.000"), p)
+	if logID := contextLogID(ctx); logID != "" {
+		msg += " logid=" + logID
+	}
 	stack := make([]byte, 4096)
 	length := runtime.Stack(stack, false)
 	stack = stack[:length]
 
-	if _, err := fmt.Fprintf(l.file, "%s\n%s\nStack:\n%s\n\n", msg, l.caller(), stack); err != nil {
+	if _, err := fmt.Fprintf(l.writer, "%s\n%s\nStack:\n%s\n\n", msg, l.caller(), stack); err != nil {
+		stats.dropped.Add(1)
 		fmt.Fprintf(os.Stderr, "Failed to write panic log: %v\n", err)
 	}
 }
 
-func (l *PanicLogger) Close() error {
-	l.mu.Lock()
-	defer l.mu.Unlock()
-	if l.file != nil {
-		return l.file.Close()
+// contextLogID returns the logid field added to ctx, or "".
+func contextLogID(ctx context.Context) string {
+	lcx := GetLoggerContext(ctx)
+	if lcx == nil {
+		return ""
 	}
-	return nil
+	lcx.mu.RLock()
+	defer lcx.mu.RUnlock()
+	for f := lcx.Head; f != nil; f = f.Next {
+		if f.Key == "logid" {
+			return fmt.Sprint(f.Value)
+		}
+	}
+	return ""
+}
+
+func (l *PanicLogger) Close() error {
+	l.closeOnce.Do(func() { close(l.stopCleanup) })
+	return l.writer.Close()
 }
@@ -0,0 +1,191 @@
+package logger
+
+import (
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+	"sync/atomic"
+)
+
+// DefaultRedactMask replaces masked values when RedactConfig.Mask is empty.
+const DefaultRedactMask = "[REDACTED]"
+
+// Redactor masks sensitive data in a record before it is written. It sees
+// the message and every attribute, those added by With and by the request
+// context included, but not the time and level.
+type Redactor interface {
+	// RedactMessage returns msg with sensitive text masked.
+	RedactMessage(msg string) string
+	// RedactAttr returns attr with its value masked when sensitive. groups
+	// are the names of the groups enclosing attr.
+	RedactAttr(groups []string, attr slog.Attr) slog.Attr
+}
+
+// RedactorController is implemented by loggers whose Redactor can be
+// replaced while the process is running.
+type RedactorController interface {
+	SetRedactor(r Redactor)
+}
+
+var (
+	_ RedactorController = (*ConsoleLogger)(nil)
+	_ RedactorController = (*FileLogger)(nil)
+)
+
+// RedactConfig is the [logger.redact] section of logger.toml.
+type RedactConfig struct {
+	// Keys are attribute keys, matched case-insensitively, whose values are
+	// masked. "key=value" and "key: value" in the message and in string
+	// values are masked too.
+	Keys []string `toml:"keys"`
+	// Patterns are regular expressions whose matches are masked in the
+	// message and in string values.
+	Patterns []string `toml:"patterns"`
+	// Mask replaces masked text, DefaultRedactMask by default.
+	Mask string `toml:"mask"`
+}
+
+// KeyRedactor is the Redactor configured by RedactConfig.
+type KeyRedactor struct {
+	keys       map[string]struct{}
+	assignment *regexp.Regexp
+	patterns   []*regexp.Regexp
+	mask       string
+}
+
+var _ Redactor = (*KeyRedactor)(nil)
+
+// NewKeyRedactor compiles conf. It returns nil, nil when conf has neither
+// keys nor patterns.
+func NewKeyRedactor(conf RedactConfig) (*KeyRedactor, error) {
+	if len(conf.Keys) == 0 && len(conf.Patterns) == 0 {
+		return nil, nil
+	}
+	r := &KeyRedactor{
+		keys: make(map[string]struct{}, len(conf.Keys)),
+		mask: conf.Mask,
+	}
+	if r.mask == "" {
+		r.mask = DefaultRedactMask
+	}
+
+	quoted := make([]string, 0, len(conf.Keys))
+	for _, key := range conf.Keys {
+		key = strings.ToLower(strings.TrimSpace(key))
+		if key == "" {
+			continue
+		}
+		r.keys[key] = struct{}{}
+		quoted = append(quoted, regexp.QuoteMeta(key))
+	}
+	if len(quoted) > 0 {
+		r.assignment = regexp.MustCompile(`(?i)\b(` + strings.Join(quoted, "|") + `)\b(\s*[:=]\s*)("[^"]*"|'[^']*'|[^\s,;&]+)`)
+	}
+
+	for _, pattern := range conf.Patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redact pattern %q: %w", pattern, err)
+		}
+		r.patterns = append(r.patterns, re)
+	}
+	return r, nil
+}
+
+// RedactMessage masks the values assigned to the configured keys and the
+// matches of the configured patterns.
+func (r *KeyRedactor) RedactMessage(msg string) string {
+	if r.assignment != nil {
+		msg = r.assignment.ReplaceAllString(msg, "${1}${2}"+r.mask)
+	}
+	for _, re := range r.patterns {
+		msg = re.ReplaceAllLiteralString(msg, r.mask)
+	}
+	return msg
+}
+
+// RedactAttr masks the whole value of a configured key, and otherwise
+// applies RedactMessage to string and error values.
+func (r *KeyRedactor) RedactAttr(groups []string, attr slog.Attr) slog.Attr {
+	if _, ok := r.keys[strings.ToLower(attr.Key)]; ok {
+		return slog.String(attr.Key, r.mask)
+	}
+	switch attr.Value.Kind() {
+	case slog.KindString:
+		attr.Value = slog.StringValue(r.RedactMessage(attr.Value.String()))
+	case slog.KindAny:
+		if err, ok := attr.Value.Any().(error); ok {
+			attr.Value = slog.StringValue(r.RedactMessage(err.Error()))
+		}
+	}
+	return attr
+}
+
+// SetRedactor replaces the Redactor of l; nil turns redaction off. It fails
+// when l does not implement RedactorController.
+func SetRedactor(l Logger, r Redactor) error {
+	rc, ok := l.(RedactorController)
+	if !ok {
+		return fmt.Errorf("logger %T does not support redaction", l)
+	}
+	rc.SetRedactor(r)
+	return nil
+}
+
+// redactorVar holds the Redactor a logger's handlers consult, so
+// SetRedactor applies to handlers created before the call.
+type redactorVar struct {
+	v atomic.Pointer[redactorBox]
+}
+
+type redactorBox struct {
+	r Redactor
+}
+
+func (v *redactorVar) Load() Redactor {
+	if box := v.v.Load(); box != nil {
+		return box.r
+	}
+	return nil
+}
+
+func (v *redactorVar) Store(r Redactor) {
+	v.v.Store(&redactorBox{r: r})
+}
+
+// withRedactorVar returns a copy of opts whose ReplaceAttr passes each
+// attribute through the Redactor in the returned redactorVar before the
+// ReplaceAttr of opts, so the message is masked before schemaOptions
+// renames its key.
+func withRedactorVar(opts *slog.HandlerOptions) (*slog.HandlerOptions, *redactorVar) {
+	rv := new(redactorVar)
+	copied := slog.HandlerOptions{}
+	if opts != nil {
+		copied = *opts
+	}
+	base := copied.ReplaceAttr
+	copied.ReplaceAttr = func(groups []string, attr slog.Attr) slog.Attr {
+		if r := rv.Load(); r != nil {
+			attr = redactAttr(r, groups, attr)
+		}
+		if base != nil {
+			return base(groups, attr)
+		}
+		return attr
+	}
+	return &copied, rv
+}
+
+func redactAttr(r Redactor, groups []string, attr slog.Attr) slog.Attr {
+	if len(groups) == 0 {
+		switch attr.Key {
+		case slog.TimeKey, slog.LevelKey, slog.SourceKey:
+			return attr
+		case slog.MessageKey:
+			attr.Value = slog.StringValue(r.RedactMessage(attr.Value.String()))
+			return attr
+		}
+	}
+	return r.RedactAttr(groups, attr)
+}
@@ -0,0 +1,101 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestKeyRedactor(t *testing.T) {
+	r, err := NewKeyRedactor(RedactConfig{
+		Keys:     []string{"password", "Token"},
+		Patterns: []string{`\b\d{16}\b`},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := r.RedactMessage(`login token=abc123 password: "p w" card 4111111111111111 user=jan`)
+	want := `login token=[REDACTED] password: [REDACTED] card [REDACTED] user=jan`
+	if got != want {
+		t.Errorf("RedactMessage = %q, want %q", got, want)
+	}
+
+	if a := r.RedactAttr(nil, slog.Int("TOKEN", 7)); a.Value.String() != DefaultRedactMask {
+		t.Errorf("key not masked: %v", a)
+	}
+	if a := r.RedactAttr(nil, slog.Any("err", errors.New("bad password=x"))); a.Value.String() != "bad password=[REDACTED]" {
+		t.Errorf("error not masked: %v", a)
+	}
+	if a := r.RedactAttr(nil, slog.Int("count", 3)); a.Value.Int64() != 3 {
+		t.Errorf("non-sensitive attr changed: %v", a)
+	}
+}
+
+func TestNewKeyRedactor(t *testing.T) {
+	if r, err := NewKeyRedactor(RedactConfig{}); r != nil || err != nil {
+		t.Errorf("empty config = %v, %v; want nil, nil", r, err)
+	}
+	if _, err := NewKeyRedactor(RedactConfig{Patterns: []string{"("}}); err == nil {
+		t.Error("invalid pattern accepted")
+	}
+}
+
+func TestRedactConfig(t *testing.T) {
+	var rec map[string]any
+	line := logOneLine(t, "format = \"ecs\"\n[logger.redact]\nkeys = [\"free\", \"logid\"]\npatterns = ['disk \\w+']\nmask = \"***\"\n")
+	if err := json.Unmarshal([]byte(line), &rec); err != nil {
+		t.Fatalf("%s: %v", line, err)
+	}
+	if rec["message"] != "***" || rec["free"] != "***" || rec["logid"] != "***" {
+		t.Errorf("record = %s", line)
+	}
+	if rec["log.level"] != "warn" {
+		t.Errorf("level changed: %s", line)
+	}
+}
+
+type upperRedactor struct{}
+
+func (upperRedactor) RedactMessage(msg string) string { return strings.ToUpper(msg) }
+
+func (upperRedactor) RedactAttr(groups []string, attr slog.Attr) slog.Attr {
+	if strings.Join(groups, ".") == "user" && attr.Key == "name" {
+		return slog.String(attr.Key, "?")
+	}
+	return attr
+}
+
+func TestSetRedactor(t *testing.T) {
+	var buf bytes.Buffer
+	opts, rv := withRedactorVar(&slog.HandlerOptions{})
+	l := &ConsoleLogger{
+		logger:   slog.New(newContextHandler(&buf, LoggerTextFormat, opts)),
+		level:    new(slog.LevelVar),
+		redactor: rv,
+	}
+	child := l.With("user", slog.GroupValue(slog.String("name", "jan")))
+
+	if err := SetRedactor(l, upperRedactor{}); err != nil {
+		t.Fatal(err)
+	}
+	child.Info(context.Background(), "hello")
+	if out := buf.String(); !strings.Contains(out, "msg=HELLO user.name=?") {
+		t.Errorf("output = %s", out)
+	}
+
+	buf.Reset()
+	l.SetRedactor(nil)
+	child.Info(context.Background(), "hello")
+	if out := buf.String(); !strings.Contains(out, "msg=hello user.name=jan") {
+		t.Errorf("output after SetRedactor(nil) = %s", out)
+	}
+
+	if err := SetRedactor(FromContext(context.Background()), upperRedactor{}); err == nil {
+		t.Error("SetRedactor accepted a logger without RedactorController")
+	}
+}
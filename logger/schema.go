@@ -0,0 +1,87 @@
+package logger
+
+import (
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// ECSVersion is the Elastic Common Schema version the "ecs" format follows.
+const ECSVersion = "8.11.0"
+
+// schemaOptions returns a copy of opts whose ReplaceAttr writes the
+// top-level time, level and message keys of format and then applies rename.
+// Keys inside groups are left alone.
+func schemaOptions(format string, rename map[string]string, opts *slog.HandlerOptions) *slog.HandlerOptions {
+	var schema func(slog.Attr) (slog.Attr, bool)
+	switch strings.ToLower(format) {
+	case LoggerECSFormat:
+		schema = ecsAttr
+	case LoggerLogfmtFormat:
+		schema = logfmtAttr
+	}
+	if schema == nil && len(rename) == 0 {
+		return opts
+	}
+
+	copied := *opts
+	base := opts.ReplaceAttr
+	copied.ReplaceAttr = func(groups []string, attr slog.Attr) slog.Attr {
+		replaced := false
+		if schema != nil && len(groups) == 0 {
+			attr, replaced = schema(attr)
+		}
+		if !replaced && base != nil {
+			attr = base(groups, attr)
+		}
+		if len(groups) == 0 {
+			if key, ok := rename[attr.Key]; ok && key != "" {
+				attr.Key = key
+			}
+		}
+		return attr
+	}
+	return &copied
+}
+
+// ecsAttr maps the built-in attributes to their ECS fields.
+func ecsAttr(attr slog.Attr) (slog.Attr, bool) {
+	switch attr.Key {
+	case slog.TimeKey:
+		if t, ok := attr.Value.Any().(time.Time); ok {
+			return slog.String("@timestamp", t.UTC().Format("2006-01-02T15:04:05.000Z")), true
+		}
+	case slog.LevelKey:
+		return slog.String("log.level", levelLabel(attr)), true
+	case slog.MessageKey:
+		return slog.Attr{Key: "message", Value: attr.Value}, true
+	}
+	return attr, false
+}
+
+// logfmtAttr writes "ts" in RFC 3339 and lowercase levels, as most logfmt
+// consumers expect.
+func logfmtAttr(attr slog.Attr) (slog.Attr, bool) {
+	switch attr.Key {
+	case slog.TimeKey:
+		if t, ok := attr.Value.Any().(time.Time); ok {
+			return slog.String("ts", t.Format("2006-01-02T15:04:05.000Z07:00")), true
+		}
+	case slog.LevelKey:
+		return slog.String(slog.LevelKey, levelLabel(attr)), true
+	}
+	return attr, false
+}
+
+// levelLabel returns the lowercase name of a level attribute, TRACE and
+// FATAL included.
+func levelLabel(attr slog.Attr) string {
+	level, ok := attr.Value.Any().(slog.Level)
+	if !ok {
+		return strings.ToLower(attr.Value.String())
+	}
+	if name, ok := LevelNames[level]; ok {
+		return strings.ToLower(name)
+	}
+	return strings.ToLower(level.String())
+}
@@ -0,0 +1,74 @@
+package logger
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// logOneLine logs one record through a file logger configured by conf and
+// returns the written line.
+func logOneLine(t *testing.T, conf string) string {
+	t.Helper()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "logger.toml")
+	conf = "[logger]\ndir = " + `"` + filepath.ToSlash(dir) + `"` + "\nfilename = \"app.log\"\nrotateRule = \"no\"\n" + conf
+	if err := os.WriteFile(path, []byte(conf), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	log, err := NewLogger(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := WithLoggerContext(context.Background())
+	AddWarning(ctx, "logid", "abc123")
+	log.Warning(ctx, "disk low", "free", 3)
+	log.Close()
+
+	data, err := os.ReadFile(filepath.Join(dir, "app.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.TrimSpace(string(data))
+}
+
+func TestECSFormat(t *testing.T) {
+	var rec map[string]any
+	line := logOneLine(t, `format = "ecs"`)
+	if err := json.Unmarshal([]byte(line), &rec); err != nil {
+		t.Fatalf("%s: %v", line, err)
+	}
+	ts, _ := rec["@timestamp"].(string)
+	if rec["log.level"] != "warn" || rec["message"] != "disk low" || rec["ecs.version"] != ECSVersion || !strings.HasSuffix(ts, "Z") {
+		t.Errorf("record = %s", line)
+	}
+	if rec["free"] != float64(3) || rec["logid"] != "abc123" {
+		t.Errorf("attributes missing: %s", line)
+	}
+	for _, key := range []string{"time", "level", "msg"} {
+		if _, ok := rec[key]; ok {
+			t.Errorf("slog key %q written: %s", key, line)
+		}
+	}
+}
+
+func TestLogfmtFormat(t *testing.T) {
+	line := logOneLine(t, `format = "logfmt"`)
+	if !strings.HasPrefix(line, "ts=") || !strings.Contains(line, " level=warn msg=\"disk low\" free=3") {
+		t.Errorf("line = %s", line)
+	}
+}
+
+func TestRenameKeys(t *testing.T) {
+	var rec map[string]any
+	line := logOneLine(t, "format = \"json\"\n[logger.rename]\ntime = \"timestamp\"\nlevel = \"severity\"\nmsg = \"message\"\n")
+	if err := json.Unmarshal([]byte(line), &rec); err != nil {
+		t.Fatalf("%s: %v", line, err)
+	}
+	if rec["severity"] != "WARN" || rec["message"] != "disk low" || rec["timestamp"] == nil || rec["time"] != nil {
+		t.Errorf("record = %s", line)
+	}
+}
@@ -0,0 +1,42 @@
+package logger
+
+import "sync/atomic"
+
+// Stats counts events of the logger subsystem since the process started,
+// across all loggers, so silent log loss shows up in metrics.
+type Stats struct {
+	// Dropped is the number of records and panic reports that could not
+	// be written.
+	Dropped int64 `json:"dropped"`
+	// Rotations is the number of rotated log files.
+	Rotations int64 `json:"rotations"`
+	// RotationFailures is the number of rotations that failed; the logger
+	// keeps writing to the current file.
+	RotationFailures int64 `json:"rotation_failures"`
+	// CleanupDeletions is the number of rotated files deleted by cleanup.
+	CleanupDeletions int64 `json:"cleanup_deletions"`
+}
+
+var stats struct {
+	dropped, rotations, rotationFailures, cleanupDeletions atomic.Int64
+}
+
+// ReadStats returns the current counters.
+func ReadStats() Stats {
+	return Stats{
+		Dropped:          stats.dropped.Load(),
+		Rotations:        stats.rotations.Load(),
+		RotationFailures: stats.rotationFailures.Load(),
+		CleanupDeletions: stats.cleanupDeletions.Load(),
+	}
+}
+
+// countRotation records the outcome of a rotation and returns err.
+func countRotation(err error) error {
+	if err != nil {
+		stats.rotationFailures.Add(1)
+	} else {
+		stats.rotations.Add(1)
+	}
+	return err
+}
@@ -0,0 +1,42 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type failingHandler struct{ slog.Handler }
+
+func (failingHandler) Handle(context.Context, slog.Record) error { return errors.New("disk full") }
+
+func TestReadStatsCountsLoggerEvents(t *testing.T) {
+	before := ReadStats()
+
+	logRecord(context.Background(), failingHandler{}, LevelInfo, "lost", false, 0)
+	countRotation(nil)
+	countRotation(errors.New("rename failed"))
+	path := filepath.Join(t.TempDir(), "app.log.2026101700")
+	if err := os.WriteFile(path, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	removeLogFile(path)
+	removeLogFile(path) // already gone: not counted
+
+	after := ReadStats()
+	if got := after.Dropped - before.Dropped; got != 1 {
+		t.Errorf("dropped += %d, want 1", got)
+	}
+	if got := after.Rotations - before.Rotations; got != 1 {
+		t.Errorf("rotations += %d, want 1", got)
+	}
+	if got := after.RotationFailures - before.RotationFailures; got != 1 {
+		t.Errorf("rotation failures += %d, want 1", got)
+	}
+	if got := after.CleanupDeletions - before.CleanupDeletions; got != 1 {
+		t.Errorf("cleanup deletions += %d, want 1", got)
+	}
+}
@@ -1,6 +1,33 @@
 package logger
 
-import "time"
+import (
+	"sync/atomic"
+	"time"
+)
+
+// logTimeLayout is the time format of records written by NewLogger.
+const logTimeLayout = "2006-01-02 15:04:05.000"
+
+// logTimeCache holds the last timestamp formatted by formatLogTime, so the
+// records logged within the same millisecond share one string.
+var logTimeCache atomic.Pointer[cachedLogTime]
+
+type cachedLogTime struct {
+	ms  int64
+	loc *time.Location
+	s   string
+}
+
+// formatLogTime formats t in logTimeLayout.
+func formatLogTime(t time.Time) string {
+	ms := t.UnixMilli()
+	if c := logTimeCache.Load(); c != nil && c.ms == ms && c.loc == t.Location() {
+		return c.s
+	}
+	s := t.Format(logTimeLayout)
+	logTimeCache.Store(&cachedLogTime{ms: ms, loc: t.Location(), s: s})
+	return s
+}
 
 // Truncate to the minute boundary
 func truncateToMinute(t time.Time) time.Time {
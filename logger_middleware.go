@@ -2,7 +2,10 @@ package golitekit
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/hansir-hsj/GoLiteKit/logger"
 )
@@ -15,6 +18,19 @@ type LoggerOptions struct {
 	LogRequestBody  bool
 	LogResponseBody bool
 	MaxBodyBytes    int64
+	// AccessLogger receives the line written when a request completes, so
+	// access lines stay out of the application log. It defaults to the
+	// middleware's logger; NewApp and NewAppFromConfig use the logger named
+	// logger.AccessLoggerName when one is installed.
+	AccessLogger logger.Logger
+	// WarnStatus and ErrorStatus are the response statuses from which the
+	// access line is logged at WARN and at ERROR instead of INFO, 400 and
+	// 500 by default; NewAppFromConfig reads them from [logger.status].
+	WarnStatus  int
+	ErrorStatus int
+	// LogErrorBodies adds the request body to WARN and ERROR lines even
+	// without LogRequestBody.
+	LogErrorBodies bool
 }
 
 // LoggerAsMiddleware logs each request and its outcome using logInst.
@@ -27,6 +43,16 @@ func LoggerAsMiddleware(logInst logger.Logger, panicInst *logger.PanicLogger, op
 	if opt.MaxBodyBytes <= 0 {
 		opt.MaxBodyBytes = DefaultLogBodyLimit
 	}
+	if opt.WarnStatus <= 0 {
+		opt.WarnStatus = http.StatusBadRequest
+	}
+	if opt.ErrorStatus <= 0 {
+		opt.ErrorStatus = http.StatusInternalServerError
+	}
+	accessInst := opt.AccessLogger
+	if accessInst == nil {
+		accessInst = logInst
+	}
 
 	return func(next Handler) Handler {
 		return func(ctx context.Context, w http.ResponseWriter, r *http.Request) (rerr error) {
@@ -36,7 +62,16 @@ func LoggerAsMiddleware(logInst logger.Logger, panicInst *logger.PanicLogger, op
 
 			rw := newResponseCapture(w, logRespBody, opt.MaxBodyBytes)
 			defer func() {
-				if logReqBody && r.Method != http.MethodGet && r.Method != http.MethodDelete {
+				// An error is rendered by ErrorHandlerMiddleware after this
+				// returns, so its status is not on rw yet.
+				status := rw.statusCode
+				appErr := ClassifyError(rerr)
+				if appErr != nil {
+					status = appErr.Code
+				}
+				elevated := status >= opt.WarnStatus || status >= opt.ErrorStatus
+
+				if (logReqBody || elevated && opt.LogErrorBodies) && r.Method != http.MethodGet && r.Method != http.MethodDelete {
 					if gcx != nil && len(gcx.rawBody) > 0 && isLoggableContentType(r.Header.Get("Content-Type")) {
 						logger.AddInfo(ctx, "request", sanitizeLoggedBody(gcx.rawBody, opt.MaxBodyBytes, r.Header.Get("Content-Type")))
 					}
@@ -46,31 +81,39 @@ func LoggerAsMiddleware(logInst logger.Logger, panicInst *logger.PanicLogger, op
 					logger.AddInfo(ctx, "response", sanitizeLoggedBody(rw.body, opt.MaxBodyBytes, rw.Header().Get("Content-Type")))
 				}
 
-				logger.AddInfo(ctx, "status", rw.statusCode)
+				logger.AddInfo(ctx, "status", status)
 
-				if rerr != nil {
-					if appErr, ok := rerr.(*AppError); ok {
-						logger.AddInfo(ctx, "err_code", appErr.Code)
-						logger.AddInfo(ctx, "err_message", appErr.Message)
-						if appErr.Internal != nil {
-							logger.AddInfo(ctx, "err_internal", sanitizeErrorMessage(appErr.Internal.Error(), opt.MaxBodyBytes))
+				if appErr != nil {
+					logger.AddInfo(ctx, "err_code", appErr.Code)
+					logger.AddInfo(ctx, "err_message", appErr.Message)
+					if appErr.Internal != nil {
+						logger.AddInfo(ctx, "err_internal", sanitizeErrorMessage(appErr.Internal.Error(), opt.MaxBodyBytes))
+						logger.AddInfo(ctx, "err_chain", errorChain(appErr.Internal))
+						if stack := errorStack(appErr.Internal); stack != "" && status >= opt.ErrorStatus {
+							logger.AddInfo(ctx, "err_stack", sanitizeErrorMessage(stack, maxLoggedStack))
 						}
 					}
-					if logInst != nil {
-						logInst.Warning(ctx, "request completed with error")
-					}
-				} else {
-					if logInst != nil {
-						logInst.Info(ctx, "succ")
-					}
 				}
-
+				if accessInst == nil {
+					return
+				}
+				switch {
+				case status >= opt.ErrorStatus:
+					accessInst.Error(ctx, "request completed with error")
+				case status >= opt.WarnStatus:
+					accessInst.Warning(ctx, "request completed with error")
+				default:
+					accessInst.Info(ctx, "succ")
+				}
 			}()
 
 			if gcx != nil {
 				gcx.responseWriter = rw
 				gcx.setContextOptions(withLogger(logInst), withPanicLogger(panicInst))
 			}
+			if logInst != nil {
+				logger.SetLogger(ctx, logInst)
+			}
 
 			logger.AddInfo(ctx, "method", r.Method)
 			logger.AddInfo(ctx, "url", sanitizeURL(r.URL))
@@ -80,3 +123,28 @@ func LoggerAsMiddleware(logInst logger.Logger, panicInst *logger.PanicLogger, op
 		}
 	}
 }
+
+// maxLoggedStack caps the err_stack field.
+const maxLoggedStack = 16 << 10
+
+// errorStack returns the stack trace err carries, for errors whose %+v
+// verb prints one after the message as github.com/pkg/errors and similar
+// packages do, and "" otherwise.
+func errorStack(err error) string {
+	msg := err.Error()
+	detail := fmt.Sprintf("%+v", err)
+	if detail == msg {
+		return ""
+	}
+	return strings.TrimSpace(strings.TrimPrefix(detail, msg))
+}
+
+// errorChain lists the types of err and the errors it wraps, outermost
+// first, e.g. [*fmt.wrapError *mysql.MySQLError].
+func errorChain(err error) []string {
+	var chain []string
+	for ; err != nil && len(chain) < 16; err = errors.Unwrap(err) {
+		chain = append(chain, fmt.Sprintf("%T", err))
+	}
+	return chain
+}
@@ -3,9 +3,12 @@ package golitekit
 import (
 	"context"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -103,6 +106,109 @@ func TestLoggerAsMiddleware_SuccessPath(t *testing.T) {
 	}
 }
 
+// levelLogger records the level and message of each line and the request
+// fields.
+type levelLogger struct {
+	captureLogger
+	levels []string
+	msgs   []string
+}
+
+func (l *levelLogger) record(ctx context.Context, level, msg string, args []any) {
+	l.levels = append(l.levels, level)
+	l.msgs = append(l.msgs, fmt.Sprintf(msg, args...))
+	l.captureLogger.Info(ctx, "")
+}
+
+func (l *levelLogger) Info(ctx context.Context, msg string, args ...any) {
+	l.record(ctx, "INFO", msg, args)
+}
+
+func (l *levelLogger) Warning(ctx context.Context, msg string, args ...any) {
+	l.record(ctx, "WARN", msg, args)
+}
+
+func (l *levelLogger) Error(ctx context.Context, msg string, args ...any) {
+	l.record(ctx, "ERROR", msg, args)
+}
+
+func TestLoggerAsMiddleware_LevelByStatus(t *testing.T) {
+	tests := []struct {
+		name    string
+		opt     LoggerOptions
+		handler Handler
+		level   string
+		status  int
+	}{
+		{"2xx", LoggerOptions{}, func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			w.WriteHeader(http.StatusCreated)
+			return nil
+		}, "INFO", http.StatusCreated},
+		{"written 404", LoggerOptions{}, func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			w.WriteHeader(http.StatusNotFound)
+			return nil
+		}, "WARN", http.StatusNotFound},
+		{"AppError 400", LoggerOptions{}, func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			return ErrBadRequest("bad", nil)
+		}, "WARN", http.StatusBadRequest},
+		{"plain error", LoggerOptions{}, func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			return fmt.Errorf("load: %w", os.ErrNotExist)
+		}, "ERROR", http.StatusInternalServerError},
+		{"raised error threshold", LoggerOptions{ErrorStatus: 503}, func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			return ErrInternal("boom", nil)
+		}, "WARN", http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := &levelLogger{captureLogger: captureLogger{fields: map[string]any{}}}
+			tt.opt.AccessLogger = l
+			req := httptest.NewRequest(http.MethodGet, "/x", nil)
+			req = req.WithContext(logger.WithLoggerContext(withContext(req.Context())))
+			LoggerAsMiddleware(nil, nil, tt.opt)(tt.handler).ServeHTTP(httptest.NewRecorder(), req)
+
+			if len(l.levels) != 1 || l.levels[0] != tt.level {
+				t.Errorf("levels = %v, want [%s]", l.levels, tt.level)
+			}
+			if l.fields["status"] != tt.status {
+				t.Errorf("status field = %v, want %d", l.fields["status"], tt.status)
+			}
+		})
+	}
+
+	l := &levelLogger{captureLogger: captureLogger{fields: map[string]any{}}}
+	mw := LoggerAsMiddleware(nil, nil, LoggerOptions{AccessLogger: l})
+	req := httptest.NewRequest(http.MethodGet, "/x", nil)
+	req = req.WithContext(logger.WithLoggerContext(withContext(req.Context())))
+	mw(func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		return fmt.Errorf("load: %w", os.ErrNotExist)
+	}).ServeHTTP(httptest.NewRecorder(), req)
+	chain, _ := l.fields["err_chain"].([]string)
+	if len(chain) != 2 || chain[0] != "*fmt.wrapError" || chain[1] != "*errors.errorString" {
+		t.Errorf("err_chain = %v", l.fields["err_chain"])
+	}
+}
+
+func TestLoggerAsMiddleware_LogErrorBodies(t *testing.T) {
+	for _, status := range []int{http.StatusOK, http.StatusUnprocessableEntity} {
+		l := &levelLogger{captureLogger: captureLogger{fields: map[string]any{}}}
+		mw := LoggerAsMiddleware(nil, nil, LoggerOptions{AccessLogger: l, LogErrorBodies: true})
+		req := httptest.NewRequest(http.MethodPost, "/x", strings.NewReader(`{"name":"x"}`))
+		req.Header.Set("Content-Type", "application/json")
+		ctx := logger.WithLoggerContext(withContext(req.Context()))
+		GetContext(ctx).rawBody = []byte(`{"name":"x"}`)
+		req = req.WithContext(ctx)
+		mw(func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			w.WriteHeader(status)
+			return nil
+		}).ServeHTTP(httptest.NewRecorder(), req)
+
+		_, logged := l.fields["request"]
+		if logged != (status >= 400) {
+			t.Errorf("status %d: request body logged = %v", status, logged)
+		}
+	}
+}
+
 func TestLoggerAsMiddleware_WithRealLogger(t *testing.T) {
 	log, err := logger.NewLogger()
 	if err != nil {
@@ -280,3 +386,53 @@ func TestLoggerIsLoggableContentType(t *testing.T) {
 		}
 	}
 }
+
+func TestLoggerAsMiddleware_FromContext(t *testing.T) {
+	capture := &captureLogger{fields: map[string]any{}}
+	mw := LoggerAsMiddleware(capture, nil)
+
+	inner := Handler(func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		logger.AddInfo(ctx, "logid", "abc123")
+		// Library code logging without the request context still gets its fields.
+		logger.FromContext(ctx).Info(context.Background(), "cache miss")
+		return nil
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/lib", nil)
+	req = req.WithContext(logger.WithLoggerContext(withContext(req.Context())))
+	mw(inner).ServeHTTP(httptest.NewRecorder(), req)
+
+	if capture.fields["logid"] != "abc123" || capture.fields["url"] != "/lib" {
+		t.Errorf("fields = %v, want the request's logid and url", capture.fields)
+	}
+}
+
+func TestLoggerAsMiddleware_AccessLogger(t *testing.T) {
+	dir := t.TempDir()
+	newLog := func(name string) *logger.FileLogger {
+		l, err := logger.NewTextLogger(&logger.Config{LoggerConfig: logger.LoggerConfig{Dir: dir, FileName: name, RotateRule: "no"}}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { l.Close() })
+		return l
+	}
+	appLog, accessLog := newLog("app.log"), newLog("access.log")
+	mw := LoggerAsMiddleware(appLog, nil, LoggerOptions{AccessLogger: accessLog})
+
+	inner := Handler(func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		w.WriteHeader(http.StatusOK)
+		return nil
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/users", nil)
+	req = req.WithContext(withContext(req.Context()))
+	mw(inner).ServeHTTP(httptest.NewRecorder(), req)
+
+	if data, _ := os.ReadFile(filepath.Join(dir, "access.log")); !strings.Contains(string(data), "msg=succ") {
+		t.Errorf("access.log = %q, want the access line", data)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "app.log")); strings.Contains(string(data), "msg=succ") {
+		t.Errorf("app.log = %q, want no access line", data)
+	}
+}
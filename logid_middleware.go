@@ -7,16 +7,100 @@ import (
 	"github.com/hansir-hsj/GoLiteKit/logger"
 )
 
-func LogIDMiddleware() Middleware {
+// LogIDHeader carries the log ID between services. An incoming value is
+// adopted as the request's log ID, and the log ID is echoed in the response.
+const LogIDHeader = "X-Log-Id"
+
+const (
+	// RequestIDHeader is the de-facto request ID header set by proxies and
+	// load balancers.
+	RequestIDHeader = "X-Request-ID"
+	// TraceparentHeader is the W3C trace context header; its trace ID is
+	// adopted as the log ID.
+	TraceparentHeader = "Traceparent"
+)
+
+// LogIDOptions configures where LogIDMiddleware looks for an incoming log ID.
+type LogIDOptions struct {
+	// Header is checked after X-Log-Id and before X-Request-ID and
+	// traceparent, e.g. "X-Correlation-ID".
+	Header string
+}
+
+// LogIDMiddleware seeds the request's log ID from the first valid one of
+// X-Log-Id, LogIDOptions.Header, X-Request-ID and the traceparent trace ID,
+// so a multi-hop request keeps one ID end to end; without one it generates
+// a fresh ID.
+func LogIDMiddleware(opts ...LogIDOptions) Middleware {
+	var opt LogIDOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
 	return func(next Handler) Handler {
 		return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
 			ctx = withContext(ctx)
 			ctx = logger.WithLoggerContext(ctx)
+			if incoming := incomingLogID(r.Header, opt.Header); incoming != "" {
+				SetLogID(ctx, incoming)
+			}
 			logID := EnsureLogID(ctx)
 			if logID != "" {
 				logger.AddInfo(ctx, "logid", logID)
+				w.Header().Set(LogIDHeader, logID)
 			}
 			return next(ctx, w, r.WithContext(ctx))
 		}
 	}
 }
+
+func incomingLogID(h http.Header, custom string) string {
+	for _, name := range [...]string{LogIDHeader, custom, RequestIDHeader} {
+		if name == "" {
+			continue
+		}
+		if id := h.Get(name); validLogID(id) {
+			return id
+		}
+	}
+	return traceIDFromTraceparent(h.Get(TraceparentHeader))
+}
+
+// traceIDFromTraceparent returns the trace ID of a version-00 traceparent
+// ("00-<32 hex trace ID>-<16 hex parent ID>-<2 hex flags>"), or "" when the
+// header is malformed or the trace ID is all zeros.
+func traceIDFromTraceparent(v string) string {
+	if len(v) != 55 || v[:3] != "00-" || v[35] != '-' || v[52] != '-' {
+		return ""
+	}
+	traceID := v[3:35]
+	zero := true
+	for i := 0; i < len(traceID); i++ {
+		c := traceID[i]
+		if !(c >= '0' && c <= '9' || c >= 'a' && c <= 'f') {
+			return ""
+		}
+		if c != '0' {
+			zero = false
+		}
+	}
+	if zero {
+		return ""
+	}
+	return traceID
+}
+
+// validLogID accepts up to 64 characters of [A-Za-z0-9._-] so a client-supplied
+// log ID cannot inject content into logs or headers.
+func validLogID(id string) bool {
+	if id == "" || len(id) > 64 {
+		return false
+	}
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == '.', c == '_', c == '-':
+		default:
+			return false
+		}
+	}
+	return true
+}
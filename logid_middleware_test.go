@@ -41,3 +41,65 @@ func TestLogIDMiddlewareAddsLogIDToLoggerContext(t *testing.T) {
 		t.Fatal("expected next handler to be called")
 	}
 }
+
+func TestLogIDMiddlewareAdoptsIncomingHeader(t *testing.T) {
+	var got string
+	inner := Handler(func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		got = EnsureLogID(ctx)
+		return nil
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/logid", nil)
+	req.Header.Set(LogIDHeader, "upstream-42")
+	rec := httptest.NewRecorder()
+	LogIDMiddleware()(inner).ServeHTTP(rec, req)
+	if got != "upstream-42" || rec.Header().Get(LogIDHeader) != "upstream-42" {
+		t.Fatalf("logid = %q, response header = %q, want upstream-42", got, rec.Header().Get(LogIDHeader))
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/logid", nil)
+	req.Header.Set(LogIDHeader, "bad id\nInjected: 1")
+	rec = httptest.NewRecorder()
+	LogIDMiddleware()(inner).ServeHTTP(rec, req)
+	if got == "" || got == "bad id\nInjected: 1" {
+		t.Fatalf("invalid incoming logid was adopted: %q", got)
+	}
+}
+
+func TestLogIDMiddlewareAdoptsStandardHeaders(t *testing.T) {
+	const trace = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	tests := []struct {
+		name    string
+		headers map[string]string
+		want    string
+	}{
+		{"request id", map[string]string{RequestIDHeader: "req-1"}, "req-1"},
+		{"traceparent", map[string]string{TraceparentHeader: trace}, "4bf92f3577b34da6a3ce929d0e0e4736"},
+		{"custom header", map[string]string{"X-Correlation-ID": "corr-1", RequestIDHeader: "req-1"}, "corr-1"},
+		{"log id wins", map[string]string{LogIDHeader: "log-1", "X-Correlation-ID": "corr-1", TraceparentHeader: trace}, "log-1"},
+		{"invalid request id", map[string]string{RequestIDHeader: "a b", TraceparentHeader: trace}, "4bf92f3577b34da6a3ce929d0e0e4736"},
+		{"zero trace id", map[string]string{TraceparentHeader: "00-00000000000000000000000000000000-00f067aa0ba902b7-01"}, ""},
+		{"malformed traceparent", map[string]string{TraceparentHeader: "00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			inner := Handler(func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+				got = EnsureLogID(ctx)
+				return nil
+			})
+			req := httptest.NewRequest(http.MethodGet, "/logid", nil)
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			LogIDMiddleware(LogIDOptions{Header: "X-Correlation-ID"})(inner).ServeHTTP(httptest.NewRecorder(), req)
+			if tt.want == "" {
+				if len(got) != 16 {
+					t.Fatalf("logid = %q, want a generated one", got)
+				}
+			} else if got != tt.want {
+				t.Fatalf("logid = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
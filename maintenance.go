@@ -0,0 +1,191 @@
+package golitekit
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultMaintenanceRetryAfter = time.Minute
+	DefaultMaintenancePath       = "/_admin/maintenance"
+)
+
+// DefaultMaintenanceAllowPaths are always served during maintenance, so
+// liveness and readiness probes of the MountHealth routes keep passing.
+var DefaultMaintenanceAllowPaths = []string{"/healthz", "/readyz"}
+
+// MaintenanceOptions configures the response served while maintenance mode is on.
+type MaintenanceOptions struct {
+	RetryAfter time.Duration // Retry-After header value, defaults to DefaultMaintenanceRetryAfter
+	AllowPaths []string      // path prefixes still served during maintenance besides DefaultMaintenanceAllowPaths, e.g. "/status"
+	Message    string        // response message, defaults to "Service under maintenance"
+}
+
+// Maintenance is a runtime switch that answers every non-allowlisted request
+// with 503 and Retry-After while enabled. It is safe for concurrent use.
+type Maintenance struct {
+	mu      sync.RWMutex
+	opts    MaintenanceOptions
+	enabled bool
+	since   time.Time
+}
+
+// NewMaintenance creates a disabled maintenance switch.
+func NewMaintenance(opts ...MaintenanceOptions) *Maintenance {
+	var opt MaintenanceOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if opt.RetryAfter <= 0 {
+		opt.RetryAfter = DefaultMaintenanceRetryAfter
+	}
+	if opt.Message == "" {
+		opt.Message = "Service under maintenance"
+	}
+	opt.AllowPaths = append(append([]string(nil), DefaultMaintenanceAllowPaths...), opt.AllowPaths...)
+	return &Maintenance{opts: opt}
+}
+
+// Set turns maintenance mode on or off.
+func (m *Maintenance) Set(enabled bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if enabled && !m.enabled {
+		m.since = time.Now()
+	}
+	if !enabled {
+		m.since = time.Time{}
+	}
+	m.enabled = enabled
+}
+
+// Enabled reports whether maintenance mode is on.
+func (m *Maintenance) Enabled() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.enabled
+}
+
+// Allow adds path prefixes that keep being served during maintenance. A
+// prefix matches itself and the paths below it: "/admin" allows
+// "/admin/users" but not "/administrator".
+func (m *Maintenance) Allow(prefixes ...string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.opts.AllowPaths = append(m.opts.AllowPaths, prefixes...)
+}
+
+// MaintenanceStatus is the JSON body of the admin endpoint.
+type MaintenanceStatus struct {
+	Enabled    bool      `json:"enabled"`
+	Since      time.Time `json:"since,omitempty"`
+	RetryAfter int       `json:"retry_after"`
+}
+
+// Status returns the current state.
+func (m *Maintenance) Status() MaintenanceStatus {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return MaintenanceStatus{
+		Enabled:    m.enabled,
+		Since:      m.since,
+		RetryAfter: retryAfterSeconds(m.opts.RetryAfter),
+	}
+}
+
+// Handler wraps next, short-circuiting non-allowlisted requests with 503
+// while maintenance mode is on.
+func (m *Maintenance) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		m.mu.RLock()
+		blocked := m.enabled && !m.allowedLocked(r.URL.Path)
+		retryAfter := m.opts.RetryAfter
+		msg := m.opts.Message
+		m.mu.RUnlock()
+
+		if !blocked {
+			next.ServeHTTP(w, r)
+			return
+		}
+		w.Header().Set("Retry-After", strconv.Itoa(retryAfterSeconds(retryAfter)))
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_ = json.NewEncoder(w).Encode(Response{Status: http.StatusServiceUnavailable, Msg: msg})
+	})
+}
+
+func (m *Maintenance) allowedLocked(path string) bool {
+	for _, prefix := range m.opts.AllowPaths {
+		if prefix == "" {
+			continue
+		}
+		if path == prefix || strings.HasPrefix(path, strings.TrimSuffix(prefix, "/")+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func retryAfterSeconds(d time.Duration) int {
+	return int((d + time.Second - 1) / time.Second)
+}
+
+// AdminHandler returns an endpoint that reports (GET), enables (PUT/POST with
+// {"enabled": true}) or disables (DELETE, or {"enabled": false}) maintenance
+// mode. Requests must carry "Authorization: Bearer <token>"; an empty token
+// panics so the endpoint is never mounted unauthenticated.
+func (m *Maintenance) AdminHandler(token string) http.Handler {
+	if token == "" {
+		panic("golitekit: maintenance admin endpoint requires a token")
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !validBearerToken(r, token) {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeMaintenanceJSON(w, http.StatusUnauthorized, Response{Status: http.StatusUnauthorized, Msg: "Unauthorized"})
+			return
+		}
+
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPut, http.MethodPost:
+			var body struct {
+				Enabled *bool `json:"enabled"`
+			}
+			if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<10)).Decode(&body); err != nil || body.Enabled == nil {
+				writeMaintenanceJSON(w, http.StatusBadRequest, Response{Status: http.StatusBadRequest, Msg: `body must be {"enabled": true|false}`})
+				return
+			}
+			m.Set(*body.Enabled)
+		case http.MethodDelete:
+			m.Set(false)
+		default:
+			w.Header().Set("Allow", "GET, PUT, POST, DELETE")
+			writeMaintenanceJSON(w, http.StatusMethodNotAllowed, Response{Status: http.StatusMethodNotAllowed, Msg: "Method Not Allowed"})
+			return
+		}
+		writeMaintenanceJSON(w, http.StatusOK, m.Status())
+	})
+}
+
+func validBearerToken(r *http.Request, token string) bool {
+	got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return ok && subtle.ConstantTimeCompare([]byte(got), []byte(token)) == 1
+}
+
+func writeMaintenanceJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+// MaintenanceAdminOptions configures App.MountMaintenance.
+type MaintenanceAdminOptions struct {
+	Token string // required bearer token
+	Path  string // defaults to DefaultMaintenancePath
+}
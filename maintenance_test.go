@@ -0,0 +1,156 @@
+package golitekit
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMaintenance_BlocksNonAllowlistedPaths(t *testing.T) {
+	m := NewMaintenance(MaintenanceOptions{RetryAfter: 90 * time.Second, AllowPaths: []string{"/healthz"}})
+	h := m.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	}))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("disabled status = %d, want 200", rec.Code)
+	}
+
+	m.Set(true)
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("enabled status = %d, want 503", rec.Code)
+	}
+	if got := rec.Header().Get("Retry-After"); got != "90" {
+		t.Fatalf("Retry-After = %q, want 90", got)
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("allowlisted status = %d, want 200", rec.Code)
+	}
+}
+
+func TestApp_MountMaintenanceTogglesAtRuntime(t *testing.T) {
+	app := NewApp()
+	app.GET("/hello", HandlerFunc(func(ctx *Context) error {
+		return ctx.String(http.StatusOK, "hello")
+	}))
+	app.MountMaintenance(MaintenanceAdminOptions{Token: "secret"})
+
+	if err := app.Start(ServerConfig{Addr: "127.0.0.1:0"}); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer app.Shutdown(context.Background())
+	base := "http://" + waitForAppServer(t, app).Addr()
+
+	do := func(method, path, token, body string) *http.Response {
+		t.Helper()
+		req, _ := http.NewRequest(method, base+path, strings.NewReader(body))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("%s %s: %v", method, path, err)
+		}
+		_, _ = io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		return resp
+	}
+
+	if resp := do(http.MethodPut, DefaultMaintenancePath, "wrong", `{"enabled":true}`); resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("bad token status = %d, want 401", resp.StatusCode)
+	}
+	if resp := do(http.MethodPut, DefaultMaintenancePath, "secret", `{"enabled":true}`); resp.StatusCode != http.StatusOK {
+		t.Fatalf("enable status = %d, want 200", resp.StatusCode)
+	}
+	resp := do(http.MethodGet, "/hello", "", "")
+	if resp.StatusCode != http.StatusServiceUnavailable || resp.Header.Get("Retry-After") == "" {
+		t.Fatalf("maintenance status = %d Retry-After=%q, want 503 with header", resp.StatusCode, resp.Header.Get("Retry-After"))
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, base+DefaultMaintenancePath, nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	statusResp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET status: %v", err)
+	}
+	var status MaintenanceStatus
+	_ = json.NewDecoder(statusResp.Body).Decode(&status)
+	statusResp.Body.Close()
+	if !status.Enabled {
+		t.Fatalf("status = %+v, want enabled", status)
+	}
+
+	if resp := do(http.MethodDelete, DefaultMaintenancePath, "secret", ""); resp.StatusCode != http.StatusOK {
+		t.Fatalf("disable status = %d, want 200", resp.StatusCode)
+	}
+	if resp := do(http.MethodGet, "/hello", "", ""); resp.StatusCode != http.StatusOK {
+		t.Fatalf("after disable status = %d, want 200", resp.StatusCode)
+	}
+}
+
+func TestServer_SetMaintenance(t *testing.T) {
+	srv := NewServer(ServerConfig{Addr: "127.0.0.1:0"})
+	if err := srv.Start(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer srv.Shutdown(context.Background())
+
+	srv.SetMaintenance(true)
+	resp, err := http.Get("http://" + srv.Addr() + "/")
+	if err != nil {
+		t.Fatalf("GET: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("status = %d, want 503", resp.StatusCode)
+	}
+}
+
+func TestMaintenance_AllowPathsMatchWholeSegments(t *testing.T) {
+	m := NewMaintenance(MaintenanceOptions{AllowPaths: []string{"/admin", "/status/"}})
+	m.Set(true)
+	h := m.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	for path, want := range map[string]int{
+		"/admin":                http.StatusOK,
+		"/admin/users":          http.StatusOK,
+		"/administrator-export": http.StatusServiceUnavailable,
+		"/status/db":            http.StatusOK,
+		"/statuses":             http.StatusServiceUnavailable,
+		"/healthz":              http.StatusOK,
+		"/readyz":               http.StatusOK,
+		"/healthzz":             http.StatusServiceUnavailable,
+	} {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != want {
+			t.Errorf("%s: status = %d, want %d", path, rec.Code, want)
+		}
+	}
+}
+
+func TestApp_MountHealthAllowlistsPrefixedProbes(t *testing.T) {
+	app := NewApp(WithHealthMonitor(NewHealthMonitor()))
+	app.MountHealth(HealthRouteOptions{Prefix: "/ops/"})
+	m := app.Maintenance()
+	m.Set(true)
+	h := m.Handler(app.Handler())
+	for _, path := range []string{"/ops/healthz", "/ops/readyz"} {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != http.StatusOK {
+			t.Errorf("%s: status = %d, want 200", path, rec.Code)
+		}
+	}
+}
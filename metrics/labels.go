@@ -0,0 +1,97 @@
+// Package metrics carries request-scoped metric labels. Controllers and
+// middleware attach labels with Label; the observability middleware that
+// installed the label set (see otel.Middleware) applies them to every metric
+// it records for the request.
+package metrics
+
+import (
+	"context"
+	"sort"
+	"sync"
+)
+
+// MaxLabels bounds the labels one request can carry; further keys are
+// dropped.
+const MaxLabels = 8
+
+// MaxValueLen bounds a label value; longer values are truncated.
+const MaxValueLen = 64
+
+// KeyValue is a single metric label.
+type KeyValue struct {
+	Key   string
+	Value string
+}
+
+type labelsCtxKey struct{}
+
+// LabelSet holds the labels of one request. It is safe for concurrent use.
+type LabelSet struct {
+	mu     sync.Mutex
+	labels []KeyValue
+}
+
+// WithLabels attaches an empty label set to ctx, unless ctx already has one.
+func WithLabels(ctx context.Context) context.Context {
+	if FromContext(ctx) != nil {
+		return ctx
+	}
+	return context.WithValue(ctx, labelsCtxKey{}, &LabelSet{})
+}
+
+// FromContext returns the label set of ctx, or nil.
+func FromContext(ctx context.Context) *LabelSet {
+	if ctx == nil {
+		return nil
+	}
+	set, _ := ctx.Value(labelsCtxKey{}).(*LabelSet)
+	return set
+}
+
+// Label sets key to value on the request's metrics, replacing an earlier
+// value of key. It is a no-op when ctx has no label set. Keep values to a
+// small, fixed vocabulary such as client_app or api_version; never use IDs.
+func Label(ctx context.Context, key, value string) {
+	if set := FromContext(ctx); set != nil {
+		set.Set(key, value)
+	}
+}
+
+// Labels returns the labels of ctx sorted by key.
+func Labels(ctx context.Context) []KeyValue {
+	return FromContext(ctx).List()
+}
+
+// Set sets key to value, see Label.
+func (s *LabelSet) Set(key, value string) {
+	if s == nil || key == "" {
+		return
+	}
+	if len(value) > MaxValueLen {
+		value = value[:MaxValueLen]
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.labels {
+		if s.labels[i].Key == key {
+			s.labels[i].Value = value
+			return
+		}
+	}
+	if len(s.labels) < MaxLabels {
+		s.labels = append(s.labels, KeyValue{Key: key, Value: value})
+	}
+}
+
+// List returns a copy of the labels sorted by key.
+func (s *LabelSet) List() []KeyValue {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	out := make([]KeyValue, len(s.labels))
+	copy(out, s.labels)
+	s.mu.Unlock()
+	sort.Slice(out, func(i, j int) bool { return out[i].Key < out[j].Key })
+	return out
+}
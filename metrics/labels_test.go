@@ -0,0 +1,48 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestLabelWithoutSetIsNoop(t *testing.T) {
+	ctx := context.Background()
+	Label(ctx, "client_app", "ios")
+	if got := Labels(ctx); len(got) != 0 {
+		t.Fatalf("Labels = %v, want none", got)
+	}
+}
+
+func TestLabelReplacesAndSorts(t *testing.T) {
+	ctx := WithLabels(context.Background())
+	if WithLabels(ctx) != ctx {
+		t.Fatal("WithLabels replaced an existing label set")
+	}
+	Label(ctx, "client_app", "ios")
+	Label(ctx, "api_version", "v1")
+	Label(ctx, "client_app", "android")
+
+	got := Labels(ctx)
+	want := []KeyValue{{"api_version", "v1"}, {"client_app", "android"}}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("Labels = %v, want %v", got, want)
+	}
+}
+
+func TestLabelBounds(t *testing.T) {
+	ctx := WithLabels(context.Background())
+	for i := 0; i < MaxLabels+3; i++ {
+		Label(ctx, fmt.Sprintf("k%02d", i), "v")
+	}
+	Label(ctx, "k00", strings.Repeat("x", MaxValueLen+10))
+
+	got := Labels(ctx)
+	if len(got) != MaxLabels {
+		t.Fatalf("len(Labels) = %d, want %d", len(got), MaxLabels)
+	}
+	if len(got[0].Value) != MaxValueLen {
+		t.Fatalf("value length = %d, want %d", len(got[0].Value), MaxValueLen)
+	}
+}
@@ -2,8 +2,12 @@ package golitekit
 
 import (
 	"context"
+	"fmt"
 	"net/http"
+	"reflect"
+	"runtime"
 	"slices"
+	"strings"
 )
 
 // Handler is the core handler type. The returned error propagates up the
@@ -25,12 +29,27 @@ func (h Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 // Middleware wraps a Handler to add cross-cutting behaviour.
 type Middleware func(next Handler) Handler
 
-// MiddlewareQueue is an ordered list of Middleware values.
-type MiddlewareQueue []Middleware
+// MiddlewareEntry is a queued middleware. Named entries can be located by
+// InsertBefore, InsertAfter, Replace and Remove.
+type MiddlewareEntry struct {
+	Name       string
+	Middleware Middleware
+}
+
+// MiddlewareQueue is an ordered list of middlewares.
+type MiddlewareQueue []MiddlewareEntry
+
+// MiddlewareDescription describes a queued middleware for introspection.
+type MiddlewareDescription struct {
+	Name string `json:"name,omitempty"`
+	Func string `json:"func"`
+}
 
 // NewMiddlewareQueue returns a MiddlewareQueue from the given middlewares.
 func NewMiddlewareQueue(middlewares ...Middleware) MiddlewareQueue {
-	return middlewares
+	mq := make(MiddlewareQueue, 0, len(middlewares))
+	mq.Use(middlewares...)
+	return mq
 }
 
 // Clone returns a shallow copy of the queue.
@@ -38,19 +57,126 @@ func (mq MiddlewareQueue) Clone() MiddlewareQueue {
 	return slices.Clone(mq)
 }
 
-// Use appends middlewares to the queue.
+// Use appends anonymous middlewares to the queue.
 func (mq *MiddlewareQueue) Use(middlewares ...Middleware) {
-	*mq = append(*mq, middlewares...)
+	for _, m := range middlewares {
+		*mq = append(*mq, MiddlewareEntry{Middleware: m})
+	}
+}
+
+// UseNamed appends m under name. It panics if name is already queued.
+func (mq *MiddlewareQueue) UseNamed(name string, m Middleware) {
+	mq.mustBeNew(name)
+	*mq = append(*mq, MiddlewareEntry{Name: name, Middleware: m})
+}
+
+// InsertBefore inserts m, named name ("" for anonymous), in front of the
+// middleware named target. It panics if target is not queued.
+func (mq *MiddlewareQueue) InsertBefore(target, name string, m Middleware) {
+	mq.insert(mq.mustIndex(target), name, m)
+}
+
+// InsertAfter inserts m, named name ("" for anonymous), behind the middleware
+// named target. It panics if target is not queued.
+func (mq *MiddlewareQueue) InsertAfter(target, name string, m Middleware) {
+	mq.insert(mq.mustIndex(target)+1, name, m)
+}
+
+// Replace swaps the middleware named name for m, keeping its position. It
+// panics if name is not queued.
+func (mq *MiddlewareQueue) Replace(name string, m Middleware) {
+	(*mq)[mq.mustIndex(name)].Middleware = m
+}
+
+// Remove drops the middleware named name and reports whether it was queued.
+func (mq *MiddlewareQueue) Remove(name string) bool {
+	i := mq.Index(name)
+	if i < 0 {
+		return false
+	}
+	*mq = slices.Delete(*mq, i, i+1)
+	return true
+}
+
+// Index returns the position of the middleware named name, or -1.
+func (mq MiddlewareQueue) Index(name string) int {
+	if name == "" {
+		return -1
+	}
+	return slices.IndexFunc(mq, func(e MiddlewareEntry) bool { return e.Name == name })
+}
+
+// Describe lists the queued middlewares in order, outermost first.
+func (mq MiddlewareQueue) Describe() []MiddlewareDescription {
+	out := make([]MiddlewareDescription, len(mq))
+	for i, e := range mq {
+		out[i] = MiddlewareDescription{Name: e.Name, Func: funcName(e.Middleware)}
+	}
+	return out
 }
 
 // Apply wraps handler with all middlewares, outermost first.
 func (mq MiddlewareQueue) Apply(handler Handler) Handler {
 	for i := len(mq) - 1; i >= 0; i-- {
-		handler = mq[i](handler)
+		handler = mq[i].Middleware(handler)
 	}
 	return handler
 }
 
+// middlewareOrder pairs default middlewares where the first must wrap the
+// second.
+var middlewareOrder = [][2]string{
+	// spans, metrics and health counters see the status ErrorHandler wrote
+	{MiddlewareObservability, MiddlewareErrorHandler},
+	{MiddlewareHealth, MiddlewareErrorHandler},
+	// ErrorHandler renders their errors and recovers the panics Timeout
+	// re-raises on the request goroutine
+	{MiddlewareErrorHandler, MiddlewareTimeout},
+	{MiddlewareErrorHandler, MiddlewareContext},
+	// a response rendered after the deadline goes to Timeout's discarded writer
+	{MiddlewareTimeout, MiddlewareContext},
+}
+
+// Validate checks the order of the named defaults: observability and health
+// wrap ErrorHandler, which wraps Timeout and Context, and Timeout wraps
+// Context. ErrorHandler may be replaced but not removed while Timeout or
+// Context is queued, or their panics would escape to net/http.
+func (mq MiddlewareQueue) Validate() error {
+	if mq.Index(MiddlewareErrorHandler) < 0 {
+		for _, name := range []string{MiddlewareTimeout, MiddlewareContext} {
+			if mq.Index(name) >= 0 {
+				return fmt.Errorf("middleware %q requires %q", name, MiddlewareErrorHandler)
+			}
+		}
+	}
+	for _, pair := range middlewareOrder {
+		outer, inner := mq.Index(pair[0]), mq.Index(pair[1])
+		if outer >= 0 && inner >= 0 && outer > inner {
+			return fmt.Errorf("middleware %q must come before %q", pair[0], pair[1])
+		}
+	}
+	return nil
+}
+
+func (mq *MiddlewareQueue) insert(i int, name string, m Middleware) {
+	mq.mustBeNew(name)
+	*mq = slices.Insert(*mq, i, MiddlewareEntry{Name: name, Middleware: m})
+}
+
+func (mq MiddlewareQueue) mustIndex(name string) int {
+	i := mq.Index(name)
+	if i < 0 {
+		panic(fmt.Sprintf("golitekit: middleware %q not found", name))
+	}
+	return i
+}
+
+func (mq MiddlewareQueue) mustBeNew(name string) {
+	if mq.Index(name) >= 0 {
+		panic(fmt.Sprintf("golitekit: middleware %q already registered", name))
+	}
+}
+
 // StdMiddleware adapts a standard net/http middleware to Middleware.
 // Use this to integrate third-party middlewares (e.g. CORS) with the framework.
 func StdMiddleware(m func(http.Handler) http.Handler) Middleware {
@@ -65,3 +191,28 @@ func StdMiddleware(m func(http.Handler) http.Handler) Middleware {
 		}
 	}
 }
+
+// funcName returns a short, human-readable name for a function value, e.g.
+// "golitekit.TimeoutMiddleware" for the closure returned by TimeoutMiddleware.
+func funcName(fn any) string {
+	v := reflect.ValueOf(fn)
+	if v.Kind() != reflect.Func || v.IsNil() {
+		return ""
+	}
+	f := runtime.FuncForPC(v.Pointer())
+	if f == nil {
+		return ""
+	}
+	name := f.Name()
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+	for {
+		i := strings.LastIndex(name, ".func")
+		if i < 0 || strings.ContainsFunc(name[i+len(".func"):], func(r rune) bool { return (r < '0' || r > '9') && r != '.' }) {
+			break
+		}
+		name = name[:i]
+	}
+	return strings.TrimSuffix(name, "-fm")
+}
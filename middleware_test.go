@@ -4,7 +4,9 @@ import (
 	"context"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestNewMiddlewareQueue(t *testing.T) {
@@ -275,3 +277,157 @@ func TestStdMiddleware(t *testing.T) {
 		}
 	})
 }
+
+func TestMiddlewareQueue_Named(t *testing.T) {
+	var order []string
+	trace := func(name string) Middleware {
+		return func(next Handler) Handler {
+			return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+				order = append(order, name)
+				return next(ctx, w, r)
+			}
+		}
+	}
+	run := func(mq MiddlewareQueue) string {
+		order = nil
+		h := mq.Apply(func(ctx context.Context, w http.ResponseWriter, r *http.Request) error { return nil })
+		req := httptest.NewRequest("GET", "/", nil)
+		_ = h(req.Context(), httptest.NewRecorder(), req)
+		return strings.Join(order, ",")
+	}
+
+	mq := NewMiddlewareQueue(trace("anon"))
+	mq.UseNamed("auth", trace("auth"))
+	mq.UseNamed("cache", trace("cache"))
+	mq.InsertBefore("auth", "cors", trace("cors"))
+	mq.InsertAfter("cache", "", trace("tail"))
+	if got := run(mq); got != "anon,cors,auth,cache,tail" {
+		t.Fatalf("order = %s", got)
+	}
+
+	mq.Replace("auth", trace("jwt"))
+	if !mq.Remove("cache") || mq.Remove("cache") {
+		t.Fatal("Remove should report whether the middleware was queued")
+	}
+	if got := run(mq); got != "anon,cors,jwt,tail" {
+		t.Fatalf("order after replace/remove = %s", got)
+	}
+
+	desc := mq.Describe()
+	if len(desc) != 4 || desc[1].Name != "cors" || desc[2].Name != "auth" || desc[0].Name != "" || desc[0].Func == "" {
+		t.Fatalf("describe = %+v", desc)
+	}
+
+	for name, fn := range map[string]func(){
+		"duplicate": func() { mq.UseNamed("cors", trace("x")) },
+		"missing":   func() { mq.InsertAfter("nope", "", trace("x")) },
+		"replace":   func() { mq.Replace("nope", trace("x")) },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s: expected a panic", name)
+				}
+			}()
+			fn()
+		}()
+	}
+}
+
+func TestApp_EditDefaultMiddlewares(t *testing.T) {
+	app := NewApp()
+	var names []string
+	for _, m := range app.Middlewares() {
+		names = append(names, m.Name)
+	}
+	if got := strings.Join(names, ","); got != "error_handler,logger,logid,timeout,context" {
+		t.Fatalf("default middlewares = %s", got)
+	}
+
+	app.EditMiddlewares(func(mq *MiddlewareQueue) {
+		mq.InsertAfter(MiddlewareLogID, "stamp", func(next Handler) Handler {
+			return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+				w.Header().Set("X-Stamp", "1")
+				return next(ctx, w, r)
+			}
+		})
+		mq.Remove(MiddlewareTimeout)
+	})
+	app.GET("/", HandlerFunc(func(ctx *Context) error { return nil }))
+
+	rec := httptest.NewRecorder()
+	app.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	if rec.Header().Get("X-Stamp") != "1" {
+		t.Error("inserted middleware did not run")
+	}
+	if desc := app.Middlewares(); len(desc) != 5 || desc[3].Name != "stamp" {
+		t.Errorf("middlewares = %+v", desc)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("EditMiddlewares after routes should panic")
+		}
+	}()
+	app.EditMiddlewares(func(mq *MiddlewareQueue) {})
+}
+
+func TestMiddlewareQueue_Validate(t *testing.T) {
+	noop := func(next Handler) Handler { return next }
+	queue := func(names ...string) MiddlewareQueue {
+		var mq MiddlewareQueue
+		for _, name := range names {
+			mq.UseNamed(name, noop)
+		}
+		return mq
+	}
+	tests := []struct {
+		names []string
+		ok    bool
+	}{
+		{[]string{MiddlewareObservability, MiddlewareErrorHandler, MiddlewareLogger, MiddlewareTimeout, MiddlewareContext}, true},
+		{[]string{MiddlewareErrorHandler, MiddlewareContext}, true},
+		{nil, true},
+		{[]string{MiddlewareTimeout, MiddlewareErrorHandler, MiddlewareContext}, false},
+		{[]string{MiddlewareErrorHandler, MiddlewareContext, MiddlewareTimeout}, false},
+		{[]string{MiddlewareErrorHandler, MiddlewareHealth}, false},
+		{[]string{MiddlewareLogger, MiddlewareTimeout, MiddlewareContext}, false},
+	}
+	for _, tt := range tests {
+		if err := queue(tt.names...).Validate(); (err == nil) != tt.ok {
+			t.Errorf("Validate(%v) = %v, want ok=%v", tt.names, err, tt.ok)
+		}
+	}
+}
+
+func TestApp_EditMiddlewaresEnforcesOrder(t *testing.T) {
+	app := NewApp()
+	before := app.Middlewares()
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("removing the error handler should panic")
+			}
+		}()
+		app.EditMiddlewares(func(mq *MiddlewareQueue) { mq.Remove(MiddlewareErrorHandler) })
+	}()
+	if after := app.Middlewares(); len(after) != len(before) {
+		t.Fatalf("rejected edit changed the chain: %+v", after)
+	}
+}
+
+func TestWithDefaultMiddlewareOptions(t *testing.T) {
+	app := NewApp(WithDefaultMiddlewareOptions(func(o *DefaultMiddlewareOptions) {
+		o.Timeout.Duration = 20 * time.Millisecond
+	}))
+	app.GET("/slow", HandlerFunc(func(ctx *Context) error {
+		<-ctx.Context().Done()
+		return nil
+	}))
+
+	rec := httptest.NewRecorder()
+	app.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/slow", nil))
+	if rec.Code != http.StatusGatewayTimeout {
+		t.Fatalf("status = %d, want 504 from the configured timeout", rec.Code)
+	}
+}
@@ -0,0 +1,200 @@
+package golitekit
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/hansir-hsj/GoLiteKit/workerpool"
+)
+
+// MirrorHeader marks the requests a Mirror sends, so the shadow upstream can
+// tell them from live traffic, e.g. to skip side effects.
+const MirrorHeader = "X-Glk-Mirror"
+
+const (
+	DefaultMirrorMaxBodyBytes = 1 << 20
+	DefaultMirrorTimeout      = 5 * time.Second
+)
+
+// MirrorOptions configures NewMirror.
+type MirrorOptions struct {
+	// Upstream is the base URL of the shadow service, e.g.
+	// "http://orders-canary:8080"; the request URI is appended.
+	Upstream string
+	// SampleRate is the fraction of requests mirrored, e.g. 0.05 for 5%;
+	// defaults to 1.
+	SampleRate float64
+	// MaxBodyBytes bounds the request body buffered for the copy; requests
+	// with larger bodies are not mirrored. Defaults to
+	// DefaultMirrorMaxBodyBytes.
+	MaxBodyBytes int64
+	// Timeout bounds each mirrored request, defaults to DefaultMirrorTimeout.
+	Timeout time.Duration
+	// Filter, if set, selects the requests that may be mirrored.
+	Filter func(r *http.Request) bool
+	// Client sends the copies, defaults to http.DefaultClient.
+	Client *http.Client
+	// Pool sends the copies in the background; when its queue is full the
+	// copy is dropped. Defaults to a pool of GOMAXPROCS workers. Register it
+	// with WithWorkerPool to drain it on shutdown.
+	Pool *workerpool.Pool
+}
+
+// MirrorStats counts the requests of a Mirror.
+type MirrorStats struct {
+	Sent    uint64 // copies the upstream answered, whatever the status
+	Failed  uint64 // copies that got no response
+	Dropped uint64 // copies the pool had no room for
+	Skipped uint64 // sampled requests whose body exceeded MaxBodyBytes
+}
+
+// Mirror replays a share of the requests to a shadow upstream, for testing a
+// new version of a service with production traffic. The copies are sent in
+// the background after the handler ran and their responses are ignored, so
+// the shadow never affects the live response.
+type Mirror struct {
+	opts MirrorOptions
+
+	sent, failed, dropped, skipped atomic.Uint64
+}
+
+// NewMirror creates a Mirror, filling zero-valued options with defaults.
+func NewMirror(opts MirrorOptions) *Mirror {
+	if opts.SampleRate <= 0 {
+		opts.SampleRate = 1
+	}
+	if opts.MaxBodyBytes <= 0 {
+		opts.MaxBodyBytes = DefaultMirrorMaxBodyBytes
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultMirrorTimeout
+	}
+	if opts.Client == nil {
+		opts.Client = http.DefaultClient
+	}
+	if opts.Pool == nil {
+		opts.Pool = workerpool.New()
+	}
+	opts.Upstream = strings.TrimRight(opts.Upstream, "/")
+	return &Mirror{opts: opts}
+}
+
+// Middleware mirrors the sampled requests. Each copy carries MirrorHeader
+// and the request's log ID in X-Log-Id, so both sides of a request can be
+// found in the logs.
+func (m *Mirror) Middleware() Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			if !m.wants(r) {
+				return next(ctx, w, r)
+			}
+			body, ok := m.bufferBody(r)
+			if !ok {
+				m.skipped.Add(1)
+				return next(ctx, w, r)
+			}
+			out := m.newRequest(r, EnsureLogID(ctx))
+			err := next(ctx, w, r)
+			// The copy detaches from the request, which ends with the handler.
+			bg := context.WithoutCancel(ctx)
+			if !m.opts.Pool.TrySubmit(bg, func(ctx context.Context) { m.send(ctx, out, body) }) {
+				m.dropped.Add(1)
+			}
+			return err
+		}
+	}
+}
+
+// Stats returns the counters of the mirrored requests.
+func (m *Mirror) Stats() MirrorStats {
+	return MirrorStats{
+		Sent:    m.sent.Load(),
+		Failed:  m.failed.Load(),
+		Dropped: m.dropped.Load(),
+		Skipped: m.skipped.Load(),
+	}
+}
+
+func (m *Mirror) wants(r *http.Request) bool {
+	// Upgrades hand the connection to the handler and cannot be replayed.
+	if r.Header.Get("Upgrade") != "" || r.Header.Get(MirrorHeader) != "" {
+		return false
+	}
+	if m.opts.Filter != nil && !m.opts.Filter(r) {
+		return false
+	}
+	return m.opts.SampleRate >= 1 || rand.Float64() < m.opts.SampleRate
+}
+
+// bufferBody reads the body for the copy and restores it for the handler;
+// it reports false when the body exceeds MaxBodyBytes.
+func (m *Mirror) bufferBody(r *http.Request) ([]byte, bool) {
+	if r.Body == nil || r.Body == http.NoBody {
+		return nil, true
+	}
+	if r.ContentLength > m.opts.MaxBodyBytes {
+		return nil, false
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, m.opts.MaxBodyBytes+1))
+	r.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(body), r.Body), r.Body}
+	if err != nil || int64(len(body)) > m.opts.MaxBodyBytes {
+		return nil, false
+	}
+	return body, true
+}
+
+// newRequest copies the method, URI and end-to-end headers of r.
+func (m *Mirror) newRequest(r *http.Request, logID string) *http.Request {
+	header := r.Header.Clone()
+	for _, h := range hopHeaders {
+		header.Del(h)
+	}
+	header.Set(MirrorHeader, "1")
+	if logID != "" {
+		header.Set(LogIDHeader, logID)
+	}
+	out := &http.Request{
+		Method: r.Method,
+		Header: header,
+	}
+	out.URL, _ = r.URL.Parse(m.opts.Upstream + r.URL.RequestURI())
+	return out
+}
+
+// hopHeaders are the headers that belong to one connection, RFC 9110 7.6.1.
+var hopHeaders = []string{
+	"Connection", "Keep-Alive", "Proxy-Authenticate", "Proxy-Authorization",
+	"Proxy-Connection", "Te", "Trailer", "Transfer-Encoding", "Upgrade",
+}
+
+func (m *Mirror) send(ctx context.Context, out *http.Request, body []byte) {
+	if out.URL == nil {
+		m.failed.Add(1)
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, m.opts.Timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, out.Method, out.URL.String(), bytes.NewReader(body))
+	if err != nil {
+		m.failed.Add(1)
+		return
+	}
+	req.Header = out.Header
+	resp, err := m.opts.Client.Do(req)
+	if err != nil {
+		m.failed.Add(1)
+		return
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	m.sent.Add(1)
+}
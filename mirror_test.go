@@ -0,0 +1,100 @@
+package golitekit
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hansir-hsj/GoLiteKit/workerpool"
+)
+
+func TestMirrorReplaysRequestsToUpstream(t *testing.T) {
+	type shadowed struct {
+		method, uri, body, logID, mirror, conn string
+	}
+	var (
+		mu  sync.Mutex
+		got []shadowed
+	)
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		got = append(got, shadowed{r.Method, r.URL.RequestURI(), string(body), r.Header.Get(LogIDHeader), r.Header.Get(MirrorHeader), r.Header.Get("Connection")})
+		mu.Unlock()
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer upstream.Close()
+
+	pool := workerpool.New(workerpool.Options{Workers: 1, QueueSize: 8})
+	mirror := NewMirror(MirrorOptions{Upstream: upstream.URL + "/", MaxBodyBytes: 8, Pool: pool})
+	app := NewApp()
+	app.Use(mirror.Middleware())
+	var seen []string
+	app.POST("/orders", HandlerFunc(func(ctx *Context) error {
+		body, _ := io.ReadAll(ctx.Request().Body)
+		seen = append(seen, string(body))
+		ctx.ResponseWriter().WriteHeader(http.StatusCreated)
+		return nil
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/orders?x=1", strings.NewReader("small"))
+	req.Header.Set(LogIDHeader, "log-1")
+	req.Header.Set("Connection", "keep-alive")
+	rec := httptest.NewRecorder()
+	app.Handler().ServeHTTP(rec, req)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("live status = %d, want 201", rec.Code)
+	}
+	// Too large to mirror, still served in full.
+	app.Handler().ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader("much too large")))
+
+	if err := pool.Drain(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(seen) != 2 || seen[0] != "small" || seen[1] != "much too large" {
+		t.Fatalf("handler saw bodies %q", seen)
+	}
+	want := shadowed{http.MethodPost, "/orders?x=1", "small", "log-1", "1", ""}
+	if len(got) != 1 || got[0] != want {
+		t.Fatalf("upstream got %+v, want [%+v]", got, want)
+	}
+	if stats := mirror.Stats(); stats != (MirrorStats{Sent: 1, Skipped: 1}) {
+		t.Fatalf("stats = %+v", stats)
+	}
+}
+
+func TestMirrorSkipsFilteredAndMirroredRequests(t *testing.T) {
+	var calls int
+	var mu sync.Mutex
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		calls++
+		mu.Unlock()
+	}))
+	defer upstream.Close()
+
+	pool := workerpool.New()
+	mirror := NewMirror(MirrorOptions{
+		Upstream: upstream.URL,
+		Pool:     pool,
+		Filter:   func(r *http.Request) bool { return r.Method == http.MethodGet },
+	})
+	h := mirror.Middleware()(func(ctx context.Context, w http.ResponseWriter, r *http.Request) error { return nil })
+
+	_ = h(context.Background(), httptest.NewRecorder(), httptest.NewRequest(http.MethodDelete, "/orders/1", nil))
+	again := httptest.NewRequest(http.MethodGet, "/orders/1", nil)
+	again.Header.Set(MirrorHeader, "1")
+	_ = h(context.Background(), httptest.NewRecorder(), again)
+	_ = h(context.Background(), httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/orders/1", nil))
+
+	if err := pool.Drain(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 1 {
+		t.Fatalf("upstream calls = %d, want 1", calls)
+	}
+}
@@ -0,0 +1,345 @@
+// Package oauth signs users in with OAuth2 and OpenID Connect providers
+// (Google, GitHub, any OIDC issuer) and keeps them signed in with a session
+// cookie whose Principal feeds golitekit's auth and authz layers.
+//
+//	google, err := oauth.Google(ctx, oauth.ProviderConfig{ClientID: id, ClientSecret: secret})
+//	m := oauth.New(oauth.Options{Secret: key, Providers: []*oauth.Provider{google, oauth.GitHub(gh)}})
+//
+//	app.Use(m.SessionMiddleware())
+//	m.Mount(app, "/auth") // GET /auth/{provider}/login, GET /auth/{provider}/callback, POST /auth/logout
+//
+// The login route accepts a local "next" path to return to after the
+// callback. State, nonce and the PKCE verifier travel in a short-lived signed
+// cookie; the session is a signed cookie as well, so no server-side store is
+// needed.
+package oauth
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	glk "github.com/hansir-hsj/GoLiteKit"
+	"golang.org/x/oauth2"
+)
+
+const (
+	DefaultSessionCookie = "glk_session"
+	DefaultSessionTTL    = 24 * time.Hour
+	stateCookie          = "glk_oauth"
+	stateTTL             = 10 * time.Minute
+)
+
+// Registrar is a router: *App, *Router or *RouterGroup.
+type Registrar interface {
+	GET(path string, c any)
+	POST(path string, c any)
+}
+
+// Options configures a Manager.
+type Options struct {
+	// Secret signs the state and session cookies. Required.
+	Secret    []byte
+	Providers []*Provider
+	// OnLogin maps the signed-in user to the session principal, e.g. to load
+	// roles or to reject unknown users by returning an *AppError. Defaults to
+	// DefaultPrincipal.
+	OnLogin func(ctx context.Context, u *User) (*glk.Principal, error)
+	// SessionCookie is the session cookie name, defaults to
+	// DefaultSessionCookie.
+	SessionCookie string
+	// SessionTTL defaults to DefaultSessionTTL.
+	SessionTTL time.Duration
+	// AfterLogin and AfterLogout are the redirect targets, default "/".
+	AfterLogin  string
+	AfterLogout string
+}
+
+// Manager runs the login flows of its providers and the session they create.
+type Manager struct {
+	opts      Options
+	providers map[string]*Provider
+}
+
+// New returns a Manager. It panics without a secret or with duplicate
+// provider names.
+func New(opts Options) *Manager {
+	if len(opts.Secret) == 0 {
+		panic("oauth: Options.Secret is required")
+	}
+	if opts.OnLogin == nil {
+		opts.OnLogin = func(ctx context.Context, u *User) (*glk.Principal, error) {
+			return DefaultPrincipal(u), nil
+		}
+	}
+	if opts.SessionCookie == "" {
+		opts.SessionCookie = DefaultSessionCookie
+	}
+	if opts.SessionTTL <= 0 {
+		opts.SessionTTL = DefaultSessionTTL
+	}
+	if opts.AfterLogin == "" {
+		opts.AfterLogin = "/"
+	}
+	if opts.AfterLogout == "" {
+		opts.AfterLogout = "/"
+	}
+	m := &Manager{opts: opts, providers: make(map[string]*Provider, len(opts.Providers))}
+	for _, p := range opts.Providers {
+		if _, dup := m.providers[p.Name]; dup {
+			panic("oauth: duplicate provider " + p.Name)
+		}
+		m.providers[p.Name] = p
+	}
+	return m
+}
+
+// DefaultPrincipal identifies u as "provider:id" with scheme "oauth" and
+// the email and name as claims.
+func DefaultPrincipal(u *User) *glk.Principal {
+	claims := map[string]string{"provider": u.Provider}
+	if u.Email != "" {
+		claims["email"] = u.Email
+	}
+	if u.Name != "" {
+		claims["name"] = u.Name
+	}
+	return &glk.Principal{ID: u.Provider + ":" + u.ID, Scheme: "oauth", Claims: claims}
+}
+
+// Mount registers the login, callback and logout routes under prefix.
+func (m *Manager) Mount(r Registrar, prefix string) {
+	prefix = strings.TrimSuffix(prefix, "/")
+	r.GET(prefix+"/{provider}/login", glk.HandlerFunc(func(ctx *glk.Context) error {
+		return m.login(ctx, prefix)
+	}))
+	r.GET(prefix+"/{provider}/callback", glk.HandlerFunc(func(ctx *glk.Context) error {
+		return m.callback(ctx, prefix)
+	}))
+	r.POST(prefix+"/logout", glk.HandlerFunc(m.logout))
+}
+
+// loginState is kept in the state cookie between login and callback.
+type loginState struct {
+	Provider    string `json:"p"`
+	State       string `json:"s"`
+	Nonce       string `json:"n"`
+	Verifier    string `json:"v"`
+	RedirectURL string `json:"r"`
+	Next        string `json:"x"`
+	Expires     int64  `json:"e"`
+}
+
+func (m *Manager) provider(ctx *glk.Context) (*Provider, error) {
+	p := m.providers[ctx.Param("provider")]
+	if p == nil {
+		return nil, glk.ErrNotFound("unknown login provider", nil)
+	}
+	return p, nil
+}
+
+func (m *Manager) login(ctx *glk.Context, prefix string) error {
+	p, err := m.provider(ctx)
+	if err != nil {
+		return err
+	}
+	r := ctx.Request()
+	st := loginState{
+		Provider:    p.Name,
+		State:       randomToken(),
+		Nonce:       randomToken(),
+		Verifier:    oauth2.GenerateVerifier(),
+		RedirectURL: p.Config.RedirectURL,
+		Next:        localPath(r.URL.Query().Get("next"), m.opts.AfterLogin),
+		Expires:     time.Now().Add(stateTTL).Unix(),
+	}
+	if st.RedirectURL == "" {
+		st.RedirectURL = requestOrigin(r) + prefix + "/" + p.Name + "/callback"
+	}
+	m.setCookie(ctx, stateCookie, m.seal("state", st), prefix+"/", int(stateTTL/time.Second))
+
+	cfg := p.Config
+	cfg.RedirectURL = st.RedirectURL
+	opts := []oauth2.AuthCodeOption{oauth2.S256ChallengeOption(st.Verifier)}
+	if p.verifier != nil {
+		opts = append(opts, oauth2.SetAuthURLParam("nonce", st.Nonce))
+	}
+	return ctx.Redirect(http.StatusFound, cfg.AuthCodeURL(st.State, opts...))
+}
+
+func (m *Manager) callback(ctx *glk.Context, prefix string) error {
+	p, err := m.provider(ctx)
+	if err != nil {
+		return err
+	}
+	r := ctx.Request()
+	rctx := r.Context()
+	q := r.URL.Query()
+	if e := q.Get("error"); e != "" {
+		return glk.ErrUnauthorized("login failed: "+e, nil)
+	}
+
+	var st loginState
+	c, err := r.Cookie(stateCookie)
+	if err != nil || !m.open("state", c.Value, &st) || st.Provider != p.Name || time.Now().Unix() > st.Expires {
+		return glk.ErrBadRequest("login expired, please try again", nil)
+	}
+	if !hmac.Equal([]byte(q.Get("state")), []byte(st.State)) {
+		return glk.ErrBadRequest("invalid login state", nil)
+	}
+	m.setCookie(ctx, stateCookie, "", prefix+"/", -1)
+
+	cfg := p.Config
+	cfg.RedirectURL = st.RedirectURL
+	token, err := cfg.Exchange(rctx, q.Get("code"), oauth2.VerifierOption(st.Verifier))
+	if err != nil {
+		return glk.ErrUnauthorized("login failed", err)
+	}
+	user, err := p.user(rctx, token, st.Nonce)
+	if err != nil {
+		return glk.ErrUnauthorized("login failed", err)
+	}
+	principal, err := m.opts.OnLogin(rctx, user)
+	if err != nil {
+		return glk.WrapError(err, http.StatusInternalServerError)
+	}
+	if principal == nil {
+		return glk.ErrForbidden("login not allowed", nil)
+	}
+
+	ttl := m.opts.SessionTTL
+	value := m.seal("session", session{Principal: *principal, Expires: time.Now().Add(ttl).Unix()})
+	m.setCookie(ctx, m.opts.SessionCookie, value, "/", int(ttl/time.Second))
+	return ctx.Redirect(http.StatusFound, st.Next)
+}
+
+func (m *Manager) logout(ctx *glk.Context) error {
+	m.setCookie(ctx, m.opts.SessionCookie, "", "/", -1)
+	return ctx.Redirect(http.StatusSeeOther, m.opts.AfterLogout)
+}
+
+type session struct {
+	Principal glk.Principal `json:"p"`
+	Expires   int64         `json:"e"`
+}
+
+// SessionMiddleware places the Principal of a valid session cookie in the
+// Context. Requests without a session pass through unauthenticated; guard
+// routes with authz.RequirePermission or RequireLogin.
+func (m *Manager) SessionMiddleware() glk.Middleware {
+	return func(next glk.Handler) glk.Handler {
+		return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			if p := m.Session(r); p != nil {
+				if gcx := glk.GetContext(ctx); gcx != nil {
+					gcx.SetPrincipal(p)
+				}
+			}
+			return next(ctx, w, r)
+		}
+	}
+}
+
+// Session returns the Principal of r's session cookie, or nil.
+func (m *Manager) Session(r *http.Request) *glk.Principal {
+	c, err := r.Cookie(m.opts.SessionCookie)
+	if err != nil {
+		return nil
+	}
+	var s session
+	if !m.open("session", c.Value, &s) || time.Now().Unix() > s.Expires {
+		return nil
+	}
+	return &s.Principal
+}
+
+// RequireLogin redirects requests without a principal to loginURL, with the
+// requested path as "next".
+func RequireLogin(loginURL string) glk.Middleware {
+	return func(next glk.Handler) glk.Handler {
+		return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			if glk.PrincipalFrom(ctx) != nil {
+				return next(ctx, w, r)
+			}
+			target := loginURL + "?next=" + url.QueryEscape(r.URL.RequestURI())
+			if gcx := glk.GetContext(ctx); gcx != nil {
+				return gcx.Redirect(http.StatusFound, target)
+			}
+			http.Redirect(w, r, target, http.StatusFound)
+			return nil
+		}
+	}
+}
+
+func (m *Manager) setCookie(ctx *glk.Context, name, value, path string, maxAge int) {
+	http.SetCookie(ctx.ResponseWriter(), &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     path,
+		MaxAge:   maxAge,
+		HttpOnly: true,
+		Secure:   ctx.Request().TLS != nil,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// seal encodes v as a signed cookie value; purpose keeps state cookies from
+// being replayed as sessions.
+func (m *Manager) seal(purpose string, v any) string {
+	payload, _ := json.Marshal(v)
+	enc := base64.RawURLEncoding.EncodeToString(payload)
+	return enc + "." + base64.RawURLEncoding.EncodeToString(m.mac(purpose, enc))
+}
+
+func (m *Manager) open(purpose, value string, v any) bool {
+	enc, sig, ok := strings.Cut(value, ".")
+	if !ok {
+		return false
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil || !hmac.Equal(mac, m.mac(purpose, enc)) {
+		return false
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(enc)
+	if err != nil {
+		return false
+	}
+	return json.Unmarshal(payload, v) == nil
+}
+
+func (m *Manager) mac(purpose, payload string) []byte {
+	h := hmac.New(sha256.New, m.opts.Secret)
+	h.Write([]byte("glk-oauth-" + purpose + ":" + payload))
+	return h.Sum(nil)
+}
+
+func randomToken() string {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		panic("oauth: crypto/rand: " + err.Error())
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// localPath returns next when it is a path on this site, so the login cannot
+// be used as an open redirect.
+func localPath(next, fallback string) string {
+	if !strings.HasPrefix(next, "/") || strings.HasPrefix(next, "//") || strings.HasPrefix(next, "/\\") {
+		return fallback
+	}
+	return next
+}
+
+func requestOrigin(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host
+}
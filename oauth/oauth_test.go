@@ -0,0 +1,273 @@
+package oauth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	glk "github.com/hansir-hsj/GoLiteKit"
+	"golang.org/x/oauth2"
+)
+
+// fakeIssuer is a minimal OIDC provider issuing an ID token for "user-1".
+type fakeIssuer struct {
+	*httptest.Server
+	key       *rsa.PrivateKey
+	challenge string // code_challenge of the last authorization request
+	nonce     string // ID token nonce; the test copies it from the auth URL
+}
+
+func newFakeIssuer(t *testing.T) *fakeIssuer {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := &fakeIssuer{key: key}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{
+			"issuer":                                f.URL,
+			"authorization_endpoint":                f.URL + "/authorize",
+			"token_endpoint":                        f.URL + "/token",
+			"jwks_uri":                              f.URL + "/jwks",
+			"id_token_signing_alg_values_supported": []string{"RS256"},
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{{Key: &key.PublicKey, KeyID: "k1", Algorithm: "RS256", Use: "sig"}}})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		sum := sha256.Sum256([]byte(r.FormValue("code_verifier")))
+		if r.FormValue("code") != "good-code" || base64.RawURLEncoding.EncodeToString(sum[:]) != f.challenge {
+			http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{
+			"access_token": "at",
+			"token_type":   "Bearer",
+			"id_token":     f.idToken(t),
+		})
+	})
+	f.Server = httptest.NewServer(mux)
+	t.Cleanup(f.Close)
+	return f
+}
+
+func (f *fakeIssuer) idToken(t *testing.T) string {
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.RS256, Key: f.key}, (&jose.SignerOptions{}).WithHeader("kid", "k1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims, _ := json.Marshal(map[string]any{
+		"iss":            f.URL,
+		"aud":            "client-1",
+		"sub":            "user-1",
+		"exp":            time.Now().Add(time.Hour).Unix(),
+		"iat":            time.Now().Unix(),
+		"nonce":          f.nonce,
+		"email":          "ann@example.com",
+		"email_verified": true,
+		"name":           "Ann",
+	})
+	jws, err := signer.Sign(claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, _ := jws.CompactSerialize()
+	return s
+}
+
+func oauthTestRouter(m *Manager) *glk.Router {
+	r := glk.NewRouter(nil)
+	r.Use(glk.ErrorHandlerMiddleware())
+	r.Use(glk.ContextAsMiddleware())
+	r.Use(m.SessionMiddleware())
+	m.Mount(r, "/auth")
+	r.GET("/me", glk.WithMiddleware(glk.HandlerFunc(func(ctx *glk.Context) error {
+		p := ctx.Principal()
+		return ctx.String(http.StatusOK, p.ID+" "+p.Claims["email"]+" "+strings.Join(p.Roles, ","))
+	}), RequireLogin("/auth/test/login")))
+	return r
+}
+
+func serve(r *glk.Router, method, target string, cookies ...*http.Cookie) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, nil)
+	for _, c := range cookies {
+		req.AddCookie(c)
+	}
+	rec := httptest.NewRecorder()
+	r.Handler().ServeHTTP(rec, req)
+	return rec
+}
+
+func cookie(rec *httptest.ResponseRecorder, name string) *http.Cookie {
+	for _, c := range rec.Result().Cookies() {
+		if c.Name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+// login starts the flow and returns the state cookie and the query of the
+// authorization URL.
+func login(t *testing.T, r *glk.Router, target string) (*http.Cookie, url.Values) {
+	t.Helper()
+	rec := serve(r, http.MethodGet, target)
+	if rec.Code != http.StatusFound {
+		t.Fatalf("login = %d %s", rec.Code, rec.Body)
+	}
+	loc, err := url.Parse(rec.Header().Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	state := cookie(rec, stateCookie)
+	if state == nil || !state.HttpOnly || state.Path != "/auth/" {
+		t.Fatalf("state cookie = %+v", state)
+	}
+	return state, loc.Query()
+}
+
+func TestOIDCLoginFlow(t *testing.T) {
+	issuer := newFakeIssuer(t)
+	p, err := OIDC(context.Background(), "test", issuer.URL, ProviderConfig{ClientID: "client-1", ClientSecret: "s"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := New(Options{Secret: []byte("k"), Providers: []*Provider{p}})
+	r := oauthTestRouter(m)
+
+	if rec := serve(r, http.MethodGet, "/me"); rec.Code != http.StatusFound || rec.Header().Get("Location") != "/auth/test/login?next=%2Fme" {
+		t.Fatalf("anonymous /me = %d %q", rec.Code, rec.Header().Get("Location"))
+	}
+
+	state, q := login(t, r, "/auth/test/login?next=/me")
+	if q.Get("redirect_uri") != "http://example.com/auth/test/callback" || q.Get("code_challenge_method") != "S256" || q.Get("nonce") == "" {
+		t.Fatalf("authorization request = %v", q)
+	}
+	issuer.challenge, issuer.nonce = q.Get("code_challenge"), q.Get("nonce")
+
+	if rec := serve(r, http.MethodGet, "/auth/test/callback?code=good-code&state=forged", state); rec.Code != http.StatusBadRequest {
+		t.Errorf("forged state = %d, want 400", rec.Code)
+	}
+
+	rec := serve(r, http.MethodGet, "/auth/test/callback?code=good-code&state="+q.Get("state"), state)
+	if rec.Code != http.StatusFound || rec.Header().Get("Location") != "/me" {
+		t.Fatalf("callback = %d %q %s", rec.Code, rec.Header().Get("Location"), rec.Body)
+	}
+	if c := cookie(rec, stateCookie); c == nil || c.MaxAge >= 0 {
+		t.Errorf("state cookie not cleared: %+v", c)
+	}
+	sess := cookie(rec, DefaultSessionCookie)
+	if sess == nil {
+		t.Fatal("no session cookie")
+	}
+
+	if rec := serve(r, http.MethodGet, "/me", sess); rec.Body.String() != "test:user-1 ann@example.com " {
+		t.Errorf("/me = %d %q", rec.Code, rec.Body)
+	}
+	tampered := *sess
+	tampered.Value = "x" + sess.Value[1:]
+	if rec := serve(r, http.MethodGet, "/me", &tampered); rec.Code != http.StatusFound {
+		t.Errorf("tampered session accepted: %d", rec.Code)
+	}
+
+	rec = serve(r, http.MethodPost, "/auth/logout", sess)
+	if c := cookie(rec, DefaultSessionCookie); rec.Code != http.StatusSeeOther || c == nil || c.MaxAge >= 0 {
+		t.Errorf("logout = %d %+v", rec.Code, c)
+	}
+}
+
+func TestOIDCRejectsNonceMismatch(t *testing.T) {
+	issuer := newFakeIssuer(t)
+	p, err := OIDC(context.Background(), "test", issuer.URL, ProviderConfig{ClientID: "client-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := oauthTestRouter(New(Options{Secret: []byte("k"), Providers: []*Provider{p}}))
+
+	state, q := login(t, r, "/auth/test/login")
+	issuer.challenge, issuer.nonce = q.Get("code_challenge"), "replayed"
+	rec := serve(r, http.MethodGet, "/auth/test/callback?code=good-code&state="+q.Get("state"), state)
+	if rec.Code != http.StatusUnauthorized || cookie(rec, DefaultSessionCookie) != nil {
+		t.Errorf("nonce mismatch = %d, want 401 without a session", rec.Code)
+	}
+}
+
+func TestOAuth2ProviderWithOnLogin(t *testing.T) {
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/token":
+			json.NewEncoder(w).Encode(map[string]any{"access_token": "at", "token_type": "Bearer"})
+		case "/user":
+			if r.Header.Get("Authorization") != "Bearer at" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]any{"id": 42, "login": "bob", "email": "bob@example.com"})
+		}
+	}))
+	defer api.Close()
+
+	old := GitHubUserURL
+	GitHubUserURL = api.URL + "/user"
+	defer func() { GitHubUserURL = old }()
+	p := GitHub(ProviderConfig{ClientID: "c", RedirectURL: "https://app.example.com/auth/github/callback"})
+	p.Name = "test"
+	p.Config.Endpoint = oauth2.Endpoint{AuthURL: api.URL + "/authorize", TokenURL: api.URL + "/token"}
+
+	var loggedIn *User
+	m := New(Options{Secret: []byte("k"), Providers: []*Provider{p}, OnLogin: func(ctx context.Context, u *User) (*glk.Principal, error) {
+		loggedIn = u
+		if u.Email != "bob@example.com" {
+			return nil, nil
+		}
+		principal := DefaultPrincipal(u)
+		principal.Roles = []string{"admin"}
+		return principal, nil
+	}})
+	r := oauthTestRouter(m)
+
+	state, q := login(t, r, "/auth/test/login?next=//evil.example.com")
+	if q.Get("redirect_uri") != "https://app.example.com/auth/github/callback" || q.Get("nonce") != "" {
+		t.Errorf("authorization request = %v", q)
+	}
+	rec := serve(r, http.MethodGet, "/auth/test/callback?code=c&state="+q.Get("state"), state)
+	if rec.Code != http.StatusFound || rec.Header().Get("Location") != "/" {
+		t.Fatalf("callback = %d %q (open redirect?) %s", rec.Code, rec.Header().Get("Location"), rec.Body)
+	}
+	if loggedIn == nil || loggedIn.ID != "42" || loggedIn.Name != "bob" || loggedIn.Provider != "test" {
+		t.Errorf("user = %+v", loggedIn)
+	}
+	if rec := serve(r, http.MethodGet, "/me", cookie(rec, DefaultSessionCookie)); rec.Body.String() != "test:42 bob@example.com admin" {
+		t.Errorf("/me = %q", rec.Body)
+	}
+
+	if rec := serve(r, http.MethodGet, "/auth/test/callback?error=access_denied"); rec.Code != http.StatusUnauthorized {
+		t.Errorf("denied consent = %d, want 401", rec.Code)
+	}
+	if rec := serve(r, http.MethodGet, "/auth/nope/login"); rec.Code != http.StatusNotFound {
+		t.Errorf("unknown provider = %d, want 404", rec.Code)
+	}
+}
+
+func TestStateCookieIsNotASession(t *testing.T) {
+	m := New(Options{Secret: []byte("k")})
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.AddCookie(&http.Cookie{Name: DefaultSessionCookie, Value: m.seal("state", session{Principal: glk.Principal{ID: "x"}, Expires: time.Now().Add(time.Hour).Unix()})})
+	if p := m.Session(req); p != nil {
+		t.Errorf("state-signed cookie accepted as session: %+v", p)
+	}
+}
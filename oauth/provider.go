@@ -0,0 +1,198 @@
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/github"
+)
+
+// User is the identity returned by a provider after a successful login.
+type User struct {
+	Provider      string         `json:"provider"`
+	ID            string         `json:"id"`
+	Email         string         `json:"email,omitempty"`
+	EmailVerified bool           `json:"email_verified,omitempty"`
+	Name          string         `json:"name,omitempty"`
+	AvatarURL     string         `json:"avatar_url,omitempty"`
+	Claims        map[string]any `json:"claims,omitempty"` // raw ID token or user-info claims
+}
+
+// ProviderConfig holds the client registration of a provider.
+type ProviderConfig struct {
+	ClientID     string
+	ClientSecret string
+	// RedirectURL is the callback URL registered with the provider. When
+	// empty it is derived from the request host and the Mount prefix.
+	RedirectURL string
+	// Scopes replaces the provider's default scopes.
+	Scopes []string
+}
+
+// Provider is an OAuth2 authorization server. OIDC providers verify the ID
+// token (signature, audience, expiry and nonce) and read the user from its
+// claims; plain OAuth2 providers call UserInfo with the access token.
+type Provider struct {
+	// Name identifies the provider in the login and callback paths.
+	Name   string
+	Config oauth2.Config
+	// UserInfo loads the user of a plain OAuth2 provider.
+	UserInfo func(ctx context.Context, client *http.Client) (*User, error)
+
+	oidc     *oidc.Provider
+	verifier *oidc.IDTokenVerifier
+}
+
+func (cfg ProviderConfig) oauth2Config(endpoint oauth2.Endpoint, defaultScopes ...string) oauth2.Config {
+	scopes := cfg.Scopes
+	if len(scopes) == 0 {
+		scopes = defaultScopes
+	}
+	return oauth2.Config{
+		ClientID:     cfg.ClientID,
+		ClientSecret: cfg.ClientSecret,
+		RedirectURL:  cfg.RedirectURL,
+		Endpoint:     endpoint,
+		Scopes:       scopes,
+	}
+}
+
+// OIDC returns a provider for the OpenID Connect issuer (Keycloak, Auth0,
+// Okta, ...), discovering its endpoints from the issuer's
+// /.well-known/openid-configuration.
+func OIDC(ctx context.Context, name, issuer string, cfg ProviderConfig) (*Provider, error) {
+	p, err := oidc.NewProvider(ctx, issuer)
+	if err != nil {
+		return nil, fmt.Errorf("oauth: discover %s: %w", issuer, err)
+	}
+	return &Provider{
+		Name:     name,
+		Config:   cfg.oauth2Config(p.Endpoint(), oidc.ScopeOpenID, "email", "profile"),
+		oidc:     p,
+		verifier: p.Verifier(&oidc.Config{ClientID: cfg.ClientID}),
+	}, nil
+}
+
+// Google returns the "google" OIDC provider.
+func Google(ctx context.Context, cfg ProviderConfig) (*Provider, error) {
+	return OIDC(ctx, "google", "https://accounts.google.com", cfg)
+}
+
+// GitHubUserURL is the API endpoint GitHub users are read from.
+var GitHubUserURL = "https://api.github.com/user"
+
+// GitHub returns the "github" provider. GitHub is plain OAuth2, so the user
+// is read from GitHubUserURL; Email is only set for public emails unless the
+// "user:email" scope is granted.
+func GitHub(cfg ProviderConfig) *Provider {
+	return &Provider{
+		Name:   "github",
+		Config: cfg.oauth2Config(github.Endpoint, "read:user", "user:email"),
+		UserInfo: func(ctx context.Context, client *http.Client) (*User, error) {
+			var claims map[string]any
+			if err := getJSON(ctx, client, GitHubUserURL, &claims); err != nil {
+				return nil, err
+			}
+			u := &User{
+				Provider:  "github",
+				Email:     stringClaim(claims, "email"),
+				Name:      stringClaim(claims, "name"),
+				AvatarURL: stringClaim(claims, "avatar_url"),
+				Claims:    claims,
+			}
+			if id, ok := claims["id"].(float64); ok {
+				u.ID = strconv.FormatInt(int64(id), 10)
+			}
+			if u.Name == "" {
+				u.Name = stringClaim(claims, "login")
+			}
+			return u, nil
+		},
+	}
+}
+
+// user returns the signed-in user for token.
+func (p *Provider) user(ctx context.Context, token *oauth2.Token, nonce string) (*User, error) {
+	if p.verifier == nil {
+		if p.UserInfo == nil {
+			return nil, fmt.Errorf("oauth: provider %q has no UserInfo", p.Name)
+		}
+		u, err := p.UserInfo(ctx, p.Config.Client(ctx, token))
+		if err != nil {
+			return nil, err
+		}
+		if u.ID == "" {
+			return nil, errors.New("oauth: user without an ID")
+		}
+		u.Provider = p.Name
+		return u, nil
+	}
+
+	raw, _ := token.Extra("id_token").(string)
+	if raw == "" {
+		return nil, errors.New("oauth: token response without an id_token")
+	}
+	idToken, err := p.verifier.Verify(ctx, raw)
+	if err != nil {
+		return nil, err
+	}
+	if idToken.Nonce != nonce {
+		return nil, errors.New("oauth: ID token nonce mismatch")
+	}
+	var claims map[string]any
+	if err := idToken.Claims(&claims); err != nil {
+		return nil, err
+	}
+	if stringClaim(claims, "email") == "" && p.oidc.UserInfoEndpoint() != "" {
+		info, err := p.oidc.UserInfo(ctx, oauth2.StaticTokenSource(token))
+		if err != nil {
+			return nil, err
+		}
+		var extra map[string]any
+		if err := info.Claims(&extra); err == nil {
+			for k, v := range extra {
+				if _, ok := claims[k]; !ok {
+					claims[k] = v
+				}
+			}
+		}
+	}
+	verified, _ := claims["email_verified"].(bool)
+	return &User{
+		Provider:      p.Name,
+		ID:            idToken.Subject,
+		Email:         stringClaim(claims, "email"),
+		EmailVerified: verified,
+		Name:          stringClaim(claims, "name"),
+		AvatarURL:     stringClaim(claims, "picture"),
+		Claims:        claims,
+	}, nil
+}
+
+func stringClaim(claims map[string]any, key string) string {
+	s, _ := claims[key].(string)
+	return s
+}
+
+func getJSON(ctx context.Context, client *http.Client, url string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("oauth: GET %s: %s", url, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
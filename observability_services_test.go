@@ -101,12 +101,12 @@ func TestDefaultMiddlewaresIncludesObservabilityFirst(t *testing.T) {
 	services := &Services{}
 	WithObservabilityMiddleware(observerMiddleware)(services)
 
-	middlewares := defaultMiddlewares(services, defaultMiddlewareOptions{})
+	middlewares := defaultMiddlewares(services, DefaultMiddlewareOptions{})
 
 	if len(middlewares) != 6 {
 		t.Fatalf("default middleware count = %d, want 6", len(middlewares))
 	}
-	if reflect.ValueOf(middlewares[0]).Pointer() != reflect.ValueOf(observerMiddleware).Pointer() {
+	if middlewares[0].Name != MiddlewareObservability || reflect.ValueOf(middlewares[0].Middleware).Pointer() != reflect.ValueOf(observerMiddleware).Pointer() {
 		t.Fatal("expected observability middleware to be first")
 	}
 }
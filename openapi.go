@@ -0,0 +1,751 @@
+package golitekit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hansir-hsj/GoLiteKit/env"
+)
+
+const (
+	DefaultDocsPath = "/docs"
+	OpenAPIVersion  = "3.0.3"
+)
+
+// OpenAPIInfo is the info object of a generated specification.
+type OpenAPIInfo struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+// OpenAPISpec is an OpenAPI 3 document. Paths map a path to its operations
+// keyed by lower-case method.
+type OpenAPISpec struct {
+	OpenAPI    string                                  `json:"openapi"`
+	Info       OpenAPIInfo                             `json:"info"`
+	Servers    []OpenAPIServer                         `json:"servers,omitempty"`
+	Paths      map[string]map[string]*OpenAPIOperation `json:"paths"`
+	Components OpenAPIComponents                       `json:"components"`
+}
+
+type OpenAPIServer struct {
+	URL string `json:"url"`
+}
+
+type OpenAPIComponents struct {
+	Schemas map[string]*OpenAPISchema `json:"schemas,omitempty"`
+}
+
+type OpenAPIOperation struct {
+	OperationID string                      `json:"operationId"`
+	Summary     string                      `json:"summary,omitempty"`
+	Description string                      `json:"description,omitempty"`
+	Tags        []string                    `json:"tags,omitempty"`
+	Deprecated  bool                        `json:"deprecated,omitempty"`
+	Parameters  []OpenAPIParameter          `json:"parameters,omitempty"`
+	RequestBody *OpenAPIRequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*OpenAPIResponse `json:"responses"`
+}
+
+type OpenAPIParameter struct {
+	Name     string         `json:"name"`
+	In       string         `json:"in"`
+	Required bool           `json:"required"`
+	Schema   *OpenAPISchema `json:"schema"`
+}
+
+type OpenAPIRequestBody struct {
+	Required bool                        `json:"required"`
+	Content  map[string]OpenAPIMediaType `json:"content"`
+}
+
+type OpenAPIResponse struct {
+	Description string                      `json:"description"`
+	Content     map[string]OpenAPIMediaType `json:"content,omitempty"`
+}
+
+type OpenAPIMediaType struct {
+	Schema  *OpenAPISchema `json:"schema,omitempty"`
+	Example any            `json:"example,omitempty"`
+}
+
+// OpenAPISchema is the subset of the schema object derived from Go types
+// and their json, validate, description and example tags.
+type OpenAPISchema struct {
+	Ref                  string                    `json:"$ref,omitempty"`
+	Type                 string                    `json:"type,omitempty"`
+	Format               string                    `json:"format,omitempty"`
+	Description          string                    `json:"description,omitempty"`
+	Example              any                       `json:"example,omitempty"`
+	Enum                 []any                     `json:"enum,omitempty"`
+	Nullable             bool                      `json:"nullable,omitempty"`
+	Minimum              *float64                  `json:"minimum,omitempty"`
+	Maximum              *float64                  `json:"maximum,omitempty"`
+	MinLength            *int                      `json:"minLength,omitempty"`
+	MaxLength            *int                      `json:"maxLength,omitempty"`
+	MinItems             *int                      `json:"minItems,omitempty"`
+	MaxItems             *int                      `json:"maxItems,omitempty"`
+	Items                *OpenAPISchema            `json:"items,omitempty"`
+	Properties           map[string]*OpenAPISchema `json:"properties,omitempty"`
+	Required             []string                  `json:"required,omitempty"`
+	AdditionalProperties *OpenAPISchema            `json:"additionalProperties,omitempty"`
+}
+
+// ResponseProvider is implemented by controllers that document their
+// responses. Keys are status codes ("200", "404"); values are:
+//   - a value of the body type, used for the schema and, unless zero, as the
+//     example; 2xx bodies of RestControllerOf controllers are wrapped in the
+//     Response envelope
+//   - a string, describing an error answered with the Response envelope
+//     (the shape the default error formatter writes for an AppError)
+//   - nil, for a response without a body
+//
+// Controllers with a request body also get a 400 documenting the
+// ValidationErrors their validate tags can report, unless they declare one.
+// Controllers without ResponseProvider are documented from their
+// ExampleProvider examples, which are complete bodies.
+type ResponseProvider interface {
+	Responses() map[string]any
+}
+
+// OperationDoc describes an operation in the specification.
+type OperationDoc struct {
+	Summary     string
+	Description string
+	Tags        []string // defaults to the first static path segment
+	Deprecated  bool
+}
+
+// DocProvider is implemented by controllers that describe their operation.
+type DocProvider interface {
+	Doc() OperationDoc
+}
+
+// OpenAPI builds a specification of the registered controller and handler
+// routes. Request schemas come from the Request field of BaseControllerOf[T],
+// response schemas from ResponseProvider or ExampleProvider; struct fields
+// are documented with their `description:"..."` and `example:"..."` tags,
+// and `validate` tags add required, enum and length/range constraints.
+func (r *Router) OpenAPI(info OpenAPIInfo, exclude ...string) *OpenAPISpec {
+	if info.Title == "" {
+		info.Title = env.AppName()
+	}
+	if info.Title == "" {
+		info.Title = "API"
+	}
+	if info.Version == "" {
+		info.Version = "0.0.0"
+	}
+	spec := &OpenAPISpec{
+		OpenAPI: OpenAPIVersion,
+		Info:    info,
+		Paths:   map[string]map[string]*OpenAPIOperation{},
+	}
+	gen := &schemaGenerator{schemas: map[string]*OpenAPISchema{}, names: map[reflect.Type]string{}}
+	ids := map[string]int{}
+
+	for _, route := range r.Routes() {
+		if route.Method == "" || excludedRoute(route.Pattern, exclude) {
+			continue
+		}
+		path, params := openAPIPath(route.Pattern)
+		op := &OpenAPIOperation{
+			OperationID: operationID(route.Method, path, ids),
+			Parameters:  params,
+			Responses:   map[string]*OpenAPIResponse{},
+		}
+		if seg := firstStaticSegment(path); seg != "" {
+			op.Tags = []string{seg}
+		}
+		if c := route.controller; c != nil {
+			describeController(gen, op, c)
+		}
+		if len(op.Responses) == 0 {
+			op.Responses["default"] = &OpenAPIResponse{Description: "Response"}
+		}
+		if spec.Paths[path] == nil {
+			spec.Paths[path] = map[string]*OpenAPIOperation{}
+		}
+		spec.Paths[path][strings.ToLower(route.Method)] = op
+	}
+	spec.Components.Schemas = gen.schemas
+	return spec
+}
+
+func describeController(gen *schemaGenerator, op *OpenAPIOperation, c Controller) {
+	if d, ok := c.(DocProvider); ok {
+		doc := d.Doc()
+		op.Summary, op.Description, op.Deprecated = doc.Summary, doc.Description, doc.Deprecated
+		if len(doc.Tags) > 0 {
+			op.Tags = doc.Tags
+		}
+	}
+	if op.Summary == "" {
+		op.Summary = reflect.Indirect(reflect.ValueOf(c)).Type().Name()
+	}
+
+	if t := requestType(c); t != nil {
+		schema := gen.schema(t)
+		op.RequestBody = &OpenAPIRequestBody{
+			Required: true,
+			Content: map[string]OpenAPIMediaType{
+				"application/json":                  {Schema: schema},
+				"application/x-www-form-urlencoded": {Schema: schema},
+			},
+		}
+		op.Responses["400"] = gen.badRequest(t)
+	}
+
+	bodies, examples := map[string]any(nil), false
+	if p, ok := c.(ResponseProvider); ok {
+		bodies = p.Responses()
+	} else if p, ok := c.(ExampleProvider); ok {
+		bodies, examples = p.Examples(), true
+	}
+	_, rest := c.(interface {
+		ServeData(context.Context, any) error
+	})
+	keys := make([]string, 0, len(bodies))
+	for key := range bodies {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys) // "200" sorts before its named variants "200:..."
+	documented := map[string]bool{}
+	for _, key := range keys {
+		status, _, _ := strings.Cut(key, ":")
+		if documented[status] {
+			continue
+		}
+		documented[status] = true
+		if examples {
+			op.Responses[status] = gen.exampleResponse(status, bodies[key])
+		} else {
+			op.Responses[status] = gen.response(status, bodies[key], rest)
+		}
+	}
+}
+
+// exampleResponse documents an ExampleProvider body, served verbatim.
+func (g *schemaGenerator) exampleResponse(status string, body any) *OpenAPIResponse {
+	media := OpenAPIMediaType{Example: body}
+	if body != nil {
+		media.Schema = g.schema(reflect.TypeOf(body))
+	}
+	return &OpenAPIResponse{
+		Description: statusDescription(status),
+		Content:     map[string]OpenAPIMediaType{"application/json": media},
+	}
+}
+
+// response documents a ResponseProvider entry: nil has no body, a string is
+// the description of an error envelope, anything else is the body type,
+// wrapped in the envelope for 2xx responses of REST controllers.
+func (g *schemaGenerator) response(status string, body any, rest bool) *OpenAPIResponse {
+	switch v := body.(type) {
+	case nil:
+		return &OpenAPIResponse{Description: statusDescription(status)}
+	case string:
+		return &OpenAPIResponse{Description: v, Content: map[string]OpenAPIMediaType{
+			"application/json": {Schema: g.schema(reflect.TypeOf(Response{}))},
+		}}
+	}
+	media := OpenAPIMediaType{Schema: g.schema(reflect.TypeOf(body))}
+	if !reflect.ValueOf(body).IsZero() {
+		media.Example = body
+	}
+	if rest && strings.HasPrefix(status, "2") {
+		media.Schema = &OpenAPISchema{Type: "object", Properties: map[string]*OpenAPISchema{
+			"status": {Type: "integer"},
+			"msg":    {Type: "string"},
+			"data":   media.Schema,
+			"logid":  {Type: "string"},
+		}, Required: []string{"status", "msg"}}
+		if media.Example != nil {
+			media.Example = Response{Status: OK, Msg: "OK", Data: media.Example}
+		}
+	}
+	return &OpenAPIResponse{
+		Description: statusDescription(status),
+		Content:     map[string]OpenAPIMediaType{"application/json": media},
+	}
+}
+
+// badRequest documents the 400 the router answers with when ParseRequest or
+// Validate fails: the error envelope carrying ValidationErrors, with one
+// example failure per validate rule of the request type.
+func (g *schemaGenerator) badRequest(t reflect.Type) *OpenAPIResponse {
+	const name = "ValidationErrorResponse"
+	if g.schemas[name] == nil {
+		fieldError := g.schema(reflect.TypeOf(FieldError{}))
+		g.schemas[name] = &OpenAPISchema{
+			Type: "object",
+			Properties: map[string]*OpenAPISchema{
+				"status": {Type: "integer", Example: http.StatusBadRequest},
+				"msg":    {Type: "string"},
+				"data":   {Type: "array", Items: fieldError, Description: "Failed validation rules; empty when the body could not be parsed"},
+				"logid":  {Type: "string"},
+			},
+			Required: []string{"status", "msg"},
+		}
+	}
+	media := OpenAPIMediaType{Schema: &OpenAPISchema{Ref: "#/components/schemas/" + name}}
+	if failures := validationFailures(t, "", map[reflect.Type]bool{}); len(failures) > 0 {
+		media.Example = Response{Status: http.StatusBadRequest, Msg: failures.Error(), Data: failures}
+	}
+	return &OpenAPIResponse{
+		Description: "Invalid request body or failed validation",
+		Content:     map[string]OpenAPIMediaType{"application/json": media},
+	}
+}
+
+// validationFailures lists the FieldError each validate rule of t reports,
+// following the field paths and default messages of ValidateStruct.
+func validationFailures(t reflect.Type, prefix string, seen map[reflect.Type]bool) ValidationErrors {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || t == timeType || seen[t] {
+		return nil
+	}
+	seen[t] = true
+	defer delete(seen, t)
+
+	catalog := DefaultCatalog()
+	var errs ValidationErrors
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		name := fieldName(sf)
+		if !sf.IsExported() || name == "-" {
+			continue
+		}
+		path := prefix + name
+		if sf.Anonymous {
+			path = strings.TrimSuffix(prefix, ".")
+		}
+		if tag := sf.Tag.Get("validate"); tag != "" && tag != "-" {
+			label := catalog.Translate(nil, "field."+path, nil)
+			if label == "field."+path {
+				label = path
+			}
+			for _, rule := range strings.Split(tag, ",") {
+				rule, param, _ := strings.Cut(strings.TrimSpace(rule), "=")
+				if rule == "" {
+					continue
+				}
+				errs = append(errs, FieldError{
+					Field:   path,
+					Rule:    rule,
+					Param:   param,
+					Message: catalog.Translate(nil, "validation."+rule, map[string]string{"field": label, "param": param}),
+				})
+			}
+		}
+		next := path + "."
+		if path == "" {
+			next = ""
+		}
+		errs = append(errs, validationFailures(sf.Type, next, seen)...)
+	}
+	return errs
+}
+
+// requestType returns T of an embedded BaseControllerOf[T], nil for NoBody.
+func requestType(c Controller) reflect.Type {
+	t := reflect.TypeOf(c)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	f, ok := t.FieldByName("Request")
+	if !ok || f.Type == reflect.TypeOf(NoBody{}) {
+		return nil
+	}
+	return f.Type
+}
+
+var patternParam = regexp.MustCompile(`\{([^}]*)\}`)
+
+// openAPIPath converts a ServeMux pattern: the host is dropped, {$} removed
+// and {name...} becomes {name}.
+func openAPIPath(pattern string) (string, []OpenAPIParameter) {
+	if i := strings.Index(pattern, "/"); i > 0 {
+		pattern = pattern[i:]
+	}
+	pattern = strings.ReplaceAll(pattern, "{$}", "")
+	var params []OpenAPIParameter
+	path := patternParam.ReplaceAllStringFunc(pattern, func(m string) string {
+		name := strings.TrimSuffix(m[1:len(m)-1], "...")
+		params = append(params, OpenAPIParameter{Name: name, In: "path", Required: true, Schema: &OpenAPISchema{Type: "string"}})
+		return "{" + name + "}"
+	})
+	return path, params
+}
+
+func excludedRoute(pattern string, exclude []string) bool {
+	for _, prefix := range exclude {
+		if prefix != "" && strings.HasPrefix(pattern, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func firstStaticSegment(path string) string {
+	for _, seg := range strings.Split(path, "/") {
+		if seg != "" && !strings.HasPrefix(seg, "{") {
+			return seg
+		}
+	}
+	return ""
+}
+
+// operationID names an operation after its method and static path segments
+// (GET /users/{id}/posts → getUsersPosts), numbering duplicates.
+func operationID(method, path string, seen map[string]int) string {
+	var b strings.Builder
+	b.WriteString(strings.ToLower(method))
+	for _, seg := range strings.FieldsFunc(path, func(r rune) bool { return r == '/' || r == '-' || r == '_' || r == '.' }) {
+		if strings.HasPrefix(seg, "{") {
+			continue
+		}
+		b.WriteString(strings.ToUpper(seg[:1]) + seg[1:])
+	}
+	id := b.String()
+	seen[id]++
+	if n := seen[id]; n > 1 {
+		id += strconv.Itoa(n)
+	}
+	return id
+}
+
+func statusDescription(status string) string {
+	if n, err := strconv.Atoi(status); err == nil && http.StatusText(n) != "" {
+		return http.StatusText(n)
+	}
+	return "Response"
+}
+
+// schemaGenerator turns Go types into schemas; named structs are stored once
+// in components and referenced.
+type schemaGenerator struct {
+	schemas map[string]*OpenAPISchema
+	names   map[reflect.Type]string
+}
+
+var (
+	timeType        = reflect.TypeOf(time.Time{})
+	rawMessageType  = reflect.TypeOf(json.RawMessage{})
+	genericArgs     = regexp.MustCompile(`[\w./-]*\.`)
+	nonIdentifierRe = regexp.MustCompile(`[^A-Za-z0-9_]+`)
+)
+
+func (g *schemaGenerator) schema(t reflect.Type) *OpenAPISchema {
+	nullable := false
+	for t.Kind() == reflect.Pointer {
+		t, nullable = t.Elem(), true
+	}
+	var s *OpenAPISchema
+	switch {
+	case t == timeType:
+		s = &OpenAPISchema{Type: "string", Format: "date-time"}
+	case t == rawMessageType:
+		s = &OpenAPISchema{}
+	case t.Kind() == reflect.Struct && t.Name() != "":
+		s = &OpenAPISchema{Ref: "#/components/schemas/" + g.component(t)}
+		if nullable {
+			// $ref siblings are ignored by OpenAPI 3.0 tools; keep the reference.
+			return s
+		}
+	case t.Kind() == reflect.Struct:
+		s = g.object(t)
+	default:
+		s = g.basic(t)
+	}
+	s.Nullable = s.Nullable || nullable && s.Ref == ""
+	return s
+}
+
+func (g *schemaGenerator) basic(t reflect.Type) *OpenAPISchema {
+	switch t.Kind() {
+	case reflect.Bool:
+		return &OpenAPISchema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &OpenAPISchema{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return &OpenAPISchema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &OpenAPISchema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &OpenAPISchema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &OpenAPISchema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &OpenAPISchema{Type: "string", Format: "byte"}
+		}
+		return &OpenAPISchema{Type: "array", Items: g.schema(t.Elem())}
+	case reflect.Map:
+		return &OpenAPISchema{Type: "object", AdditionalProperties: g.schema(t.Elem())}
+	}
+	return &OpenAPISchema{} // interfaces and anything else: any value
+}
+
+// component registers a named struct and returns its component name.
+func (g *schemaGenerator) component(t reflect.Type) string {
+	if name, ok := g.names[t]; ok {
+		return name
+	}
+	base := strings.Trim(nonIdentifierRe.ReplaceAllString(genericArgs.ReplaceAllString(t.Name(), ""), "_"), "_")
+	name := base
+	for i := 2; g.schemas[name] != nil; i++ {
+		name = base + strconv.Itoa(i)
+	}
+	g.names[t] = name
+	g.schemas[name] = &OpenAPISchema{} // placeholder for recursive types
+	*g.schemas[name] = *g.object(t)
+	return name
+}
+
+func (g *schemaGenerator) object(t reflect.Type) *OpenAPISchema {
+	s := &OpenAPISchema{Type: "object", Properties: map[string]*OpenAPISchema{}}
+	g.fields(t, s)
+	return s
+}
+
+func (g *schemaGenerator) fields(t reflect.Type, s *OpenAPISchema) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, skip := jsonFieldName(f)
+		if skip {
+			continue
+		}
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			for ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				g.fields(ft, s)
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		prop := g.schema(f.Type)
+		if prop.Ref != "" && (f.Tag.Get("description") != "" || f.Tag.Get("example") != "") {
+			prop = &OpenAPISchema{Ref: prop.Ref} // annotations need a copy, not the component
+		}
+		if prop.Ref == "" {
+			prop.Description = f.Tag.Get("description")
+			if ex, ok := f.Tag.Lookup("example"); ok {
+				prop.Example = exampleValue(ex, f.Type)
+			}
+		}
+		required := applyValidateTag(prop, f.Tag.Get("validate"))
+		if required {
+			s.Required = append(s.Required, name)
+		}
+		s.Properties[name] = prop
+	}
+}
+
+// jsonFieldName returns the json name of f ("" when untagged).
+func jsonFieldName(f reflect.StructField) (name string, skip bool) {
+	tag := f.Tag.Get("json")
+	if tag == "-" {
+		return "", true
+	}
+	name, _, _ = strings.Cut(tag, ",")
+	return name, false
+}
+
+// exampleValue parses an example tag for the field kind, falling back to the
+// raw string.
+func exampleValue(s string, t reflect.Type) any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		if b, err := strconv.ParseBool(s); err == nil {
+			return b
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return n
+		}
+	case reflect.Float32, reflect.Float64:
+		if f, err := strconv.ParseFloat(s, 64); err == nil {
+			return f
+		}
+	case reflect.Slice, reflect.Map, reflect.Struct:
+		var v any
+		if json.Unmarshal([]byte(s), &v) == nil {
+			return v
+		}
+	}
+	return s
+}
+
+// applyValidateTag maps the rules ValidateStruct supports onto s and reports
+// "required". min, max and len bound numbers by value, strings by length and
+// arrays by item count.
+func applyValidateTag(s *OpenAPISchema, tag string) (required bool) {
+	for _, rule := range strings.Split(tag, ",") {
+		name, arg, _ := strings.Cut(strings.TrimSpace(rule), "=")
+		switch name {
+		case "required":
+			required = true
+		case "email":
+			s.Format = "email"
+		case "oneof":
+			for _, v := range strings.Fields(arg) {
+				s.Enum = append(s.Enum, exampleValueForType(v, s.Type))
+			}
+		case "min", "max", "len":
+			n, err := strconv.ParseFloat(arg, 64)
+			if err != nil {
+				continue
+			}
+			lower, upper := name != "max", name != "min"
+			switch s.Type {
+			case "string":
+				if lower {
+					s.MinLength = ptrTo(int(n))
+				}
+				if upper {
+					s.MaxLength = ptrTo(int(n))
+				}
+			case "array":
+				if lower {
+					s.MinItems = ptrTo(int(n))
+				}
+				if upper {
+					s.MaxItems = ptrTo(int(n))
+				}
+			case "integer", "number":
+				if lower {
+					s.Minimum = ptrTo(n)
+				}
+				if upper {
+					s.Maximum = ptrTo(n)
+				}
+			}
+		}
+	}
+	return required
+}
+
+func exampleValueForType(v, typ string) any {
+	switch typ {
+	case "integer":
+		if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return n
+		}
+	case "number":
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			return f
+		}
+	}
+	return v
+}
+
+func ptrTo[T any](v T) *T { return &v }
+
+// DocsOptions configures MountDocs.
+type DocsOptions struct {
+	Path    string // URL prefix, defaults to DefaultDocsPath
+	Info    OpenAPIInfo
+	Exclude []string // route pattern prefixes left out, e.g. DefaultAdminPrefix
+	// Always mounts the docs whatever the run mode; by default they are only
+	// mounted when env.RunMode is "debug", "dev", "development" or "test".
+	Always bool
+}
+
+// MountDocs serves the OpenAPI specification of the registered routes at
+// {path}/openapi.json and Swagger UI at {path}. The specification is built
+// on each request, so routes registered after MountDocs are included. It
+// returns false when the docs were not mounted because of the run mode.
+func (r *Router) MountDocs(opts ...DocsOptions) bool {
+	var opt DocsOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if !opt.Always && !devRunMode(env.RunMode()) {
+		return false
+	}
+	path := opt.Path
+	if path == "" {
+		path = DefaultDocsPath
+	}
+	path = "/" + strings.Trim(path, "/")
+	exclude := append([]string{path}, opt.Exclude...)
+
+	r.GET(path+"/openapi.json", HandlerFunc(func(ctx *Context) error {
+		return ctx.JSON(http.StatusOK, r.OpenAPI(opt.Info, exclude...))
+	}))
+	r.GET(path+"/{$}", HandlerFunc(func(ctx *Context) error {
+		return swaggerUI(ctx, path+"/openapi.json", opt.Info.Title)
+	}))
+	r.GET(path, HandlerFunc(func(ctx *Context) error {
+		http.Redirect(ctx.ResponseWriter(), ctx.Request(), path+"/", http.StatusMovedPermanently)
+		return nil
+	}))
+	return true
+}
+
+// devRunMode reports whether mode is a development run mode; an unset mode
+// counts as production.
+func devRunMode(mode string) bool {
+	switch strings.ToLower(mode) {
+	case "debug", "dev", "development", "test":
+		return true
+	}
+	return false
+}
+
+var swaggerUITemplate = template.Must(template.New("swagger").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <title>{{.Title}}</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js"></script>
+  <script>
+    window.ui = SwaggerUIBundle({ url: {{.SpecURL}}, dom_id: "#swagger-ui" });
+  </script>
+</body>
+</html>
+`))
+
+func swaggerUI(ctx *Context, specURL, title string) error {
+	if title == "" {
+		title = "API docs"
+	}
+	var b strings.Builder
+	if err := swaggerUITemplate.Execute(&b, map[string]string{"Title": title, "SpecURL": specURL}); err != nil {
+		return fmt.Errorf("render swagger ui: %w", err)
+	}
+	return ctx.HTML(http.StatusOK, b.String())
+}
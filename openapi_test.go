@@ -0,0 +1,207 @@
+package golitekit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type openAPIAddress struct {
+	City string `json:"city" description:"City name" example:"Paris"`
+}
+
+type openAPIUser struct {
+	ID        int64           `json:"id" example:"7"`
+	Name      string          `json:"name" validate:"required,min=2,max=32" description:"Display name"`
+	Role      string          `json:"role,omitempty" validate:"oneof=admin member"`
+	Email     string          `json:"email" validate:"required,email"`
+	Address   *openAPIAddress `json:"address,omitempty"`
+	Tags      []string        `json:"tags"`
+	CreatedAt time.Time       `json:"created_at"`
+	Secret    string          `json:"-"`
+}
+
+type createUserController struct {
+	RestControllerOf[openAPIUser]
+}
+
+func (c *createUserController) Serve(ctx context.Context) error {
+	return c.ServeData(ctx, c.Request)
+}
+
+func (c *createUserController) Responses() map[string]any {
+	return map[string]any{"200": openAPIUser{}}
+}
+
+func (c *createUserController) Doc() OperationDoc {
+	return OperationDoc{Summary: "Create a user", Tags: []string{"users"}}
+}
+
+func TestRouter_OpenAPIFromControllers(t *testing.T) {
+	served := false
+	r := newTestRouter()
+	r.POST("/users", &createUserController{})
+	r.GET("/users/{id}", &exampleUserController{served: &served})
+	r.GET("/files/{path...}", HandlerFunc(func(ctx *Context) error { return nil }))
+
+	spec := r.OpenAPI(OpenAPIInfo{Title: "demo", Version: "1.0"})
+	if spec.OpenAPI != OpenAPIVersion || spec.Info.Title != "demo" {
+		t.Fatalf("spec header = %+v", spec)
+	}
+
+	create := spec.Paths["/users"]["post"]
+	if create == nil || create.Summary != "Create a user" || create.Tags[0] != "users" || create.OperationID != "postUsers" {
+		t.Fatalf("post /users = %+v", create)
+	}
+	body := create.RequestBody.Content["application/json"].Schema
+	if body.Ref != "#/components/schemas/openAPIUser" {
+		t.Fatalf("request schema = %+v", body)
+	}
+	user := spec.Components.Schemas["openAPIUser"]
+	if strings.Join(user.Required, ",") != "name,email" {
+		t.Errorf("required = %v", user.Required)
+	}
+	name := user.Properties["name"]
+	if name.Description != "Display name" || *name.MinLength != 2 || *name.MaxLength != 32 {
+		t.Errorf("name = %+v", name)
+	}
+	if user.Properties["id"].Example != int64(7) || user.Properties["email"].Format != "email" {
+		t.Errorf("id/email = %+v %+v", user.Properties["id"], user.Properties["email"])
+	}
+	if e := user.Properties["role"].Enum; len(e) != 2 || e[0] != "admin" {
+		t.Errorf("role enum = %v", e)
+	}
+	if user.Properties["created_at"].Format != "date-time" || user.Properties["tags"].Items.Type != "string" {
+		t.Errorf("created_at/tags = %+v %+v", user.Properties["created_at"], user.Properties["tags"])
+	}
+	if _, ok := user.Properties["Secret"]; ok {
+		t.Errorf(`json:"-" field documented`)
+	}
+	if city := spec.Components.Schemas["openAPIAddress"].Properties["city"]; city.Example != "Paris" {
+		t.Errorf("city = %+v", city)
+	}
+	envelope := create.Responses["200"].Content["application/json"].Schema
+	if envelope.Properties["data"].Ref != "#/components/schemas/openAPIUser" || envelope.Properties["status"].Type != "integer" {
+		t.Errorf("rest response not wrapped in the envelope: %+v", envelope)
+	}
+	if create.Responses["400"] == nil {
+		t.Errorf("request body errors not documented")
+	}
+
+	get := spec.Paths["/users/{id}"]["get"]
+	if get == nil || get.RequestBody != nil || len(get.Parameters) != 1 || get.Parameters[0].Name != "id" {
+		t.Fatalf("get /users/{id} = %+v", get)
+	}
+	ok := get.Responses["200"].Content["application/json"]
+	if ok.Schema.Type != "object" || ok.Example.(map[string]any)["name"] != "alice" {
+		t.Errorf("example response = %+v", ok)
+	}
+	if get.Responses["404"] == nil {
+		t.Errorf("404 example not documented")
+	}
+	if files := spec.Paths["/files/{path}"]["get"]; files == nil || files.Parameters[0].Name != "path" {
+		t.Errorf("wildcard path = %+v", spec.Paths)
+	}
+}
+
+func TestRouter_MountDocs(t *testing.T) {
+	r := newTestRouter()
+	r.POST("/users", &createUserController{})
+	if r.MountDocs(DocsOptions{Info: OpenAPIInfo{Title: "demo"}}) {
+		t.Fatal("docs mounted with an unset run mode")
+	}
+	if !r.MountDocs(DocsOptions{Info: OpenAPIInfo{Title: "demo"}, Always: true}) {
+		t.Fatal("docs not mounted with Always")
+	}
+
+	rec := httptest.NewRecorder()
+	r.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/docs/openapi.json", nil))
+	var spec OpenAPISpec
+	if err := json.Unmarshal(rec.Body.Bytes(), &spec); err != nil {
+		t.Fatalf("decode spec: %v (%s)", err, rec.Body)
+	}
+	if spec.Paths["/users"] == nil || len(spec.Paths) != 1 {
+		t.Errorf("paths = %v, want only /users", spec.Paths)
+	}
+
+	rec = httptest.NewRecorder()
+	r.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/docs/", nil))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "SwaggerUIBundle") || !strings.Contains(rec.Body.String(), "/docs/openapi.json") {
+		t.Errorf("swagger ui = %d %s", rec.Code, rec.Body)
+	}
+	rec = httptest.NewRecorder()
+	r.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/docs", nil))
+	if rec.Code != http.StatusMovedPermanently {
+		t.Errorf("/docs = %d, want a redirect", rec.Code)
+	}
+}
+
+func TestDevRunMode(t *testing.T) {
+	for mode, want := range map[string]bool{"": false, "debug": true, "Dev": true, "test": true, "prod": false, "release": false} {
+		if got := devRunMode(mode); got != want {
+			t.Errorf("devRunMode(%q) = %v, want %v", mode, got, want)
+		}
+	}
+}
+
+type openAPIOrder struct {
+	Items []string `json:"items" validate:"min=1,max=10"`
+	Buyer struct {
+		Email string `json:"email" validate:"required,email"`
+	} `json:"buyer"`
+}
+
+type createOrderController struct {
+	RestControllerOf[openAPIOrder]
+}
+
+func (c *createOrderController) Serve(ctx context.Context) error { return c.ServeOK(ctx) }
+
+func (c *createOrderController) Responses() map[string]any {
+	return map[string]any{"200": nil, "409": "order already exists"}
+}
+
+func TestRouter_OpenAPIErrorResponses(t *testing.T) {
+	r := newTestRouter()
+	r.POST("/orders", &createOrderController{})
+	spec := r.OpenAPI(OpenAPIInfo{})
+	op := spec.Paths["/orders"]["post"]
+
+	if items := spec.Components.Schemas["openAPIOrder"].Properties["items"]; *items.MinItems != 1 || *items.MaxItems != 10 {
+		t.Errorf("items = %+v", items)
+	}
+	if ok := op.Responses["200"]; ok.Content != nil {
+		t.Errorf("nil response documented with a body: %+v", ok)
+	}
+	conflict := op.Responses["409"]
+	if conflict.Description != "order already exists" || conflict.Content["application/json"].Schema.Ref != "#/components/schemas/Response" {
+		t.Errorf("409 = %+v", conflict)
+	}
+
+	bad := op.Responses["400"].Content["application/json"]
+	if bad.Schema.Ref != "#/components/schemas/ValidationErrorResponse" {
+		t.Fatalf("400 schema = %+v", bad.Schema)
+	}
+	if data := spec.Components.Schemas["ValidationErrorResponse"].Properties["data"]; data.Items.Ref != "#/components/schemas/FieldError" {
+		t.Errorf("validation data = %+v", data)
+	}
+	example := bad.Example.(Response)
+	failures := example.Data.(ValidationErrors)
+	var got []string
+	for _, f := range failures {
+		got = append(got, f.Field+":"+f.Rule)
+		if f.Message == "" || strings.HasPrefix(f.Message, "validation.") {
+			t.Errorf("untranslated message %+v", f)
+		}
+	}
+	if strings.Join(got, ",") != "items:min,items:max,buyer.email:required,buyer.email:email" {
+		t.Errorf("documented failures = %v", got)
+	}
+	if example.Status != http.StatusBadRequest {
+		t.Errorf("example status = %d", example.Status)
+	}
+}
@@ -1,12 +1,15 @@
 package otel
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
 	glk "github.com/hansir-hsj/GoLiteKit"
 	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 )
@@ -82,3 +85,52 @@ func TestAppObservabilityRecordsFourxxAsErrorWhenEnabled(t *testing.T) {
 		t.Fatalf("4xx span status = %v, want error when enabled", spans[0].Status.Code)
 	}
 }
+
+func TestAppObservabilityTagsTenant(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	reader := sdkmetric.NewManualReader()
+	app := glk.NewApp(WithObservability(
+		WithTracerProvider(provider),
+		WithMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))),
+		WithRequestLabels("tenant"),
+	))
+	app.Use(glk.TenantMiddleware(glk.TenantFromHeader("")))
+	app.GET("/orders", glk.HandlerFunc(func(ctx *glk.Context) error {
+		_, span := glk.StartSpan(ctx.Context(), "orders.load")
+		span.End()
+		return nil
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/orders", nil)
+	req.Header.Set(glk.DefaultTenantHeader, "acme")
+	app.Handler().ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := exporter.GetSpans()
+	if len(spans) != 2 {
+		t.Fatalf("spans = %d, want 2", len(spans))
+	}
+	for _, span := range spans {
+		found := false
+		for _, attr := range span.Attributes {
+			if string(attr.Key) == TenantAttribute && attr.Value.AsString() == "acme" {
+				found = true
+			}
+		}
+		if !found {
+			t.Fatalf("span %s lacks %s=acme: %v", span.Name, TenantAttribute, span.Attributes)
+		}
+	}
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatalf("collect metrics: %v", err)
+	}
+	sets := metricAttributeSets(t, rm, "glk.http.server.requests")
+	if len(sets) != 1 {
+		t.Fatalf("got %d attribute sets, want 1", len(sets))
+	}
+	if v, _ := sets[0].Value("tenant"); v.AsString() != "acme" {
+		t.Fatalf("tenant label = %q, want acme", v.AsString())
+	}
+}
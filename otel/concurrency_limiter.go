@@ -0,0 +1,62 @@
+package otel
+
+import (
+	"context"
+
+	glk "github.com/hansir-hsj/GoLiteKit"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// RegisterConcurrencyLimiterMetrics reports each limiter under its map key:
+//
+//	glk.concurrency.in_flight  gauge of requests holding a slot
+//	glk.concurrency.queued     gauge of requests waiting for a slot
+//	glk.concurrency.limit      gauge of slots
+//	glk.concurrency.requests   counter by limiter and result (admitted,
+//	                           waited, rejected, timed_out); waited counts
+//	                           admitted requests that queued first
+func RegisterConcurrencyLimiterMetrics(provider metric.MeterProvider, limiters map[string]*glk.ConcurrencyLimiter, opts ...Option) error {
+	options := applyOptions(opts)
+	meter := provider.Meter(options.ServiceName)
+
+	inFlight, err := meter.Int64ObservableGauge("glk.concurrency.in_flight")
+	if err != nil {
+		return err
+	}
+	queued, err := meter.Int64ObservableGauge("glk.concurrency.queued")
+	if err != nil {
+		return err
+	}
+	limit, err := meter.Int64ObservableGauge("glk.concurrency.limit")
+	if err != nil {
+		return err
+	}
+	requests, err := meter.Int64ObservableCounter("glk.concurrency.requests")
+	if err != nil {
+		return err
+	}
+
+	_, err = meter.RegisterCallback(func(ctx context.Context, o metric.Observer) error {
+		for name, limiter := range limiters {
+			stats := limiter.Stats()
+			limiterAttr := attribute.String("limiter", name)
+			o.ObserveInt64(inFlight, int64(stats.InFlight), metric.WithAttributes(limiterAttr))
+			o.ObserveInt64(queued, int64(stats.Queued), metric.WithAttributes(limiterAttr))
+			o.ObserveInt64(limit, int64(stats.Max), metric.WithAttributes(limiterAttr))
+			for _, result := range []struct {
+				name  string
+				count uint64
+			}{
+				{"admitted", stats.Admitted},
+				{"waited", stats.Waited},
+				{"rejected", stats.Rejected},
+				{"timed_out", stats.TimedOut},
+			} {
+				o.ObserveInt64(requests, int64(result.count), metric.WithAttributes(limiterAttr, attribute.String("result", result.name)))
+			}
+		}
+		return nil
+	}, inFlight, queued, limit, requests)
+	return err
+}
@@ -0,0 +1,51 @@
+package otel
+
+import (
+	"context"
+	"testing"
+
+	glk "github.com/hansir-hsj/GoLiteKit"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestRegisterConcurrencyLimiterMetricsObservesQueue(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	limiter := glk.NewConcurrencyLimiter(1)
+	if err := limiter.Acquire(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer limiter.Release()
+	_ = limiter.Acquire(context.Background())
+
+	if err := RegisterConcurrencyLimiterMetrics(provider, map[string]*glk.ConcurrencyLimiter{"exports": limiter}); err != nil {
+		t.Fatalf("RegisterConcurrencyLimiterMetrics: %v", err)
+	}
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatalf("collect: %v", err)
+	}
+
+	gauges := map[string]int64{}
+	results := map[string]int64{}
+	for _, scope := range rm.ScopeMetrics {
+		for _, m := range scope.Metrics {
+			switch data := m.Data.(type) {
+			case metricdata.Gauge[int64]:
+				gauges[m.Name] = data.DataPoints[0].Value
+			case metricdata.Sum[int64]:
+				for _, dp := range data.DataPoints {
+					result, _ := dp.Attributes.Value("result")
+					results[result.AsString()] = dp.Value
+				}
+			}
+		}
+	}
+	if gauges["glk.concurrency.in_flight"] != 1 || gauges["glk.concurrency.queued"] != 0 || gauges["glk.concurrency.limit"] != 1 {
+		t.Fatalf("gauges = %v", gauges)
+	}
+	if results["admitted"] != 1 || results["rejected"] != 1 {
+		t.Fatalf("requests = %v, want 1 admitted and 1 rejected", results)
+	}
+}
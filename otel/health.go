@@ -0,0 +1,46 @@
+package otel
+
+import (
+	"context"
+
+	glk "github.com/hansir-hsj/GoLiteKit"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// RegisterHealthMetrics exports the HealthMonitor rolling counters as
+// observable gauges on provider.
+func RegisterHealthMetrics(provider metric.MeterProvider, monitor *glk.HealthMonitor, opts ...Option) error {
+	options := applyOptions(opts)
+	meter := provider.Meter(options.ServiceName)
+
+	requests, err := meter.Int64ObservableGauge("glk.health.window.requests")
+	if err != nil {
+		return err
+	}
+	errors, err := meter.Int64ObservableGauge("glk.health.window.errors")
+	if err != nil {
+		return err
+	}
+	panics, err := meter.Int64ObservableGauge("glk.health.window.panics")
+	if err != nil {
+		return err
+	}
+	ready, err := meter.Int64ObservableGauge("glk.health.ready")
+	if err != nil {
+		return err
+	}
+
+	_, err = meter.RegisterCallback(func(ctx context.Context, o metric.Observer) error {
+		snap := monitor.Snapshot()
+		o.ObserveInt64(requests, snap.Requests)
+		o.ObserveInt64(errors, snap.Errors)
+		o.ObserveInt64(panics, snap.Panics)
+		readyValue := int64(0)
+		if snap.Ready {
+			readyValue = 1
+		}
+		o.ObserveInt64(ready, readyValue)
+		return nil
+	}, requests, errors, panics, ready)
+	return err
+}
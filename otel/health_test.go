@@ -0,0 +1,48 @@
+package otel
+
+import (
+	"context"
+	"testing"
+
+	glk "github.com/hansir-hsj/GoLiteKit"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestRegisterHealthMetricsObservesSnapshot(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	monitor := glk.NewHealthMonitor(glk.HealthOptions{MaxPanics: 1})
+	monitor.RecordRequest(500)
+	monitor.RecordPanic()
+
+	if err := RegisterHealthMetrics(provider, monitor); err != nil {
+		t.Fatalf("RegisterHealthMetrics: %v", err)
+	}
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatalf("collect: %v", err)
+	}
+
+	values := map[string]int64{}
+	for _, scope := range rm.ScopeMetrics {
+		for _, m := range scope.Metrics {
+			gauge, ok := m.Data.(metricdata.Gauge[int64])
+			if !ok || len(gauge.DataPoints) == 0 {
+				continue
+			}
+			values[m.Name] = gauge.DataPoints[0].Value
+		}
+	}
+
+	if values["glk.health.window.errors"] != 1 {
+		t.Fatalf("errors gauge = %d, want 1", values["glk.health.window.errors"])
+	}
+	if values["glk.health.window.panics"] != 1 {
+		t.Fatalf("panics gauge = %d, want 1", values["glk.health.window.panics"])
+	}
+	if values["glk.health.ready"] != 0 {
+		t.Fatalf("ready gauge = %d, want 0", values["glk.health.ready"])
+	}
+}
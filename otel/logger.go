@@ -0,0 +1,49 @@
+package otel
+
+import (
+	"context"
+
+	"github.com/hansir-hsj/GoLiteKit/logger"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// RegisterLoggerMetrics reports the counters of logger.ReadStats:
+//
+//	glk.logger.dropped            records that could not be written
+//	glk.logger.rotations          rotated log files
+//	glk.logger.rotation_failures  rotations that failed
+//	glk.logger.cleanup.deletions  rotated files deleted by cleanup
+//
+// A rising dropped or rotation_failures count means log lines are being
+// lost.
+func RegisterLoggerMetrics(provider metric.MeterProvider, opts ...Option) error {
+	options := applyOptions(opts)
+	meter := provider.Meter(options.ServiceName)
+
+	dropped, err := meter.Int64ObservableCounter("glk.logger.dropped")
+	if err != nil {
+		return err
+	}
+	rotations, err := meter.Int64ObservableCounter("glk.logger.rotations")
+	if err != nil {
+		return err
+	}
+	failures, err := meter.Int64ObservableCounter("glk.logger.rotation_failures")
+	if err != nil {
+		return err
+	}
+	deletions, err := meter.Int64ObservableCounter("glk.logger.cleanup.deletions")
+	if err != nil {
+		return err
+	}
+
+	_, err = meter.RegisterCallback(func(ctx context.Context, o metric.Observer) error {
+		stats := logger.ReadStats()
+		o.ObserveInt64(dropped, stats.Dropped)
+		o.ObserveInt64(rotations, stats.Rotations)
+		o.ObserveInt64(failures, stats.RotationFailures)
+		o.ObserveInt64(deletions, stats.CleanupDeletions)
+		return nil
+	}, dropped, rotations, failures, deletions)
+	return err
+}
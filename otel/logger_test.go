@@ -0,0 +1,46 @@
+package otel
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hansir-hsj/GoLiteKit/logger"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestRegisterLoggerMetricsObservesStats(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	if err := RegisterLoggerMetrics(provider); err != nil {
+		t.Fatalf("RegisterLoggerMetrics: %v", err)
+	}
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatalf("collect: %v", err)
+	}
+	stats := logger.ReadStats()
+	want := map[string]int64{
+		"glk.logger.dropped":           stats.Dropped,
+		"glk.logger.rotations":         stats.Rotations,
+		"glk.logger.rotation_failures": stats.RotationFailures,
+		"glk.logger.cleanup.deletions": stats.CleanupDeletions,
+	}
+	for _, scope := range rm.ScopeMetrics {
+		for _, m := range scope.Metrics {
+			sum, ok := m.Data.(metricdata.Sum[int64])
+			if !ok || len(sum.DataPoints) != 1 || !sum.IsMonotonic {
+				t.Errorf("%s: data = %+v, want one monotonic point", m.Name, m.Data)
+				continue
+			}
+			if sum.DataPoints[0].Value != want[m.Name] {
+				t.Errorf("%s = %d, want %d", m.Name, sum.DataPoints[0].Value, want[m.Name])
+			}
+			delete(want, m.Name)
+		}
+	}
+	if len(want) > 0 {
+		t.Errorf("missing metrics %v", want)
+	}
+}
@@ -3,9 +3,11 @@ package otel
 import (
 	"context"
 	"slices"
+	"sync"
 	"time"
 
 	glk "github.com/hansir-hsj/GoLiteKit"
+	"github.com/hansir-hsj/GoLiteKit/metrics"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/metric"
 )
@@ -13,11 +15,19 @@ import (
 type metricRecorder struct {
 	httpRequests       metric.Int64Counter
 	httpDuration       metric.Float64Histogram
+	httpErrors         metric.Int64Counter
 	serviceSpanCalls   metric.Int64Counter
 	serviceSpanLatency metric.Float64Histogram
 	options            Options
+
+	labelMu     sync.Mutex
+	labelValues map[string]map[string]struct{}
 }
 
+// overflowLabelValue replaces request label values beyond
+// Options.RequestLabelValueLimit.
+const overflowLabelValue = "other"
+
 func newMetricRecorder(options Options) *metricRecorder {
 	if options.MeterProvider == nil {
 		return nil
@@ -25,18 +35,23 @@ func newMetricRecorder(options Options) *metricRecorder {
 	meter := options.MeterProvider.Meter(options.ServiceName)
 	httpRequests, _ := meter.Int64Counter("glk.http.server.requests")
 	httpDuration, _ := meter.Float64Histogram("glk.http.server.duration_ms")
+	httpErrors, _ := meter.Int64Counter("glk.http.server.errors")
 	serviceSpanCalls, _ := meter.Int64Counter("glk.service.span.calls")
 	serviceSpanLatency, _ := meter.Float64Histogram("glk.service.span.duration_ms")
 	return &metricRecorder{
 		httpRequests:       httpRequests,
 		httpDuration:       httpDuration,
+		httpErrors:         httpErrors,
 		serviceSpanCalls:   serviceSpanCalls,
 		serviceSpanLatency: serviceSpanLatency,
 		options:            options,
 	}
 }
 
-func (r *metricRecorder) recordHTTP(ctx context.Context, method, route string, status int, elapsed time.Duration) {
+// recordHTTP records one request. Labels attached with metrics.Label are
+// added to the request counter, the latency histogram and, for failed
+// requests, the error counter.
+func (r *metricRecorder) recordHTTP(ctx context.Context, method, route string, status int, failed bool, elapsed time.Duration) {
 	if r == nil {
 		return
 	}
@@ -45,8 +60,47 @@ func (r *metricRecorder) recordHTTP(ctx context.Context, method, route string, s
 		attribute.String("http.route", route),
 		attribute.Int("http.response.status_code", status),
 	}
+	attrs = append(attrs, r.requestLabels(metrics.Labels(ctx))...)
 	r.httpRequests.Add(ctx, 1, metric.WithAttributes(attrs...))
 	r.httpDuration.Record(ctx, float64(elapsed)/float64(time.Millisecond), metric.WithAttributes(attrs...))
+	if failed {
+		r.httpErrors.Add(ctx, 1, metric.WithAttributes(attrs...))
+	}
+}
+
+// requestLabels maps the allowlisted request labels to attributes. Each key
+// keeps at most RequestLabelValueLimit distinct values; the rest are recorded
+// as "other" so a misbehaving caller cannot blow up cardinality.
+func (r *metricRecorder) requestLabels(labels []metrics.KeyValue) []attribute.KeyValue {
+	if len(labels) == 0 || len(r.options.RequestLabels) == 0 {
+		return nil
+	}
+	r.labelMu.Lock()
+	defer r.labelMu.Unlock()
+	if r.labelValues == nil {
+		r.labelValues = make(map[string]map[string]struct{})
+	}
+	attrs := make([]attribute.KeyValue, 0, len(labels))
+	for _, l := range labels {
+		if !slices.Contains(r.options.RequestLabels, l.Key) || metricLabelDenied(l.Key) {
+			continue
+		}
+		seen := r.labelValues[l.Key]
+		if seen == nil {
+			seen = make(map[string]struct{})
+			r.labelValues[l.Key] = seen
+		}
+		value := l.Value
+		if _, ok := seen[value]; !ok {
+			if limit := r.options.RequestLabelValueLimit; limit > 0 && len(seen) >= limit {
+				value = overflowLabelValue
+			} else {
+				seen[value] = struct{}{}
+			}
+		}
+		attrs = append(attrs, attribute.String(l.Key, value))
+	}
+	return attrs
 }
 
 func (r *metricRecorder) recordServiceSpan(ctx context.Context, name string, status glk.SpanStatus, elapsed time.Duration, attrs []glk.Attribute) {
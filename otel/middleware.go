@@ -3,7 +3,6 @@ package otel
 import (
 	"bufio"
 	"context"
-	"fmt"
 	"net"
 	"net/http"
 	"strings"
@@ -11,6 +10,7 @@ import (
 
 	glk "github.com/hansir-hsj/GoLiteKit"
 	"github.com/hansir-hsj/GoLiteKit/logger"
+	"github.com/hansir-hsj/GoLiteKit/metrics"
 	"go.opentelemetry.io/otel/trace"
 )
 
@@ -22,6 +22,7 @@ func Middleware(observer *Observer, opts ...Option) glk.Middleware {
 			pattern := routePattern(r)
 			spanName := "HTTP " + r.Method + " " + pattern
 			ctx = glk.WithObserverContext(ctx, observer)
+			ctx = metrics.WithLabels(ctx)
 			spanAttrs := []glk.Attribute{
 				glk.StringAttr("http.request.method", r.Method),
 				glk.StringAttr("http.route", pattern),
@@ -57,7 +58,11 @@ func Middleware(observer *Observer, opts ...Option) glk.Middleware {
 				glk.IntAttr("http.response.status_code", status),
 				glk.FloatAttr("http.server.duration_ms", float64(elapsed)/float64(time.Millisecond)),
 			)
-			observer.metrics.recordHTTP(ctx, r.Method, pattern, status, elapsed)
+			if tenant := glk.TenantFrom(ctx); tenant != nil {
+				span.SetAttributes(glk.StringAttr(TenantAttribute, tenant.ID))
+			}
+			failed := err != nil || status >= http.StatusInternalServerError
+			observer.metrics.recordHTTP(ctx, r.Method, pattern, status, failed, elapsed)
 
 			if err != nil {
 				span.SetError(err)
@@ -100,22 +105,27 @@ func (w *statusCapture) WriteHeader(statusCode int) {
 	if w.headerWritten {
 		return
 	}
+	if statusCode >= 100 && statusCode < 200 && statusCode != http.StatusSwitchingProtocols {
+		// 1xx, such as 103 Early Hints, precedes the final status.
+		w.ResponseWriter.WriteHeader(statusCode)
+		return
+	}
 	w.headerWritten = true
 	w.statusCode = statusCode
 	w.ResponseWriter.WriteHeader(statusCode)
 }
 
 func (w *statusCapture) Flush() {
-	if f, ok := w.ResponseWriter.(http.Flusher); ok {
-		f.Flush()
-	}
+	_ = w.FlushError()
+}
+
+// FlushError is Flush reporting the error of the underlying writer.
+func (w *statusCapture) FlushError() error {
+	return http.NewResponseController(w.ResponseWriter).Flush()
 }
 
 func (w *statusCapture) Hijack() (net.Conn, *bufio.ReadWriter, error) {
-	if hj, ok := w.ResponseWriter.(http.Hijacker); ok {
-		return hj.Hijack()
-	}
-	return nil, nil, fmt.Errorf("underlying ResponseWriter does not support Hijack")
+	return http.NewResponseController(w.ResponseWriter).Hijack()
 }
 
 func (w *statusCapture) Unwrap() http.ResponseWriter {
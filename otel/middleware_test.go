@@ -10,6 +10,7 @@ import (
 
 	glk "github.com/hansir-hsj/GoLiteKit"
 	"github.com/hansir-hsj/GoLiteKit/logger"
+	"github.com/hansir-hsj/GoLiteKit/metrics"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
 	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
@@ -113,6 +114,80 @@ func TestMiddlewareRecordsHTTPMetrics(t *testing.T) {
 	assertMetricMissing(t, rm, "glk.service.span.duration_ms")
 }
 
+func TestMiddlewareAppliesRequestLabels(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	meterProvider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	observer := NewObserver(
+		WithMeterProvider(meterProvider),
+		WithRequestLabels("client_app", "api_version", "user.id"),
+		WithRequestLabelValueLimit(1),
+	)
+	middleware := Middleware(observer)
+	handler := middleware(func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		metrics.Label(r.Context(), "client_app", r.URL.Query().Get("app"))
+		metrics.Label(ctx, "api_version", "v2")
+		metrics.Label(ctx, "user.id", "42")
+		metrics.Label(ctx, "tenant", "acme")
+		return errors.New("boom")
+	})
+
+	for _, app := range []string{"ios", "android"} {
+		req := httptest.NewRequest(http.MethodGet, "/users?app="+app, nil)
+		req.Pattern = "GET /users"
+		_ = handler(context.Background(), httptest.NewRecorder(), req)
+	}
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatalf("collect metrics: %v", err)
+	}
+	for _, name := range []string{"glk.http.server.requests", "glk.http.server.duration_ms", "glk.http.server.errors"} {
+		sets := metricAttributeSets(t, rm, name)
+		if len(sets) != 2 {
+			t.Fatalf("%s: got %d attribute sets, want 2", name, len(sets))
+		}
+		apps := map[string]bool{}
+		for _, set := range sets {
+			if v, _ := set.Value("api_version"); v.AsString() != "v2" {
+				t.Fatalf("%s: api_version = %q, want v2", name, v.AsString())
+			}
+			if set.HasValue("user.id") || set.HasValue("tenant") {
+				t.Fatalf("%s: denied or unlisted label recorded: %v", name, set.ToSlice())
+			}
+			v, _ := set.Value("client_app")
+			apps[v.AsString()] = true
+		}
+		if !apps["ios"] || !apps["other"] {
+			t.Fatalf("%s: client_app values = %v, want ios and other", name, apps)
+		}
+	}
+}
+
+func metricAttributeSets(t *testing.T, rm metricdata.ResourceMetrics, name string) []attribute.Set {
+	t.Helper()
+	for _, scope := range rm.ScopeMetrics {
+		for _, m := range scope.Metrics {
+			if m.Name != name {
+				continue
+			}
+			var sets []attribute.Set
+			switch data := m.Data.(type) {
+			case metricdata.Sum[int64]:
+				for _, dp := range data.DataPoints {
+					sets = append(sets, dp.Attributes)
+				}
+			case metricdata.Histogram[float64]:
+				for _, dp := range data.DataPoints {
+					sets = append(sets, dp.Attributes)
+				}
+			}
+			return sets
+		}
+	}
+	t.Fatalf("metric %q not found", name)
+	return nil
+}
+
 func TestMiddlewareMarksServerError(t *testing.T) {
 	exporter := tracetest.NewInMemoryExporter()
 	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
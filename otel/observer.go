@@ -8,6 +8,10 @@ import (
 	"go.opentelemetry.io/otel/trace"
 )
 
+// TenantAttribute is the span attribute holding the ID of the request's
+// glk.Tenant.
+const TenantAttribute = "tenant.id"
+
 type Observer struct {
 	options Options
 	tracer  trace.Tracer
@@ -28,7 +32,12 @@ func NewObserver(opts ...Option) *Observer {
 	}
 }
 
+// StartSpan starts a service span, tagged with the request's tenant when
+// glk.TenantMiddleware resolved one.
 func (o *Observer) StartSpan(ctx context.Context, name string, attrs ...glk.Attribute) (context.Context, glk.Span) {
+	if tenant := glk.TenantFrom(ctx); tenant != nil {
+		attrs = append(attrs[:len(attrs):len(attrs)], glk.StringAttr(TenantAttribute, tenant.ID))
+	}
 	ctx, span := o.tracer.Start(ctx, name, trace.WithAttributes(mapAttributes(attrs)...))
 	return ctx, &spanImpl{
 		ctx:            ctx,
@@ -11,6 +11,12 @@ type Options struct {
 	ServiceName            string
 	ClientErrorAsSpanError bool
 	MetricAttributeLabels  []string
+	// RequestLabels allowlists the keys set with metrics.Label that are
+	// recorded on HTTP metrics; other keys are ignored.
+	RequestLabels []string
+	// RequestLabelValueLimit bounds the distinct values recorded per request
+	// label key; later values are recorded as "other".
+	RequestLabelValueLimit int
 	TracerProvider         trace.TracerProvider
 	MeterProvider          metric.MeterProvider
 }
@@ -18,9 +24,12 @@ type Options struct {
 type Option func(*Options)
 
 func defaultOptions() Options {
-	return Options{}
+	return Options{RequestLabelValueLimit: DefaultRequestLabelValueLimit}
 }
 
+// DefaultRequestLabelValueLimit is the default RequestLabelValueLimit.
+const DefaultRequestLabelValueLimit = 100
+
 func WithServiceName(name string) Option {
 	return func(o *Options) {
 		o.ServiceName = name
@@ -39,6 +48,20 @@ func WithMetricAttributeLabels(keys ...string) Option {
 	}
 }
 
+// WithRequestLabels records the given metrics.Label keys on the HTTP request
+// counter, latency histogram and error counter.
+func WithRequestLabels(keys ...string) Option {
+	return func(o *Options) {
+		o.RequestLabels = slices.Clone(keys)
+	}
+}
+
+func WithRequestLabelValueLimit(n int) Option {
+	return func(o *Options) {
+		o.RequestLabelValueLimit = n
+	}
+}
+
 func WithTracerProvider(provider trace.TracerProvider) Option {
 	return func(o *Options) {
 		o.TracerProvider = provider
@@ -0,0 +1,64 @@
+package otel
+
+import (
+	"context"
+
+	glk "github.com/hansir-hsj/GoLiteKit"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// RegisterRateLimiterMetrics reports each limiter under its map key:
+//
+//	glk.ratelimit.requests       counter by limiter, class and result
+//	                             (allowed, denied_global, denied_key,
+//	                             denied_capacity)
+//	glk.ratelimit.tracked_keys   gauge of per-key limiters in memory
+//	glk.ratelimit.global.tokens  gauge of tokens left in the global limiter
+//
+// With a Prometheus exporter they appear as glk_ratelimit_requests_total and
+// so on.
+func RegisterRateLimiterMetrics(provider metric.MeterProvider, limiters map[string]*glk.RateLimiter, opts ...Option) error {
+	options := applyOptions(opts)
+	meter := provider.Meter(options.ServiceName)
+
+	requests, err := meter.Int64ObservableCounter("glk.ratelimit.requests")
+	if err != nil {
+		return err
+	}
+	trackedKeys, err := meter.Int64ObservableGauge("glk.ratelimit.tracked_keys")
+	if err != nil {
+		return err
+	}
+	globalTokens, err := meter.Float64ObservableGauge("glk.ratelimit.global.tokens")
+	if err != nil {
+		return err
+	}
+
+	_, err = meter.RegisterCallback(func(ctx context.Context, o metric.Observer) error {
+		for name, limiter := range limiters {
+			stats := limiter.Stats()
+			limiterAttr := attribute.String("limiter", name)
+			o.ObserveInt64(trackedKeys, int64(stats.TrackedKeys), metric.WithAttributes(limiterAttr))
+			if stats.Global != nil {
+				o.ObserveFloat64(globalTokens, stats.Global.Tokens, metric.WithAttributes(limiterAttr))
+			}
+			for class, c := range stats.Classes {
+				classAttr := attribute.String("class", class)
+				for _, result := range []struct {
+					name  string
+					count uint64
+				}{
+					{"allowed", c.Allowed},
+					{"denied_global", c.DeniedGlobal},
+					{"denied_key", c.DeniedKey},
+					{"denied_capacity", c.DeniedCapacity},
+				} {
+					o.ObserveInt64(requests, int64(result.count), metric.WithAttributes(limiterAttr, classAttr, attribute.String("result", result.name)))
+				}
+			}
+		}
+		return nil
+	}, requests, trackedKeys, globalTokens)
+	return err
+}
@@ -0,0 +1,53 @@
+package otel
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	glk "github.com/hansir-hsj/GoLiteKit"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestRegisterRateLimiterMetricsObservesCounters(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	limiter := glk.NewRateLimiter(0, 1)
+	mw := limiter.RateLimiterAsMiddleware(glk.ByIP)(func(ctx context.Context, w http.ResponseWriter, r *http.Request) error { return nil })
+	for i := 0; i < 3; i++ {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		_ = mw(req.Context(), httptest.NewRecorder(), req)
+	}
+
+	if err := RegisterRateLimiterMetrics(provider, map[string]*glk.RateLimiter{"api": limiter}); err != nil {
+		t.Fatalf("RegisterRateLimiterMetrics: %v", err)
+	}
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatalf("collect: %v", err)
+	}
+
+	results := map[string]int64{}
+	var tracked int64
+	for _, scope := range rm.ScopeMetrics {
+		for _, m := range scope.Metrics {
+			switch m.Name {
+			case "glk.ratelimit.requests":
+				for _, dp := range m.Data.(metricdata.Sum[int64]).DataPoints {
+					result, _ := dp.Attributes.Value("result")
+					results[result.AsString()] = dp.Value
+				}
+			case "glk.ratelimit.tracked_keys":
+				tracked = m.Data.(metricdata.Gauge[int64]).DataPoints[0].Value
+			}
+		}
+	}
+	if results["allowed"] != 1 || results["denied_key"] != 2 {
+		t.Fatalf("requests = %v, want 1 allowed and 2 denied_key", results)
+	}
+	if tracked != 1 {
+		t.Fatalf("tracked keys = %d, want 1", tracked)
+	}
+}
@@ -0,0 +1,41 @@
+package otel
+
+import (
+	"context"
+
+	glk "github.com/hansir-hsj/GoLiteKit"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// RegisterServiceMetrics reports the windowed percentiles of stats as the
+// glk.service.latency gauge (milliseconds, one point per span.name and
+// quantile) and the span counts as the glk.service.window.calls gauge.
+func RegisterServiceMetrics(provider metric.MeterProvider, stats *glk.ServiceStats, opts ...Option) error {
+	options := applyOptions(opts)
+	meter := provider.Meter(options.ServiceName)
+
+	latency, err := meter.Float64ObservableGauge("glk.service.latency", metric.WithUnit("ms"))
+	if err != nil {
+		return err
+	}
+	calls, err := meter.Int64ObservableGauge("glk.service.window.calls")
+	if err != nil {
+		return err
+	}
+
+	_, err = meter.RegisterCallback(func(ctx context.Context, o metric.Observer) error {
+		for _, s := range stats.Snapshot() {
+			name := attribute.String("span.name", s.Service)
+			o.ObserveInt64(calls, int64(s.WindowCalls), metric.WithAttributes(name))
+			if s.WindowCalls == 0 {
+				continue
+			}
+			o.ObserveFloat64(latency, s.P50, metric.WithAttributes(name, attribute.String("quantile", "0.5")))
+			o.ObserveFloat64(latency, s.P95, metric.WithAttributes(name, attribute.String("quantile", "0.95")))
+			o.ObserveFloat64(latency, s.P99, metric.WithAttributes(name, attribute.String("quantile", "0.99")))
+		}
+		return nil
+	}, latency, calls)
+	return err
+}
@@ -0,0 +1,55 @@
+package otel
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	glk "github.com/hansir-hsj/GoLiteKit"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestRegisterServiceMetricsObservesPercentiles(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	stats := glk.NewServiceStats()
+	for i := 1; i <= 100; i++ {
+		stats.Record("db.query", time.Duration(i)*time.Millisecond, false)
+	}
+
+	if err := RegisterServiceMetrics(provider, stats); err != nil {
+		t.Fatalf("RegisterServiceMetrics: %v", err)
+	}
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatalf("collect: %v", err)
+	}
+
+	quantiles := map[string]float64{}
+	var calls int64
+	for _, scope := range rm.ScopeMetrics {
+		for _, m := range scope.Metrics {
+			switch data := m.Data.(type) {
+			case metricdata.Gauge[float64]:
+				for _, dp := range data.DataPoints {
+					q, _ := dp.Attributes.Value("quantile")
+					quantiles[q.AsString()] = dp.Value
+				}
+			case metricdata.Gauge[int64]:
+				calls = data.DataPoints[0].Value
+			}
+		}
+	}
+
+	if calls != 100 {
+		t.Fatalf("calls gauge = %d, want 100", calls)
+	}
+	if p99 := quantiles["0.99"]; p99 < 98 || p99 > 101 {
+		t.Fatalf("p99 = %v, want about 99ms", p99)
+	}
+	if p50 := quantiles["0.5"]; p50 < 49 || p50 > 51 {
+		t.Fatalf("p50 = %v, want about 50ms", p50)
+	}
+}
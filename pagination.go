@@ -0,0 +1,172 @@
+package golitekit
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	DefaultPageSize    = 20
+	DefaultMaxPageSize = 100
+)
+
+// PaginatorOptions configures NewPaginator.
+type PaginatorOptions struct {
+	DefaultSize int // page_size when absent, defaults to DefaultPageSize
+	MaxSize     int // cap on page_size, defaults to DefaultMaxPageSize
+	// SortFields allow-lists the sort query values, mapping each to its
+	// column. Sorting on anything else is rejected with 400.
+	SortFields map[string]string
+	// DefaultSort applies when the request has no sort, e.g. "-created_at".
+	DefaultSort string
+	// Param names, defaulting to "page", "page_size" and "sort".
+	PageParam, SizeParam, SortParam string
+}
+
+// Paginator parses page, page_size and sort query parameters:
+//
+//	?page=2&page_size=50&sort=-created_at,name
+//
+// A leading "-" sorts descending.
+type Paginator struct {
+	opt PaginatorOptions
+}
+
+// NewPaginator creates a Paginator.
+func NewPaginator(opts ...PaginatorOptions) *Paginator {
+	var opt PaginatorOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if opt.MaxSize <= 0 {
+		opt.MaxSize = DefaultMaxPageSize
+	}
+	if opt.DefaultSize <= 0 {
+		opt.DefaultSize = min(DefaultPageSize, opt.MaxSize)
+	}
+	if opt.PageParam == "" {
+		opt.PageParam = "page"
+	}
+	if opt.SizeParam == "" {
+		opt.SizeParam = "page_size"
+	}
+	if opt.SortParam == "" {
+		opt.SortParam = "sort"
+	}
+	return &Paginator{opt: opt}
+}
+
+// SortField is one column of a sort order.
+type SortField struct {
+	Field  string // the sort query value
+	Column string
+	Desc   bool
+}
+
+// Page is a parsed page request.
+type Page struct {
+	Number int // 1-based
+	Size   int
+	Sort   []SortField
+
+	request *http.Request
+	opt     *PaginatorOptions
+}
+
+// Parse reads the page request of r. Page numbers below 1 and oversized
+// pages are clamped; an unknown sort field is a 400.
+func (p *Paginator) Parse(r *http.Request) (Page, error) {
+	q := r.URL.Query()
+	page := Page{
+		Number:  parseValue(q.Get(p.opt.PageParam), 1, strconv.Atoi),
+		Size:    parseValue(q.Get(p.opt.SizeParam), p.opt.DefaultSize, strconv.Atoi),
+		request: r,
+		opt:     &p.opt,
+	}
+	page.Number = max(page.Number, 1)
+	if page.Size <= 0 {
+		page.Size = p.opt.DefaultSize
+	}
+	page.Size = min(page.Size, p.opt.MaxSize)
+
+	sort := q.Get(p.opt.SortParam)
+	if sort == "" {
+		sort = p.opt.DefaultSort
+	}
+	for _, field := range splitValues([]string{sort}, ",") {
+		desc := strings.HasPrefix(field, "-")
+		field = strings.TrimPrefix(field, "-")
+		column, ok := p.opt.SortFields[field]
+		if !ok {
+			return Page{}, ErrBadRequest(fmt.Sprintf("Cannot sort by %q", field), nil)
+		}
+		page.Sort = append(page.Sort, SortField{Field: field, Column: column, Desc: desc})
+	}
+	return page, nil
+}
+
+// Paginate parses the page request of the current request.
+func (ctx *Context) Paginate(p *Paginator) (Page, error) {
+	return p.Parse(ctx.request)
+}
+
+// Paginate parses the page request of the current request.
+func (c *BaseControllerOf[T]) Paginate(p *Paginator) (Page, error) {
+	return p.Parse(c.request)
+}
+
+// Offset is the number of rows before the page.
+func (pg Page) Offset() int {
+	return (pg.Number - 1) * pg.Size
+}
+
+// Scope applies the page's offset, limit and order to a query:
+//
+//	db.Scopes(page.Scope).Find(&orders)
+func (pg Page) Scope(db *gorm.DB) *gorm.DB {
+	db = db.Offset(pg.Offset()).Limit(pg.Size)
+	for _, s := range pg.Sort {
+		db = db.Order(clause.OrderByColumn{Column: clause.Column{Name: s.Column}, Desc: s.Desc})
+	}
+	return db
+}
+
+// PageResult is the paginated Response.Data envelope.
+type PageResult struct {
+	Items    any    `json:"items"`
+	Page     int    `json:"page"`
+	PageSize int    `json:"page_size"`
+	Total    int64  `json:"total"`
+	Next     string `json:"next,omitempty"`
+	Prev     string `json:"prev,omitempty"`
+}
+
+// Result wraps the items of the page and the total row count, with links
+// to the next and previous pages that keep the other query parameters.
+func (pg Page) Result(items any, total int64) PageResult {
+	res := PageResult{Items: items, Page: pg.Number, PageSize: pg.Size, Total: total}
+	if pg.request == nil {
+		return res
+	}
+	if int64(pg.Offset()+pg.Size) < total {
+		res.Next = pg.link(pg.Number + 1)
+	}
+	if pg.Number > 1 {
+		res.Prev = pg.link(pg.Number - 1)
+	}
+	return res
+}
+
+func (pg Page) link(number int) string {
+	u := *pg.request.URL
+	q := u.Query()
+	q.Set(pg.opt.PageParam, strconv.Itoa(number))
+	q.Set(pg.opt.SizeParam, strconv.Itoa(pg.Size))
+	u.RawQuery = q.Encode()
+	return u.RequestURI()
+}
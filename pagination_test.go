@@ -0,0 +1,69 @@
+package golitekit
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	mysqlDriver "gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+func TestPaginator_Parse(t *testing.T) {
+	p := NewPaginator(PaginatorOptions{
+		MaxSize:     50,
+		SortFields:  map[string]string{"created": "created_at", "name": "name"},
+		DefaultSort: "-created",
+	})
+
+	page, err := p.Parse(httptest.NewRequest(http.MethodGet, "/orders?page=0&page_size=500", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page.Number != 1 || page.Size != 50 || len(page.Sort) != 1 || page.Sort[0] != (SortField{"created", "created_at", true}) {
+		t.Fatalf("clamped page = %+v", page)
+	}
+
+	page, err = p.Parse(httptest.NewRequest(http.MethodGet, "/orders?page=3&sort=name,-created", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page.Size != DefaultPageSize || page.Offset() != 40 || len(page.Sort) != 2 || page.Sort[0].Desc || !page.Sort[1].Desc {
+		t.Fatalf("page = %+v", page)
+	}
+
+	_, err = p.Parse(httptest.NewRequest(http.MethodGet, "/orders?sort=password", nil))
+	var appErr *AppError
+	if !errors.As(err, &appErr) || appErr.Code != http.StatusBadRequest {
+		t.Fatalf("disallowed sort = %v, want 400", err)
+	}
+}
+
+func TestPage_ScopeAndResult(t *testing.T) {
+	p := NewPaginator(PaginatorOptions{SortFields: map[string]string{"created": "created_at"}})
+	page, err := p.Parse(httptest.NewRequest(http.MethodGet, "/orders?status=open&page=2&page_size=10&sort=-created", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gdb, err := gorm.Open(mysqlDriver.New(mysqlDriver.Config{DSN: "u:p@tcp(127.0.0.1:1)/x", SkipInitializeWithVersion: true}), &gorm.Config{DisableAutomaticPing: true, DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	type order struct{ ID int }
+	var orders []order
+	sql := gdb.Scopes(page.Scope).Find(&orders).Statement.SQL.String()
+	if !strings.Contains(sql, "ORDER BY `created_at` DESC LIMIT ? OFFSET ?") {
+		t.Fatalf("sql = %s", sql)
+	}
+
+	res := page.Result([]int{11, 12}, 35)
+	if res.Next != "/orders?page=3&page_size=10&sort=-created&status=open" || res.Prev != "/orders?page=1&page_size=10&sort=-created&status=open" {
+		t.Fatalf("links = %q %q", res.Next, res.Prev)
+	}
+	if last := (Page{Number: 4, Size: 10}).Result(nil, 35); last.Next != "" {
+		t.Fatalf("last page has next %q", last.Next)
+	}
+}
@@ -0,0 +1,178 @@
+package golitekit
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// BindParams sets the fields of the struct dst points to from the query
+// string and the path parameters of the request:
+//
+//	type ListParams struct {
+//		OrgID  int64     `path:"org"`
+//		Page   int       `query:"page"`
+//		Active *bool     `query:"active"`
+//		Since  time.Time `query:"since" layout:"2006-01-02"`
+//		Tags   []string  `query:"tag"`              // ?tag=a&tag=b
+//		IDs    []int     `query:"ids" sep:","`      // ?ids=1,2,3
+//	}
+//
+// Fields without a query or path tag, and absent parameters, are left
+// alone. Ints, uints, floats, bools, strings, time.Time (RFC 3339 unless a
+// layout tag is set), time.Duration, pointers and slices of these are
+// supported. A value that does not convert is answered with a 400.
+func (ctx *Context) BindParams(dst any) error {
+	return bindParams(ctx.request, dst)
+}
+
+// BindParams binds query and path parameters onto dst, see Context.BindParams.
+func (c *BaseControllerOf[T]) BindParams(dst any) error {
+	return bindParams(c.request, dst)
+}
+
+func bindParams(r *http.Request, dst any) error {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Pointer || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("golitekit: BindParams needs a struct pointer, got %T", dst)
+	}
+	if r == nil {
+		return nil
+	}
+	return bindParamFields(v.Elem(), r, r.URL.Query())
+}
+
+func bindParamFields(v reflect.Value, r *http.Request, query map[string][]string) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		field := v.Field(i)
+		if f.Anonymous && f.Type.Kind() == reflect.Struct {
+			if err := bindParamFields(field, r, query); err != nil {
+				return err
+			}
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+
+		var source, name string
+		var values []string
+		if name = f.Tag.Get("path"); name != "" {
+			source = "path"
+			if s := r.PathValue(name); s != "" {
+				values = []string{s}
+			}
+		} else if name = f.Tag.Get("query"); name != "" {
+			source = "query"
+			values = query[name]
+		} else {
+			continue
+		}
+		if len(values) == 0 {
+			continue
+		}
+		if sep := f.Tag.Get("sep"); sep != "" {
+			var split []string
+			for _, s := range values {
+				split = append(split, strings.Split(s, sep)...)
+			}
+			values = split
+		}
+		if err := setParamField(field, values, f.Tag.Get("layout")); err != nil {
+			return ErrBadRequest(fmt.Sprintf("Invalid %s parameter %q", source, name), err)
+		}
+	}
+	return nil
+}
+
+// setParamField sets field from values: the first one, or all of them for
+// a slice.
+func setParamField(field reflect.Value, values []string, layout string) error {
+	if field.Kind() == reflect.Slice && field.Type().Elem().Kind() != reflect.Uint8 {
+		s := reflect.MakeSlice(field.Type(), len(values), len(values))
+		for i, value := range values {
+			if err := setStringValue(s.Index(i), value, layout); err != nil {
+				return err
+			}
+		}
+		field.Set(s)
+		return nil
+	}
+	return setStringValue(field, values[0], layout)
+}
+
+// setStringValue parses value into field. layout applies to time.Time and
+// defaults to time.RFC3339.
+func setStringValue(field reflect.Value, value, layout string) error {
+	switch field.Type() {
+	case timeType:
+		if layout == "" {
+			layout = time.RFC3339
+		}
+		t, err := time.Parse(layout, value)
+		if err != nil {
+			return err
+		}
+		field.Set(reflect.ValueOf(t))
+		return nil
+	case durationType:
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(d))
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		intVal, err := strconv.ParseInt(value, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(intVal)
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		uintVal, err := strconv.ParseUint(value, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetUint(uintVal)
+
+	case reflect.Float32, reflect.Float64:
+		floatVal, err := strconv.ParseFloat(value, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetFloat(floatVal)
+
+	case reflect.Bool:
+		boolVal, _ := parseBool(value)
+		field.SetBool(boolVal)
+
+	case reflect.Ptr:
+		// Allocate a new value of the pointed-to type, parse the string into it,
+		// then point the field at the new value.  This supports optional fields
+		// declared as *string, *int64, *bool, etc.
+		ptr := reflect.New(field.Type().Elem())
+		if err := setStringValue(ptr.Elem(), value, layout); err != nil {
+			return err
+		}
+		field.Set(ptr)
+
+	default:
+		return fmt.Errorf("unsupported field type: %v", field.Kind())
+	}
+
+	return nil
+}
@@ -0,0 +1,75 @@
+package golitekit
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type pageParams struct {
+	Page  int    `query:"page"`
+	Sort  string `query:"sort"`
+	Limit int
+}
+
+type listParams struct {
+	pageParams
+	OrgID   int64         `path:"org"`
+	Active  *bool         `query:"active"`
+	Since   time.Time     `query:"since" layout:"2006-01-02"`
+	Timeout time.Duration `query:"timeout"`
+	Tags    []string      `query:"tag"`
+	IDs     []uint        `query:"ids" sep:","`
+}
+
+func TestBindParams(t *testing.T) {
+	var got listParams
+	r := newTestRouter()
+	r.GET("/orgs/{org}/items", func(ctx *Context) error {
+		got = listParams{pageParams: pageParams{Limit: 20}}
+		if err := ctx.BindParams(&got); err != nil {
+			return err
+		}
+		return ctx.String(http.StatusOK, "ok")
+	})
+
+	rec := httptest.NewRecorder()
+	r.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet,
+		"/orgs/7/items?page=3&sort=-id&active=true&since=2024-05-01&timeout=1m30s&tag=a&tag=b&ids=1,2,3", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("code = %d: %s", rec.Code, rec.Body)
+	}
+	want := listParams{
+		pageParams: pageParams{Page: 3, Sort: "-id", Limit: 20},
+		OrgID:      7,
+		Since:      time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+		Timeout:    90 * time.Second,
+		Tags:       []string{"a", "b"},
+		IDs:        []uint{1, 2, 3},
+	}
+	if got.Active == nil || !*got.Active {
+		t.Fatalf("Active = %v", got.Active)
+	}
+	got.Active = nil
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("bound %+v\nwant  %+v", got, want)
+	}
+
+	rec = httptest.NewRecorder()
+	r.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/orgs/x/items", nil))
+	var resp Response
+	_ = json.Unmarshal(rec.Body.Bytes(), &resp)
+	if rec.Code != http.StatusBadRequest || resp.Msg != `Invalid path parameter "org"` {
+		t.Fatalf("bad path param = %d %q", rec.Code, resp.Msg)
+	}
+}
+
+func TestBindParams_RejectsNonStructPointer(t *testing.T) {
+	var n int
+	if err := bindParams(httptest.NewRequest(http.MethodGet, "/", nil), &n); err == nil {
+		t.Fatal("want an error for a non-struct destination")
+	}
+}
@@ -1,6 +1,7 @@
 package golitekit
 
 import (
+	"context"
 	"net"
 	"net/http"
 	"net/http/pprof"
@@ -11,9 +12,16 @@ import (
 type PprofOptions struct {
 	Prefix       string // URL prefix, defaults to "/debug/pprof"
 	LoopbackOnly bool   // restrict to loopback addresses (127.0.0.1, ::1)
+	// Token, when set, requires "Authorization: Bearer <token>" as MountAdmin
+	// does.
+	Token string
+	// Middlewares run inside the router's middlewares, after the loopback
+	// and token checks, e.g. BasicAuthMiddleware.
+	Middlewares []Middleware
 }
 
-// MountPprof registers pprof handlers on the router's mux.
+// MountPprof registers pprof handlers on the router's mux, behind the
+// router's middlewares and the checks of opts.
 func (r *Router) MountPprof(opts ...PprofOptions) {
 	opt := PprofOptions{Prefix: "/debug/pprof"}
 	if len(opts) > 0 {
@@ -23,21 +31,25 @@ func (r *Router) MountPprof(opts ...PprofOptions) {
 		opt.Prefix = "/debug/pprof"
 	}
 
+	guards := NewMiddlewareQueue()
+	if opt.LoopbackOnly {
+		guards.Use(loopbackOnlyMiddleware)
+	}
+	if opt.Token != "" {
+		guards.Use(adminAuthMiddleware(opt.Token))
+	}
+	guards.Use(opt.Middlewares...)
 	wrap := func(h http.HandlerFunc) http.Handler {
-		if !opt.LoopbackOnly {
-			return r.wrapHTTPHandler(h)
-		}
-		return r.wrapHTTPHandler(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
-			if !isLoopback(req) {
-				http.Error(w, "Forbidden", http.StatusForbidden)
-				return
-			}
+		inner := Handler(func(ctx context.Context, w http.ResponseWriter, req *http.Request) error {
 			h(w, req)
-		}))
+			return nil
+		})
+		return r.wrapHandlerWithContext(r.middlewares.Apply(guards.Apply(inner)))
 	}
 
 	r.routesRegistered = true
 	prefix := strings.TrimRight(opt.Prefix, "/")
+	r.recordRoute("", prefix+"/", "pprof")
 	r.mux.Handle(prefix+"/", wrap(pprof.Index))
 	r.mux.Handle(prefix+"/cmdline", wrap(pprof.Cmdline))
 	r.mux.Handle(prefix+"/profile", wrap(pprof.Profile))
@@ -45,6 +57,38 @@ func (r *Router) MountPprof(opts ...PprofOptions) {
 	r.mux.Handle(prefix+"/trace", wrap(pprof.Trace))
 }
 
+// PprofHandler serves the pprof endpoints on a router of their own, behind
+// the app's middlewares as they are now, for a separate internal listener:
+//
+//	app.Start(glk.ServerConfig{Addr: ":8080", Listeners: []glk.ListenerConfig{
+//		{Name: "pprof", Addr: "127.0.0.1:6060", Handler: app.PprofHandler()},
+//	}})
+func (a *App) PprofHandler(opts ...PprofOptions) http.Handler {
+	r := NewRouter(a.services)
+	r.middlewares = a.router.middlewares.Clone()
+	r.MountPprof(opts...)
+	return r.Handler()
+}
+
+// configPprofOptions guards the pprof endpoints enabled by the config: only
+// loopback clients are served unless a token is set, which remote clients
+// must then send.
+func configPprofOptions(token string) PprofOptions {
+	if token != "" {
+		return PprofOptions{Token: token}
+	}
+	return PprofOptions{LoopbackOnly: true}
+}
+
+func loopbackOnlyMiddleware(next Handler) Handler {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		if !isLoopback(r) {
+			return ErrForbidden("Forbidden", nil)
+		}
+		return next(ctx, w, r)
+	}
+}
+
 func isLoopback(r *http.Request) bool {
 	host, _, err := net.SplitHostPort(r.RemoteAddr)
 	if err != nil {
@@ -113,3 +113,80 @@ func TestPprof_LoopbackOnly_AllowsLocal(t *testing.T) {
 		t.Fatalf("status = %d, want %d for loopback", rec.Code, http.StatusOK)
 	}
 }
+
+func TestPprof_TokenAndMiddlewares(t *testing.T) {
+	var ran bool
+	app := NewApp()
+	app.MountPprof(PprofOptions{Token: "secret", Middlewares: []Middleware{func(next Handler) Handler {
+		return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			ran = true
+			return next(ctx, w, r)
+		}
+	}}})
+
+	req := httptest.NewRequest(http.MethodGet, "/debug/pprof/", nil)
+	rec := httptest.NewRecorder()
+	app.Handler().ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized || ran {
+		t.Fatalf("status = %d, middleware ran = %v; want 401 before the middlewares", rec.Code, ran)
+	}
+
+	req.Header.Set("Authorization", "Bearer secret")
+	rec = httptest.NewRecorder()
+	app.Handler().ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK || !ran {
+		t.Fatalf("status = %d, middleware ran = %v; want 200 through the middlewares", rec.Code, ran)
+	}
+}
+
+func TestApp_PprofHandlerServesSeparately(t *testing.T) {
+	app := NewApp()
+	h := app.PprofHandler(PprofOptions{Prefix: "/pprof"})
+
+	req := httptest.NewRequest(http.MethodGet, "/pprof/", nil)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("pprof handler status = %d, want 200", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	app.Handler().ServeHTTP(rec, req)
+	if rec.Code == http.StatusOK {
+		t.Fatal("pprof served on the app router too")
+	}
+
+	app.services.pprofAddr = "127.0.0.1:0"
+	config := app.serverConfig(nil)
+	if len(config.Listeners) != 1 || config.Listeners[0].Name != "pprof" || config.Listeners[0].Handler == nil {
+		t.Fatalf("listeners = %+v, want the pprof listener", config.Listeners)
+	}
+}
+
+func TestApp_PprofListenerFromConfigIsGuarded(t *testing.T) {
+	serve := func(token, remote, auth string) int {
+		app := NewApp()
+		app.services.pprofAddr = ":6060"
+		app.services.pprofToken = token
+		req := httptest.NewRequest(http.MethodGet, "/debug/pprof/", nil)
+		req.RemoteAddr = remote
+		if auth != "" {
+			req.Header.Set("Authorization", "Bearer "+auth)
+		}
+		rec := httptest.NewRecorder()
+		app.serverConfig(nil).Listeners[0].Handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+	if code := serve("", "127.0.0.1:1234", ""); code != http.StatusOK {
+		t.Errorf("loopback without token = %d, want 200", code)
+	}
+	if code := serve("", "203.0.113.7:1234", ""); code != http.StatusForbidden {
+		t.Errorf("remote without token = %d, want 403", code)
+	}
+	if code := serve("s3cret", "203.0.113.7:1234", ""); code != http.StatusUnauthorized {
+		t.Errorf("remote without the token = %d, want 401", code)
+	}
+	if code := serve("s3cret", "203.0.113.7:1234", "s3cret"); code != http.StatusOK {
+		t.Errorf("remote with the token = %d, want 200", code)
+	}
+}
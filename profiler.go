@@ -0,0 +1,336 @@
+package golitekit
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"runtime/pprof"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hansir-hsj/GoLiteKit/env"
+	"github.com/hansir-hsj/GoLiteKit/logger"
+)
+
+const (
+	DefaultProfileInterval    = time.Minute
+	DefaultProfileCPUDuration = 10 * time.Second
+	DefaultProfileMaxFiles    = 48
+)
+
+// DefaultProfiles are the profiles a Profiler captures by default.
+var DefaultProfiles = []string{"cpu", "heap"}
+
+// Profile is one captured profile in pprof format.
+type Profile struct {
+	Kind   string // "cpu" or a runtime/pprof profile name such as "heap"
+	Start  time.Time
+	End    time.Time
+	Labels map[string]string
+	Data   []byte
+}
+
+// ProfileSink stores captured profiles.
+type ProfileSink interface {
+	Store(ctx context.Context, p Profile) error
+}
+
+// ProfilerOptions configures NewProfiler.
+type ProfilerOptions struct {
+	// Interval between captures, defaults to DefaultProfileInterval.
+	Interval time.Duration
+	// CPUDuration is how long the CPU profile of each capture runs, defaults
+	// to DefaultProfileCPUDuration and is capped at Interval.
+	CPUDuration time.Duration
+	// Profiles to capture, defaults to DefaultProfiles.
+	Profiles []string
+	// AppName and AppVersion tag every profile as the "app" and "version"
+	// labels; they default to env.AppName and the main module version. The
+	// "glk_version" label is always set.
+	AppName    string
+	AppVersion string
+	// Labels are added to every profile.
+	Labels map[string]string
+	// Sinks receive every profile.
+	Sinks []ProfileSink
+}
+
+// Profiler captures profiles of the process at intervals for post-incident
+// analysis, e.g. into the log dir with DirProfileSink or to Pyroscope with
+// PyroscopeSink.
+type Profiler struct {
+	opts   ProfilerOptions
+	labels map[string]string
+
+	startOnce sync.Once
+	stopOnce  sync.Once
+	stop      chan struct{}
+	done      chan struct{}
+}
+
+// NewProfiler creates a Profiler; Start begins capturing.
+func NewProfiler(opts ...ProfilerOptions) *Profiler {
+	var opt ProfilerOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if opt.Interval <= 0 {
+		opt.Interval = DefaultProfileInterval
+	}
+	if opt.CPUDuration <= 0 {
+		opt.CPUDuration = DefaultProfileCPUDuration
+	}
+	opt.CPUDuration = min(opt.CPUDuration, opt.Interval)
+	if len(opt.Profiles) == 0 {
+		opt.Profiles = DefaultProfiles
+	}
+	if opt.AppName == "" {
+		opt.AppName = env.AppName()
+	}
+	if opt.AppVersion == "" {
+		if info, ok := debug.ReadBuildInfo(); ok {
+			opt.AppVersion = info.Main.Version
+		}
+	}
+
+	labels := map[string]string{"glk_version": Version}
+	for k, v := range opt.Labels {
+		labels[k] = v
+	}
+	if opt.AppName != "" {
+		labels["app"] = opt.AppName
+	}
+	if opt.AppVersion != "" {
+		labels["version"] = opt.AppVersion
+	}
+	return &Profiler{
+		opts:   opt,
+		labels: labels,
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+}
+
+// Start begins capturing in the background; later calls do nothing.
+func (p *Profiler) Start() {
+	p.startOnce.Do(func() {
+		go p.run()
+	})
+}
+
+func (p *Profiler) run() {
+	defer close(p.done)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-p.stop:
+		case <-ctx.Done():
+		}
+		cancel()
+	}()
+
+	ticker := time.NewTicker(p.opts.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := p.Capture(ctx); err != nil && ctx.Err() == nil {
+				logger.Named(logger.AppLoggerName).Warning(ctx, "profile capture failed", "err", err.Error())
+			}
+		}
+	}
+}
+
+// Stop ends capturing, cutting a running CPU profile short, and waits for
+// the capture in progress until ctx is done.
+func (p *Profiler) Stop(ctx context.Context) error {
+	p.stopOnce.Do(func() { close(p.stop) })
+	// A Profiler that never started has nothing to wait for.
+	p.startOnce.Do(func() { close(p.done) })
+	select {
+	case <-p.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Capture takes one round of profiles and stores them in every sink. The
+// CPU profile runs for CPUDuration or until ctx is done.
+func (p *Profiler) Capture(ctx context.Context) error {
+	var errs []error
+	for _, kind := range p.opts.Profiles {
+		profile, err := p.capture(ctx, kind)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", kind, err))
+			continue
+		}
+		for _, sink := range p.opts.Sinks {
+			if err := sink.Store(ctx, profile); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", kind, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (p *Profiler) capture(ctx context.Context, kind string) (Profile, error) {
+	profile := Profile{Kind: kind, Start: time.Now(), Labels: p.labels}
+	var buf bytes.Buffer
+	if kind == "cpu" {
+		// Fails while another CPU profile, e.g. /debug/pprof/profile, runs.
+		if err := pprof.StartCPUProfile(&buf); err != nil {
+			return Profile{}, err
+		}
+		timer := time.NewTimer(p.opts.CPUDuration)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+		}
+		pprof.StopCPUProfile()
+	} else {
+		prof := pprof.Lookup(kind)
+		if prof == nil {
+			return Profile{}, fmt.Errorf("unknown profile")
+		}
+		if err := prof.WriteTo(&buf, 0); err != nil {
+			return Profile{}, err
+		}
+	}
+	profile.End = time.Now()
+	profile.Data = buf.Bytes()
+	return profile, nil
+}
+
+// profilerFromEnv builds the Profiler of [HttpServer.Profiling], or nil
+// when it sets neither dir nor pyroscopeURL.
+func profilerFromEnv() *Profiler {
+	var sinks []ProfileSink
+	if dir := env.ProfileDir(); dir != "" {
+		sinks = append(sinks, DirProfileSink{Dir: dir, MaxFiles: env.ProfileMaxFiles()})
+	}
+	if u := env.PyroscopeURL(); u != "" {
+		sinks = append(sinks, PyroscopeSink{URL: u})
+	}
+	if len(sinks) == 0 {
+		return nil
+	}
+	return NewProfiler(ProfilerOptions{
+		Interval:    env.ProfileInterval(),
+		CPUDuration: env.ProfileCPUDuration(),
+		Profiles:    env.Profiles(),
+		Sinks:       sinks,
+	})
+}
+
+func (a *App) startProfiler() {
+	if p := a.services.Profiler(); p != nil {
+		p.Start()
+	}
+}
+
+// DirProfileSink writes profiles into a directory as
+// <app>-<kind>-<time>.pprof, keeping the newest MaxFiles of each kind.
+type DirProfileSink struct {
+	Dir      string
+	MaxFiles int // defaults to DefaultProfileMaxFiles
+}
+
+func (s DirProfileSink) Store(ctx context.Context, p Profile) error {
+	if err := os.MkdirAll(s.Dir, 0o755); err != nil {
+		return err
+	}
+	prefix := p.Kind + "-"
+	if app := p.Labels["app"]; app != "" {
+		prefix = app + "-" + prefix
+	}
+	name := prefix + p.Start.UTC().Format("20060102T150405.000") + ".pprof"
+	if err := os.WriteFile(filepath.Join(s.Dir, name), p.Data, 0o644); err != nil {
+		return err
+	}
+
+	maxFiles := s.MaxFiles
+	if maxFiles <= 0 {
+		maxFiles = DefaultProfileMaxFiles
+	}
+	entries, err := os.ReadDir(s.Dir)
+	if err != nil {
+		return err
+	}
+	var files []string
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasPrefix(e.Name(), prefix) && strings.HasSuffix(e.Name(), ".pprof") {
+			files = append(files, e.Name())
+		}
+	}
+	// The timestamps sort lexically.
+	sort.Strings(files)
+	for len(files) > maxFiles {
+		if err := os.Remove(filepath.Join(s.Dir, files[0])); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		files = files[1:]
+	}
+	return nil
+}
+
+// PyroscopeSink pushes profiles to the ingest API of a Pyroscope server as
+// <app>.<kind>{labels}.
+type PyroscopeSink struct {
+	URL    string // server base URL, e.g. http://pyroscope:4040
+	Client *http.Client
+}
+
+func (s PyroscopeSink) Store(ctx context.Context, p Profile) error {
+	app := p.Labels["app"]
+	if app == "" {
+		app = "golitekit"
+	}
+	keys := make([]string, 0, len(p.Labels))
+	for k := range p.Labels {
+		if k != "app" {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	tags := make([]string, len(keys))
+	for i, k := range keys {
+		tags[i] = k + "=" + p.Labels[k]
+	}
+
+	q := url.Values{}
+	q.Set("name", app+"."+p.Kind+"{"+strings.Join(tags, ",")+"}")
+	q.Set("from", fmt.Sprint(p.Start.Unix()))
+	q.Set("until", fmt.Sprint(p.End.Unix()))
+	q.Set("format", "pprof")
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimRight(s.URL, "/")+"/ingest?"+q.Encode(), bytes.NewReader(p.Data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("pyroscope ingest: %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package golitekit
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestProfiler_CaptureToDir(t *testing.T) {
+	dir := t.TempDir()
+	p := NewProfiler(ProfilerOptions{
+		CPUDuration: 10 * time.Millisecond,
+		AppName:     "orders",
+		Sinks:       []ProfileSink{DirProfileSink{Dir: dir, MaxFiles: 2}},
+	})
+	for i := 0; i < 3; i++ {
+		if err := p.Capture(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(2 * time.Millisecond)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	counts := map[string]int{}
+	for _, e := range entries {
+		name := e.Name()
+		if !strings.HasSuffix(name, ".pprof") {
+			t.Errorf("unexpected file %s", name)
+			continue
+		}
+		switch {
+		case strings.HasPrefix(name, "orders-cpu-"):
+			counts["cpu"]++
+		case strings.HasPrefix(name, "orders-heap-"):
+			counts["heap"]++
+		default:
+			t.Errorf("unexpected file %s", name)
+		}
+	}
+	if counts["cpu"] != 2 || counts["heap"] != 2 {
+		t.Errorf("files = %v, want the newest 2 of each kind", counts)
+	}
+}
+
+func TestProfiler_PyroscopeSink(t *testing.T) {
+	var gotName, gotFormat string
+	var gotBody int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/ingest" {
+			t.Errorf("path = %s, want /ingest", r.URL.Path)
+		}
+		gotName = r.URL.Query().Get("name")
+		gotFormat = r.URL.Query().Get("format")
+		b, _ := io.ReadAll(r.Body)
+		gotBody = len(b)
+	}))
+	defer srv.Close()
+
+	p := NewProfiler(ProfilerOptions{
+		Profiles:   []string{"heap"},
+		AppName:    "orders",
+		AppVersion: "v1.2.3",
+		Labels:     map[string]string{"region": "eu"},
+		Sinks:      []ProfileSink{PyroscopeSink{URL: srv.URL + "/"}},
+	})
+	if err := p.Capture(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	want := "orders.heap{glk_version=" + Version + ",region=eu,version=v1.2.3}"
+	if gotName != want || gotFormat != "pprof" || gotBody == 0 {
+		t.Errorf("name = %q, format = %q, body = %d bytes; want %q, pprof and a profile", gotName, gotFormat, gotBody, want)
+	}
+}
+
+func TestProfiler_Stop(t *testing.T) {
+	if err := NewProfiler().Stop(context.Background()); err != nil {
+		t.Errorf("Stop before Start = %v", err)
+	}
+
+	p := NewProfiler(ProfilerOptions{
+		Interval: time.Millisecond,
+		Profiles: []string{"cpu"},
+		Sinks:    []ProfileSink{DirProfileSink{Dir: t.TempDir()}},
+	})
+	p.Start()
+	time.Sleep(5 * time.Millisecond)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := p.Stop(ctx); err != nil {
+		t.Errorf("Stop = %v, want the running capture cut short", err)
+	}
+}
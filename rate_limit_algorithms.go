@@ -0,0 +1,201 @@
+package golitekit
+
+import (
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// RateAlgorithm selects how a RateLimiter limits each key. All algorithms
+// admit on average rate requests per second; they differ in how bursts are
+// treated.
+type RateAlgorithm int
+
+const (
+	// TokenBucket admits bursts of up to burst requests and refills at rate.
+	TokenBucket RateAlgorithm = iota
+	// SlidingWindow admits at most burst requests within any window, keeping
+	// the time of each admitted request.
+	SlidingWindow
+	// FixedWindow admits at most burst requests per window, with windows
+	// aligned to when the key was first seen; cheap, but allows up to twice
+	// burst around a window boundary.
+	FixedWindow
+	// LeakyBucket spaces requests 1/rate apart: a request arriving early waits
+	// for its slot, and is rejected when burst requests are already waiting.
+	LeakyBucket
+)
+
+func (a RateAlgorithm) String() string {
+	switch a {
+	case TokenBucket:
+		return "token_bucket"
+	case SlidingWindow:
+		return "sliding_window"
+	case FixedWindow:
+		return "fixed_window"
+	case LeakyBucket:
+		return "leaky_bucket"
+	}
+	return "unknown"
+}
+
+// WithAlgorithm selects the per-key algorithm; the default is TokenBucket.
+// The global limiter is always a token bucket.
+func WithAlgorithm(algorithm RateAlgorithm) RateLimiterOption {
+	return func(opts *RateLimiterOptions) {
+		opts.Algorithm = algorithm
+	}
+}
+
+// WithWindow sets the window of SlidingWindow and FixedWindow, which admit
+// burst requests per window. It defaults to burst/rate, the time the token
+// bucket takes to refill.
+func WithWindow(window time.Duration) RateLimiterOption {
+	return func(opts *RateLimiterOptions) {
+		opts.Window = window
+	}
+}
+
+// keyLimiter limits the requests of one key; *rate.Limiter is the token
+// bucket.
+type keyLimiter interface {
+	Allow() bool
+}
+
+// queueingLimiter is a keyLimiter that can admit a request after a delay.
+type queueingLimiter interface {
+	keyLimiter
+	// reserve claims the next slot and returns how long to wait for it, or
+	// false when the queue is full.
+	reserve(now time.Time) (time.Duration, bool)
+}
+
+func (r *RateLimiter) newKeyLimiter(limit rate.Limit, burst int) keyLimiter {
+	window := r.window
+	if window <= 0 {
+		window = defaultWindow(limit, burst)
+	}
+	switch r.algorithm {
+	case SlidingWindow:
+		return &slidingWindowLimiter{limit: burst, window: window}
+	case FixedWindow:
+		return &fixedWindowLimiter{limit: burst, window: window}
+	case LeakyBucket:
+		return &leakyBucketLimiter{interval: rateInterval(limit), capacity: burst}
+	}
+	return rate.NewLimiter(limit, burst)
+}
+
+// defaultWindow is burst/rate, or a second for an infinite or zero rate.
+func defaultWindow(limit rate.Limit, burst int) time.Duration {
+	if limit <= 0 || limit == rate.Inf || burst <= 0 {
+		return time.Second
+	}
+	return time.Duration(float64(burst) / float64(limit) * float64(time.Second))
+}
+
+func rateInterval(limit rate.Limit) time.Duration {
+	if limit == rate.Inf {
+		return 0
+	}
+	if limit <= 0 {
+		return time.Duration(1<<63 - 1)
+	}
+	return time.Duration(float64(time.Second) / float64(limit))
+}
+
+type slidingWindowLimiter struct {
+	mu     sync.Mutex
+	limit  int
+	window time.Duration
+	// log is a ring of the last limit admission times, oldest at head.
+	log  []time.Time
+	head int
+}
+
+func (l *slidingWindowLimiter) Allow() bool {
+	now := time.Now()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.limit <= 0 {
+		return false
+	}
+	if len(l.log) < l.limit {
+		l.log = append(l.log, now)
+		return true
+	}
+	// the oldest of the last limit admissions must have left the window
+	if now.Sub(l.log[l.head]) < l.window {
+		return false
+	}
+	l.log[l.head] = now
+	l.head = (l.head + 1) % l.limit
+	return true
+}
+
+type fixedWindowLimiter struct {
+	mu     sync.Mutex
+	limit  int
+	window time.Duration
+	start  time.Time
+	count  int
+}
+
+func (l *fixedWindowLimiter) Allow() bool {
+	now := time.Now()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.start.IsZero() || now.Sub(l.start) >= l.window {
+		if !l.start.IsZero() && l.window > 0 {
+			l.start = l.start.Add(now.Sub(l.start) / l.window * l.window)
+		} else {
+			l.start = now
+		}
+		l.count = 0
+	}
+	if l.count >= l.limit {
+		return false
+	}
+	l.count++
+	return true
+}
+
+type leakyBucketLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	capacity int
+	next     time.Time
+}
+
+func (l *leakyBucketLimiter) reserve(now time.Time) (time.Duration, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.capacity <= 0 {
+		return 0, false
+	}
+	slot := l.next
+	if slot.Before(now) {
+		slot = now
+	}
+	wait := slot.Sub(now)
+	// wait/interval requests are ahead in the queue
+	if l.interval > 0 && wait > time.Duration(l.capacity-1)*l.interval {
+		return 0, false
+	}
+	l.next = slot.Add(l.interval)
+	return wait, true
+}
+
+// Allow admits a request only when it need not wait for its slot.
+func (l *leakyBucketLimiter) Allow() bool {
+	now := time.Now()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.capacity <= 0 || l.next.After(now) {
+		return false
+	}
+	l.next = now.Add(l.interval)
+	return true
+}
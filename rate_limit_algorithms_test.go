@@ -0,0 +1,98 @@
+package golitekit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRateLimiter_Algorithms(t *testing.T) {
+	for _, algorithm := range []RateAlgorithm{TokenBucket, SlidingWindow, FixedWindow, LeakyBucket} {
+		t.Run(algorithm.String(), func(t *testing.T) {
+			rl := NewRateLimiter(1, 3, WithAlgorithm(algorithm))
+			limiter, _ := rl.limiterForKey("k")
+			allowed := 0
+			for i := 0; i < 5; i++ {
+				if limiter.Allow() {
+					allowed++
+				}
+			}
+			want := 3
+			if algorithm == LeakyBucket {
+				want = 1 // requests are spaced 1/rate apart
+			}
+			if allowed != want {
+				t.Fatalf("allowed %d of 5, want %d", allowed, want)
+			}
+			if got := rl.Stats().Algorithm; got != algorithm.String() {
+				t.Errorf("Stats().Algorithm = %q", got)
+			}
+		})
+	}
+}
+
+func TestSlidingWindowLimiter(t *testing.T) {
+	l := &slidingWindowLimiter{limit: 2, window: 60 * time.Millisecond}
+	if !l.Allow() || !l.Allow() || l.Allow() {
+		t.Fatal("want 2 of 3 admitted within the window")
+	}
+	time.Sleep(70 * time.Millisecond)
+	if !l.Allow() {
+		t.Fatal("want an admission once the oldest left the window")
+	}
+}
+
+func TestFixedWindowLimiter(t *testing.T) {
+	l := &fixedWindowLimiter{limit: 2, window: 60 * time.Millisecond}
+	if !l.Allow() || !l.Allow() || l.Allow() {
+		t.Fatal("want 2 of 3 admitted within the window")
+	}
+	time.Sleep(70 * time.Millisecond)
+	if !l.Allow() || !l.Allow() || l.Allow() {
+		t.Fatal("want the count reset in the next window")
+	}
+}
+
+func TestLeakyBucketLimiter_Reserve(t *testing.T) {
+	l := &leakyBucketLimiter{interval: 100 * time.Millisecond, capacity: 3}
+	now := time.Now()
+	for i, want := range []time.Duration{0, 100 * time.Millisecond, 200 * time.Millisecond} {
+		wait, ok := l.reserve(now)
+		if !ok || wait != want {
+			t.Fatalf("reserve %d = %v, %v; want %v", i, wait, ok, want)
+		}
+	}
+	if _, ok := l.reserve(now); ok {
+		t.Fatal("reserve beyond capacity succeeded")
+	}
+	if wait, ok := l.reserve(now.Add(150 * time.Millisecond)); !ok || wait != 150*time.Millisecond {
+		t.Fatalf("reserve after leaking = %v, %v", wait, ok)
+	}
+}
+
+func TestRateLimiterAsMiddleware_LeakyBucketWaits(t *testing.T) {
+	rl := NewRateLimiter(20, 2, WithAlgorithm(LeakyBucket)) // one slot per 50ms
+	handler := rl.RateLimiterAsMiddleware(ByIP)(func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		return nil
+	})
+	serve := func() error {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		return handler(req.Context(), httptest.NewRecorder(), req)
+	}
+
+	start := time.Now()
+	if err := serve(); err != nil {
+		t.Fatal(err)
+	}
+	if err := serve(); err != nil {
+		t.Fatalf("queued request rejected: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond {
+		t.Errorf("second request served after %v, want it delayed to its slot", elapsed)
+	}
+	if rl.Stats().Allowed != 2 {
+		t.Errorf("allowed = %d, want 2", rl.Stats().Allowed)
+	}
+}
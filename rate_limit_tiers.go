@@ -0,0 +1,95 @@
+package golitekit
+
+import (
+	"net/http"
+
+	"github.com/hansir-hsj/GoLiteKit/env"
+	"golang.org/x/time/rate"
+)
+
+// TierResolver returns the rate and burst of the key of r, e.g. by the plan
+// of the API key the request carries.
+type TierResolver func(r *http.Request) (rate.Limit, int)
+
+// RateLimitTier is the per-key limit of one tier, such as "free" or "pro".
+type RateLimitTier struct {
+	Rate  rate.Limit
+	Burst int
+}
+
+// WithTierResolver limits each key by the rate and burst resolve returns
+// for its request instead of the limiter's own. A key whose tier changes,
+// e.g. on an upgrade, starts over with a full burst of the new tier.
+func WithTierResolver(resolve TierResolver) RateLimiterOption {
+	return func(opts *RateLimiterOptions) {
+		opts.Tiers = resolve
+		opts.TierOf = nil
+		opts.TenantLimits = false
+	}
+}
+
+// WithTiers limits each key by the tier tierOf names for its request; a
+// request whose tier is "" or missing from tiers gets the limiter's rate and
+// burst. Unless a classifier is set, the tier also names the class counted
+// in Stats.
+func WithTiers(tiers map[string]RateLimitTier, tierOf func(r *http.Request) string) RateLimiterOption {
+	return func(opts *RateLimiterOptions) {
+		opts.Tiers = nil
+		opts.TierTable = tiers
+		opts.TierOf = tierOf
+		opts.TenantLimits = false
+	}
+}
+
+// tierResolver returns the resolver configured by options, with unknown
+// tiers falling back to limit and burst.
+func tierResolver(options RateLimiterOptions, limit rate.Limit, burst int) TierResolver {
+	if options.TenantLimits {
+		return func(r *http.Request) (rate.Limit, int) {
+			if t := TenantFrom(r.Context()); t != nil && t.RateLimit != nil {
+				return t.RateLimit.Rate, t.RateLimit.Burst
+			}
+			return limit, burst
+		}
+	}
+	if options.Tiers != nil || options.TierOf == nil {
+		return options.Tiers
+	}
+	tiers, tierOf := options.TierTable, options.TierOf
+	return func(r *http.Request) (rate.Limit, int) {
+		if tier, ok := tiers[tierOf(r)]; ok {
+			return tier.Rate, tier.Burst
+		}
+		return limit, burst
+	}
+}
+
+// tierClassifier names the class of a request by its tier, counting unknown
+// tiers in DefaultRateLimitClass.
+func tierClassifier(tiers map[string]RateLimitTier, tierOf func(r *http.Request) string) func(r *http.Request) string {
+	return func(r *http.Request) string {
+		name := tierOf(r)
+		if _, ok := tiers[name]; !ok {
+			return ""
+		}
+		return name
+	}
+}
+
+// RateLimitTiersFromEnv returns the tiers of the [HttpServer.RateLimit.tiers]
+// table; a tier without rateBurst gets a burst of its rateLimit.
+func RateLimitTiersFromEnv() map[string]RateLimitTier {
+	envTiers := env.RateTiers()
+	if len(envTiers) == 0 {
+		return nil
+	}
+	tiers := make(map[string]RateLimitTier, len(envTiers))
+	for name, t := range envTiers {
+		burst := t.RateBurst
+		if burst == 0 {
+			burst = t.RateLimit
+		}
+		tiers[name] = RateLimitTier{Rate: rate.Limit(t.RateLimit), Burst: burst}
+	}
+	return tiers
+}
@@ -0,0 +1,90 @@
+package golitekit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"golang.org/x/time/rate"
+)
+
+func TestRateLimiter_Tiers(t *testing.T) {
+	plans := map[string]string{"a": "free", "b": "pro", "c": "unknown"}
+	rl := NewRateLimiter(0, 1, WithTiers(map[string]RateLimitTier{
+		"free": {Rate: 0, Burst: 2},
+		"pro":  {Rate: 0, Burst: 5},
+	}, func(r *http.Request) string {
+		return plans[r.Header.Get("X-API-Key")]
+	}))
+	handler := rl.RateLimiterAsMiddleware(func(r *http.Request) string {
+		return r.Header.Get("X-API-Key")
+	})(func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		return nil
+	})
+	allowed := func(key string, n int) int {
+		ok := 0
+		for i := 0; i < n; i++ {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("X-API-Key", key)
+			if handler(req.Context(), httptest.NewRecorder(), req) == nil {
+				ok++
+			}
+		}
+		return ok
+	}
+
+	if got := allowed("a", 10); got != 2 {
+		t.Errorf("free key allowed %d, want 2", got)
+	}
+	if got := allowed("b", 10); got != 5 {
+		t.Errorf("pro key allowed %d, want 5", got)
+	}
+	if got := allowed("c", 10); got != 1 {
+		t.Errorf("unknown tier allowed %d, want the default burst 1", got)
+	}
+
+	// upgrading the key replaces its exhausted limiter
+	plans["a"] = "pro"
+	if got := allowed("a", 10); got != 5 {
+		t.Errorf("upgraded key allowed %d, want 5", got)
+	}
+
+	stats := rl.Stats()
+	if c := stats.Classes["pro"]; c.Allowed != 10 || c.DeniedKey != 10 {
+		t.Errorf("pro = %+v", c)
+	}
+	if c := stats.Classes[DefaultRateLimitClass]; c.Allowed != 1 || c.DeniedKey != 9 {
+		t.Errorf("default = %+v", c)
+	}
+	if stats.TrackedKeys != 3 {
+		t.Errorf("tracked keys = %d, want 3", stats.TrackedKeys)
+	}
+}
+
+func TestRateLimiter_TierResolver(t *testing.T) {
+	rl := NewRateLimiter(0, 1, WithTierResolver(func(r *http.Request) (rate.Limit, int) {
+		if r.Header.Get("X-API-Key") != "" {
+			return 0, 3
+		}
+		return 0, 1
+	}))
+	handler := rl.RateLimiterAsMiddleware(ByIP)(func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		return nil
+	})
+
+	ok := 0
+	for i := 0; i < 5; i++ {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("X-API-Key", "k")
+		if handler(req.Context(), httptest.NewRecorder(), req) == nil {
+			ok++
+		}
+	}
+	if ok != 3 {
+		t.Errorf("allowed %d, want 3", ok)
+	}
+	if _, counted := rl.Stats().Classes[DefaultRateLimitClass]; !counted {
+		t.Error("a resolver without classifier should count in the default class")
+	}
+}
@@ -1,6 +1,8 @@
 package golitekit
 
 import (
+	"net/http"
+	"sort"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -11,6 +13,18 @@ import (
 const (
 	DefaultRateLimiterTTL     = 10 * time.Minute
 	DefaultRateLimiterMaxKeys = 10000
+	// maxRateLimiterSweepInterval caps how long an idle key outlives its TTL.
+	maxRateLimiterSweepInterval = time.Minute
+
+	// DefaultRateLimitClass is the key class of requests without a
+	// classifier or whose classifier returns "".
+	DefaultRateLimitClass = "default"
+	// MaxRateLimitClasses bounds the key classes counted per limiter; further
+	// classes are counted as OtherRateLimitClass.
+	MaxRateLimitClasses = 64
+	OtherRateLimitClass = "other"
+	// rateLimitTopDenied is the number of keys in RateLimiterStats.TopDenied.
+	rateLimitTopDenied = 10
 )
 
 type RateLimiterOptions struct {
@@ -19,6 +33,23 @@ type RateLimiterOptions struct {
 	GlobalBurst  int
 	TTL          time.Duration
 	MaxKeys      int
+	// Classify names the class of a request, e.g. "anonymous" or "api_key",
+	// for the allowed/denied counters of Stats. Keep classes to a small,
+	// fixed set; never return the key itself.
+	Classify func(r *http.Request) string
+	// Algorithm and Window select how each key is limited, see
+	// WithAlgorithm and WithWindow.
+	Algorithm RateAlgorithm
+	Window    time.Duration
+	// Tiers resolves the rate and burst of each request's key, see
+	// WithTierResolver. Otherwise TierOf names the tier of a request and
+	// TierTable holds the limit of each tier, see WithTiers.
+	Tiers     TierResolver
+	TierTable map[string]RateLimitTier
+	TierOf    func(r *http.Request) string
+	// TenantLimits limits each key by the RateLimit of the request's Tenant,
+	// see WithTenantLimits.
+	TenantLimits bool
 }
 
 type RateLimiterOption func(*RateLimiterOptions)
@@ -31,6 +62,8 @@ func WithGlobalRateLimiter(rate rate.Limit, burst int) RateLimiterOption {
 	}
 }
 
+// WithTTL sets the idle timeout of per-key limiters: a key unused for ttl
+// is purged by the sweep, while active keys are kept however old they are.
 func WithTTL(ttl time.Duration) RateLimiterOption {
 	return func(opts *RateLimiterOptions) {
 		opts.TTL = ttl
@@ -49,9 +82,28 @@ func WithMaxKeys(max int) RateLimiterOption {
 	}
 }
 
+// WithKeyClassifier counts allowed and denied requests per class returned
+// by classify, see RateLimiterOptions.Classify.
+func WithKeyClassifier(classify func(r *http.Request) string) RateLimiterOption {
+	return func(opts *RateLimiterOptions) {
+		opts.Classify = classify
+	}
+}
+
 type limiterEntry struct {
-	limiter  *rate.Limiter
+	limiter  keyLimiter
+	rate     rate.Limit
+	burst    int
 	lastUsed atomic.Int64
+	denied   atomic.Uint64
+}
+
+// rateLimitCounters counts the decisions of one key class.
+type rateLimitCounters struct {
+	allowed        atomic.Uint64
+	deniedGlobal   atomic.Uint64
+	deniedKey      atomic.Uint64
+	deniedCapacity atomic.Uint64
 }
 
 type RateLimiter struct {
@@ -63,7 +115,19 @@ type RateLimiter struct {
 	ttl           time.Duration
 	maxKeys       int
 	enableGlobal  bool
-	cleanCounter  atomic.Int64
+	algorithm     RateAlgorithm
+	window        time.Duration
+
+	// sweeping is set while the sweep goroutine runs; it stops once no key
+	// is left and is restarted by the next new key.
+	sweeping bool
+	closed   bool
+	stop     chan struct{}
+
+	tiers     TierResolver
+	classify  func(r *http.Request) string
+	classesMu sync.RWMutex
+	classes   map[string]*rateLimitCounters
 }
 
 func NewRateLimiter(rat rate.Limit, burst int, opts ...RateLimiterOption) *RateLimiter {
@@ -90,6 +154,15 @@ func NewRateLimiter(rat rate.Limit, burst int, opts ...RateLimiterOption) *RateL
 		ttl:          ttl,
 		maxKeys:      maxKeys,
 		enableGlobal: options.EnableGlobal,
+		algorithm:    options.Algorithm,
+		window:       options.Window,
+		tiers:        tierResolver(options, rat, burst),
+		classify:     options.Classify,
+		classes:      make(map[string]*rateLimitCounters),
+		stop:         make(chan struct{}),
+	}
+	if r.classify == nil && options.Tiers == nil && options.TierOf != nil {
+		r.classify = tierClassifier(options.TierTable, options.TierOf)
 	}
 	if options.EnableGlobal {
 		r.globalLimiter = rate.NewLimiter(options.GlobalRate, options.GlobalBurst)
@@ -98,19 +171,57 @@ func NewRateLimiter(rat rate.Limit, burst int, opts ...RateLimiterOption) *RateL
 	return r
 }
 
-func (r *RateLimiter) limiterForKey(key string) (*rate.Limiter, bool) {
+// counters returns the counters of the class of req.
+func (r *RateLimiter) counters(req *http.Request) *rateLimitCounters {
+	class := DefaultRateLimitClass
+	if r.classify != nil {
+		if c := r.classify(req); c != "" {
+			class = c
+		}
+	}
+
+	r.classesMu.RLock()
+	c := r.classes[class]
+	r.classesMu.RUnlock()
+	if c != nil {
+		return c
+	}
+
+	r.classesMu.Lock()
+	defer r.classesMu.Unlock()
+	if c = r.classes[class]; c != nil {
+		return c
+	}
+	if len(r.classes) >= MaxRateLimitClasses {
+		class = OtherRateLimitClass
+		if c = r.classes[class]; c != nil {
+			return c
+		}
+	}
+	c = &rateLimitCounters{}
+	r.classes[class] = c
+	return c
+}
+
+func (r *RateLimiter) limiterForKey(key string) (keyLimiter, bool) {
+	entry, ok := r.entryForKey(key, r.rate, r.burst)
+	if !ok {
+		return nil, false
+	}
+	return entry.limiter, true
+}
+
+// entryForKey returns the limiter of key, created with limit and burst. A
+// key whose tier changed gets a new limiter with the new limit.
+func (r *RateLimiter) entryForKey(key string, limit rate.Limit, burst int) (*limiterEntry, bool) {
 	now := time.Now().UnixNano()
 
 	r.mu.RLock()
 	entry, exists := r.limiters[key]
-	if exists {
+	if exists && entry.rate == limit && entry.burst == burst {
 		entry.lastUsed.Store(now)
 		r.mu.RUnlock()
-
-		if r.ttl > 0 && r.cleanCounter.Add(1)%1000 == 0 {
-			go r.cleanExpired()
-		}
-		return entry.limiter, true
+		return entry, true
 	}
 	r.mu.RUnlock()
 
@@ -118,12 +229,12 @@ func (r *RateLimiter) limiterForKey(key string) (*rate.Limiter, bool) {
 	defer r.mu.Unlock()
 
 	entry, exists = r.limiters[key]
-	if exists {
+	if exists && entry.rate == limit && entry.burst == burst {
 		entry.lastUsed.Store(now)
-		return entry.limiter, true
+		return entry, true
 	}
 
-	if len(r.limiters) >= r.maxKeys {
+	if !exists && len(r.limiters) >= r.maxKeys {
 		if r.ttl > 0 {
 			r.cleanExpiredLocked(now)
 		}
@@ -133,12 +244,41 @@ func (r *RateLimiter) limiterForKey(key string) (*rate.Limiter, bool) {
 	}
 
 	entry = &limiterEntry{
-		limiter: rate.NewLimiter(r.rate, r.burst),
+		limiter: r.newKeyLimiter(limit, burst),
+		rate:    limit,
+		burst:   burst,
 	}
 	entry.lastUsed.Store(now)
 	r.limiters[key] = entry
+	if r.ttl > 0 && !r.sweeping && !r.closed {
+		r.sweeping = true
+		go r.sweep()
+	}
 
-	return entry.limiter, true
+	return entry, true
+}
+
+// sweep purges idle keys every half TTL (at most every minute) until no key
+// is left or the limiter is closed.
+func (r *RateLimiter) sweep() {
+	interval := min(r.ttl/2, maxRateLimiterSweepInterval)
+	ticker := time.NewTicker(max(interval, time.Millisecond))
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.stop:
+			return
+		case <-ticker.C:
+		}
+		r.mu.Lock()
+		r.cleanExpiredLocked(time.Now().UnixNano())
+		if len(r.limiters) == 0 {
+			r.sweeping = false
+			r.mu.Unlock()
+			return
+		}
+		r.mu.Unlock()
+	}
 }
 
 func (r *RateLimiter) cleanExpired() {
@@ -148,6 +288,17 @@ func (r *RateLimiter) cleanExpired() {
 	r.cleanExpiredLocked(time.Now().UnixNano())
 }
 
+// Close stops the sweep of idle keys. The limiter keeps working, but idle
+// keys are then only purged when MaxKeys is reached.
+func (r *RateLimiter) Close() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.closed {
+		r.closed = true
+		close(r.stop)
+	}
+}
+
 func (r *RateLimiter) cleanExpiredLocked(now int64) {
 	if r.ttl <= 0 {
 		return
@@ -160,3 +311,114 @@ func (r *RateLimiter) cleanExpiredLocked(now int64) {
 		}
 	}
 }
+
+// RateLimiterStats is a point-in-time view of a RateLimiter. The counters
+// only grow; Reset leaves them unchanged.
+type RateLimiterStats struct {
+	Algorithm   string              `json:"algorithm"`
+	TrackedKeys int                 `json:"tracked_keys"`
+	MaxKeys     int                 `json:"max_keys"`
+	Rate        float64             `json:"rate"`
+	Burst       int                 `json:"burst"`
+	TTL         time.Duration       `json:"ttl"`
+	Global      *GlobalLimiterStats `json:"global,omitempty"`
+
+	Allowed uint64 `json:"allowed"`
+	Denied  uint64 `json:"denied"`
+	// Classes holds the counters per key class, see WithKeyClassifier.
+	Classes map[string]RateLimitClassStats `json:"classes,omitempty"`
+	// TopDenied lists the tracked keys denied most often, most first.
+	TopDenied []RateLimitedKey `json:"top_denied,omitempty"`
+}
+
+// RateLimitClassStats counts the decisions for one key class. Denials are
+// split by the limit that rejected the request.
+type RateLimitClassStats struct {
+	Allowed        uint64 `json:"allowed"`
+	DeniedGlobal   uint64 `json:"denied_global"`
+	DeniedKey      uint64 `json:"denied_key"`
+	DeniedCapacity uint64 `json:"denied_capacity"`
+}
+
+// Denied returns the denials of the class.
+func (s RateLimitClassStats) Denied() uint64 {
+	return s.DeniedGlobal + s.DeniedKey + s.DeniedCapacity
+}
+
+// RateLimitedKey is a tracked key and the number of its denied requests.
+type RateLimitedKey struct {
+	Key    string `json:"key"`
+	Denied uint64 `json:"denied"`
+}
+
+// GlobalLimiterStats describes the shared limiter applied before per-key limits.
+type GlobalLimiterStats struct {
+	Rate   float64 `json:"rate"`
+	Burst  int     `json:"burst"`
+	Tokens float64 `json:"tokens"`
+}
+
+// Stats returns the current limiter configuration, the number of tracked
+// keys, the allowed/denied counters and the keys throttled most.
+func (r *RateLimiter) Stats() RateLimiterStats {
+	r.mu.RLock()
+	keys := len(r.limiters)
+	var top []RateLimitedKey
+	for key, entry := range r.limiters {
+		if denied := entry.denied.Load(); denied > 0 {
+			top = append(top, RateLimitedKey{Key: key, Denied: denied})
+		}
+	}
+	r.mu.RUnlock()
+	sort.Slice(top, func(i, j int) bool {
+		if top[i].Denied != top[j].Denied {
+			return top[i].Denied > top[j].Denied
+		}
+		return top[i].Key < top[j].Key
+	})
+	if len(top) > rateLimitTopDenied {
+		top = top[:rateLimitTopDenied]
+	}
+
+	stats := RateLimiterStats{
+		Algorithm:   r.algorithm.String(),
+		TrackedKeys: keys,
+		MaxKeys:     r.maxKeys,
+		Rate:        float64(r.rate),
+		Burst:       r.burst,
+		TTL:         r.ttl,
+		TopDenied:   top,
+	}
+	r.classesMu.RLock()
+	if len(r.classes) > 0 {
+		stats.Classes = make(map[string]RateLimitClassStats, len(r.classes))
+	}
+	for class, c := range r.classes {
+		cs := RateLimitClassStats{
+			Allowed:        c.allowed.Load(),
+			DeniedGlobal:   c.deniedGlobal.Load(),
+			DeniedKey:      c.deniedKey.Load(),
+			DeniedCapacity: c.deniedCapacity.Load(),
+		}
+		stats.Classes[class] = cs
+		stats.Allowed += cs.Allowed
+		stats.Denied += cs.Denied()
+	}
+	r.classesMu.RUnlock()
+	if r.globalLimiter != nil {
+		stats.Global = &GlobalLimiterStats{
+			Rate:   float64(r.globalLimiter.Limit()),
+			Burst:  r.globalLimiter.Burst(),
+			Tokens: r.globalLimiter.Tokens(),
+		}
+	}
+	return stats
+}
+
+// Reset forgets every per-key limiter, so all clients start again with a
+// full burst. The global limiter is left unchanged.
+func (r *RateLimiter) Reset() {
+	r.mu.Lock()
+	r.limiters = make(map[string]*limiterEntry)
+	r.mu.Unlock()
+}
@@ -4,35 +4,73 @@ import (
 	"context"
 	"net"
 	"net/http"
+	"time"
 )
 
 // RateLimiterAsMiddleware returns a middleware that enforces rate limits using keyFunc.
 func (r *RateLimiter) RateLimiterAsMiddleware(keyFunc func(r *http.Request) string) Middleware {
 	return func(next Handler) Handler {
 		return func(ctx context.Context, w http.ResponseWriter, req *http.Request) error {
+			counters := r.counters(req)
 			if r.enableGlobal && r.globalLimiter != nil {
 				if !r.globalLimiter.Allow() {
+					counters.deniedGlobal.Add(1)
 					return ErrTooManyRequests("Global rate limit exceeded", nil)
 				}
 			}
 
 			if keyFunc != nil {
 				key := keyFunc(req)
-				limiter, ok := r.limiterForKey(key)
+				limit, burst := r.rate, r.burst
+				if r.tiers != nil {
+					limit, burst = r.tiers(req)
+				}
+				entry, ok := r.entryForKey(key, limit, burst)
 				if !ok {
+					counters.deniedCapacity.Add(1)
 					return ErrTooManyRequests("Rate limiter key capacity exceeded", nil)
 				}
 
-				if !limiter.Allow() {
-					return ErrTooManyRequests("Rate limit exceeded", nil)
+				if err := waitForLimiter(ctx, entry.limiter); err != nil {
+					entry.denied.Add(1)
+					counters.deniedKey.Add(1)
+					return err
 				}
 			}
 
+			counters.allowed.Add(1)
 			return next(ctx, w, req)
 		}
 	}
 }
 
+// waitForLimiter admits a request through l, waiting for its slot when l
+// queues requests (LeakyBucket).
+func waitForLimiter(ctx context.Context, l keyLimiter) error {
+	q, ok := l.(queueingLimiter)
+	if !ok {
+		if !l.Allow() {
+			return ErrTooManyRequests("Rate limit exceeded", nil)
+		}
+		return nil
+	}
+	wait, ok := q.reserve(time.Now())
+	if !ok {
+		return ErrTooManyRequests("Rate limit exceeded", nil)
+	}
+	if wait <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ErrTooManyRequests("Rate limit exceeded", ctx.Err())
+	}
+}
+
 // ByIP returns the client IP address (without port) for use as a rate limiter key.
 func ByIP(r *http.Request) string {
 	host, _, err := net.SplitHostPort(r.RemoteAddr)
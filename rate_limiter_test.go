@@ -397,3 +397,80 @@ func TestByPath(t *testing.T) {
 		t.Errorf("key = %s, want /api/users", key)
 	}
 }
+
+func TestRateLimiter_StatsCounters(t *testing.T) {
+	rl := NewRateLimiter(0, 1, WithKeyClassifier(func(r *http.Request) string {
+		if r.Header.Get("X-API-Key") != "" {
+			return "api_key"
+		}
+		return "anonymous"
+	}))
+	handler := rl.RateLimiterAsMiddleware(ByIP)(func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		return nil
+	})
+	serve := func(ip, apiKey string) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = ip + ":1234"
+		if apiKey != "" {
+			req.Header.Set("X-API-Key", apiKey)
+		}
+		_ = handler(req.Context(), httptest.NewRecorder(), req)
+	}
+	for i := 0; i < 4; i++ {
+		serve("10.0.0.1", "")
+	}
+	for i := 0; i < 2; i++ {
+		serve("10.0.0.2", "k")
+	}
+
+	stats := rl.Stats()
+	if stats.Allowed != 2 || stats.Denied != 4 {
+		t.Fatalf("allowed/denied = %d/%d, want 2/4", stats.Allowed, stats.Denied)
+	}
+	if c := stats.Classes["anonymous"]; c.Allowed != 1 || c.DeniedKey != 3 {
+		t.Errorf("anonymous = %+v", c)
+	}
+	if c := stats.Classes["api_key"]; c.Allowed != 1 || c.DeniedKey != 1 {
+		t.Errorf("api_key = %+v", c)
+	}
+	want := []RateLimitedKey{{Key: "10.0.0.1", Denied: 3}, {Key: "10.0.0.2", Denied: 1}}
+	if len(stats.TopDenied) != 2 || stats.TopDenied[0] != want[0] || stats.TopDenied[1] != want[1] {
+		t.Errorf("top denied = %+v, want %+v", stats.TopDenied, want)
+	}
+}
+
+func TestRateLimiter_SweepPurgesIdleKeys(t *testing.T) {
+	rl := NewRateLimiter(10, 5, WithTTL(40*time.Millisecond))
+	defer rl.Close()
+
+	rl.limiterForKey("idle")
+	deadline := time.Now().Add(150 * time.Millisecond)
+	for time.Now().Before(deadline) {
+		rl.limiterForKey("active")
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	rl.mu.RLock()
+	_, idle := rl.limiters["idle"]
+	_, active := rl.limiters["active"]
+	rl.mu.RUnlock()
+	if idle || !active {
+		t.Fatalf("idle kept = %v, active kept = %v; want only the active key", idle, active)
+	}
+
+	// the sweep stops once every key has expired and restarts on a new key
+	time.Sleep(150 * time.Millisecond)
+	rl.mu.RLock()
+	sweeping, keys := rl.sweeping, len(rl.limiters)
+	rl.mu.RUnlock()
+	if sweeping || keys != 0 {
+		t.Fatalf("sweeping = %v with %d keys after all expired", sweeping, keys)
+	}
+	rl.limiterForKey("again")
+	rl.mu.RLock()
+	sweeping = rl.sweeping
+	rl.mu.RUnlock()
+	if !sweeping {
+		t.Fatal("sweep not restarted for a new key")
+	}
+}
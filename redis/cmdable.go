@@ -0,0 +1,26 @@
+package redis
+
+import (
+	"context"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Cmdable is the command interface of go-redis clients, pipelines and
+// transactions. Code depending on it instead of *redis.Client can be unit
+// tested with a fake or miniredis.
+type Cmdable = redis.Cmdable
+
+type cmdableKey struct{}
+
+// WithRedis returns a copy of ctx carrying c, which Context.Cmdable and
+// controllers prefer over the app's Redis client.
+func WithRedis(ctx context.Context, c Cmdable) context.Context {
+	return context.WithValue(ctx, cmdableKey{}, c)
+}
+
+// FromContext returns the Cmdable set by WithRedis, or nil.
+func FromContext(ctx context.Context) Cmdable {
+	c, _ := ctx.Value(cmdableKey{}).(Cmdable)
+	return c
+}
@@ -42,6 +42,12 @@ type Config struct {
 	RConfig `toml:"redis"`
 }
 
+// ConfigEnvNamespace names the environment variable overrides of the file
+// like GLK_REDIS_HOST, whatever the file is called.
+func (c *Config) ConfigEnvNamespace() string {
+	return "redis"
+}
+
 func parse(conf string) (*Config, error) {
 	var redisConfig Config
 	if err := config.Parse(conf, &redisConfig); err != nil {
@@ -55,6 +61,9 @@ func buildOptions(cfg *Config) redis.Options {
 		Addr:     fmt.Sprintf("%s:%d", cfg.Host, cfg.Port),
 		Password: cfg.Password,
 		DB:       cfg.DB,
+		// Bound commands by the caller's context deadline (e.g. the request
+		// timeout) instead of only ReadTimeout/WriteTimeout.
+		ContextTimeoutEnabled: true,
 	}
 
 	if cfg.Username != "" {
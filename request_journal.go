@@ -0,0 +1,266 @@
+package golitekit
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	DefaultJournalSlots = 1024
+
+	journalMagic      = "GLKJRNL1"
+	journalHeaderSize = 64
+	journalSlotSize   = 256
+	journalSlotHeader = 11 // state(1) + start unix nanos(8) + payload length(2)
+)
+
+// JournalEntry is a request that was in flight when the journal was written.
+type JournalEntry struct {
+	Method string    `json:"method"`
+	Route  string    `json:"route"`
+	LogID  string    `json:"logid,omitempty"`
+	Start  time.Time `json:"start"`
+}
+
+// RequestJournalOptions configures OpenRequestJournal.
+type RequestJournalOptions struct {
+	Path  string // journal file, required
+	Slots int    // concurrent requests tracked, defaults to DefaultJournalSlots
+}
+
+// RequestJournal records a summary of every in-flight request in a fixed-size
+// ring file (memory-mapped where supported). Entries are cleared when the
+// request finishes, so after a crash the file holds exactly the requests that
+// were running when the process died. Requests beyond Slots concurrent ones
+// are not recorded.
+type RequestJournal struct {
+	store     journalStore
+	free      chan int
+	recovered []JournalEntry
+	dropped   atomic.Int64
+
+	// mu guards the mapping: slot writes share it, Close takes it exclusively.
+	mu     sync.RWMutex
+	closed bool
+}
+
+// journalStore is the backing memory of the journal file. sync persists a
+// byte range when the memory is not shared with the file.
+type journalStore interface {
+	data() []byte
+	sync(off, n int) error
+	close() error
+}
+
+// OpenRequestJournal opens or creates the journal at opts.Path. Entries left
+// active by a previous process are available from Recovered; the file is
+// then reset for this process.
+func OpenRequestJournal(opts RequestJournalOptions) (*RequestJournal, error) {
+	if opts.Path == "" {
+		return nil, errors.New("golitekit: request journal path is required")
+	}
+	if opts.Slots <= 0 {
+		opts.Slots = DefaultJournalSlots
+	}
+
+	recovered := readJournalFile(opts.Path)
+	size := journalHeaderSize + opts.Slots*journalSlotSize
+	store, err := openJournalStore(opts.Path, size)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := store.data()
+	clear(buf)
+	copy(buf, journalMagic)
+	binary.LittleEndian.PutUint32(buf[8:], uint32(opts.Slots))
+	binary.LittleEndian.PutUint32(buf[12:], journalSlotSize)
+	if err := store.sync(0, len(buf)); err != nil {
+		_ = store.close()
+		return nil, err
+	}
+
+	j := &RequestJournal{
+		store:     store,
+		free:      make(chan int, opts.Slots),
+		recovered: recovered,
+	}
+	for i := 0; i < opts.Slots; i++ {
+		j.free <- i
+	}
+	return j, nil
+}
+
+// readJournalFile returns the active entries of an existing journal file, or
+// nil when the file is missing or not a journal.
+func readJournalFile(path string) []JournalEntry {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	return parseJournal(buf)
+}
+
+func parseJournal(buf []byte) []JournalEntry {
+	if len(buf) < journalHeaderSize || string(buf[:8]) != journalMagic {
+		return nil
+	}
+	slots := int(binary.LittleEndian.Uint32(buf[8:]))
+	slotSize := int(binary.LittleEndian.Uint32(buf[12:]))
+	if slotSize <= journalSlotHeader || len(buf) < journalHeaderSize+slots*slotSize {
+		return nil
+	}
+	var entries []JournalEntry
+	for i := 0; i < slots; i++ {
+		slot := buf[journalHeaderSize+i*slotSize : journalHeaderSize+(i+1)*slotSize]
+		if slot[0] != 1 {
+			continue
+		}
+		n := int(binary.LittleEndian.Uint16(slot[9:]))
+		if journalSlotHeader+n > len(slot) {
+			continue
+		}
+		parts := strings.SplitN(string(slot[journalSlotHeader:journalSlotHeader+n]), "\x00", 3)
+		for len(parts) < 3 {
+			parts = append(parts, "")
+		}
+		entries = append(entries, JournalEntry{
+			Method: parts[0],
+			Route:  parts[1],
+			LogID:  parts[2],
+			Start:  time.Unix(0, int64(binary.LittleEndian.Uint64(slot[1:]))),
+		})
+	}
+	return entries
+}
+
+// Recovered returns the requests that were in flight when the previous
+// process using this journal stopped without finishing them.
+func (j *RequestJournal) Recovered() []JournalEntry {
+	return append([]JournalEntry(nil), j.recovered...)
+}
+
+// Dropped returns how many requests were not recorded because every slot
+// was in use.
+func (j *RequestJournal) Dropped() int64 {
+	return j.dropped.Load()
+}
+
+// begin records an in-flight request and returns its slot, or -1 when the
+// journal is full.
+func (j *RequestJournal) begin(e JournalEntry) int {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+	if j.closed {
+		return -1
+	}
+	var slot int
+	select {
+	case slot = <-j.free:
+	default:
+		j.dropped.Add(1)
+		return -1
+	}
+
+	payload := []byte(e.Method + "\x00" + e.Route + "\x00" + e.LogID)
+	if len(payload) > journalSlotSize-journalSlotHeader {
+		payload = payload[:journalSlotSize-journalSlotHeader]
+	}
+	off := journalHeaderSize + slot*journalSlotSize
+	buf := j.store.data()[off : off+journalSlotSize]
+	// Write the payload before flipping the state byte so a crash never
+	// exposes a half-written active entry.
+	copy(buf[journalSlotHeader:], payload)
+	clear(buf[journalSlotHeader+len(payload):])
+	binary.LittleEndian.PutUint64(buf[1:], uint64(e.Start.UnixNano()))
+	binary.LittleEndian.PutUint16(buf[9:], uint16(len(payload)))
+	buf[0] = 1
+	_ = j.store.sync(off, journalSlotSize)
+	return slot
+}
+
+// end clears a slot returned by begin.
+func (j *RequestJournal) end(slot int) {
+	if slot < 0 {
+		return
+	}
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+	if j.closed {
+		return
+	}
+	off := journalHeaderSize + slot*journalSlotSize
+	j.store.data()[off] = 0
+	_ = j.store.sync(off, 1)
+	j.free <- slot
+}
+
+// Active returns the requests currently recorded in the journal.
+func (j *RequestJournal) Active() []JournalEntry {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+	if j.closed {
+		return nil
+	}
+	return parseJournal(bytes.Clone(j.store.data()))
+}
+
+// Middleware records every request passing through it. Place it after
+// LogIDMiddleware so entries carry the log ID.
+func (j *RequestJournal) Middleware() Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			route := r.Pattern
+			if route == "" {
+				route = r.URL.Path
+			}
+			slot := j.begin(JournalEntry{
+				Method: r.Method,
+				Route:  route,
+				LogID:  EnsureLogID(ctx),
+				Start:  time.Now(),
+			})
+			defer j.end(slot)
+			return next(ctx, w, r)
+		}
+	}
+}
+
+// Close releases the journal file. Requests still in flight stay recorded.
+func (j *RequestJournal) Close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.closed {
+		return nil
+	}
+	j.closed = true
+	return j.store.close()
+}
+
+// WithRequestJournal records in-flight requests in j; entries recovered from
+// a previous crash are logged when the app is created.
+func WithRequestJournal(j *RequestJournal) ServiceOption {
+	return func(s *Services) { s.journal = j }
+}
+
+// reportRecoveredRequests logs the requests that were in flight when the
+// previous process died.
+func reportRecoveredRequests(s *Services) {
+	j := s.RequestJournal()
+	if j == nil || s.Logger() == nil {
+		return
+	}
+	ctx := context.Background()
+	for _, e := range j.Recovered() {
+		s.Logger().Warning(ctx, "request in flight at previous crash: %s %s logid=%s started=%s",
+			e.Method, e.Route, e.LogID, e.Start.Format(time.RFC3339Nano))
+	}
+}
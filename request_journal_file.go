@@ -0,0 +1,33 @@
+//go:build !unix
+
+package golitekit
+
+import "os"
+
+// fileJournalStore keeps the journal in memory and writes every changed
+// range through to the file on platforms without syscall.Mmap.
+type fileJournalStore struct {
+	f   *os.File
+	buf []byte
+}
+
+func openJournalStore(path string, size int) (journalStore, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	if err := f.Truncate(int64(size)); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &fileJournalStore{f: f, buf: make([]byte, size)}, nil
+}
+
+func (s *fileJournalStore) data() []byte { return s.buf }
+
+func (s *fileJournalStore) sync(off, n int) error {
+	_, err := s.f.WriteAt(s.buf[off:off+n], int64(off))
+	return err
+}
+
+func (s *fileJournalStore) close() error { return s.f.Close() }
@@ -0,0 +1,83 @@
+package golitekit
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+)
+
+func TestRequestJournal_RecordsInFlightRequests(t *testing.T) {
+	j, err := OpenRequestJournal(RequestJournalOptions{Path: filepath.Join(t.TempDir(), "journal"), Slots: 4})
+	if err != nil {
+		t.Fatalf("OpenRequestJournal: %v", err)
+	}
+	defer j.Close()
+
+	var active []JournalEntry
+	r := newTestRouter()
+	r.Use(LogIDMiddleware(), j.Middleware())
+	r.GET("/users/{id}", HandlerFunc(func(ctx *Context) error {
+		active = j.Active()
+		return ctx.String(http.StatusOK, "ok")
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/users/7", nil)
+	req.Header.Set(LogIDHeader, "journal-1")
+	r.Handler().ServeHTTP(httptest.NewRecorder(), req)
+
+	if len(active) != 1 || active[0].Route != "GET /users/{id}" || active[0].LogID != "journal-1" || active[0].Method != http.MethodGet {
+		t.Fatalf("active during request = %+v", active)
+	}
+	if got := j.Active(); len(got) != 0 {
+		t.Fatalf("active after request = %+v, want none", got)
+	}
+}
+
+func TestRequestJournal_RecoversEntriesAfterCrash(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal")
+	j, err := OpenRequestJournal(RequestJournalOptions{Path: path, Slots: 2})
+	if err != nil {
+		t.Fatalf("OpenRequestJournal: %v", err)
+	}
+	// Simulate a crash: the request never finishes and the journal is not
+	// cleaned up.
+	j.begin(JournalEntry{Method: http.MethodPost, Route: "POST /orders", LogID: "abc"})
+	j.begin(JournalEntry{Method: http.MethodGet, Route: "GET /slow", LogID: "def"})
+	if slot := j.begin(JournalEntry{Method: http.MethodGet, Route: "GET /dropped"}); slot != -1 || j.Dropped() != 1 {
+		t.Fatalf("full journal slot = %d dropped = %d", slot, j.Dropped())
+	}
+	if err := j.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	reopened, err := OpenRequestJournal(RequestJournalOptions{Path: path, Slots: 2})
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	defer reopened.Close()
+	recovered := reopened.Recovered()
+	if len(recovered) != 2 || recovered[0].Route != "POST /orders" || recovered[1].LogID != "def" {
+		t.Fatalf("recovered = %+v", recovered)
+	}
+	if got := reopened.Active(); len(got) != 0 {
+		t.Fatalf("reopened journal not reset: %+v", got)
+	}
+
+	l := &journalLogger{}
+	NewApp(WithRequestJournal(reopened), WithLogger(l))
+	if len(l.warnings) != 2 {
+		t.Fatalf("logged %d recovered requests, want 2: %v", len(l.warnings), l.warnings)
+	}
+}
+
+type journalLogger struct {
+	captureLogger
+	warnings []string
+}
+
+func (l *journalLogger) Warning(ctx context.Context, msg string, args ...any) {
+	l.warnings = append(l.warnings, fmt.Sprintf(msg, args...))
+}
@@ -3,10 +3,10 @@ package golitekit
 import (
 	"bufio"
 	"bytes"
-	"fmt"
 	"net"
 	"net/http"
 	"sync"
+	"time"
 )
 
 const DefaultDeferredResponseBufferLimit = 1 << 20
@@ -24,12 +24,47 @@ type deferredResponseWriter struct {
 	mu              sync.Mutex
 }
 
+// Caps on the writers kept by deferredWriterPool, so one large response
+// does not pin its buffer or header map for the life of the process.
+const (
+	maxPooledDeferredBuffer  = 64 << 10
+	maxPooledDeferredHeaders = 32
+)
+
+// deferredWriterPool recycles the deferred writers of ErrorHandlerMiddleware.
+var deferredWriterPool = sync.Pool{
+	New: func() any {
+		return &deferredResponseWriter{header: make(http.Header)}
+	},
+}
+
+// newDeferredResponseWriter returns a writer from deferredWriterPool; hand
+// it back with ReleaseTo once the response is done.
 func newDeferredResponseWriter(w http.ResponseWriter) *deferredResponseWriter {
-	return &deferredResponseWriter{
-		ResponseWriter: w,
-		header:         make(http.Header),
-		statusCode:     http.StatusOK,
-		bufferLimit:    DefaultDeferredResponseBufferLimit,
+	d := deferredWriterPool.Get().(*deferredResponseWriter)
+	d.ResponseWriter = w
+	d.statusCode = http.StatusOK
+	d.bufferLimit = DefaultDeferredResponseBufferLimit
+	return d
+}
+
+// ReleaseTo clears d and puts it into pool, unless its buffer or header map
+// outgrew the pooling caps. d must not be used afterwards.
+func (d *deferredResponseWriter) ReleaseTo(pool *sync.Pool) {
+	d.mu.Lock()
+	keep := d.buffer.Cap() <= maxPooledDeferredBuffer && len(d.header) <= maxPooledDeferredHeaders
+	d.ResponseWriter = nil
+	d.buffer.Reset()
+	clear(d.header)
+	d.statusCode = http.StatusOK
+	d.bufferLimit = DefaultDeferredResponseBufferLimit
+	d.isCommitted = false
+	d.isFlushed = false
+	d.isHeaderWritten = false
+	d.isHijacked = false
+	d.mu.Unlock()
+	if keep {
+		pool.Put(d)
 	}
 }
 
@@ -72,16 +107,45 @@ func (d *deferredResponseWriter) WriteHeader(code int) {
 	d.mu.Lock()
 	defer d.mu.Unlock()
 
-	if d.isHeaderWritten {
+	if d.isHijacked {
 		return
 	}
-	if d.isHijacked {
+	if isInformational(code) {
+		d.writeInformationalLocked(code)
+		return
+	}
+	if d.isHeaderWritten {
 		return
 	}
 	d.isHeaderWritten = true
 	d.statusCode = code
 }
 
+// isInformational reports whether code is a 1xx response sent ahead of the
+// final one, such as 103 Early Hints. 101 ends the exchange and is not.
+func isInformational(code int) bool {
+	return code >= 100 && code < 200 && code != http.StatusSwitchingProtocols
+}
+
+// writeInformationalLocked sends a 1xx response with the headers set so far
+// at once, leaving the final response buffered.
+func (d *deferredResponseWriter) writeInformationalLocked(code int) {
+	if d.isCommitted {
+		d.ResponseWriter.WriteHeader(code)
+		return
+	}
+	header := d.ResponseWriter.Header()
+	saved := header.Clone()
+	for k, v := range d.header {
+		header[k] = v
+	}
+	d.ResponseWriter.WriteHeader(code)
+	clear(header)
+	for k, v := range saved {
+		header[k] = v
+	}
+}
+
 // Commit writes cached response to the actual ResponseWriter.
 func (d *deferredResponseWriter) Commit() error {
 	d.mu.Lock()
@@ -134,11 +198,17 @@ func (d *deferredResponseWriter) Reset() {
 }
 
 func (d *deferredResponseWriter) Flush() {
+	_ = d.FlushError()
+}
+
+// FlushError commits the buffered response and flushes the underlying
+// writer, reporting its error; http.ResponseController.Flush calls it.
+func (d *deferredResponseWriter) FlushError() error {
 	d.mu.Lock()
 	defer d.mu.Unlock()
 
 	if d.isHijacked {
-		return
+		return http.ErrHijacked
 	}
 	if !d.isFlushed {
 		// First flush: commit buffered headers/body and switch to streaming pass-through.
@@ -151,14 +221,15 @@ func (d *deferredResponseWriter) Flush() {
 		}
 		d.ResponseWriter.WriteHeader(d.statusCode)
 		if d.buffer.Len() > 0 {
-			_, _ = d.ResponseWriter.Write(d.buffer.Bytes())
+			_, err := d.ResponseWriter.Write(d.buffer.Bytes())
 			d.buffer.Reset()
+			if err != nil {
+				return err
+			}
 		}
 	}
 
-	if f, ok := d.ResponseWriter.(http.Flusher); ok {
-		f.Flush()
-	}
+	return http.NewResponseController(d.ResponseWriter).Flush()
 }
 
 func (d *deferredResponseWriter) IsFlushed() bool {
@@ -168,25 +239,151 @@ func (d *deferredResponseWriter) IsFlushed() bool {
 }
 
 func (d *deferredResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
-	if hj, ok := d.ResponseWriter.(http.Hijacker); ok {
-		conn, rw, err := hj.Hijack()
-		if err != nil {
-			return nil, nil, err
-		}
-		d.mu.Lock()
-		d.isHijacked = true
-		d.buffer.Reset()
-		d.mu.Unlock()
-		return conn, rw, nil
+	conn, rw, err := http.NewResponseController(d.ResponseWriter).Hijack()
+	if err != nil {
+		return nil, nil, err
 	}
-
-	return nil, nil, fmt.Errorf("underlying ResponseWriter does not support Hijack")
+	d.mu.Lock()
+	d.isHijacked = true
+	d.buffer.Reset()
+	d.mu.Unlock()
+	return conn, rw, nil
 }
 
 func (d *deferredResponseWriter) Unwrap() http.ResponseWriter {
 	return d.ResponseWriter
 }
 
+// timeoutResponseWriter is the writer TimeoutMiddleware hands to its handler
+// goroutine. Headers are a private copy until the response starts, and once
+// the middleware took over with timeout, every write fails with
+// http.ErrHandlerTimeout, so a late handler never races the timeout response.
+type timeoutResponseWriter struct {
+	http.ResponseWriter
+	header      http.Header
+	mu          sync.Mutex
+	timedOut    bool
+	wroteHeader bool
+}
+
+func newTimeoutResponseWriter(w http.ResponseWriter) *timeoutResponseWriter {
+	header := w.Header().Clone()
+	if header == nil {
+		header = make(http.Header)
+	}
+	return &timeoutResponseWriter{ResponseWriter: w, header: header}
+}
+
+func (t *timeoutResponseWriter) Header() http.Header {
+	return t.header
+}
+
+func (t *timeoutResponseWriter) WriteHeader(code int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.timedOut {
+		t.writeHeaderLocked(code)
+	}
+}
+
+func (t *timeoutResponseWriter) writeHeaderLocked(code int) {
+	if t.wroteHeader {
+		return
+	}
+	// A 1xx response goes out with the current headers and does not start
+	// the final response.
+	t.wroteHeader = !isInformational(code)
+	dst := t.ResponseWriter.Header()
+	for k := range dst {
+		if _, ok := t.header[k]; !ok {
+			delete(dst, k)
+		}
+	}
+	for k, v := range t.header {
+		dst[k] = v
+	}
+	t.ResponseWriter.WriteHeader(code)
+}
+
+func (t *timeoutResponseWriter) Write(b []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+	t.writeHeaderLocked(http.StatusOK)
+	return t.ResponseWriter.Write(b)
+}
+
+func (t *timeoutResponseWriter) Flush() {
+	_ = t.FlushError()
+}
+
+// FlushError is Flush reporting the error of the underlying writer.
+func (t *timeoutResponseWriter) FlushError() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.timedOut {
+		return http.ErrHandlerTimeout
+	}
+	t.writeHeaderLocked(http.StatusOK)
+	return http.NewResponseController(t.ResponseWriter).Flush()
+}
+
+// SetReadDeadline, SetWriteDeadline and EnableFullDuplex serve
+// http.ResponseController, failing once the handler timed out: the
+// connection then belongs to the timeout response.
+func (t *timeoutResponseWriter) SetReadDeadline(deadline time.Time) error {
+	return t.controller(func(rc *http.ResponseController) error { return rc.SetReadDeadline(deadline) })
+}
+
+func (t *timeoutResponseWriter) SetWriteDeadline(deadline time.Time) error {
+	return t.controller(func(rc *http.ResponseController) error { return rc.SetWriteDeadline(deadline) })
+}
+
+func (t *timeoutResponseWriter) EnableFullDuplex() error {
+	return t.controller((*http.ResponseController).EnableFullDuplex)
+}
+
+func (t *timeoutResponseWriter) controller(fn func(*http.ResponseController) error) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.timedOut {
+		return http.ErrHandlerTimeout
+	}
+	return fn(http.NewResponseController(t.ResponseWriter))
+}
+
+func (t *timeoutResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.timedOut {
+		return nil, nil, http.ErrHandlerTimeout
+	}
+	conn, rw, err := http.NewResponseController(t.ResponseWriter).Hijack()
+	if err == nil {
+		// The connection belongs to the handler now; treat it as a started response.
+		t.wroteHeader = true
+	}
+	return conn, rw, err
+}
+
+// timeout closes the writer to the handler unless the response already
+// started, and reports whether it did so.
+func (t *timeoutResponseWriter) timeout() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.wroteHeader {
+		return false
+	}
+	t.timedOut = true
+	return true
+}
+
+func (t *timeoutResponseWriter) Unwrap() http.ResponseWriter {
+	return t.ResponseWriter
+}
+
 type responseCapture struct {
 	http.ResponseWriter
 	body         []byte
@@ -222,23 +419,24 @@ func (r *responseCapture) Write(b []byte) (int, error) {
 
 func (r *responseCapture) WriteHeader(code int) {
 	r.mu.Lock()
-	r.statusCode = code
+	if !isInformational(code) {
+		r.statusCode = code
+	}
 	r.mu.Unlock()
 	r.ResponseWriter.WriteHeader(code)
 }
 
 func (r *responseCapture) Flush() {
-	if f, ok := r.ResponseWriter.(http.Flusher); ok {
-		f.Flush()
-	}
+	_ = r.FlushError()
 }
 
-func (r *responseCapture) Hijack() (net.Conn, *bufio.ReadWriter, error) {
-	if hj, ok := r.ResponseWriter.(http.Hijacker); ok {
-		return hj.Hijack()
-	}
+// FlushError is Flush reporting the error of the underlying writer.
+func (r *responseCapture) FlushError() error {
+	return http.NewResponseController(r.ResponseWriter).Flush()
+}
 
-	return nil, nil, fmt.Errorf("underlying ResponseWriter does not support Hijack")
+func (r *responseCapture) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return http.NewResponseController(r.ResponseWriter).Hijack()
 }
 
 func (r *responseCapture) Unwrap() http.ResponseWriter {
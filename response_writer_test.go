@@ -2,10 +2,14 @@ package golitekit
 
 import (
 	"bufio"
+	"context"
+	"errors"
+	"io"
 	"net"
 	"net/http"
 	"net/http/httptest"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 )
@@ -486,3 +490,152 @@ func TestResponseCapture_ResponseControllerSetWriteDeadline(t *testing.T) {
 		t.Fatalf("write deadline = %v, want %v", rec.writeDeadline, deadline)
 	}
 }
+
+func TestDeferredResponseWriter_ReleaseTo(t *testing.T) {
+	var pool sync.Pool
+	rec := httptest.NewRecorder()
+	dw := newDeferredResponseWriter(rec)
+	dw.Header().Set("X-Test", "1")
+	dw.WriteHeader(http.StatusTeapot)
+	dw.Write([]byte("hello"))
+	dw.Flush()
+	dw.ReleaseTo(&pool)
+
+	if got := pool.Get(); got != nil && got != dw {
+		t.Fatal("pool returned another writer")
+	}
+	got := dw
+	if got.ResponseWriter != nil || len(got.header) != 0 || got.buffer.Len() != 0 ||
+		got.statusCode != http.StatusOK || got.isCommitted || got.isFlushed || got.isHeaderWritten {
+		t.Errorf("released writer keeps state: %+v", got)
+	}
+
+	big := newDeferredResponseWriter(httptest.NewRecorder())
+	big.Write(make([]byte, maxPooledDeferredBuffer+1))
+	big.ReleaseTo(&pool)
+	if got := pool.Get(); got != nil {
+		t.Error("writer with an oversized buffer was pooled")
+	}
+}
+
+func benchmarkSmallResponses(b *testing.B, newWriter func(http.ResponseWriter) *deferredResponseWriter, release bool) {
+	body := []byte(`{"status":0,"msg":"ok"}`)
+	rec := httptest.NewRecorder()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		rec.Body.Reset()
+		dw := newWriter(rec)
+		dw.Header().Set("Content-Type", "application/json")
+		dw.WriteHeader(http.StatusOK)
+		dw.Write(body)
+		dw.Commit()
+		if release {
+			dw.ReleaseTo(&deferredWriterPool)
+		}
+	}
+}
+
+func BenchmarkDeferredResponseWriter_Pooled(b *testing.B) {
+	benchmarkSmallResponses(b, newDeferredResponseWriter, true)
+}
+
+func BenchmarkDeferredResponseWriter_Unpooled(b *testing.B) {
+	benchmarkSmallResponses(b, func(w http.ResponseWriter) *deferredResponseWriter {
+		return &deferredResponseWriter{
+			ResponseWriter: w,
+			header:         make(http.Header),
+			statusCode:     http.StatusOK,
+			bufferLimit:    DefaultDeferredResponseBufferLimit,
+		}
+	}, false)
+}
+
+func BenchmarkErrorHandlerMiddleware_SmallResponse(b *testing.B) {
+	h := ErrorHandlerMiddleware()(func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("Content-Type", "application/json")
+		_, err := w.Write([]byte(`{"status":0,"msg":"ok"}`))
+		return err
+	})
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			h.ServeHTTP(httptest.NewRecorder(), req)
+		}
+	})
+}
+
+// unwrapOnlyWriter is a third-party style wrapper implementing nothing but
+// Unwrap.
+type unwrapOnlyWriter struct{ http.ResponseWriter }
+
+func (w unwrapOnlyWriter) Unwrap() http.ResponseWriter { return w.ResponseWriter }
+
+func TestResponseControllerThroughWrapperStack(t *testing.T) {
+	app := NewApp(WithDefaultMiddlewareOptions(func(o *DefaultMiddlewareOptions) {
+		o.Timeout.Duration = time.Second
+	}))
+	app.Use(func(next Handler) Handler {
+		return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			return next(ctx, unwrapOnlyWriter{w}, r)
+		}
+	})
+	app.Use(CompressionMiddleware())
+	errs := make(chan []error, 1)
+	app.GET("/rc", HandlerFunc(func(ctx *Context) error {
+		rc := http.NewResponseController(ctx.ResponseWriter())
+		deadline := time.Now().Add(time.Second)
+		got := []error{rc.SetReadDeadline(deadline), rc.SetWriteDeadline(deadline), rc.EnableFullDuplex()}
+		ctx.ResponseWriter().Write([]byte("hello"))
+		got = append(got, rc.Flush())
+		errs <- got
+		return nil
+	}))
+	app.GET("/hijack", HandlerFunc(func(ctx *Context) error {
+		conn, rw, err := http.NewResponseController(ctx.ResponseWriter()).Hijack()
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+		rw.WriteString("HTTP/1.1 200 OK\r\nContent-Length: 8\r\nConnection: close\r\n\r\nhijacked")
+		return rw.Flush()
+	}))
+	srv := httptest.NewServer(app.Handler())
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/rc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	for i, err := range <-errs {
+		if err != nil {
+			t.Errorf("ResponseController call %d: %v", i, err)
+		}
+	}
+
+	resp, err = http.Get(srv.URL + "/hijack")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "hijacked" {
+		t.Errorf("hijacked body = %q", body)
+	}
+}
+
+func TestTimeoutResponseWriter_ResponseControllerAfterTimeout(t *testing.T) {
+	tw := newTimeoutResponseWriter(&deadlineRecorder{ResponseRecorder: httptest.NewRecorder()})
+	rc := http.NewResponseController(tw)
+	if err := rc.SetWriteDeadline(time.Now()); err != nil {
+		t.Fatalf("SetWriteDeadline before timeout: %v", err)
+	}
+	tw.timeout()
+	if err := rc.SetWriteDeadline(time.Now()); !errors.Is(err, http.ErrHandlerTimeout) {
+		t.Errorf("SetWriteDeadline after timeout = %v, want ErrHandlerTimeout", err)
+	}
+	if err := rc.Flush(); !errors.Is(err, http.ErrHandlerTimeout) {
+		t.Errorf("Flush after timeout = %v, want ErrHandlerTimeout", err)
+	}
+}
@@ -2,7 +2,13 @@ package golitekit
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 )
 
 const (
@@ -33,6 +39,54 @@ func (c *RestControllerOf[T]) ServeData(ctx context.Context, data any) error {
 	return c.JSON(http.StatusOK, res)
 }
 
+// ServeDataWithCache serves data like ServeData and lets clients cache it
+// for maxAge: it sets Cache-Control, Expires and an ETag of the marshaled
+// data, and answers a GET or HEAD whose If-None-Match holds that ETag with
+// 304 Not Modified and no body.
+func (c *RestControllerOf[T]) ServeDataWithCache(ctx context.Context, data any, maxAge time.Duration) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return ErrInternal("Failed to marshal JSON response", err)
+	}
+	sum := sha256.Sum256(payload)
+	// weak: the envelope around data carries a per-request log ID
+	etag := `W/"` + hex.EncodeToString(sum[:16]) + `"`
+
+	w := c.gcx.ResponseWriter()
+	h := w.Header()
+	h.Set("ETag", etag)
+	h.Set("Cache-Control", "max-age="+strconv.Itoa(int(maxAge/time.Second)))
+	h.Set("Expires", time.Now().Add(maxAge).UTC().Format(http.TimeFormat))
+
+	r := c.gcx.Request()
+	if (r.Method == http.MethodGet || r.Method == http.MethodHead) && etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return nil
+	}
+	return c.ServeData(ctx, json.RawMessage(payload))
+}
+
+// etagMatches reports whether the If-None-Match header value lists etag,
+// comparing weakly.
+func etagMatches(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+	want := strings.TrimPrefix(etag, "W/")
+	for _, tag := range strings.Split(ifNoneMatch, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == want {
+			return true
+		}
+	}
+	return false
+}
+
+// ServePage serves items of page with the total row count as a PageResult.
+func (c *RestControllerOf[T]) ServePage(ctx context.Context, page Page, items any, total int64) error {
+	return c.ServeData(ctx, page.Result(items, total))
+}
+
 func (c *RestControllerOf[T]) ServeOK(ctx context.Context) error {
 	return c.ServeData(ctx, nil)
 }
@@ -5,7 +5,9 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 )
 
 func buildRestCtx(t *testing.T) (context.Context, *httptest.ResponseRecorder) {
@@ -162,3 +164,48 @@ func TestRestController_ServeData_IncludesLogID(t *testing.T) {
 		t.Error("expected LogID to be populated when log ID is present")
 	}
 }
+
+type cachedItemsController struct {
+	RestController
+}
+
+func (c *cachedItemsController) Serve(ctx context.Context) error {
+	return c.ServeDataWithCache(ctx, []string{"a", "b"}, time.Minute)
+}
+
+func TestRestController_ServeDataWithCache(t *testing.T) {
+	r := newTestRouter()
+	r.GET("/items", &cachedItemsController{})
+
+	rec := httptest.NewRecorder()
+	r.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/items", nil))
+	etag := rec.Header().Get("ETag")
+	if rec.Code != http.StatusOK || etag == "" || rec.Header().Get("Cache-Control") != "max-age=60" || rec.Header().Get("Expires") == "" {
+		t.Fatalf("first response = %d %v", rec.Code, rec.Header())
+	}
+	var resp Response
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if items, _ := resp.Data.([]any); len(items) != 2 {
+		t.Fatalf("data = %v", resp.Data)
+	}
+
+	for inm, want := range map[string]int{
+		etag:                           http.StatusNotModified,
+		`"other", ` + etag:             http.StatusNotModified,
+		strings.TrimPrefix(etag, "W/"): http.StatusNotModified,
+		`"other"`:                      http.StatusOK,
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/items", nil)
+		req.Header.Set("If-None-Match", inm)
+		rec := httptest.NewRecorder()
+		r.Handler().ServeHTTP(rec, req)
+		if rec.Code != want {
+			t.Errorf("If-None-Match %s = %d, want %d", inm, rec.Code, want)
+		}
+		if want == http.StatusNotModified && (rec.Body.Len() != 0 || rec.Header().Get("ETag") != etag) {
+			t.Errorf("304 body %q, ETag %q", rec.Body.String(), rec.Header().Get("ETag"))
+		}
+	}
+}
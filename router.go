@@ -6,20 +6,50 @@ import (
 	"fmt"
 	"net/http"
 	"reflect"
+	"sort"
+	"sync/atomic"
+
+	"github.com/hansir-hsj/GoLiteKit/logger"
 )
 
 // HandlerFunc is a lightweight handler that receives the request Context directly.
 type HandlerFunc func(ctx *Context) error
 
 type routeTarget struct {
-	controller Controller
-	handler    HandlerFunc
+	controller  Controller
+	handler     HandlerFunc
+	middlewares MiddlewareQueue // route-level, see WithMiddleware and MiddlewareProvider
+}
+
+// routeWithMiddleware is the route target returned by WithMiddleware.
+type routeWithMiddleware struct {
+	target      any
+	middlewares []Middleware
+}
+
+// WithMiddleware attaches middlewares to a single route; c is a controller or
+// handler as accepted by GET, POST, etc.:
+//
+//	r.GET("/admin/stats", glk.WithMiddleware(&StatsController{}, auth, cache))
+//
+// Route middlewares run after the global and group middlewares, followed by
+// those declared by a MiddlewareProvider controller.
+func WithMiddleware(c any, middlewares ...Middleware) any {
+	return routeWithMiddleware{target: c, middlewares: middlewares}
 }
 
 func newRouteTarget(c any) routeTarget {
 	switch h := c.(type) {
+	case routeWithMiddleware:
+		target := newRouteTarget(h.target)
+		target.middlewares = append(NewMiddlewareQueue(h.middlewares...), target.middlewares...)
+		return target
 	case Controller:
-		return routeTarget{controller: h}
+		target := routeTarget{controller: h}
+		if p, ok := h.(MiddlewareProvider); ok {
+			target.middlewares = NewMiddlewareQueue(p.Middlewares()...)
+		}
+		return target
 	case HandlerFunc:
 		return routeTarget{handler: h}
 	case func(*Context) error:
@@ -32,6 +62,14 @@ func newRouteTarget(c any) routeTarget {
 	}
 }
 
+// name identifies the handler in route listings.
+func (t routeTarget) name() string {
+	if t.controller != nil {
+		return fmt.Sprintf("%T", t.controller)
+	}
+	return funcName(t.handler)
+}
+
 func isControllerValue(c any) bool {
 	if c == nil {
 		return false
@@ -51,6 +89,28 @@ type Router struct {
 	middlewares      MiddlewareQueue
 	services         *Services
 	routesRegistered bool
+	routes           []RouteInfo
+	mock             atomic.Bool
+
+	options       RouterOptions
+	prefixOptions []prefixOptions // see RouterGroup.SetOptions
+}
+
+// RouteInfo describes a registered route.
+type RouteInfo struct {
+	Method  string `json:"method"`         // empty for method-agnostic mounts such as Static
+	Host    string `json:"host,omitempty"` // set for routes of a Host group
+	Pattern string `json:"pattern"`
+	Handler string `json:"handler"`
+
+	// Examples are the controller's declared example responses, keyed by
+	// status code (see ExampleProvider).
+	Examples map[string]any `json:"examples,omitempty"`
+
+	// Middlewares names the route-level middlewares (see WithMiddleware).
+	Middlewares []string `json:"middlewares,omitempty"`
+
+	controller Controller // prototype the OpenAPI generator reflects on
 }
 
 // NewRouter creates a new Router.
@@ -72,13 +132,38 @@ func (r *Router) Use(middlewares ...Middleware) *Router {
 	return r
 }
 
-func (r *Router) GET(path string, c any)     { r.handle(http.MethodGet, path, c, nil) }
-func (r *Router) POST(path string, c any)    { r.handle(http.MethodPost, path, c, nil) }
-func (r *Router) PUT(path string, c any)     { r.handle(http.MethodPut, path, c, nil) }
-func (r *Router) DELETE(path string, c any)  { r.handle(http.MethodDelete, path, c, nil) }
-func (r *Router) PATCH(path string, c any)   { r.handle(http.MethodPatch, path, c, nil) }
-func (r *Router) HEAD(path string, c any)    { r.handle(http.MethodHead, path, c, nil) }
-func (r *Router) OPTIONS(path string, c any) { r.handle(http.MethodOptions, path, c, nil) }
+// UseNamed adds a global middleware under name, so it can later be located
+// with EditMiddlewares.
+func (r *Router) UseNamed(name string, m Middleware) *Router {
+	return r.EditMiddlewares(func(mq *MiddlewareQueue) { mq.UseNamed(name, m) })
+}
+
+// EditMiddlewares lets fn reorder, replace or remove global middlewares, e.g.
+// the named defaults installed by NewApp. Like Use, it panics once routes
+// have been registered.
+func (r *Router) EditMiddlewares(fn func(mq *MiddlewareQueue)) *Router {
+	if r.routesRegistered {
+		panic("golitekit: middleware must be registered before routes")
+	}
+	mq := r.middlewares.Clone()
+	fn(&mq)
+	if err := mq.Validate(); err != nil {
+		panic("golitekit: " + err.Error())
+	}
+	r.middlewares = mq
+	return r
+}
+
+// Middlewares describes the global middlewares, outermost first.
+func (r *Router) Middlewares() []MiddlewareDescription { return r.middlewares.Describe() }
+
+func (r *Router) GET(path string, c any)     { r.handle(http.MethodGet, "", path, c, nil) }
+func (r *Router) POST(path string, c any)    { r.handle(http.MethodPost, "", path, c, nil) }
+func (r *Router) PUT(path string, c any)     { r.handle(http.MethodPut, "", path, c, nil) }
+func (r *Router) DELETE(path string, c any)  { r.handle(http.MethodDelete, "", path, c, nil) }
+func (r *Router) PATCH(path string, c any)   { r.handle(http.MethodPatch, "", path, c, nil) }
+func (r *Router) HEAD(path string, c any)    { r.handle(http.MethodHead, "", path, c, nil) }
+func (r *Router) OPTIONS(path string, c any) { r.handle(http.MethodOptions, "", path, c, nil) }
 
 // Any registers all common HTTP methods.
 func (r *Router) Any(path string, c any) {
@@ -91,12 +176,23 @@ func (r *Router) Any(path string, c any) {
 	r.OPTIONS(path, c)
 }
 
-func (r *Router) handle(method, path string, c any, groupMiddlewares MiddlewareQueue) {
+func (r *Router) handle(method, host, path string, c any, groupMiddlewares MiddlewareQueue) {
 	r.routesRegistered = true
 	target := newRouteTarget(c)
+	info := r.recordRoute(method, path, target.name())
+	info.Host = host
+	info.Examples, info.controller = target.examples(), target.controller
+	for _, m := range target.middlewares.Describe() {
+		info.Middlewares = append(info.Middlewares, m.Func)
+	}
+	if len(target.middlewares) > 0 {
+		groupMiddlewares = append(groupMiddlewares.Clone(), target.middlewares...)
+	}
 	handler := r.wrapRouteTarget(target, groupMiddlewares)
 
-	// Register the method-specific handler directly (Go 1.22+ pattern syntax).
+	// Register the method-specific handler directly (Go 1.22+ pattern syntax),
+	// scoped to the host of a Host group.
+	path = host + path
 	r.mux.Handle(method+" "+path, handler)
 
 	// Register a path-only catch-all once per path to return a JSON 405.
@@ -126,7 +222,10 @@ func (r *Router) wrapContextHandler(fn HandlerFunc, groupMiddlewares MiddlewareQ
 			return fmt.Errorf("golitekit: context not initialized")
 		}
 		gcx.setContextOptions(withRequest(req), withResponseWriter(w))
-		return fn(gcx)
+		if err := fn(gcx); err != nil {
+			return err
+		}
+		return gcx.abortError()
 	})
 
 	var prebuilt Handler = innerHandler
@@ -145,11 +244,14 @@ func (r *Router) wrapController(c Controller, groupMiddlewares MiddlewareQueue)
 		panic(fmt.Sprintf("golitekit: controller must be a pointer to struct, got %T", c))
 	}
 	t := ctrlType.Elem()
+	plan := clonePlanFor(t)
 	prototype := reflect.ValueOf(c).Elem()
+	clones := allocStats.controllerCounter(t)
 
 	newController := func() Controller {
+		clones.clones.Add(1)
 		v := reflect.New(t)
-		v.Elem().Set(prototype)
+		plan.copyFields(v.Elem(), prototype)
 		return v.Interface().(Controller)
 	}
 
@@ -159,12 +261,31 @@ func (r *Router) wrapController(c Controller, groupMiddlewares MiddlewareQueue)
 			gcx.setContextOptions(withRequest(req), withResponseWriter(w))
 		}
 
+		if r.mock.Load() {
+			if provider, ok := c.(ExampleProvider); ok {
+				return serveMockExample(GetContext(ctx), provider.Examples())
+			}
+		}
+
 		handler := newController()
+		gcx := GetContext(ctx)
 
-		// Call optional lifecycle hooks if implemented
+		// Call optional lifecycle hooks if implemented. After each step a
+		// controller that called Abort or Redirect skips the remaining ones.
 		if init, ok := handler.(Initializer); ok {
 			if err := init.Init(ctx); err != nil {
-				return WrapError(err, http.StatusInternalServerError)
+				return lifecycleError(ctx, "init", err, http.StatusInternalServerError)
+			}
+		}
+		if gcx.Aborted() {
+			return gcx.abortError()
+		}
+		if checker, ok := handler.(SanityChecker); ok {
+			if err := checker.SanityCheck(ctx); err != nil {
+				return lifecycleError(ctx, "sanity_check", err, http.StatusBadRequest)
+			}
+			if gcx.Aborted() {
+				return gcx.abortError()
 			}
 		}
 		// Parse before validation so Validate can inspect bound request data.
@@ -173,20 +294,42 @@ func (r *Router) wrapController(c Controller, groupMiddlewares MiddlewareQueue)
 		// default JSON/form/multipart parsing path.
 		if parser, ok := handler.(RequestParser); ok {
 			if err := parser.ParseRequest(ctx); err != nil {
-				return WrapError(err, http.StatusBadRequest)
+				return lifecycleError(ctx, "parse_request", err, http.StatusBadRequest)
 			}
 		}
 		if val, ok := handler.(Validator); ok {
 			if err := val.Validate(ctx); err != nil {
-				return WrapError(err, http.StatusBadRequest)
+				return lifecycleError(ctx, "validate", err, http.StatusBadRequest)
+			}
+		}
+		if gcx.Aborted() {
+			return gcx.abortError()
+		}
+		if hook, ok := handler.(BeforeServeHook); ok {
+			if err := hook.BeforeServe(ctx); err != nil {
+				return lifecycleError(ctx, "before_serve", err, http.StatusInternalServerError)
+			}
+			if gcx.Aborted() {
+				return gcx.abortError()
 			}
 		}
 		if err := handler.Serve(ctx); err != nil {
-			return WrapError(err, http.StatusInternalServerError)
+			return lifecycleError(ctx, "serve", err, http.StatusInternalServerError)
+		}
+		if gcx.Aborted() {
+			return gcx.abortError()
+		}
+		if hook, ok := handler.(AfterServeHook); ok {
+			if err := hook.AfterServe(ctx); err != nil {
+				return lifecycleError(ctx, "after_serve", err, http.StatusInternalServerError)
+			}
+			if gcx.Aborted() {
+				return gcx.abortError()
+			}
 		}
 		if fin, ok := handler.(Finalizer); ok {
 			if err := fin.Finalize(ctx); err != nil {
-				return WrapError(err, http.StatusInternalServerError)
+				return lifecycleError(ctx, "finalize", err, http.StatusInternalServerError)
 			}
 		}
 		return nil
@@ -202,6 +345,15 @@ func (r *Router) wrapController(c Controller, groupMiddlewares MiddlewareQueue)
 	return r.wrapHandlerWithContext(prebuilt)
 }
 
+// lifecycleError converts the error of a controller lifecycle stage into an
+// *AppError (see ClassifyError; code when no rule matches) and records the failing stage as
+// the "controller_stage" log field, next to the request's log ID. The
+// remaining stages are skipped by returning it.
+func lifecycleError(ctx context.Context, stage string, err error, code int) *AppError {
+	logger.AddInfo(ctx, "controller_stage", stage)
+	return classifyError(err, code)
+}
+
 func (r *Router) wrapHTTPHandler(handler http.Handler) http.Handler {
 	innerHandler := Handler(func(ctx context.Context, w http.ResponseWriter, req *http.Request) error {
 		handler.ServeHTTP(w, req)
@@ -231,7 +383,7 @@ func (r *Router) wrapHandlerWithContext(prebuilt Handler) http.Handler {
 		// Errors propagate up through the middleware chain. ErrorHandlerMiddleware
 		// (when present) handles them; otherwise fall back to a plain HTTP error.
 		if err := prebuilt(req.Context(), w, req); err != nil {
-			appErr := WrapError(err, http.StatusInternalServerError)
+			appErr := ClassifyError(err)
 			http.Error(w, appErr.Message, appErr.Code)
 		}
 	})
@@ -246,12 +398,50 @@ func (r *Router) Group(prefix string) *RouterGroup {
 	}
 }
 
+// Host creates a route group served only for requests to host, e.g.
+// "admin.example.com", matched without the port. Its routes take precedence
+// over the routes without a host, which serve every hostname, and it has its
+// own middlewares like any group.
+func (r *Router) Host(host string) *RouterGroup {
+	return &RouterGroup{
+		router:      r,
+		host:        host,
+		middlewares: NewMiddlewareQueue(),
+	}
+}
+
 // Static serves static files.
 func (r *Router) Static(urlPath, fsPath string) {
 	fs := http.FileServer(http.Dir(fsPath))
 	r.routesRegistered = true
+	r.recordRoute("", urlPath+"/", "static:"+fsPath)
 	r.mux.Handle(urlPath+"/", r.wrapHTTPHandler(http.StripPrefix(urlPath, fs)))
 }
 
+// Routes returns the registered routes sorted by pattern, host and method.
+func (r *Router) Routes() []RouteInfo {
+	routes := append([]RouteInfo(nil), r.routes...)
+	sort.SliceStable(routes, func(i, j int) bool {
+		if routes[i].Pattern != routes[j].Pattern {
+			return routes[i].Pattern < routes[j].Pattern
+		}
+		if routes[i].Host != routes[j].Host {
+			return routes[i].Host < routes[j].Host
+		}
+		return routes[i].Method < routes[j].Method
+	})
+	return routes
+}
+
+func (r *Router) recordRoute(method, pattern, handler string) *RouteInfo {
+	r.routes = append(r.routes, RouteInfo{Method: method, Pattern: pattern, Handler: handler})
+	return &r.routes[len(r.routes)-1]
+}
+
 // Handler returns the http.Handler.
-func (r *Router) Handler() http.Handler { return r.mux }
+func (r *Router) Handler() http.Handler {
+	if r.redirectsEnabled() {
+		return r.fixPathHandler()
+	}
+	return r.mux
+}
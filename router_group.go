@@ -5,6 +5,7 @@ import "net/http"
 // RouterGroup is a group of routes with shared prefix and middlewares.
 type RouterGroup struct {
 	router           *Router
+	host             string // see Router.Host
 	prefix           string
 	middlewares      MiddlewareQueue
 	routesRegistered bool
@@ -43,7 +44,7 @@ func (g *RouterGroup) Any(path string, c any) {
 
 func (g *RouterGroup) handle(method, path string, c any) {
 	g.routesRegistered = true
-	g.router.handle(method, g.prefix+path, c, g.middlewares)
+	g.router.handle(method, g.host, g.prefix+path, c, g.middlewares)
 }
 
 // Group creates a nested group inheriting parent middlewares.
@@ -51,6 +52,7 @@ func (g *RouterGroup) Group(prefix string) *RouterGroup {
 	g.childrenCreated = true
 	return &RouterGroup{
 		router:      g.router,
+		host:        g.host,
 		prefix:      g.prefix + prefix,
 		middlewares: g.middlewares.Clone(),
 	}
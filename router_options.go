@@ -0,0 +1,203 @@
+package golitekit
+
+import (
+	"net"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// RouterOptions controls how requests that match no route are corrected.
+// The ServeMux already redirects /users to /users/ when only the latter is
+// registered and cleans paths such as /a/../b.
+type RouterOptions struct {
+	// RedirectTrailingSlash redirects /users/ to /users when only the latter
+	// is registered.
+	RedirectTrailingSlash bool
+	// RedirectFixedPath redirects a path that matches a route after cleaning
+	// it and ignoring the case of its literal segments, e.g. /USERS/42 to
+	// /users/42. Wildcard values keep their case.
+	RedirectFixedPath bool
+	// CaseInsensitive serves a path that matches a route when the case of its
+	// literal segments is ignored, without redirecting.
+	CaseInsensitive bool
+}
+
+func (o RouterOptions) enabled() bool {
+	return o.RedirectTrailingSlash || o.RedirectFixedPath || o.CaseInsensitive
+}
+
+type prefixOptions struct {
+	host   string
+	prefix string
+	opts   RouterOptions
+}
+
+// SetOptions sets the RouterOptions of every route outside a group with its
+// own options.
+func (r *Router) SetOptions(opts RouterOptions) *Router {
+	r.options = opts
+	return r
+}
+
+// SetOptions sets the RouterOptions of the paths under the group's prefix,
+// and host for a Host group, including nested groups without their own
+// options.
+func (g *RouterGroup) SetOptions(opts RouterOptions) *RouterGroup {
+	g.router.prefixOptions = append(g.router.prefixOptions, prefixOptions{host: g.host, prefix: g.prefix, opts: opts})
+	return g
+}
+
+// optionsFor returns the options of the longest group prefix containing the
+// path of req, compared without case so a miscased path finds its group. A
+// group of the request's host beats one without a host.
+func (r *Router) optionsFor(req *http.Request) RouterOptions {
+	p := req.URL.Path
+	host := req.Host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	opts, best := r.options, -1
+	for _, po := range r.prefixOptions {
+		if po.host != "" && !strings.EqualFold(po.host, host) {
+			continue
+		}
+		prefix := strings.TrimSuffix(po.prefix, "/")
+		if len(p) < len(prefix) || !strings.EqualFold(p[:len(prefix)], prefix) {
+			continue
+		}
+		if len(p) > len(prefix) && p[len(prefix)] != '/' {
+			continue
+		}
+		score := len(prefix)
+		if po.host != "" {
+			score += len(p) + 1
+		}
+		if score > best {
+			opts, best = po.opts, score
+		}
+	}
+	return opts
+}
+
+func (r *Router) redirectsEnabled() bool {
+	if r.options.enabled() {
+		return true
+	}
+	for _, po := range r.prefixOptions {
+		if po.opts.enabled() {
+			return true
+		}
+	}
+	return false
+}
+
+// fixPathHandler serves the mux, correcting requests that match no route as
+// configured by the RouterOptions.
+func (r *Router) fixPathHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if _, pattern := r.mux.Handler(req); pattern == "" {
+			if fixed, redirect, ok := r.fixPath(req, r.optionsFor(req)); ok {
+				if redirect {
+					redirectToPath(w, req, fixed)
+					return
+				}
+				req = withPath(req, fixed)
+			}
+		}
+		r.mux.ServeHTTP(w, req)
+	})
+}
+
+// fixPath looks for a registered path req could have meant and reports
+// whether to redirect to it rather than serve it in place.
+func (r *Router) fixPath(req *http.Request, opts RouterOptions) (fixed string, redirect, ok bool) {
+	p := req.URL.Path
+	candidates := []string{p}
+	if opts.RedirectTrailingSlash && len(p) > 1 && strings.HasSuffix(p, "/") {
+		stripped := strings.TrimSuffix(p, "/")
+		if r.matches(req, stripped) {
+			return stripped, true, true
+		}
+		candidates = append(candidates, stripped)
+	}
+	if !opts.RedirectFixedPath && !opts.CaseInsensitive {
+		return "", false, false
+	}
+	for _, c := range candidates {
+		if opts.RedirectFixedPath {
+			cleaned := path.Clean(c)
+			if strings.HasSuffix(c, "/") && cleaned != "/" {
+				cleaned += "/"
+			}
+			c = cleaned
+		}
+		for _, route := range r.routes {
+			if fixed, ok := matchFold(route.Pattern, c); ok && r.matches(req, fixed) {
+				// A case-insensitive match is served in place unless the
+				// trailing slash had to go too.
+				return fixed, opts.RedirectFixedPath || c != p, true
+			}
+		}
+	}
+	return "", false, false
+}
+
+// matches reports whether the mux has a route for req with path p.
+func (r *Router) matches(req *http.Request, p string) bool {
+	_, pattern := r.mux.Handler(withPath(req, p))
+	return pattern != ""
+}
+
+// matchFold matches p against a route pattern, comparing literal segments
+// without case, and returns p with the literal segments of the pattern.
+func matchFold(pattern, p string) (string, bool) {
+	pSegs := strings.Split(pattern, "/")
+	segs := strings.Split(p, "/")
+	for i, ps := range pSegs {
+		last := i == len(pSegs)-1
+		switch {
+		case ps == "{$}" && last:
+			if i != len(segs)-1 || segs[i] != "" {
+				return "", false
+			}
+		case i >= len(segs):
+			return "", false
+		case strings.HasSuffix(ps, "...}") && strings.HasPrefix(ps, "{"), ps == "" && last && i > 0:
+			// The rest of the path belongs to the wildcard or subtree.
+			return strings.Join(segs, "/"), true
+		case strings.HasPrefix(ps, "{") && strings.HasSuffix(ps, "}"):
+			if segs[i] == "" {
+				return "", false
+			}
+		case !strings.EqualFold(ps, segs[i]):
+			return "", false
+		default:
+			segs[i] = ps
+		}
+	}
+	if len(segs) != len(pSegs) {
+		return "", false
+	}
+	return strings.Join(segs, "/"), true
+}
+
+func withPath(req *http.Request, p string) *http.Request {
+	r2 := *req
+	u := *req.URL
+	u.Path, u.RawPath = p, ""
+	r2.URL = &u
+	return &r2
+}
+
+// redirectToPath redirects to p, keeping the query: 301 for GET and HEAD,
+// 308 for other methods so the body is sent again.
+func redirectToPath(w http.ResponseWriter, req *http.Request, p string) {
+	code := http.StatusMovedPermanently
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		code = http.StatusPermanentRedirect
+	}
+	u := url.URL{Path: p, RawQuery: req.URL.RawQuery}
+	http.Redirect(w, req, u.String(), code)
+}
@@ -0,0 +1,93 @@
+package golitekit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func pathEcho(c *Context) error {
+	c.ResponseWriter().Write([]byte(c.Request().URL.Path + " " + c.Request().PathValue("id")))
+	return nil
+}
+
+func TestRouterOptions_Redirects(t *testing.T) {
+	r := NewRouter(nil)
+	r.SetOptions(RouterOptions{RedirectTrailingSlash: true, RedirectFixedPath: true})
+	r.GET("/users", HandlerFunc(pathEcho))
+	r.GET("/users/{id}/orders", HandlerFunc(pathEcho))
+	r.POST("/orders", HandlerFunc(pathEcho))
+	h := r.Handler()
+
+	tests := []struct {
+		method, target string
+		code           int
+		location       string
+	}{
+		{http.MethodGet, "/users/", http.StatusMovedPermanently, "/users"},
+		{http.MethodGet, "/Users/", http.StatusMovedPermanently, "/users"},
+		{http.MethodGet, "/USERS/AbC/Orders?page=2", http.StatusMovedPermanently, "/users/AbC/orders?page=2"},
+		{http.MethodPost, "/orders/", http.StatusPermanentRedirect, "/orders"},
+		{http.MethodGet, "/users", http.StatusOK, ""},
+		{http.MethodGet, "/accounts/", http.StatusNotFound, ""},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.target, nil))
+		if rec.Code != tt.code || rec.Header().Get("Location") != tt.location {
+			t.Errorf("%s %s = %d %q, want %d %q", tt.method, tt.target, rec.Code, rec.Header().Get("Location"), tt.code, tt.location)
+		}
+	}
+}
+
+func TestRouterOptions_CaseInsensitiveServesInPlace(t *testing.T) {
+	r := NewRouter(nil)
+	r.SetOptions(RouterOptions{CaseInsensitive: true})
+	r.GET("/users/{id}", HandlerFunc(pathEcho))
+
+	rec := httptest.NewRecorder()
+	r.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/Users/Ab", nil))
+	if rec.Code != http.StatusOK || rec.Body.String() != "/users/Ab Ab" {
+		t.Errorf("GET /Users/Ab = %d %q, want the route served with the wildcard value kept", rec.Code, rec.Body.String())
+	}
+}
+
+func TestRouterOptions_PerGroup(t *testing.T) {
+	r := NewRouter(nil)
+	api := r.Group("/api")
+	api.SetOptions(RouterOptions{RedirectTrailingSlash: true})
+	api.GET("/items", HandlerFunc(pathEcho))
+	r.GET("/pages", HandlerFunc(pathEcho))
+	h := r.Handler()
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/items/", nil))
+	if rec.Code != http.StatusMovedPermanently || rec.Header().Get("Location") != "/api/items" {
+		t.Errorf("GET /api/items/ = %d %q, want a redirect to /api/items", rec.Code, rec.Header().Get("Location"))
+	}
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/pages/", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("GET /pages/ = %d, want 404 outside the group", rec.Code)
+	}
+}
+
+func TestMatchFold(t *testing.T) {
+	tests := []struct {
+		pattern, path, want string
+		ok                  bool
+	}{
+		{"/users/{id}", "/USERS/Ab", "/users/Ab", true},
+		{"/static/", "/Static/CSS/a.css", "/static/CSS/a.css", true},
+		{"/files/{path...}", "/FILES/a/B", "/files/a/B", true},
+		{"/{$}", "/", "/", true},
+		{"/users/{id}", "/users", "", false},
+		{"/users", "/users/1", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := matchFold(tt.pattern, tt.path)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("matchFold(%q, %q) = %q, %v; want %q, %v", tt.pattern, tt.path, got, ok, tt.want, tt.ok)
+		}
+	}
+}
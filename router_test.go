@@ -547,3 +547,115 @@ func TestControllerRequestTracksMiddlewareRequestContext(t *testing.T) {
 		t.Fatal("expected Context.Request to include middleware-updated context")
 	}
 }
+
+type guardedController struct {
+	BaseController
+	order *[]string
+}
+
+func (c *guardedController) Middlewares() []Middleware {
+	return []Middleware{traceMiddleware(c.order, "controller")}
+}
+
+func (c *guardedController) Serve(ctx context.Context) error {
+	*c.order = append(*c.order, "serve")
+	return c.JSON(http.StatusOK, nil)
+}
+
+func traceMiddleware(order *[]string, name string) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			*order = append(*order, name)
+			return next(ctx, w, r)
+		}
+	}
+}
+
+func TestRouter_RouteLevelMiddlewares(t *testing.T) {
+	var order []string
+	r := newTestRouter()
+	r.Use(traceMiddleware(&order, "global"))
+	g := r.Group("/api").Use(traceMiddleware(&order, "group"))
+	g.GET("/guarded", WithMiddleware(&guardedController{order: &order}, traceMiddleware(&order, "route")))
+	g.GET("/plain", HandlerFunc(func(ctx *Context) error {
+		order = append(order, "plain")
+		return nil
+	}))
+	deny := func(next Handler) Handler {
+		return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			return ErrForbidden("denied", nil)
+		}
+	}
+	r.GET("/denied", WithMiddleware(func(ctx *Context) error {
+		t.Error("handler behind a rejecting middleware ran")
+		return nil
+	}, deny))
+
+	r.Handler().ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/guarded", nil))
+	if got := strings.Join(order, ","); got != "global,group,route,controller,serve" {
+		t.Errorf("order = %s", got)
+	}
+	order = nil
+	r.Handler().ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/plain", nil))
+	if got := strings.Join(order, ","); got != "global,group,plain" {
+		t.Errorf("route middlewares leaked to a sibling route: %s", got)
+	}
+	rec := httptest.NewRecorder()
+	r.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/denied", nil))
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("denied = %d", rec.Code)
+	}
+
+	for _, route := range r.Routes() {
+		if route.Pattern == "/api/guarded" && len(route.Middlewares) != 2 {
+			t.Errorf("guarded middlewares = %v", route.Middlewares)
+		}
+		if route.Pattern == "/api/plain" && route.Middlewares != nil {
+			t.Errorf("plain middlewares = %v", route.Middlewares)
+		}
+	}
+}
+
+func TestRouter_HostScopesRoutesAndMiddlewares(t *testing.T) {
+	r := NewRouter(nil)
+	admin := r.Host("admin.example.com")
+	admin.Use(func(next Handler) Handler {
+		return func(ctx context.Context, w http.ResponseWriter, req *http.Request) error {
+			w.Header().Set("X-Scope", "admin")
+			return next(ctx, w, req)
+		}
+	})
+	admin.GET("/{$}", func(c *Context) error { _, err := c.ResponseWriter().Write([]byte("admin home")); return err })
+	admin.Group("/users").GET("/{id}", func(c *Context) error { _, err := c.ResponseWriter().Write([]byte("admin user")); return err })
+	r.GET("/{$}", func(c *Context) error { _, err := c.ResponseWriter().Write([]byte("home")); return err })
+	r.GET("/about", func(c *Context) error { _, err := c.ResponseWriter().Write([]byte("about")); return err })
+	h := r.Handler()
+
+	tests := []struct {
+		host, path, body, scope string
+		code                    int
+	}{
+		{"admin.example.com", "/", "admin home", "admin", http.StatusOK},
+		{"admin.example.com:8443", "/users/7", "admin user", "admin", http.StatusOK},
+		{"api.example.com", "/", "home", "", http.StatusOK},
+		{"api.example.com", "/users/7", "", "", http.StatusNotFound},
+		{"admin.example.com", "/about", "about", "", http.StatusOK},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+		req.Host = tt.host
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if rec.Code != tt.code || rec.Header().Get("X-Scope") != tt.scope || (tt.body != "" && rec.Body.String() != tt.body) {
+			t.Errorf("%s%s = %d %q scope %q, want %d %q scope %q", tt.host, tt.path, rec.Code, rec.Body.String(), rec.Header().Get("X-Scope"), tt.code, tt.body, tt.scope)
+		}
+	}
+
+	var hosts []string
+	for _, route := range r.Routes() {
+		hosts = append(hosts, route.Host)
+	}
+	if strings.Join(hosts, ",") != ",admin.example.com,,admin.example.com" {
+		t.Errorf("route hosts = %q", hosts)
+	}
+}
@@ -3,11 +3,19 @@ package golitekit
 import (
 	"context"
 	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"net"
 	"net/http"
+	"os"
+	"slices"
+	"strings"
 	"sync"
 	"time"
+
+	"github.com/hansir-hsj/GoLiteKit/env"
 )
 
 // ServerConfig holds HTTP server settings.
@@ -19,9 +27,60 @@ type ServerConfig struct {
 	IdleTimeout       time.Duration
 	ReadHeaderTimeout time.Duration
 	MaxHeaderBytes    int
-	ShutdownTimeout   time.Duration
-	TLSCertFile       string
-	TLSKeyFile        string
+	// ShutdownTimeout bounds the whole shutdown when ShutdownBudgets is left
+	// unset; explicit budgets replace their share of it.
+	ShutdownTimeout time.Duration
+	// ShutdownBudgets bounds the phases of ListenAndServe's shutdown; zero
+	// fields are derived from ShutdownTimeout, see DefaultShutdownBudgets.
+	ShutdownBudgets ShutdownBudgets
+	TLSCertFile     string
+	TLSKeyFile      string
+	// TLSClientCAFile enables mutual TLS: client certificates are verified
+	// against this PEM bundle. TLSClientAuth defaults to
+	// tls.RequireAndVerifyClientCert when a CA file is set; to serve without
+	// client certificates leave TLSClientCAFile empty.
+	TLSClientCAFile string
+	TLSClientAuth   tls.ClientAuthType
+	// TLSHosts are further certificates selected by SNI, so one server
+	// terminates TLS for several domains; TLSCertFile, if set, serves the
+	// other names. ACME hosts keep their certificates in ACMECacheDir and
+	// register ACMEEmail as the account contact with the CA at
+	// ACMEDirectoryURL, Let's Encrypt when empty.
+	TLSHosts         []TLSHost
+	ACMECacheDir     string
+	ACMEEmail        string
+	ACMEDirectoryURL string
+
+	// Maintenance is the switch consulted before every request; nil creates
+	// a disabled one reachable through Server.SetMaintenance.
+	Maintenance *Maintenance
+
+	// Listeners are served alongside the primary Addr by the same Server and
+	// share its timeouts and lifecycle.
+	Listeners []ListenerConfig
+}
+
+// ListenerConfig describes an additional listener managed by a Server.
+type ListenerConfig struct {
+	Name        string
+	Network     string // defaults to the primary Network
+	Addr        string
+	TLSCertFile string
+	TLSKeyFile  string
+	// TLSClientCAFile and TLSClientAuth configure mutual TLS as on ServerConfig.
+	TLSClientCAFile string
+	TLSClientAuth   tls.ClientAuthType
+
+	// PathPrefix restricts the listener to requests under this path; other
+	// paths receive a 404. Empty serves every path.
+	PathPrefix string
+	// Handler overrides the server handler for this listener, e.g. a separate
+	// admin router. Nil uses the handler passed to Start.
+	Handler http.Handler
+	// RedirectHTTPS answers every request with a 308 redirect to HTTPS.
+	// HTTPSAddr supplies the target port; empty or ":443" omits the port.
+	RedirectHTTPS bool
+	HTTPSAddr     string
 }
 
 // DefaultServerConfig returns sensible defaults.
@@ -44,8 +103,19 @@ type Server struct {
 	mu         sync.Mutex
 	httpServer *http.Server
 	listener   net.Listener
+	extras     []*extraListener
 	done       chan error
+	stopped    chan struct{} // closed once every Serve call has returned
 	started    bool
+	inflight   inflightRequests
+
+	maintenance *Maintenance
+}
+
+type extraListener struct {
+	name       string
+	httpServer *http.Server
+	listener   net.Listener
 }
 
 // NewServer creates a new Server.
@@ -75,7 +145,89 @@ func NewServer(config ServerConfig) *Server {
 	if config.ShutdownTimeout == 0 {
 		config.ShutdownTimeout = defaults.ShutdownTimeout
 	}
-	return &Server{config: config}
+	// the defaults below must not leak into the caller's slice
+	config.Listeners = slices.Clone(config.Listeners)
+	for i := range config.Listeners {
+		if config.Listeners[i].Network == "" {
+			config.Listeners[i].Network = config.Network
+		}
+	}
+	maintenance := config.Maintenance
+	if maintenance == nil {
+		maintenance = NewMaintenance()
+	}
+	return &Server{config: config, maintenance: maintenance}
+}
+
+// ServerConfigFromEnv builds a ServerConfig from the values loaded by env.Init,
+// including TLS files and additional listeners. It fails on an unknown
+// clientAuth value.
+func ServerConfigFromEnv() (ServerConfig, error) {
+	config := ServerConfig{
+		Addr:              env.Addr(),
+		Network:           env.Network(),
+		ReadTimeout:       env.ReadTimeout(),
+		WriteTimeout:      env.WriteTimeout(),
+		IdleTimeout:       env.IdleTimeout(),
+		ReadHeaderTimeout: env.ReadHeaderTimeout(),
+		MaxHeaderBytes:    env.MaxHeaderBytes(),
+		ShutdownTimeout:   env.ShutdownTimeout(),
+		ShutdownBudgets: ShutdownBudgets{
+			StopAccepting: env.StopAcceptingTimeout(),
+			DrainRequests: env.DrainRequestsTimeout(),
+			DrainJobs:     env.DrainJobsTimeout(),
+			ClosePools:    env.ClosePoolsTimeout(),
+			FlushLogs:     env.FlushLogsTimeout(),
+		},
+	}
+	if env.TLS() {
+		config.TLSCertFile = env.TLSCertFile()
+		config.TLSKeyFile = env.TLSKeyFile()
+		caFile, clientAuth, err := clientAuthFromEnv(env.TLSClientCAFile(), env.TLSClientAuth())
+		if err != nil {
+			return ServerConfig{}, fmt.Errorf("HttpServer.TLSConfig: %w", err)
+		}
+		config.TLSClientCAFile = caFile
+		config.TLSClientAuth = clientAuth
+		for _, h := range env.TLSHosts() {
+			config.TLSHosts = append(config.TLSHosts, TLSHost{Host: h.Host, CertFile: h.CertFile, KeyFile: h.KeyFile, ACME: h.ACME})
+		}
+		config.ACMECacheDir = env.ACMECacheDir()
+		config.ACMEEmail = env.ACMEEmail()
+	}
+	for _, l := range env.Listeners() {
+		caFile, clientAuth, err := clientAuthFromEnv(l.ClientCAFile, l.ClientAuth)
+		if err != nil {
+			return ServerConfig{}, fmt.Errorf("listener %q: %w", l.Name, err)
+		}
+		config.Listeners = append(config.Listeners, ListenerConfig{
+			Name:            l.Name,
+			Network:         l.Network,
+			Addr:            l.Addr,
+			TLSCertFile:     l.CertFile,
+			TLSKeyFile:      l.KeyFile,
+			TLSClientCAFile: caFile,
+			TLSClientAuth:   clientAuth,
+			PathPrefix:      l.PathPrefix,
+			RedirectHTTPS:   l.RedirectHTTPS,
+			HTTPSAddr:       l.HTTPSAddr,
+		})
+	}
+	return config, nil
+}
+
+// clientAuthFromEnv parses a clientAuth value and resolves it against the
+// client CA file. An explicit "none" drops the CA file, which would otherwise
+// upgrade the listener to "require".
+func clientAuthFromEnv(caFile, value string) (string, tls.ClientAuthType, error) {
+	clientAuth, err := ParseClientAuth(value)
+	if err != nil {
+		return "", tls.NoClientCert, err
+	}
+	if clientAuth == tls.NoClientCert && strings.TrimSpace(value) != "" {
+		caFile = ""
+	}
+	return caFile, clientAuth, nil
 }
 
 // Start begins listening and serving without signal handling.
@@ -87,7 +239,7 @@ func (s *Server) Start(handler http.Handler) error {
 }
 
 // ListenAndServe starts the server and blocks until ctx is cancelled,
-// then performs graceful shutdown within ShutdownTimeout.
+// then shuts down in phases (see ShutdownInPhases) within ShutdownBudgets.
 func (s *Server) ListenAndServe(ctx context.Context, handler http.Handler) error {
 	if err := s.Start(handler); err != nil {
 		return err
@@ -97,22 +249,109 @@ func (s *Server) ListenAndServe(ctx context.Context, handler http.Handler) error
 	case serveErr := <-s.Done():
 		return serveErr
 	case <-ctx.Done():
-		shutdownCtx, cancel := context.WithTimeout(context.Background(), s.config.ShutdownTimeout)
-		defer cancel()
-		return s.Shutdown(shutdownCtx)
+		return s.ShutdownInPhases(s.config.ShutdownBudgets).Err()
 	}
 }
 
+// ShutdownInPhases stops the server in two time-boxed phases: stop_accepting
+// closes the listeners, drain_requests waits for in-flight requests. When a
+// budget expires the remaining connections are closed and the cut-off
+// requests are listed in the report. Zero budgets are derived from
+// ShutdownTimeout.
+func (s *Server) ShutdownInPhases(budgets ShutdownBudgets) *ShutdownReport {
+	report := &ShutdownReport{}
+	s.shutdownPhases(budgets.withDefaults(s.config.ShutdownTimeout), report)
+	return report
+}
+
+func (s *Server) shutdownPhases(b ShutdownBudgets, report *ShutdownReport) {
+	s.mu.Lock()
+	httpServer := s.httpServer
+	stopped := s.stopped
+	servers := []*http.Server{httpServer}
+	for _, extra := range s.extras {
+		servers = append(servers, extra.httpServer)
+	}
+	s.mu.Unlock()
+	if httpServer == nil {
+		return
+	}
+
+	// http.Server.Shutdown closes the listeners first and then waits for
+	// active connections; drainCtx is cancelled when the drain budget expires.
+	drainCtx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	drained := make(chan error, 1)
+	go func() {
+		errs := make([]error, len(servers))
+		var wg sync.WaitGroup
+		for i, srv := range servers {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				errs[i] = srv.Shutdown(drainCtx)
+			}()
+		}
+		wg.Wait()
+		drained <- errors.Join(errs...)
+	}()
+	closeAll := func() {
+		for _, srv := range servers {
+			_ = srv.Close()
+		}
+	}
+
+	phase := PhaseReport{Phase: PhaseStopAccepting, Budget: b.StopAccepting}
+	start := time.Now()
+	timer := time.NewTimer(b.StopAccepting)
+	select {
+	case <-stopped:
+	case <-timer.C:
+		phase.TimedOut = true
+		phase.Unfinished = []string{"listeners"}
+		closeAll()
+	}
+	timer.Stop()
+	phase.Elapsed = time.Since(start)
+	report.Phases = append(report.Phases, phase)
+
+	phase = PhaseReport{Phase: PhaseDrainRequests, Budget: b.DrainRequests}
+	start = time.Now()
+	timer = time.NewTimer(b.DrainRequests)
+	select {
+	case err := <-drained:
+		phase.Err = err
+	case <-timer.C:
+		phase.TimedOut = true
+		phase.Unfinished = s.inflight.list()
+		cancel()
+		closeAll()
+		<-drained
+	}
+	timer.Stop()
+	phase.Elapsed = time.Since(start)
+	report.Phases = append(report.Phases, phase)
+
+	s.mu.Lock()
+	s.started = false
+	s.mu.Unlock()
+}
+
 // Shutdown gracefully stops the server.
 func (s *Server) Shutdown(ctx context.Context) error {
 	s.mu.Lock()
 	httpServer := s.httpServer
+	extras := s.extras
 	s.mu.Unlock()
 
 	if httpServer == nil {
 		return nil
 	}
-	if err := httpServer.Shutdown(ctx); err != nil {
+	errs := []error{httpServer.Shutdown(ctx)}
+	for _, extra := range extras {
+		errs = append(errs, extra.httpServer.Shutdown(ctx))
+	}
+	if err := errors.Join(errs...); err != nil {
 		return err
 	}
 
@@ -122,6 +361,17 @@ func (s *Server) Shutdown(ctx context.Context) error {
 	return nil
 }
 
+// SetMaintenance turns maintenance mode on or off without restarting. While on,
+// every non-allowlisted request receives 503 with a Retry-After header.
+func (s *Server) SetMaintenance(enabled bool) {
+	s.maintenance.Set(enabled)
+}
+
+// Maintenance returns the server's maintenance switch.
+func (s *Server) Maintenance() *Maintenance {
+	return s.maintenance
+}
+
 // Addr returns the listening address.
 func (s *Server) Addr() string {
 	s.mu.Lock()
@@ -135,6 +385,20 @@ func (s *Server) Addr() string {
 	return addr
 }
 
+// ListenerAddr returns the bound address of the named additional listener, or
+// an empty string when no listener with that name is running.
+func (s *Server) ListenerAddr(name string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, extra := range s.extras {
+		if extra.name == name {
+			return extra.listener.Addr().String()
+		}
+	}
+	return ""
+}
+
 // Done returns a channel that receives the background Serve result after Start
 // or Run begins serving. A nil value means the server stopped via Shutdown.
 func (s *Server) Done() <-chan error {
@@ -177,6 +441,7 @@ func (s *Server) startServing(handler http.Handler) (<-chan error, error) {
 		return nil, err
 	}
 
+	handler = s.Handler(handler)
 	httpServer := s.newHTTPServer(handler)
 	ln, err := s.listen()
 	if err != nil {
@@ -184,14 +449,29 @@ func (s *Server) startServing(handler http.Handler) (<-chan error, error) {
 		return nil, err
 	}
 
+	extras, err := s.listenExtras(handler)
+	if err != nil {
+		_ = ln.Close()
+		s.releaseStart()
+		return nil, err
+	}
+
 	s.mu.Lock()
 	s.httpServer = httpServer
 	s.listener = ln
+	s.extras = extras
 	serveChan := s.serveLocked(ln)
 	s.mu.Unlock()
 	return serveChan, nil
 }
 
+// Handler returns handler as the server serves it, behind the maintenance
+// gate and the in-flight request tracking that Shutdown drains. It lets
+// tests drive a server through httptest without binding a port.
+func (s *Server) Handler(handler http.Handler) http.Handler {
+	return s.inflight.wrap(s.maintenance.Handler(handler))
+}
+
 func (s *Server) newHTTPServer(handler http.Handler) *http.Server {
 	return &http.Server{
 		Handler:           handler,
@@ -204,32 +484,207 @@ func (s *Server) newHTTPServer(handler http.Handler) *http.Server {
 }
 
 func (s *Server) listen() (net.Listener, error) {
-	ln, err := net.Listen(s.config.Network, s.config.Addr)
+	return listenWithTLS(s.config.Network, s.config.Addr, tlsFiles{
+		certFile:   s.config.TLSCertFile,
+		keyFile:    s.config.TLSKeyFile,
+		clientCA:   s.config.TLSClientCAFile,
+		clientAuth: s.config.TLSClientAuth,
+		hosts:      s.config.TLSHosts,
+		acme: acmeConfig{
+			cacheDir:     s.config.ACMECacheDir,
+			email:        s.config.ACMEEmail,
+			directoryURL: s.config.ACMEDirectoryURL,
+		},
+	})
+}
+
+func (s *Server) listenExtras(handler http.Handler) ([]*extraListener, error) {
+	extras := make([]*extraListener, 0, len(s.config.Listeners))
+	for _, lc := range s.config.Listeners {
+		ln, err := listenWithTLS(lc.Network, lc.Addr, tlsFiles{
+			certFile:   lc.TLSCertFile,
+			keyFile:    lc.TLSKeyFile,
+			clientCA:   lc.TLSClientCAFile,
+			clientAuth: lc.TLSClientAuth,
+		})
+		if err != nil {
+			for _, extra := range extras {
+				_ = extra.listener.Close()
+			}
+			return nil, fmt.Errorf("listener %q: %w", lc.Name, err)
+		}
+		extras = append(extras, &extraListener{
+			name:       lc.Name,
+			httpServer: s.newHTTPServer(listenerHandler(lc, handler)),
+			listener:   ln,
+		})
+	}
+	return extras, nil
+}
+
+type tlsFiles struct {
+	certFile   string
+	keyFile    string
+	clientCA   string
+	clientAuth tls.ClientAuthType
+	hosts      []TLSHost
+	acme       acmeConfig
+}
+
+func listenWithTLS(network, addr string, files tlsFiles) (net.Listener, error) {
+	if (files.certFile == "" || files.keyFile == "") && len(files.hosts) == 0 {
+		ln, err := net.Listen(network, addr)
+		if err != nil {
+			return nil, fmt.Errorf("listen error: %w", err)
+		}
+		return ln, nil
+	}
+
+	tlsConfig, err := files.config()
+	if err != nil {
+		return nil, err
+	}
+	ln, err := net.Listen(network, addr)
 	if err != nil {
 		return nil, fmt.Errorf("listen error: %w", err)
 	}
+	return tls.NewListener(ln, tlsConfig), nil
+}
 
-	if s.config.TLSCertFile == "" || s.config.TLSKeyFile == "" {
-		return ln, nil
+func (f tlsFiles) config() (*tls.Config, error) {
+	config := &tls.Config{}
+	if f.certFile != "" && f.keyFile != "" {
+		cert, err := tls.LoadX509KeyPair(f.certFile, f.keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("load tls cert error: %w", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+	if len(f.hosts) > 0 {
+		sni, err := newSNICertificates(f.hosts, f.acme)
+		if err != nil {
+			return nil, err
+		}
+		sni.apply(config)
+	}
+	if f.clientCA == "" {
+		config.ClientAuth = f.clientAuth
+		return config, nil
 	}
 
-	cert, err := tls.LoadX509KeyPair(s.config.TLSCertFile, s.config.TLSKeyFile)
+	pem, err := os.ReadFile(f.clientCA)
 	if err != nil {
-		_ = ln.Close()
-		return nil, fmt.Errorf("load tls cert error: %w", err)
+		return nil, fmt.Errorf("load tls client ca error: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("load tls client ca error: no certificates in %s", f.clientCA)
+	}
+	config.ClientCAs = pool
+	config.ClientAuth = f.clientAuth
+	if config.ClientAuth == tls.NoClientCert {
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return config, nil
+}
+
+// ParseClientAuth maps a config value to a tls.ClientAuthType. Accepted values
+// are "none", "request", "require-any", "verify-if-given" and "require"
+// (require and verify). Empty and "none" return tls.NoClientCert; unknown
+// values return an error, so a typo never changes verification silently.
+func ParseClientAuth(s string) (tls.ClientAuthType, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "none":
+		return tls.NoClientCert, nil
+	case "request":
+		return tls.RequestClientCert, nil
+	case "require-any":
+		return tls.RequireAnyClientCert, nil
+	case "verify-if-given":
+		return tls.VerifyClientCertIfGiven, nil
+	case "require":
+		return tls.RequireAndVerifyClientCert, nil
+	default:
+		return tls.NoClientCert, fmt.Errorf("unknown clientAuth %q: use none, request, require-any, verify-if-given or require", s)
 	}
-	return tls.NewListener(ln, &tls.Config{Certificates: []tls.Certificate{cert}}), nil
 }
 
+// listenerHandler resolves the handler served by an additional listener.
+func listenerHandler(lc ListenerConfig, handler http.Handler) http.Handler {
+	if lc.RedirectHTTPS {
+		return httpsRedirectHandler(lc.HTTPSAddr)
+	}
+	if lc.Handler != nil {
+		handler = lc.Handler
+	}
+	if lc.PathPrefix == "" {
+		return handler
+	}
+	prefix := strings.TrimRight(lc.PathPrefix, "/")
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != prefix && !strings.HasPrefix(r.URL.Path, prefix+"/") {
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			w.WriteHeader(http.StatusNotFound)
+			_ = json.NewEncoder(w).Encode(Response{Status: http.StatusNotFound, Msg: "Not Found"})
+			return
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// httpsRedirectHandler redirects every request to the same host and URI over HTTPS.
+func httpsRedirectHandler(httpsAddr string) http.Handler {
+	port := ""
+	if _, p, err := net.SplitHostPort(httpsAddr); err == nil && p != "443" {
+		port = p
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		if port != "" {
+			host = net.JoinHostPort(host, port)
+		}
+		http.Redirect(w, r, "https://"+host+r.URL.RequestURI(), http.StatusPermanentRedirect)
+	})
+}
+
+// serveLocked serves the primary and additional listeners. The first
+// unexpected serve error closes the remaining listeners so Done reports it.
 func (s *Server) serveLocked(ln net.Listener) <-chan error {
 	s.done = make(chan error, 1)
-	done := s.done
-	httpServer := s.httpServer
+	s.stopped = make(chan struct{})
+	done, stopped := s.done, s.stopped
+	servers := []*http.Server{s.httpServer}
+	listeners := []net.Listener{ln}
+	for _, extra := range s.extras {
+		servers = append(servers, extra.httpServer)
+		listeners = append(listeners, extra.listener)
+	}
+
+	results := make(chan error, len(servers))
+	for i := range servers {
+		go func(srv *http.Server, l net.Listener) {
+			err := srv.Serve(l)
+			if err == http.ErrServerClosed {
+				err = nil
+			}
+			results <- err
+		}(servers[i], listeners[i])
+	}
+
 	go func() {
-		err := httpServer.Serve(ln)
-		if err == http.ErrServerClosed {
-			err = nil
+		var err error
+		for range servers {
+			if serveErr := <-results; serveErr != nil && err == nil {
+				err = serveErr
+				for _, srv := range servers {
+					_ = srv.Close()
+				}
+			}
 		}
+		close(stopped)
 		s.mu.Lock()
 		if s.done == done {
 			s.started = false
@@ -2,8 +2,21 @@ package golitekit
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
 	"net"
 	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
 	"strings"
 	"sync"
 	"testing"
@@ -74,7 +87,7 @@ func TestNewServer_PreservesExplicitConfig(t *testing.T) {
 
 	srv := NewServer(cfg)
 
-	if srv.config != cfg {
+	if !reflect.DeepEqual(srv.config, cfg) {
 		t.Fatalf("config = %#v, want %#v", srv.config, cfg)
 	}
 }
@@ -560,3 +573,407 @@ func TestApp_ConcurrentStart_AllowsOneServer(t *testing.T) {
 		t.Fatalf("App.Shutdown: %v", err)
 	}
 }
+
+func TestNewServer_DoesNotModifyListeners(t *testing.T) {
+	listeners := []ListenerConfig{{Name: "admin", Addr: "127.0.0.1:0"}}
+	srv := NewServer(ServerConfig{Network: "tcp4", Listeners: listeners})
+	if listeners[0].Network != "" {
+		t.Fatalf("caller's listener network = %q, want it untouched", listeners[0].Network)
+	}
+	if srv.config.Listeners[0].Network != "tcp4" {
+		t.Fatalf("listener network = %q, want the server's tcp4", srv.config.Listeners[0].Network)
+	}
+}
+
+func TestServer_ServesAdditionalListeners(t *testing.T) {
+	adminHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("admin"))
+	})
+	srv := NewServer(ServerConfig{
+		Addr: "127.0.0.1:0",
+		Listeners: []ListenerConfig{
+			{Name: "admin", Addr: "127.0.0.1:0", PathPrefix: "/_admin", Handler: adminHandler},
+			{Name: "redirect", Addr: "127.0.0.1:0", RedirectHTTPS: true, HTTPSAddr: ":8443"},
+		},
+	})
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("main"))
+	})
+	if err := srv.Start(handler); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := srv.Shutdown(ctx); err != nil {
+			t.Fatalf("Shutdown: %v", err)
+		}
+	}()
+
+	get := func(url string) (*http.Response, string) {
+		t.Helper()
+		client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		}}
+		resp, err := client.Get(url)
+		if err != nil {
+			t.Fatalf("GET %s: %v", url, err)
+		}
+		defer resp.Body.Close()
+		var b strings.Builder
+		_, _ = io.Copy(&b, resp.Body)
+		return resp, b.String()
+	}
+
+	if _, body := get("http://" + srv.Addr() + "/"); body != "main" {
+		t.Fatalf("primary body = %q, want main", body)
+	}
+
+	adminAddr := srv.ListenerAddr("admin")
+	if _, body := get("http://" + adminAddr + "/_admin/routes"); body != "admin" {
+		t.Fatalf("admin body = %q, want admin", body)
+	}
+	if resp, _ := get("http://" + adminAddr + "/public"); resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("admin non-prefix status = %d, want 404", resp.StatusCode)
+	}
+
+	resp, _ := get("http://" + srv.ListenerAddr("redirect") + "/a?b=1")
+	if resp.StatusCode != http.StatusPermanentRedirect {
+		t.Fatalf("redirect status = %d, want 308", resp.StatusCode)
+	}
+	if loc := resp.Header.Get("Location"); loc != "https://127.0.0.1:8443/a?b=1" {
+		t.Fatalf("Location = %q, want https://127.0.0.1:8443/a?b=1", loc)
+	}
+}
+
+func TestServer_ListenerFailureReleasesPrimary(t *testing.T) {
+	occupied, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer occupied.Close()
+
+	srv := NewServer(ServerConfig{
+		Addr:      "127.0.0.1:0",
+		Listeners: []ListenerConfig{{Name: "busy", Addr: occupied.Addr().String()}},
+	})
+	if err := srv.Start(http.NotFoundHandler()); err == nil {
+		t.Fatal("expected Start to fail when an additional listener cannot bind")
+	}
+	srv.config.Listeners = nil
+	if err := srv.Start(http.NotFoundHandler()); err != nil {
+		t.Fatalf("Start after failure: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_ = srv.Shutdown(ctx)
+}
+
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+func newTestCA(t *testing.T) *testCA {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate ca key: %v", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("create ca: %v", err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	return &testCA{cert: cert, key: key, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+// issue signs a leaf certificate and returns its PEM-encoded cert and key.
+func (ca *testCA) issue(t *testing.T, subject pkix.Name, usage x509.ExtKeyUsage) (certPEM, keyPEM []byte) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      subject,
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatalf("create cert: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("marshal key: %v", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+func TestServer_MutualTLSExposesClientSubject(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCA(t)
+	serverCert, serverKey := ca.issue(t, pkix.Name{CommonName: "server"}, x509.ExtKeyUsageServerAuth)
+	clientCert, clientKey := ca.issue(t, pkix.Name{CommonName: "alice", Organization: []string{"Example"}}, x509.ExtKeyUsageClientAuth)
+
+	write := func(name string, data []byte) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, data, 0o600); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+		return path
+	}
+
+	app := NewApp()
+	app.GET("/whoami", HandlerFunc(func(ctx *Context) error {
+		return ctx.String(http.StatusOK, ctx.ClientSubject())
+	}))
+	srv := NewServer(ServerConfig{
+		Addr:            "127.0.0.1:0",
+		TLSCertFile:     write("server.crt", serverCert),
+		TLSKeyFile:      write("server.key", serverKey),
+		TLSClientCAFile: write("ca.crt", ca.pem),
+	})
+	if err := srv.Start(app.Handler()); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = srv.Shutdown(ctx)
+	}()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	newClient := func(certs ...tls.Certificate) *http.Client {
+		return &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{
+			RootCAs:      roots,
+			Certificates: certs,
+		}}}
+	}
+
+	pair, err := tls.X509KeyPair(clientCert, clientKey)
+	if err != nil {
+		t.Fatalf("client key pair: %v", err)
+	}
+	resp, err := newClient(pair).Get("https://" + srv.Addr() + "/whoami")
+	if err != nil {
+		t.Fatalf("GET with client cert: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if got := string(body); got != "CN=alice,O=Example" {
+		t.Fatalf("subject = %q, want CN=alice,O=Example", got)
+	}
+
+	if resp, err := newClient().Get("https://" + srv.Addr() + "/whoami"); err == nil {
+		resp.Body.Close()
+		t.Fatal("expected handshake failure without a client certificate")
+	}
+}
+
+func TestServer_SelectsCertificateBySNI(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCA(t)
+	write := func(name string, data []byte) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, data, 0o600); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+		return path
+	}
+	hostFiles := func(cn string) (string, string) {
+		cert, key := ca.issue(t, pkix.Name{CommonName: cn}, x509.ExtKeyUsageServerAuth)
+		return write(cn+".crt", cert), write(cn+".key", key)
+	}
+	defaultCert, defaultKey := hostFiles("default")
+	apiCert, apiKey := hostFiles("api")
+	adminCert, adminKey := hostFiles("admin")
+
+	srv := NewServer(ServerConfig{
+		Addr:        "127.0.0.1:0",
+		TLSCertFile: defaultCert,
+		TLSKeyFile:  defaultKey,
+		TLSHosts: []TLSHost{
+			{Host: "api.example.com", CertFile: apiCert, KeyFile: apiKey},
+			{Host: "*.admin.example.com", CertFile: adminCert, KeyFile: adminKey},
+		},
+	})
+	if err := srv.Start(http.NotFoundHandler()); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer srv.Shutdown(context.Background())
+
+	for serverName, want := range map[string]string{
+		"API.example.com":      "api",
+		"eu.admin.example.com": "admin",
+		"admin.example.com":    "default",
+		"other.example.com":    "default",
+	} {
+		conn, err := tls.Dial("tcp", srv.Addr(), &tls.Config{ServerName: serverName, InsecureSkipVerify: true})
+		if err != nil {
+			t.Fatalf("dial %s: %v", serverName, err)
+		}
+		if got := conn.ConnectionState().PeerCertificates[0].Subject.CommonName; got != want {
+			t.Errorf("certificate for %s = %q, want %q", serverName, got, want)
+		}
+		conn.Close()
+	}
+}
+
+func TestParseClientAuth(t *testing.T) {
+	cases := map[string]tls.ClientAuthType{
+		"":                tls.NoClientCert,
+		"none":            tls.NoClientCert,
+		"request":         tls.RequestClientCert,
+		"require-any":     tls.RequireAnyClientCert,
+		"verify-if-given": tls.VerifyClientCertIfGiven,
+		"require":         tls.RequireAndVerifyClientCert,
+	}
+	for in, want := range cases {
+		if got, err := ParseClientAuth(in); err != nil || got != want {
+			t.Errorf("ParseClientAuth(%q) = %v, %v, want %v", in, got, err, want)
+		}
+	}
+	if _, err := ParseClientAuth("typo"); err == nil {
+		t.Error("ParseClientAuth(typo) succeeded, want an error")
+	}
+}
+
+func TestClientAuthFromEnv(t *testing.T) {
+	tests := []struct {
+		value      string
+		wantCA     string
+		wantClient tls.ClientAuthType
+	}{
+		// The listener upgrades the default to require with a CA file.
+		{"", "ca.pem", tls.NoClientCert},
+		{"none", "", tls.NoClientCert},
+		{"verify-if-given", "ca.pem", tls.VerifyClientCertIfGiven},
+	}
+	for _, tt := range tests {
+		ca, clientAuth, err := clientAuthFromEnv("ca.pem", tt.value)
+		if err != nil || ca != tt.wantCA || clientAuth != tt.wantClient {
+			t.Errorf("clientAuthFromEnv(%q) = %q, %v, %v, want %q, %v", tt.value, ca, clientAuth, err, tt.wantCA, tt.wantClient)
+		}
+	}
+	if _, _, err := clientAuthFromEnv("ca.pem", "sometimes"); err == nil {
+		t.Error("clientAuthFromEnv(sometimes) succeeded, want an error")
+	}
+}
+
+func TestNewAppWithOptions_ServesOnConfiguredAddr(t *testing.T) {
+	app, err := NewAppWithOptions(WithAddr("127.0.0.1:0"), WithMiddlewarePreset(MinimalMiddlewares))
+	if err != nil {
+		t.Fatalf("NewAppWithOptions: %v", err)
+	}
+	var names []string
+	for _, d := range app.router.middlewares.Describe() {
+		names = append(names, d.Name)
+	}
+	if !reflect.DeepEqual(names, []string{MiddlewareErrorHandler, MiddlewareContext}) {
+		t.Fatalf("middlewares = %v", names)
+	}
+	app.GET("/", func(ctx *Context) error {
+		return ctx.JSON(http.StatusOK, map[string]string{"ok": "true"})
+	})
+	if err := app.Start(); err != nil {
+		t.Fatalf("App.Start: %v", err)
+	}
+	defer app.Shutdown(context.Background())
+
+	resp, err := http.Get("http://" + app.currentServer().Addr() + "/")
+	if err != nil {
+		t.Fatalf("GET: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+}
+
+func TestNewAppWithOptions_RoutesOnCallerMux(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/legacy", func(w http.ResponseWriter, r *http.Request) { io.WriteString(w, "legacy") })
+	app, err := NewAppWithOptions(WithMux(mux))
+	if err != nil {
+		t.Fatal(err)
+	}
+	app.GET("/new", func(ctx *Context) error { return ctx.String(http.StatusOK, "new") })
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+	for path, want := range map[string]string{"/legacy": "legacy", "/new": "new"} {
+		resp, err := http.Get(srv.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if string(body) != want {
+			t.Errorf("GET %s = %q, want %q", path, body, want)
+		}
+	}
+}
+
+func TestNewAppWithOptions_RejectsInvalidOptions(t *testing.T) {
+	tests := []struct {
+		name string
+		opt  ServiceOption
+	}{
+		{"cert without key", WithServerConfig(ServerConfig{Addr: ":8443", TLSCertFile: "cert.pem"})},
+		{"bad addr", WithAddr("localhost")},
+		{"duplicate listener", WithServerConfig(ServerConfig{Addr: ":0", Listeners: []ListenerConfig{{Name: "a", Addr: ":0"}, {Name: "a", Addr: ":0"}}})},
+		{"preset breaks order", WithMiddlewarePreset(func(mq *MiddlewareQueue) { mq.Remove(MiddlewareErrorHandler) })},
+		{"sni host without key", WithServerConfig(ServerConfig{Addr: ":8443", TLSHosts: []TLSHost{{Host: "a.example.com", CertFile: "a.pem"}}})},
+		{"acme without cache", WithServerConfig(ServerConfig{Addr: ":8443", TLSHosts: []TLSHost{{Host: "a.example.com", ACME: true}}})},
+		{"acme wildcard", WithServerConfig(ServerConfig{Addr: ":8443", ACMECacheDir: "certs", TLSHosts: []TLSHost{{Host: "*.example.com", ACME: true}}})},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewAppWithOptions(tt.opt); err == nil {
+				t.Fatal("want an error")
+			}
+		})
+	}
+}
+
+func TestServer_HandlerAppliesMaintenanceWithoutListening(t *testing.T) {
+	srv := NewServer(ServerConfig{})
+	ts := httptest.NewServer(srv.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "ok")
+	})))
+	defer ts.Close()
+
+	get := func() int {
+		resp, err := http.Get(ts.URL + "/")
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	if code := get(); code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", code)
+	}
+	srv.SetMaintenance(true)
+	if code := get(); code != http.StatusServiceUnavailable {
+		t.Fatalf("status in maintenance = %d, want 503", code)
+	}
+}
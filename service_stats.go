@@ -0,0 +1,230 @@
+package golitekit
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	DefaultServiceStatsWindow  = time.Minute
+	DefaultServiceStatsBuckets = 6
+)
+
+// ServiceStatsOptions configures the sliding window of ServiceStats.
+type ServiceStatsOptions struct {
+	Window  time.Duration // percentile window, defaults to DefaultServiceStatsWindow
+	Buckets int           // number of histograms the window is split into
+}
+
+// ServiceStat is the snapshot of one service name. Calls and Errors count
+// every span since the start or the last Reset; the percentiles, in
+// milliseconds, cover the spans that ended within Window.
+type ServiceStat struct {
+	Service     string  `json:"service"`
+	Calls       uint64  `json:"calls"`
+	Errors      uint64  `json:"errors"`
+	WindowCalls uint64  `json:"window_calls"`
+	P50         float64 `json:"p50_ms"`
+	P95         float64 `json:"p95_ms"`
+	P99         float64 `json:"p99_ms"`
+}
+
+type serviceStatsBucket struct {
+	start int64
+	hist  *LatencyHistogram
+}
+
+type serviceEntry struct {
+	calls  atomic.Uint64
+	errors atomic.Uint64
+
+	mu      sync.Mutex
+	buckets []serviceStatsBucket
+}
+
+// ServiceStats aggregates the spans started with StartSpan by name: call and
+// error counts, and p50/p95/p99 latencies over a sliding window. Install it
+// with Middleware, which keeps the request's observer (e.g. otel) working,
+// and report it with AdminOptions.Services or otel.RegisterServiceMetrics.
+type ServiceStats struct {
+	opts       ServiceStatsOptions
+	bucketSize int64
+
+	mu       sync.RWMutex
+	services map[string]*serviceEntry
+	now      func() time.Time
+}
+
+// NewServiceStats creates a ServiceStats, filling zero-valued options with
+// defaults.
+func NewServiceStats(opts ...ServiceStatsOptions) *ServiceStats {
+	var opt ServiceStatsOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if opt.Window <= 0 {
+		opt.Window = DefaultServiceStatsWindow
+	}
+	if opt.Buckets <= 0 {
+		opt.Buckets = DefaultServiceStatsBuckets
+	}
+	bucketSize := int64(opt.Window) / int64(opt.Buckets)
+	if bucketSize <= 0 {
+		bucketSize = 1
+	}
+	return &ServiceStats{
+		opts:       opt,
+		bucketSize: bucketSize,
+		services:   make(map[string]*serviceEntry),
+		now:        time.Now,
+	}
+}
+
+// Middleware makes StartSpan within the request record into s. Spans are
+// still started by the observer the request already had, if any.
+func (s *ServiceStats) Middleware() Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			ctx = WithObserverContext(ctx, s.Wrap(ObserverFromContext(ctx)))
+			return next(ctx, w, r.WithContext(ctx))
+		}
+	}
+}
+
+// Wrap returns an Observer that starts spans with next, or no-op spans when
+// next is nil, and records each one into s when it ends.
+func (s *ServiceStats) Wrap(next Observer) Observer {
+	return serviceStatsObserver{stats: s, next: next}
+}
+
+type serviceStatsObserver struct {
+	stats *ServiceStats
+	next  Observer
+}
+
+func (o serviceStatsObserver) StartSpan(ctx context.Context, name string, attrs ...Attribute) (context.Context, Span) {
+	var span Span = noopSpan{}
+	if o.next != nil {
+		ctx, span = o.next.StartSpan(ctx, name, attrs...)
+	}
+	return ctx, &serviceStatsSpan{Span: span, stats: o.stats, name: name, started: time.Now()}
+}
+
+// Record counts one call of service that took d.
+func (s *ServiceStats) Record(service string, d time.Duration, failed bool) {
+	if s == nil {
+		return
+	}
+	e := s.entry(service)
+	e.calls.Add(1)
+	if failed {
+		e.errors.Add(1)
+	}
+
+	start := s.now().UnixNano() / s.bucketSize
+	e.mu.Lock()
+	b := &e.buckets[start%int64(len(e.buckets))]
+	if b.hist == nil || b.start != start {
+		*b = serviceStatsBucket{start: start, hist: &LatencyHistogram{}}
+	}
+	h := b.hist
+	e.mu.Unlock()
+	h.Record(d)
+}
+
+func (s *ServiceStats) entry(service string) *serviceEntry {
+	s.mu.RLock()
+	e := s.services[service]
+	s.mu.RUnlock()
+	if e != nil {
+		return e
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if e = s.services[service]; e == nil {
+		e = &serviceEntry{buckets: make([]serviceStatsBucket, s.opts.Buckets)}
+		s.services[service] = e
+	}
+	return e
+}
+
+// Snapshot returns every service's stats sorted by name.
+func (s *ServiceStats) Snapshot() []ServiceStat {
+	if s == nil {
+		return nil
+	}
+	current := s.now().UnixNano() / s.bucketSize
+	oldest := current - int64(s.opts.Buckets) + 1
+
+	s.mu.RLock()
+	stats := make([]ServiceStat, 0, len(s.services))
+	for name, e := range s.services {
+		var window LatencyHistogram
+		e.mu.Lock()
+		for _, b := range e.buckets {
+			if b.hist != nil && b.start >= oldest && b.start <= current {
+				window.merge(b.hist)
+			}
+		}
+		e.mu.Unlock()
+		stats = append(stats, ServiceStat{
+			Service:     name,
+			Calls:       e.calls.Load(),
+			Errors:      e.errors.Load(),
+			WindowCalls: window.count.Load(),
+			P50:         durationMillis(window.Quantile(0.50)),
+			P95:         durationMillis(window.Quantile(0.95)),
+			P99:         durationMillis(window.Quantile(0.99)),
+		})
+	}
+	s.mu.RUnlock()
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Service < stats[j].Service })
+	return stats
+}
+
+// Window returns the length of the percentile window.
+func (s *ServiceStats) Window() time.Duration {
+	return s.opts.Window
+}
+
+// Reset drops every service.
+func (s *ServiceStats) Reset() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	s.services = make(map[string]*serviceEntry)
+	s.mu.Unlock()
+}
+
+type serviceStatsSpan struct {
+	Span
+	stats   *ServiceStats
+	name    string
+	started time.Time
+	failed  bool
+	ended   atomic.Bool
+}
+
+func (s *serviceStatsSpan) End() {
+	if s.ended.CompareAndSwap(false, true) {
+		s.stats.Record(s.name, time.Since(s.started), s.failed)
+	}
+	s.Span.End()
+}
+
+func (s *serviceStatsSpan) SetError(err error) {
+	if err != nil {
+		s.failed = true
+	}
+	s.Span.SetError(err)
+}
+
+func (s *serviceStatsSpan) SetStatus(code SpanStatus, message string) {
+	s.failed = code == SpanStatusError
+	s.Span.SetStatus(code, message)
+}
@@ -0,0 +1,117 @@
+package golitekit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestServiceStats_CountsSpansByName(t *testing.T) {
+	stats := NewServiceStats()
+	ctx := WithObserverContext(context.Background(), stats.Wrap(nil))
+
+	for i := 0; i < 3; i++ {
+		_, span := StartSpan(ctx, "db.query")
+		span.End()
+	}
+	_, span := StartSpan(ctx, "db.query")
+	span.SetError(errors.New("timeout"))
+	span.End()
+	span.End() // a second End is not counted
+	_, span = StartSpan(ctx, "redis.get")
+	span.SetStatus(SpanStatusError, "down")
+	span.End()
+
+	got := stats.Snapshot()
+	if len(got) != 2 || got[0].Service != "db.query" || got[1].Service != "redis.get" {
+		t.Fatalf("services = %+v", got)
+	}
+	if got[0].Calls != 4 || got[0].Errors != 1 || got[0].WindowCalls != 4 {
+		t.Errorf("db.query = %+v, want 4 calls, 1 error", got[0])
+	}
+	if got[1].Calls != 1 || got[1].Errors != 1 {
+		t.Errorf("redis.get = %+v, want 1 call, 1 error", got[1])
+	}
+}
+
+func TestServiceStats_SlidingWindow(t *testing.T) {
+	stats := NewServiceStats(ServiceStatsOptions{Window: time.Minute, Buckets: 6})
+	now := time.Unix(1_700_000_000, 0)
+	stats.now = func() time.Time { return now }
+
+	for i := 1; i <= 100; i++ {
+		stats.Record("api", time.Duration(i)*time.Millisecond, false)
+	}
+	snap := stats.Snapshot()[0]
+	if snap.P50 < 49 || snap.P50 > 51 || snap.P95 < 94 || snap.P95 > 96 || snap.P99 < 98 || snap.P99 > 100 {
+		t.Fatalf("percentiles = %+v", snap)
+	}
+
+	now = now.Add(30 * time.Second)
+	stats.Record("api", 500*time.Millisecond, false)
+	if snap := stats.Snapshot()[0]; snap.WindowCalls != 101 {
+		t.Fatalf("within window = %+v", snap)
+	}
+
+	// the first 100 calls age out, the counters keep them
+	now = now.Add(45 * time.Second)
+	snap = stats.Snapshot()[0]
+	if snap.Calls != 101 || snap.WindowCalls != 1 || snap.P50 < 490 {
+		t.Fatalf("after window = %+v", snap)
+	}
+
+	now = now.Add(time.Minute)
+	if snap := stats.Snapshot()[0]; snap.WindowCalls != 0 || snap.P99 != 0 {
+		t.Fatalf("empty window = %+v", snap)
+	}
+}
+
+func TestServiceStats_WrapKeepsObserver(t *testing.T) {
+	next := &recordingObserver{}
+	stats := NewServiceStats()
+	_, span := stats.Wrap(next).StartSpan(context.Background(), "mail.send")
+	span.End()
+	if len(next.started) != 1 || next.started[0] != "mail.send" {
+		t.Errorf("next observer saw %v", next.started)
+	}
+	if got := stats.Snapshot(); len(got) != 1 || got[0].Calls != 1 {
+		t.Errorf("stats = %+v", got)
+	}
+}
+
+func TestServiceStats_Admin(t *testing.T) {
+	stats := NewServiceStats()
+	app := NewApp()
+	app.Use(stats.Middleware())
+	app.GET("/users/{id}", HandlerFunc(func(ctx *Context) error {
+		_, span := StartSpan(ctx.Request().Context(), "db.query")
+		span.End()
+		return nil
+	}))
+	app.MountAdmin(AdminOptions{Token: "secret", Services: stats})
+
+	for i := 0; i < 2; i++ {
+		adminRequest(app, http.MethodGet, "/users/1", "")
+	}
+	rec := adminRequest(app, http.MethodGet, "/_admin/services", "")
+	var body struct {
+		Window   string        `json:"window"`
+		Services []ServiceStat `json:"services"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode: %v (%s)", err, rec.Body)
+	}
+	if body.Window != "1m0s" || len(body.Services) != 1 || body.Services[0].Calls != 2 {
+		t.Fatalf("body = %+v", body)
+	}
+
+	if rec := adminRequest(app, http.MethodPost, "/_admin/services/reset", ""); rec.Code != http.StatusOK {
+		t.Fatalf("reset status = %d", rec.Code)
+	}
+	if got := stats.Snapshot(); len(got) != 0 {
+		t.Fatalf("stats after reset = %+v", got)
+	}
+}
@@ -2,21 +2,43 @@ package golitekit
 
 import (
 	"fmt"
+	"net"
+	"net/http"
+	"strings"
 	"sync"
+	"sync/atomic"
 
 	"github.com/hansir-hsj/GoLiteKit/logger"
+	"github.com/hansir-hsj/GoLiteKit/workerpool"
 	"github.com/redis/go-redis/v9"
 	"gorm.io/gorm"
 )
 
 // Services holds framework dependencies and startup-registered custom services.
 type Services struct {
-	db                      *gorm.DB
-	redis                   *redis.Client
+	// db and redis are swapped by subsystem restarts, see Restart.
+	db                      atomic.Pointer[gorm.DB]
+	redis                   atomic.Pointer[redis.Client]
 	logger                  logger.Logger
+	namedLoggers            map[string]logger.Logger
 	panicLogger             *logger.PanicLogger
 	observer                Observer
 	observabilityMiddleware Middleware
+	healthMonitor           *HealthMonitor
+	exports                 *ExportManager
+	catalog                 *Catalog
+	journal                 *RequestJournal
+	workerPools             map[string]*workerpool.Pool
+	pprofAddr               string // serve pprof on its own listener, see env.PprofAddr
+	pprofToken              string // see env.PprofToken
+	profiler                *Profiler
+	startupLogOff           bool
+	middlewareDefaults      []func(*DefaultMiddlewareOptions)
+	middlewarePresets       []MiddlewarePreset
+	serverConfig            *ServerConfig
+	mux                     *http.ServeMux
+	dbOpener                DBOpener
+	restarters              map[string]RestartFunc
 
 	mu     sync.RWMutex
 	custom map[string]any
@@ -25,17 +47,30 @@ type Services struct {
 type ServiceOption func(*Services)
 
 func WithDB(db *gorm.DB) ServiceOption {
-	return func(s *Services) { s.db = db }
+	return func(s *Services) { s.db.Store(db) }
 }
 
 func WithRedis(client *redis.Client) ServiceOption {
-	return func(s *Services) { s.redis = client }
+	return func(s *Services) { s.redis.Store(client) }
 }
 
 func WithLogger(l logger.Logger) ServiceOption {
 	return func(s *Services) { s.logger = l }
 }
 
+// WithNamedLogger installs l as the logger named name, e.g.
+// logger.AccessLoggerName, which the app registers for logger.Named and
+// closes on shutdown. It takes precedence over the [loggers.<name>] section
+// NewAppFromConfig would load.
+func WithNamedLogger(name string, l logger.Logger) ServiceOption {
+	return func(s *Services) {
+		if s.namedLoggers == nil {
+			s.namedLoggers = make(map[string]logger.Logger)
+		}
+		s.namedLoggers[name] = l
+	}
+}
+
 func WithPanicLogger(pl *logger.PanicLogger) ServiceOption {
 	return func(s *Services) { s.panicLogger = pl }
 }
@@ -48,23 +83,159 @@ func WithObservabilityMiddleware(m Middleware) ServiceOption {
 	return func(s *Services) { s.observabilityMiddleware = m }
 }
 
+// WithDefaultMiddlewareOptions lets fn adjust the options of the default
+// middlewares, after NewAppFromConfig applied the env config:
+//
+//	glk.WithDefaultMiddlewareOptions(func(o *glk.DefaultMiddlewareOptions) {
+//		o.Timeout.Duration = 3 * time.Second
+//	})
+func WithDefaultMiddlewareOptions(fn func(*DefaultMiddlewareOptions)) ServiceOption {
+	return func(s *Services) { s.middlewareDefaults = append(s.middlewareDefaults, fn) }
+}
+
+// MiddlewarePreset edits the default middlewares when the app is built;
+// the result must pass MiddlewareQueue.Validate.
+type MiddlewarePreset func(mq *MiddlewareQueue)
+
+// MinimalMiddlewares keeps only the error handler and the response
+// rendering of the defaults, e.g. for embedding or tests.
+func MinimalMiddlewares(mq *MiddlewareQueue) {
+	for _, name := range []string{MiddlewareLogger, MiddlewareLogID, MiddlewareJournal, MiddlewareTimeout} {
+		mq.Remove(name)
+	}
+}
+
+// WithMiddlewarePreset applies preset to the default middlewares, after
+// WithDefaultMiddlewareOptions.
+func WithMiddlewarePreset(preset MiddlewarePreset) ServiceOption {
+	return func(s *Services) { s.middlewarePresets = append(s.middlewarePresets, preset) }
+}
+
+// WithServerConfig sets the config App.Start and App.ListenAndServe use
+// when called without one.
+func WithServerConfig(config ServerConfig) ServiceOption {
+	return func(s *Services) {
+		c := config
+		s.serverConfig = &c
+	}
+}
+
+// WithAddr sets the listen address of the app's server, on top of
+// WithServerConfig or DefaultServerConfig.
+func WithAddr(addr string) ServiceOption {
+	return func(s *Services) {
+		if s.serverConfig == nil {
+			c := DefaultServerConfig()
+			s.serverConfig = &c
+		}
+		s.serverConfig.Addr = addr
+	}
+}
+
+// WithMux registers the app's routes on mux instead of a new ServeMux, to
+// embed the app in an existing server.
+func WithMux(mux *http.ServeMux) ServiceOption {
+	return func(s *Services) { s.mux = mux }
+}
+
+// WithHealthMonitor installs a HealthMonitor that counts every request and
+// recovered panic for readiness checks.
+func WithHealthMonitor(m *HealthMonitor) ServiceOption {
+	return func(s *Services) { s.healthMonitor = m }
+}
+
+// WithExportManager installs the ExportManager used by AcceptExport-style
+// controllers and App.MountExports.
+func WithExportManager(m *ExportManager) ServiceOption {
+	return func(s *Services) { s.exports = m }
+}
+
+// WithWorkerPool registers pool under name for Services.WorkerPool; the
+// app drains it in the drain_jobs phase of GracefulShutdown.
+func WithWorkerPool(name string, pool *workerpool.Pool) ServiceOption {
+	return func(s *Services) {
+		if s.workerPools == nil {
+			s.workerPools = make(map[string]*workerpool.Pool)
+		}
+		s.workerPools[name] = pool
+	}
+}
+
+// WithProfiler installs a Profiler that the app starts with its server and
+// stops in the drain_jobs phase of GracefulShutdown.
+func WithProfiler(p *Profiler) ServiceOption {
+	return func(s *Services) { s.profiler = p }
+}
+
+// WithStartupLog turns the "startup" record App.Start logs off or back on;
+// it is on by default.
+func WithStartupLog(enabled bool) ServiceOption {
+	return func(s *Services) { s.startupLogOff = !enabled }
+}
+
 // WithService registers a named custom service during app construction.
 func WithService(key string, value any) ServiceOption {
 	return func(s *Services) { s.registerCustom(key, value) }
 }
 
+// validateServerConfig rejects a WithServerConfig/WithAddr config that
+// cannot be served.
+func (s *Services) validateServerConfig() error {
+	c := s.serverConfig
+	if c == nil {
+		return nil
+	}
+	network := c.Network
+	if network == "" {
+		network = "tcp"
+	}
+	if err := validateListenAddr("server", network, c.Addr, c.TLSCertFile, c.TLSKeyFile); err != nil {
+		return err
+	}
+	if err := validateTLSHosts(c.TLSHosts, c.ACMECacheDir); err != nil {
+		return err
+	}
+	names := make(map[string]bool, len(c.Listeners))
+	for _, l := range c.Listeners {
+		if names[l.Name] {
+			return fmt.Errorf("server config: duplicate listener %q", l.Name)
+		}
+		names[l.Name] = true
+		n := l.Network
+		if n == "" {
+			n = network
+		}
+		if err := validateListenAddr("listener "+l.Name, n, l.Addr, l.TLSCertFile, l.TLSKeyFile); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateListenAddr(what, network, addr, certFile, keyFile string) error {
+	if (certFile == "") != (keyFile == "") {
+		return fmt.Errorf("server config: %s: TLSCertFile and TLSKeyFile must be set together", what)
+	}
+	if strings.HasPrefix(network, "tcp") && addr != "" {
+		if _, _, err := net.SplitHostPort(addr); err != nil {
+			return fmt.Errorf("server config: %s: invalid addr %q: %w", what, addr, err)
+		}
+	}
+	return nil
+}
+
 func (s *Services) DB() *gorm.DB {
 	if s == nil {
 		return nil
 	}
-	return s.db
+	return s.db.Load()
 }
 
 func (s *Services) Redis() *redis.Client {
 	if s == nil {
 		return nil
 	}
-	return s.redis
+	return s.redis.Load()
 }
 
 func (s *Services) Logger() logger.Logger {
@@ -74,6 +245,26 @@ func (s *Services) Logger() logger.Logger {
 	return s.logger
 }
 
+// NamedLogger returns the logger installed for name by WithNamedLogger or
+// loaded from [loggers.<name>], or nil.
+func (s *Services) NamedLogger(name string) logger.Logger {
+	if s == nil {
+		return nil
+	}
+	return s.namedLoggers[name]
+}
+
+// registerLoggers registers the app logger and the named loggers for
+// logger.Named.
+func (s *Services) registerLoggers() {
+	if s.logger != nil {
+		logger.Register(logger.AppLoggerName, s.logger)
+	}
+	for name, l := range s.namedLoggers {
+		logger.Register(name, l)
+	}
+}
+
 func (s *Services) PanicLogger() *logger.PanicLogger {
 	if s == nil {
 		return nil
@@ -95,6 +286,51 @@ func (s *Services) ObservabilityMiddleware() Middleware {
 	return s.observabilityMiddleware
 }
 
+func (s *Services) HealthMonitor() *HealthMonitor {
+	if s == nil {
+		return nil
+	}
+	return s.healthMonitor
+}
+
+func (s *Services) Exports() *ExportManager {
+	if s == nil {
+		return nil
+	}
+	return s.exports
+}
+
+// WorkerPool returns the pool registered under name with WithWorkerPool,
+// or nil.
+func (s *Services) WorkerPool(name string) *workerpool.Pool {
+	if s == nil {
+		return nil
+	}
+	return s.workerPools[name]
+}
+
+func (s *Services) Profiler() *Profiler {
+	if s == nil {
+		return nil
+	}
+	return s.profiler
+}
+
+func (s *Services) RequestJournal() *RequestJournal {
+	if s == nil {
+		return nil
+	}
+	return s.journal
+}
+
+// Catalog returns the installed message catalog, or DefaultCatalog.
+func (s *Services) Catalog() *Catalog {
+	if s == nil || s.catalog == nil {
+		return DefaultCatalog()
+	}
+	return s.catalog
+}
+
 func (s *Services) registerCustom(key string, value any) {
 	if key == "" {
 		panic("golitekit: service key must not be empty")
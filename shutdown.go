@@ -0,0 +1,224 @@
+package golitekit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Shutdown phases, in the order they run.
+const (
+	PhaseStopAccepting = "stop_accepting"
+	PhaseDrainRequests = "drain_requests"
+	PhaseDrainJobs     = "drain_jobs"
+	PhaseClosePools    = "close_pools"
+	PhaseFlushLogs     = "flush_logs"
+)
+
+// ShutdownBudgets bounds each shutdown phase. A phase that runs out of budget
+// is abandoned (connections are force-closed, jobs cancelled) and reported,
+// so one slow component cannot starve the phases after it. Zero fields are
+// derived from ShutdownTimeout, see DefaultShutdownBudgets.
+type ShutdownBudgets struct {
+	StopAccepting time.Duration
+	DrainRequests time.Duration
+	DrainJobs     time.Duration
+	ClosePools    time.Duration
+	FlushLogs     time.Duration
+}
+
+// DefaultShutdownBudgets splits total so the phases together never take
+// longer: 5% stop accepting, 10% drain jobs, 5% close pools, 5% flush logs,
+// and what they leave, 75%, drains in-flight requests.
+func DefaultShutdownBudgets(total time.Duration) ShutdownBudgets {
+	b := ShutdownBudgets{
+		StopAccepting: total / 20,
+		DrainJobs:     total / 10,
+		ClosePools:    total / 20,
+		FlushLogs:     total / 20,
+	}
+	b.DrainRequests = total - b.StopAccepting - b.DrainJobs - b.ClosePools - b.FlushLogs
+	return b
+}
+
+func (b ShutdownBudgets) withDefaults(total time.Duration) ShutdownBudgets {
+	return b.or(DefaultShutdownBudgets(total))
+}
+
+// or fills the zero fields of b from d.
+func (b ShutdownBudgets) or(d ShutdownBudgets) ShutdownBudgets {
+	if b.StopAccepting <= 0 {
+		b.StopAccepting = d.StopAccepting
+	}
+	if b.DrainRequests <= 0 {
+		b.DrainRequests = d.DrainRequests
+	}
+	if b.DrainJobs <= 0 {
+		b.DrainJobs = d.DrainJobs
+	}
+	if b.ClosePools <= 0 {
+		b.ClosePools = d.ClosePools
+	}
+	if b.FlushLogs <= 0 {
+		b.FlushLogs = d.FlushLogs
+	}
+	return b
+}
+
+// PhaseReport is the outcome of one shutdown phase.
+type PhaseReport struct {
+	Phase    string
+	Budget   time.Duration
+	Elapsed  time.Duration
+	TimedOut bool
+	// Unfinished lists what was still running when the budget expired:
+	// in-flight requests, export job IDs or component names.
+	Unfinished []string
+	Err        error
+}
+
+// ShutdownReport is returned by phased shutdowns.
+type ShutdownReport struct {
+	Phases []PhaseReport
+}
+
+// Phase returns the report of the named phase, or nil when it did not run.
+func (r *ShutdownReport) Phase(name string) *PhaseReport {
+	for i := range r.Phases {
+		if r.Phases[i].Phase == name {
+			return &r.Phases[i]
+		}
+	}
+	return nil
+}
+
+// Err describes every phase that failed or ran out of budget, or returns nil
+// when the shutdown was clean.
+func (r *ShutdownReport) Err() error {
+	var errs []error
+	for _, p := range r.Phases {
+		if p.TimedOut {
+			msg := fmt.Sprintf("shutdown phase %s exceeded its %s budget", p.Phase, p.Budget)
+			if len(p.Unfinished) > 0 {
+				msg += "; unfinished: " + strings.Join(p.Unfinished, ", ")
+			}
+			errs = append(errs, errors.New(msg))
+		}
+		if p.Err != nil {
+			errs = append(errs, fmt.Errorf("shutdown phase %s: %w", p.Phase, p.Err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// shutdownStep is one component stopped during a phase.
+type shutdownStep struct {
+	name string
+	fn   func(ctx context.Context) error
+}
+
+// runShutdownPhase runs steps concurrently and waits at most budget for them.
+// Steps still running afterwards are reported as unfinished and abandoned.
+func runShutdownPhase(phase string, budget time.Duration, steps []shutdownStep) PhaseReport {
+	report := PhaseReport{Phase: phase, Budget: budget}
+	start := time.Now()
+	ctx, cancel := context.WithTimeout(context.Background(), budget)
+	defer cancel()
+
+	var (
+		mu       sync.Mutex
+		errs     []error
+		finished = make(map[string]bool, len(steps))
+		wg       sync.WaitGroup
+	)
+	for _, step := range steps {
+		wg.Add(1)
+		go func(step shutdownStep) {
+			defer wg.Done()
+			err := step.fn(ctx)
+			mu.Lock()
+			defer mu.Unlock()
+			if errors.Is(err, context.DeadlineExceeded) {
+				return // gave up on its budget: unfinished
+			}
+			finished[step.name] = true
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", step.name, err))
+			}
+		}(step)
+	}
+	allDone := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(allDone)
+	}()
+
+	select {
+	case <-allDone:
+	case <-ctx.Done():
+	}
+	report.Elapsed = time.Since(start)
+
+	mu.Lock()
+	defer mu.Unlock()
+	for _, step := range steps {
+		if !finished[step.name] {
+			report.TimedOut = true
+			report.Unfinished = append(report.Unfinished, step.name)
+		}
+	}
+	report.Err = errors.Join(errs...)
+	return report
+}
+
+// inflightRequests tracks the requests a Server is handling so a drain that
+// runs out of budget can say which ones it cut off.
+type inflightRequests struct {
+	mu   sync.Mutex
+	reqs map[*http.Request]time.Time
+}
+
+func (t *inflightRequests) wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.mu.Lock()
+		if t.reqs == nil {
+			t.reqs = make(map[*http.Request]time.Time)
+		}
+		t.reqs[r] = time.Now()
+		t.mu.Unlock()
+		defer func() {
+			t.mu.Lock()
+			delete(t.reqs, r)
+			t.mu.Unlock()
+		}()
+		next.ServeHTTP(w, r)
+	})
+}
+
+// list returns "METHOD /path (running 1.2s)" for each request, oldest first.
+func (t *inflightRequests) list() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	type entry struct {
+		desc  string
+		start time.Time
+	}
+	entries := make([]entry, 0, len(t.reqs))
+	for r, start := range t.reqs {
+		entries = append(entries, entry{
+			desc:  fmt.Sprintf("%s %s (running %s)", r.Method, r.URL.Path, time.Since(start).Round(time.Millisecond)),
+			start: start,
+		})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].start.Before(entries[j].start) })
+	out := make([]string, len(entries))
+	for i, e := range entries {
+		out[i] = e.desc
+	}
+	return out
+}
@@ -0,0 +1,145 @@
+package golitekit
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hansir-hsj/GoLiteKit/workerpool"
+)
+
+func startPhasedTestServer(t *testing.T, handler http.Handler) *Server {
+	t.Helper()
+	srv := NewServer(ServerConfig{Addr: "127.0.0.1:0", ShutdownTimeout: time.Second})
+	if err := srv.Start(handler); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	return srv
+}
+
+func TestServer_ShutdownInPhases_Clean(t *testing.T) {
+	srv := startPhasedTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	report := srv.ShutdownInPhases(ShutdownBudgets{})
+	if err := report.Err(); err != nil {
+		t.Fatalf("Err() = %v", err)
+	}
+	for _, name := range []string{PhaseStopAccepting, PhaseDrainRequests} {
+		p := report.Phase(name)
+		if p == nil {
+			t.Fatalf("phase %s missing", name)
+		}
+		if p.TimedOut {
+			t.Errorf("phase %s timed out", name)
+		}
+	}
+	if got := report.Phase(PhaseDrainRequests).Budget; got != 750*time.Millisecond {
+		t.Errorf("drain budget = %v, want 750ms (what the other phases leave)", got)
+	}
+	if err := srv.Start(http.NotFoundHandler()); err != nil {
+		t.Errorf("restart after phased shutdown: %v", err)
+	}
+	srv.ShutdownInPhases(ShutdownBudgets{})
+}
+
+func TestServer_ShutdownInPhases_HardKillsSlowRequests(t *testing.T) {
+	entered := make(chan struct{})
+	release := make(chan struct{})
+	defer close(release)
+	srv := startPhasedTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(entered)
+		<-release
+	}))
+
+	go func() {
+		resp, err := http.Get("http://" + srv.Addr() + "/slow")
+		if err == nil {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+	}()
+	<-entered
+
+	start := time.Now()
+	report := srv.ShutdownInPhases(ShutdownBudgets{DrainRequests: 50 * time.Millisecond})
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("shutdown took %v, budget was not enforced", elapsed)
+	}
+
+	drain := report.Phase(PhaseDrainRequests)
+	if drain == nil || !drain.TimedOut {
+		t.Fatalf("drain phase = %+v, want timed out", drain)
+	}
+	if len(drain.Unfinished) != 1 || !strings.HasPrefix(drain.Unfinished[0], "GET /slow") {
+		t.Errorf("Unfinished = %v, want the slow request", drain.Unfinished)
+	}
+	if err := report.Err(); err == nil || !strings.Contains(err.Error(), "drain_requests") {
+		t.Errorf("Err() = %v, want drain_requests report", err)
+	}
+	select {
+	case <-srv.Done():
+	case <-time.After(time.Second):
+		t.Fatal("server still serving after hard kill")
+	}
+}
+
+func TestApp_GracefulShutdown_SlowJobDoesNotStarveLaterPhases(t *testing.T) {
+	app, m := newTestExportApp(t, ExportOptions{})
+	job := m.Enqueue(ExportSpec{Name: "slow"}, func(ctx context.Context, job *ExportJob, w io.Writer) error {
+		<-ctx.Done()
+		time.Sleep(20 * time.Millisecond) // still winding down when the budget expires
+		return ctx.Err()
+	})
+	if err := app.Start(ServerConfig{Addr: "127.0.0.1:0"}); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+
+	report := app.GracefulShutdown(ShutdownBudgets{DrainJobs: 30 * time.Millisecond})
+	want := []string{PhaseStopAccepting, PhaseDrainRequests, PhaseDrainJobs, PhaseClosePools, PhaseFlushLogs}
+	if len(report.Phases) != len(want) {
+		t.Fatalf("phases = %+v, want %v", report.Phases, want)
+	}
+	for i, name := range want {
+		if report.Phases[i].Phase != name {
+			t.Errorf("phase[%d] = %s, want %s", i, report.Phases[i].Phase, name)
+		}
+	}
+
+	jobs := report.Phase(PhaseDrainJobs)
+	if !jobs.TimedOut || len(jobs.Unfinished) != 1 || jobs.Unfinished[0] != job.ID() {
+		t.Errorf("drain_jobs = %+v, want job %s unfinished", jobs, job.ID())
+	}
+	if p := report.Phase(PhaseFlushLogs); p.TimedOut || p.Err != nil {
+		t.Errorf("flush_logs = %+v, want clean", p)
+	}
+	if app.currentServer() != nil {
+		t.Error("server not cleared after GracefulShutdown")
+	}
+}
+
+func TestApp_GracefulShutdown_DrainsWorkerPools(t *testing.T) {
+	pool := workerpool.New(workerpool.Options{Workers: 1})
+	stuck := workerpool.New(workerpool.Options{Workers: 1})
+	app := NewApp(WithWorkerPool("fanout", pool), WithWorkerPool("stuck", stuck))
+	var done atomic.Bool
+	pool.Submit(context.Background(), func(context.Context) {
+		time.Sleep(10 * time.Millisecond)
+		done.Store(true)
+	})
+	release := make(chan struct{})
+	defer close(release)
+	stuck.Submit(context.Background(), func(context.Context) { <-release })
+
+	report := app.GracefulShutdown(ShutdownBudgets{DrainJobs: 100 * time.Millisecond})
+	if !done.Load() {
+		t.Error("queued task did not finish before shutdown moved on")
+	}
+	jobs := report.Phase(PhaseDrainJobs)
+	if !jobs.TimedOut || len(jobs.Unfinished) != 1 || jobs.Unfinished[0] != "workerpool.stuck" {
+		t.Errorf("drain_jobs = %+v, want workerpool.stuck unfinished", jobs)
+	}
+}
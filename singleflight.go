@@ -0,0 +1,153 @@
+package golitekit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/hansir-hsj/GoLiteKit/logger"
+)
+
+const DefaultSingleflightMaxBody = 1 << 20
+
+var errSingleflightPanic = errors.New("shared request panicked")
+
+// DefaultSingleflightVary are the request headers that make up the default
+// singleflight key next to the method and URL, so personalised or
+// negotiated responses are never shared between different requests.
+var DefaultSingleflightVary = []string{"Authorization", "Cookie", "Accept", "Accept-Encoding", "Accept-Language"}
+
+// SingleflightOptions configures SingleflightMiddleware.
+type SingleflightOptions struct {
+	// Key returns the key of requests that may share a response; "" runs
+	// the request on its own. Defaults to the method, the path, the sorted
+	// query and the Vary headers.
+	Key func(r *http.Request) string
+	// Vary are the headers of the default key, defaults to
+	// DefaultSingleflightVary.
+	Vary []string
+	// MaxBody is the largest response shared with waiters, defaults to
+	// DefaultSingleflightMaxBody; waiters of a larger one run the request
+	// themselves.
+	MaxBody int
+}
+
+type singleflightCall struct {
+	done   chan struct{}
+	status int
+	header http.Header
+	body   []byte
+	shared bool // false when the response could not be captured
+	err    error
+}
+
+// SingleflightMiddleware coalesces concurrent identical GET and HEAD
+// requests into one handler run and serves its response to every waiter,
+// protecting backends from cache stampedes. Waiters share the outcome of
+// the first request, including its error; they stop waiting when their own
+// context ends.
+func SingleflightMiddleware(opts ...SingleflightOptions) Middleware {
+	var opt SingleflightOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if opt.Vary == nil {
+		opt.Vary = DefaultSingleflightVary
+	}
+	if opt.MaxBody <= 0 {
+		opt.MaxBody = DefaultSingleflightMaxBody
+	}
+	if opt.Key == nil {
+		vary := opt.Vary
+		opt.Key = func(r *http.Request) string { return singleflightKey(r, vary) }
+	}
+
+	var mu sync.Mutex
+	calls := make(map[string]*singleflightCall)
+
+	return func(next Handler) Handler {
+		return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			if r.Method != http.MethodGet && r.Method != http.MethodHead {
+				return next(ctx, w, r)
+			}
+			key := opt.Key(r)
+			if key == "" {
+				return next(ctx, w, r)
+			}
+
+			mu.Lock()
+			if c, ok := calls[key]; ok {
+				mu.Unlock()
+				select {
+				case <-c.done:
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+				if !c.shared {
+					return next(ctx, w, r)
+				}
+				logger.AddInfo(ctx, "singleflight", "shared")
+				if c.err != nil {
+					return c.err
+				}
+				replayResponse(w, c.status, c.header, c.body)
+				return nil
+			}
+			c := &singleflightCall{done: make(chan struct{})}
+			calls[key] = c
+			mu.Unlock()
+
+			rec := &capturingWriter{ResponseWriter: w, status: http.StatusOK, limit: opt.MaxBody}
+			defer func() {
+				if v := recover(); v != nil {
+					c.err = ErrInternal("Internal Server Error", errSingleflightPanic)
+					c.shared = true
+					finishSingleflight(&mu, calls, key, c)
+					panic(v)
+				}
+			}()
+			c.err = next(ctx, rec, r)
+			if c.err == nil {
+				c.err = flushResponse(ctx, rec, r)
+			}
+			c.status, c.header, c.body = rec.status, rec.header, rec.body.Bytes()
+			if c.header == nil {
+				c.header = w.Header().Clone()
+			}
+			// Waiters of a response that was not captured run on their own.
+			c.shared = !rec.overflow && (rec.wroteHeader || c.err != nil)
+			finishSingleflight(&mu, calls, key, c)
+			return c.err
+		}
+	}
+}
+
+func finishSingleflight(mu *sync.Mutex, calls map[string]*singleflightCall, key string, c *singleflightCall) {
+	mu.Lock()
+	delete(calls, key)
+	mu.Unlock()
+	close(c.done)
+}
+
+// singleflightKey joins the method, path, sorted query and vary headers.
+func singleflightKey(r *http.Request, vary []string) string {
+	var b strings.Builder
+	b.WriteString(r.Method)
+	b.WriteByte(' ')
+	b.WriteString(r.URL.Path)
+	if r.URL.RawQuery != "" {
+		b.WriteByte('?')
+		b.WriteString(r.URL.Query().Encode())
+	}
+	for _, h := range vary {
+		for _, v := range r.Header.Values(h) {
+			b.WriteByte('\n')
+			b.WriteString(h)
+			b.WriteByte(':')
+			b.WriteString(v)
+		}
+	}
+	return b.String()
+}
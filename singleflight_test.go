@@ -0,0 +1,142 @@
+package golitekit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSingleflightMiddleware_CoalescesConcurrentGETs(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	h := SingleflightMiddleware()(func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		calls.Add(1)
+		<-release
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"hot":true}`))
+		return nil
+	})
+
+	var wg sync.WaitGroup
+	recs := make([]*httptest.ResponseRecorder, 4)
+	for i := range recs {
+		recs[i] = httptest.NewRecorder()
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// the query is normalised, so the order of parameters does not matter
+			target := "/items?b=2&a=1"
+			if i%2 == 1 {
+				target = "/items?a=1&b=2"
+			}
+			req := httptest.NewRequest(http.MethodGet, target, nil)
+			if err := h(req.Context(), recs[i], req); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	waitFor(t, func() bool { return calls.Load() == 1 })
+	// a request with another Authorization is not coalesced
+	other := httptest.NewRequest(http.MethodGet, "/items?a=1&b=2", nil)
+	other.Header.Set("Authorization", "Bearer x")
+	go func() { _ = h(other.Context(), httptest.NewRecorder(), other) }()
+	waitFor(t, func() bool { return calls.Load() == 2 })
+	// let the remaining goroutines join the first call
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	for i, rec := range recs {
+		if rec.Body.String() != `{"hot":true}` || rec.Header().Get("Content-Type") != "application/json" {
+			t.Errorf("response %d = %q %v", i, rec.Body.String(), rec.Header())
+		}
+	}
+
+	// once finished, the next request runs the handler again
+	req := httptest.NewRequest(http.MethodGet, "/items?a=1&b=2", nil)
+	_ = h(req.Context(), httptest.NewRecorder(), req)
+	if calls.Load() != 3 {
+		t.Fatalf("handler ran %d times, want 3", calls.Load())
+	}
+}
+
+func TestSingleflightMiddleware_LargeResponseNotShared(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	h := SingleflightMiddleware(SingleflightOptions{MaxBody: 4})(func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		if calls.Add(1) == 1 {
+			<-release
+		}
+		_, _ = w.Write([]byte(strings.Repeat("x", 8)))
+		return nil
+	})
+
+	done := make(chan struct{})
+	go func() {
+		req := httptest.NewRequest(http.MethodGet, "/big", nil)
+		_ = h(req.Context(), httptest.NewRecorder(), req)
+		close(done)
+	}()
+	waitFor(t, func() bool { return calls.Load() == 1 })
+	waiter := make(chan *httptest.ResponseRecorder)
+	go func() {
+		req := httptest.NewRequest(http.MethodGet, "/big", nil)
+		rec := httptest.NewRecorder()
+		_ = h(req.Context(), rec, req)
+		waiter <- rec
+	}()
+	close(release)
+	<-done
+	if rec := <-waiter; rec.Body.Len() != 8 {
+		t.Fatalf("waiter body = %q", rec.Body.String())
+	}
+}
+
+type hotItemsController struct {
+	BaseController
+	calls   *atomic.Int32
+	release chan struct{}
+}
+
+func (c *hotItemsController) Serve(ctx context.Context) error {
+	c.calls.Add(1)
+	<-c.release
+	return c.JSON(http.StatusOK, map[string]bool{"hot": true})
+}
+
+func TestSingleflightMiddleware_SharesControllerJSON(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	app := NewApp()
+	app.Use(SingleflightMiddleware())
+	app.GET("/items", &hotItemsController{calls: &calls, release: release})
+
+	var wg sync.WaitGroup
+	recs := make([]*httptest.ResponseRecorder, 3)
+	for i := range recs {
+		recs[i] = httptest.NewRecorder()
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			app.Handler().ServeHTTP(recs[i], httptest.NewRequest(http.MethodGet, "/items", nil))
+		}()
+	}
+	waitFor(t, func() bool { return calls.Load() == 1 })
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if calls.Load() != 1 {
+		t.Fatalf("controller ran %d times, want 1", calls.Load())
+	}
+	for i, rec := range recs {
+		if rec.Code != http.StatusOK || rec.Body.String() != `{"hot":true}` || rec.Header().Get("Content-Type") != "application/json" {
+			t.Errorf("response %d = %d %q %v", i, rec.Code, rec.Body.String(), rec.Header())
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package golitekit
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/acme"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// TLSHost is a certificate a server presents to clients asking for Host via
+// SNI, see ServerConfig.TLSHosts.
+type TLSHost struct {
+	// Host is a hostname such as "api.example.com" or a wildcard such as
+	// "*.example.com", which matches one label.
+	Host     string
+	CertFile string
+	KeyFile  string
+	// ACME obtains and renews the certificate of Host from an ACME CA, Let's
+	// Encrypt by default, instead of loading CertFile and KeyFile. It uses the
+	// TLS-ALPN-01 challenge, so the listener must be reachable on port 443,
+	// and needs ServerConfig.ACMECacheDir.
+	ACME bool
+}
+
+// acmeConfig is the account of the ACME hosts, see ServerConfig.
+type acmeConfig struct {
+	cacheDir     string
+	email        string
+	directoryURL string
+}
+
+// sniCertificates selects the certificate of a handshake by its server name.
+type sniCertificates struct {
+	certs     map[string]*tls.Certificate // by lower-case host or "*.domain"
+	acmeHosts map[string]bool
+	manager   *autocert.Manager
+}
+
+func newSNICertificates(hosts []TLSHost, ac acmeConfig) (*sniCertificates, error) {
+	s := &sniCertificates{
+		certs:     make(map[string]*tls.Certificate),
+		acmeHosts: make(map[string]bool),
+	}
+	for _, h := range hosts {
+		name := strings.ToLower(h.Host)
+		if h.ACME {
+			s.acmeHosts[name] = true
+			continue
+		}
+		cert, err := tls.LoadX509KeyPair(h.CertFile, h.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("load tls cert of %s error: %w", h.Host, err)
+		}
+		s.certs[name] = &cert
+	}
+	if len(s.acmeHosts) > 0 {
+		s.manager = &autocert.Manager{
+			Prompt: autocert.AcceptTOS,
+			Cache:  autocert.DirCache(ac.cacheDir),
+			Email:  ac.email,
+			HostPolicy: func(_ context.Context, host string) error {
+				if !s.acmeHosts[strings.ToLower(host)] {
+					return fmt.Errorf("acme: host %q not configured", host)
+				}
+				return nil
+			},
+		}
+		if ac.directoryURL != "" {
+			s.manager.Client = &acme.Client{DirectoryURL: ac.directoryURL}
+		}
+	}
+	return s, nil
+}
+
+// apply makes config select certificates by SNI, falling back to its
+// Certificates for other names.
+func (s *sniCertificates) apply(config *tls.Config) {
+	config.GetCertificate = s.getCertificate
+	if s.manager != nil {
+		config.NextProtos = []string{"http/1.1", acme.ALPNProto}
+	}
+}
+
+func (s *sniCertificates) getCertificate(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	name := strings.ToLower(strings.TrimSuffix(hello.ServerName, "."))
+	if s.acmeHosts[name] {
+		return s.manager.GetCertificate(hello)
+	}
+	if cert, ok := s.certs[name]; ok {
+		return cert, nil
+	}
+	if i := strings.IndexByte(name, '.'); i > 0 {
+		if cert, ok := s.certs["*"+name[i:]]; ok {
+			return cert, nil
+		}
+	}
+	// nil selects tls.Config.Certificates, the TLSCertFile of the server.
+	return nil, nil
+}
+
+// validateTLSHosts rejects TLSHosts that cannot be served.
+func validateTLSHosts(hosts []TLSHost, acmeCacheDir string) error {
+	seen := make(map[string]bool, len(hosts))
+	for _, h := range hosts {
+		name := strings.ToLower(h.Host)
+		switch {
+		case name == "":
+			return fmt.Errorf("server config: TLSHosts: empty Host")
+		case seen[name]:
+			return fmt.Errorf("server config: TLSHosts: duplicate host %q", h.Host)
+		case h.ACME && (h.CertFile != "" || h.KeyFile != ""):
+			return fmt.Errorf("server config: TLSHosts: %s: ACME and certificate files are exclusive", h.Host)
+		case h.ACME && strings.HasPrefix(name, "*"):
+			return fmt.Errorf("server config: TLSHosts: %s: ACME does not issue wildcard certificates", h.Host)
+		case h.ACME && acmeCacheDir == "":
+			return fmt.Errorf("server config: TLSHosts: %s: ACME requires ACMECacheDir", h.Host)
+		case !h.ACME && (h.CertFile == "" || h.KeyFile == ""):
+			return fmt.Errorf("server config: TLSHosts: %s: CertFile and KeyFile must be set", h.Host)
+		}
+		seen[name] = true
+	}
+	return nil
+}
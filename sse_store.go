@@ -0,0 +1,223 @@
+package golitekit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// EventStore keeps the recent events of named SSE streams so clients that
+// reconnect with Last-Event-ID receive what they missed, whichever replica
+// served them before.
+type EventStore interface {
+	// Append stores event and returns the ID the store assigned to it.
+	Append(ctx context.Context, stream string, event SSEvent) (string, error)
+	// Since returns the events after lastID ("" for the oldest kept), oldest
+	// first. With block > 0 it waits up to block for new events when there
+	// are none and then returns an empty result.
+	Since(ctx context.Context, stream, lastID string, block time.Duration) ([]SSEvent, error)
+	// Last returns the ID of the newest event, or "0-0" for an empty stream.
+	Last(ctx context.Context, stream string) (string, error)
+}
+
+// RedisEventStoreOptions configures NewRedisEventStore.
+type RedisEventStoreOptions struct {
+	// Prefix is prepended to stream names to form Redis keys, defaults to
+	// "glk:sse:".
+	Prefix string
+	// MaxLen keeps about this many events per stream; 0 means no limit.
+	MaxLen int64
+	// MaxAge drops events older than this, and whole streams idle for as
+	// long; 0 means no limit.
+	MaxAge time.Duration
+	// ReadLimit caps the events returned by one Since call, defaults to 1000.
+	ReadLimit int64
+}
+
+// RedisEventStore is an EventStore on Redis Streams. Event IDs are the
+// stream entry IDs, so they are ordered across all replicas appending to a
+// stream.
+type RedisEventStore struct {
+	client redis.UniversalClient
+	opts   RedisEventStoreOptions
+}
+
+// NewRedisEventStore returns an EventStore keeping events in client.
+func NewRedisEventStore(client redis.UniversalClient, opts ...RedisEventStoreOptions) *RedisEventStore {
+	var opt RedisEventStoreOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if opt.Prefix == "" {
+		opt.Prefix = "glk:sse:"
+	}
+	if opt.ReadLimit <= 0 {
+		opt.ReadLimit = 1000
+	}
+	return &RedisEventStore{client: client, opts: opt}
+}
+
+func (s *RedisEventStore) key(stream string) string {
+	return s.opts.Prefix + stream
+}
+
+// Append adds event to the stream and trims it by MaxLen and MaxAge. The
+// event's own ID is replaced by the stream entry ID.
+func (s *RedisEventStore) Append(ctx context.Context, stream string, event SSEvent) (string, error) {
+	data, err := serializeSSEData(event.Data)
+	if err != nil {
+		return "", err
+	}
+	values := []any{"data", data}
+	if event.Event != "" {
+		values = append(values, "event", event.Event)
+	}
+	if event.Retry > 0 {
+		values = append(values, "retry", event.Retry)
+	}
+
+	key := s.key(stream)
+	pipe := s.client.TxPipeline()
+	add := pipe.XAdd(ctx, &redis.XAddArgs{Stream: key, MaxLen: s.opts.MaxLen, Approx: s.opts.MaxLen > 0, Values: values})
+	if s.opts.MaxAge > 0 {
+		minID := strconv.FormatInt(time.Now().Add(-s.opts.MaxAge).UnixMilli(), 10)
+		pipe.XTrimMinIDApprox(ctx, key, minID, 0)
+		pipe.PExpire(ctx, key, s.opts.MaxAge)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return "", err
+	}
+	return add.Val(), nil
+}
+
+// Since reads the events after lastID, blocking up to block for new ones.
+func (s *RedisEventStore) Since(ctx context.Context, stream, lastID string, block time.Duration) ([]SSEvent, error) {
+	if lastID == "" {
+		lastID = "0-0"
+	}
+	key := s.key(stream)
+	var msgs []redis.XMessage
+	if block > 0 {
+		res, err := s.client.XRead(ctx, &redis.XReadArgs{Streams: []string{key, lastID}, Count: s.opts.ReadLimit, Block: block}).Result()
+		if errors.Is(err, redis.Nil) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(res) > 0 {
+			msgs = res[0].Messages
+		}
+	} else {
+		var err error
+		msgs, err = s.client.XRangeN(ctx, key, "("+lastID, "+", s.opts.ReadLimit).Result()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	events := make([]SSEvent, 0, len(msgs))
+	for _, m := range msgs {
+		e := SSEvent{ID: m.ID}
+		e.Data, _ = m.Values["data"].(string)
+		e.Event, _ = m.Values["event"].(string)
+		if retry, ok := m.Values["retry"].(string); ok {
+			e.Retry, _ = strconv.Atoi(retry)
+		}
+		events = append(events, e)
+	}
+	return events, nil
+}
+
+// Last returns the ID of the newest event in the stream.
+func (s *RedisEventStore) Last(ctx context.Context, stream string) (string, error) {
+	msgs, err := s.client.XRevRangeN(ctx, s.key(stream), "+", "-", 1).Result()
+	if err != nil {
+		return "", err
+	}
+	if len(msgs) == 0 {
+		return "0-0", nil
+	}
+	return msgs[0].ID, nil
+}
+
+var streamIDPattern = regexp.MustCompile(`^[0-9]+(-[0-9]+)?$`)
+
+// LastEventID returns the ID a reconnecting SSE client resumes from: the
+// Last-Event-ID header, or the "lastEventId" query parameter for clients that
+// cannot set headers.
+func LastEventID(r *http.Request) string {
+	if id := r.Header.Get("Last-Event-ID"); id != "" {
+		return id
+	}
+	return r.URL.Query().Get("lastEventId")
+}
+
+// EventStreamOptions configures ServeEventStream.
+type EventStreamOptions struct {
+	// Heartbeat is how long to wait for new events before sending a comment
+	// that keeps proxies from closing the idle connection, defaults to 15s.
+	Heartbeat time.Duration
+}
+
+// ServeEventStream serves stream from store as SSE: events after the
+// client's Last-Event-ID are replayed first (new clients start at the newest
+// event), then events appended by any replica are sent as they arrive until
+// the client disconnects. Events older than the store's retention are not
+// replayed.
+func (ctx *Context) ServeEventStream(store EventStore, stream string, opts ...EventStreamOptions) error {
+	var opt EventStreamOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if opt.Heartbeat <= 0 {
+		opt.Heartbeat = 15 * time.Second
+	}
+	rctx := ctx.Context()
+	sse := ctx.SSEWriter()
+
+	last := LastEventID(ctx.request)
+	if !streamIDPattern.MatchString(last) {
+		var err error
+		if last, err = store.Last(rctx, stream); err != nil {
+			return ErrInternal("failed to read event stream", err)
+		}
+	}
+	if err := sse.Comment("connected"); err != nil {
+		return nil
+	}
+
+	for {
+		events, err := store.Since(rctx, stream, last, opt.Heartbeat)
+		if rctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			return ErrInternal(fmt.Sprintf("failed to read event stream %q", stream), err)
+		}
+		if len(events) == 0 {
+			if err := sse.Comment("ping"); err != nil {
+				return nil
+			}
+			continue
+		}
+		for _, e := range events {
+			if err := sse.Send(e); err != nil {
+				return nil
+			}
+			last = e.ID
+		}
+	}
+}
+
+// ServeEventStream serves stream from store as SSE; see
+// Context.ServeEventStream.
+func (c *BaseControllerOf[T]) ServeEventStream(store EventStore, stream string, opts ...EventStreamOptions) error {
+	return c.gcx.ServeEventStream(store, stream, opts...)
+}
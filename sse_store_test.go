@@ -0,0 +1,139 @@
+package golitekit
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func newTestEventStore(t *testing.T, opts ...RedisEventStoreOptions) *RedisEventStore {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return NewRedisEventStore(client, opts...)
+}
+
+func TestRedisEventStore_AppendAndSince(t *testing.T) {
+	ctx := context.Background()
+	store := newTestEventStore(t)
+
+	if last, err := store.Last(ctx, "orders"); err != nil || last != "0-0" {
+		t.Fatalf("Last on empty stream = %q, %v", last, err)
+	}
+	first, err := store.Append(ctx, "orders", SSEvent{Event: "created", Data: map[string]int{"id": 1}, Retry: 3000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, _ := store.Append(ctx, "orders", SSEvent{Data: "plain"})
+	if last, _ := store.Last(ctx, "orders"); last != second {
+		t.Errorf("Last = %q, want %q", last, second)
+	}
+
+	all, err := store.Since(ctx, "orders", "", 0)
+	if err != nil || len(all) != 2 {
+		t.Fatalf("Since(\"\") = %v, %v", all, err)
+	}
+	if all[0] != (SSEvent{ID: first, Event: "created", Data: `{"id":1}`, Retry: 3000}) {
+		t.Errorf("first event = %+v", all[0])
+	}
+	missed, _ := store.Since(ctx, "orders", first, 0)
+	if len(missed) != 1 || missed[0].ID != second || missed[0].Data != "plain" {
+		t.Errorf("Since(first) = %+v", missed)
+	}
+	if other, _ := store.Since(ctx, "payments", "", 0); len(other) != 0 {
+		t.Errorf("streams are not isolated: %+v", other)
+	}
+}
+
+func TestRedisEventStore_SinceBlocksForNewEvents(t *testing.T) {
+	ctx := context.Background()
+	store := newTestEventStore(t)
+	last, _ := store.Last(ctx, "orders")
+
+	if events, err := store.Since(ctx, "orders", last, 20*time.Millisecond); err != nil || len(events) != 0 {
+		t.Fatalf("idle Since = %v, %v", events, err)
+	}
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		store.Append(ctx, "orders", SSEvent{Data: "late"})
+	}()
+	events, err := store.Since(ctx, "orders", last, 2*time.Second)
+	if err != nil || len(events) != 1 || events[0].Data != "late" {
+		t.Fatalf("blocking Since = %v, %v", events, err)
+	}
+}
+
+func TestRedisEventStore_Retention(t *testing.T) {
+	ctx := context.Background()
+	store := newTestEventStore(t, RedisEventStoreOptions{MaxAge: time.Hour, Prefix: "app:"})
+	store.Append(ctx, "orders", SSEvent{Data: "x"})
+
+	ttl, err := store.client.PTTL(ctx, "app:orders").Result()
+	if err != nil || ttl <= 0 || ttl > time.Hour {
+		t.Errorf("stream TTL = %v, %v", ttl, err)
+	}
+}
+
+func TestContext_ServeEventStreamReplaysAndFollows(t *testing.T) {
+	ctx := context.Background()
+	store := newTestEventStore(t)
+	first, _ := store.Append(ctx, "orders", SSEvent{Data: "one"})
+	store.Append(ctx, "orders", SSEvent{Event: "created", Data: "two"})
+
+	r := newTestRouter()
+	r.GET("/events", func(c *Context) error {
+		return c.ServeEventStream(store, "orders", EventStreamOptions{Heartbeat: 50 * time.Millisecond})
+	})
+	srv := httptest.NewServer(r.Handler())
+	defer srv.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, srv.URL+"/events", nil)
+	req.Header.Set("Last-Event-ID", first)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("Content-Type = %q", resp.Header.Get("Content-Type"))
+	}
+
+	body := bufio.NewReader(resp.Body)
+	readEvent := func() string {
+		t.Helper()
+		var lines []string
+		for {
+			line, err := body.ReadString('\n')
+			if err != nil {
+				t.Fatalf("read: %v (got %q)", err, lines)
+			}
+			line = strings.TrimSuffix(line, "\n")
+			switch {
+			case line == "" && len(lines) > 0:
+				return strings.Join(lines, "|")
+			case line == "", strings.HasPrefix(line, ":"):
+			case strings.HasPrefix(line, "id: "):
+				// IDs are generated by Redis; only their presence matters.
+				lines = append(lines, "id")
+			default:
+				lines = append(lines, line)
+			}
+		}
+	}
+
+	if got := readEvent(); got != "id|event: created|data: two" {
+		t.Errorf("replayed event = %q", got)
+	}
+	store.Append(ctx, "orders", SSEvent{Data: "three"})
+	if got := readEvent(); got != "id|data: three" {
+		t.Errorf("live event = %q", got)
+	}
+}
@@ -0,0 +1,157 @@
+package golitekit
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	glkdb "github.com/hansir-hsj/GoLiteKit/db"
+	"github.com/hansir-hsj/GoLiteKit/env"
+	"github.com/hansir-hsj/GoLiteKit/logger"
+	glkredis "github.com/hansir-hsj/GoLiteKit/redis"
+)
+
+// startupCheckTimeout bounds each connectivity check of the startup record.
+const startupCheckTimeout = 2 * time.Second
+
+// startupInfo is the summary the app logs once its server listens, so a
+// misconfiguration shows in the first lines of the log.
+type startupInfo struct {
+	App         string
+	RunMode     string
+	RootDir     string
+	Addr        string
+	TLS         string
+	Listeners   []string
+	Routes      int
+	Middlewares []string
+	Loggers     []string
+	DB          string
+	Redis       string
+}
+
+// logStartup writes the startup record to the app logger and, in the
+// development run modes, a banner to stdout.
+func (a *App) logStartup(srv *Server) {
+	if a.services.startupLogOff {
+		return
+	}
+	info := a.startupInfo(srv)
+	if l := a.services.Logger(); l != nil {
+		l.Info(context.Background(), "startup", info.args()...)
+	}
+	if devRunMode(env.RunMode()) {
+		info.printBanner(os.Stdout)
+	}
+}
+
+func (a *App) startupInfo(srv *Server) startupInfo {
+	info := startupInfo{
+		App:     env.AppName(),
+		RunMode: env.RunMode(),
+		RootDir: env.RootDir(),
+		Addr:    srv.Addr(),
+		TLS:     tlsState(srv.config.TLSCertFile, srv.config.TLSClientCAFile, len(srv.config.TLSHosts)),
+		Routes:  len(a.router.Routes()),
+	}
+	for _, lc := range srv.config.Listeners {
+		addr := srv.ListenerAddr(lc.Name)
+		if addr == "" {
+			addr = lc.Addr
+		}
+		info.Listeners = append(info.Listeners, fmt.Sprintf("%s=%s (%s)", lc.Name, addr, tlsState(lc.TLSCertFile, lc.TLSClientCAFile, 0)))
+	}
+	for _, m := range a.router.Middlewares() {
+		name := m.Name
+		if name == "" {
+			name = m.Func
+		}
+		info.Middlewares = append(info.Middlewares, name)
+	}
+
+	info.Loggers = append(info.Loggers, logger.AppLoggerName+"="+logger.Destination(a.services.Logger()))
+	names := make([]string, 0, len(a.services.namedLoggers))
+	for name := range a.services.namedLoggers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		info.Loggers = append(info.Loggers, name+"="+logger.Destination(a.services.namedLoggers[name]))
+	}
+	if pl := a.services.PanicLogger(); pl != nil {
+		info.Loggers = append(info.Loggers, "panic="+pl.FilePath())
+	}
+
+	info.DB = "disabled"
+	if db := a.services.DB(); db != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), startupCheckTimeout)
+		info.DB = checkResult(glkdb.Ping(ctx, db))
+		cancel()
+	}
+	info.Redis = "disabled"
+	if client := a.services.Redis(); client != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), startupCheckTimeout)
+		info.Redis = checkResult(glkredis.Ping(ctx, client))
+		cancel()
+	}
+	return info
+}
+
+func tlsState(certFile, clientCAFile string, sniHosts int) string {
+	state := "on"
+	switch {
+	case certFile == "" && sniHosts == 0:
+		return "off"
+	case clientCAFile != "":
+		state = "mtls"
+	}
+	if sniHosts > 0 {
+		state += fmt.Sprintf(", %d sni hosts", sniHosts)
+	}
+	return state
+}
+
+func checkResult(err error) string {
+	if err != nil {
+		return "error: " + err.Error()
+	}
+	return "ok"
+}
+
+func (info startupInfo) args() []any {
+	return []any{
+		"app", info.App,
+		"glk_version", Version,
+		"run_mode", info.RunMode,
+		"root_dir", info.RootDir,
+		"addr", info.Addr,
+		"tls", info.TLS,
+		"listeners", info.Listeners,
+		"routes", info.Routes,
+		"middlewares", info.Middlewares,
+		"loggers", info.Loggers,
+		"db", info.DB,
+		"redis", info.Redis,
+	}
+}
+
+func (info startupInfo) printBanner(w io.Writer) {
+	app := info.App
+	if app == "" {
+		app = "app"
+	}
+	fmt.Fprintf(w, "GoLiteKit %s  %s (%s)\n", Version, app, info.RunMode)
+	fmt.Fprintf(w, "  listening   %s (tls %s)\n", info.Addr, info.TLS)
+	for _, l := range info.Listeners {
+		fmt.Fprintf(w, "              %s\n", l)
+	}
+	fmt.Fprintf(w, "  routes      %d\n", info.Routes)
+	fmt.Fprintf(w, "  middleware  %s\n", strings.Join(info.Middlewares, " > "))
+	fmt.Fprintf(w, "  loggers     %s\n", strings.Join(info.Loggers, ", "))
+	fmt.Fprintf(w, "  db          %s\n", info.DB)
+	fmt.Fprintf(w, "  redis       %s\n", info.Redis)
+}
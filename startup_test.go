@@ -0,0 +1,84 @@
+package golitekit
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+type startupRecorder struct {
+	captureLogger
+	msg  string
+	args map[string]any
+}
+
+func (l *startupRecorder) Info(ctx context.Context, msg string, args ...any) {
+	l.msg = msg
+	l.args = make(map[string]any)
+	for i := 0; i+1 < len(args); i += 2 {
+		l.args[args[i].(string)] = args[i+1]
+	}
+}
+
+func TestApp_StartLogsStartupRecord(t *testing.T) {
+	rec := &startupRecorder{}
+	app := NewApp(WithLogger(rec), WithAddr("127.0.0.1:0"))
+	app.GET("/a", func(*Context) error { return nil })
+	app.GET("/b", func(*Context) error { return nil })
+	if err := app.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer app.Shutdown(context.Background())
+
+	if rec.msg != "startup" {
+		t.Fatalf("logged %q, want the startup record", rec.msg)
+	}
+	if addr := rec.args["addr"].(string); !strings.HasPrefix(addr, "127.0.0.1:") || strings.HasSuffix(addr, ":0") {
+		t.Errorf("addr = %q, want the bound address", addr)
+	}
+	if rec.args["tls"] != "off" || rec.args["routes"] != 2 || rec.args["db"] != "disabled" || rec.args["redis"] != "disabled" {
+		t.Errorf("args = %v", rec.args)
+	}
+	middlewares := rec.args["middlewares"].([]string)
+	if len(middlewares) == 0 || middlewares[0] != MiddlewareErrorHandler {
+		t.Errorf("middlewares = %v, want the chain starting with %s", middlewares, MiddlewareErrorHandler)
+	}
+	loggers := rec.args["loggers"].([]string)
+	if len(loggers) == 0 || !strings.HasPrefix(loggers[0], "app=") {
+		t.Errorf("loggers = %v, want the app logger first", loggers)
+	}
+}
+
+func TestApp_WithStartupLogDisabled(t *testing.T) {
+	rec := &startupRecorder{}
+	app := NewApp(WithLogger(rec), WithAddr("127.0.0.1:0"), WithStartupLog(false))
+	if err := app.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer app.Shutdown(context.Background())
+	if rec.msg != "" {
+		t.Errorf("logged %q with the startup log off", rec.msg)
+	}
+}
+
+func TestStartupInfo_PrintBanner(t *testing.T) {
+	var buf bytes.Buffer
+	startupInfo{
+		App:         "orders",
+		RunMode:     "debug",
+		Addr:        "127.0.0.1:8080",
+		TLS:         "on",
+		Listeners:   []string{"pprof=127.0.0.1:6060 (off)"},
+		Routes:      3,
+		Middlewares: []string{"error_handler", "logger"},
+		DB:          "ok",
+		Redis:       "error: dial tcp: connection refused",
+	}.printBanner(&buf)
+	out := buf.String()
+	for _, want := range []string{"orders (debug)", "127.0.0.1:8080 (tls on)", "pprof=127.0.0.1:6060", "error_handler > logger", "redis       error: dial tcp"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("banner lacks %q:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package golitekit
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// NDJSONContentType is the media type of newline-delimited JSON.
+const NDJSONContentType = "application/x-ndjson"
+
+// StreamWriter writes a chunked response whose parts reach the client as they
+// are produced. The first Flush commits the status and headers, bypassing the
+// buffer ErrorHandlerMiddleware keeps for ordinary responses; an error
+// returned after that can only be logged, not sent.
+type StreamWriter struct {
+	w       http.ResponseWriter
+	rc      *http.ResponseController
+	started bool
+}
+
+// NewStreamWriter returns a StreamWriter for w with the given Content-Type.
+func NewStreamWriter(w http.ResponseWriter, contentType string) *StreamWriter {
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	return &StreamWriter{w: w, rc: http.NewResponseController(w)}
+}
+
+// Write writes p without flushing; call Flush when a batch is complete.
+func (s *StreamWriter) Write(p []byte) (int, error) {
+	if !s.started {
+		s.started = true
+		s.w.WriteHeader(http.StatusOK)
+	}
+	return s.w.Write(p)
+}
+
+// WriteJSON writes v as one NDJSON line.
+func (s *StreamWriter) WriteJSON(v any) error {
+	line, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = s.Write(append(line, '\n'))
+	return err
+}
+
+// Flush sends the data written so far to the client.
+func (s *StreamWriter) Flush() {
+	if !s.started {
+		s.started = true
+		s.w.WriteHeader(http.StatusOK)
+	}
+	_ = s.rc.Flush()
+}
+
+// Started reports whether the response was committed.
+func (s *StreamWriter) Started() bool {
+	return s.started
+}
+
+// StreamWriter returns a StreamWriter for the response with the given
+// Content-Type.
+func (ctx *Context) StreamWriter(contentType string) *StreamWriter {
+	return NewStreamWriter(ctx.responseWriter, contentType)
+}
+
+// ServeNDJSON streams the values received from ch as newline-delimited JSON
+// until ch is closed, flushing whenever no further row is ready, so large
+// result sets are never buffered in memory. A value that is an error ends the
+// stream and is returned; so is the request context's error when the client
+// goes away or the request times out. The producer should stop sending once
+// the request context is done.
+//
+//	rows := make(chan any)
+//	go func() {
+//		defer close(rows)
+//		for cursor.Next() {
+//			select {
+//			case rows <- cursor.Row():
+//			case <-ctx.Context().Done():
+//				return
+//			}
+//		}
+//	}()
+//	return ctx.ServeNDJSON(rows)
+func (ctx *Context) ServeNDJSON(ch <-chan any) error {
+	sw := ctx.StreamWriter(NDJSONContentType)
+	done := ctx.Context().Done()
+	for {
+		select {
+		case row, ok := <-ch:
+			if !ok {
+				sw.Flush()
+				return nil
+			}
+			if err, isErr := row.(error); isErr {
+				return err
+			}
+			if err := sw.WriteJSON(row); err != nil {
+				return ErrInternal("failed to write NDJSON row", err)
+			}
+			if len(ch) == 0 {
+				sw.Flush()
+			}
+		case <-done:
+			return ctx.Context().Err()
+		}
+	}
+}
+
+// ServeNDJSON streams the values received from ch as newline-delimited JSON;
+// see Context.ServeNDJSON.
+func (c *BaseControllerOf[T]) ServeNDJSON(ch <-chan any) error {
+	return c.gcx.ServeNDJSON(ch)
+}
+
+// Stream returns a StreamWriter for the response with the given
+// Content-Type.
+func (c *BaseControllerOf[T]) Stream(contentType string) *StreamWriter {
+	return c.gcx.StreamWriter(contentType)
+}
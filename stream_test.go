@@ -0,0 +1,90 @@
+package golitekit
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestContext_ServeNDJSON(t *testing.T) {
+	r := newTestRouter()
+	r.GET("/rows", func(ctx *Context) error {
+		rows := make(chan any, 3)
+		rows <- map[string]int{"id": 1}
+		rows <- map[string]int{"id": 2}
+		rows <- "three"
+		close(rows)
+		return ctx.ServeNDJSON(rows)
+	})
+
+	rec := httptest.NewRecorder()
+	r.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/rows", nil))
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != NDJSONContentType {
+		t.Fatalf("response = %d %v", rec.Code, rec.Header())
+	}
+	if want := "{\"id\":1}\n{\"id\":2}\n\"three\"\n"; rec.Body.String() != want {
+		t.Errorf("body = %q, want %q", rec.Body, want)
+	}
+}
+
+func TestContext_ServeNDJSONFlushesRowsAsProduced(t *testing.T) {
+	next := make(chan struct{})
+	r := newTestRouter()
+	r.GET("/rows", func(ctx *Context) error {
+		rows := make(chan any)
+		go func() {
+			defer close(rows)
+			rows <- 1
+			<-next // the client must see row 1 before row 2 is produced
+			rows <- 2
+		}()
+		return ctx.ServeNDJSON(rows)
+	})
+	srv := httptest.NewServer(r.Handler())
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/rows")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	lines := bufio.NewReader(resp.Body)
+	if line, err := lines.ReadString('\n'); err != nil || line != "1\n" {
+		t.Fatalf("first line = %q, %v", line, err)
+	}
+	close(next)
+	if line, err := lines.ReadString('\n'); err != nil || line != "2\n" {
+		t.Fatalf("second line = %q, %v", line, err)
+	}
+}
+
+func TestContext_ServeNDJSONErrors(t *testing.T) {
+	r := newTestRouter()
+	r.GET("/before", func(ctx *Context) error {
+		rows := make(chan any, 1)
+		rows <- ErrConflict("export already running", nil)
+		return ctx.ServeNDJSON(rows)
+	})
+	r.GET("/during", func(ctx *Context) error {
+		rows := make(chan any)
+		go func() {
+			rows <- 1
+			rows <- ErrInternal("cursor failed", nil)
+		}()
+		return ctx.ServeNDJSON(rows)
+	})
+
+	rec := httptest.NewRecorder()
+	r.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/before", nil))
+	if rec.Code != http.StatusConflict || strings.Contains(rec.Header().Get("Content-Type"), "ndjson") {
+		t.Errorf("error before the first row = %d %v", rec.Code, rec.Header())
+	}
+
+	rec = httptest.NewRecorder()
+	r.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/during", nil))
+	if rec.Code != http.StatusOK || rec.Body.String() != "1\n" {
+		t.Errorf("error after a row = %d %q, want the streamed rows only", rec.Code, rec.Body)
+	}
+}
@@ -0,0 +1,139 @@
+package golitekit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+// Built-in subsystem names accepted by Services.Restart.
+const (
+	SubsystemDB        = "db"
+	SubsystemRedis     = "redis"
+	SubsystemRateLimit = "ratelimit"
+)
+
+// SubsystemDrainDelay is how long a replaced DB pool or Redis client stays
+// open after a restart so requests that already hold it can finish.
+const SubsystemDrainDelay = 30 * time.Second
+
+// ErrUnknownSubsystem is returned by Restart for names that are not registered.
+var ErrUnknownSubsystem = errors.New("golitekit: unknown subsystem")
+
+// RestartFunc restarts a subsystem in place. It must keep the old state
+// serving until the replacement is ready, so traffic is not dropped.
+type RestartFunc func(ctx context.Context) error
+
+// DBOpener opens a new DB pool; it is called by the "db" subsystem restart.
+type DBOpener func(ctx context.Context) (*gorm.DB, error)
+
+// WithRestartable registers a named subsystem restart, e.g. reloading
+// templates or a cache. It overrides a built-in of the same name.
+func WithRestartable(name string, fn RestartFunc) ServiceOption {
+	return func(s *Services) {
+		if name == "" || fn == nil {
+			panic("golitekit: WithRestartable requires a name and a function")
+		}
+		if s.restarters == nil {
+			s.restarters = make(map[string]RestartFunc)
+		}
+		s.restarters[name] = fn
+	}
+}
+
+// WithDBOpener enables the "db" subsystem restart: open builds a new pool,
+// which replaces the current one once it answers a ping. When no DB was
+// installed with WithDB, open is not called until the first restart.
+func WithDBOpener(open DBOpener) ServiceOption {
+	return func(s *Services) { s.dbOpener = open }
+}
+
+// Subsystems returns the names Restart accepts: registered restarts, "db"
+// when a DBOpener is installed and "redis" when a Redis client is installed.
+func (s *Services) Subsystems() []string {
+	if s == nil {
+		return nil
+	}
+	seen := make(map[string]bool)
+	for name := range s.restarters {
+		seen[name] = true
+	}
+	if s.dbOpener != nil {
+		seen[SubsystemDB] = true
+	}
+	if s.Redis() != nil {
+		seen[SubsystemRedis] = true
+	}
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Restart restarts the named subsystem without stopping the server. The
+// built-in "db" and "redis" restarts build a new pool or client, swap it in
+// once it answers a ping, and close the old one after SubsystemDrainDelay; on
+// failure the old one keeps serving.
+func (s *Services) Restart(ctx context.Context, name string) error {
+	if fn, ok := s.restarters[name]; ok {
+		return fn(ctx)
+	}
+	switch {
+	case name == SubsystemDB && s.dbOpener != nil:
+		return s.restartDB(ctx)
+	case name == SubsystemRedis && s.Redis() != nil:
+		return s.restartRedis(ctx)
+	}
+	return fmt.Errorf("%w: %s", ErrUnknownSubsystem, name)
+}
+
+func (s *Services) restartDB(ctx context.Context) error {
+	next, err := s.dbOpener(ctx)
+	if err != nil {
+		return fmt.Errorf("reopen db: %w", err)
+	}
+	sqlDB, err := next.DB()
+	if err == nil {
+		err = sqlDB.PingContext(ctx)
+	}
+	if err != nil {
+		if sqlDB != nil {
+			_ = sqlDB.Close()
+		}
+		return fmt.Errorf("reopen db: %w", err)
+	}
+	if old := s.db.Swap(next); old != nil {
+		if oldSQL, err := old.DB(); err == nil {
+			time.AfterFunc(SubsystemDrainDelay, func() { _ = oldSQL.Close() })
+		}
+	}
+	return nil
+}
+
+func (s *Services) restartRedis(ctx context.Context) error {
+	old := s.Redis()
+	opts := *old.Options()
+	next := redis.NewClient(&opts)
+	if err := next.Ping(ctx).Err(); err != nil {
+		_ = next.Close()
+		return fmt.Errorf("rebuild redis client: %w", err)
+	}
+	if s.redis.CompareAndSwap(old, next) {
+		time.AfterFunc(SubsystemDrainDelay, func() { _ = old.Close() })
+		return nil
+	}
+	_ = next.Close() // a concurrent restart won
+	return nil
+}
+
+// Restart restarts a subsystem of the app, see Services.Restart.
+func (a *App) Restart(ctx context.Context, name string) error {
+	return a.services.Restart(ctx, name)
+}
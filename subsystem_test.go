@@ -0,0 +1,77 @@
+package golitekit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestServices_RestartCustomSubsystem(t *testing.T) {
+	reloads := 0
+	s := &Services{}
+	WithRestartable("templates", func(ctx context.Context) error {
+		reloads++
+		return nil
+	})(s)
+
+	if err := s.Restart(context.Background(), "templates"); err != nil {
+		t.Fatalf("Restart: %v", err)
+	}
+	if reloads != 1 {
+		t.Errorf("reloads = %d, want 1", reloads)
+	}
+	if err := s.Restart(context.Background(), SubsystemRedis); !errors.Is(err, ErrUnknownSubsystem) {
+		t.Errorf("Restart(redis) without client = %v, want ErrUnknownSubsystem", err)
+	}
+	if got := s.Subsystems(); len(got) != 1 || got[0] != "templates" {
+		t.Errorf("Subsystems = %v, want [templates]", got)
+	}
+}
+
+func TestAdmin_RestartsSubsystems(t *testing.T) {
+	limiter := NewRateLimiter(1, 1)
+	limiter.limiterForKey("203.0.113.7")
+	failing := errors.New("template syntax error")
+
+	app := NewApp(
+		WithRestartable("templates", func(ctx context.Context) error { return nil }),
+		WithRestartable("cache", func(ctx context.Context) error { return failing }),
+	)
+	app.MountAdmin(AdminOptions{Token: "secret", RateLimiters: map[string]*RateLimiter{"api": limiter}})
+
+	rec := adminRequest(app, http.MethodGet, "/_admin/subsystems", "")
+	var names []string
+	if err := json.Unmarshal(rec.Body.Bytes(), &names); err != nil {
+		t.Fatalf("decode: %v (%s)", err, rec.Body)
+	}
+	if want := []string{"cache", "ratelimit", "templates"}; len(names) != 3 || names[0] != want[0] || names[1] != want[1] || names[2] != want[2] {
+		t.Errorf("subsystems = %v, want %v", names, want)
+	}
+
+	rec = adminRequest(app, http.MethodPost, "/_admin/subsystems/ratelimit/restart", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("ratelimit restart status = %d (%s)", rec.Code, rec.Body)
+	}
+	if keys := limiter.Stats().TrackedKeys; keys != 0 {
+		t.Errorf("tracked keys after reset = %d, want 0", keys)
+	}
+
+	if rec = adminRequest(app, http.MethodPost, "/_admin/subsystems/templates/restart", ""); rec.Code != http.StatusOK {
+		t.Errorf("templates restart status = %d (%s)", rec.Code, rec.Body)
+	}
+	if rec = adminRequest(app, http.MethodPost, "/_admin/subsystems/cache/restart", ""); rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("failing restart status = %d, want 503", rec.Code)
+	}
+	if rec = adminRequest(app, http.MethodPost, "/_admin/subsystems/nope/restart", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("unknown restart status = %d, want 404", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	app.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/_admin/subsystems/templates/restart", nil))
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("unauthenticated restart status = %d, want 401", rec.Code)
+	}
+}
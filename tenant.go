@@ -0,0 +1,206 @@
+package golitekit
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/hansir-hsj/GoLiteKit/logger"
+	"github.com/hansir-hsj/GoLiteKit/metrics"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+// DefaultTenantHeader is the header TenantFromHeader reads by default.
+const DefaultTenantHeader = "X-Tenant-Id"
+
+// Tenant is the customer a request acts for, placed in the Context by
+// TenantMiddleware. Its optional connections and rate limit replace the
+// app's for the requests of the tenant.
+type Tenant struct {
+	ID string `json:"id"`
+	// DB and Redis, if set, are returned by Context.DB and Context.Redis
+	// instead of the app's, e.g. for a database per tenant.
+	DB    *gorm.DB      `json:"-"`
+	Redis *redis.Client `json:"-"`
+	// RateLimit, if set, is the per-key limit of the tenant's requests under
+	// a RateLimiter with WithTenantLimits.
+	RateLimit *RateLimitTier `json:"-"`
+	// Config holds tenant settings such as feature flags or branding.
+	Config map[string]string `json:"config,omitempty"`
+}
+
+// Tenant returns the tenant of the request, or nil.
+func (ctx *Context) Tenant() *Tenant {
+	ctx.dataLock.RLock()
+	defer ctx.dataLock.RUnlock()
+	return ctx.tenant
+}
+
+// SetTenant sets the tenant of the request, e.g. from a custom middleware.
+func (ctx *Context) SetTenant(t *Tenant) {
+	ctx.dataLock.Lock()
+	defer ctx.dataLock.Unlock()
+	ctx.tenant = t
+}
+
+// TenantFrom returns the tenant of the request, or nil.
+func TenantFrom(ctx context.Context) *Tenant {
+	if gcx := GetContext(ctx); gcx != nil {
+		return gcx.Tenant()
+	}
+	return nil
+}
+
+// Tenant returns the tenant of the request, or nil.
+func (c *BaseControllerOf[T]) Tenant() *Tenant {
+	if c.gcx == nil {
+		return nil
+	}
+	return c.gcx.Tenant()
+}
+
+// TenantResolver returns the tenant ID of r, or "" when r names none.
+type TenantResolver func(r *http.Request) string
+
+// TenantLookup returns the tenant with id. It returns a nil Tenant for
+// unknown IDs; errors are reported as 500s unless they are an *AppError.
+type TenantLookup func(ctx context.Context, id string) (*Tenant, error)
+
+// TenantFromSubdomain takes the tenant ID from the label before domain, e.g.
+// "acme" for acme.example.com under "example.com". Hosts outside domain or
+// with more labels name no tenant.
+func TenantFromSubdomain(domain string) TenantResolver {
+	suffix := "." + strings.ToLower(strings.Trim(domain, "."))
+	return func(r *http.Request) string {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		label, ok := strings.CutSuffix(strings.ToLower(strings.TrimSuffix(host, ".")), suffix)
+		if !ok || strings.Contains(label, ".") {
+			return ""
+		}
+		return label
+	}
+}
+
+// TenantFromHeader takes the tenant ID from header, DefaultTenantHeader when
+// empty.
+func TenantFromHeader(header string) TenantResolver {
+	if header == "" {
+		header = DefaultTenantHeader
+	}
+	return func(r *http.Request) string {
+		return strings.TrimSpace(r.Header.Get(header))
+	}
+}
+
+// TenantFromClaim takes the tenant ID from a claim of the authenticated
+// Principal, so register TenantMiddleware after the auth middleware.
+func TenantFromClaim(claim string) TenantResolver {
+	return func(r *http.Request) string {
+		return PrincipalFrom(r.Context()).claim(claim)
+	}
+}
+
+func (p *Principal) claim(key string) string {
+	if p == nil {
+		return ""
+	}
+	return p.Claims[key]
+}
+
+// FirstTenant returns the first tenant ID the resolvers find, e.g. a token
+// claim and else the subdomain.
+func FirstTenant(resolvers ...TenantResolver) TenantResolver {
+	return func(r *http.Request) string {
+		for _, resolve := range resolvers {
+			if id := resolve(r); id != "" {
+				return id
+			}
+		}
+		return ""
+	}
+}
+
+// StaticTenants looks tenants up in a fixed list.
+func StaticTenants(tenants ...*Tenant) TenantLookup {
+	byID := make(map[string]*Tenant, len(tenants))
+	for _, t := range tenants {
+		byID[t.ID] = t
+	}
+	return func(ctx context.Context, id string) (*Tenant, error) {
+		return byID[id], nil
+	}
+}
+
+// TenantOptions configures TenantMiddleware.
+type TenantOptions struct {
+	// Lookup loads the resolved tenant; requests naming an unknown tenant are
+	// answered with 404. Defaults to a Tenant with only the ID.
+	Lookup TenantLookup
+	// Optional serves requests that name no tenant, without one in the
+	// Context; otherwise they are answered with 400.
+	Optional bool
+}
+
+// TenantMiddleware resolves the tenant of each request and places it in the
+// Context. The tenant ID is logged as "tenant" with every record of the
+// request and set as the "tenant" metrics.Label, which otel records once
+// allowlisted with otel.WithRequestLabels("tenant").
+func TenantMiddleware(resolve TenantResolver, opts ...TenantOptions) Middleware {
+	var opt TenantOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	return func(next Handler) Handler {
+		return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			id := resolve(r)
+			if id == "" {
+				if opt.Optional {
+					return next(ctx, w, r)
+				}
+				return ErrBadRequest("Tenant required", nil)
+			}
+			t := &Tenant{ID: id}
+			if opt.Lookup != nil {
+				var err error
+				if t, err = opt.Lookup(ctx, id); err != nil {
+					return WrapError(err, http.StatusInternalServerError)
+				}
+				if t == nil {
+					return ErrNotFound("Unknown tenant", nil)
+				}
+			}
+			if gcx := GetContext(ctx); gcx != nil {
+				gcx.SetTenant(t)
+			}
+			logger.AddInfo(ctx, "tenant", t.ID)
+			metrics.Label(ctx, "tenant", t.ID)
+			return next(ctx, w, r)
+		}
+	}
+}
+
+// ByTenant returns the tenant ID of the request for use as a rate limiter
+// key, so all requests of a tenant share one limit. Requests without a tenant
+// share the key "".
+func ByTenant(r *http.Request) string {
+	if t := TenantFrom(r.Context()); t != nil {
+		return t.ID
+	}
+	return ""
+}
+
+// WithTenantLimits limits each key by the RateLimit of the request's tenant;
+// requests without a tenant or whose tenant has none get the limiter's rate
+// and burst.
+func WithTenantLimits() RateLimiterOption {
+	return func(opts *RateLimiterOptions) {
+		opts.Tiers = nil
+		opts.TierOf = nil
+		opts.TenantLimits = true
+	}
+}
@@ -0,0 +1,135 @@
+package golitekit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/redis/go-redis/v9"
+)
+
+func TestTenantResolvers(t *testing.T) {
+	sub := TenantFromSubdomain("example.com")
+	claim := TenantFromClaim("tenant")
+	tests := []struct {
+		name    string
+		host    string
+		header  string
+		claims  map[string]string
+		resolve TenantResolver
+		want    string
+	}{
+		{"subdomain", "Acme.Example.com:8443", "", nil, sub, "acme"},
+		{"apex", "example.com", "", nil, sub, ""},
+		{"nested subdomain", "a.b.example.com", "", nil, sub, ""},
+		{"other domain", "acme.example.org", "", nil, sub, ""},
+		{"header", "example.com", " globex ", nil, TenantFromHeader(""), "globex"},
+		{"claim", "example.com", "", map[string]string{"tenant": "initech"}, claim, "initech"},
+		{"no principal", "example.com", "", nil, claim, ""},
+		{"first", "acme.example.com", "globex", nil, FirstTenant(claim, TenantFromHeader(""), sub), "globex"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.Host = tt.host
+			if tt.header != "" {
+				r.Header.Set(DefaultTenantHeader, tt.header)
+			}
+			if tt.claims != nil {
+				ctx := withContext(r.Context())
+				GetContext(ctx).SetPrincipal(&Principal{ID: "u1", Claims: tt.claims})
+				r = r.WithContext(ctx)
+			}
+			if got := tt.resolve(r); got != tt.want {
+				t.Fatalf("tenant = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTenantMiddleware(t *testing.T) {
+	acmeRedis := redis.NewClient(&redis.Options{Addr: "127.0.0.1:0"})
+	defer acmeRedis.Close()
+	lookup := StaticTenants(&Tenant{ID: "acme", Redis: acmeRedis})
+	failing := func(ctx context.Context, id string) (*Tenant, error) { return nil, errors.New("db down") }
+
+	tests := []struct {
+		name       string
+		opts       TenantOptions
+		tenant     string
+		wantStatus int
+		wantTenant string
+	}{
+		{"known", TenantOptions{Lookup: lookup}, "acme", http.StatusOK, "acme"},
+		{"unknown", TenantOptions{Lookup: lookup}, "globex", http.StatusNotFound, ""},
+		{"missing", TenantOptions{Lookup: lookup}, "", http.StatusBadRequest, ""},
+		{"optional", TenantOptions{Lookup: lookup, Optional: true}, "", http.StatusOK, ""},
+		{"no lookup", TenantOptions{}, "globex", http.StatusOK, "globex"},
+		{"lookup error", TenantOptions{Lookup: failing}, "acme", http.StatusInternalServerError, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := NewApp()
+			app.Use(TenantMiddleware(TenantFromHeader(""), tt.opts))
+			var got *Tenant
+			var client *redis.Client
+			app.GET("/orders", HandlerFunc(func(ctx *Context) error {
+				got, client = ctx.Tenant(), ctx.Redis()
+				return nil
+			}))
+			req := httptest.NewRequest(http.MethodGet, "/orders", nil)
+			if tt.tenant != "" {
+				req.Header.Set(DefaultTenantHeader, tt.tenant)
+			}
+			rec := httptest.NewRecorder()
+			app.Handler().ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if id := got.idOrEmpty(); id != tt.wantTenant {
+				t.Fatalf("tenant = %q, want %q", id, tt.wantTenant)
+			}
+			if tt.wantTenant == "acme" && client != acmeRedis {
+				t.Fatalf("Redis() did not return the tenant's client")
+			}
+		})
+	}
+}
+
+func (t *Tenant) idOrEmpty() string {
+	if t == nil {
+		return ""
+	}
+	return t.ID
+}
+
+func TestRateLimiterWithTenantLimits(t *testing.T) {
+	limiter := NewRateLimiter(0.001, 1, WithTenantLimits())
+	defer limiter.Close()
+	app := NewApp()
+	app.Use(TenantMiddleware(TenantFromHeader(""), TenantOptions{Lookup: StaticTenants(
+		&Tenant{ID: "pro", RateLimit: &RateLimitTier{Rate: 0.001, Burst: 3}},
+		&Tenant{ID: "free"},
+	)}))
+	app.Use(limiter.RateLimiterAsMiddleware(ByTenant))
+	app.GET("/orders", HandlerFunc(func(ctx *Context) error { return nil }))
+
+	allowed := map[string]int{}
+	for range 5 {
+		for _, tenant := range []string{"pro", "free"} {
+			req := httptest.NewRequest(http.MethodGet, "/orders", nil)
+			req.Header.Set(DefaultTenantHeader, tenant)
+			rec := httptest.NewRecorder()
+			app.Handler().ServeHTTP(rec, req)
+			if rec.Code == http.StatusOK {
+				allowed[tenant]++
+			}
+		}
+	}
+	if allowed["pro"] != 3 || allowed["free"] != 1 {
+		t.Fatalf("allowed = %v, want pro 3 and free 1", allowed)
+	}
+}
@@ -2,19 +2,112 @@ package golitekit
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net"
 	"net/http"
 	"time"
+
+	"github.com/hansir-hsj/GoLiteKit/logger"
 )
 
+// TimeoutSource identifies where a deadline was detected, so 5xx server-side
+// timeouts can be told apart from slow clients and load shedding.
+type TimeoutSource int
+
+const (
+	// TimeoutSourceHandler means the request deadline set by TimeoutMiddleware expired.
+	TimeoutSourceHandler TimeoutSource = iota
+	// TimeoutSourceUpstream means a downstream call (DB, cache, HTTP) timed out
+	// while the request deadline was still running.
+	TimeoutSourceUpstream
+	// TimeoutSourceClient means the client was too slow sending the request.
+	TimeoutSourceClient
+	// TimeoutSourceShed means the request was rejected to shed load.
+	TimeoutSourceShed
+)
+
+func (s TimeoutSource) String() string {
+	switch s {
+	case TimeoutSourceHandler:
+		return "handler"
+	case TimeoutSourceUpstream:
+		return "upstream"
+	case TimeoutSourceClient:
+		return "client"
+	case TimeoutSourceShed:
+		return "shed"
+	}
+	return "unknown"
+}
+
+// DefaultStatus returns the status code used for the source when no override
+// is configured: 504 for handler and upstream, 408 for client, 503 for shed.
+func (s TimeoutSource) DefaultStatus() int {
+	switch s {
+	case TimeoutSourceClient:
+		return http.StatusRequestTimeout
+	case TimeoutSourceShed:
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusGatewayTimeout
+}
+
+func (s TimeoutSource) message() string {
+	switch s {
+	case TimeoutSourceHandler:
+		return "Handler timeout"
+	case TimeoutSourceUpstream:
+		return "Upstream timeout"
+	case TimeoutSourceClient:
+		return "Client request timeout"
+	case TimeoutSourceShed:
+		return "Service overloaded"
+	}
+	return "Timeout"
+}
+
+// NewTimeoutError returns an AppError for source with its default status code.
+func NewTimeoutError(source TimeoutSource, internal error) *AppError {
+	return &AppError{Code: source.DefaultStatus(), Message: source.message(), Internal: internal}
+}
+
 // TimeoutOptions configures the timeout middleware.
 type TimeoutOptions struct {
 	Duration   time.Duration
 	SSETimeout time.Duration
+
+	// HandlerStatus overrides the status for TimeoutSourceHandler (default 504).
+	HandlerStatus int
+	// UpstreamStatus overrides the status for TimeoutSourceUpstream (default 504).
+	UpstreamStatus int
+}
+
+func (opt TimeoutOptions) timeoutError(ctx context.Context, source TimeoutSource, internal error) *AppError {
+	appErr := NewTimeoutError(source, internal)
+	switch {
+	case source == TimeoutSourceHandler && opt.HandlerStatus != 0:
+		appErr.Code = opt.HandlerStatus
+	case source == TimeoutSourceUpstream && opt.UpstreamStatus != 0:
+		appErr.Code = opt.UpstreamStatus
+	}
+	logger.AddInfo(ctx, "timeout_source", source.String())
+	return appErr
 }
 
 // TimeoutMiddleware creates a timeout middleware.
-// Without options, no timeout is applied.
+// Without options, no timeout is applied. With a timeout, the rest of the
+// chain runs in its own goroutine behind a guarded ResponseWriter: when the
+// deadline expires before the response started, the writer is closed to the
+// handler (its writes fail with http.ErrHandlerTimeout) and the timeout error
+// is returned at once, so the client gets a 504 even from handlers that ignore
+// ctx. A response that already started (e.g. SSE) cannot be replaced; the
+// middleware then waits for the handler to return.
+//
+// Deadline errors are classified by where they were detected: an expired
+// request deadline is a handler timeout, a deadline error returned while the
+// request deadline was still running is an upstream timeout. Client read
+// timeouts are reported as 408 by request parsing and pass through unchanged.
 func TimeoutMiddleware(opts ...TimeoutOptions) Middleware {
 	var opt TimeoutOptions
 	if len(opts) > 0 {
@@ -31,7 +124,11 @@ func TimeoutMiddleware(opts ...TimeoutOptions) Middleware {
 			}
 
 			if timeout < 1 {
-				return next(ctx, w, r)
+				err := next(ctx, w, r)
+				if isUnclassifiedTimeout(err) {
+					return opt.timeoutError(ctx, TimeoutSourceUpstream, err)
+				}
+				return err
 			}
 
 			timeoutCtx, cancel := context.WithTimeoutCause(
@@ -41,13 +138,81 @@ func TimeoutMiddleware(opts ...TimeoutOptions) Middleware {
 			)
 			defer cancel()
 
-			err := next(timeoutCtx, w, r.WithContext(timeoutCtx))
+			tw := newTimeoutResponseWriter(w)
+			done := make(chan timeoutResult, 1)
+			go func() {
+				var res timeoutResult
+				defer func() {
+					if p := recover(); p != nil {
+						res = timeoutResult{panicked: true, recovered: p}
+					}
+					done <- res
+				}()
+				res.err = next(timeoutCtx, tw, r.WithContext(timeoutCtx))
+			}()
 
-			if timeoutCtx.Err() == context.DeadlineExceeded && err == nil {
-				return ErrTimeout(fmt.Sprintf("Request timeout: %v", context.Cause(timeoutCtx)), nil)
+			var res timeoutResult
+			select {
+			case res = <-done:
+			case <-timeoutCtx.Done():
+				if timeoutCtx.Err() == context.DeadlineExceeded && tw.timeout() {
+					go reportLatePanic(ctx, done)
+					return opt.timeoutError(ctx, TimeoutSourceHandler, context.Cause(timeoutCtx))
+				}
+				res = <-done
+			}
+			if res.panicked {
+				panic(res.recovered) // for ErrorHandlerMiddleware, on the request goroutine
+			}
+
+			err := res.err
+			if timeoutCtx.Err() == context.DeadlineExceeded && (err == nil || isUnclassifiedTimeout(err)) {
+				return opt.timeoutError(ctx, TimeoutSourceHandler, context.Cause(timeoutCtx))
+			}
+			if isUnclassifiedTimeout(err) {
+				return opt.timeoutError(ctx, TimeoutSourceUpstream, err)
 			}
 
 			return err
 		}
 	}
 }
+
+type timeoutResult struct {
+	err       error
+	panicked  bool
+	recovered any
+}
+
+// reportLatePanic waits for a handler abandoned by TimeoutMiddleware and
+// reports its panic, which no longer has a request to fail.
+func reportLatePanic(ctx context.Context, done <-chan timeoutResult) {
+	res := <-done
+	if !res.panicked {
+		return
+	}
+	if gcx := GetContext(ctx); gcx != nil && gcx.PanicLogger() != nil {
+		gcx.PanicLogger().Report(ctx, res.recovered)
+	}
+}
+
+// isTimeoutError reports whether err is a context deadline or network timeout.
+func isTimeoutError(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// isUnclassifiedTimeout reports whether err is a timeout that has not been
+// given a specific status yet: a plain error or a generic 500 AppError.
+func isUnclassifiedTimeout(err error) bool {
+	if appErr, ok := err.(*AppError); ok && appErr.Code != http.StatusInternalServerError {
+		return false
+	}
+	return isTimeoutError(err)
+}
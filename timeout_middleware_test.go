@@ -212,3 +212,204 @@ func TestTimeoutMiddlewareUsesExplicitDuration(t *testing.T) {
 		t.Fatal("handler was not called")
 	}
 }
+
+type fakeNetTimeout struct{}
+
+func (fakeNetTimeout) Error() string   { return "i/o timeout" }
+func (fakeNetTimeout) Timeout() bool   { return true }
+func (fakeNetTimeout) Temporary() bool { return true }
+
+func runTimeoutMiddleware(t *testing.T, opts TimeoutOptions, inner Handler) error {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, "/test", nil)
+	ctx := withContext(req.Context())
+	req = req.WithContext(ctx)
+	return TimeoutMiddleware(opts)(inner)(ctx, httptest.NewRecorder(), req)
+}
+
+func TestTimeoutMiddleware_ClassifiesTimeoutSources(t *testing.T) {
+	testCases := []struct {
+		name     string
+		opts     TimeoutOptions
+		inner    Handler
+		wantCode int
+		wantMsg  string
+	}{
+		{
+			name: "handler deadline returns 504",
+			opts: TimeoutOptions{Duration: 20 * time.Millisecond},
+			inner: func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+				<-ctx.Done()
+				return ctx.Err()
+			},
+			wantCode: http.StatusGatewayTimeout,
+			wantMsg:  "Handler timeout",
+		},
+		{
+			name: "handler status override",
+			opts: TimeoutOptions{Duration: 20 * time.Millisecond, HandlerStatus: http.StatusServiceUnavailable},
+			inner: func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+				<-ctx.Done()
+				return nil
+			},
+			wantCode: http.StatusServiceUnavailable,
+			wantMsg:  "Handler timeout",
+		},
+		{
+			name: "upstream deadline within request deadline returns 504",
+			opts: TimeoutOptions{Duration: time.Minute},
+			inner: func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+				return ErrInternal("query failed", fmt.Errorf("db: %w", context.DeadlineExceeded))
+			},
+			wantCode: http.StatusGatewayTimeout,
+			wantMsg:  "Upstream timeout",
+		},
+		{
+			name: "upstream network timeout without request deadline",
+			opts: TimeoutOptions{UpstreamStatus: http.StatusBadGateway},
+			inner: func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+				return fakeNetTimeout{}
+			},
+			wantCode: http.StatusBadGateway,
+			wantMsg:  "Upstream timeout",
+		},
+		{
+			name: "client timeout passes through",
+			opts: TimeoutOptions{Duration: time.Minute},
+			inner: func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+				return NewTimeoutError(TimeoutSourceClient, fakeNetTimeout{})
+			},
+			wantCode: http.StatusRequestTimeout,
+			wantMsg:  "Client request timeout",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := runTimeoutMiddleware(t, tc.opts, tc.inner)
+			appErr, ok := err.(*AppError)
+			if !ok {
+				t.Fatalf("error = %T %v, want *AppError", err, err)
+			}
+			if appErr.Code != tc.wantCode {
+				t.Errorf("status = %d, want %d", appErr.Code, tc.wantCode)
+			}
+			if appErr.Message != tc.wantMsg {
+				t.Errorf("message = %q, want %q", appErr.Message, tc.wantMsg)
+			}
+		})
+	}
+}
+
+func TestTimeoutMiddleware_LeavesClassifiedErrorsAlone(t *testing.T) {
+	err := runTimeoutMiddleware(t, TimeoutOptions{Duration: time.Minute}, func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		return ErrBadRequest("bad", fakeNetTimeout{})
+	})
+	if appErr, ok := err.(*AppError); !ok || appErr.Code != http.StatusBadRequest {
+		t.Fatalf("error = %v, want untouched 400", err)
+	}
+}
+
+func TestTimeoutSource_DefaultStatus(t *testing.T) {
+	want := map[TimeoutSource]int{
+		TimeoutSourceHandler:  http.StatusGatewayTimeout,
+		TimeoutSourceUpstream: http.StatusGatewayTimeout,
+		TimeoutSourceClient:   http.StatusRequestTimeout,
+		TimeoutSourceShed:     http.StatusServiceUnavailable,
+	}
+	for source, code := range want {
+		if got := source.DefaultStatus(); got != code {
+			t.Errorf("%s status = %d, want %d", source, got, code)
+		}
+	}
+}
+
+func timeoutTestRouter(d time.Duration) *Router {
+	r := NewRouter(nil)
+	r.Use(ErrorHandlerMiddleware())
+	r.Use(TimeoutMiddleware(TimeoutOptions{Duration: d}))
+	r.Use(ContextAsMiddleware())
+	return r
+}
+
+func TestTimeoutMiddleware_RespondsWhileHandlerIgnoresContext(t *testing.T) {
+	release := make(chan struct{})
+	lateWrite := make(chan error, 1)
+	r := timeoutTestRouter(20 * time.Millisecond)
+	r.GET("/slow", func(ctx *Context) error {
+		<-release // ignores ctx
+		_, err := ctx.ResponseWriter().Write([]byte("late"))
+		lateWrite <- err
+		return ctx.String(http.StatusOK, "late")
+	})
+
+	rec := httptest.NewRecorder()
+	start := time.Now()
+	r.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/slow", nil))
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("response took %v, want it at the deadline", elapsed)
+	}
+	if rec.Code != http.StatusGatewayTimeout {
+		t.Fatalf("code = %d, want 504", rec.Code)
+	}
+	body := rec.Body.String()
+
+	close(release)
+	if err := <-lateWrite; err != http.ErrHandlerTimeout {
+		t.Errorf("late write error = %v, want http.ErrHandlerTimeout", err)
+	}
+	time.Sleep(10 * time.Millisecond) // let ContextAsMiddleware try to write too
+	if rec.Body.String() != body {
+		t.Errorf("late handler output reached the client: %q", rec.Body)
+	}
+}
+
+func TestTimeoutMiddleware_WaitsForStartedResponse(t *testing.T) {
+	r := timeoutTestRouter(20 * time.Millisecond)
+	r.GET("/stream", func(ctx *Context) error {
+		w := ctx.ResponseWriter()
+		w.Header().Set("X-Stream", "1")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("first"))
+		w.(http.Flusher).Flush()
+		<-ctx.Request().Context().Done()
+		w.Write([]byte(" last"))
+		return nil
+	})
+
+	rec := httptest.NewRecorder()
+	r.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/stream", nil))
+	if rec.Code != http.StatusOK || rec.Body.String() != "first last" || rec.Header().Get("X-Stream") != "1" {
+		t.Errorf("stream = %d %q %v", rec.Code, rec.Body, rec.Header())
+	}
+}
+
+func TestTimeoutMiddleware_PropagatesHandlerPanic(t *testing.T) {
+	r := timeoutTestRouter(time.Minute)
+	r.GET("/panic", func(ctx *Context) error {
+		panic("boom")
+	})
+
+	rec := httptest.NewRecorder()
+	r.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/panic", nil))
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("code = %d, want 500", rec.Code)
+	}
+}
+
+func TestTimeoutResponseWriter_KeepsOuterHeaders(t *testing.T) {
+	rec := httptest.NewRecorder()
+	rec.Header().Set("X-Log-Id", "abc")
+	rec.Header().Set("X-Drop", "1")
+	tw := newTimeoutResponseWriter(rec)
+	tw.Header().Del("X-Drop")
+	tw.Header().Set("Content-Type", "text/plain")
+	tw.Write([]byte("ok"))
+
+	if rec.Header().Get("X-Log-Id") != "abc" || rec.Header().Get("X-Drop") != "" || rec.Header().Get("Content-Type") != "text/plain" {
+		t.Errorf("headers = %v", rec.Header())
+	}
+	if tw.timeout() {
+		t.Error("timeout took over a started response")
+	}
+}
@@ -0,0 +1,167 @@
+package golitekit
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/hansir-hsj/GoLiteKit/internal/rules"
+)
+
+// FieldError describes one failed validation rule.
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Param   string `json:"param,omitempty"`
+	Message string `json:"message"`
+}
+
+// ValidationErrors is returned (wrapped in a 400 AppError) when struct tag
+// validation fails. The default error formatter renders it as the response data.
+type ValidationErrors []FieldError
+
+func (e ValidationErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, fe := range e {
+		msgs[i] = fe.Message
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Translator resolves a message key with placeholder args, e.g. a Catalog
+// bound to the request locales.
+type Translator func(key string, args map[string]string) string
+
+// ValidateStruct checks the `validate` tags of v (a struct or pointer to
+// struct) and returns ValidationErrors, or nil when every rule passes.
+//
+// Supported rules: required, min=N, max=N, len=N, email, oneof=a b c. min and
+// max compare numbers by value and strings, slices and maps by length. Nested
+// structs are validated with dotted field paths. Other rules are ignored, so
+// structs tagged for another validator can be checked as well.
+//
+// Messages are resolved through tr using the key "validation.<rule>" with
+// {field} and {param} placeholders. The `msg` tag overrides the key, either for
+// every rule (`msg:"user.name.invalid"`) or per rule
+// (`msg:"required=user.name.required,min=user.name.short"`). The {field}
+// placeholder is itself translated via "field.<name>" when that key exists.
+func ValidateStruct(v any, tr Translator) error {
+	if tr == nil {
+		catalog := DefaultCatalog()
+		tr = func(key string, args map[string]string) string {
+			return catalog.Translate(nil, key, args)
+		}
+	}
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return nil
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil
+	}
+	var errs ValidationErrors
+	validateFields(rv, "", tr, &errs)
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+func validateFields(rv reflect.Value, prefix string, tr Translator, errs *ValidationErrors) {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		fv := rv.Field(i)
+		name := fieldName(sf)
+		if name == "-" {
+			continue
+		}
+		path := name
+		if sf.Anonymous {
+			path = strings.TrimSuffix(prefix, ".")
+		} else if prefix != "" {
+			path = prefix + name
+		}
+
+		if tag := sf.Tag.Get("validate"); tag != "" && tag != "-" {
+			keys := parseMessageKeys(sf.Tag.Get("msg"))
+			for _, rule := range strings.Split(tag, ",") {
+				rule, param, _ := strings.Cut(strings.TrimSpace(rule), "=")
+				if rule == "" {
+					continue
+				}
+				// Rules of other validators, e.g. go-playground's gte or dive,
+				// and malformed parameters are not ours to enforce.
+				if ok, known := rules.Check(fv, rule, param); ok || !known {
+					continue
+				}
+				key := "validation." + rule
+				if k, found := keys[rule]; found {
+					key = k
+				} else if k, found := keys[""]; found {
+					key = k
+				}
+				label := tr("field."+path, nil)
+				if label == "field."+path {
+					label = path
+				}
+				*errs = append(*errs, FieldError{
+					Field:   path,
+					Rule:    rule,
+					Param:   param,
+					Message: tr(key, map[string]string{"field": label, "param": param}),
+				})
+				if rule == "required" {
+					break
+				}
+			}
+		}
+
+		nested := fv
+		if nested.Kind() == reflect.Pointer && !nested.IsNil() {
+			nested = nested.Elem()
+		}
+		if nested.Kind() == reflect.Struct {
+			next := path + "."
+			if path == "" {
+				next = ""
+			}
+			validateFields(nested, next, tr, errs)
+		}
+	}
+}
+
+func fieldName(sf reflect.StructField) string {
+	if sf.Anonymous {
+		return ""
+	}
+	for _, key := range []string{"json", "form"} {
+		if name, _, _ := strings.Cut(sf.Tag.Get(key), ","); name != "" {
+			return name
+		}
+	}
+	return sf.Name
+}
+
+// parseMessageKeys parses a `msg` tag into rule -> key; the "" entry applies
+// to every rule.
+func parseMessageKeys(tag string) map[string]string {
+	if tag == "" {
+		return nil
+	}
+	if !strings.Contains(tag, "=") {
+		return map[string]string{"": tag}
+	}
+	keys := make(map[string]string)
+	for _, pair := range strings.Split(tag, ",") {
+		if rule, key, ok := strings.Cut(strings.TrimSpace(pair), "="); ok {
+			keys[rule] = key
+		}
+	}
+	return keys
+}
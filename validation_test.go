@@ -0,0 +1,141 @@
+package golitekit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type signupRequest struct {
+	Name    string `json:"name" validate:"required,min=3" msg:"min=signup.name.short"`
+	Email   string `json:"email" validate:"required,email"`
+	Age     int    `json:"age" validate:"min=18,max=130"`
+	Role    string `json:"role" validate:"oneof=admin user"`
+	Address struct {
+		City string `json:"city" validate:"required"`
+	} `json:"address"`
+}
+
+func TestValidateStruct_Rules(t *testing.T) {
+	var req signupRequest
+	req.Name = "al"
+	req.Email = "not-an-email"
+	req.Age = 12
+	req.Role = "root"
+
+	err := ValidateStruct(&req, nil)
+	fields, ok := err.(ValidationErrors)
+	if !ok {
+		t.Fatalf("err = %T %v, want ValidationErrors", err, err)
+	}
+	got := map[string]string{}
+	for _, fe := range fields {
+		got[fe.Field] = fe.Rule
+	}
+	want := map[string]string{"name": "min", "email": "email", "age": "min", "role": "oneof", "address.city": "required"}
+	for field, rule := range want {
+		if got[field] != rule {
+			t.Errorf("field %s rule = %q, want %q (all: %+v)", field, got[field], rule, fields)
+		}
+	}
+
+	req.Name, req.Email, req.Age, req.Role, req.Address.City = "alice", "alice@example.com", 30, "user", "Paris"
+	if err := ValidateStruct(&req, nil); err != nil {
+		t.Fatalf("valid request: %v", err)
+	}
+}
+
+func TestValidateStruct_IgnoresUnknownRules(t *testing.T) {
+	type foreignTags struct {
+		Age  int      `validate:"gte=18"`
+		ID   string   `validate:"required,uuid"`
+		Tags []string `validate:"dive,min=x"`
+	}
+	err := ValidateStruct(&foreignTags{Age: 1, ID: "x", Tags: []string{"a"}}, nil)
+	if err != nil {
+		t.Fatalf("err = %v, want unknown rules ignored", err)
+	}
+	if err := ValidateStruct(&foreignTags{}, nil); err == nil {
+		t.Fatal("required still applies next to unknown rules")
+	}
+}
+
+type plainSignupController struct {
+	BaseControllerOf[signupRequest]
+}
+
+func (c *plainSignupController) Serve(ctx context.Context) error {
+	return c.JSON(http.StatusOK, map[string]string{"ok": "true"})
+}
+
+func TestBaseController_ValidateIsOptIn(t *testing.T) {
+	r := newTestRouter()
+	r.POST("/signup", &plainSignupController{})
+	req := httptest.NewRequest(http.MethodPost, "/signup", bytes.NewReader([]byte(`{"name":"al"}`)))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	r.Handler().ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200 without ValidateRequest; body = %s", rec.Code, rec.Body)
+	}
+}
+
+type signupController struct {
+	BaseControllerOf[signupRequest]
+}
+
+func (c *signupController) Validate(ctx context.Context) error {
+	return c.ValidateRequest()
+}
+
+func (c *signupController) Serve(ctx context.Context) error {
+	return c.JSON(http.StatusOK, map[string]string{"ok": "true"})
+}
+
+func TestBaseController_ValidateTranslatesPerLocale(t *testing.T) {
+	catalog := NewCatalog().
+		Add("en", map[string]string{"signup.name.short": "{field} needs {param}+ characters"}).
+		Add("zh", map[string]string{"field.name": "用户名", "signup.name.short": "{field}至少{param}个字符"})
+
+	r := newTestRouter()
+	r.services = &Services{}
+	WithCatalog(catalog)(r.services)
+	r.POST("/signup", &signupController{})
+
+	post := func(lang string) Response {
+		t.Helper()
+		body, _ := json.Marshal(map[string]any{"name": "al", "email": "alice@example.com", "address": map[string]string{"city": "Paris"}, "age": 20})
+		req := httptest.NewRequest(http.MethodPost, "/signup", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept-Language", lang)
+		rec := httptest.NewRecorder()
+		r.Handler().ServeHTTP(rec, req)
+		if rec.Code != http.StatusBadRequest {
+			t.Fatalf("status = %d, want 400; body = %s", rec.Code, rec.Body)
+		}
+		var resp Response
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		return resp
+	}
+
+	en := post("en-US,en;q=0.9")
+	if en.Msg != "Validation failed" {
+		t.Fatalf("en msg = %q", en.Msg)
+	}
+	if data := en.Data.([]any)[0].(map[string]any); data["message"] != "name needs 3+ characters" {
+		t.Fatalf("en field message = %v", data["message"])
+	}
+
+	zh := post("fr;q=0.5, zh-CN")
+	if zh.Msg != "参数校验失败" {
+		t.Fatalf("zh msg = %q", zh.Msg)
+	}
+	if data := zh.Data.([]any)[0].(map[string]any); data["message"] != "用户名至少3个字符" {
+		t.Fatalf("zh field message = %v", data["message"])
+	}
+}
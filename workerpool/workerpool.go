@@ -0,0 +1,245 @@
+// Package workerpool runs tasks on a bounded set of goroutines. Submit
+// waits for room in the queue as long as its context allows, Drain stops
+// the pool and waits for the queued tasks on shutdown, and Group fans work
+// out from a handler and collects the first error:
+//
+//	pool := workerpool.New(workerpool.Options{Workers: 8})
+//	app := glk.NewApp(glk.WithWorkerPool("fanout", pool))
+//
+//	g, ctx := pool.Group(ctx)
+//	for _, id := range ids {
+//		g.Go(func(ctx context.Context) error { return load(ctx, id) })
+//	}
+//	err := g.Wait()
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+	"runtime/debug"
+	"sync"
+)
+
+// ErrClosed is returned by Submit once Drain was called.
+var ErrClosed = errors.New("workerpool: pool is draining")
+
+// Options configures New.
+type Options struct {
+	// Workers is the number of goroutines running tasks, defaults to
+	// GOMAXPROCS.
+	Workers int
+	// QueueSize is the number of tasks waiting for a worker, defaults to
+	// Workers.
+	QueueSize int
+	// OnPanic receives the value a task panicked with; the worker keeps
+	// running. Defaults to printing it with the stack to stderr.
+	OnPanic func(recovered any)
+}
+
+type task struct {
+	ctx context.Context
+	fn  func(ctx context.Context)
+}
+
+// Pool is a bounded worker pool. It is safe for concurrent use.
+type Pool struct {
+	tasks   chan task
+	quit    chan struct{}
+	onPanic func(any)
+
+	mu         sync.Mutex
+	closed     bool
+	submitting sync.WaitGroup
+	workers    sync.WaitGroup
+	drainOnce  sync.Once
+	done       chan struct{}
+}
+
+// New starts a pool.
+func New(opts ...Options) *Pool {
+	var opt Options
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if opt.Workers <= 0 {
+		opt.Workers = runtime.GOMAXPROCS(0)
+	}
+	if opt.QueueSize <= 0 {
+		opt.QueueSize = opt.Workers
+	}
+	if opt.OnPanic == nil {
+		opt.OnPanic = func(recovered any) {
+			fmt.Fprintf(os.Stderr, "workerpool: task panic: %v\n%s", recovered, debug.Stack())
+		}
+	}
+
+	p := &Pool{
+		tasks:   make(chan task, opt.QueueSize),
+		quit:    make(chan struct{}),
+		onPanic: opt.OnPanic,
+		done:    make(chan struct{}),
+	}
+	p.workers.Add(opt.Workers)
+	for i := 0; i < opt.Workers; i++ {
+		go p.work()
+	}
+	return p
+}
+
+func (p *Pool) work() {
+	defer p.workers.Done()
+	for t := range p.tasks {
+		p.run(t)
+	}
+}
+
+// run calls the task unless its context ended while it was queued.
+func (p *Pool) run(t task) {
+	if t.ctx.Err() != nil {
+		return
+	}
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			p.onPanic(recovered)
+		}
+	}()
+	t.fn(t.ctx)
+}
+
+// Submit queues fn to run with ctx, waiting for room in the queue until ctx
+// is done. A task whose ctx ends before a worker picks it up is dropped.
+func (p *Pool) Submit(ctx context.Context, fn func(ctx context.Context)) error {
+	return p.submit(ctx, task{ctx: ctx, fn: fn})
+}
+
+// submit queues t, waiting until ctx is done.
+func (p *Pool) submit(ctx context.Context, t task) error {
+	if !p.enter() {
+		return ErrClosed
+	}
+	defer p.submitting.Done()
+
+	select {
+	case p.tasks <- t:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-p.quit:
+		return ErrClosed
+	}
+}
+
+// TrySubmit queues fn like Submit but without waiting; it reports whether
+// fn was queued.
+func (p *Pool) TrySubmit(ctx context.Context, fn func(ctx context.Context)) bool {
+	if !p.enter() {
+		return false
+	}
+	defer p.submitting.Done()
+
+	select {
+	case p.tasks <- task{ctx: ctx, fn: fn}:
+		return true
+	default:
+		return false
+	}
+}
+
+// enter registers a Submit call unless the pool is draining.
+func (p *Pool) enter() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return false
+	}
+	p.submitting.Add(1)
+	return true
+}
+
+// Drain stops accepting tasks and waits for the queued and running ones to
+// finish. When ctx is done first it returns ctx.Err(); the workers still
+// finish the queue in the background. Drain may be called more than once.
+func (p *Pool) Drain(ctx context.Context) error {
+	p.drainOnce.Do(func() {
+		p.mu.Lock()
+		p.closed = true
+		p.mu.Unlock()
+		close(p.quit)
+
+		go func() {
+			p.submitting.Wait()
+			close(p.tasks)
+			p.workers.Wait()
+			close(p.done)
+		}()
+	})
+
+	select {
+	case <-p.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Group runs related tasks on the pool and waits for them, like errgroup:
+// the first error cancels the context the tasks get.
+type Group struct {
+	pool   *Pool
+	ctx    context.Context
+	cancel context.CancelCauseFunc
+	wg     sync.WaitGroup
+
+	errOnce sync.Once
+	err     error
+}
+
+// Group returns a Group whose tasks run with a context derived from ctx.
+func (p *Pool) Group(ctx context.Context) (*Group, context.Context) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	return &Group{pool: p, ctx: ctx, cancel: cancel}, ctx
+}
+
+// Go submits fn, waiting for room in the queue. A panic in fn, or a failed
+// submit, is the Group's error like a returned one; once the Group failed,
+// queued tasks are skipped.
+func (g *Group) Go(fn func(ctx context.Context) error) {
+	g.wg.Add(1)
+	// The task is queued without cancellation so the pool never drops it
+	// before it can mark itself done.
+	err := g.pool.submit(g.ctx, task{ctx: context.WithoutCancel(g.ctx), fn: func(context.Context) {
+		defer g.wg.Done()
+		if g.ctx.Err() != nil {
+			return
+		}
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				g.fail(fmt.Errorf("workerpool: task panic: %v", recovered))
+			}
+		}()
+		if err := fn(g.ctx); err != nil {
+			g.fail(err)
+		}
+	}})
+	if err != nil {
+		g.wg.Done()
+		g.fail(err)
+	}
+}
+
+// Wait waits for the submitted tasks and returns the first error.
+func (g *Group) Wait() error {
+	g.wg.Wait()
+	g.cancel(nil)
+	return g.err
+}
+
+func (g *Group) fail(err error) {
+	g.errOnce.Do(func() {
+		g.err = err
+		g.cancel(err)
+	})
+}
@@ -0,0 +1,104 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPoolBoundsWorkersAndDrains(t *testing.T) {
+	p := New(Options{Workers: 2, QueueSize: 4})
+	var running, peak, done atomic.Int32
+	for i := 0; i < 10; i++ {
+		err := p.Submit(context.Background(), func(context.Context) {
+			n := running.Add(1)
+			for {
+				old := peak.Load()
+				if n <= old || peak.CompareAndSwap(old, n) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			running.Add(-1)
+			done.Add(1)
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := p.Drain(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if done.Load() != 10 || peak.Load() > 2 {
+		t.Errorf("done = %d, peak = %d; want 10 tasks on at most 2 workers", done.Load(), peak.Load())
+	}
+	if err := p.Submit(context.Background(), func(context.Context) {}); !errors.Is(err, ErrClosed) {
+		t.Errorf("Submit after Drain = %v, want ErrClosed", err)
+	}
+}
+
+func TestPoolSubmitHonoursContext(t *testing.T) {
+	p := New(Options{Workers: 1, QueueSize: 1})
+	defer p.Drain(context.Background())
+	release := make(chan struct{})
+	started := make(chan struct{})
+	p.Submit(context.Background(), func(context.Context) {
+		close(started)
+		<-release
+	})
+	<-started
+	p.Submit(context.Background(), func(context.Context) {})
+	if p.TrySubmit(context.Background(), func(context.Context) {}) {
+		t.Error("TrySubmit queued past the queue size")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := p.Submit(ctx, func(context.Context) {}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Submit on a full queue = %v, want DeadlineExceeded", err)
+	}
+	close(release)
+}
+
+func TestPoolRecoversPanics(t *testing.T) {
+	recovered := make(chan any, 1)
+	p := New(Options{Workers: 1, OnPanic: func(r any) { recovered <- r }})
+	p.Submit(context.Background(), func(context.Context) { panic("boom") })
+	var ran atomic.Bool
+	p.Submit(context.Background(), func(context.Context) { ran.Store(true) })
+	p.Drain(context.Background())
+	if r := <-recovered; r != "boom" || !ran.Load() {
+		t.Errorf("recovered %v, ran = %v; want the worker to survive the panic", r, ran.Load())
+	}
+}
+
+func TestGroupReturnsFirstErrorAndCancels(t *testing.T) {
+	p := New(Options{Workers: 2})
+	defer p.Drain(context.Background())
+	errBoom := errors.New("boom")
+
+	g, ctx := p.Group(context.Background())
+	for i := 0; i < 5; i++ {
+		g.Go(func(ctx context.Context) error {
+			if i == 0 {
+				return errBoom
+			}
+			<-ctx.Done()
+			return ctx.Err()
+		})
+	}
+	if err := g.Wait(); !errors.Is(err, errBoom) {
+		t.Errorf("Wait = %v, want %v", err, errBoom)
+	}
+	if context.Cause(ctx) != errBoom {
+		t.Errorf("cause = %v, want %v", context.Cause(ctx), errBoom)
+	}
+
+	g, _ = p.Group(context.Background())
+	g.Go(func(context.Context) error { panic("bad") })
+	if err := g.Wait(); err == nil {
+		t.Error("panicking task did not fail the group")
+	}
+}